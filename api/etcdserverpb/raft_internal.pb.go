@@ -105,6 +105,11 @@ type InternalRaftRequest struct {
 	ClusterVersionSet        *membershippb.ClusterVersionSetRequest    `protobuf:"bytes,1300,opt,name=cluster_version_set,json=clusterVersionSet,proto3" json:"cluster_version_set,omitempty"`
 	ClusterMemberAttrSet     *membershippb.ClusterMemberAttrSetRequest `protobuf:"bytes,1301,opt,name=cluster_member_attr_set,json=clusterMemberAttrSet,proto3" json:"cluster_member_attr_set,omitempty"`
 	DowngradeInfoSet         *membershippb.DowngradeInfoSetRequest     `protobuf:"bytes,1302,opt,name=downgrade_info_set,json=downgradeInfoSet,proto3" json:"downgrade_info_set,omitempty"`
+	QuotaSet                 *QuotaSetRequest                          `protobuf:"bytes,1400,opt,name=quota_set,json=quotaSet,proto3" json:"quota_set,omitempty"`
+	QuotaDelete              *QuotaDeleteRequest                       `protobuf:"bytes,1401,opt,name=quota_delete,json=quotaDelete,proto3" json:"quota_delete,omitempty"`
+	AuthTenantAdd            *AuthTenantAddRequest                     `protobuf:"bytes,1500,opt,name=auth_tenant_add,json=authTenantAdd,proto3" json:"auth_tenant_add,omitempty"`
+	AuthTenantDelete         *AuthTenantDeleteRequest                  `protobuf:"bytes,1501,opt,name=auth_tenant_delete,json=authTenantDelete,proto3" json:"auth_tenant_delete,omitempty"`
+	AuthTenantList           *AuthTenantListRequest                    `protobuf:"bytes,1502,opt,name=auth_tenant_list,json=authTenantList,proto3" json:"auth_tenant_list,omitempty"`
 	XXX_NoUnkeyedLiteral     struct{}                                  `json:"-"`
 	XXX_unrecognized         []byte                                    `json:"-"`
 	XXX_sizecache            int32                                     `json:"-"`
@@ -238,73 +243,81 @@ func init() {
 func init() { proto.RegisterFile("raft_internal.proto", fileDescriptor_b4c9a9be0cfca103) }
 
 var fileDescriptor_b4c9a9be0cfca103 = []byte{
-	// 1054 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x56, 0x5d, 0x6f, 0x1b, 0x45,
-	0x14, 0xad, 0xd3, 0x34, 0x89, 0xc7, 0x49, 0x9a, 0x4e, 0x52, 0x3a, 0x38, 0x92, 0x71, 0x03, 0x2d,
-	0x01, 0x8a, 0x53, 0x1c, 0x78, 0xe1, 0x05, 0x5c, 0x3b, 0x4a, 0x83, 0x4a, 0x15, 0x6d, 0x0b, 0xaa,
-	0x84, 0xd0, 0x32, 0xde, 0xbd, 0xb1, 0xb7, 0x59, 0xef, 0x2e, 0x33, 0x63, 0x37, 0x7d, 0xe5, 0x91,
-	0x67, 0x40, 0xfc, 0x0c, 0x3e, 0xff, 0x43, 0x85, 0xf8, 0x28, 0xf0, 0x07, 0x20, 0xbc, 0xf0, 0x0e,
-	0xbc, 0xa3, 0xf9, 0xd8, 0x5d, 0xaf, 0x3d, 0xce, 0xdb, 0xfa, 0xde, 0x73, 0xcf, 0x39, 0x33, 0x73,
-	0xef, 0x78, 0xd0, 0x3a, 0xa3, 0x47, 0xc2, 0x0d, 0x22, 0x01, 0x2c, 0xa2, 0x61, 0x23, 0x61, 0xb1,
-	0x88, 0xf1, 0x32, 0x08, 0xcf, 0xe7, 0xc0, 0x46, 0xc0, 0x92, 0x6e, 0x75, 0xa3, 0x17, 0xf7, 0x62,
-	0x95, 0xd8, 0x91, 0x5f, 0x1a, 0x53, 0x5d, 0xcb, 0x31, 0x26, 0x52, 0x66, 0x89, 0x67, 0x3e, 0xeb,
-	0x32, 0xb9, 0x43, 0x93, 0x60, 0x67, 0x04, 0x8c, 0x07, 0x71, 0x94, 0x74, 0xd3, 0x2f, 0x83, 0xb8,
-	0x9e, 0x21, 0x06, 0x30, 0xe8, 0x02, 0xe3, 0xfd, 0x20, 0x49, 0xba, 0x63, 0x3f, 0x34, 0x6e, 0x8b,
-	0xa1, 0x15, 0x07, 0x3e, 0x1e, 0x02, 0x17, 0xb7, 0x81, 0xfa, 0xc0, 0xf0, 0x2a, 0x9a, 0x3b, 0xe8,
-	0x90, 0x52, 0xbd, 0xb4, 0x3d, 0xef, 0xcc, 0x1d, 0x74, 0x70, 0x15, 0x2d, 0x0d, 0xb9, 0x34, 0x3f,
-	0x00, 0x32, 0x57, 0x2f, 0x6d, 0x97, 0x9d, 0xec, 0x37, 0xbe, 0x81, 0x56, 0xe8, 0x50, 0xf4, 0x5d,
-	0x06, 0xa3, 0x40, 0x6a, 0x93, 0xf3, 0xb2, 0xec, 0xd6, 0xe2, 0xa7, 0xdf, 0x93, 0xf3, 0xbb, 0x8d,
-	0xd7, 0x9c, 0x65, 0x99, 0x75, 0x4c, 0xf2, 0xcd, 0xc5, 0x4f, 0x54, 0xf8, 0xe6, 0xd6, 0x0f, 0x18,
-	0xad, 0x1f, 0x98, 0x1d, 0x71, 0xe8, 0x91, 0x30, 0x06, 0xf0, 0x2e, 0x5a, 0xe8, 0x2b, 0x13, 0xc4,
-	0xaf, 0x97, 0xb6, 0x2b, 0xcd, 0xcd, 0xc6, 0xf8, 0x3e, 0x35, 0x0a, 0x3e, 0x1d, 0x03, 0x9d, 0xf2,
-	0x7b, 0x0d, 0xcd, 0x8d, 0x9a, 0xca, 0x69, 0xa5, 0x79, 0xd9, 0x4a, 0xe0, 0xcc, 0x8d, 0x9a, 0xf8,
-	0x26, 0xba, 0xc0, 0x68, 0xd4, 0x03, 0x65, 0xb9, 0xd2, 0xac, 0x4e, 0x20, 0x65, 0x2a, 0x85, 0x6b,
-	0x20, 0x7e, 0x19, 0x9d, 0x4f, 0x86, 0x82, 0xcc, 0x2b, 0x3c, 0x29, 0xe2, 0x0f, 0x87, 0xe9, 0x22,
-	0x1c, 0x09, 0xc2, 0x6d, 0xb4, 0xec, 0x43, 0x08, 0x02, 0x5c, 0x2d, 0x72, 0x41, 0x15, 0xd5, 0x8b,
-	0x45, 0x1d, 0x85, 0x28, 0x48, 0x55, 0xfc, 0x3c, 0x26, 0x05, 0xc5, 0x49, 0x44, 0x16, 0x6c, 0x82,
-	0xf7, 0x4f, 0xa2, 0x4c, 0x50, 0x9c, 0x44, 0xf8, 0x2d, 0x84, 0xbc, 0x78, 0x90, 0x50, 0x4f, 0xc8,
-	0x63, 0x58, 0x54, 0x25, 0xcf, 0x15, 0x4b, 0xda, 0x59, 0x3e, 0xad, 0x1c, 0x2b, 0xc1, 0x6f, 0xa3,
-	0x4a, 0x08, 0x94, 0x83, 0xdb, 0x63, 0x34, 0x12, 0x64, 0xc9, 0xc6, 0x70, 0x47, 0x02, 0xf6, 0x65,
-	0x3e, 0x63, 0x08, 0xb3, 0x90, 0x5c, 0xb3, 0x66, 0x60, 0x30, 0x8a, 0x8f, 0x81, 0x94, 0x6d, 0x6b,
-	0x56, 0x14, 0x8e, 0x02, 0x64, 0x6b, 0x0e, 0xf3, 0x98, 0x3c, 0x16, 0x1a, 0x52, 0x36, 0x20, 0xc8,
-	0x76, 0x2c, 0x2d, 0x99, 0xca, 0x8e, 0x45, 0x01, 0xf1, 0x03, 0xb4, 0xa6, 0x65, 0xbd, 0x3e, 0x78,
-	0xc7, 0x49, 0x1c, 0x44, 0x82, 0x54, 0x54, 0xf1, 0x0b, 0x16, 0xe9, 0x76, 0x06, 0x32, 0x34, 0x69,
-	0xb3, 0xbe, 0xee, 0x5c, 0x0c, 0x8b, 0x00, 0xdc, 0x42, 0x15, 0xd5, 0xdd, 0x10, 0xd1, 0x6e, 0x08,
-	0xe4, 0x6f, 0xeb, 0xae, 0xb6, 0x86, 0xa2, 0xbf, 0xa7, 0x00, 0xd9, 0x9e, 0xd0, 0x2c, 0x84, 0x3b,
-	0x48, 0x8d, 0x80, 0xeb, 0x07, 0x5c, 0x71, 0xfc, 0xb3, 0x68, 0xdb, 0x14, 0xc9, 0xd1, 0xd1, 0x88,
-	0x6c, 0x53, 0x68, 0x1e, 0xc3, 0xef, 0x18, 0x23, 0x5c, 0x50, 0x31, 0xe4, 0xe4, 0xbf, 0x99, 0x46,
-	0xee, 0x29, 0xc0, 0xc4, 0xca, 0xde, 0xd0, 0x8e, 0x74, 0x0e, 0xdf, 0xd5, 0x8e, 0x20, 0x12, 0x81,
-	0x47, 0x05, 0x90, 0x7f, 0x35, 0xd9, 0x4b, 0x45, 0xb2, 0x74, 0x3a, 0x5b, 0x63, 0xd0, 0xd4, 0x5a,
-	0xa1, 0x1e, 0xef, 0x99, 0x2b, 0x40, 0xde, 0x09, 0x2e, 0xf5, 0x7d, 0xf2, 0xe3, 0xd2, 0xac, 0x25,
-	0xbe, 0xc7, 0x81, 0xb5, 0x7c, 0xbf, 0xb0, 0x44, 0x13, 0xc3, 0x77, 0xd1, 0x5a, 0x4e, 0xa3, 0x87,
-	0x80, 0xfc, 0xa4, 0x99, 0x9e, 0xb7, 0x33, 0x99, 0xe9, 0x31, 0x64, 0xab, 0xb4, 0x10, 0x2e, 0xda,
-	0xea, 0x81, 0x20, 0x3f, 0x9f, 0x69, 0x6b, 0x1f, 0xc4, 0x94, 0xad, 0x7d, 0x10, 0xb8, 0x87, 0x9e,
-	0xcd, 0x69, 0xbc, 0xbe, 0x1c, 0x4b, 0x37, 0xa1, 0x9c, 0x3f, 0x8a, 0x99, 0x4f, 0x7e, 0xd1, 0x94,
-	0xaf, 0xd8, 0x29, 0xdb, 0x0a, 0x7d, 0x68, 0xc0, 0x29, 0xfb, 0x33, 0xd4, 0x9a, 0xc6, 0x0f, 0xd0,
-	0xc6, 0x98, 0x5f, 0x39, 0x4f, 0x2e, 0x8b, 0x43, 0x20, 0x4f, 0xb5, 0xc6, 0xf5, 0x19, 0xb6, 0xd5,
-	0x2c, 0xc6, 0x79, 0xdb, 0x5c, 0xa2, 0x93, 0x19, 0xfc, 0x01, 0xba, 0x9c, 0x33, 0xeb, 0xd1, 0xd4,
-	0xd4, 0xbf, 0x6a, 0xea, 0x17, 0xed, 0xd4, 0x66, 0x46, 0xc7, 0xb8, 0x31, 0x9d, 0x4a, 0xe1, 0xdb,
-	0x68, 0x35, 0x27, 0x0f, 0x03, 0x2e, 0xc8, 0x6f, 0x9a, 0xf5, 0xaa, 0x9d, 0xf5, 0x4e, 0xc0, 0x45,
-	0xa1, 0x8f, 0xd2, 0x60, 0xc6, 0x24, 0xad, 0x69, 0xa6, 0xdf, 0x67, 0x32, 0x49, 0xe9, 0x29, 0xa6,
-	0x34, 0x98, 0x1d, 0xbd, 0x62, 0x92, 0x1d, 0xf9, 0x55, 0x79, 0xd6, 0xd1, 0xcb, 0x9a, 0xc9, 0x8e,
-	0x34, 0xb1, 0xac, 0x23, 0x15, 0x8d, 0xe9, 0xc8, 0xaf, 0xcb, 0xb3, 0x3a, 0x52, 0x56, 0x59, 0x3a,
-	0x32, 0x0f, 0x17, 0x6d, 0xc9, 0x8e, 0xfc, 0xe6, 0x4c, 0x5b, 0x93, 0x1d, 0x69, 0x62, 0xf8, 0x21,
-	0xaa, 0x8e, 0xd1, 0xa8, 0x46, 0x49, 0x80, 0x0d, 0x02, 0xae, 0xfe, 0x7f, 0xbf, 0xd5, 0x9c, 0x37,
-	0x66, 0x70, 0x4a, 0xf8, 0x61, 0x86, 0x4e, 0xf9, 0xaf, 0x50, 0x7b, 0x1e, 0x0f, 0xd0, 0x66, 0xae,
-	0x65, 0x5a, 0x67, 0x4c, 0xec, 0x3b, 0x2d, 0xf6, 0xaa, 0x5d, 0x4c, 0x77, 0xc9, 0xb4, 0x1a, 0xa1,
-	0x33, 0x00, 0xf8, 0x23, 0xb4, 0xee, 0x85, 0x43, 0x2e, 0x80, 0xb9, 0xe6, 0x2d, 0xe3, 0x72, 0x10,
-	0xe4, 0x33, 0x64, 0x46, 0x60, 0xfc, 0x21, 0xd3, 0x68, 0x6b, 0xe4, 0xfb, 0x1a, 0x78, 0x0f, 0xc4,
-	0xd4, 0xad, 0x77, 0xc9, 0x9b, 0x84, 0xe0, 0x87, 0xe8, 0x4a, 0xaa, 0xa0, 0xc9, 0x5c, 0x2a, 0x04,
-	0x53, 0x2a, 0x9f, 0x23, 0x73, 0x0f, 0xda, 0x54, 0xde, 0x55, 0xb1, 0x96, 0x10, 0xcc, 0x26, 0xb4,
-	0xe1, 0x59, 0x50, 0xf8, 0x43, 0x84, 0xfd, 0xf8, 0x51, 0xd4, 0x63, 0xd4, 0x07, 0x37, 0x88, 0x8e,
-	0x62, 0x25, 0xf3, 0x85, 0x96, 0xb9, 0x56, 0x94, 0xe9, 0xa4, 0xc0, 0x83, 0xe8, 0x28, 0xb6, 0x49,
-	0xac, 0xf9, 0x13, 0x88, 0xfc, 0x31, 0x75, 0x11, 0xad, 0xec, 0x0d, 0x12, 0xf1, 0xd8, 0x01, 0x9e,
-	0xc4, 0x11, 0x87, 0xad, 0xc7, 0x68, 0xf3, 0x8c, 0xeb, 0x1b, 0x63, 0x34, 0xaf, 0xde, 0x72, 0x25,
-	0xf5, 0x96, 0x53, 0xdf, 0xf2, 0x8d, 0x97, 0xdd, 0x6a, 0xe6, 0x8d, 0x97, 0xfe, 0xc6, 0x57, 0xd1,
-	0x32, 0x0f, 0x06, 0x49, 0x08, 0xae, 0x88, 0x8f, 0x41, 0x3f, 0xf1, 0xca, 0x4e, 0x45, 0xc7, 0xee,
-	0xcb, 0x50, 0xe6, 0xe5, 0xd6, 0xc6, 0x93, 0x3f, 0x6b, 0xe7, 0x9e, 0x9c, 0xd6, 0x4a, 0x4f, 0x4f,
-	0x6b, 0xa5, 0x3f, 0x4e, 0x6b, 0xa5, 0x2f, 0xff, 0xaa, 0x9d, 0xeb, 0x2e, 0xa8, 0x97, 0xe6, 0xee,
-	0xff, 0x01, 0x00, 0x00, 0xff, 0xff, 0x30, 0x36, 0x53, 0xc6, 0x0b, 0x0b, 0x00, 0x00,
+	// 1173 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x97, 0xcb, 0x73, 0xdc, 0xc4,
+	0x13, 0xc7, 0xb3, 0xb6, 0x63, 0x7b, 0x47, 0x7e, 0x6c, 0xc6, 0xf6, 0x2f, 0xf3, 0xb3, 0x0b, 0xe3,
+	0x18, 0x12, 0x0c, 0x04, 0x3b, 0xd8, 0xc0, 0x81, 0x0b, 0x6c, 0xbc, 0x2e, 0xc7, 0x54, 0x92, 0x32,
+	0x8a, 0x81, 0x54, 0x51, 0x94, 0x98, 0x95, 0xc6, 0xbb, 0x8a, 0xb5, 0x92, 0x3c, 0x33, 0xda, 0x38,
+	0x57, 0x8e, 0x9c, 0x81, 0xe2, 0xcf, 0xe0, 0xf9, 0x3f, 0xe4, 0xc0, 0x23, 0xc0, 0x15, 0x28, 0x30,
+	0x17, 0x2e, 0x9c, 0x80, 0x2a, 0xb8, 0x51, 0xf3, 0xd0, 0x6b, 0x77, 0xe4, 0x9b, 0xb6, 0xfb, 0xdb,
+	0x9f, 0xee, 0x99, 0xe9, 0x96, 0x66, 0xc1, 0x1c, 0xc5, 0x87, 0xdc, 0xf1, 0x43, 0x4e, 0x68, 0x88,
+	0x83, 0xf5, 0x98, 0x46, 0x3c, 0x82, 0x53, 0x84, 0xbb, 0x1e, 0x23, 0xb4, 0x4f, 0x68, 0xdc, 0x5e,
+	0x9c, 0xef, 0x44, 0x9d, 0x48, 0x3a, 0x36, 0xc4, 0x93, 0xd2, 0x2c, 0x36, 0x72, 0x8d, 0xb6, 0xd4,
+	0x69, 0xec, 0xea, 0xc7, 0x15, 0xe1, 0xdc, 0xc0, 0xb1, 0xbf, 0xd1, 0x27, 0x94, 0xf9, 0x51, 0x18,
+	0xb7, 0xd3, 0x27, 0xad, 0xb8, 0x92, 0x29, 0x7a, 0xa4, 0xd7, 0x26, 0x94, 0x75, 0xfd, 0x38, 0x6e,
+	0x17, 0x7e, 0x28, 0xdd, 0x2a, 0x05, 0xd3, 0x36, 0x39, 0x4e, 0x08, 0xe3, 0x37, 0x08, 0xf6, 0x08,
+	0x85, 0x33, 0x60, 0x64, 0xaf, 0x85, 0x6a, 0x2b, 0xb5, 0xb5, 0x31, 0x7b, 0x64, 0xaf, 0x05, 0x17,
+	0xc1, 0x64, 0xc2, 0x44, 0xf1, 0x3d, 0x82, 0x46, 0x56, 0x6a, 0x6b, 0x75, 0x3b, 0xfb, 0x0d, 0xaf,
+	0x82, 0x69, 0x9c, 0xf0, 0xae, 0x43, 0x49, 0xdf, 0x17, 0xb9, 0xd1, 0xa8, 0x08, 0xbb, 0x3e, 0xf1,
+	0xfe, 0x97, 0x68, 0x74, 0x6b, 0xfd, 0x79, 0x7b, 0x4a, 0x78, 0x6d, 0xed, 0x7c, 0x79, 0xe2, 0x3d,
+	0x69, 0xbe, 0xb6, 0xfa, 0xc7, 0x02, 0x98, 0xdb, 0xd3, 0x3b, 0x62, 0xe3, 0x43, 0xae, 0x0b, 0x80,
+	0x5b, 0x60, 0xbc, 0x2b, 0x8b, 0x40, 0xde, 0x4a, 0x6d, 0xcd, 0xda, 0x5c, 0x5a, 0x2f, 0xee, 0xd3,
+	0x7a, 0xa9, 0x4e, 0x7b, 0xbc, 0x6b, 0xae, 0xf7, 0x32, 0x18, 0xe9, 0x6f, 0xca, 0x4a, 0xad, 0xcd,
+	0x05, 0x23, 0xc0, 0x1e, 0xe9, 0x6f, 0xc2, 0x6b, 0xe0, 0x3c, 0xc5, 0x61, 0x87, 0xc8, 0x92, 0xad,
+	0xcd, 0xc5, 0x01, 0xa5, 0x70, 0xa5, 0x72, 0x25, 0x84, 0xcf, 0x80, 0xd1, 0x38, 0xe1, 0x68, 0x4c,
+	0xea, 0x51, 0x59, 0xbf, 0x9f, 0xa4, 0x8b, 0xb0, 0x85, 0x08, 0x6e, 0x83, 0x29, 0x8f, 0x04, 0x84,
+	0x13, 0x47, 0x25, 0x39, 0x2f, 0x83, 0x56, 0xca, 0x41, 0x2d, 0xa9, 0x28, 0xa5, 0xb2, 0xbc, 0xdc,
+	0x26, 0x12, 0xf2, 0x93, 0x10, 0x8d, 0x9b, 0x12, 0x1e, 0x9c, 0x84, 0x59, 0x42, 0x7e, 0x12, 0xc2,
+	0x57, 0x00, 0x70, 0xa3, 0x5e, 0x8c, 0x5d, 0x2e, 0x8e, 0x61, 0x42, 0x86, 0x3c, 0x5e, 0x0e, 0xd9,
+	0xce, 0xfc, 0x69, 0x64, 0x21, 0x04, 0xbe, 0x0a, 0xac, 0x80, 0x60, 0x46, 0x9c, 0x0e, 0xc5, 0x21,
+	0x47, 0x93, 0x26, 0xc2, 0x4d, 0x21, 0xd8, 0x15, 0xfe, 0x8c, 0x10, 0x64, 0x26, 0xb1, 0x66, 0x45,
+	0xa0, 0xa4, 0x1f, 0x1d, 0x11, 0x54, 0x37, 0xad, 0x59, 0x22, 0x6c, 0x29, 0xc8, 0xd6, 0x1c, 0xe4,
+	0x36, 0x71, 0x2c, 0x38, 0xc0, 0xb4, 0x87, 0x80, 0xe9, 0x58, 0x9a, 0xc2, 0x95, 0x1d, 0x8b, 0x14,
+	0xc2, 0xbb, 0xa0, 0xa1, 0xd2, 0xba, 0x5d, 0xe2, 0x1e, 0xc5, 0x91, 0x1f, 0x72, 0x64, 0xc9, 0xe0,
+	0x27, 0x0d, 0xa9, 0xb7, 0x33, 0x91, 0xc6, 0xa4, 0xcd, 0xfa, 0x82, 0x3d, 0x1b, 0x94, 0x05, 0xb0,
+	0x09, 0x2c, 0xd9, 0xdd, 0x24, 0xc4, 0xed, 0x80, 0xa0, 0xdf, 0x8d, 0xbb, 0xda, 0x4c, 0x78, 0x77,
+	0x47, 0x0a, 0xb2, 0x3d, 0xc1, 0x99, 0x09, 0xb6, 0x80, 0x1c, 0x01, 0xc7, 0xf3, 0x99, 0x64, 0xfc,
+	0x39, 0x61, 0xda, 0x14, 0xc1, 0x68, 0xf9, 0xac, 0x08, 0xb1, 0x70, 0x6e, 0x83, 0xaf, 0xe9, 0x42,
+	0x18, 0xc7, 0x3c, 0x61, 0xe8, 0xef, 0xca, 0x42, 0xee, 0x48, 0xc1, 0xc0, 0xca, 0x5e, 0x54, 0x15,
+	0x29, 0x1f, 0xbc, 0xad, 0x2a, 0x22, 0x21, 0xf7, 0x5d, 0xcc, 0x09, 0xfa, 0x4b, 0xc1, 0x9e, 0x2e,
+	0xc3, 0xd2, 0xe9, 0x6c, 0x16, 0xa4, 0x69, 0x69, 0xa5, 0x78, 0xb8, 0xa3, 0x5f, 0x01, 0x09, 0x23,
+	0xd4, 0xc1, 0x9e, 0x87, 0xbe, 0x9a, 0xac, 0x5a, 0xe2, 0x1b, 0x8c, 0xd0, 0xa6, 0xe7, 0x95, 0x96,
+	0xa8, 0x6d, 0xf0, 0x36, 0x68, 0xe4, 0x18, 0x35, 0x04, 0xe8, 0x6b, 0x45, 0x7a, 0xc2, 0x4c, 0xd2,
+	0xd3, 0xa3, 0x61, 0x33, 0xb8, 0x64, 0x2e, 0x97, 0xd5, 0x21, 0x1c, 0x7d, 0x73, 0x66, 0x59, 0xbb,
+	0x84, 0x0f, 0x95, 0xb5, 0x4b, 0x38, 0xec, 0x80, 0xff, 0xe7, 0x18, 0xb7, 0x2b, 0xc6, 0xd2, 0x89,
+	0x31, 0x63, 0xf7, 0x23, 0xea, 0xa1, 0x6f, 0x15, 0xf2, 0x59, 0x33, 0x72, 0x5b, 0xaa, 0xf7, 0xb5,
+	0x38, 0xa5, 0xff, 0x0f, 0x1b, 0xdd, 0xf0, 0x2e, 0x98, 0x2f, 0xd4, 0x2b, 0xe6, 0xc9, 0xa1, 0x51,
+	0x40, 0xd0, 0x23, 0x95, 0xe3, 0x4a, 0x45, 0xd9, 0x72, 0x16, 0xa3, 0xbc, 0x6d, 0x2e, 0xe0, 0x41,
+	0x0f, 0x7c, 0x1b, 0x2c, 0xe4, 0x64, 0x35, 0x9a, 0x0a, 0xfd, 0x9d, 0x42, 0x3f, 0x65, 0x46, 0xeb,
+	0x19, 0x2d, 0xb0, 0x21, 0x1e, 0x72, 0xc1, 0x1b, 0x60, 0x26, 0x87, 0x07, 0x3e, 0xe3, 0xe8, 0x7b,
+	0x45, 0xbd, 0x64, 0xa6, 0xde, 0xf4, 0x19, 0x2f, 0xf5, 0x51, 0x6a, 0xcc, 0x48, 0xa2, 0x34, 0x45,
+	0xfa, 0xa1, 0x92, 0x24, 0x52, 0x0f, 0x91, 0x52, 0x63, 0x76, 0xf4, 0x92, 0x24, 0x3a, 0xf2, 0x93,
+	0x7a, 0xd5, 0xd1, 0x8b, 0x98, 0xc1, 0x8e, 0xd4, 0xb6, 0xac, 0x23, 0x25, 0x46, 0x77, 0xe4, 0xa7,
+	0xf5, 0xaa, 0x8e, 0x14, 0x51, 0x86, 0x8e, 0xcc, 0xcd, 0xe5, 0xb2, 0x44, 0x47, 0x7e, 0x76, 0x66,
+	0x59, 0x83, 0x1d, 0xa9, 0x6d, 0xf0, 0x1e, 0x58, 0x2c, 0x60, 0x64, 0xa3, 0xc4, 0x84, 0xf6, 0x7c,
+	0x26, 0xbf, 0xbf, 0x9f, 0x2b, 0xe6, 0xd5, 0x0a, 0xa6, 0x90, 0xef, 0x67, 0xea, 0x94, 0x7f, 0x11,
+	0x9b, 0xfd, 0xb0, 0x07, 0x96, 0xf2, 0x5c, 0xba, 0x75, 0x0a, 0xc9, 0xbe, 0x50, 0xc9, 0x9e, 0x33,
+	0x27, 0x53, 0x5d, 0x32, 0x9c, 0x0d, 0xe1, 0x0a, 0x01, 0x7c, 0x17, 0xcc, 0xb9, 0x41, 0xc2, 0x38,
+	0xa1, 0x8e, 0xbe, 0xcb, 0x38, 0x8c, 0x70, 0xf4, 0x01, 0xd0, 0x23, 0x50, 0xbc, 0xc8, 0xac, 0x6f,
+	0x2b, 0xe5, 0x9b, 0x4a, 0x78, 0x87, 0xf0, 0xa1, 0xb7, 0xde, 0x05, 0x77, 0x50, 0x02, 0xef, 0x81,
+	0x8b, 0x69, 0x06, 0x05, 0x73, 0x30, 0xe7, 0x54, 0x66, 0xf9, 0x10, 0xe8, 0xf7, 0xa0, 0x29, 0xcb,
+	0x2d, 0x69, 0x6b, 0x72, 0x4e, 0x4d, 0x89, 0xe6, 0x5d, 0x83, 0x0a, 0xbe, 0x03, 0xa0, 0x17, 0xdd,
+	0x0f, 0x3b, 0x14, 0x7b, 0xc4, 0xf1, 0xc3, 0xc3, 0x48, 0xa6, 0xf9, 0x48, 0xa5, 0xb9, 0x5c, 0x4e,
+	0xd3, 0x4a, 0x85, 0x7b, 0xe1, 0x61, 0x64, 0x4a, 0xd1, 0xf0, 0x06, 0x14, 0xb0, 0x05, 0xea, 0xc7,
+	0x49, 0xc4, 0xb1, 0xa4, 0xfe, 0xa3, 0xa8, 0x8f, 0x95, 0x4f, 0xe2, 0x75, 0xe1, 0x1f, 0xa6, 0xbd,
+	0x64, 0x4f, 0x1e, 0x6b, 0x0f, 0xbc, 0x05, 0xa6, 0x14, 0x45, 0x37, 0xf8, 0xbf, 0xc0, 0xd4, 0x93,
+	0x12, 0x54, 0xea, 0xee, 0x9c, 0x65, 0x1d, 0xe7, 0x4e, 0x78, 0x00, 0x66, 0x65, 0xc3, 0x70, 0x12,
+	0x8a, 0xc6, 0x14, 0xc3, 0xf7, 0xa3, 0xfa, 0x16, 0xaf, 0x0e, 0x37, 0xc9, 0x81, 0x14, 0xe5, 0xe3,
+	0x97, 0x33, 0xa7, 0x71, 0xd1, 0x2d, 0x76, 0xb2, 0x48, 0xd5, 0xa5, 0xfe, 0x64, 0xe9, 0x9d, 0xac,
+	0x00, 0x57, 0xd4, 0xdb, 0xc0, 0x03, 0x0a, 0xf8, 0x16, 0x68, 0x14, 0xf1, 0xf2, 0xdd, 0xf3, 0xb3,
+	0x55, 0x35, 0xe8, 0x2a, 0xb4, 0xf0, 0xf6, 0xc9, 0xd1, 0x33, 0xb8, 0xe4, 0xcf, 0xef, 0xbb, 0xb3,
+	0x60, 0x7a, 0xa7, 0x17, 0xf3, 0x07, 0x36, 0x61, 0x71, 0x14, 0x32, 0xb2, 0xfa, 0x00, 0x2c, 0x9d,
+	0xf1, 0x85, 0x85, 0x10, 0x8c, 0xc9, 0xeb, 0x76, 0x4d, 0x5e, 0xb7, 0xe5, 0xb3, 0xb8, 0x86, 0x67,
+	0x1f, 0x1e, 0x7d, 0x0d, 0x4f, 0x7f, 0xc3, 0x4b, 0x60, 0x8a, 0xf9, 0xbd, 0x38, 0x20, 0x0e, 0x8f,
+	0x8e, 0x88, 0xba, 0x85, 0xd7, 0x6d, 0x4b, 0xd9, 0x0e, 0x84, 0x29, 0xab, 0xe5, 0xfa, 0xfc, 0xc3,
+	0x5f, 0x97, 0xcf, 0x3d, 0x3c, 0x5d, 0xae, 0x3d, 0x3a, 0x5d, 0xae, 0xfd, 0x72, 0xba, 0x5c, 0xfb,
+	0xf8, 0xb7, 0xe5, 0x73, 0xed, 0x71, 0xf9, 0x67, 0x60, 0xeb, 0xbf, 0x01, 0x00, 0xef, 0xbe, 0x21,
+	0x58, 0xae, 0x0c, 0x00, 0x00,
 }
 
 func (m *RequestHeader) Marshal() (dAtA []byte, err error) {
@@ -375,6 +388,76 @@ func (m *InternalRaftRequest) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.AuthTenantList != nil {
+		{
+			size, err := m.AuthTenantList.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5d
+		i--
+		dAtA[i] = 0xf2
+	}
+	if m.AuthTenantDelete != nil {
+		{
+			size, err := m.AuthTenantDelete.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5d
+		i--
+		dAtA[i] = 0xea
+	}
+	if m.AuthTenantAdd != nil {
+		{
+			size, err := m.AuthTenantAdd.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x5d
+		i--
+		dAtA[i] = 0xe2
+	}
+	if m.QuotaDelete != nil {
+		{
+			size, err := m.QuotaDelete.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x57
+		i--
+		dAtA[i] = 0xca
+	}
+	if m.QuotaSet != nil {
+		{
+			size, err := m.QuotaSet.MarshalToSizedBuffer(dAtA[:i])
+			if err != nil {
+				return 0, err
+			}
+			i -= size
+			i = encodeVarintRaftInternal(dAtA, i, uint64(size))
+		}
+		i--
+		dAtA[i] = 0x57
+		i--
+		dAtA[i] = 0xc2
+	}
 	if m.DowngradeInfoSet != nil {
 		{
 			size, err := m.DowngradeInfoSet.MarshalToSizedBuffer(dAtA[:i])
@@ -1038,6 +1121,26 @@ func (m *InternalRaftRequest) Size() (n int) {
 		l = m.DowngradeInfoSet.Size()
 		n += 2 + l + sovRaftInternal(uint64(l))
 	}
+	if m.QuotaSet != nil {
+		l = m.QuotaSet.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.QuotaDelete != nil {
+		l = m.QuotaDelete.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthTenantAdd != nil {
+		l = m.AuthTenantAdd.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthTenantDelete != nil {
+		l = m.AuthTenantDelete.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
+	if m.AuthTenantList != nil {
+		l = m.AuthTenantList.Size()
+		n += 2 + l + sovRaftInternal(uint64(l))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -2371,6 +2474,186 @@ func (m *InternalRaftRequest) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 1400:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QuotaSet", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.QuotaSet == nil {
+				m.QuotaSet = &QuotaSetRequest{}
+			}
+			if err := m.QuotaSet.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1401:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field QuotaDelete", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.QuotaDelete == nil {
+				m.QuotaDelete = &QuotaDeleteRequest{}
+			}
+			if err := m.QuotaDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1500:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthTenantAdd", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthTenantAdd == nil {
+				m.AuthTenantAdd = &AuthTenantAddRequest{}
+			}
+			if err := m.AuthTenantAdd.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1501:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthTenantDelete", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthTenantDelete == nil {
+				m.AuthTenantDelete = &AuthTenantDeleteRequest{}
+			}
+			if err := m.AuthTenantDelete.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
+		case 1502:
+			if wireType != 2 {
+				return fmt.Errorf("proto: wrong wireType = %d for field AuthTenantList", wireType)
+			}
+			var msglen int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowRaftInternal
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				msglen |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			if msglen < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			postIndex := iNdEx + msglen
+			if postIndex < 0 {
+				return ErrInvalidLengthRaftInternal
+			}
+			if postIndex > l {
+				return io.ErrUnexpectedEOF
+			}
+			if m.AuthTenantList == nil {
+				m.AuthTenantList = &AuthTenantListRequest{}
+			}
+			if err := m.AuthTenantList.Unmarshal(dAtA[iNdEx:postIndex]); err != nil {
+				return err
+			}
+			iNdEx = postIndex
 		default:
 			iNdEx = preIndex
 			skippy, err := skipRaftInternal(dAtA[iNdEx:])
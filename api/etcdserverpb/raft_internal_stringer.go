@@ -101,11 +101,19 @@ func (as *txnRequestStringer) String() string {
 	for _, f := range as.Request.Failure {
 		failure = append(failure, newLoggableRequestOp(f).String())
 	}
-	return fmt.Sprintf("compare:<%s> success:<%s> failure:<%s>",
+	s := fmt.Sprintf("compare:<%s> success:<%s> failure:<%s>",
 		strings.Join(compare, " "),
 		strings.Join(success, " "),
 		strings.Join(failure, " "),
 	)
+	if len(as.Request.ReadSet) != 0 {
+		var readSet []string
+		for _, e := range as.Request.ReadSet {
+			readSet = append(readSet, e.String())
+		}
+		s += fmt.Sprintf(" read_set:<%s>", strings.Join(readSet, " "))
+	}
+	return s
 }
 
 // requestOpStringer implements a custom proto String to replace value bytes fields with value
@@ -165,6 +173,7 @@ type loggablePutRequest struct {
 	PrevKv      bool   `protobuf:"varint,4,opt,name=prev_kv,proto3"`
 	IgnoreValue bool   `protobuf:"varint,5,opt,name=ignore_value,proto3"`
 	IgnoreLease bool   `protobuf:"varint,6,opt,name=ignore_lease,proto3"`
+	Ttl         int64  `protobuf:"varint,7,opt,name=ttl,proto3"`
 }
 
 func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
@@ -175,6 +184,7 @@ func NewLoggablePutRequest(request *PutRequest) *loggablePutRequest {
 		request.PrevKv,
 		request.IgnoreValue,
 		request.IgnoreLease,
+		request.Ttl,
 	}
 }
 
@@ -65,7 +65,10 @@ type KeyValue struct {
 	// lease is the ID of the lease that attached to key.
 	// When the attached lease expires, the key will be deleted.
 	// If lease is 0, then no lease is attached to the key.
-	Lease                int64    `protobuf:"varint,6,opt,name=lease,proto3" json:"lease,omitempty"`
+	Lease int64 `protobuf:"varint,6,opt,name=lease,proto3" json:"lease,omitempty"`
+	// ttl is the time to live in seconds the key was put with. The key is
+	// deleted once it expires. If ttl is 0, the key was put without a ttl.
+	Ttl                  int64    `protobuf:"varint,7,opt,name=ttl,proto3" json:"ttl,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -116,10 +119,19 @@ type Event struct {
 	// its modification revision set to the revision of deletion.
 	Kv *KeyValue `protobuf:"bytes,2,opt,name=kv,proto3" json:"kv,omitempty"`
 	// prev_kv holds the key-value pair before the event happens.
-	PrevKv               *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	PrevKv *KeyValue `protobuf:"bytes,3,opt,name=prev_kv,json=prevKv,proto3" json:"prev_kv,omitempty"`
+	// unchanged_value is set on PUT events of watchers omitting unchanged values
+	// when the put did not change the value of the key. The value is omitted
+	// from kv and prev_kv.
+	UnchangedValue bool `protobuf:"varint,4,opt,name=unchanged_value,json=unchangedValue,proto3" json:"unchanged_value,omitempty"`
+	// coalesced_start_revision is set on events of coalescing watchers that
+	// collapse several events of the key into the latest one. The event covers
+	// the changes of the key from coalesced_start_revision to the mod revision
+	// of kv, and prev_kv is the key-value before coalesced_start_revision.
+	CoalescedStartRevision int64    `protobuf:"varint,5,opt,name=coalesced_start_revision,json=coalescedStartRevision,proto3" json:"coalesced_start_revision,omitempty"`
+	XXX_NoUnkeyedLiteral   struct{} `json:"-"`
+	XXX_unrecognized       []byte   `json:"-"`
+	XXX_sizecache          int32    `json:"-"`
 }
 
 func (m *Event) Reset()         { *m = Event{} }
@@ -164,26 +176,30 @@ func init() {
 func init() { proto.RegisterFile("kv.proto", fileDescriptor_2216fe83c9c12408) }
 
 var fileDescriptor_2216fe83c9c12408 = []byte{
-	// 303 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x6c, 0x90, 0x41, 0x4e, 0xc2, 0x40,
-	0x14, 0x86, 0x3b, 0x14, 0x0a, 0x3e, 0x08, 0x36, 0x13, 0x12, 0x27, 0x2e, 0x26, 0x95, 0x8d, 0x18,
-	0x13, 0x4c, 0xf0, 0x06, 0xc6, 0xae, 0x70, 0x61, 0x1a, 0x74, 0x4b, 0x4a, 0x79, 0x21, 0xa4, 0x94,
-	0x69, 0x4a, 0x9d, 0xa4, 0x37, 0x71, 0xef, 0xde, 0x73, 0xb0, 0xe4, 0x08, 0x52, 0x2f, 0x62, 0xfa,
-	0xc6, 0xe2, 0xc6, 0xcd, 0xe4, 0xfd, 0xff, 0xff, 0x65, 0xe6, 0x7f, 0x03, 0x9d, 0x58, 0x8f, 0xd3,
-	0x4c, 0xe5, 0x8a, 0x3b, 0x89, 0x8e, 0xa2, 0x74, 0x71, 0x39, 0x58, 0xa9, 0x95, 0x22, 0xeb, 0xae,
-	0x9a, 0x4c, 0x3a, 0xfc, 0x64, 0xd0, 0x99, 0x62, 0xf1, 0x1a, 0x6e, 0xde, 0x90, 0xbb, 0x60, 0xc7,
-	0x58, 0x08, 0xe6, 0xb1, 0x51, 0x2f, 0xa8, 0x46, 0x7e, 0x0d, 0xe7, 0x51, 0x86, 0x61, 0x8e, 0xf3,
-	0x0c, 0xf5, 0x7a, 0xb7, 0x56, 0x5b, 0xd1, 0xf0, 0xd8, 0xc8, 0x0e, 0xfa, 0xc6, 0x0e, 0x7e, 0x5d,
-	0x7e, 0x05, 0xbd, 0x44, 0x2d, 0xff, 0x28, 0x9b, 0xa8, 0x6e, 0xa2, 0x96, 0x27, 0x44, 0x40, 0x5b,
-	0x63, 0x46, 0x69, 0x93, 0xd2, 0x5a, 0xf2, 0x01, 0xb4, 0x74, 0x55, 0x40, 0xb4, 0xe8, 0x65, 0x23,
-	0x2a, 0x77, 0x83, 0xe1, 0x0e, 0x85, 0x43, 0xb4, 0x11, 0xc3, 0x0f, 0x06, 0x2d, 0x5f, 0xe3, 0x36,
-	0xe7, 0xb7, 0xd0, 0xcc, 0x8b, 0x14, 0xa9, 0x6e, 0x7f, 0x72, 0x31, 0x36, 0x7b, 0x8e, 0x29, 0x34,
-	0xe7, 0xac, 0x48, 0x31, 0x20, 0x88, 0x7b, 0xd0, 0x88, 0x35, 0x75, 0xef, 0x4e, 0xdc, 0x1a, 0xad,
-	0x17, 0x0f, 0x1a, 0xb1, 0xe6, 0x37, 0xd0, 0x4e, 0x33, 0xd4, 0xf3, 0x58, 0x53, 0xf9, 0xff, 0x30,
-	0xa7, 0x02, 0xa6, 0x7a, 0xe8, 0xc1, 0xd9, 0xe9, 0x7e, 0xde, 0x06, 0xfb, 0xf9, 0x65, 0xe6, 0x5a,
-	0x1c, 0xc0, 0x79, 0xf4, 0x9f, 0xfc, 0x99, 0xef, 0xb2, 0x07, 0xb1, 0x3f, 0x4a, 0xeb, 0x70, 0x94,
-	0xd6, 0xbe, 0x94, 0xec, 0x50, 0x4a, 0xf6, 0x55, 0x4a, 0xf6, 0xfe, 0x2d, 0xad, 0x85, 0x43, 0xff,
-	0x7e, 0xff, 0x13, 0x00, 0x00, 0xff, 0xff, 0xb5, 0x45, 0x92, 0x5d, 0xa1, 0x01, 0x00, 0x00,
+	// 357 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x74, 0x91, 0xc1, 0xce, 0x93, 0x40,
+	0x14, 0x85, 0x19, 0xf8, 0x81, 0x7a, 0xff, 0xa6, 0x92, 0x49, 0xa3, 0x13, 0x17, 0x04, 0xbb, 0xb1,
+	0xc6, 0xa4, 0x26, 0x75, 0xe3, 0xda, 0xc8, 0xaa, 0x2e, 0x0c, 0x56, 0xb7, 0x84, 0xc2, 0x4d, 0x6d,
+	0xa0, 0x0c, 0x81, 0xe9, 0x24, 0xbc, 0x80, 0xcf, 0xe0, 0xe3, 0xb8, 0xec, 0xb2, 0x8f, 0x60, 0xeb,
+	0x8b, 0x98, 0x99, 0x11, 0xba, 0x72, 0x43, 0xee, 0x3d, 0xe7, 0x4b, 0xb8, 0xe7, 0x0c, 0x4c, 0x4a,
+	0xb9, 0x6a, 0x5a, 0x2e, 0x38, 0xf5, 0x8e, 0x32, 0xcf, 0x9b, 0xdd, 0x8b, 0xf9, 0x9e, 0xef, 0xb9,
+	0x96, 0xde, 0xaa, 0xc9, 0xb8, 0x8b, 0x5f, 0x04, 0x26, 0x1b, 0xec, 0xbf, 0x65, 0xd5, 0x09, 0x69,
+	0x00, 0x4e, 0x89, 0x3d, 0x23, 0x11, 0x59, 0x4e, 0x13, 0x35, 0xd2, 0x57, 0xf0, 0x34, 0x6f, 0x31,
+	0x13, 0x98, 0xb6, 0x28, 0x0f, 0xdd, 0x81, 0xd7, 0xcc, 0x8e, 0xc8, 0xd2, 0x49, 0x66, 0x46, 0x4e,
+	0xfe, 0xa9, 0xf4, 0x25, 0x4c, 0x8f, 0xbc, 0xb8, 0x53, 0x8e, 0xa6, 0x1e, 0x8f, 0xbc, 0x18, 0x11,
+	0x06, 0xbe, 0xc4, 0x56, 0xbb, 0x0f, 0xda, 0x1d, 0x56, 0x3a, 0x07, 0x57, 0xaa, 0x03, 0x98, 0xab,
+	0xff, 0x6c, 0x16, 0xa5, 0x56, 0x98, 0x75, 0xc8, 0x3c, 0x4d, 0x9b, 0x45, 0xdd, 0x28, 0x44, 0xc5,
+	0x7c, 0xad, 0xa9, 0x71, 0xf1, 0xc3, 0x06, 0x37, 0x96, 0x58, 0x0b, 0xfa, 0x06, 0x1e, 0x44, 0xdf,
+	0xa0, 0x0e, 0x30, 0x5b, 0x3f, 0x5f, 0x99, 0xe4, 0x2b, 0x6d, 0x9a, 0xef, 0xb6, 0x6f, 0x30, 0xd1,
+	0x10, 0x8d, 0xc0, 0x2e, 0xa5, 0x4e, 0xf3, 0xb8, 0x0e, 0x06, 0x74, 0xa8, 0x22, 0xb1, 0x4b, 0x49,
+	0x5f, 0x83, 0xdf, 0xb4, 0x28, 0xd3, 0x52, 0x32, 0xe7, 0x3f, 0x98, 0xa7, 0x80, 0x8d, 0x54, 0x3d,
+	0x9d, 0xea, 0xfc, 0x7b, 0x56, 0xef, 0xb1, 0x48, 0x4d, 0x16, 0x95, 0x71, 0x92, 0xcc, 0x46, 0xd9,
+	0x54, 0xfc, 0x1e, 0x58, 0xce, 0xb3, 0x0a, 0xbb, 0x1c, 0x8b, 0xb4, 0x13, 0x59, 0x2b, 0xee, 0x9d,
+	0xb9, 0x3a, 0xd3, 0xb3, 0xd1, 0xff, 0xa2, 0xec, 0xa1, 0xbe, 0x45, 0x04, 0x4f, 0xc6, 0x08, 0xd4,
+	0x07, 0xe7, 0xf3, 0xd7, 0x6d, 0x60, 0x51, 0x00, 0xef, 0x63, 0xfc, 0x29, 0xde, 0xc6, 0x01, 0xf9,
+	0xc0, 0xce, 0xd7, 0xd0, 0xba, 0x5c, 0x43, 0xeb, 0x7c, 0x0b, 0xc9, 0xe5, 0x16, 0x92, 0xdf, 0xb7,
+	0x90, 0xfc, 0xfc, 0x13, 0x5a, 0x3b, 0x4f, 0x3f, 0xf6, 0xbb, 0xbf, 0x03, 0x00, 0x4e, 0x73, 0xbf,
+	0x98, 0x16, 0x02, 0x00, 0x00,
 }
 
 func (m *KeyValue) Marshal() (dAtA []byte, err error) {
@@ -210,6 +226,11 @@ func (m *KeyValue) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.Ttl != 0 {
+		i = encodeVarintKv(dAtA, i, uint64(m.Ttl))
+		i--
+		dAtA[i] = 0x38
+	}
 	if m.Lease != 0 {
 		i = encodeVarintKv(dAtA, i, uint64(m.Lease))
 		i--
@@ -271,6 +292,21 @@ func (m *Event) MarshalToSizedBuffer(dAtA []byte) (int, error) {
 		i -= len(m.XXX_unrecognized)
 		copy(dAtA[i:], m.XXX_unrecognized)
 	}
+	if m.CoalescedStartRevision != 0 {
+		i = encodeVarintKv(dAtA, i, uint64(m.CoalescedStartRevision))
+		i--
+		dAtA[i] = 0x28
+	}
+	if m.UnchangedValue {
+		i--
+		if m.UnchangedValue {
+			dAtA[i] = 1
+		} else {
+			dAtA[i] = 0
+		}
+		i--
+		dAtA[i] = 0x20
+	}
 	if m.PrevKv != nil {
 		{
 			size, err := m.PrevKv.MarshalToSizedBuffer(dAtA[:i])
@@ -340,6 +376,9 @@ func (m *KeyValue) Size() (n int) {
 	if m.Lease != 0 {
 		n += 1 + sovKv(uint64(m.Lease))
 	}
+	if m.Ttl != 0 {
+		n += 1 + sovKv(uint64(m.Ttl))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -363,6 +402,12 @@ func (m *Event) Size() (n int) {
 		l = m.PrevKv.Size()
 		n += 1 + l + sovKv(uint64(l))
 	}
+	if m.UnchangedValue {
+		n += 2
+	}
+	if m.CoalescedStartRevision != 0 {
+		n += 1 + sovKv(uint64(m.CoalescedStartRevision))
+	}
 	if m.XXX_unrecognized != nil {
 		n += len(m.XXX_unrecognized)
 	}
@@ -548,6 +593,25 @@ func (m *KeyValue) Unmarshal(dAtA []byte) error {
 					break
 				}
 			}
+		case 7:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field Ttl", wireType)
+			}
+			m.Ttl = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.Ttl |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipKv(dAtA[iNdEx:])
@@ -690,6 +754,45 @@ func (m *Event) Unmarshal(dAtA []byte) error {
 				return err
 			}
 			iNdEx = postIndex
+		case 4:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field UnchangedValue", wireType)
+			}
+			var v int
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				v |= int(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
+			m.UnchangedValue = bool(v != 0)
+		case 5:
+			if wireType != 0 {
+				return fmt.Errorf("proto: wrong wireType = %d for field CoalescedStartRevision", wireType)
+			}
+			m.CoalescedStartRevision = 0
+			for shift := uint(0); ; shift += 7 {
+				if shift >= 64 {
+					return ErrIntOverflowKv
+				}
+				if iNdEx >= l {
+					return io.ErrUnexpectedEOF
+				}
+				b := dAtA[iNdEx]
+				iNdEx++
+				m.CoalescedStartRevision |= int64(b&0x7F) << shift
+				if b < 0x80 {
+					break
+				}
+			}
 		default:
 			iNdEx = preIndex
 			skippy, err := skipKv(dAtA[iNdEx:])
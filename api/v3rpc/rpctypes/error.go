@@ -33,6 +33,20 @@ var (
 	ErrGRPCFutureRev               = status.New(codes.OutOfRange, "etcdserver: mvcc: required revision is a future revision").Err()
 	ErrGRPCNoSpace                 = status.New(codes.ResourceExhausted, "etcdserver: mvcc: database space exceeded").Err()
 
+	ErrGRPCKeyQuotaExceeded = status.New(codes.ResourceExhausted, "etcdserver: key quota exceeded").Err()
+	ErrGRPCKeyQuotaNotFound = status.New(codes.NotFound, "etcdserver: key quota not found").Err()
+	ErrGRPCKeyQuotaInvalid  = status.New(codes.InvalidArgument, "etcdserver: invalid key quota").Err()
+
+	ErrGRPCInvalidTTL  = status.New(codes.InvalidArgument, "etcdserver: ttl must not be negative").Err()
+	ErrGRPCTTLProvided = status.New(codes.InvalidArgument, "etcdserver: ttl cannot be combined with lease or ignore_lease").Err()
+
+	ErrGRPCInvalidRangeFilter   = status.New(codes.InvalidArgument, "etcdserver: invalid range filter").Err()
+	ErrGRPCInvalidContinueToken = status.New(codes.InvalidArgument, "etcdserver: invalid continue token").Err()
+	ErrGRPCInvalidWatchFilter   = status.New(codes.InvalidArgument, "etcdserver: invalid watch filter").Err()
+	ErrGRPCInvalidMaxStaleness  = status.New(codes.InvalidArgument, "etcdserver: max staleness must not be negative").Err()
+
+	ErrGRPCInvalidCoalesceWindow = status.New(codes.InvalidArgument, "etcdserver: coalesce window must be between 0 and 10s").Err()
+
 	ErrGRPCLeaseNotFound    = status.New(codes.NotFound, "etcdserver: requested lease not found").Err()
 	ErrGRPCLeaseExist       = status.New(codes.FailedPrecondition, "etcdserver: lease already exists").Err()
 	ErrGRPCLeaseTTLTooLarge = status.New(codes.OutOfRange, "etcdserver: too large lease TTL").Err()
@@ -47,6 +61,10 @@ var (
 	ErrGRPCMemberNotLearner       = status.New(codes.FailedPrecondition, "etcdserver: can only promote a learner member").Err()
 	ErrGRPCLearnerNotReady        = status.New(codes.FailedPrecondition, "etcdserver: can only promote a learner member which is in sync with leader").Err()
 	ErrGRPCTooManyLearners        = status.New(codes.FailedPrecondition, "etcdserver: too many learner members in cluster").Err()
+	ErrGRPCObserverNotPromotable  = status.New(codes.FailedPrecondition, "etcdserver: cannot promote an observer member").Err()
+	ErrGRPCTooManyObservers       = status.New(codes.FailedPrecondition, "etcdserver: too many observer members in cluster").Err()
+	ErrGRPCObserverNotLearner     = status.New(codes.FailedPrecondition, "etcdserver: observer member must be added as a learner").Err()
+	ErrGRPCInvalidSnapshotSource  = status.New(codes.FailedPrecondition, "etcdserver: snapshot source must be a voting member").Err()
 
 	ErrGRPCRequestTooLarge        = status.New(codes.InvalidArgument, "etcdserver: request is too large").Err()
 	ErrGRPCRequestTooManyRequests = status.New(codes.ResourceExhausted, "etcdserver: too many requests").Err()
@@ -69,6 +87,14 @@ var (
 	ErrGRPCInvalidAuthMgmt      = status.New(codes.InvalidArgument, "etcdserver: invalid auth management").Err()
 	ErrGRPCAuthOldRevision      = status.New(codes.InvalidArgument, "etcdserver: revision of auth store is old").Err()
 
+	ErrGRPCTenantEmpty          = status.New(codes.InvalidArgument, "etcdserver: tenant name is empty").Err()
+	ErrGRPCTenantPrefixEmpty    = status.New(codes.InvalidArgument, "etcdserver: tenant prefix is empty").Err()
+	ErrGRPCTenantAlreadyExist   = status.New(codes.FailedPrecondition, "etcdserver: tenant name already exists").Err()
+	ErrGRPCTenantNotFound       = status.New(codes.FailedPrecondition, "etcdserver: tenant name not found").Err()
+	ErrGRPCTenantPrefixConflict = status.New(codes.FailedPrecondition, "etcdserver: tenant prefix overlaps the prefix of another tenant").Err()
+	ErrGRPCTenantNotEmpty       = status.New(codes.FailedPrecondition, "etcdserver: tenant still has users or roles").Err()
+	ErrGRPCTenantMismatch       = status.New(codes.FailedPrecondition, "etcdserver: user and role belong to different tenants").Err()
+
 	ErrGRPCNoLeader                   = status.New(codes.Unavailable, "etcdserver: no leader").Err()
 	ErrGRPCNotLeader                  = status.New(codes.FailedPrecondition, "etcdserver: not leader").Err()
 	ErrGRPCLeaderChanged              = status.New(codes.Unavailable, "etcdserver: leader changed").Err()
@@ -89,6 +115,15 @@ var (
 	ErrGRPCDowngradeInProcess            = status.New(codes.FailedPrecondition, "etcdserver: cluster has a downgrade job in progress").Err()
 	ErrGRPCNoInflightDowngrade           = status.New(codes.FailedPrecondition, "etcdserver: no inflight downgrade job").Err()
 
+	ErrGRPCSnapshotSessionNotFound = status.New(codes.NotFound, "etcdserver: snapshot session not found").Err()
+	ErrGRPCSnapshotSessionBusy     = status.New(codes.Unavailable, "etcdserver: snapshot session is being streamed").Err()
+	ErrGRPCInvalidSnapshotOffset   = status.New(codes.InvalidArgument, "etcdserver: invalid snapshot offset").Err()
+
+	ErrGRPCEncryptionDisabled = status.New(codes.FailedPrecondition, "etcdserver: encryption at rest is not enabled").Err()
+
+	ErrGRPCReadSetNotSupported = status.New(codes.FailedPrecondition, "etcdserver: read set requires cluster version 3.6").Err()
+	ErrGRPCKeyTTLNotSupported  = status.New(codes.FailedPrecondition, "etcdserver: key ttl requires cluster version 3.6").Err()
+
 	ErrGRPCCanceled         = status.New(codes.Canceled, "etcdserver: request canceled").Err()
 	ErrGRPCDeadlineExceeded = status.New(codes.DeadlineExceeded, "etcdserver: context deadline exceeded").Err()
 
@@ -105,6 +140,20 @@ var (
 		ErrorDesc(ErrGRPCFutureRev):         ErrGRPCFutureRev,
 		ErrorDesc(ErrGRPCNoSpace):           ErrGRPCNoSpace,
 
+		ErrorDesc(ErrGRPCKeyQuotaExceeded): ErrGRPCKeyQuotaExceeded,
+		ErrorDesc(ErrGRPCKeyQuotaNotFound): ErrGRPCKeyQuotaNotFound,
+		ErrorDesc(ErrGRPCKeyQuotaInvalid):  ErrGRPCKeyQuotaInvalid,
+
+		ErrorDesc(ErrGRPCInvalidTTL):  ErrGRPCInvalidTTL,
+		ErrorDesc(ErrGRPCTTLProvided): ErrGRPCTTLProvided,
+
+		ErrorDesc(ErrGRPCInvalidRangeFilter):   ErrGRPCInvalidRangeFilter,
+		ErrorDesc(ErrGRPCInvalidContinueToken): ErrGRPCInvalidContinueToken,
+		ErrorDesc(ErrGRPCInvalidWatchFilter):   ErrGRPCInvalidWatchFilter,
+		ErrorDesc(ErrGRPCInvalidMaxStaleness):  ErrGRPCInvalidMaxStaleness,
+
+		ErrorDesc(ErrGRPCInvalidCoalesceWindow): ErrGRPCInvalidCoalesceWindow,
+
 		ErrorDesc(ErrGRPCLeaseNotFound):    ErrGRPCLeaseNotFound,
 		ErrorDesc(ErrGRPCLeaseExist):       ErrGRPCLeaseExist,
 		ErrorDesc(ErrGRPCLeaseTTLTooLarge): ErrGRPCLeaseTTLTooLarge,
@@ -117,6 +166,10 @@ var (
 		ErrorDesc(ErrGRPCMemberNotLearner):       ErrGRPCMemberNotLearner,
 		ErrorDesc(ErrGRPCLearnerNotReady):        ErrGRPCLearnerNotReady,
 		ErrorDesc(ErrGRPCTooManyLearners):        ErrGRPCTooManyLearners,
+		ErrorDesc(ErrGRPCObserverNotPromotable):  ErrGRPCObserverNotPromotable,
+		ErrorDesc(ErrGRPCTooManyObservers):       ErrGRPCTooManyObservers,
+		ErrorDesc(ErrGRPCObserverNotLearner):     ErrGRPCObserverNotLearner,
+		ErrorDesc(ErrGRPCInvalidSnapshotSource):  ErrGRPCInvalidSnapshotSource,
 
 		ErrorDesc(ErrGRPCRequestTooLarge):        ErrGRPCRequestTooLarge,
 		ErrorDesc(ErrGRPCRequestTooManyRequests): ErrGRPCRequestTooManyRequests,
@@ -138,6 +191,14 @@ var (
 		ErrorDesc(ErrGRPCInvalidAuthMgmt):      ErrGRPCInvalidAuthMgmt,
 		ErrorDesc(ErrGRPCAuthOldRevision):      ErrGRPCAuthOldRevision,
 
+		ErrorDesc(ErrGRPCTenantEmpty):          ErrGRPCTenantEmpty,
+		ErrorDesc(ErrGRPCTenantPrefixEmpty):    ErrGRPCTenantPrefixEmpty,
+		ErrorDesc(ErrGRPCTenantAlreadyExist):   ErrGRPCTenantAlreadyExist,
+		ErrorDesc(ErrGRPCTenantNotFound):       ErrGRPCTenantNotFound,
+		ErrorDesc(ErrGRPCTenantPrefixConflict): ErrGRPCTenantPrefixConflict,
+		ErrorDesc(ErrGRPCTenantNotEmpty):       ErrGRPCTenantNotEmpty,
+		ErrorDesc(ErrGRPCTenantMismatch):       ErrGRPCTenantMismatch,
+
 		ErrorDesc(ErrGRPCNoLeader):                   ErrGRPCNoLeader,
 		ErrorDesc(ErrGRPCNotLeader):                  ErrGRPCNotLeader,
 		ErrorDesc(ErrGRPCLeaderChanged):              ErrGRPCLeaderChanged,
@@ -156,6 +217,15 @@ var (
 		ErrorDesc(ErrGRPCInvalidDowngradeTargetVersion): ErrGRPCInvalidDowngradeTargetVersion,
 		ErrorDesc(ErrGRPCDowngradeInProcess):            ErrGRPCDowngradeInProcess,
 		ErrorDesc(ErrGRPCNoInflightDowngrade):           ErrGRPCNoInflightDowngrade,
+
+		ErrorDesc(ErrGRPCSnapshotSessionNotFound): ErrGRPCSnapshotSessionNotFound,
+		ErrorDesc(ErrGRPCSnapshotSessionBusy):     ErrGRPCSnapshotSessionBusy,
+		ErrorDesc(ErrGRPCInvalidSnapshotOffset):   ErrGRPCInvalidSnapshotOffset,
+
+		ErrorDesc(ErrGRPCEncryptionDisabled): ErrGRPCEncryptionDisabled,
+
+		ErrorDesc(ErrGRPCReadSetNotSupported): ErrGRPCReadSetNotSupported,
+		ErrorDesc(ErrGRPCKeyTTLNotSupported):  ErrGRPCKeyTTLNotSupported,
 	}
 )
 
@@ -172,6 +242,20 @@ var (
 	ErrFutureRev         = Error(ErrGRPCFutureRev)
 	ErrNoSpace           = Error(ErrGRPCNoSpace)
 
+	ErrKeyQuotaExceeded = Error(ErrGRPCKeyQuotaExceeded)
+	ErrKeyQuotaNotFound = Error(ErrGRPCKeyQuotaNotFound)
+	ErrKeyQuotaInvalid  = Error(ErrGRPCKeyQuotaInvalid)
+
+	ErrInvalidTTL  = Error(ErrGRPCInvalidTTL)
+	ErrTTLProvided = Error(ErrGRPCTTLProvided)
+
+	ErrInvalidRangeFilter   = Error(ErrGRPCInvalidRangeFilter)
+	ErrInvalidContinueToken = Error(ErrGRPCInvalidContinueToken)
+	ErrInvalidWatchFilter   = Error(ErrGRPCInvalidWatchFilter)
+	ErrInvalidMaxStaleness  = Error(ErrGRPCInvalidMaxStaleness)
+
+	ErrInvalidCoalesceWindow = Error(ErrGRPCInvalidCoalesceWindow)
+
 	ErrLeaseNotFound    = Error(ErrGRPCLeaseNotFound)
 	ErrLeaseExist       = Error(ErrGRPCLeaseExist)
 	ErrLeaseTTLTooLarge = Error(ErrGRPCLeaseTTLTooLarge)
@@ -184,6 +268,10 @@ var (
 	ErrMemberNotLearner       = Error(ErrGRPCMemberNotLearner)
 	ErrMemberLearnerNotReady  = Error(ErrGRPCLearnerNotReady)
 	ErrTooManyLearners        = Error(ErrGRPCTooManyLearners)
+	ErrObserverNotPromotable  = Error(ErrGRPCObserverNotPromotable)
+	ErrTooManyObservers       = Error(ErrGRPCTooManyObservers)
+	ErrObserverNotLearner     = Error(ErrGRPCObserverNotLearner)
+	ErrInvalidSnapshotSource  = Error(ErrGRPCInvalidSnapshotSource)
 
 	ErrRequestTooLarge = Error(ErrGRPCRequestTooLarge)
 	ErrTooManyRequests = Error(ErrGRPCRequestTooManyRequests)
@@ -205,6 +293,14 @@ var (
 	ErrAuthOldRevision      = Error(ErrGRPCAuthOldRevision)
 	ErrInvalidAuthMgmt      = Error(ErrGRPCInvalidAuthMgmt)
 
+	ErrTenantEmpty          = Error(ErrGRPCTenantEmpty)
+	ErrTenantPrefixEmpty    = Error(ErrGRPCTenantPrefixEmpty)
+	ErrTenantAlreadyExist   = Error(ErrGRPCTenantAlreadyExist)
+	ErrTenantNotFound       = Error(ErrGRPCTenantNotFound)
+	ErrTenantPrefixConflict = Error(ErrGRPCTenantPrefixConflict)
+	ErrTenantNotEmpty       = Error(ErrGRPCTenantNotEmpty)
+	ErrTenantMismatch       = Error(ErrGRPCTenantMismatch)
+
 	ErrNoLeader                   = Error(ErrGRPCNoLeader)
 	ErrNotLeader                  = Error(ErrGRPCNotLeader)
 	ErrLeaderChanged              = Error(ErrGRPCLeaderChanged)
@@ -223,6 +319,15 @@ var (
 	ErrInvalidDowngradeTargetVersion = Error(ErrGRPCInvalidDowngradeTargetVersion)
 	ErrDowngradeInProcess            = Error(ErrGRPCDowngradeInProcess)
 	ErrNoInflightDowngrade           = Error(ErrGRPCNoInflightDowngrade)
+
+	ErrSnapshotSessionNotFound = Error(ErrGRPCSnapshotSessionNotFound)
+	ErrSnapshotSessionBusy     = Error(ErrGRPCSnapshotSessionBusy)
+	ErrInvalidSnapshotOffset   = Error(ErrGRPCInvalidSnapshotOffset)
+
+	ErrEncryptionDisabled = Error(ErrGRPCEncryptionDisabled)
+
+	ErrReadSetNotSupported = Error(ErrGRPCReadSetNotSupported)
+	ErrKeyTTLNotSupported  = Error(ErrGRPCKeyTTLNotSupported)
 )
 
 // EtcdError defines gRPC server errors.
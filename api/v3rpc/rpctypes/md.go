@@ -19,4 +19,7 @@ var (
 	MetadataHasLeader        = "true"
 
 	MetadataClientAPIVersionKey = "client-api-version"
+
+	MetadataWatchCompressionKey  = "watch-compression"
+	MetadataWatchCompressionGzip = "gzip"
 )
@@ -43,6 +43,9 @@ type (
 	AuthRoleDeleteResponse           pb.AuthRoleDeleteResponse
 	AuthUserListResponse             pb.AuthUserListResponse
 	AuthRoleListResponse             pb.AuthRoleListResponse
+	AuthTenantAddResponse            pb.AuthTenantAddResponse
+	AuthTenantDeleteResponse         pb.AuthTenantDeleteResponse
+	AuthTenantListResponse           pb.AuthTenantListResponse
 
 	PermissionType authpb.Permission_Type
 	Permission     authpb.Permission
@@ -52,6 +55,10 @@ const (
 	PermRead      = authpb.READ
 	PermWrite     = authpb.WRITE
 	PermReadWrite = authpb.READWRITE
+	PermWatch     = authpb.WATCH
+	PermDelete    = authpb.DELETE
+	PermLease     = authpb.LEASE
+	PermAdmin     = authpb.ADMIN
 )
 
 type UserAddOptions authpb.UserAddOptions
@@ -108,8 +115,30 @@ type Auth interface {
 	// RoleRevokePermission revokes a permission from a role.
 	RoleRevokePermission(ctx context.Context, role string, key, rangeEnd string) (*AuthRoleRevokePermissionResponse, error)
 
+	// RoleDenyPermission adds a deny rule to a role. A deny rule overrides the
+	// permissions granting the same operations on overlapping keys.
+	RoleDenyPermission(ctx context.Context, name string, key, rangeEnd string, permType PermissionType) (*AuthRoleGrantPermissionResponse, error)
+
+	// RoleRevokeDenyPermission removes a deny rule from a role.
+	RoleRevokeDenyPermission(ctx context.Context, role string, key, rangeEnd string) (*AuthRoleRevokePermissionResponse, error)
+
 	// RoleDelete deletes a role.
 	RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error)
+
+	// TenantAdd adds a new tenant whose users are confined to the keys under prefix.
+	TenantAdd(ctx context.Context, name string, prefix string) (*AuthTenantAddResponse, error)
+
+	// TenantDelete deletes a tenant. The tenant must not have any users or roles.
+	TenantDelete(ctx context.Context, name string) (*AuthTenantDeleteResponse, error)
+
+	// TenantList gets a list of all tenants.
+	TenantList(ctx context.Context) (*AuthTenantListResponse, error)
+
+	// TenantUserAdd adds a new user to a tenant with some options.
+	TenantUserAdd(ctx context.Context, tenant string, name string, password string, opt *UserAddOptions) (*AuthUserAddResponse, error)
+
+	// TenantRoleAdd adds a new role to a tenant. Permissions granted to the role are relative to the tenant prefix.
+	TenantRoleAdd(ctx context.Context, tenant string, name string) (*AuthRoleAddResponse, error)
 }
 
 type authClient struct {
@@ -223,11 +252,52 @@ func (auth *authClient) RoleRevokePermission(ctx context.Context, role string, k
 	return (*AuthRoleRevokePermissionResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) RoleDenyPermission(ctx context.Context, name string, key, rangeEnd string, permType PermissionType) (*AuthRoleGrantPermissionResponse, error) {
+	perm := &authpb.Permission{
+		Key:      []byte(key),
+		RangeEnd: []byte(rangeEnd),
+		PermType: authpb.Permission_Type(permType),
+		Deny:     true,
+	}
+	resp, err := auth.remote.RoleGrantPermission(ctx, &pb.AuthRoleGrantPermissionRequest{Name: name, Perm: perm}, auth.callOpts...)
+	return (*AuthRoleGrantPermissionResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) RoleRevokeDenyPermission(ctx context.Context, role string, key, rangeEnd string) (*AuthRoleRevokePermissionResponse, error) {
+	resp, err := auth.remote.RoleRevokePermission(ctx, &pb.AuthRoleRevokePermissionRequest{Role: role, Key: []byte(key), RangeEnd: []byte(rangeEnd), Deny: true}, auth.callOpts...)
+	return (*AuthRoleRevokePermissionResponse)(resp), toErr(ctx, err)
+}
+
 func (auth *authClient) RoleDelete(ctx context.Context, role string) (*AuthRoleDeleteResponse, error) {
 	resp, err := auth.remote.RoleDelete(ctx, &pb.AuthRoleDeleteRequest{Role: role}, auth.callOpts...)
 	return (*AuthRoleDeleteResponse)(resp), toErr(ctx, err)
 }
 
+func (auth *authClient) TenantAdd(ctx context.Context, name string, prefix string) (*AuthTenantAddResponse, error) {
+	resp, err := auth.remote.TenantAdd(ctx, &pb.AuthTenantAddRequest{Name: name, Prefix: []byte(prefix)}, auth.callOpts...)
+	return (*AuthTenantAddResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) TenantDelete(ctx context.Context, name string) (*AuthTenantDeleteResponse, error) {
+	resp, err := auth.remote.TenantDelete(ctx, &pb.AuthTenantDeleteRequest{Name: name}, auth.callOpts...)
+	return (*AuthTenantDeleteResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) TenantList(ctx context.Context) (*AuthTenantListResponse, error) {
+	resp, err := auth.remote.TenantList(ctx, &pb.AuthTenantListRequest{}, auth.callOpts...)
+	return (*AuthTenantListResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) TenantUserAdd(ctx context.Context, tenant string, name string, password string, options *UserAddOptions) (*AuthUserAddResponse, error) {
+	resp, err := auth.remote.UserAdd(ctx, &pb.AuthUserAddRequest{Name: name, Password: password, Options: (*authpb.UserAddOptions)(options), Tenant: tenant}, auth.callOpts...)
+	return (*AuthUserAddResponse)(resp), toErr(ctx, err)
+}
+
+func (auth *authClient) TenantRoleAdd(ctx context.Context, tenant string, name string) (*AuthRoleAddResponse, error) {
+	resp, err := auth.remote.RoleAdd(ctx, &pb.AuthRoleAddRequest{Name: name, Tenant: tenant}, auth.callOpts...)
+	return (*AuthRoleAddResponse)(resp), toErr(ctx, err)
+}
+
 func StrToPermissionType(s string) (PermissionType, error) {
 	val, ok := authpb.Permission_Type_value[strings.ToUpper(s)]
 	if ok {
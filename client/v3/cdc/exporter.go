@@ -0,0 +1,221 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cdc implements the export of the changes of etcd key-values, for
+// change data capture.
+package cdc
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	defaultBatchSize     = 1000
+	defaultFlushInterval = time.Second
+	// retryDelay is the delay before watching again after a failure.
+	retryDelay = 500 * time.Millisecond
+)
+
+// Config configures an Exporter.
+type Config struct {
+	// Prefix is the prefix of the exported keys, or empty for every key.
+	Prefix string
+	Format Format
+	// Source is the source attribute of CloudEvents, "etcd://<cluster ID>"
+	// if empty.
+	Source string
+	// PrevKV exports the previous key-values of the events.
+	PrevKV bool
+	// StartRevision is the first revision exported when the sink has no
+	// cursor. The revisions after the current one are exported if zero.
+	StartRevision int64
+	// BatchSize is the number of events from which the exporter flushes the
+	// events to the sink, at a revision boundary. 1000 is used if zero.
+	BatchSize int
+	// FlushInterval is the interval at which the exporter flushes the
+	// events to the sink. A second is used if zero.
+	FlushInterval time.Duration
+	Logger        *zap.Logger
+}
+
+// Exporter exports the events of the keys under a prefix to a sink. The events
+// of a revision are written at once, after the events of the previous
+// revisions, and the export resumes after the cursor of the sink.
+type Exporter struct {
+	c    *clientv3.Client
+	sink Sink
+	cfg  Config
+	lg   *zap.Logger
+}
+
+// NewExporter returns an exporter of the changes of the cluster of the given
+// client to the given sink. The export starts with Run.
+func NewExporter(c *clientv3.Client, sink Sink, cfg Config) (*Exporter, error) {
+	if _, err := newEncoder(cfg.Format, cfg.Source); err != nil {
+		return nil, err
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = defaultBatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = defaultFlushInterval
+	}
+	lg := cfg.Logger
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &Exporter{c: c, sink: sink, cfg: cfg, lg: lg}, nil
+}
+
+// Run exports the changes until the context is done, and returns the error
+// of the context, rpctypes.ErrCompacted if the revisions to export are
+// compacted, or the error of the sink or of the encoding of the events. Only
+// the failed watches are retried. The buffered events are flushed before
+// returning.
+func (e *Exporter) Run(ctx context.Context) error {
+	resp, err := e.c.Get(ctx, e.cfg.Prefix, clientv3.WithCountOnly())
+	if err != nil {
+		return err
+	}
+	source := e.cfg.Source
+	if source == "" {
+		source = fmt.Sprintf("etcd://%x", resp.Header.ClusterId)
+	}
+	enc, _ := newEncoder(e.cfg.Format, source)
+
+	rev := e.sink.Cursor()
+	if rev == 0 {
+		rev = resp.Header.Revision
+		if e.cfg.StartRevision > 0 {
+			rev = e.cfg.StartRevision - 1
+		}
+	}
+	for {
+		err = e.watch(ctx, enc, &rev)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var xerr *exportError
+		if errors.As(err, &xerr) {
+			return xerr.err
+		}
+		if errors.Is(err, rpctypes.ErrCompacted) {
+			return err
+		}
+		e.lg.Warn("change export failed; retrying", zap.Int64("revision", rev), zap.Error(err))
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// exportError is an error of the sink or of the encoding of the events, which
+// watching again would not fix.
+type exportError struct {
+	err error
+}
+
+func (e *exportError) Error() string { return e.err.Error() }
+
+func (e *exportError) Unwrap() error { return e.err }
+
+// watch exports the events after the given revision until the watch fails,
+// and moves the revision as the events are flushed. The errors of the sink
+// and of the encoding are returned as exportErrors.
+func (e *Exporter) watch(ctx context.Context, enc *encoder, rev *int64) error {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	opts := []clientv3.OpOption{clientv3.WithPrefix(), clientv3.WithRev(*rev + 1), clientv3.WithProgressNotify()}
+	if e.cfg.PrevKV {
+		opts = append(opts, clientv3.WithPrevKV())
+	}
+	wch := e.c.Watch(wctx, e.cfg.Prefix, opts...)
+
+	ticker := time.NewTicker(e.cfg.FlushInterval)
+	defer ticker.Stop()
+	var (
+		buf bytes.Buffer
+		n   int
+		// last is the revision of the buffered events
+		last = *rev
+	)
+	flush := func() error {
+		if last == *rev {
+			return nil
+		}
+		if err := e.sink.Write(buf.Bytes(), last); err != nil {
+			return &exportError{err}
+		}
+		buf.Reset()
+		n, *rev = 0, last
+		return nil
+	}
+	for {
+		select {
+		case wresp, ok := <-wch:
+			if !ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				return ctx.Err()
+			}
+			if wresp.CompactRevision != 0 {
+				if err := flush(); err != nil {
+					return err
+				}
+				return rpctypes.ErrCompacted
+			}
+			if err := wresp.Err(); err != nil {
+				if ferr := flush(); ferr != nil {
+					return ferr
+				}
+				return err
+			}
+			// a watch response holds every event of its revisions
+			for _, ev := range wresp.Events {
+				if err := enc.encode(&buf, (*mvccpb.Event)(ev)); err != nil {
+					return &exportError{err}
+				}
+				n++
+				last = ev.Kv.ModRevision
+			}
+			if wresp.IsProgressNotify() && wresp.Header.Revision > last {
+				// moves the cursor past the revisions without events, which
+				// may be compacted
+				last = wresp.Header.Revision
+			}
+			if n >= e.cfg.BatchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+		case <-ticker.C:
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+}
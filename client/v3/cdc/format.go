@@ -0,0 +1,123 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// Format is the encoding of the exported events.
+type Format string
+
+const (
+	// FormatJSON encodes every event as a JSON object on its own line.
+	FormatJSON Format = "json"
+	// FormatCloudEvents encodes every event as a CloudEvents 1.0 JSON object
+	// on its own line, holding the JSON object of FormatJSON as data.
+	FormatCloudEvents Format = "cloudevents"
+	// FormatProtobuf encodes every event as a mvccpb.Event message, prefixed
+	// by its size as a varint.
+	FormatProtobuf Format = "protobuf"
+)
+
+// Ext returns the file name extension of the format.
+func (f Format) Ext() string {
+	if f == FormatProtobuf {
+		return ".pb"
+	}
+	return ".ndjson"
+}
+
+// jsonEvent is the JSON object of an event. The keys and values are base64
+// encoded, as in the JSON output of etcdctl.
+type jsonEvent struct {
+	Type   string           `json:"type"`
+	Kv     *mvccpb.KeyValue `json:"kv"`
+	PrevKv *mvccpb.KeyValue `json:"prev_kv,omitempty"`
+}
+
+// cloudEvent is the CloudEvents 1.0 JSON object of an event.
+type cloudEvent struct {
+	SpecVersion     string    `json:"specversion"`
+	ID              string    `json:"id"`
+	Source          string    `json:"source"`
+	Type            string    `json:"type"`
+	Subject         string    `json:"subject"`
+	DataContentType string    `json:"datacontenttype"`
+	Revision        int64     `json:"etcdrevision"`
+	Data            jsonEvent `json:"data"`
+}
+
+// encoder appends encoded events to a buffer.
+type encoder struct {
+	format Format
+	// source is the source attribute of CloudEvents.
+	source string
+}
+
+func newEncoder(format Format, source string) (*encoder, error) {
+	switch format {
+	case FormatJSON, FormatCloudEvents, FormatProtobuf:
+		return &encoder{format: format, source: source}, nil
+	default:
+		return nil, fmt.Errorf("cdc: unknown format %q", format)
+	}
+}
+
+func (enc *encoder) encode(buf *bytes.Buffer, ev *mvccpb.Event) error {
+	var v interface{}
+	je := jsonEvent{Type: ev.Type.String(), Kv: ev.Kv, PrevKv: ev.PrevKv}
+	switch enc.format {
+	case FormatProtobuf:
+		b, err := ev.Marshal()
+		if err != nil {
+			return err
+		}
+		var size [binary.MaxVarintLen64]byte
+		buf.Write(size[:binary.PutUvarint(size[:], uint64(len(b)))])
+		buf.Write(b)
+		return nil
+	case FormatCloudEvents:
+		typ := "io.etcd.kv.put"
+		if ev.Type == mvccpb.DELETE {
+			typ = "io.etcd.kv.delete"
+		}
+		v = &cloudEvent{
+			SpecVersion: "1.0",
+			// a key changes once per revision
+			ID:              fmt.Sprintf("%d/%x", ev.Kv.ModRevision, ev.Kv.Key),
+			Source:          enc.source,
+			Type:            typ,
+			Subject:         string(ev.Kv.Key),
+			DataContentType: "application/json",
+			Revision:        ev.Kv.ModRevision,
+			Data:            je,
+		}
+	default:
+		v = &je
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	buf.Write(b)
+	buf.WriteByte('\n')
+	return nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestEncoder(t *testing.T) {
+	ev := &mvccpb.Event{
+		Type:   mvccpb.PUT,
+		Kv:     &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte("bar"), CreateRevision: 2, ModRevision: 3, Version: 2},
+		PrevKv: &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte("baz"), CreateRevision: 2, ModRevision: 2, Version: 1},
+	}
+	tests := []struct {
+		format Format
+		want   string
+	}{
+		{
+			FormatJSON,
+			`{"type":"PUT","kv":{"key":"Zm9v","create_revision":2,"mod_revision":3,"version":2,"value":"YmFy"},"prev_kv":{"key":"Zm9v","create_revision":2,"mod_revision":2,"version":1,"value":"YmF6"}}` + "\n",
+		},
+		{
+			FormatCloudEvents,
+			`{"specversion":"1.0","id":"3/666f6f","source":"etcd://test","type":"io.etcd.kv.put","subject":"foo","datacontenttype":"application/json","etcdrevision":3,"data":{"type":"PUT","kv":{"key":"Zm9v","create_revision":2,"mod_revision":3,"version":2,"value":"YmFy"},"prev_kv":{"key":"Zm9v","create_revision":2,"mod_revision":2,"version":1,"value":"YmF6"}}}` + "\n",
+		},
+	}
+	for _, tt := range tests {
+		enc, err := newEncoder(tt.format, "etcd://test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err = enc.encode(&buf, ev); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("%s: encoded %s, want %s", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestEncoderProtobuf(t *testing.T) {
+	evs := []*mvccpb.Event{
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("foo"), Value: []byte("bar"), CreateRevision: 2, ModRevision: 2, Version: 1}},
+		{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("foo"), ModRevision: 3}},
+	}
+	enc, err := newEncoder(FormatProtobuf, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	for _, ev := range evs {
+		if err = enc.encode(&buf, ev); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, want := range evs {
+		size, err := binary.ReadUvarint(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ev mvccpb.Event
+		if err = ev.Unmarshal(buf.Next(int(size))); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(&ev, want) {
+			t.Errorf("decoded %v, want %v", &ev, want)
+		}
+	}
+	if buf.Len() != 0 {
+		t.Errorf("%d bytes left", buf.Len())
+	}
+}
+
+func TestUnknownFormat(t *testing.T) {
+	if _, err := newEncoder("xml", ""); err == nil {
+		t.Fatal("expected an error")
+	}
+}
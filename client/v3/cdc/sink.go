@@ -0,0 +1,303 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+)
+
+const (
+	// DefaultMaxFileSize is the size from which a file sink rotates files.
+	DefaultMaxFileSize = 64 * 1024 * 1024
+
+	cursorFileName = "cursor"
+	lockFileName   = "LOCK"
+)
+
+// Sink persists the exported events with the revision cursor.
+type Sink interface {
+	// Cursor returns the last revision whose events were written, or zero if
+	// none were.
+	Cursor() int64
+	// Write writes the encoded events of the revisions up to rev, which may
+	// be none, and moves the cursor to rev.
+	Write(p []byte, rev int64) error
+	Close() error
+}
+
+// cursor is the persisted revision cursor.
+type cursor struct {
+	Revision int64 `json:"revision"`
+	// File is the name of the file being written to by a file sink, and
+	// Offset the size of the events written to it up to Revision.
+	File   string `json:"file,omitempty"`
+	Offset int64  `json:"offset,omitempty"`
+}
+
+// readCursor returns the cursor persisted in the given file, or a zero cursor
+// if the file does not exist.
+func readCursor(path string) (cursor, error) {
+	var cur cursor
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cur, nil
+	}
+	if err != nil {
+		return cur, err
+	}
+	if err = json.Unmarshal(b, &cur); err != nil {
+		return cur, fmt.Errorf("cdc: invalid cursor file %q: %w", path, err)
+	}
+	return cur, nil
+}
+
+// writeCursor atomically replaces the cursor persisted in the given file.
+func writeCursor(path string, cur cursor) error {
+	b, err := json.Marshal(cur)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = fileutil.Fsync(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+func syncDir(dir string) error {
+	d, err := fileutil.OpenDir(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return fileutil.Fsync(d)
+}
+
+// FileSinkConfig configures a FileSink.
+type FileSinkConfig struct {
+	// Dir is the directory of the files and of the cursor.
+	Dir string
+	// Ext is the file name extension of the files, the one of FormatJSON if
+	// empty.
+	Ext string
+	// MaxFileSize is the size from which the files are rotated.
+	// DefaultMaxFileSize is used if zero.
+	MaxFileSize int64
+}
+
+// FileSink writes the events to rotating files in a directory, named after
+// their sequence number. The cursor file of the directory holds the revision
+// of the last events written, and the size of the file they were written to,
+// so that the events written after it are dropped on resume. The events are
+// thereby exported exactly once.
+type FileSink struct {
+	cfg  FileSinkConfig
+	lock *fileutil.LockedFile
+	f    *os.File
+	cur  cursor
+	// err is the error of a failed write, after which the sink fails until
+	// reopened, as the file may hold events after the cursor.
+	err error
+}
+
+// NewFileSink opens the file sink of the given directory, creating it if
+// needed, and drops the events written after its cursor.
+func NewFileSink(cfg FileSinkConfig) (*FileSink, error) {
+	if cfg.Ext == "" {
+		cfg.Ext = FormatJSON.Ext()
+	}
+	if cfg.MaxFileSize == 0 {
+		cfg.MaxFileSize = DefaultMaxFileSize
+	}
+	if err := os.MkdirAll(cfg.Dir, fileutil.PrivateDirMode); err != nil {
+		return nil, err
+	}
+	lock, err := fileutil.TryLockFile(filepath.Join(cfg.Dir, lockFileName), os.O_WRONLY|os.O_CREATE, fileutil.PrivateFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("cdc: failed to lock %q: %w", cfg.Dir, err)
+	}
+	s := &FileSink{cfg: cfg, lock: lock}
+	if err = s.open(); err != nil {
+		lock.Close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *FileSink) open() error {
+	cursorPath := filepath.Join(s.cfg.Dir, cursorFileName)
+	cur, err := readCursor(cursorPath)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(s.cfg.Dir)
+	if err != nil {
+		return err
+	}
+	// the entries are sorted by name, and so by sequence number
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasSuffix(name, s.cfg.Ext) {
+			continue
+		}
+		path := filepath.Join(s.cfg.Dir, name)
+		switch {
+		case cur.File == "":
+			// a file created before the cursor, when the sink is new
+			if fi, err := os.Stat(path); err != nil || fi.Size() != 0 {
+				return fmt.Errorf("cdc: file %q without cursor", path)
+			}
+		case name == cur.File:
+			if err = os.Truncate(path, cur.Offset); err != nil {
+				return err
+			}
+			continue
+		case name < cur.File:
+			continue
+		}
+		// written after the cursor
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+	}
+
+	if cur.File == "" {
+		cur.File = s.fileName(1)
+	}
+	s.cur = cur
+	if s.f, err = os.OpenFile(filepath.Join(s.cfg.Dir, cur.File), os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileutil.PrivateFileMode); err != nil {
+		return err
+	}
+	if err = syncDir(s.cfg.Dir); err != nil {
+		return err
+	}
+	return writeCursor(cursorPath, s.cur)
+}
+
+func (s *FileSink) fileName(seq uint64) string { return fmt.Sprintf("%016x%s", seq, s.cfg.Ext) }
+
+func (s *FileSink) Cursor() int64 { return s.cur.Revision }
+
+func (s *FileSink) Write(p []byte, rev int64) error {
+	if s.err == nil {
+		s.err = s.write(p, rev)
+	}
+	return s.err
+}
+
+func (s *FileSink) write(p []byte, rev int64) error {
+	if len(p) != 0 {
+		if _, err := s.f.Write(p); err != nil {
+			return err
+		}
+		if err := fileutil.Fdatasync(s.f); err != nil {
+			return err
+		}
+	}
+	s.cur.Revision = rev
+	s.cur.Offset += int64(len(p))
+	if s.cur.Offset >= s.cfg.MaxFileSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+	return writeCursor(filepath.Join(s.cfg.Dir, cursorFileName), s.cur)
+}
+
+// rotate moves the sink to the next file.
+func (s *FileSink) rotate() error {
+	var seq uint64
+	if _, err := fmt.Sscanf(s.cur.File, "%016x", &seq); err != nil {
+		return err
+	}
+	name := s.fileName(seq + 1)
+	f, err := os.OpenFile(filepath.Join(s.cfg.Dir, name), os.O_WRONLY|os.O_CREATE|os.O_APPEND, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	if err = syncDir(s.cfg.Dir); err != nil {
+		f.Close()
+		return err
+	}
+	s.f.Close()
+	s.f, s.cur.File, s.cur.Offset = f, name, 0
+	return nil
+}
+
+func (s *FileSink) Close() error {
+	err := s.f.Close()
+	if lerr := s.lock.Close(); err == nil {
+		err = lerr
+	}
+	return err
+}
+
+// WriterSink writes the events to a writer, such as the standard output. The
+// cursor is persisted after the events are written, so the events written
+// after it before a crash are written again on resume.
+type WriterSink struct {
+	w          io.Writer
+	cursorPath string
+	cur        cursor
+}
+
+// NewWriterSink returns a sink writing to the given writer, with the cursor
+// persisted in the given file, if any.
+func NewWriterSink(w io.Writer, cursorPath string) (*WriterSink, error) {
+	s := &WriterSink{w: w, cursorPath: cursorPath}
+	if cursorPath != "" {
+		var err error
+		if s.cur, err = readCursor(cursorPath); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+func (s *WriterSink) Cursor() int64 { return s.cur.Revision }
+
+func (s *WriterSink) Write(p []byte, rev int64) error {
+	if _, err := s.w.Write(p); err != nil {
+		return err
+	}
+	s.cur.Revision = rev
+	if s.cursorPath == "" {
+		return nil
+	}
+	return writeCursor(s.cursorPath, s.cur)
+}
+
+func (s *WriterSink) Close() error { return nil }
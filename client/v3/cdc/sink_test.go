@@ -0,0 +1,109 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cdc
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func readFiles(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := make(map[string]string)
+	for _, e := range entries {
+		if filepath.Ext(e.Name()) != FormatJSON.Ext() {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[e.Name()] = string(b)
+	}
+	return files
+}
+
+func TestFileSinkResume(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewFileSink(FileSinkConfig{Dir: dir, MaxFileSize: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rev := s.Cursor(); rev != 0 {
+		t.Fatalf("cursor = %d, want 0", rev)
+	}
+	for i, w := range []struct {
+		p   string
+		rev int64
+	}{{"aaaa\n", 2}, {"bbbbbbb\n", 3}, {"cc\n", 4}} {
+		if err = s.Write([]byte(w.p), w.rev); err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+	}
+	if _, err = NewFileSink(FileSinkConfig{Dir: dir}); err == nil {
+		t.Fatal("expected the directory to be locked")
+	}
+
+	// events written after the cursor before a crash
+	f, err := os.OpenFile(filepath.Join(dir, "0000000000000002.ndjson"), os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString("dd\n")
+	f.Close()
+	if err = os.WriteFile(filepath.Join(dir, "0000000000000003.ndjson"), []byte("ee\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s, err = NewFileSink(FileSinkConfig{Dir: dir, MaxFileSize: 10}); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	if rev := s.Cursor(); rev != 4 {
+		t.Fatalf("cursor = %d, want 4", rev)
+	}
+	want := map[string]string{
+		"0000000000000001.ndjson": "aaaa\nbbbbbbb\n",
+		"0000000000000002.ndjson": "cc\n",
+	}
+	if files := readFiles(t, dir); !reflect.DeepEqual(files, want) {
+		t.Fatalf("files = %q, want %q", files, want)
+	}
+	if err = s.Write(nil, 6); err != nil {
+		t.Fatal(err)
+	}
+	if rev := s.Cursor(); rev != 6 {
+		t.Fatalf("cursor = %d, want 6", rev)
+	}
+}
+
+func TestFileSinkWithoutCursor(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "0000000000000001.ndjson"), []byte("aa\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFileSink(FileSinkConfig{Dir: dir}); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -394,6 +394,10 @@ func (mm mockMaintenance) Defragment(ctx context.Context, endpoint string) (*Def
 	return nil, nil
 }
 
+func (mm mockMaintenance) DefragmentWithOptions(ctx context.Context, endpoint string, opts ...DefragOption) (*DefragmentResponse, error) {
+	return nil, nil
+}
+
 func (mm mockMaintenance) HashKV(ctx context.Context, endpoint string, rev int64) (*HashKVResponse, error) {
 	return nil, nil
 }
@@ -414,6 +418,30 @@ func (mm mockMaintenance) Downgrade(ctx context.Context, action DowngradeAction,
 	return nil, nil
 }
 
+func (mm mockMaintenance) SnapshotSession(ctx context.Context, sessionID, offset int64) (etcdserverpb.Maintenance_SnapshotClient, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) SnapshotDelta(ctx context.Context, baseRev int64) (etcdserverpb.Maintenance_SnapshotDeltaClient, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) QuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*QuotaSetResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) QuotaDelete(ctx context.Context, prefix string) (*QuotaDeleteResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) QuotaList(ctx context.Context) (*QuotaListResponse, error) {
+	return nil, nil
+}
+
+func (mm mockMaintenance) RotateEncryptionKey(ctx context.Context, endpoint string, rateLimitBytes int64) (*RotateEncryptionKeyResponse, error) {
+	return nil, nil
+}
+
 type mockAuthServer struct {
 	*etcdserverpb.UnimplementedAuthServer
 }
@@ -438,6 +466,10 @@ func (mc *mockCluster) MemberAddAsLearner(ctx context.Context, peerAddrs []strin
 	return nil, nil
 }
 
+func (mc *mockCluster) MemberAddAsObserver(ctx context.Context, peerAddrs []string, snapshotSourceID uint64) (*MemberAddResponse, error) {
+	return nil, nil
+}
+
 func (mc *mockCluster) MemberRemove(ctx context.Context, id uint64) (*MemberRemoveResponse, error) {
 	return nil, nil
 }
@@ -42,6 +42,12 @@ type Cluster interface {
 	// MemberAddAsLearner adds a new learner member into the cluster.
 	MemberAddAsLearner(ctx context.Context, peerAddrs []string) (*MemberAddResponse, error)
 
+	// MemberAddAsObserver adds a new observer member into the cluster. Observers are
+	// raft learners serving serializable reads and watches, which can never be promoted.
+	// If snapshotSourceID is not zero, the voting member with this ID sends the snapshots
+	// to the observer instead of the leader.
+	MemberAddAsObserver(ctx context.Context, peerAddrs []string, snapshotSourceID uint64) (*MemberAddResponse, error)
+
 	// MemberRemove removes an existing member from the cluster.
 	MemberRemove(ctx context.Context, id uint64) (*MemberRemoveResponse, error)
 
@@ -74,23 +80,23 @@ func NewClusterFromClusterClient(remote pb.ClusterClient, c *Client) Cluster {
 }
 
 func (c *cluster) MemberAdd(ctx context.Context, peerAddrs []string) (*MemberAddResponse, error) {
-	return c.memberAdd(ctx, peerAddrs, false)
+	return c.memberAdd(ctx, &pb.MemberAddRequest{PeerURLs: peerAddrs})
 }
 
 func (c *cluster) MemberAddAsLearner(ctx context.Context, peerAddrs []string) (*MemberAddResponse, error) {
-	return c.memberAdd(ctx, peerAddrs, true)
+	return c.memberAdd(ctx, &pb.MemberAddRequest{PeerURLs: peerAddrs, IsLearner: true})
+}
+
+func (c *cluster) MemberAddAsObserver(ctx context.Context, peerAddrs []string, snapshotSourceID uint64) (*MemberAddResponse, error) {
+	return c.memberAdd(ctx, &pb.MemberAddRequest{PeerURLs: peerAddrs, IsLearner: true, IsObserver: true, SnapshotSourceID: snapshotSourceID})
 }
 
-func (c *cluster) memberAdd(ctx context.Context, peerAddrs []string, isLearner bool) (*MemberAddResponse, error) {
+func (c *cluster) memberAdd(ctx context.Context, r *pb.MemberAddRequest) (*MemberAddResponse, error) {
 	// fail-fast before panic in rafthttp
-	if _, err := types.NewURLs(peerAddrs); err != nil {
+	if _, err := types.NewURLs(r.PeerURLs); err != nil {
 		return nil, err
 	}
 
-	r := &pb.MemberAddRequest{
-		PeerURLs:  peerAddrs,
-		IsLearner: isLearner,
-	}
 	resp, err := c.remote.MemberAdd(ctx, r, c.callOpts...)
 	if err != nil {
 		return nil, toErr(ctx, err)
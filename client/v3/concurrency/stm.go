@@ -16,8 +16,11 @@ package concurrency
 
 import (
 	"context"
+	"errors"
 	"math"
 
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	v3 "go.etcd.io/etcd/client/v3"
 )
 
@@ -63,6 +66,7 @@ type stmOptions struct {
 	iso      Isolation
 	ctx      context.Context
 	prefetch []string
+	validate bool
 }
 
 type stmOption func(*stmOptions)
@@ -85,6 +89,17 @@ func WithPrefetch(keys ...string) stmOption {
 	return func(so *stmOptions) { so.prefetch = append(so.prefetch, keys...) }
 }
 
+// WithServerValidation sends the read set of the transaction to the server,
+// which validates it when applying the writes and returns the keys which
+// changed on conflict. The retried transaction then reads these keys, and the
+// keys which did not change, without a round trip. The isolation level is
+// unchanged: the read set stays guarded by compares, which servers predating
+// etcd v3.6 evaluate while ignoring the read set, and the STM stops sending the
+// read set if the cluster rejects it while it has members predating v3.6.
+func WithServerValidation() stmOption {
+	return func(so *stmOptions) { so.validate = true }
+}
+
 // NewSTM initiates a new STM instance, using serializable snapshot isolation by default.
 func NewSTM(c *v3.Client, apply func(STM) error, so ...stmOption) (*v3.TxnResponse, error) {
 	opts := &stmOptions{ctx: c.Ctx()}
@@ -105,8 +120,7 @@ func mkSTM(c *v3.Client, opts *stmOptions) STM {
 	switch opts.iso {
 	case SerializableSnapshot:
 		s := &stmSerializable{
-			stm:      stm{client: c, ctx: opts.ctx},
-			prefetch: make(map[string]*v3.GetResponse),
+			stm: stm{client: c, ctx: opts.ctx, validate: opts.validate, prefetch: make(map[string]*v3.GetResponse)},
 		}
 		s.conflicts = func() []v3.Cmp {
 			return append(s.rset.cmps(), s.wset.cmps(s.rset.first()+1)...)
@@ -114,16 +128,16 @@ func mkSTM(c *v3.Client, opts *stmOptions) STM {
 		return s
 	case Serializable:
 		s := &stmSerializable{
-			stm:      stm{client: c, ctx: opts.ctx},
-			prefetch: make(map[string]*v3.GetResponse),
+			stm: stm{client: c, ctx: opts.ctx, validate: opts.validate, prefetch: make(map[string]*v3.GetResponse)},
 		}
 		s.conflicts = func() []v3.Cmp { return s.rset.cmps() }
 		return s
 	case RepeatableReads:
-		s := &stm{client: c, ctx: opts.ctx, getOpts: []v3.OpOption{v3.WithSerializable()}}
+		s := &stm{client: c, ctx: opts.ctx, getOpts: []v3.OpOption{v3.WithSerializable()}, validate: opts.validate}
 		s.conflicts = func() []v3.Cmp { return s.rset.cmps() }
 		return s
 	case ReadCommitted:
+		// the read set is never validated
 		s := &stm{client: c, ctx: opts.ctx, getOpts: []v3.OpOption{v3.WithSerializable()}}
 		s.conflicts = func() []v3.Cmp { return nil }
 		return s
@@ -178,6 +192,12 @@ type stm struct {
 	getOpts []v3.OpOption
 	// conflicts computes the current conflicts on the txn
 	conflicts func() []v3.Cmp
+	// validate sends the read set to the server for validation, which
+	// returns the conflicting keys if the txn fails
+	validate bool
+	// prefetch holds the read key values and revisions loaded from a
+	// conflicting commit, moved to the read set once read again
+	prefetch map[string]*v3.GetResponse
 }
 
 type stmPut struct {
@@ -213,6 +233,41 @@ func (rs readSet) cmps() []v3.Cmp {
 	return cmps
 }
 
+// entries returns the read set validated by the server
+func (rs readSet) entries() []v3.ReadSetEntry {
+	entries := make([]v3.ReadSetEntry, 0, len(rs))
+	for k, rk := range rs {
+		e := v3.ReadSetEntry{Key: []byte(k)}
+		if len(rk.Kvs) != 0 {
+			e.ModRevision = rk.Kvs[0].ModRevision
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+// current returns the read set as of a txn response validating it, with the
+// conflicting keys updated
+func (rs readSet) current(txnresp *v3.TxnResponse) readSet {
+	conflicts := make(map[string]*mvccpb.KeyValue, len(txnresp.Conflicts))
+	for _, kv := range txnresp.Conflicts {
+		conflicts[string(kv.Key)] = kv
+	}
+	cur := make(readSet, len(rs))
+	for k, rk := range rs {
+		resp := *rk
+		resp.Header = txnresp.Header
+		if kv, ok := conflicts[k]; ok {
+			resp.Kvs, resp.Count = nil, 0
+			if kv.ModRevision != 0 {
+				resp.Kvs, resp.Count = []*mvccpb.KeyValue{kv}, 1
+			}
+		}
+		cur[k] = &resp
+	}
+	return cur
+}
+
 type writeSet map[string]stmPut
 
 func (ws writeSet) get(keys ...string) *stmPut {
@@ -263,13 +318,28 @@ func (s *stm) Rev(key string) int64 {
 }
 
 func (s *stm) commit() *v3.TxnResponse {
-	txnresp, err := s.client.Txn(s.ctx).If(s.conflicts()...).Then(s.wset.puts()...).Commit()
+	txn := s.client.Txn(s.ctx)
+	if s.validate {
+		txn = txn.ReadSet(s.rset.entries()...)
+	}
+	txnresp, err := txn.If(s.conflicts()...).Then(s.wset.puts()...).Commit()
+	if s.validate && errors.Is(err, rpctypes.ErrReadSetNotSupported) {
+		// the cluster has members which would not validate the read set
+		s.validate = false
+		txnresp, err = s.client.Txn(s.ctx).If(s.conflicts()...).Then(s.wset.puts()...).Commit()
+	}
 	if err != nil {
 		panic(stmError{err})
 	}
 	if txnresp.Succeeded {
 		return txnresp
 	}
+	if s.validate && len(txnresp.Conflicts) != 0 {
+		// load prefetch with the unchanged and conflicting keys; a txn
+		// failing without conflicts failed its compares, which servers
+		// predating read sets evaluate alone
+		s.prefetch = s.rset.current(txnresp)
+	}
 	return nil
 }
 
@@ -277,6 +347,12 @@ func (s *stm) fetch(keys ...string) *v3.GetResponse {
 	if len(keys) == 0 {
 		return nil
 	}
+	for _, key := range keys {
+		if resp, ok := s.prefetch[key]; ok {
+			delete(s.prefetch, key)
+			s.rset[key] = resp
+		}
+	}
 	ops := make([]v3.Op, len(keys))
 	for i, key := range keys {
 		if resp, ok := s.rset[key]; ok {
@@ -299,7 +375,6 @@ func (s *stm) reset() {
 
 type stmSerializable struct {
 	stm
-	prefetch map[string]*v3.GetResponse
 }
 
 func (s *stmSerializable) Get(keys ...string) string {
@@ -307,12 +382,6 @@ func (s *stmSerializable) Get(keys ...string) string {
 		return wv.val
 	}
 	firstRead := len(s.rset) == 0
-	for _, key := range keys {
-		if resp, ok := s.prefetch[key]; ok {
-			delete(s.prefetch, key)
-			s.rset[key] = resp
-		}
-	}
 	resp := s.stm.fetch(keys...)
 	if firstRead {
 		// txn's base revision is defined by the first read
@@ -340,6 +409,14 @@ func (s *stmSerializable) gets() ([]string, []v3.Op) {
 }
 
 func (s *stmSerializable) commit() *v3.TxnResponse {
+	if s.validate {
+		txnresp := s.stm.commit()
+		if txnresp == nil && len(s.prefetch) != 0 {
+			// the prefetched keys are current as of the conflicting commit
+			s.getOpts = nil
+		}
+		return txnresp
+	}
 	keys, getops := s.gets()
 	txn := s.client.Txn(s.ctx).If(s.conflicts()...).Then(s.wset.puts()...)
 	// use Else to prefetch keys in case of conflict to save a round trip
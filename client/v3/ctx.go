@@ -37,6 +37,20 @@ func WithRequireLeader(ctx context.Context) context.Context {
 	return metadata.NewOutgoingContext(ctx, copied)
 }
 
+// WithWatchCompression returns a context whose watches share a watch stream
+// compressed with gzip, which trades CPU for bandwidth on large watch
+// responses. The server compresses its responses on the stream as well.
+func WithWatchCompression(ctx context.Context) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok { // no outgoing metadata ctx key, create one
+		md = metadata.Pairs(rpctypes.MetadataWatchCompressionKey, rpctypes.MetadataWatchCompressionGzip)
+		return metadata.NewOutgoingContext(ctx, md)
+	}
+	copied := md.Copy() // avoid racey updates
+	copied.Set(rpctypes.MetadataWatchCompressionKey, rpctypes.MetadataWatchCompressionGzip)
+	return metadata.NewOutgoingContext(ctx, copied)
+}
+
 // embeds client version
 func withVersion(ctx context.Context) context.Context {
 	md, ok := metadata.FromOutgoingContext(ctx)
@@ -66,3 +66,27 @@ func TestMetadataWithClientAPIVersion(t *testing.T) {
 		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataClientAPIVersionKey, ss)
 	}
 }
+
+func TestMetadataWithWatchCompression(t *testing.T) {
+	ctx := WithWatchCompression(WithRequireLeader(context.TODO()))
+
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		t.Fatal("expected outgoing metadata ctx key")
+	}
+	if ss := md.Get(rpctypes.MetadataRequireLeaderKey); !reflect.DeepEqual(ss, []string{rpctypes.MetadataHasLeader}) {
+		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataRequireLeaderKey, ss)
+	}
+	if ss := md.Get(rpctypes.MetadataWatchCompressionKey); !reflect.DeepEqual(ss, []string{rpctypes.MetadataWatchCompressionGzip}) {
+		t.Fatalf("unexpected metadata for %q %v", rpctypes.MetadataWatchCompressionKey, ss)
+	}
+	if streamKeyFromCtx(ctx) == streamKeyFromCtx(WithRequireLeader(context.TODO())) {
+		t.Fatal("expected compressed watches to use their own stream")
+	}
+	if opts := watchCallOpts(ctx, nil); len(opts) != 1 {
+		t.Fatalf("expected a compressor call option, got %v", opts)
+	}
+	if opts := watchCallOpts(context.TODO(), nil); len(opts) != 0 {
+		t.Fatalf("expected no call options, got %v", opts)
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"encoding/json"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// RangeFilter restricts a 'Get' request to the key-values it matches. See
+// WithFilter.
+type RangeFilter pb.RangeFilter
+
+// Filter sets the result of a range filter and the value it is compared to.
+// The result is one of "=", "!=", ">", "<" or, for value and JSON filters,
+// "prefix". It panics on an unknown result or a value of the wrong type, as
+// Compare does.
+func Filter(f RangeFilter, result string, v interface{}) RangeFilter {
+	switch result {
+	case "=":
+		f.Result = pb.RangeFilter_EQUAL
+	case "!=":
+		f.Result = pb.RangeFilter_NOT_EQUAL
+	case ">":
+		f.Result = pb.RangeFilter_GREATER
+	case "<":
+		f.Result = pb.RangeFilter_LESS
+	case "prefix":
+		f.Result = pb.RangeFilter_PREFIX
+	default:
+		panic("Unknown result op")
+	}
+
+	switch f.Target {
+	case pb.RangeFilter_VALUE:
+		val, ok := v.(string)
+		if !ok {
+			panic("bad filter value")
+		}
+		f.TargetUnion = &pb.RangeFilter_Value{Value: []byte(val)}
+	case pb.RangeFilter_JSON:
+		val, err := json.Marshal(v)
+		if err != nil {
+			panic("bad filter value")
+		}
+		f.TargetUnion = &pb.RangeFilter_Value{Value: val}
+	case pb.RangeFilter_VERSION:
+		f.TargetUnion = &pb.RangeFilter_Version{Version: mustInt64(v)}
+	case pb.RangeFilter_CREATE:
+		f.TargetUnion = &pb.RangeFilter_CreateRevision{CreateRevision: mustInt64(v)}
+	case pb.RangeFilter_MOD:
+		f.TargetUnion = &pb.RangeFilter_ModRevision{ModRevision: mustInt64(v)}
+	case pb.RangeFilter_LEASE:
+		f.TargetUnion = &pb.RangeFilter_Lease{Lease: mustInt64orLeaseID(v)}
+	default:
+		panic("Unknown filter type")
+	}
+	return f
+}
+
+// ValueFilter filters on the value of keys.
+func ValueFilter() RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_VALUE}
+}
+
+// VersionFilter filters on the version of keys.
+func VersionFilter() RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_VERSION}
+}
+
+// CreateRevisionFilter filters on the creation revision of keys.
+func CreateRevisionFilter() RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_CREATE}
+}
+
+// ModRevisionFilter filters on the modification revision of keys.
+func ModRevisionFilter() RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_MOD}
+}
+
+// LeaseFilter filters on the lease ID attached to keys. The empty LeaseID is
+// 0, otherwise known as `NoLease`.
+func LeaseFilter() RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_LEASE}
+}
+
+// JSONFilter filters on the field at path of values holding JSON documents,
+// for example "spec.containers[0].image". The filter value is encoded as JSON,
+// so Filter(JSONFilter("replicas"), ">", 2) matches {"replicas": 3}. Values
+// that are not JSON, or lack the field, never match.
+func JSONFilter(path string) RangeFilter {
+	return RangeFilter{Target: pb.RangeFilter_JSON, JsonPath: path}
+}
@@ -0,0 +1,48 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package informer keeps an in-memory cache of the keys under a prefix,
+// consistent with a single revision of the cluster, and notifies handlers of
+// its changes.
+//
+// An informer lists the keys at a revision, then watches the changes from the
+// next revision. When the watched revisions are compacted, it lists the keys
+// again, and notifies the handlers of the differences with the cache. Key
+// values can be looked up by secondary indexes computed by index functions.
+//
+// First, create a client:
+//
+//	cli, err := clientv3.New(clientv3.Config{Endpoints: []string{"localhost:2379"}})
+//	if err != nil {
+//		// handle error!
+//	}
+//
+// Next, create an informer over a prefix, with handlers and indexes:
+//
+//	inf := informer.New(cli, cli, "/pods/",
+//		informer.WithHandler(informer.HandlerFuncs{
+//			AddFunc:    func(kv *mvccpb.KeyValue) { fmt.Printf("added %s\n", kv.Key) },
+//			DeleteFunc: func(kv *mvccpb.KeyValue) { fmt.Printf("deleted %s\n", kv.Key) },
+//		}),
+//		informer.WithIndexer("node", func(kv *mvccpb.KeyValue) []string {
+//			return []string{nodeOf(kv.Value)}
+//		}),
+//	)
+//	go inf.Run(ctx)
+//
+// Then, wait for the cache to be filled, and read it:
+//
+//	<-inf.Synced()
+//	kvs, err := inf.ByIndex("node", "node-1")
+package informer
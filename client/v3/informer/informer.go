@@ -0,0 +1,358 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const (
+	defaultPageSize = 1000
+	// retryDelay is the delay before listing or watching again after a
+	// failure.
+	retryDelay = 500 * time.Millisecond
+)
+
+// ErrUnknownIndex is returned when looking up an index with no index
+// function.
+var ErrUnknownIndex = errors.New("informer: unknown index")
+
+// Handler is notified of the changes of the cache of an informer. The
+// handlers are called one at a time, in the order of the changes, after the
+// cache reflects them. They must not block for long, as the informer does
+// not process other changes meanwhile.
+type Handler interface {
+	// OnAdd is called when a key is added to the cache.
+	OnAdd(kv *mvccpb.KeyValue)
+	// OnUpdate is called when the value of a key changes, and for every key
+	// on a resync, in which case old and kv are the same.
+	OnUpdate(old, kv *mvccpb.KeyValue)
+	// OnDelete is called with the last value of a key deleted from the cache.
+	OnDelete(kv *mvccpb.KeyValue)
+}
+
+// HandlerFuncs is a Handler calling its functions, if set.
+type HandlerFuncs struct {
+	AddFunc    func(kv *mvccpb.KeyValue)
+	UpdateFunc func(old, kv *mvccpb.KeyValue)
+	DeleteFunc func(kv *mvccpb.KeyValue)
+}
+
+func (h HandlerFuncs) OnAdd(kv *mvccpb.KeyValue) {
+	if h.AddFunc != nil {
+		h.AddFunc(kv)
+	}
+}
+
+func (h HandlerFuncs) OnUpdate(old, kv *mvccpb.KeyValue) {
+	if h.UpdateFunc != nil {
+		h.UpdateFunc(old, kv)
+	}
+}
+
+func (h HandlerFuncs) OnDelete(kv *mvccpb.KeyValue) {
+	if h.DeleteFunc != nil {
+		h.DeleteFunc(kv)
+	}
+}
+
+// Option configures an Informer.
+type Option func(*Informer)
+
+// WithHandler adds a handler notified of the changes of the cache.
+func WithHandler(h Handler) Option {
+	return func(inf *Informer) { inf.handlers = append(inf.handlers, h) }
+}
+
+// WithIndexer adds a secondary index, maintained with the given function.
+func WithIndexer(name string, f IndexFunc) Option {
+	return func(inf *Informer) { inf.indexers[name] = f }
+}
+
+// WithResyncPeriod makes the informer call OnUpdate for every cached key
+// with the given period, so that handlers can retry failed actions.
+func WithResyncPeriod(d time.Duration) Option {
+	return func(inf *Informer) { inf.resyncPeriod = d }
+}
+
+// WithPageSize sets the number of keys fetched per request when listing.
+func WithPageSize(n int64) Option {
+	return func(inf *Informer) { inf.pageSize = n }
+}
+
+// Informer keeps an in-memory cache of the keys under a prefix, consistent
+// with a single revision, and notifies handlers of its changes.
+type Informer struct {
+	kv     clientv3.KV
+	w      clientv3.Watcher
+	prefix string
+
+	handlers     []Handler
+	indexers     map[string]IndexFunc
+	resyncPeriod time.Duration
+	pageSize     int64
+
+	mu    sync.RWMutex
+	store *store
+	// rev is the revision the cache is consistent with.
+	rev int64
+
+	syncOnce sync.Once
+	syncedc  chan struct{}
+}
+
+// New returns an informer over the keys under the given prefix, or over the
+// whole key space if the prefix is empty. The informer starts with Run.
+func New(kv clientv3.KV, w clientv3.Watcher, prefix string, opts ...Option) *Informer {
+	inf := &Informer{
+		kv:       kv,
+		w:        w,
+		prefix:   prefix,
+		indexers: make(map[string]IndexFunc),
+		pageSize: defaultPageSize,
+		syncedc:  make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(inf)
+	}
+	inf.store = newStore(inf.indexers)
+	return inf
+}
+
+// Run fills the cache and keeps it up to date until the context is done,
+// and returns the error of the context. Run must be called once.
+func (inf *Informer) Run(ctx context.Context) error {
+	var resync <-chan time.Time
+	if inf.resyncPeriod > 0 {
+		ticker := time.NewTicker(inf.resyncPeriod)
+		defer ticker.Stop()
+		resync = ticker.C
+	}
+
+	relist := true
+	for {
+		var err error
+		if relist {
+			err = inf.list(ctx)
+		} else {
+			err = inf.watch(ctx, resync)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch {
+		case relist && err == nil:
+			// the cache is listed; watch the changes after its revision
+			relist = false
+			continue
+		case errors.Is(err, rpctypes.ErrCompacted):
+			relist = true
+		}
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// list replaces the cache with the keys at the current revision, and
+// notifies the handlers of the differences.
+func (inf *Informer) list(ctx context.Context) error {
+	var (
+		kvs []*mvccpb.KeyValue
+		rev int64
+	)
+	key, end := inf.prefix, clientv3.GetPrefixRangeEnd(inf.prefix)
+	if key == "" {
+		key = "\x00"
+	}
+	for {
+		opts := []clientv3.OpOption{clientv3.WithRange(end), clientv3.WithLimit(inf.pageSize)}
+		if rev != 0 {
+			opts = append(opts, clientv3.WithRev(rev))
+		}
+		resp, err := inf.kv.Get(ctx, key, opts...)
+		if err != nil {
+			return err
+		}
+		if rev == 0 {
+			rev = resp.Header.Revision
+		}
+		kvs = append(kvs, resp.Kvs...)
+		if !resp.More || len(resp.Kvs) == 0 {
+			break
+		}
+		key = string(append(resp.Kvs[len(resp.Kvs)-1].Key, 0))
+	}
+
+	var notify []func(h Handler)
+	inf.mu.Lock()
+	listed := make(map[string]struct{}, len(kvs))
+	for _, kv := range kvs {
+		kv := kv
+		listed[string(kv.Key)] = struct{}{}
+		old, ok := inf.store.put(kv)
+		switch {
+		case !ok:
+			notify = append(notify, func(h Handler) { h.OnAdd(kv) })
+		case old.ModRevision != kv.ModRevision:
+			notify = append(notify, func(h Handler) { h.OnUpdate(old, kv) })
+		}
+	}
+	for _, old := range inf.store.list() {
+		old := old
+		if _, ok := listed[string(old.Key)]; !ok {
+			inf.store.delete(string(old.Key))
+			notify = append(notify, func(h Handler) { h.OnDelete(old) })
+		}
+	}
+	inf.rev = rev
+	inf.mu.Unlock()
+
+	inf.notify(notify)
+	inf.syncOnce.Do(func() { close(inf.syncedc) })
+	return nil
+}
+
+// watch applies the changes after the revision of the cache until the watch
+// fails, and resyncs the handlers on the given channel.
+func (inf *Informer) watch(ctx context.Context, resync <-chan time.Time) error {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+
+	inf.mu.RLock()
+	rev := inf.rev
+	inf.mu.RUnlock()
+	wch := inf.w.Watch(wctx, inf.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1))
+	for {
+		select {
+		case wresp, ok := <-wch:
+			if !ok {
+				return ctx.Err()
+			}
+			if wresp.CompactRevision != 0 {
+				return rpctypes.ErrCompacted
+			}
+			if err := wresp.Err(); err != nil {
+				return err
+			}
+			inf.apply(&wresp)
+		case <-resync:
+			inf.mu.RLock()
+			kvs := inf.store.list()
+			inf.mu.RUnlock()
+			notify := make([]func(h Handler), len(kvs))
+			for i, kv := range kvs {
+				kv := kv
+				notify[i] = func(h Handler) { h.OnUpdate(kv, kv) }
+			}
+			inf.notify(notify)
+		}
+	}
+}
+
+// apply applies the events of a watch response, which hold every event of
+// their revisions, so that the cache stays consistent with a revision.
+func (inf *Informer) apply(wresp *clientv3.WatchResponse) {
+	var notify []func(h Handler)
+	inf.mu.Lock()
+	for _, ev := range wresp.Events {
+		kv := ev.Kv
+		switch ev.Type {
+		case clientv3.EventTypePut:
+			if old, ok := inf.store.put(kv); ok {
+				notify = append(notify, func(h Handler) { h.OnUpdate(old, kv) })
+			} else {
+				notify = append(notify, func(h Handler) { h.OnAdd(kv) })
+			}
+		case clientv3.EventTypeDelete:
+			if old, ok := inf.store.delete(string(kv.Key)); ok {
+				notify = append(notify, func(h Handler) { h.OnDelete(old) })
+			}
+		}
+		inf.rev = kv.ModRevision
+	}
+	if wresp.IsProgressNotify() && wresp.Header.Revision > inf.rev {
+		inf.rev = wresp.Header.Revision
+	}
+	inf.mu.Unlock()
+	inf.notify(notify)
+}
+
+func (inf *Informer) notify(notify []func(h Handler)) {
+	for _, n := range notify {
+		for _, h := range inf.handlers {
+			n(h)
+		}
+	}
+}
+
+// Synced returns a channel closed once the cache is first filled.
+func (inf *Informer) Synced() <-chan struct{} { return inf.syncedc }
+
+// HasSynced returns whether the cache was filled.
+func (inf *Informer) HasSynced() bool {
+	select {
+	case <-inf.syncedc:
+		return true
+	default:
+		return false
+	}
+}
+
+// Rev returns the revision the cache is consistent with.
+func (inf *Informer) Rev() int64 {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.rev
+}
+
+// Get returns the cached key-value of the given key.
+func (inf *Informer) Get(key string) (*mvccpb.KeyValue, bool) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.store.get(key)
+}
+
+// List returns the cached key-values, sorted by key, and the revision they
+// are consistent with.
+func (inf *Informer) List() ([]*mvccpb.KeyValue, int64) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.store.list(), inf.rev
+}
+
+// ByIndex returns the cached key-values indexed under the given value of the
+// given index, sorted by key.
+func (inf *Informer) ByIndex(name, value string) ([]*mvccpb.KeyValue, error) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.store.byIndex(name, value)
+}
+
+// IndexValues returns the values of the given index, sorted.
+func (inf *Informer) IndexValues(name string) ([]string, error) {
+	inf.mu.RLock()
+	defer inf.mu.RUnlock()
+	return inf.store.indexValues(name)
+}
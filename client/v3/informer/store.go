@@ -0,0 +1,145 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"sort"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// IndexFunc returns the values a key-value is indexed under.
+type IndexFunc func(kv *mvccpb.KeyValue) []string
+
+// store is a cache of key-values with secondary indexes. It is not safe for
+// concurrent use.
+type store struct {
+	kvs      map[string]*mvccpb.KeyValue
+	indexers map[string]IndexFunc
+	// indices maps an index name to the keys of each index value.
+	indices map[string]map[string]map[string]struct{}
+}
+
+func newStore(indexers map[string]IndexFunc) *store {
+	s := &store{
+		kvs:      make(map[string]*mvccpb.KeyValue),
+		indexers: indexers,
+		indices:  make(map[string]map[string]map[string]struct{}, len(indexers)),
+	}
+	for name := range indexers {
+		s.indices[name] = make(map[string]map[string]struct{})
+	}
+	return s
+}
+
+func (s *store) get(key string) (*mvccpb.KeyValue, bool) {
+	kv, ok := s.kvs[key]
+	return kv, ok
+}
+
+// put stores the key-value, and returns the key-value it replaced, if any.
+func (s *store) put(kv *mvccpb.KeyValue) (*mvccpb.KeyValue, bool) {
+	key := string(kv.Key)
+	old, ok := s.kvs[key]
+	if ok {
+		s.unindex(key, old)
+	}
+	s.kvs[key] = kv
+	s.index(key, kv)
+	return old, ok
+}
+
+// delete deletes the key, and returns the key-value it held, if any.
+func (s *store) delete(key string) (*mvccpb.KeyValue, bool) {
+	old, ok := s.kvs[key]
+	if !ok {
+		return nil, false
+	}
+	s.unindex(key, old)
+	delete(s.kvs, key)
+	return old, true
+}
+
+func (s *store) index(key string, kv *mvccpb.KeyValue) {
+	for name, f := range s.indexers {
+		idx := s.indices[name]
+		for _, v := range f(kv) {
+			keys, ok := idx[v]
+			if !ok {
+				keys = make(map[string]struct{})
+				idx[v] = keys
+			}
+			keys[key] = struct{}{}
+		}
+	}
+}
+
+func (s *store) unindex(key string, kv *mvccpb.KeyValue) {
+	for name, f := range s.indexers {
+		idx := s.indices[name]
+		for _, v := range f(kv) {
+			if keys, ok := idx[v]; ok {
+				delete(keys, key)
+				if len(keys) == 0 {
+					delete(idx, v)
+				}
+			}
+		}
+	}
+}
+
+// list returns the key-values, sorted by key.
+func (s *store) list() []*mvccpb.KeyValue {
+	kvs := make([]*mvccpb.KeyValue, 0, len(s.kvs))
+	for _, kv := range s.kvs {
+		kvs = append(kvs, kv)
+	}
+	sortByKey(kvs)
+	return kvs
+}
+
+// byIndex returns the key-values indexed under the given value, sorted by
+// key.
+func (s *store) byIndex(name, value string) ([]*mvccpb.KeyValue, error) {
+	idx, ok := s.indices[name]
+	if !ok {
+		return nil, ErrUnknownIndex
+	}
+	keys := idx[value]
+	kvs := make([]*mvccpb.KeyValue, 0, len(keys))
+	for key := range keys {
+		kvs = append(kvs, s.kvs[key])
+	}
+	sortByKey(kvs)
+	return kvs, nil
+}
+
+// indexValues returns the values of the given index, sorted.
+func (s *store) indexValues(name string) ([]string, error) {
+	idx, ok := s.indices[name]
+	if !ok {
+		return nil, ErrUnknownIndex
+	}
+	vs := make([]string, 0, len(idx))
+	for v := range idx {
+		vs = append(vs, v)
+	}
+	sort.Strings(vs)
+	return vs, nil
+}
+
+func sortByKey(kvs []*mvccpb.KeyValue) {
+	sort.Slice(kvs, func(i, j int) bool { return string(kvs[i].Key) < string(kvs[j].Key) })
+}
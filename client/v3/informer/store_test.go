@@ -0,0 +1,78 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package informer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func keysOf(kvs []*mvccpb.KeyValue) []string {
+	keys := make([]string, len(kvs))
+	for i, kv := range kvs {
+		keys[i] = string(kv.Key)
+	}
+	return keys
+}
+
+func TestStoreIndex(t *testing.T) {
+	// index the key-values by the comma separated labels of their value
+	s := newStore(map[string]IndexFunc{
+		"label": func(kv *mvccpb.KeyValue) []string { return strings.Split(string(kv.Value), ",") },
+	})
+	s.put(&mvccpb.KeyValue{Key: []byte("b"), Value: []byte("x,y")})
+	s.put(&mvccpb.KeyValue{Key: []byte("a"), Value: []byte("x")})
+	if old, ok := s.put(&mvccpb.KeyValue{Key: []byte("c"), Value: []byte("y")}); ok {
+		t.Fatalf("put of a new key replaced %v", old)
+	}
+
+	check := func(value string, want []string) {
+		t.Helper()
+		kvs, err := s.byIndex("label", value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := keysOf(kvs); !reflect.DeepEqual(got, want) {
+			t.Errorf("keys labeled %q = %v, want %v", value, got, want)
+		}
+	}
+	check("x", []string{"a", "b"})
+	check("y", []string{"b", "c"})
+
+	// updates move the key between the index values
+	if old, ok := s.put(&mvccpb.KeyValue{Key: []byte("b"), Value: []byte("z")}); !ok || string(old.Value) != "x,y" {
+		t.Fatalf("put replaced %v, want the value x,y", old)
+	}
+	check("x", []string{"a"})
+	check("y", []string{"c"})
+	check("z", []string{"b"})
+
+	if _, ok := s.delete("c"); !ok {
+		t.Fatal("delete of c found no key")
+	}
+	check("y", []string{})
+	if vs, _ := s.indexValues("label"); !reflect.DeepEqual(vs, []string{"x", "z"}) {
+		t.Errorf("index values = %v, want [x z]", vs)
+	}
+	if got := keysOf(s.list()); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("keys = %v, want [a b]", got)
+	}
+	if _, err := s.byIndex("unknown", "x"); err != ErrUnknownIndex {
+		t.Errorf("err = %v, want %v", err, ErrUnknownIndex)
+	}
+}
@@ -107,7 +107,7 @@ func (lkv *leasingKV) Do(ctx context.Context, op v3.Op) (v3.OpResponse, error) {
 		return resp.OpResponse(), err
 	case op.IsTxn():
 		cmps, thenOps, elseOps := op.Txn()
-		resp, err := lkv.Txn(ctx).If(cmps...).Then(thenOps...).Else(elseOps...).Commit()
+		resp, err := lkv.Txn(ctx).If(cmps...).ReadSet(op.ReadSet()...).Then(thenOps...).Else(elseOps...).Commit()
 		return resp.OpResponse(), err
 	}
 	return v3.OpResponse{}, nil
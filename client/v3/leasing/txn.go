@@ -29,6 +29,7 @@ type txnLeasing struct {
 	cs   []v3.Cmp
 	opst []v3.Op
 	opse []v3.Op
+	rs   []v3.ReadSetEntry
 }
 
 func (txn *txnLeasing) If(cs ...v3.Cmp) v3.Txn {
@@ -49,9 +50,18 @@ func (txn *txnLeasing) Else(ops ...v3.Op) v3.Txn {
 	return txn
 }
 
+func (txn *txnLeasing) ReadSet(rs ...v3.ReadSetEntry) v3.Txn {
+	txn.rs = append(txn.rs, rs...)
+	txn.Txn = txn.Txn.ReadSet(rs...)
+	return txn
+}
+
 func (txn *txnLeasing) Commit() (*v3.TxnResponse, error) {
-	if resp, err := txn.eval(); resp != nil || err != nil {
-		return resp, err
+	// the read set is validated by the server
+	if len(txn.rs) == 0 {
+		if resp, err := txn.eval(); resp != nil || err != nil {
+			return resp, err
+		}
 	}
 	return txn.serverTxn()
 }
@@ -195,6 +205,7 @@ func (txn *txnLeasing) serverTxn() (*v3.TxnResponse, error) {
 
 	userOps := gatherOps(append(txn.opst, txn.opse...))
 	userTxn := v3.OpTxn(txn.cs, txn.opst, txn.opse)
+	userTxn.WithReadSet(txn.rs)
 	fbOps := txn.fallback(userOps)
 
 	defer closeAll(txn.lkv.leases.LockWriteOps(userOps))
@@ -35,6 +35,12 @@ type (
 	MoveLeaderResponse pb.MoveLeaderResponse
 	DowngradeResponse  pb.DowngradeResponse
 
+	QuotaSetResponse    pb.QuotaSetResponse
+	QuotaDeleteResponse pb.QuotaDeleteResponse
+	QuotaListResponse   pb.QuotaListResponse
+
+	RotateEncryptionKeyResponse pb.RotateEncryptionKeyResponse
+
 	DowngradeAction pb.DowngradeRequest_DowngradeAction
 )
 
@@ -60,6 +66,11 @@ type Maintenance interface {
 	// times with different endpoints.
 	Defragment(ctx context.Context, endpoint string) (*DefragmentResponse, error)
 
+	// DefragmentWithOptions defragments a given etcd member as Defragment, with
+	// the given options. WithOnlineDefrag keeps the member serving requests
+	// during the defragmentation.
+	DefragmentWithOptions(ctx context.Context, endpoint string, opts ...DefragOption) (*DefragmentResponse, error)
+
 	// Status gets the status of the endpoint.
 	Status(ctx context.Context, endpoint string) (*StatusResponse, error)
 
@@ -79,6 +90,21 @@ type Maintenance interface {
 	// Deprecated: use SnapshotWithVersion instead.
 	Snapshot(ctx context.Context) (io.ReadCloser, error)
 
+	// SnapshotSession streams the snapshot of a resumable snapshot session.
+	// A zero sessionID opens a new session, otherwise the session is resumed
+	// from offset. Each response carries the session ID, the offset and the
+	// CRC-32C checksum of its blob, and the last one the SHA-256 digest of the
+	// whole snapshot. Sessions expire a minute after their last stream ended.
+	// Supported since etcd 3.6.
+	SnapshotSession(ctx context.Context, sessionID, offset int64) (pb.Maintenance_SnapshotClient, error)
+
+	// SnapshotDelta streams the key revisions after baseRev along with the
+	// lease, quota and auth state, so that a snapshot at baseRev can be
+	// brought up to date. The first response carries the revisions and
+	// hashes of both ends of the delta, which are checked on restore.
+	// Supported since etcd 3.6.
+	SnapshotDelta(ctx context.Context, baseRev int64) (pb.Maintenance_SnapshotDeltaClient, error)
+
 	// MoveLeader requests current leader to transfer its leadership to the transferee.
 	// Request must be made to the leader.
 	MoveLeader(ctx context.Context, transfereeID uint64) (*MoveLeaderResponse, error)
@@ -87,6 +113,28 @@ type Maintenance interface {
 	// on the cluster version.
 	// Supported since etcd 3.5.
 	Downgrade(ctx context.Context, action DowngradeAction, version string) (*DowngradeResponse, error)
+
+	// QuotaSet creates or replaces the storage quota on the given key prefix.
+	// Writes that would grow the keys under the prefix beyond maxBytes bytes
+	// or maxKeys keys are rejected. A zero limit is not enforced.
+	// Supported since etcd 3.6.
+	QuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*QuotaSetResponse, error)
+
+	// QuotaDelete removes the storage quota on the given key prefix.
+	// Supported since etcd 3.6.
+	QuotaDelete(ctx context.Context, prefix string) (*QuotaDeleteResponse, error)
+
+	// QuotaList lists all key prefix quotas along with their current usage.
+	// Supported since etcd 3.6.
+	QuotaList(ctx context.Context) (*QuotaListResponse, error)
+
+	// RotateEncryptionKey reloads the encryption keys of the given member,
+	// and rewrites its backend encrypted with the new primary key, copying at
+	// most rateLimitBytes bytes per second if positive. Like Defragment, it
+	// rotates the key of a single member, so each member is rotated in turn
+	// once their key files have the new key.
+	// Supported since etcd 3.6.
+	RotateEncryptionKey(ctx context.Context, endpoint string, rateLimitBytes int64) (*RotateEncryptionKeyResponse, error)
 }
 
 // SnapshotResponse is aggregated response from the snapshot stream.
@@ -188,13 +236,37 @@ func (m *maintenance) AlarmDisarm(ctx context.Context, am *AlarmMember) (*AlarmR
 	return nil, toErr(ctx, err)
 }
 
+// DefragOption configures a defragmentation.
+type DefragOption func(*pb.DefragmentRequest)
+
+// WithOnlineDefrag defragments the member while it keeps serving reads and
+// writes, which are only blocked for a short swap of the database files at
+// the end. Supported since etcd 3.6.
+func WithOnlineDefrag() DefragOption {
+	return func(r *pb.DefragmentRequest) { r.Online = true }
+}
+
+// WithDefragRateLimit limits the bytes copied per second by an online
+// defragmentation.
+func WithDefragRateLimit(bytesPerSecond int64) DefragOption {
+	return func(r *pb.DefragmentRequest) { r.RateLimitBytes = bytesPerSecond }
+}
+
 func (m *maintenance) Defragment(ctx context.Context, endpoint string) (*DefragmentResponse, error) {
+	return m.DefragmentWithOptions(ctx, endpoint)
+}
+
+func (m *maintenance) DefragmentWithOptions(ctx context.Context, endpoint string, opts ...DefragOption) (*DefragmentResponse, error) {
 	remote, cancel, err := m.dial(endpoint)
 	if err != nil {
 		return nil, toErr(ctx, err)
 	}
 	defer cancel()
-	resp, err := remote.Defragment(ctx, &pb.DefragmentRequest{}, m.callOpts...)
+	req := &pb.DefragmentRequest{}
+	for _, opt := range opts {
+		opt(req)
+	}
+	resp, err := remote.Defragment(ctx, req, m.callOpts...)
 	if err != nil {
 		return nil, toErr(ctx, err)
 	}
@@ -306,6 +378,51 @@ func (m *maintenance) logAndCloseWithError(err error, pw *io.PipeWriter) {
 	pw.CloseWithError(err)
 }
 
+func (m *maintenance) SnapshotSession(ctx context.Context, sessionID, offset int64) (pb.Maintenance_SnapshotClient, error) {
+	req := &pb.SnapshotRequest{Resumable: true, SessionId: sessionID, Offset: offset}
+	ss, err := m.remote.Snapshot(ctx, req, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return &snapshotSessionClient{ctx: ctx, Maintenance_SnapshotClient: ss}, nil
+}
+
+// snapshotSessionClient converts the errors of a snapshot session stream.
+type snapshotSessionClient struct {
+	ctx context.Context
+	pb.Maintenance_SnapshotClient
+}
+
+func (sc *snapshotSessionClient) Recv() (*pb.SnapshotResponse, error) {
+	resp, err := sc.Maintenance_SnapshotClient.Recv()
+	if err != nil && err != io.EOF {
+		return nil, toErr(sc.ctx, err)
+	}
+	return resp, err
+}
+
+func (m *maintenance) SnapshotDelta(ctx context.Context, baseRev int64) (pb.Maintenance_SnapshotDeltaClient, error) {
+	sc, err := m.remote.SnapshotDelta(ctx, &pb.SnapshotDeltaRequest{BaseRevision: baseRev}, append(m.callOpts, withMax(defaultStreamMaxRetries))...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return &snapshotDeltaClient{ctx: ctx, Maintenance_SnapshotDeltaClient: sc}, nil
+}
+
+// snapshotDeltaClient converts the errors of a snapshot delta stream.
+type snapshotDeltaClient struct {
+	ctx context.Context
+	pb.Maintenance_SnapshotDeltaClient
+}
+
+func (sc *snapshotDeltaClient) Recv() (*pb.SnapshotDeltaResponse, error) {
+	resp, err := sc.Maintenance_SnapshotDeltaClient.Recv()
+	if err != nil && err != io.EOF {
+		return nil, toErr(sc.ctx, err)
+	}
+	return resp, err
+}
+
 func (m *maintenance) save(resp *pb.SnapshotResponse, pw *io.PipeWriter) error {
 	// can "resp == nil && err == nil"
 	// before we receive snapshot SHA digest?
@@ -348,3 +465,32 @@ func (m *maintenance) Downgrade(ctx context.Context, action DowngradeAction, ver
 	resp, err := m.remote.Downgrade(ctx, &pb.DowngradeRequest{Action: actionType, Version: version}, m.callOpts...)
 	return (*DowngradeResponse)(resp), toErr(ctx, err)
 }
+
+func (m *maintenance) QuotaSet(ctx context.Context, prefix string, maxBytes, maxKeys int64) (*QuotaSetResponse, error) {
+	req := &pb.QuotaSetRequest{Quota: &pb.KeyQuota{Prefix: []byte(prefix), MaxBytes: maxBytes, MaxKeys: maxKeys}}
+	resp, err := m.remote.QuotaSet(ctx, req, m.callOpts...)
+	return (*QuotaSetResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) QuotaDelete(ctx context.Context, prefix string) (*QuotaDeleteResponse, error) {
+	resp, err := m.remote.QuotaDelete(ctx, &pb.QuotaDeleteRequest{Prefix: []byte(prefix)}, m.callOpts...)
+	return (*QuotaDeleteResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) QuotaList(ctx context.Context) (*QuotaListResponse, error) {
+	resp, err := m.remote.QuotaList(ctx, &pb.QuotaListRequest{}, m.callOpts...)
+	return (*QuotaListResponse)(resp), toErr(ctx, err)
+}
+
+func (m *maintenance) RotateEncryptionKey(ctx context.Context, endpoint string, rateLimitBytes int64) (*RotateEncryptionKeyResponse, error) {
+	remote, cancel, err := m.dial(endpoint)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	defer cancel()
+	resp, err := remote.RotateEncryptionKey(ctx, &pb.RotateEncryptionKeyRequest{RateLimitBytes: rateLimitBytes}, m.callOpts...)
+	if err != nil {
+		return nil, toErr(ctx, err)
+	}
+	return (*RotateEncryptionKeyResponse)(resp), nil
+}
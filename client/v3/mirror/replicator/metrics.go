@@ -0,0 +1,68 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package replicator
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	replicationLagRevisions = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "replication",
+		Name:      "lag_revisions",
+		Help:      "The number of source revisions not yet replicated to the destination.",
+	},
+		[]string{"name"},
+	)
+	replicationLagSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "replication",
+		Name:      "lag_seconds",
+		Help:      "The time since the destination last caught up with the source, 0 if it is caught up.",
+	},
+		[]string{"name"},
+	)
+	replicationEvents = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "replication",
+		Name:      "events_total",
+		Help:      "The total number of source events replicated to the destination.",
+	},
+		[]string{"name"},
+	)
+	replicationConflicts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "replication",
+		Name:      "conflicts_total",
+		Help:      "The total number of destination keys changed outside of the replication, by resolution.",
+	},
+		[]string{"name", "resolution"},
+	)
+	replicationFullSyncs = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "replication",
+		Name:      "full_syncs_total",
+		Help:      "The total number of full syncs of the destination, by reason.",
+	},
+		[]string{"name", "reason"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(replicationLagRevisions)
+	prometheus.MustRegister(replicationLagSeconds)
+	prometheus.MustRegister(replicationEvents)
+	prometheus.MustRegister(replicationConflicts)
+	prometheus.MustRegister(replicationFullSyncs)
+}
@@ -0,0 +1,779 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package replicator implements a continuous replication between etcd
+// clusters, with active-passive failover.
+package replicator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/mirror"
+)
+
+const (
+	// DefaultMetaPrefix is the default prefix of the keys holding the state
+	// of the replications in both clusters.
+	DefaultMetaPrefix = "__etcd_replication/"
+
+	defaultMaxTxnOps = 128
+	// retryDelay is the delay before replicating again after a
+	// failure or a failover.
+	retryDelay = time.Second
+	// checkpointInterval throttles the checkpoints of the source revisions
+	// with no replicated change, e.g. of filtered keys or progress
+	// notifications.
+	checkpointInterval = 5 * time.Second
+)
+
+var (
+	// ErrFenced is returned when the replication state of a cluster changed
+	// concurrently, as when the destination of a replication is promoted.
+	ErrFenced = errors.New("replicator: replication state changed concurrently")
+	// ErrNoActiveCluster is returned when neither cluster of a replication is
+	// active.
+	ErrNoActiveCluster = errors.New("replicator: no active cluster")
+	// ErrNoReplicationState is returned when promoting a cluster which is not
+	// part of a replication.
+	ErrNoReplicationState = errors.New("replicator: no replication state")
+
+	errRoleChanged = errors.New("replicator: replication roles changed")
+)
+
+// ConflictPolicy decides how the changes of destination keys made outside of
+// a replication are resolved.
+type ConflictPolicy int
+
+const (
+	// ConflictOverwrite overwrites the destination with the source.
+	ConflictOverwrite ConflictPolicy = iota
+	// ConflictKeep keeps the destination, and skips the source change.
+	ConflictKeep
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictOverwrite:
+		return "overwrite"
+	case ConflictKeep:
+		return "keep"
+	default:
+		return fmt.Sprintf("ConflictPolicy(%d)", int(p))
+	}
+}
+
+// Config configures a Replicator.
+type Config struct {
+	// Name identifies the replication. Its state is kept in both clusters,
+	// under MetaPrefix+Name.
+	Name string
+	// Primary and Secondary are the clusters of the replication. The primary
+	// is active, and replicated to the secondary, until a failover.
+	Primary, Secondary *clientv3.Client
+	// PrimaryPrefix and SecondaryPrefix are the replicated prefixes. A key
+	// under the prefix of the active cluster is replicated under the prefix
+	// of the passive cluster, with the same suffix.
+	PrimaryPrefix, SecondaryPrefix string
+	// Filter returns whether a key is replicated, given its suffix after the
+	// replicated prefix. Every key is replicated if nil. The keys which are
+	// not replicated are left alone in the destination.
+	Filter func(key []byte) bool
+	// ConflictPolicy resolves the changes of destination keys made outside
+	// of the replication, detected when replicating a later change.
+	ConflictPolicy ConflictPolicy
+	// MetaPrefix is the prefix of the replication state keys, which are never
+	// replicated. DefaultMetaPrefix is used if empty.
+	MetaPrefix string
+	// MaxTxnOps is the maximum number of operations of the transactions of
+	// the destination, which must not exceed the limit of the cluster.
+	MaxTxnOps int
+	Logger    *zap.Logger
+}
+
+// Replicator continuously replicates the keys of the active cluster of a pair
+// to the passive one.
+//
+// The replication checkpoints the last replicated source revision in the
+// replication state of the destination, in the transactions writing the
+// changes, so that it resumes from it after a restart. The revisions without
+// replicated changes are checkpointed alone, at most every few seconds, so
+// that the checkpoint is not compacted while the source only changes keys
+// which are not replicated. A full sync replaces
+// the destination keys when there is no checkpoint, or when the source
+// compacted the revisions after it.
+//
+// A failover promotes the passive cluster with Promote. Its replication state
+// moves to a new epoch, which fences the writes of the replication from the
+// former active cluster, and the replication reverses once the former active
+// cluster is available. As the former active cluster may have changes which
+// were not replicated, the reverse replication starts with a full sync.
+type Replicator struct {
+	cfg Config
+	lg  *zap.Logger
+
+	primary, secondary endpoint
+}
+
+// endpoint is a cluster of a replication, with its replication state.
+type endpoint struct {
+	name   string
+	c      *clientv3.Client
+	prefix string
+
+	id uint64
+	// state is the replication state of the cluster, or nil if it has none.
+	state *replicationState
+	// modRev is the modification revision of the state, and rev the revision
+	// it was read at.
+	modRev, rev int64
+}
+
+// replicationState is the replication state of a cluster.
+type replicationState struct {
+	// Epoch is incremented when a cluster is promoted.
+	Epoch int64 `json:"epoch"`
+	// Active is whether the cluster is the source of the replication.
+	Active bool `json:"active"`
+	// Source is the ID of the source cluster of a passive cluster, and
+	// Revision the last source revision replicated to it, if any.
+	Source   uint64 `json:"source,omitempty"`
+	Revision int64  `json:"revision,omitempty"`
+}
+
+// New returns a replicator between the given clusters. The replication
+// starts with Run.
+func New(cfg Config) *Replicator {
+	if cfg.MetaPrefix == "" {
+		cfg.MetaPrefix = DefaultMetaPrefix
+	}
+	if cfg.MaxTxnOps <= 1 {
+		cfg.MaxTxnOps = defaultMaxTxnOps
+	}
+	lg := cfg.Logger
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &Replicator{
+		cfg:       cfg,
+		lg:        lg.With(zap.String("replication", cfg.Name)),
+		primary:   endpoint{name: "primary", c: cfg.Primary, prefix: cfg.PrimaryPrefix},
+		secondary: endpoint{name: "secondary", c: cfg.Secondary, prefix: cfg.SecondaryPrefix},
+	}
+}
+
+// Run replicates the active cluster to the passive one until the context is
+// done, and returns the error of the context. The direction of the
+// replication follows the failovers.
+func (r *Replicator) Run(ctx context.Context) error {
+	for {
+		err := r.runOnce(ctx)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if errors.Is(err, errRoleChanged) || errors.Is(err, ErrFenced) {
+			r.lg.Info("replication roles changed; restarting", zap.Error(err))
+		} else {
+			r.lg.Warn("replication failed; retrying", zap.Error(err))
+		}
+		select {
+		case <-time.After(retryDelay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// runOnce replicates in the direction given by the replication states until
+// the replication fails or the states change.
+func (r *Replicator) runOnce(ctx context.Context) error {
+	src, dst, err := r.roles(ctx)
+	if err != nil {
+		return err
+	}
+	r.lg.Info(
+		"replicating",
+		zap.String("source", src.name),
+		zap.String("destination", dst.name),
+		zap.Int64("epoch", src.state.Epoch),
+	)
+
+	rctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	rolec := make(chan error, 1)
+	go func() {
+		rolec <- r.watchRoles(rctx, src, dst)
+		cancel()
+	}()
+	err = r.replicate(rctx, src, dst)
+	cancel()
+	// the first of the two to fail cancels the other
+	if rerr := <-rolec; rerr != nil && !errors.Is(rerr, context.Canceled) {
+		return rerr
+	}
+	return err
+}
+
+// roles returns the active and the passive clusters, and makes the primary
+// active when neither cluster has a replication state.
+func (r *Replicator) roles(ctx context.Context) (src, dst *endpoint, err error) {
+	p, s := r.primary, r.secondary
+	if err = r.loadState(ctx, &p); err != nil {
+		return nil, nil, err
+	}
+	if err = r.loadState(ctx, &s); err != nil {
+		return nil, nil, err
+	}
+	if p.state == nil && s.state == nil {
+		st := &replicationState{Epoch: 1, Active: true}
+		resp, err := p.c.Txn(ctx).If(
+			clientv3.Compare(clientv3.ModRevision(r.stateKey()), "=", 0),
+		).Then(
+			clientv3.OpPut(r.stateKey(), encodeState(st)),
+		).Commit()
+		if err != nil {
+			return nil, nil, err
+		}
+		if !resp.Succeeded {
+			return nil, nil, errRoleChanged
+		}
+		p.state, p.modRev, p.rev = st, resp.Header.Revision, resp.Header.Revision
+	}
+
+	active := func(ep *endpoint) bool { return ep.state != nil && ep.state.Active }
+	switch {
+	case !active(&p) && !active(&s):
+		return nil, nil, ErrNoActiveCluster
+	case active(&p) && active(&s) && p.state.Epoch == s.state.Epoch:
+		return nil, nil, fmt.Errorf("replicator: both clusters are active in epoch %d", p.state.Epoch)
+	case active(&s) && (!active(&p) || s.state.Epoch > p.state.Epoch):
+		// a promoted secondary, or a primary demoted while unavailable
+		return &s, &p, nil
+	default:
+		return &p, &s, nil
+	}
+}
+
+func (r *Replicator) stateKey() string { return r.cfg.MetaPrefix + r.cfg.Name }
+
+// loadState reads the replication state of the given cluster.
+func (r *Replicator) loadState(ctx context.Context, ep *endpoint) error {
+	resp, err := ep.c.Get(ctx, r.stateKey())
+	if err != nil {
+		return fmt.Errorf("replicator: failed to read the replication state of the %s: %w", ep.name, err)
+	}
+	ep.id, ep.rev = resp.Header.ClusterId, resp.Header.Revision
+	ep.state, ep.modRev = nil, 0
+	if len(resp.Kvs) != 0 {
+		if ep.state, err = decodeState(resp.Kvs[0].Value); err != nil {
+			return err
+		}
+		ep.modRev = resp.Kvs[0].ModRevision
+	}
+	return nil
+}
+
+func encodeState(st *replicationState) string {
+	b, err := json.Marshal(st)
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+}
+
+func decodeState(v []byte) (*replicationState, error) {
+	st := &replicationState{}
+	if err := json.Unmarshal(v, st); err != nil {
+		return nil, fmt.Errorf("replicator: invalid replication state %q: %w", v, err)
+	}
+	return st, nil
+}
+
+// watchRoles returns errRoleChanged once the replication state of the source
+// changes, or the destination is promoted.
+func (r *Replicator) watchRoles(ctx context.Context, src, dst *endpoint) error {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	srcc := src.c.Watch(wctx, r.stateKey(), clientv3.WithRev(src.rev+1))
+	dstc := dst.c.Watch(wctx, r.stateKey(), clientv3.WithRev(dst.rev+1))
+	for {
+		select {
+		case wresp, ok := <-srcc:
+			if !ok {
+				return ctx.Err()
+			}
+			if err := wresp.Err(); err != nil {
+				return watchErr(ctx, err)
+			}
+			if len(wresp.Events) != 0 {
+				// the replication never writes the state of the source
+				return errRoleChanged
+			}
+		case wresp, ok := <-dstc:
+			if !ok {
+				return ctx.Err()
+			}
+			if err := wresp.Err(); err != nil {
+				return watchErr(ctx, err)
+			}
+			for _, ev := range wresp.Events {
+				if ev.Type == clientv3.EventTypeDelete {
+					return errRoleChanged
+				}
+				// the replication writes passive states only
+				if st, err := decodeState(ev.Kv.Value); err != nil || st.Active {
+					return errRoleChanged
+				}
+			}
+		}
+	}
+}
+
+// watchErr returns the error of the context once done, rather than the error
+// of the watches it canceled.
+func watchErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// replicate replicates the source to the destination until it fails,
+// resuming from the checkpoint of the destination if any.
+func (r *Replicator) replicate(ctx context.Context, src, dst *endpoint) error {
+	d := &destination{
+		r:     r,
+		ep:    dst,
+		state: replicationState{Epoch: src.state.Epoch, Source: src.id},
+		fence: dst.modRev,
+	}
+	var rev int64
+	reason := "no_checkpoint"
+	if st := dst.state; st != nil && !st.Active && st.Source == src.id && st.Revision != 0 {
+		rev = st.Revision
+	}
+	for {
+		if rev == 0 {
+			replicationFullSyncs.WithLabelValues(r.cfg.Name, reason).Inc()
+			var err error
+			if rev, err = r.fullSync(ctx, src, d); err != nil {
+				return err
+			}
+		}
+		err := r.watch(ctx, src, d, rev)
+		if !errors.Is(err, rpctypes.ErrCompacted) {
+			return err
+		}
+		r.lg.Warn("replication source compacted past the checkpoint; syncing fully", zap.Int64("revision", rev))
+		rev, reason = 0, "compacted"
+	}
+}
+
+// fullSync replaces the replicated keys of the destination with the keys of
+// the source at its current revision, and returns the revision.
+func (r *Replicator) fullSync(ctx context.Context, src *endpoint, d *destination) (int64, error) {
+	existing := make(map[string][]byte)
+	dresp, derrc := mirror.NewSyncer(d.ep.c, d.ep.prefix, 0).SyncBase(ctx)
+	for resp := range dresp {
+		for _, kv := range resp.Kvs {
+			if _, ok := r.suffix(d.ep, kv.Key); ok {
+				existing[string(kv.Key)] = kv.Value
+			}
+		}
+	}
+	if err := <-derrc; err != nil {
+		return 0, err
+	}
+
+	resp, err := src.c.Get(ctx, r.stateKey(), clientv3.WithCountOnly())
+	if err != nil {
+		return 0, err
+	}
+	rev := resp.Header.Revision
+	r.lg.Info("syncing replication destination fully", zap.Int64("revision", rev))
+
+	var ops []replicaOp
+	var puts, deletes int
+	sresp, serrc := mirror.NewSyncer(src.c, src.prefix, rev).SyncBase(ctx)
+	for resp := range sresp {
+		for _, kv := range resp.Kvs {
+			key, ok := r.destKey(src, d.ep, kv.Key)
+			if !ok {
+				continue
+			}
+			v, ok := existing[key]
+			delete(existing, key)
+			if ok && bytes.Equal(v, kv.Value) {
+				continue
+			}
+			ops = append(ops, replicaOp{key: key, value: kv.Value})
+			puts++
+			if len(ops) == r.cfg.MaxTxnOps-1 {
+				if err = d.commit(ctx, ops, 0); err != nil {
+					return 0, err
+				}
+				ops = ops[:0]
+			}
+		}
+	}
+	if err = <-serrc; err != nil {
+		return 0, err
+	}
+	for key := range existing {
+		ops = append(ops, replicaOp{key: key, delete: true})
+		deletes++
+		if len(ops) == r.cfg.MaxTxnOps-1 {
+			if err = d.commit(ctx, ops, 0); err != nil {
+				return 0, err
+			}
+			ops = ops[:0]
+		}
+	}
+	if err = d.commit(ctx, ops, rev); err != nil {
+		return 0, err
+	}
+	r.lg.Info("synced replication destination fully", zap.Int64("revision", rev), zap.Int("puts", puts), zap.Int("deletes", deletes))
+	return rev, nil
+}
+
+// watch replicates the source changes after the given revision until the
+// watch fails.
+func (r *Replicator) watch(ctx context.Context, src *endpoint, d *destination, rev int64) error {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	wch := src.c.Watch(wctx, src.prefix, clientv3.WithPrefix(), clientv3.WithRev(rev+1), clientv3.WithPrevKV(), clientv3.WithProgressNotify())
+	d.rev = rev
+	// seen is the last source revision whose events were all replicated
+	var seen int64
+
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	// behind is when the destination was found behind the source, or zero
+	// if it is caught up.
+	var behind time.Time
+	for {
+		select {
+		case wresp, ok := <-wch:
+			if !ok {
+				return ctx.Err()
+			}
+			if wresp.CompactRevision != 0 {
+				return rpctypes.ErrCompacted
+			}
+			if err := wresp.Err(); err != nil {
+				return err
+			}
+			if err := r.apply(ctx, src, d, wresp.Events); err != nil {
+				return err
+			}
+			// the header holds the revision of the source when sent, which
+			// is ahead of the events while the watch catches up
+			applied := wresp.Header.Revision
+			if n := len(wresp.Events); n != 0 {
+				applied = wresp.Events[n-1].Kv.ModRevision
+			}
+			if len(wresp.Events) != 0 || wresp.IsProgressNotify() {
+				seen = applied
+			}
+			lag := wresp.Header.Revision - applied
+			replicationLagRevisions.WithLabelValues(r.cfg.Name).Set(float64(lag))
+			switch {
+			case lag == 0:
+				behind = time.Time{}
+			case behind.IsZero():
+				behind = time.Now()
+			}
+		case <-ticker.C:
+		}
+		if seen > d.rev && time.Since(d.checkpointed) >= checkpointInterval {
+			if err := d.commit(ctx, nil, seen); err != nil {
+				return err
+			}
+		}
+		var lagSeconds float64
+		if !behind.IsZero() {
+			lagSeconds = time.Since(behind).Seconds()
+		}
+		replicationLagSeconds.WithLabelValues(r.cfg.Name).Set(lagSeconds)
+	}
+}
+
+// apply replicates the events of a watch response, which hold every event of
+// their revisions, in batches of transactions checkpointing the revision.
+func (r *Replicator) apply(ctx context.Context, src *endpoint, d *destination, evs []*clientv3.Event) error {
+	var ops []replicaOp
+	keys := make(map[string]struct{})
+	for _, ev := range evs {
+		op, ok := r.replicaOp(src, d.ep, ev)
+		if !ok {
+			continue
+		}
+		// a key changes once per transaction
+		if _, dup := keys[op.key]; dup || len(ops) == r.cfg.MaxTxnOps-1 {
+			// the revision of the event may be partially replicated, which
+			// is harmless as replaying a replicated change is a no-op
+			if err := d.commit(ctx, ops, ev.Kv.ModRevision-1); err != nil {
+				return err
+			}
+			replicationEvents.WithLabelValues(r.cfg.Name).Add(float64(len(ops)))
+			ops, keys = ops[:0], make(map[string]struct{})
+		}
+		ops = append(ops, op)
+		keys[op.key] = struct{}{}
+	}
+	if len(ops) == 0 {
+		return nil
+	}
+	if err := d.commit(ctx, ops, evs[len(evs)-1].Kv.ModRevision); err != nil {
+		return err
+	}
+	replicationEvents.WithLabelValues(r.cfg.Name).Add(float64(len(ops)))
+	return nil
+}
+
+// replicaOp returns the destination change of a source event, checked
+// against the previous source value if known, or false if the key is not
+// replicated.
+func (r *Replicator) replicaOp(src, dst *endpoint, ev *clientv3.Event) (replicaOp, bool) {
+	key, ok := r.destKey(src, dst, ev.Kv.Key)
+	if !ok {
+		return replicaOp{}, false
+	}
+	op := replicaOp{key: key}
+	switch ev.Type {
+	case clientv3.EventTypePut:
+		op.value = ev.Kv.Value
+	case clientv3.EventTypeDelete:
+		op.delete = true
+	}
+	switch {
+	case ev.PrevKv != nil:
+		op.check, op.prev, op.prevExists = true, ev.PrevKv.Value, true
+	case ev.Type == clientv3.EventTypePut && ev.Kv.Version == 1:
+		// a created key
+		op.check = true
+	}
+	return op, true
+}
+
+// suffix returns the suffix of a key after the replicated prefix of the given
+// cluster, or false if the key is not replicated.
+func (r *Replicator) suffix(ep *endpoint, key []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(key, []byte(ep.prefix)) || bytes.HasPrefix(key, []byte(r.cfg.MetaPrefix)) {
+		return nil, false
+	}
+	suffix := key[len(ep.prefix):]
+	if r.cfg.Filter != nil && !r.cfg.Filter(suffix) {
+		return nil, false
+	}
+	return suffix, true
+}
+
+// destKey maps a source key to the destination, or returns false if the key
+// is not replicated.
+func (r *Replicator) destKey(src, dst *endpoint, key []byte) (string, bool) {
+	suffix, ok := r.suffix(src, key)
+	if !ok {
+		return "", false
+	}
+	dkey := dst.prefix + string(suffix)
+	if strings.HasPrefix(dkey, r.cfg.MetaPrefix) {
+		return "", false
+	}
+	return dkey, true
+}
+
+// replicaOp is a change of a destination key.
+type replicaOp struct {
+	key    string
+	value  []byte
+	delete bool
+	// check is whether the key is expected to hold the previous source
+	// value, prev, or to be absent if prevExists is false.
+	check      bool
+	prev       []byte
+	prevExists bool
+}
+
+func (op *replicaOp) op() clientv3.Op {
+	if op.delete {
+		return clientv3.OpDelete(op.key)
+	}
+	return clientv3.OpPut(op.key, string(op.value))
+}
+
+func (op *replicaOp) cmp() clientv3.Cmp {
+	if op.prevExists {
+		return clientv3.Compare(clientv3.Value(op.key), "=", string(op.prev))
+	}
+	return clientv3.Compare(clientv3.CreateRevision(op.key), "=", 0)
+}
+
+// applied returns whether the given destination key-value already reflects
+// the change.
+func (op *replicaOp) applied(kv *mvccpb.KeyValue) bool {
+	if op.delete {
+		return kv == nil
+	}
+	return kv != nil && bytes.Equal(kv.Value, op.value)
+}
+
+// expected returns whether the given destination key-value is the expected
+// previous one.
+func (op *replicaOp) expected(kv *mvccpb.KeyValue) bool {
+	if !op.prevExists {
+		return kv == nil
+	}
+	return kv != nil && bytes.Equal(kv.Value, op.prev)
+}
+
+// destination writes the replicated changes to the passive cluster, in
+// transactions fenced by the modification revision of its replication state.
+type destination struct {
+	r  *Replicator
+	ep *endpoint
+	// state is the replication state written with the changes.
+	state replicationState
+	// fence is the expected modification revision of the replication state.
+	fence int64
+	// rev is the last checkpointed revision, and checkpointed when it was
+	// written.
+	rev          int64
+	checkpointed time.Time
+}
+
+// commit writes the given changes, and the replication state with the given
+// checkpoint revision, or none if zero. The checked changes of keys changed
+// outside of the replication are resolved by the conflict policy.
+func (d *destination) commit(ctx context.Context, ops []replicaOp, rev int64) error {
+	key := d.r.stateKey()
+	st := d.state
+	st.Revision = rev
+	for {
+		cmps := []clientv3.Cmp{clientv3.Compare(clientv3.ModRevision(key), "=", d.fence)}
+		thens := make([]clientv3.Op, 0, len(ops)+1)
+		elses := []clientv3.Op{clientv3.OpGet(key)}
+		for i := range ops {
+			if ops[i].check {
+				cmps = append(cmps, ops[i].cmp())
+				elses = append(elses, clientv3.OpGet(ops[i].key))
+			}
+			thens = append(thens, ops[i].op())
+		}
+		thens = append(thens, clientv3.OpPut(key, encodeState(&st)))
+		resp, err := d.ep.c.Txn(ctx).If(cmps...).Then(thens...).Else(elses...).Commit()
+		if err != nil {
+			return err
+		}
+		if resp.Succeeded {
+			d.fence = resp.Header.Revision
+			if rev != 0 {
+				d.rev, d.checkpointed = rev, time.Now()
+			}
+			return nil
+		}
+		kvs := resp.Responses[0].GetResponseRange().Kvs
+		if len(kvs) == 0 || kvs[0].ModRevision != d.fence {
+			return ErrFenced
+		}
+		ops = d.resolve(ops, resp.Responses[1:])
+	}
+}
+
+// resolve returns the changes to retry given the current destination
+// key-values of the checked changes.
+func (d *destination) resolve(ops []replicaOp, resps []*pb.ResponseOp) []replicaOp {
+	resolved := ops[:0]
+	for _, op := range ops {
+		if !op.check {
+			resolved = append(resolved, op)
+			continue
+		}
+		var kv *mvccpb.KeyValue
+		if kvs := resps[0].GetResponseRange().Kvs; len(kvs) != 0 {
+			kv = kvs[0]
+		}
+		resps = resps[1:]
+		switch {
+		case op.applied(kv):
+			// replayed after a restart
+			continue
+		case op.expected(kv):
+			// changed back concurrently
+		case d.r.cfg.ConflictPolicy == ConflictKeep:
+			d.r.lg.Warn("replication conflict; keeping the destination", zap.String("key", op.key))
+			replicationConflicts.WithLabelValues(d.r.cfg.Name, "kept").Inc()
+			continue
+		default:
+			d.r.lg.Warn("replication conflict; overwriting the destination", zap.String("key", op.key))
+			replicationConflicts.WithLabelValues(d.r.cfg.Name, "overwritten").Inc()
+			op.prev, op.prevExists = nil, kv != nil
+			if kv != nil {
+				op.prev = kv.Value
+			}
+		}
+		resolved = append(resolved, op)
+	}
+	return resolved
+}
+
+// Promote makes the cluster of the given client the active cluster of the
+// replication with the given name and state key prefix, as for a failover,
+// and returns its epoch. The replication reverses once it observes the new
+// state. Promoting the active cluster does nothing.
+func Promote(ctx context.Context, c *clientv3.Client, metaPrefix, name string) (int64, error) {
+	if metaPrefix == "" {
+		metaPrefix = DefaultMetaPrefix
+	}
+	key := metaPrefix + name
+	resp, err := c.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, ErrNoReplicationState
+	}
+	st, err := decodeState(resp.Kvs[0].Value)
+	if err != nil {
+		return 0, err
+	}
+	if st.Active {
+		return st.Epoch, nil
+	}
+	// the epoch of a passive cluster is the epoch of the active cluster
+	st = &replicationState{Epoch: st.Epoch + 1, Active: true}
+	tresp, err := c.Txn(ctx).If(
+		clientv3.Compare(clientv3.ModRevision(key), "=", resp.Kvs[0].ModRevision),
+	).Then(
+		clientv3.OpPut(key, encodeState(st)),
+	).Commit()
+	if err != nil {
+		return 0, err
+	}
+	if !tresp.Succeeded {
+		return 0, ErrFenced
+	}
+	return st.Epoch, nil
+}
@@ -122,6 +122,11 @@ func (txn *txnPrefix) Else(ops ...clientv3.Op) clientv3.Txn {
 	return txn
 }
 
+func (txn *txnPrefix) ReadSet(rs ...clientv3.ReadSetEntry) clientv3.Txn {
+	txn.Txn = txn.Txn.ReadSet(txn.kv.prefixReadSet(rs)...)
+	return txn
+}
+
 func (txn *txnPrefix) Commit() (*clientv3.TxnResponse, error) {
 	resp, err := txn.Txn.Commit()
 	if err != nil {
@@ -139,7 +144,9 @@ func (kv *kvPrefix) prefixOp(op clientv3.Op) clientv3.Op {
 		return op
 	}
 	cmps, thenOps, elseOps := op.Txn()
-	return clientv3.OpTxn(kv.prefixCmps(cmps), kv.prefixOps(thenOps), kv.prefixOps(elseOps))
+	txnOp := clientv3.OpTxn(kv.prefixCmps(cmps), kv.prefixOps(thenOps), kv.prefixOps(elseOps))
+	txnOp.WithReadSet(kv.prefixReadSet(op.ReadSet()))
+	return txnOp
 }
 
 func (kv *kvPrefix) unprefixGetResponse(resp *clientv3.GetResponse) {
@@ -161,6 +168,9 @@ func (kv *kvPrefix) unprefixDeleteResponse(resp *clientv3.DeleteResponse) {
 }
 
 func (kv *kvPrefix) unprefixTxnResponse(resp *clientv3.TxnResponse) {
+	for _, c := range resp.Conflicts {
+		c.Key = c.Key[len(kv.pfx):]
+	}
 	for _, r := range resp.Responses {
 		switch tv := r.Response.(type) {
 		case *pb.ResponseOp_ResponseRange:
@@ -201,6 +211,18 @@ func (kv *kvPrefix) prefixCmps(cs []clientv3.Cmp) []clientv3.Cmp {
 	return newCmps
 }
 
+func (kv *kvPrefix) prefixReadSet(rs []clientv3.ReadSetEntry) []clientv3.ReadSetEntry {
+	if rs == nil {
+		return nil
+	}
+	newReadSet := make([]clientv3.ReadSetEntry, len(rs))
+	for i := range rs {
+		newReadSet[i] = rs[i]
+		newReadSet[i].Key = append([]byte(kv.pfx), rs[i].Key...)
+	}
+	return newReadSet
+}
+
 func (kv *kvPrefix) prefixOps(ops []clientv3.Op) []clientv3.Op {
 	newOps := make([]clientv3.Op, len(ops))
 	for i := range ops {
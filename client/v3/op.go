@@ -14,7 +14,11 @@
 
 package clientv3
 
-import pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+import (
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
 
 type opType int
 
@@ -44,6 +48,10 @@ type Op struct {
 	maxModRev    int64
 	minCreateRev int64
 	maxCreateRev int64
+	filters      []*pb.RangeFilter
+	continueTok  string
+	maxStale     time.Duration
+	maxStaleRevs int64
 
 	// for range, watch
 	rev int64
@@ -60,6 +68,7 @@ type Op struct {
 	// for put
 	ignoreValue bool
 	ignoreLease bool
+	ttl         int64
 
 	// progressNotify is for progress updates.
 	progressNotify bool
@@ -68,6 +77,12 @@ type Op struct {
 	// filters for watchers
 	filterPut    bool
 	filterDelete bool
+	keyGlob      string
+	keyRegex     string
+	// omitUnchanged omits the values of puts that do not change them
+	omitUnchanged bool
+	// coalesceWindow delays events to merge the changes of the same key
+	coalesceWindow time.Duration
 
 	// for put
 	val     []byte
@@ -77,6 +92,7 @@ type Op struct {
 	cmps    []Cmp
 	thenOps []Op
 	elseOps []Op
+	readSet []ReadSetEntry
 
 	isOptsWithFromKey bool
 	isOptsWithPrefix  bool
@@ -94,6 +110,12 @@ func (op Op) Txn() ([]Cmp, []Op, []Op) {
 	return op.cmps, op.thenOps, op.elseOps
 }
 
+// ReadSet returns the read set of a "txn" Op, if any.
+func (op Op) ReadSet() []ReadSetEntry { return op.readSet }
+
+// WithReadSet sets the read set of a "txn" Op.
+func (op *Op) WithReadSet(rs []ReadSetEntry) { op.readSet = rs }
+
 // KeyBytes returns the byte slice holding the Op's key.
 func (op Op) KeyBytes() []byte { return op.key }
 
@@ -136,9 +158,24 @@ func (op Op) MinCreateRev() int64 { return op.minCreateRev }
 // MaxCreateRev returns the operation's maximum create revision.
 func (op Op) MaxCreateRev() int64 { return op.maxCreateRev }
 
+// Filters returns the operation's range filters.
+func (op Op) Filters() []*pb.RangeFilter { return op.filters }
+
+// ContinueToken returns the operation's continue token.
+func (op Op) ContinueToken() string { return op.continueTok }
+
+// MaxStaleness returns the operation's staleness bound in time.
+func (op Op) MaxStaleness() time.Duration { return op.maxStale }
+
+// MaxStalenessRevisions returns the operation's staleness bound in revisions.
+func (op Op) MaxStalenessRevisions() int64 { return op.maxStaleRevs }
+
 // WithRangeBytes sets the byte slice for the Op's range end.
 func (op *Op) WithRangeBytes(end []byte) { op.end = end }
 
+// TTL returns the time to live in seconds of a 'Put' Op, if any.
+func (op Op) TTL() int64 { return op.ttl }
+
 // ValueBytes returns the byte slice holding the Op's value, if any.
 func (op Op) ValueBytes() []byte { return op.val }
 
@@ -161,6 +198,11 @@ func (op Op) toRangeRequest() *pb.RangeRequest {
 		MaxModRevision:    op.maxModRev,
 		MinCreateRevision: op.minCreateRev,
 		MaxCreateRevision: op.maxCreateRev,
+		Filters:           op.filters,
+		ContinueToken:     op.continueTok,
+
+		MaxStalenessMs:        op.maxStale.Milliseconds(),
+		MaxStalenessRevisions: op.maxStaleRevs,
 	}
 	if op.sort != nil {
 		r.SortOrder = pb.RangeRequest_SortOrder(op.sort.Order)
@@ -182,7 +224,11 @@ func (op Op) toTxnRequest() *pb.TxnRequest {
 	for i := range op.cmps {
 		cmps[i] = (*pb.Compare)(&op.cmps[i])
 	}
-	return &pb.TxnRequest{Compare: cmps, Success: thenOps, Failure: elseOps}
+	var readSet []*pb.ReadSetEntry
+	for i := range op.readSet {
+		readSet = append(readSet, (*pb.ReadSetEntry)(&op.readSet[i]))
+	}
+	return &pb.TxnRequest{Compare: cmps, Success: thenOps, Failure: elseOps, ReadSet: readSet}
 }
 
 func (op Op) toRequestOp() *pb.RequestOp {
@@ -190,7 +236,7 @@ func (op Op) toRequestOp() *pb.RequestOp {
 	case tRange:
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestRange{RequestRange: op.toRangeRequest()}}
 	case tPut:
-		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease}
+		r := &pb.PutRequest{Key: op.key, Value: op.val, Lease: int64(op.leaseID), PrevKv: op.prevKV, IgnoreValue: op.ignoreValue, IgnoreLease: op.ignoreLease, Ttl: op.ttl}
 		return &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: r}}
 	case tDeleteRange:
 		r := &pb.DeleteRangeRequest{Key: op.key, RangeEnd: op.end, PrevKv: op.prevKV}
@@ -253,6 +299,8 @@ func OpDelete(key string, opts ...OpOption) Op {
 		panic("unexpected sort in delete")
 	case ret.serializable:
 		panic("unexpected serializable in delete")
+	case ret.maxStale != 0, ret.maxStaleRevs != 0:
+		panic("unexpected max staleness in delete")
 	case ret.countOnly:
 		panic("unexpected countOnly in delete")
 	case ret.minModRev != 0, ret.maxModRev != 0:
@@ -282,6 +330,8 @@ func OpPut(key, val string, opts ...OpOption) Op {
 		panic("unexpected sort in put")
 	case ret.serializable:
 		panic("unexpected serializable in put")
+	case ret.maxStale != 0, ret.maxStaleRevs != 0:
+		panic("unexpected max staleness in put")
 	case ret.countOnly:
 		panic("unexpected countOnly in put")
 	case ret.minModRev != 0, ret.maxModRev != 0:
@@ -313,12 +363,16 @@ func opWatch(key string, opts ...OpOption) Op {
 		panic("unexpected sort in watch")
 	case ret.serializable:
 		panic("unexpected serializable in watch")
+	case ret.maxStale != 0, ret.maxStaleRevs != 0:
+		panic("unexpected max staleness in watch")
 	case ret.countOnly:
 		panic("unexpected countOnly in watch")
 	case ret.minModRev != 0, ret.maxModRev != 0:
 		panic("unexpected mod revision filter in watch")
 	case ret.minCreateRev != 0, ret.maxCreateRev != 0:
 		panic("unexpected create revision filter in watch")
+	case ret.continueTok != "":
+		panic("unexpected continue token in watch")
 	}
 	return ret
 }
@@ -337,10 +391,59 @@ func WithLease(leaseID LeaseID) OpOption {
 	return func(op *Op) { op.leaseID = leaseID }
 }
 
+// WithTTL sets a time to live in seconds on a key in 'Put' request. The key
+// is deleted once the TTL expires, without the need to grant a lease.
+// This option can not be combined with WithLease or WithIgnoreLease.
+func WithTTL(ttl int64) OpOption {
+	return func(op *Op) { op.ttl = ttl }
+}
+
 // WithLimit limits the number of results to return from 'Get' request.
 // If WithLimit is given a 0 limit, it is treated as no limit.
 func WithLimit(n int64) OpOption { return func(op *Op) { op.limit = n } }
 
+// WithFilter restricts 'Get' request to the keys matching all the given
+// filters. The server evaluates the filters before applying the limit, and
+// the count of the response only includes the matching keys.
+// In 'Watch' request, it discards the PUT events whose key-value does not
+// match all the filters; DELETE events are not affected.
+func WithFilter(filters ...RangeFilter) OpOption {
+	return func(op *Op) {
+		for i := range filters {
+			f := pb.RangeFilter(filters[i])
+			op.filters = append(op.filters, &f)
+		}
+	}
+}
+
+// WithContinue resumes a paginated 'Get' request from the ContinueToken of the
+// previous response. The request must otherwise be the same as the request of
+// the first page; keys are returned in key order at the revision of the first
+// page. See also Pager.
+func WithContinue(token string) OpOption {
+	return func(op *Op) { op.continueTok = token }
+}
+
+// WithMaxStaleness lets a linearizable 'Get' request be served by a follower
+// whose state is at most d behind the leader, measured from the last message
+// the follower handled from the leader. Otherwise the follower waits for its
+// state to catch up, or confirms the read with the leader. The bound has a
+// millisecond precision and is ignored by serializable requests. The revision
+// of the returned state is in the header of the response.
+func WithMaxStaleness(d time.Duration) OpOption {
+	return func(op *Op) { op.maxStale = d }
+}
+
+// WithMaxStalenessRevisions lets a linearizable 'Get' request be served by a
+// follower whose state is at most revs revisions behind the revision it knows
+// committed. The follower knows the revisions committed as of its last message
+// from the leader, within the election timeout, so the revisions committed
+// since then are not counted; use WithMaxStaleness to bound them as well. When
+// both are set, both bounds hold.
+func WithMaxStalenessRevisions(revs int64) OpOption {
+	return func(op *Op) { op.maxStaleRevs = revs }
+}
+
 // WithRev specifies the store revision for 'Get' request.
 // Or the start revision of 'Watch' request.
 func WithRev(rev int64) OpOption { return func(op *Op) { op.rev = rev } }
@@ -505,6 +608,33 @@ func WithPrevKV() OpOption {
 	}
 }
 
+// WithKeyGlob discards events of keys not matching the glob pattern from the
+// watcher. The pattern uses the syntax of path.Match and must match the whole
+// key, e.g. "/pods/*/status".
+func WithKeyGlob(pattern string) OpOption {
+	return func(op *Op) { op.keyGlob = pattern }
+}
+
+// WithKeyRegex discards events of keys not matching the regular expression
+// from the watcher. The expression must match the whole key.
+func WithKeyRegex(expr string) OpOption {
+	return func(op *Op) { op.keyRegex = expr }
+}
+
+// WithOmitUnchangedValue omits the value of PUT events that do not change the
+// value of the key, such as lease updates. Such events have UnchangedValue set.
+func WithOmitUnchangedValue() OpOption {
+	return func(op *Op) { op.omitUnchanged = true }
+}
+
+// WithCoalesceWindow delays the events of the watcher by up to window and
+// merges the events of a key within the window into its latest event, which
+// then has CoalescedStartRevision set to the revision of the first merged
+// change. The window is at most 10 seconds.
+func WithCoalesceWindow(window time.Duration) OpOption {
+	return func(op *Op) { op.coalesceWindow = window }
+}
+
 // WithFragment to receive raw watch response with fragmentation.
 // Fragmentation is disabled by default. If fragmentation is enabled,
 // etcd watch server will split watch response before sending to clients
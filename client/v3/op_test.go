@@ -17,6 +17,7 @@ package clientv3
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 )
@@ -37,6 +38,39 @@ func TestOpWithSort(t *testing.T) {
 	}
 }
 
+func TestOpWithTTL(t *testing.T) {
+	req := OpPut("foo", "bar", WithTTL(10)).toRequestOp().GetRequestPut()
+	wreq := &pb.PutRequest{Key: []byte("foo"), Value: []byte("bar"), Ttl: 10}
+	if !reflect.DeepEqual(req, wreq) {
+		t.Fatalf("expected %+v, got %+v", wreq, req)
+	}
+}
+
+func TestOpWithFilter(t *testing.T) {
+	op := OpGet("foo", WithPrefix(), WithFilter(
+		Filter(VersionFilter(), ">", 1),
+		Filter(JSONFilter("spec.replicas"), "=", 3),
+		Filter(ValueFilter(), "prefix", "{"),
+	))
+	req := op.toRangeRequest()
+	wfilters := []*pb.RangeFilter{
+		{Result: pb.RangeFilter_GREATER, Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 1}},
+		{Result: pb.RangeFilter_EQUAL, Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte("3")}, JsonPath: "spec.replicas"},
+		{Result: pb.RangeFilter_PREFIX, Target: pb.RangeFilter_VALUE, TargetUnion: &pb.RangeFilter_Value{Value: []byte("{")}},
+	}
+	if !reflect.DeepEqual(req.Filters, wfilters) {
+		t.Fatalf("expected %+v, got %+v", wfilters, req.Filters)
+	}
+}
+
+func TestOpWithMaxStaleness(t *testing.T) {
+	req := OpGet("foo", WithMaxStaleness(500*time.Millisecond), WithMaxStalenessRevisions(100)).toRangeRequest()
+	wreq := &pb.RangeRequest{Key: []byte("foo"), MaxStalenessMs: 500, MaxStalenessRevisions: 100}
+	if !reflect.DeepEqual(req, wreq) {
+		t.Fatalf("expected %+v, got %+v", wreq, req)
+	}
+}
+
 func TestIsSortOptionValid(t *testing.T) {
 	rangeReqs := []struct {
 		sortOrder     pb.RangeRequest_SortOrder
@@ -84,6 +84,7 @@ func (kv *kvOrdering) Txn(ctx context.Context) clientv3.Txn {
 		[]clientv3.Cmp{},
 		[]clientv3.Op{},
 		[]clientv3.Op{},
+		nil,
 	}
 }
 
@@ -98,6 +99,7 @@ type txnOrdering struct {
 	cmps    []clientv3.Cmp
 	thenOps []clientv3.Op
 	elseOps []clientv3.Op
+	readSet []clientv3.ReadSetEntry
 }
 
 func (txn *txnOrdering) If(cs ...clientv3.Cmp) clientv3.Txn {
@@ -124,6 +126,14 @@ func (txn *txnOrdering) Else(ops ...clientv3.Op) clientv3.Txn {
 	return txn
 }
 
+func (txn *txnOrdering) ReadSet(rs ...clientv3.ReadSetEntry) clientv3.Txn {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+	txn.readSet = rs
+	txn.Txn.ReadSet(rs...)
+	return txn
+}
+
 func (txn *txnOrdering) Commit() (*clientv3.TxnResponse, error) {
 	// prevRev is stored in a local variable in order to record the prevRev
 	// at the beginning of the Commit operation, because concurrent
@@ -131,6 +141,7 @@ func (txn *txnOrdering) Commit() (*clientv3.TxnResponse, error) {
 	// middle of the Commit operation.
 	prevRev := txn.getPrevRev()
 	opTxn := clientv3.OpTxn(txn.cmps, txn.thenOps, txn.elseOps)
+	opTxn.WithReadSet(txn.readSet)
 	for {
 		opResp, err := txn.KV.Do(txn.ctx, opTxn)
 		if err != nil {
@@ -139,6 +139,7 @@ func TestTxnOrdering(t *testing.T) {
 			[]clientv3.Cmp{},
 			[]clientv3.Op{},
 			[]clientv3.Op{},
+			nil,
 		}
 		res, err := txn.Commit()
 		if err != nil {
@@ -0,0 +1,90 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrNoContinueToken is returned by Pager when the server returns a partial
+// range without a continue token, either because it does not support
+// pagination or because the options do not keep the keys in key order.
+var ErrNoContinueToken = errors.New("etcdclient: server did not return a continue token")
+
+// Pager iterates over the pages of a 'Get' request using continue tokens,
+// so that large ranges are fetched in bounded chunks at a single revision.
+//
+//	p := clientv3.NewPager(cli, "foo", 500, clientv3.WithPrefix())
+//	for p.Next(ctx) {
+//		for _, kv := range p.Response().Kvs {
+//			...
+//		}
+//	}
+//	if err := p.Err(); err != nil {
+//		...
+//	}
+//
+// If the revision of the first page is compacted before the last page is
+// fetched, Next fails with rpctypes.ErrCompacted and the range has to be
+// restarted.
+type Pager struct {
+	kv       KV
+	key      string
+	pageSize int64
+	opts     []OpOption
+
+	resp  *GetResponse
+	token string
+	done  bool
+	err   error
+}
+
+// NewPager creates a Pager fetching at most pageSize keys per page. The
+// options are the options of the 'Get' request and must keep the keys in
+// ascending key order; WithLimit and WithContinue are set by the pager.
+func NewPager(kv KV, key string, pageSize int64, opts ...OpOption) *Pager {
+	return &Pager{kv: kv, key: key, pageSize: pageSize, opts: opts}
+}
+
+// Next fetches the next page. It returns false once all pages are fetched or
+// a request failed, see Err. The page that stopped the iteration because it
+// lacked a continue token is still returned.
+func (p *Pager) Next(ctx context.Context) bool {
+	if p.done {
+		return false
+	}
+	opts := append(append([]OpOption{}, p.opts...), WithLimit(p.pageSize), WithContinue(p.token))
+	resp, err := p.kv.Get(ctx, p.key, opts...)
+	if err != nil {
+		p.resp, p.err, p.done = nil, err, true
+		return false
+	}
+	p.resp, p.token = resp, resp.ContinueToken
+	if p.token == "" {
+		p.done = true
+		if resp.More {
+			// members older than v3.6 do not issue continue tokens
+			p.err = ErrNoContinueToken
+		}
+	}
+	return true
+}
+
+// Response returns the page fetched by the last call to Next.
+func (p *Pager) Response() *GetResponse { return p.resp }
+
+// Err returns the error of the request that stopped the iteration, if any.
+func (p *Pager) Err() error { return p.err }
@@ -0,0 +1,77 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3
+
+import (
+	"context"
+	"testing"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// pagedKV serves the pages of a range, recording the requests it gets.
+type pagedKV struct {
+	KV
+	pages []*GetResponse
+	reqs  []*pb.RangeRequest
+}
+
+func (kv *pagedKV) Get(ctx context.Context, key string, opts ...OpOption) (*GetResponse, error) {
+	op := OpGet(key, opts...)
+	kv.reqs = append(kv.reqs, op.toRangeRequest())
+	resp := kv.pages[0]
+	kv.pages = kv.pages[1:]
+	return resp, nil
+}
+
+func TestPager(t *testing.T) {
+	kv := &pagedKV{pages: []*GetResponse{
+		{Kvs: []*mvccpb.KeyValue{{Key: []byte("a")}, {Key: []byte("b")}}, More: true, ContinueToken: "t1"},
+		{Kvs: []*mvccpb.KeyValue{{Key: []byte("c")}}},
+	}}
+	p := NewPager(kv, "a", 2, WithPrefix())
+	var keys []string
+	for p.Next(context.TODO()) {
+		for _, kv := range p.Response().Kvs {
+			keys = append(keys, string(kv.Key))
+		}
+	}
+	if err := p.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 3 || keys[0] != "a" || keys[2] != "c" {
+		t.Fatalf("expected keys [a b c], got %v", keys)
+	}
+	if len(kv.reqs) != 2 || kv.reqs[0].ContinueToken != "" || kv.reqs[1].ContinueToken != "t1" || kv.reqs[1].Limit != 2 {
+		t.Fatalf("unexpected requests %+v", kv.reqs)
+	}
+}
+
+func TestPagerNoContinueToken(t *testing.T) {
+	kv := &pagedKV{pages: []*GetResponse{
+		{Kvs: []*mvccpb.KeyValue{{Key: []byte("a")}}, More: true},
+	}}
+	p := NewPager(kv, "a", 1, WithPrefix())
+	if !p.Next(context.TODO()) {
+		t.Fatal("expected the first page")
+	}
+	if p.Next(context.TODO()) {
+		t.Fatal("expected no more pages")
+	}
+	if p.Err() != ErrNoContinueToken {
+		t.Fatalf("expected %v, got %v", ErrNoContinueToken, p.Err())
+	}
+}
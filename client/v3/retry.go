@@ -214,6 +214,10 @@ func (rmc *retryMaintenanceClient) Snapshot(ctx context.Context, in *pb.Snapshot
 	return rmc.mc.Snapshot(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rmc *retryMaintenanceClient) SnapshotDelta(ctx context.Context, in *pb.SnapshotDeltaRequest, opts ...grpc.CallOption) (stream pb.Maintenance_SnapshotDeltaClient, err error) {
+	return rmc.mc.SnapshotDelta(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rmc *retryMaintenanceClient) MoveLeader(ctx context.Context, in *pb.MoveLeaderRequest, opts ...grpc.CallOption) (resp *pb.MoveLeaderResponse, err error) {
 	return rmc.mc.MoveLeader(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
@@ -226,6 +230,22 @@ func (rmc *retryMaintenanceClient) Downgrade(ctx context.Context, in *pb.Downgra
 	return rmc.mc.Downgrade(ctx, in, opts...)
 }
 
+func (rmc *retryMaintenanceClient) QuotaSet(ctx context.Context, in *pb.QuotaSetRequest, opts ...grpc.CallOption) (resp *pb.QuotaSetResponse, err error) {
+	return rmc.mc.QuotaSet(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) QuotaDelete(ctx context.Context, in *pb.QuotaDeleteRequest, opts ...grpc.CallOption) (resp *pb.QuotaDeleteResponse, err error) {
+	return rmc.mc.QuotaDelete(ctx, in, opts...)
+}
+
+func (rmc *retryMaintenanceClient) QuotaList(ctx context.Context, in *pb.QuotaListRequest, opts ...grpc.CallOption) (resp *pb.QuotaListResponse, err error) {
+	return rmc.mc.QuotaList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
+func (rmc *retryMaintenanceClient) RotateEncryptionKey(ctx context.Context, in *pb.RotateEncryptionKeyRequest, opts ...grpc.CallOption) (resp *pb.RotateEncryptionKeyResponse, err error) {
+	return rmc.mc.RotateEncryptionKey(ctx, in, opts...)
+}
+
 type retryAuthClient struct {
 	ac pb.AuthClient
 }
@@ -253,6 +273,10 @@ func (rac *retryAuthClient) RoleList(ctx context.Context, in *pb.AuthRoleListReq
 	return rac.ac.RoleList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
 }
 
+func (rac *retryAuthClient) TenantList(ctx context.Context, in *pb.AuthTenantListRequest, opts ...grpc.CallOption) (resp *pb.AuthTenantListResponse, err error) {
+	return rac.ac.TenantList(ctx, in, append(opts, withRetryPolicy(repeatable))...)
+}
+
 func (rac *retryAuthClient) AuthEnable(ctx context.Context, in *pb.AuthEnableRequest, opts ...grpc.CallOption) (resp *pb.AuthEnableResponse, err error) {
 	return rac.ac.AuthEnable(ctx, in, opts...)
 }
@@ -301,6 +325,14 @@ func (rac *retryAuthClient) RoleRevokePermission(ctx context.Context, in *pb.Aut
 	return rac.ac.RoleRevokePermission(ctx, in, opts...)
 }
 
+func (rac *retryAuthClient) TenantAdd(ctx context.Context, in *pb.AuthTenantAddRequest, opts ...grpc.CallOption) (resp *pb.AuthTenantAddResponse, err error) {
+	return rac.ac.TenantAdd(ctx, in, opts...)
+}
+
+func (rac *retryAuthClient) TenantDelete(ctx context.Context, in *pb.AuthTenantDeleteRequest, opts ...grpc.CallOption) (resp *pb.AuthTenantDeleteResponse, err error) {
+	return rac.ac.TenantDelete(ctx, in, opts...)
+}
+
 func (rac *retryAuthClient) Authenticate(ctx context.Context, in *pb.AuthenticateRequest, opts ...grpc.CallOption) (resp *pb.AuthenticateResponse, err error) {
 	return rac.ac.Authenticate(ctx, in, opts...)
 }
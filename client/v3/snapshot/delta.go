@@ -0,0 +1,269 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var errDeltaDigestMismatch = errors.New("sha256 digest of snapshot delta does not match its content")
+
+// DeltaInfo describes a snapshot delta, which brings a snapshot at
+// BaseRevision to Revision. BaseHash and Hash are the hashes of the key
+// space at both revisions, ignoring the revisions before BaseRevision.
+type DeltaInfo struct {
+	BaseRevision int64
+	Revision     int64
+	BaseHash     uint32
+	Hash         uint32
+	// FullBuckets are the buckets stored as a whole in the delta, which
+	// replace the buckets of the same name on restore.
+	FullBuckets [][]byte
+}
+
+// SaveDelta fetches the delta between baseRev and the current revision from
+// remote etcd server and saves it to target path. The delta file holds the
+// length-delimited responses of the stream followed by their SHA-256 digest.
+// Make sure to specify only one endpoint in client configuration, as the
+// delta is a point-in-time state of the selected node.
+func SaveDelta(ctx context.Context, lg *zap.Logger, cfg clientv3.Config, baseRev int64, path string) (*DeltaInfo, error) {
+	cfg.Logger = lg.Named("client")
+	if len(cfg.Endpoints) != 1 {
+		return nil, fmt.Errorf("snapshot delta must be requested to one selected node, not multiple %v", cfg.Endpoints)
+	}
+	cli, err := clientv3.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	defer cli.Close()
+
+	lg.Info("fetching snapshot delta", zap.String("endpoint", cfg.Endpoints[0]), zap.Int64("base-revision", baseRev))
+	return saveDelta(ctx, lg, cli, baseRev, path)
+}
+
+func saveDelta(ctx context.Context, lg *zap.Logger, m clientv3.Maintenance, baseRev int64, path string) (*DeltaInfo, error) {
+	partpath := path + ".part"
+	defer os.RemoveAll(partpath)
+
+	f, err := os.OpenFile(partpath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileutil.PrivateFileMode)
+	if err != nil {
+		return nil, fmt.Errorf("could not open %s (%v)", partpath, err)
+	}
+	defer f.Close()
+
+	start := time.Now()
+	sc, err := m.SnapshotDelta(ctx, baseRev)
+	if err != nil {
+		return nil, err
+	}
+	h := sha256.New()
+	w := bufio.NewWriter(io.MultiWriter(f, h))
+	var (
+		info    *DeltaInfo
+		entries int
+	)
+	for {
+		resp, err := sc.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if info == nil {
+			if resp.BaseRevision != baseRev {
+				return nil, fmt.Errorf("unexpected base revision %d of snapshot delta, expected %d", resp.BaseRevision, baseRev)
+			}
+			info = deltaInfo(resp)
+		}
+		entries += len(resp.Entries)
+		if err = writeDeltaRecord(w, resp); err != nil {
+			return nil, err
+		}
+	}
+	if info == nil {
+		return nil, errors.New("snapshot delta stream ended without a response")
+	}
+	if err = w.Flush(); err != nil {
+		return nil, err
+	}
+	if _, err = f.Write(h.Sum(nil)); err != nil {
+		return nil, err
+	}
+	if err = fileutil.Fsync(f); err != nil {
+		return nil, err
+	}
+	size, err := f.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+	lg.Info("fetched snapshot delta",
+		zap.Int64("base-revision", info.BaseRevision),
+		zap.Int64("revision", info.Revision),
+		zap.Int("entries", entries),
+		zap.String("size", humanize.Bytes(uint64(size))),
+		zap.Duration("took", time.Since(start)),
+	)
+
+	if err = os.Rename(partpath, path); err != nil {
+		return nil, fmt.Errorf("could not rename %s to %s (%v)", partpath, path, err)
+	}
+	lg.Info("saved", zap.String("path", path))
+	return info, nil
+}
+
+func deltaInfo(resp *pb.SnapshotDeltaResponse) *DeltaInfo {
+	return &DeltaInfo{
+		BaseRevision: resp.BaseRevision,
+		Revision:     resp.Revision,
+		BaseHash:     resp.BaseHash,
+		Hash:         resp.Hash,
+		FullBuckets:  resp.FullBuckets,
+	}
+}
+
+func writeDeltaRecord(w io.Writer, resp *pb.SnapshotDeltaResponse) error {
+	data, err := resp.Marshal()
+	if err != nil {
+		return err
+	}
+	var buf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(buf[:], uint64(len(data)))
+	if _, err = w.Write(buf[:n]); err != nil {
+		return err
+	}
+	_, err = w.Write(data)
+	return err
+}
+
+// DeltaReader reads the entries of a snapshot delta file.
+type DeltaReader struct {
+	Info DeltaInfo
+
+	f *os.File
+	r *bufio.Reader
+	// pending holds the entries of the first response, read on open.
+	pending []*pb.SnapshotDeltaEntry
+}
+
+// OpenDelta opens the snapshot delta file at path after verifying its
+// SHA-256 digest.
+func OpenDelta(path string) (*DeltaReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dr, err := openDelta(f)
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("invalid snapshot delta %s (%v)", path, err)
+	}
+	return dr, nil
+}
+
+func openDelta(f *os.File) (*DeltaReader, error) {
+	st, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := st.Size() - sha256.Size
+	if size <= 0 {
+		return nil, io.ErrUnexpectedEOF
+	}
+	h := sha256.New()
+	if _, err = io.CopyN(h, f, size); err != nil {
+		return nil, err
+	}
+	sum := make([]byte, sha256.Size)
+	if _, err = io.ReadFull(f, sum); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sum, h.Sum(nil)) {
+		return nil, errDeltaDigestMismatch
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	dr := &DeltaReader{f: f, r: bufio.NewReader(io.LimitReader(f, size))}
+	resp, err := readDeltaRecord(dr.r)
+	if err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	dr.Info = *deltaInfo(resp)
+	dr.pending = resp.Entries
+	return dr, nil
+}
+
+// Next returns the next batch of entries of the delta, or io.EOF after the
+// last one.
+func (dr *DeltaReader) Next() ([]*pb.SnapshotDeltaEntry, error) {
+	if len(dr.pending) > 0 {
+		ents := dr.pending
+		dr.pending = nil
+		return ents, nil
+	}
+	resp, err := readDeltaRecord(dr.r)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Entries, nil
+}
+
+// Close closes the delta file.
+func (dr *DeltaReader) Close() error {
+	return dr.f.Close()
+}
+
+func readDeltaRecord(r *bufio.Reader) (*pb.SnapshotDeltaResponse, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	data := make([]byte, n)
+	if _, err = io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	resp := &pb.SnapshotDeltaResponse{}
+	if err = resp.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
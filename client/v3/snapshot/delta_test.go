@@ -0,0 +1,112 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type fakeDeltaMaintenance struct {
+	clientv3.Maintenance
+	resps []*pb.SnapshotDeltaResponse
+}
+
+func (fm *fakeDeltaMaintenance) SnapshotDelta(ctx context.Context, baseRev int64) (pb.Maintenance_SnapshotDeltaClient, error) {
+	return &fakeSnapshotDeltaStream{resps: fm.resps}, nil
+}
+
+type fakeSnapshotDeltaStream struct {
+	grpc.ClientStream
+	resps []*pb.SnapshotDeltaResponse
+}
+
+func (ss *fakeSnapshotDeltaStream) Recv() (*pb.SnapshotDeltaResponse, error) {
+	if len(ss.resps) == 0 {
+		return nil, io.EOF
+	}
+	resp := ss.resps[0]
+	ss.resps = ss.resps[1:]
+	return resp, nil
+}
+
+func TestSaveDelta(t *testing.T) {
+	entry := func(key string) *pb.SnapshotDeltaEntry {
+		return &pb.SnapshotDeltaEntry{Bucket: []byte("key"), Key: []byte(key), Value: []byte("value")}
+	}
+	fm := &fakeDeltaMaintenance{resps: []*pb.SnapshotDeltaResponse{
+		{BaseRevision: 5, Revision: 8, BaseHash: 1, Hash: 2, FullBuckets: [][]byte{[]byte("lease")}},
+		{Entries: []*pb.SnapshotDeltaEntry{entry("a"), entry("b")}},
+		{Entries: []*pb.SnapshotDeltaEntry{entry("c")}},
+	}}
+	path := filepath.Join(t.TempDir(), "delta")
+	info, err := saveDelta(context.Background(), zaptest.NewLogger(t), fm, 5, path)
+	require.NoError(t, err)
+	want := DeltaInfo{BaseRevision: 5, Revision: 8, BaseHash: 1, Hash: 2, FullBuckets: [][]byte{[]byte("lease")}}
+	assert.Equal(t, want, *info)
+	_, err = os.Stat(path + ".part")
+	assert.True(t, os.IsNotExist(err))
+
+	dr, err := OpenDelta(path)
+	require.NoError(t, err)
+	defer dr.Close()
+	assert.Equal(t, want, dr.Info)
+	var keys []string
+	for {
+		ents, err := dr.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		for _, e := range ents {
+			keys = append(keys, string(e.Key))
+		}
+	}
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+}
+
+func TestSaveDeltaBaseRevisionMismatch(t *testing.T) {
+	fm := &fakeDeltaMaintenance{resps: []*pb.SnapshotDeltaResponse{{BaseRevision: 4, Revision: 8}}}
+	path := filepath.Join(t.TempDir(), "delta")
+	_, err := saveDelta(context.Background(), zaptest.NewLogger(t), fm, 5, path)
+	assert.Error(t, err)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestOpenDeltaCorrupted(t *testing.T) {
+	fm := &fakeDeltaMaintenance{resps: []*pb.SnapshotDeltaResponse{{BaseRevision: 5, Revision: 8}}}
+	path := filepath.Join(t.TempDir(), "delta")
+	_, err := saveDelta(context.Background(), zaptest.NewLogger(t), fm, 5, path)
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	data[1] ^= 0xff
+	require.NoError(t, os.WriteFile(path, data, 0600))
+	_, err = OpenDelta(path)
+	assert.ErrorContains(t, err, errDeltaDigestMismatch.Error())
+}
@@ -15,9 +15,13 @@
 package snapshot
 
 import (
+	"bytes"
 	"context"
 	"crypto/sha256"
+	"errors"
 	"fmt"
+	"hash"
+	"hash/crc32"
 	"io"
 	"os"
 	"time"
@@ -25,6 +29,7 @@ import (
 	"github.com/dustin/go-humanize"
 	"go.uber.org/zap"
 
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	clientv3 "go.etcd.io/etcd/client/v3"
 )
@@ -38,7 +43,11 @@ func hasChecksum(n int64) bool {
 }
 
 // SaveWithVersion fetches snapshot from remote etcd server, saves data
-// to target path and returns server version. If the context "ctx" is canceled or timed out,
+// to target path and returns server version. The snapshot is fetched in a
+// resumable snapshot session if the server supports it, so an interrupted
+// stream is resumed from the last received chunk, and the SHA-256 digest of
+// the snapshot is verified before the file is renamed into place.
+// If the context "ctx" is canceled or timed out,
 // snapshot save stream will error out (e.g. context.Canceled,
 // context.DeadlineExceeded). Make sure to specify only one endpoint
 // in client configuration. Snapshot API must be requested to a
@@ -66,26 +75,25 @@ func SaveWithVersion(ctx context.Context, lg *zap.Logger, cfg clientv3.Config, d
 	}
 	lg.Info("created temporary db file", zap.String("path", partpath))
 
+	defer f.Close()
+
 	start := time.Now()
-	resp, err := cli.SnapshotWithVersion(ctx)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Snapshot.Close()
 	lg.Info("fetching snapshot", zap.String("endpoint", cfg.Endpoints[0]))
-	var size int64
-	size, err = io.Copy(f, resp.Snapshot)
+	ft := &fetcher{lg: lg, m: cli, f: f, h: sha256.New()}
+	err = ft.fetch(ctx)
+	version = ft.version
 	if err != nil {
-		return resp.Version, err
+		return version, err
 	}
+	size := ft.written
 	if !hasChecksum(size) {
-		return resp.Version, fmt.Errorf("sha256 checksum not found [bytes: %d]", size)
+		return version, fmt.Errorf("sha256 checksum not found [bytes: %d]", size)
 	}
 	if err = fileutil.Fsync(f); err != nil {
-		return resp.Version, err
+		return version, err
 	}
 	if err = f.Close(); err != nil {
-		return resp.Version, err
+		return version, err
 	}
 	lg.Info("fetched snapshot",
 		zap.String("endpoint", cfg.Endpoints[0]),
@@ -95,8 +103,159 @@ func SaveWithVersion(ctx context.Context, lg *zap.Logger, cfg clientv3.Config, d
 	)
 
 	if err = os.Rename(partpath, dbPath); err != nil {
-		return resp.Version, fmt.Errorf("could not rename %s to %s (%v)", partpath, dbPath, err)
+		return version, fmt.Errorf("could not rename %s to %s (%v)", partpath, dbPath, err)
 	}
 	lg.Info("saved", zap.String("path", dbPath))
-	return resp.Version, nil
+	return version, nil
+}
+
+// maxResumeAttempts is the number of consecutive attempts to resume a
+// snapshot session without receiving any data before giving up.
+const maxResumeAttempts = 10
+
+var (
+	resumeBackoff = time.Second
+
+	errDigestMismatch = errors.New("sha256 digest of snapshot does not match the digest of the server")
+
+	crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// fetcher writes the snapshot of a resumable snapshot session to f, resuming
+// the session after the stream is interrupted. The snapshot is followed by
+// its SHA-256 digest, as in snapshots of non-resumable streams.
+type fetcher struct {
+	lg *zap.Logger
+	m  clientv3.Maintenance
+	f  *os.File
+	h  hash.Hash
+
+	sessionID int64
+	size      int64
+	version   string
+	// legacy is set if the server does not support snapshot sessions and
+	// streams the snapshot in one go.
+	legacy bool
+	// written is the number of bytes written to f.
+	written int64
+}
+
+func (ft *fetcher) fetch(ctx context.Context) error {
+	attempts := 0
+	for {
+		written := ft.written
+		done, err := ft.fetchStream(ctx)
+		if done || ft.legacy {
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		switch err {
+		case rpctypes.ErrInvalidSnapshotOffset:
+			return err
+		case rpctypes.ErrSnapshotSessionNotFound:
+			// the session expired, so the snapshot starts over
+			ft.lg.Warn("snapshot session expired; restarting snapshot", zap.Int64("session-id", ft.sessionID))
+			if err = ft.reset(); err != nil {
+				return err
+			}
+		}
+		if ft.written > written {
+			attempts = 0
+		}
+		if attempts++; attempts > maxResumeAttempts {
+			return fmt.Errorf("failed to resume snapshot session %d after %d attempts (%v)", ft.sessionID, maxResumeAttempts, err)
+		}
+		ft.lg.Warn("snapshot stream interrupted; resuming",
+			zap.Int64("session-id", ft.sessionID),
+			zap.Int64("offset", ft.written),
+			zap.Error(err),
+		)
+		select {
+		case <-time.After(resumeBackoff):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// fetchStream receives a stream of the session from the current offset. It
+// returns true if the snapshot is complete or cannot be resumed.
+func (ft *fetcher) fetchStream(ctx context.Context) (bool, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ss, err := ft.m.SnapshotSession(ctx, ft.sessionID, ft.written)
+	if err != nil {
+		return false, err
+	}
+	for {
+		resp, err := ss.Recv()
+		if err == io.EOF {
+			if ft.legacy {
+				return true, nil
+			}
+			return false, io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return false, err
+		}
+
+		if ft.sessionID == 0 && !ft.legacy {
+			ft.version = resp.Version
+			if resp.SessionId == 0 {
+				ft.lg.Info("server does not support resumable snapshots; fetching snapshot in one stream")
+				ft.legacy = true
+			} else {
+				ft.sessionID, ft.size = resp.SessionId, resp.Size_
+				ft.lg.Info("opened snapshot session",
+					zap.Int64("session-id", ft.sessionID),
+					zap.Int64("total-bytes", ft.size),
+				)
+			}
+		}
+		if ft.legacy {
+			if err = ft.write(resp.Blob); err != nil {
+				return true, err
+			}
+			continue
+		}
+
+		if resp.SessionId != ft.sessionID || resp.Size_ != ft.size || resp.Offset != ft.written {
+			return true, fmt.Errorf("unexpected snapshot response [session: %d, offset: %d, size: %d], expected [session: %d, offset: %d, size: %d]",
+				resp.SessionId, resp.Offset, resp.Size_, ft.sessionID, ft.written, ft.size)
+		}
+		if resp.Sha256 != nil {
+			if !bytes.Equal(resp.Sha256, ft.h.Sum(nil)) {
+				return true, errDigestMismatch
+			}
+			return true, ft.write(resp.Sha256)
+		}
+		if crc32.Checksum(resp.Blob, crc32cTable) != resp.Checksum {
+			return false, fmt.Errorf("crc32c checksum mismatch of snapshot chunk at offset %d", resp.Offset)
+		}
+		if err = ft.write(resp.Blob); err != nil {
+			return true, err
+		}
+		ft.h.Write(resp.Blob)
+	}
+}
+
+func (ft *fetcher) write(p []byte) error {
+	n, err := ft.f.Write(p)
+	ft.written += int64(n)
+	return err
+}
+
+// reset discards the fetched part of the snapshot.
+func (ft *fetcher) reset() error {
+	if err := ft.f.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := ft.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	ft.h.Reset()
+	ft.sessionID, ft.size, ft.written = 0, 0, 0
+	return nil
 }
@@ -0,0 +1,131 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+var errDisconnected = errors.New("disconnected")
+
+// fakeMaintenance serves a snapshot session in chunks of 4 bytes, and
+// interrupts the stream after failAfter chunks until failures is exhausted.
+type fakeMaintenance struct {
+	clientv3.Maintenance
+	data      []byte
+	sha       []byte
+	failAfter int
+	failures  int
+	offsets   []int64
+}
+
+func (fm *fakeMaintenance) SnapshotSession(ctx context.Context, sessionID, offset int64) (pb.Maintenance_SnapshotClient, error) {
+	fm.offsets = append(fm.offsets, offset)
+	var resps []*pb.SnapshotResponse
+	for off := offset; off < int64(len(fm.data)); off += 4 {
+		end := off + 4
+		if end > int64(len(fm.data)) {
+			end = int64(len(fm.data))
+		}
+		blob := fm.data[off:end]
+		resps = append(resps, &pb.SnapshotResponse{
+			SessionId: 1,
+			Offset:    off,
+			Size_:     int64(len(fm.data)),
+			Blob:      blob,
+			Checksum:  crc32.Checksum(blob, crc32cTable),
+		})
+	}
+	resps = append(resps, &pb.SnapshotResponse{SessionId: 1, Offset: int64(len(fm.data)), Size_: int64(len(fm.data)), Sha256: fm.sha})
+	ss := &fakeSnapshotStream{resps: resps, failAfter: -1}
+	if fm.failures > 0 {
+		fm.failures--
+		ss.failAfter = fm.failAfter
+	}
+	return ss, nil
+}
+
+type fakeSnapshotStream struct {
+	grpc.ClientStream
+	resps     []*pb.SnapshotResponse
+	failAfter int
+}
+
+func (ss *fakeSnapshotStream) Recv() (*pb.SnapshotResponse, error) {
+	if ss.failAfter == 0 {
+		return nil, errDisconnected
+	}
+	ss.failAfter--
+	if len(ss.resps) == 0 {
+		return nil, io.EOF
+	}
+	resp := ss.resps[0]
+	ss.resps = ss.resps[1:]
+	return resp, nil
+}
+
+func TestFetcherResume(t *testing.T) {
+	defer func(backoff time.Duration) { resumeBackoff = backoff }(resumeBackoff)
+	resumeBackoff = 0
+
+	data := []byte("0123456789abcdefghij")
+	sum := sha256.Sum256(data)
+	tcs := []struct {
+		name    string
+		sha     []byte
+		wantErr error
+	}{
+		{name: "matching digest", sha: sum[:]},
+		{name: "mismatching digest", sha: make([]byte, sha256.Size), wantErr: errDigestMismatch},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			f, err := os.Create(filepath.Join(t.TempDir(), "db.part"))
+			require.NoError(t, err)
+			defer f.Close()
+
+			fm := &fakeMaintenance{data: data, sha: tc.sha, failAfter: 2, failures: 2}
+			ft := &fetcher{lg: zaptest.NewLogger(t), m: fm, f: f, h: sha256.New()}
+			err = ft.fetch(context.Background())
+			assert.Equal(t, tc.wantErr, err)
+			// the stream is resumed where the previous stream was interrupted
+			assert.Equal(t, []int64{0, 8, 16}, fm.offsets)
+			if tc.wantErr != nil {
+				return
+			}
+
+			got, err := os.ReadFile(f.Name())
+			require.NoError(t, err)
+			assert.Equal(t, append(append([]byte{}, data...), sum[:]...), got)
+			assert.Equal(t, int64(len(got)), ft.written)
+		})
+	}
+}
@@ -47,10 +47,21 @@ type Txn interface {
 	// comparisons passed in If() fail.
 	Else(ops ...Op) Txn
 
+	// ReadSet takes the keys read by an optimistic transaction, with the mod
+	// revisions they were read at. The transaction then succeeds only if none
+	// of the keys changed since, in addition to the comparisons passing, and
+	// its response holds the current key-values of the changed keys in
+	// Conflicts otherwise.
+	ReadSet(rs ...ReadSetEntry) Txn
+
 	// Commit tries to commit the transaction.
 	Commit() (*TxnResponse, error)
 }
 
+// ReadSetEntry is a key read by an optimistic transaction, with the mod
+// revision it was read at, or zero if the key did not exist.
+type ReadSetEntry pb.ReadSetEntry
+
 type txn struct {
 	kv  *kv
 	ctx context.Context
@@ -59,10 +70,12 @@ type txn struct {
 	cif   bool
 	cthen bool
 	celse bool
+	crs   bool
 
 	isWrite bool
 
 	cmps []*pb.Compare
+	rs   []*pb.ReadSetEntry
 
 	sus []*pb.RequestOp
 	fas []*pb.RequestOp
@@ -134,11 +147,36 @@ func (txn *txn) Else(ops ...Op) Txn {
 	return txn
 }
 
+func (txn *txn) ReadSet(rs ...ReadSetEntry) Txn {
+	txn.mu.Lock()
+	defer txn.mu.Unlock()
+
+	if txn.crs {
+		panic("cannot call ReadSet twice!")
+	}
+
+	if txn.cthen {
+		panic("cannot call ReadSet after Then!")
+	}
+
+	if txn.celse {
+		panic("cannot call ReadSet after Else!")
+	}
+
+	txn.crs = true
+
+	for i := range rs {
+		txn.rs = append(txn.rs, (*pb.ReadSetEntry)(&rs[i]))
+	}
+
+	return txn
+}
+
 func (txn *txn) Commit() (*TxnResponse, error) {
 	txn.mu.Lock()
 	defer txn.mu.Unlock()
 
-	r := &pb.TxnRequest{Compare: txn.cmps, Success: txn.sus, Failure: txn.fas}
+	r := &pb.TxnRequest{Compare: txn.cmps, Success: txn.sus, Failure: txn.fas, ReadSet: txn.rs}
 
 	var resp *pb.TxnResponse
 	var err error
@@ -24,6 +24,7 @@ import (
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
@@ -219,6 +220,15 @@ type watchRequest struct {
 
 	// filters is the list of events to filter out
 	filters []pb.WatchCreateRequest_FilterType
+	// keyGlob and keyRegex restrict the watched keys to the matching keys
+	keyGlob  string
+	keyRegex string
+	// kvFilters filters out puts whose key-value does not match
+	kvFilters []*pb.RangeFilter
+	// omitUnchanged omits the values of puts that do not change them
+	omitUnchanged bool
+	// coalesceWindow delays events to merge the changes of the same key
+	coalesceWindow time.Duration
 	// get the previous key-value pair before the event happens
 	prevKV bool
 	// retc receives a chan WatchResponse once the watcher is established
@@ -281,7 +291,7 @@ func (w *watcher) newWatcherGrpcStream(inctx context.Context) *watchGrpcStream {
 	wgs := &watchGrpcStream{
 		owner:      w,
 		remote:     w.remote,
-		callOpts:   w.callOpts,
+		callOpts:   watchCallOpts(inctx, w.callOpts),
 		ctx:        ctx,
 		ctxKey:     streamKeyFromCtx(inctx),
 		cancel:     cancel,
@@ -321,6 +331,13 @@ func (w *watcher) Watch(ctx context.Context, key string, opts ...OpOption) Watch
 		filters:        filters,
 		prevKV:         ow.prevKV,
 		retc:           make(chan chan WatchResponse, 1),
+
+		keyGlob:       ow.keyGlob,
+		keyRegex:      ow.keyRegex,
+		kvFilters:     ow.filters,
+		omitUnchanged: ow.omitUnchanged,
+
+		coalesceWindow: ow.coalesceWindow,
 	}
 
 	ok := false
@@ -1054,6 +1071,12 @@ func (wr *watchRequest) toPB() *pb.WatchRequest {
 		Filters:        wr.filters,
 		PrevKv:         wr.prevKV,
 		Fragment:       wr.fragment,
+
+		KeyGlob:            wr.keyGlob,
+		KeyRegex:           wr.keyRegex,
+		KvFilters:          wr.kvFilters,
+		OmitUnchangedValue: wr.omitUnchanged,
+		CoalesceWindowMs:   wr.coalesceWindow.Milliseconds(),
 	}
 	cr := &pb.WatchRequest_CreateRequest{CreateRequest: req}
 	return &pb.WatchRequest{RequestUnion: cr}
@@ -1066,6 +1089,18 @@ func (pr *progressRequest) toPB() *pb.WatchRequest {
 	return &pb.WatchRequest{RequestUnion: cr}
 }
 
+// watchCallOpts returns the call options of a watch stream opened with ctx,
+// which asks for compressed responses if ctx is from WithWatchCompression.
+func watchCallOpts(ctx context.Context, callOpts []grpc.CallOption) []grpc.CallOption {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || len(md.Get(v3rpc.MetadataWatchCompressionKey)) == 0 {
+		return callOpts
+	}
+	opts := make([]grpc.CallOption, 0, len(callOpts)+1)
+	opts = append(opts, callOpts...)
+	return append(opts, grpc.UseCompressor(gzip.Name))
+}
+
 func streamKeyFromCtx(ctx context.Context) string {
 	if md, ok := metadata.FromOutgoingContext(ctx); ok {
 		return fmt.Sprintf("%+v", md)
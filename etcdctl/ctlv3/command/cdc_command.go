@@ -0,0 +1,110 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/cdc"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	cdcPrefix        string
+	cdcFormat        string
+	cdcOutputDir     string
+	cdcCursorFile    string
+	cdcMaxFileSize   int64
+	cdcBatchSize     int
+	cdcFlushInterval time.Duration
+	cdcRev           int64
+	cdcPrevKV        bool
+	cdcSource        string
+)
+
+// NewCDCExportCommand returns the cobra command for "cdc-export".
+func NewCDCExportCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cdc-export [options]",
+		Short: "Exports the changes of a key prefix to files or the standard output",
+		Run:   cdcExportCommandFunc,
+	}
+
+	cmd.Flags().StringVar(&cdcPrefix, "prefix", "", "Key prefix to export the changes of")
+	cmd.Flags().StringVar(&cdcFormat, "format", string(cdc.FormatJSON), "Format of the events: 'json', 'cloudevents' or 'protobuf'")
+	cmd.Flags().StringVar(&cdcOutputDir, "output-dir", "", "Directory of the rotating files and of the cursor, exported exactly once; the standard output if empty")
+	cmd.Flags().StringVar(&cdcCursorFile, "cursor-file", "", "File persisting the cursor of the standard output, exported at least once")
+	cmd.Flags().Int64Var(&cdcMaxFileSize, "max-file-size", cdc.DefaultMaxFileSize, "Size in bytes from which the files are rotated")
+	cmd.Flags().IntVar(&cdcBatchSize, "batch-size", 1000, "Number of events from which the events are flushed")
+	cmd.Flags().DurationVar(&cdcFlushInterval, "flush-interval", time.Second, "Interval at which the events are flushed")
+	cmd.Flags().Int64Var(&cdcRev, "rev", 0, "Revision to start exporting from without cursor; the next revision if 0")
+	cmd.Flags().BoolVar(&cdcPrevKV, "prev-kv", false, "Export the previous key-values of the events")
+	cmd.Flags().StringVar(&cdcSource, "cloudevents-source", "", "Source attribute of the CloudEvents; etcd://<cluster ID> if empty")
+
+	return cmd
+}
+
+// cdcExportCommandFunc executes the "cdc-export" command.
+func cdcExportCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("cdc-export takes no arguments"))
+	}
+	if cdcOutputDir != "" && cdcCursorFile != "" {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("--cursor-file cannot be used with --output-dir, which holds the cursor"))
+	}
+	format := cdc.Format(cdcFormat)
+
+	var (
+		sink cdc.Sink
+		err  error
+	)
+	if cdcOutputDir != "" {
+		sink, err = cdc.NewFileSink(cdc.FileSinkConfig{Dir: cdcOutputDir, Ext: format.Ext(), MaxFileSize: cdcMaxFileSize})
+	} else {
+		sink, err = cdc.NewWriterSink(os.Stdout, cdcCursorFile)
+	}
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	defer sink.Close()
+
+	c := mustClientFromCmd(cmd)
+	e, err := cdc.NewExporter(c, sink, cdc.Config{
+		Prefix:        cdcPrefix,
+		Format:        format,
+		Source:        cdcSource,
+		PrevKV:        cdcPrevKV,
+		StartRevision: cdcRev,
+		BatchSize:     cdcBatchSize,
+		FlushInterval: cdcFlushInterval,
+	})
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+
+	// the buffered events are flushed on interrupt
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if err = e.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+}
@@ -21,9 +21,15 @@ import (
 
 	"github.com/spf13/cobra"
 
+	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 )
 
+var (
+	defragOnline    bool
+	defragRateLimit int64
+)
+
 // NewDefragCommand returns the cobra command for "Defrag".
 func NewDefragCommand() *cobra.Command {
 	cmd := &cobra.Command{
@@ -32,10 +38,23 @@ func NewDefragCommand() *cobra.Command {
 		Run:   defragCommandFunc,
 	}
 	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	cmd.Flags().BoolVar(&defragOnline, "online", false, "keep serving reads and writes during the defragmentation, blocking them only for a short swap at the end")
+	cmd.Flags().Int64Var(&defragRateLimit, "rate-limit", 0, "limit the bytes per second copied by an online defragmentation (0 for no limit)")
 	return cmd
 }
 
 func defragCommandFunc(cmd *cobra.Command, args []string) {
+	if defragRateLimit < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("invalid --rate-limit %d", defragRateLimit))
+	}
+	if defragRateLimit != 0 && !defragOnline {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("--rate-limit requires --online"))
+	}
+	var opts []clientv3.DefragOption
+	if defragOnline {
+		opts = append(opts, clientv3.WithOnlineDefrag(), clientv3.WithDefragRateLimit(defragRateLimit))
+	}
+
 	failures := 0
 	cfg := clientConfigFromCmd(cmd)
 	for _, ep := range endpointsFromCluster(cmd) {
@@ -43,7 +62,7 @@ func defragCommandFunc(cmd *cobra.Command, args []string) {
 		c := mustClient(cfg)
 		ctx, cancel := commandCtx(cmd)
 		start := time.Now()
-		_, err := c.Defragment(ctx, ep)
+		_, err := c.DefragmentWithOptions(ctx, ep, opts...)
 		d := time.Now().Sub(start)
 		cancel()
 		if err != nil {
@@ -0,0 +1,80 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var rotateKeyRateLimit int64
+
+// NewEncryptionCommand returns the cobra command for "encryption".
+func NewEncryptionCommand() *cobra.Command {
+	ec := &cobra.Command{
+		Use:   "encryption <subcommand>",
+		Short: "Encryption at rest related commands",
+	}
+
+	ec.AddCommand(NewEncryptionRotateKeyCommand())
+
+	return ec
+}
+
+// NewEncryptionRotateKeyCommand returns the cobra command for "encryption rotate-key".
+func NewEncryptionRotateKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rotate-key",
+		Short: "Reloads the encryption keys of the etcd members with given endpoints and re-encrypts their storage",
+		Run:   encryptionRotateKeyCommandFunc,
+	}
+	cmd.PersistentFlags().BoolVar(&epClusterEndpoints, "cluster", false, "use all endpoints from the cluster member list")
+	cmd.Flags().Int64Var(&rotateKeyRateLimit, "rate-limit", 0, "limit the bytes per second rewritten while re-encrypting the storage (0 for no limit)")
+	return cmd
+}
+
+func encryptionRotateKeyCommandFunc(cmd *cobra.Command, args []string) {
+	if rotateKeyRateLimit < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("invalid --rate-limit %d", rotateKeyRateLimit))
+	}
+
+	failures := 0
+	cfg := clientConfigFromCmd(cmd)
+	for _, ep := range endpointsFromCluster(cmd) {
+		cfg.Endpoints = []string{ep}
+		c := mustClient(cfg)
+		ctx, cancel := commandCtx(cmd)
+		start := time.Now()
+		resp, err := c.RotateEncryptionKey(ctx, ep, rotateKeyRateLimit)
+		d := time.Since(start)
+		cancel()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rotate the encryption key of etcd member[%s]. took %s. (%v)\n", ep, d.String(), err)
+			failures++
+		} else {
+			fmt.Printf("Rotated the encryption key of etcd member[%s] to key %d. took %s\n", ep, resp.KeyId, d.String())
+		}
+		c.Close()
+	}
+
+	if failures != 0 {
+		os.Exit(cobrautl.ExitError)
+	}
+}
@@ -154,6 +154,8 @@ func epHealthCommandFunc(cmd *cobra.Command, args []string) {
 							eh.Error = eh.Error + "NOSPACE "
 						case etcdserverpb.AlarmType_CORRUPT:
 							eh.Error = eh.Error + "CORRUPT "
+						case etcdserverpb.AlarmType_STORAGE_CORRUPT:
+							eh.Error = eh.Error + "STORAGE_CORRUPT "
 						default:
 							eh.Error = eh.Error + "UNKNOWN "
 						}
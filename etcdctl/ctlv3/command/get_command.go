@@ -15,11 +15,15 @@
 package command
 
 import (
+	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
 )
@@ -35,6 +39,11 @@ var (
 	getKeysOnly    bool
 	getCountOnly   bool
 	printValueOnly bool
+	getFilters     []string
+	getPageSize    int64
+
+	getMaxStaleness     time.Duration
+	getMaxStalenessRevs int64
 )
 
 // NewGetCommand returns the cobra command for "get".
@@ -55,6 +64,10 @@ func NewGetCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&getKeysOnly, "keys-only", false, "Get only the keys")
 	cmd.Flags().BoolVar(&getCountOnly, "count-only", false, "Get only the count")
 	cmd.Flags().BoolVar(&printValueOnly, "print-value-only", false, `Only write values when using the "simple" output format`)
+	cmd.Flags().Int64Var(&getPageSize, "page-size", 0, "Fetch the keys in pages of at most this many keys at a single revision")
+	cmd.Flags().DurationVar(&getMaxStaleness, "max-staleness", 0, "Let a follower serve a linearizable read when its state is at most this far behind the leader, e.g. 500ms")
+	cmd.Flags().Int64Var(&getMaxStalenessRevs, "max-staleness-revisions", 0, "Let a follower serve a linearizable read when its state is at most this many revisions behind")
+	cmd.Flags().StringArrayVar(&getFilters, "filter", nil, `Only get keys matching the filter, e.g. "version>1", "value^=prefix" or "json:spec.replicas=3" (repeatable)`)
 
 	cmd.RegisterFlagCompletionFunc("consistency", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"l", "s"}, cobra.ShellCompDirectiveDefault
@@ -72,6 +85,10 @@ func NewGetCommand() *cobra.Command {
 // getCommandFunc executes the "get" command.
 func getCommandFunc(cmd *cobra.Command, args []string) {
 	key, opts := getGetOp(args)
+	if getPageSize > 0 {
+		getPagesFunc(cmd, key, opts)
+		return
+	}
 	ctx, cancel := commandCtx(cmd)
 	resp, err := mustClientFromCmd(cmd).Get(ctx, key, opts...)
 	cancel()
@@ -85,6 +102,29 @@ func getCommandFunc(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	setPrintValueOnly()
+	display.Get(*resp)
+}
+
+// getPagesFunc prints the keys of the "get" command page by page.
+func getPagesFunc(cmd *cobra.Command, key string, opts []clientv3.OpOption) {
+	setPrintValueOnly()
+	p := clientv3.NewPager(mustClientFromCmd(cmd), key, getPageSize, opts...)
+	for {
+		ctx, cancel := commandCtx(cmd)
+		ok := p.Next(ctx)
+		cancel()
+		if !ok {
+			break
+		}
+		display.Get(*p.Response())
+	}
+	if err := p.Err(); err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+}
+
+func setPrintValueOnly() {
 	if printValueOnly {
 		dp, simple := (display).(*simplePrinter)
 		if !simple {
@@ -92,7 +132,6 @@ func getCommandFunc(cmd *cobra.Command, args []string) {
 		}
 		dp.valueOnly = true
 	}
-	display.Get(*resp)
 }
 
 func getGetOp(args []string) (string, []clientv3.OpOption) {
@@ -108,11 +147,34 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--keys-only` and `--count-only` cannot be set at the same time, choose one"))
 	}
 
+	if getPageSize < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--page-size` must not be negative"))
+	}
+	if getPageSize > 0 && (getLimit != 0 || getCountOnly) {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--page-size` cannot be combined with `--limit` or `--count-only`"))
+	}
+	if getPageSize > 0 && (strings.ToUpper(getSortOrder) == "DESCEND" || (getSortTarget != "" && strings.ToUpper(getSortTarget) != "KEY")) {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--page-size` requires the keys in ascending key order"))
+	}
+
+	if getMaxStaleness < 0 || getMaxStalenessRevs < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--max-staleness` and `--max-staleness-revisions` must not be negative"))
+	}
+
 	var opts []clientv3.OpOption
 	switch getConsistency {
 	case "s":
+		if getMaxStaleness != 0 || getMaxStalenessRevs != 0 {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("`--max-staleness` and `--max-staleness-revisions` require linearizable consistency"))
+		}
 		opts = append(opts, clientv3.WithSerializable())
 	case "l":
+		if getMaxStaleness != 0 {
+			opts = append(opts, clientv3.WithMaxStaleness(getMaxStaleness))
+		}
+		if getMaxStalenessRevs != 0 {
+			opts = append(opts, clientv3.WithMaxStalenessRevisions(getMaxStalenessRevs))
+		}
 	default:
 		cobrautl.ExitWithError(cobrautl.ExitBadFeature, fmt.Errorf("unknown consistency flag %q", getConsistency))
 	}
@@ -126,6 +188,13 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 	}
 
 	opts = append(opts, clientv3.WithLimit(getLimit))
+	for _, expr := range getFilters {
+		f, err := parseGetFilter(expr)
+		if err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+		}
+		opts = append(opts, clientv3.WithFilter(f))
+	}
 	if getRev > 0 {
 		opts = append(opts, clientv3.WithRev(getRev))
 	}
@@ -190,3 +259,65 @@ func getGetOp(args []string) (string, []clientv3.OpOption) {
 
 	return key, opts
 }
+
+// getFilterOps maps the operators of filter expressions to the results of
+// clientv3.Filter. Longer operators come first so that "!=" is not read as "=".
+var getFilterOps = []struct{ op, result string }{
+	{"!=", "!="},
+	{"^=", "prefix"},
+	{"=", "="},
+	{">", ">"},
+	{"<", "<"},
+}
+
+// parseGetFilter parses a filter expression of the form <target><op><value>.
+// The target is one of value, version, create, mod, lease or json:<path>, the
+// operator one of =, !=, >, < or ^= (prefix). Leases are given in hex and JSON
+// values as JSON.
+func parseGetFilter(expr string) (clientv3.RangeFilter, error) {
+	opIdx, op, result := -1, "", ""
+	for _, o := range getFilterOps {
+		if i := strings.Index(expr, o.op); i > 0 && (opIdx < 0 || i < opIdx) {
+			opIdx, op, result = i, o.op, o.result
+		}
+	}
+	if opIdx < 0 {
+		return clientv3.RangeFilter{}, fmt.Errorf("bad filter %q, expected <target><op><value>", expr)
+	}
+	target, val := expr[:opIdx], expr[opIdx+len(op):]
+
+	var f clientv3.RangeFilter
+	var v interface{}
+	var err error
+	switch {
+	case target == "value":
+		f, v = clientv3.ValueFilter(), val
+	case target == "version":
+		f = clientv3.VersionFilter()
+		v, err = strconv.ParseInt(val, 10, 64)
+	case target == "create":
+		f = clientv3.CreateRevisionFilter()
+		v, err = strconv.ParseInt(val, 10, 64)
+	case target == "mod":
+		f = clientv3.ModRevisionFilter()
+		v, err = strconv.ParseInt(val, 10, 64)
+	case target == "lease":
+		f = clientv3.LeaseFilter()
+		v, err = strconv.ParseInt(val, 16, 64)
+	case strings.HasPrefix(target, "json:"):
+		f = clientv3.JSONFilter(strings.TrimPrefix(target, "json:"))
+		if !json.Valid([]byte(val)) {
+			err = fmt.Errorf("not valid JSON")
+		}
+		v = json.RawMessage(val)
+	default:
+		return clientv3.RangeFilter{}, fmt.Errorf("bad filter target %q in %q", target, expr)
+	}
+	if err != nil {
+		return clientv3.RangeFilter{}, fmt.Errorf("bad filter value %q in %q (%v)", val, expr, err)
+	}
+	if result == "prefix" && f.Target != pb.RangeFilter_VALUE && f.Target != pb.RangeFilter_JSON {
+		return clientv3.RangeFilter{}, fmt.Errorf("bad filter %q, ^= is only for value and json targets", expr)
+	}
+	return clientv3.Filter(f, result, v), nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"reflect"
+	"testing"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+func Test_parseGetFilter(t *testing.T) {
+	tt := []struct {
+		expr    string
+		want    clientv3.RangeFilter
+		wantErr bool
+	}{
+		{expr: "version>1", want: clientv3.Filter(clientv3.VersionFilter(), ">", int64(1))},
+		{expr: "mod<10", want: clientv3.Filter(clientv3.ModRevisionFilter(), "<", int64(10))},
+		{expr: "create!=2", want: clientv3.Filter(clientv3.CreateRevisionFilter(), "!=", int64(2))},
+		{expr: "lease=694d", want: clientv3.Filter(clientv3.LeaseFilter(), "=", int64(0x694d))},
+		{expr: "value=a=b", want: clientv3.Filter(clientv3.ValueFilter(), "=", "a=b")},
+		{expr: "value^=foo", want: clientv3.Filter(clientv3.ValueFilter(), "prefix", "foo")},
+		{expr: `json:spec.image^="nginx"`, want: clientv3.Filter(clientv3.JSONFilter("spec.image"), "prefix", "nginx")},
+		{expr: "json:replicas>2", want: clientv3.Filter(clientv3.JSONFilter("replicas"), ">", 2)},
+		{expr: "value", wantErr: true},
+		{expr: "=foo", wantErr: true},
+		{expr: "size>1", wantErr: true},
+		{expr: "version>a", wantErr: true},
+		{expr: "version^=1", wantErr: true},
+		{expr: "json:a=foo", wantErr: true},
+	}
+	for _, tc := range tt {
+		got, err := parseGetFilter(tc.expr)
+		if tc.wantErr {
+			if err == nil {
+				t.Errorf("parseGetFilter(%q) expected error, got %+v", tc.expr, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseGetFilter(%q) unexpected error: %v", tc.expr, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("parseGetFilter(%q) = %+v, want %+v", tc.expr, got, tc.want)
+		}
+	}
+}
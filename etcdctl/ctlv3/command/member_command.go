@@ -29,6 +29,8 @@ import (
 var (
 	memberPeerURLs string
 	isLearner      bool
+	isObserver     bool
+	snapshotSource string
 )
 
 // NewMemberCommand returns the cobra command for "member".
@@ -58,6 +60,8 @@ func NewMemberAddCommand() *cobra.Command {
 
 	cc.Flags().StringVar(&memberPeerURLs, "peer-urls", "", "comma separated peer URLs for the new member.")
 	cc.Flags().BoolVar(&isLearner, "learner", false, "indicates if the new member is raft learner")
+	cc.Flags().BoolVar(&isObserver, "observer", false, "indicates if the new member is a permanent non-voting observer, which cannot be promoted")
+	cc.Flags().StringVar(&snapshotSource, "snapshot-source", "", "hex ID of the voting member sending snapshots to the new observer instead of the leader")
 
 	return cc
 }
@@ -94,7 +98,7 @@ func NewMemberListCommand() *cobra.Command {
 		Use:   "list",
 		Short: "Lists all members in the cluster",
 		Long: `When --write-out is set to simple, this command prints out comma-separated member lists for each endpoint.
-The items in the lists are ID, Status, Name, Peer Addrs, Client Addrs, Is Learner.
+The items in the lists are ID, Status, Name, Peer Addrs, Client Addrs, Is Learner, Is Observer.
 `,
 
 		Run: memberListCommandFunc,
@@ -137,6 +141,20 @@ func memberAddCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("member peer urls not provided"))
 	}
 
+	if isLearner && isObserver {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("--learner and --observer cannot be set together"))
+	}
+	var sourceID uint64
+	if len(snapshotSource) > 0 {
+		if !isObserver {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, errors.New("--snapshot-source requires --observer"))
+		}
+		var err error
+		if sourceID, err = strconv.ParseUint(snapshotSource, 16, 64); err != nil {
+			cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad snapshot source ID arg (%v), expecting ID in Hex", err))
+		}
+	}
+
 	urls := strings.Split(memberPeerURLs, ",")
 	ctx, cancel := commandCtx(cmd)
 	cli := mustClientFromCmd(cmd)
@@ -144,7 +162,9 @@ func memberAddCommandFunc(cmd *cobra.Command, args []string) {
 		resp *clientv3.MemberAddResponse
 		err  error
 	)
-	if isLearner {
+	if isObserver {
+		resp, err = cli.MemberAddAsObserver(ctx, urls, sourceID)
+	} else if isLearner {
 		resp, err = cli.MemberAddAsLearner(ctx, urls)
 	} else {
 		resp, err = cli.MemberAdd(ctx, urls)
@@ -56,6 +56,14 @@ type printer interface {
 
 	Alarm(v3.AlarmResponse)
 
+	QuotaSet(prefix string, r v3.QuotaSetResponse)
+	QuotaDelete(prefix string, r v3.QuotaDeleteResponse)
+	QuotaList(r v3.QuotaListResponse)
+
+	TenantAdd(name string, r v3.AuthTenantAddResponse)
+	TenantDelete(name string, r v3.AuthTenantDeleteResponse)
+	TenantList(r v3.AuthTenantListResponse)
+
 	RoleAdd(role string, r v3.AuthRoleAddResponse)
 	RoleGet(role string, r v3.AuthRoleGetResponse)
 	RoleDelete(role string, r v3.AuthRoleDeleteResponse)
@@ -155,6 +163,24 @@ func (p *printerRPC) UserDelete(_ string, r v3.AuthUserDeleteResponse) {
 func (p *printerRPC) AuthStatus(r v3.AuthStatusResponse) {
 	p.p((*pb.AuthStatusResponse)(&r))
 }
+func (p *printerRPC) QuotaSet(_ string, r v3.QuotaSetResponse) {
+	p.p((*pb.QuotaSetResponse)(&r))
+}
+func (p *printerRPC) QuotaDelete(_ string, r v3.QuotaDeleteResponse) {
+	p.p((*pb.QuotaDeleteResponse)(&r))
+}
+func (p *printerRPC) QuotaList(r v3.QuotaListResponse) {
+	p.p((*pb.QuotaListResponse)(&r))
+}
+func (p *printerRPC) TenantAdd(_ string, r v3.AuthTenantAddResponse) {
+	p.p((*pb.AuthTenantAddResponse)(&r))
+}
+func (p *printerRPC) TenantDelete(_ string, r v3.AuthTenantDeleteResponse) {
+	p.p((*pb.AuthTenantDeleteResponse)(&r))
+}
+func (p *printerRPC) TenantList(r v3.AuthTenantListResponse) {
+	p.p((*pb.AuthTenantListResponse)(&r))
+}
 
 type printerUnsupported struct{ printerRPC }
 
@@ -175,7 +201,7 @@ func (p *printerUnsupported) DowngradeEnable(r v3.DowngradeResponse)
 func (p *printerUnsupported) DowngradeCancel(r v3.DowngradeResponse)                    { p.p(nil) }
 
 func makeMemberListTable(r v3.MemberListResponse) (hdr []string, rows [][]string) {
-	hdr = []string{"ID", "Status", "Name", "Peer Addrs", "Client Addrs", "Is Learner"}
+	hdr = []string{"ID", "Status", "Name", "Peer Addrs", "Client Addrs", "Is Learner", "Is Observer"}
 	for _, m := range r.Members {
 		status := "started"
 		if len(m.Name) == 0 {
@@ -185,6 +211,10 @@ func makeMemberListTable(r v3.MemberListResponse) (hdr []string, rows [][]string
 		if m.IsLearner {
 			isLearner = "true"
 		}
+		isObserver := "false"
+		if m.IsObserver {
+			isObserver = "true"
+		}
 		rows = append(rows, []string{
 			fmt.Sprintf("%x", m.ID),
 			status,
@@ -192,6 +222,7 @@ func makeMemberListTable(r v3.MemberListResponse) (hdr []string, rows [][]string
 			strings.Join(m.PeerURLs, ","),
 			strings.Join(m.ClientURLs, ","),
 			isLearner,
+			isObserver,
 		})
 	}
 	return hdr, rows
@@ -243,3 +274,23 @@ func makeEndpointHashKVTable(hashList []epHashKV) (hdr []string, rows [][]string
 	}
 	return hdr, rows
 }
+
+func makeQuotaListTable(r v3.QuotaListResponse) (hdr []string, rows [][]string) {
+	hdr = []string{"prefix", "used bytes", "max bytes", "used keys", "max keys"}
+	limit := func(v int64) string {
+		if v == 0 {
+			return "unlimited"
+		}
+		return fmt.Sprint(v)
+	}
+	for _, q := range r.Quotas {
+		rows = append(rows, []string{
+			string(q.Quota.Prefix),
+			fmt.Sprint(q.UsedBytes),
+			limit(q.Quota.MaxBytes),
+			fmt.Sprint(q.UsedKeys),
+			limit(q.Quota.MaxKeys),
+		})
+	}
+	return hdr, rows
+}
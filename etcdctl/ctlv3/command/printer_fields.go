@@ -39,6 +39,9 @@ func (p *fieldsPrinter) kv(pfx string, kv *spb.KeyValue) {
 	} else {
 		fmt.Printf("\"%sLease\" : %d\n", pfx, kv.Lease)
 	}
+	if kv.Ttl != 0 {
+		fmt.Printf("\"%sTTL\" : %d\n", pfx, kv.Ttl)
+	}
 }
 
 func (p *fieldsPrinter) hdr(h *pb.ResponseHeader) {
@@ -175,6 +178,14 @@ func (p *fieldsPrinter) MemberList(r v3.MemberListResponse) {
 			fmt.Printf("\"ClientURL\" : %q\n", u)
 		}
 		fmt.Println(`"IsLearner" :`, m.IsLearner)
+		fmt.Println(`"IsObserver" :`, m.IsObserver)
+		if m.SnapshotSourceID != 0 {
+			if p.isHex {
+				fmt.Println(`"SnapshotSourceID" :`, types.ID(m.SnapshotSourceID))
+			} else {
+				fmt.Println(`"SnapshotSourceID" :`, m.SnapshotSourceID)
+			}
+		}
 		fmt.Println()
 	}
 }
@@ -237,6 +248,9 @@ func (p *fieldsPrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Println(`"PermType" : `, p.PermType.String())
 		fmt.Printf("\"Key\" : %q\n", string(p.Key))
 		fmt.Printf("\"RangeEnd\" : %q\n", string(p.RangeEnd))
+		if p.Deny {
+			fmt.Println(`"Deny" : `, p.Deny)
+		}
 	}
 }
 func (p *fieldsPrinter) RoleDelete(role string, r v3.AuthRoleDeleteResponse) { p.hdr(r.Header) }
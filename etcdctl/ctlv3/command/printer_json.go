@@ -90,6 +90,12 @@ func printMemberListWithHexJSON(r clientv3.MemberListResponse) {
 			return
 		}
 		buffer.Write(b)
+		if r.Members[i].IsObserver {
+			buffer.WriteString(",\"isObserver\":true,\"snapshotSourceID\":\"")
+			b = strconv.AppendUint(nil, r.Members[i].SnapshotSourceID, 16)
+			buffer.Write(b)
+			buffer.WriteString("\"")
+		}
 		buffer.WriteByte('}')
 		if i == len(r.Members)-1 {
 			buffer.WriteString("]")
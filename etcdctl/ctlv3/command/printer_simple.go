@@ -19,6 +19,7 @@ import (
 	"os"
 	"strings"
 
+	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	v3 "go.etcd.io/etcd/client/v3"
@@ -125,9 +126,40 @@ func (s *simplePrinter) Alarm(resp v3.AlarmResponse) {
 	}
 }
 
+func (s *simplePrinter) QuotaSet(prefix string, r v3.QuotaSetResponse) {
+	fmt.Printf("Quota on prefix %q set\n", prefix)
+}
+
+func (s *simplePrinter) QuotaDelete(prefix string, r v3.QuotaDeleteResponse) {
+	fmt.Printf("Quota on prefix %q deleted\n", prefix)
+}
+
+func (s *simplePrinter) QuotaList(r v3.QuotaListResponse) {
+	_, rows := makeQuotaListTable(r)
+	for _, row := range rows {
+		fmt.Println(strings.Join(row, ", "))
+	}
+}
+
+func (s *simplePrinter) TenantAdd(name string, r v3.AuthTenantAddResponse) {
+	fmt.Printf("Tenant %s created\n", name)
+}
+
+func (s *simplePrinter) TenantDelete(name string, r v3.AuthTenantDeleteResponse) {
+	fmt.Printf("Tenant %s deleted\n", name)
+}
+
+func (s *simplePrinter) TenantList(r v3.AuthTenantListResponse) {
+	for _, t := range r.Tenants {
+		fmt.Printf("%s, %s\n", t.Name, t.Prefix)
+	}
+}
+
 func (s *simplePrinter) MemberAdd(r v3.MemberAddResponse) {
 	asLearner := " "
-	if r.Member.IsLearner {
+	if r.Member.IsObserver {
+		asLearner = " as observer "
+	} else if r.Member.IsLearner {
 		asLearner = " as learner "
 	}
 	fmt.Printf("Member %16x added%sto cluster %16x\n", r.Member.ID, asLearner, r.Header.ClusterId)
@@ -196,6 +228,9 @@ func (s *simplePrinter) RoleAdd(role string, r v3.AuthRoleAddResponse) {
 
 func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 	fmt.Printf("Role %s\n", role)
+	if r.Tenant != "" {
+		fmt.Printf("Tenant: %s\n", r.Tenant)
+	}
 	if rootRole == role && r.Perm == nil {
 		fmt.Println("KV Read:")
 		fmt.Println("\t[, <open ended>")
@@ -220,8 +255,11 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 		fmt.Print("\n")
 	}
 
-	for _, perm := range r.Perm {
-		if perm.PermType == v3.PermRead || perm.PermType == v3.PermReadWrite {
+	printPerms := func(deny bool, types ...authpb.Permission_Type) {
+		for _, perm := range r.Perm {
+			if perm.Deny != deny || !hasPermType(types, perm.PermType) {
+				continue
+			}
 			if len(perm.RangeEnd) == 0 {
 				fmt.Printf("\t%s\n", string(perm.Key))
 			} else {
@@ -229,18 +267,48 @@ func (s *simplePrinter) RoleGet(role string, r v3.AuthRoleGetResponse) {
 			}
 		}
 	}
+
+	printPerms(false, v3.PermRead, v3.PermReadWrite)
 	fmt.Println("KV Write:")
-	for _, perm := range r.Perm {
-		if perm.PermType == v3.PermWrite || perm.PermType == v3.PermReadWrite {
-			if len(perm.RangeEnd) == 0 {
-				fmt.Printf("\t%s\n", string(perm.Key))
-			} else {
-				printRange((*v3.Permission)(perm))
+	printPerms(false, v3.PermWrite, v3.PermReadWrite)
+
+	// the other sections are only printed when they are not empty
+	sections := []struct {
+		title string
+		deny  bool
+		types []authpb.Permission_Type
+	}{
+		{"KV Watch:", false, []authpb.Permission_Type{v3.PermWatch}},
+		{"KV Delete:", false, []authpb.Permission_Type{v3.PermDelete}},
+		{"KV Lease:", false, []authpb.Permission_Type{v3.PermLease}},
+		{"Admin:", false, []authpb.Permission_Type{v3.PermAdmin}},
+		{"Denied KV Read:", true, []authpb.Permission_Type{v3.PermRead, v3.PermReadWrite}},
+		{"Denied KV Write:", true, []authpb.Permission_Type{v3.PermWrite, v3.PermReadWrite}},
+		{"Denied KV Watch:", true, []authpb.Permission_Type{v3.PermWatch}},
+		{"Denied KV Delete:", true, []authpb.Permission_Type{v3.PermDelete}},
+		{"Denied KV Lease:", true, []authpb.Permission_Type{v3.PermLease}},
+		{"Denied Admin:", true, []authpb.Permission_Type{v3.PermAdmin}},
+	}
+	for _, sec := range sections {
+		for _, perm := range r.Perm {
+			if perm.Deny == sec.deny && hasPermType(sec.types, perm.PermType) {
+				fmt.Println(sec.title)
+				printPerms(sec.deny, sec.types...)
+				break
 			}
 		}
 	}
 }
 
+func hasPermType(types []authpb.Permission_Type, typ authpb.Permission_Type) bool {
+	for _, t := range types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
 func (s *simplePrinter) RoleList(r v3.AuthRoleListResponse) {
 	for _, role := range r.Roles {
 		fmt.Printf("%s\n", role)
@@ -273,6 +341,9 @@ func (s *simplePrinter) UserAdd(name string, r v3.AuthUserAddResponse) {
 
 func (s *simplePrinter) UserGet(name string, r v3.AuthUserGetResponse) {
 	fmt.Printf("User: %s\n", name)
+	if r.Tenant != "" {
+		fmt.Printf("Tenant: %s\n", r.Tenant)
+	}
 	fmt.Print("Roles:")
 	for _, role := range r.Roles {
 		fmt.Printf(" %s", role)
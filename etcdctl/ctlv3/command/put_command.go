@@ -30,6 +30,7 @@ var (
 	putPrevKV      bool
 	putIgnoreVal   bool
 	putIgnoreLease bool
+	putTTL         int64
 )
 
 // NewPutCommand returns the cobra command for "put".
@@ -62,6 +63,7 @@ will store the content of the file to <key>.
 	cmd.Flags().BoolVar(&putPrevKV, "prev-kv", false, "return the previous key-value pair before modification")
 	cmd.Flags().BoolVar(&putIgnoreVal, "ignore-value", false, "updates the key using its current value")
 	cmd.Flags().BoolVar(&putIgnoreLease, "ignore-lease", false, "updates the key using its current lease")
+	cmd.Flags().Int64Var(&putTTL, "ttl", 0, "time to live of the key in seconds, after which the key is deleted")
 	return cmd
 }
 
@@ -102,6 +104,10 @@ func getPutOp(args []string) (string, string, []clientv3.OpOption) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("bad lease ID (%v), expecting ID in Hex", err))
 	}
 
+	if putTTL != 0 && (id != 0 || putIgnoreLease) {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("'ttl' cannot be combined with 'lease' or 'ignore-lease'"))
+	}
+
 	var opts []clientv3.OpOption
 	if id != 0 {
 		opts = append(opts, clientv3.WithLease(clientv3.LeaseID(id)))
@@ -115,6 +121,9 @@ func getPutOp(args []string) (string, string, []clientv3.OpOption) {
 	if putIgnoreLease {
 		opts = append(opts, clientv3.WithIgnoreLease())
 	}
+	if putTTL != 0 {
+		opts = append(opts, clientv3.WithTTL(putTTL))
+	}
 
 	return key, value, opts
 }
@@ -0,0 +1,116 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+var (
+	quotaMaxBytes int64
+	quotaMaxKeys  int64
+)
+
+// NewQuotaCommand returns the cobra command for "quota".
+func NewQuotaCommand() *cobra.Command {
+	qc := &cobra.Command{
+		Use:   "quota <subcommand>",
+		Short: "Key prefix quota related commands",
+	}
+
+	qc.AddCommand(NewQuotaSetCommand())
+	qc.AddCommand(NewQuotaDeleteCommand())
+	qc.AddCommand(NewQuotaListCommand())
+
+	return qc
+}
+
+func NewQuotaSetCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "set <prefix>",
+		Short: "Sets the storage quota on a key prefix",
+		Run:   quotaSetCommandFunc,
+	}
+	cmd.Flags().Int64Var(&quotaMaxBytes, "max-bytes", 0, "Maximum total size of the keys and values under the prefix (0 is unlimited)")
+	cmd.Flags().Int64Var(&quotaMaxKeys, "max-keys", 0, "Maximum number of keys under the prefix (0 is unlimited)")
+	return &cmd
+}
+
+// quotaSetCommandFunc executes the "quota set" command.
+func quotaSetCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("quota set command needs 1 argument"))
+	}
+	if quotaMaxBytes < 0 || quotaMaxKeys < 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("quota limits must not be negative"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).QuotaSet(ctx, args[0], quotaMaxBytes, quotaMaxKeys)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.QuotaSet(args[0], *resp)
+}
+
+func NewQuotaDeleteCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "delete <prefix>",
+		Short: "Deletes the storage quota on a key prefix",
+		Run:   quotaDeleteCommandFunc,
+	}
+	return &cmd
+}
+
+// quotaDeleteCommandFunc executes the "quota delete" command.
+func quotaDeleteCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("quota delete command needs 1 argument"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).QuotaDelete(ctx, args[0])
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.QuotaDelete(args[0], *resp)
+}
+
+func NewQuotaListCommand() *cobra.Command {
+	cmd := cobra.Command{
+		Use:   "list",
+		Short: "Lists all key prefix quotas and their usage",
+		Run:   quotaListCommandFunc,
+	}
+	return &cmd
+}
+
+// quotaListCommandFunc executes the "quota list" command.
+func quotaListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("quota list command accepts no arguments"))
+	}
+	ctx, cancel := commandCtx(cmd)
+	resp, err := mustClientFromCmd(cmd).QuotaList(ctx)
+	cancel()
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+	display.QuotaList(*resp)
+}
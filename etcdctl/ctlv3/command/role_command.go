@@ -27,6 +27,7 @@ import (
 var (
 	rolePermPrefix  bool
 	rolePermFromKey bool
+	rolePermDeny    bool
 )
 
 // NewRoleCommand returns the cobra command for "role".
@@ -46,12 +47,16 @@ func NewRoleCommand() *cobra.Command {
 	return ac
 }
 
+var roleTenant string
+
 func newRoleAddCommand() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "add <role name>",
 		Short: "Adds a new role",
 		Run:   roleAddCommandFunc,
 	}
+	cmd.Flags().StringVar(&roleTenant, "tenant", "", "Add the role to a tenant")
+	return cmd
 }
 
 func newRoleDeleteCommand() *cobra.Command {
@@ -87,6 +92,7 @@ func newRoleGrantPermissionCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&rolePermPrefix, "prefix", false, "grant a prefix permission")
 	cmd.Flags().BoolVar(&rolePermFromKey, "from-key", false, "grant a permission of keys that are greater than or equal to the given key using byte compare")
+	cmd.Flags().BoolVar(&rolePermDeny, "deny", false, "add a deny rule that overrides the permissions granting the same operations")
 
 	return cmd
 }
@@ -100,6 +106,7 @@ func newRoleRevokePermissionCommand() *cobra.Command {
 
 	cmd.Flags().BoolVar(&rolePermPrefix, "prefix", false, "revoke a prefix permission")
 	cmd.Flags().BoolVar(&rolePermFromKey, "from-key", false, "revoke a permission of keys that are greater than or equal to the given key using byte compare")
+	cmd.Flags().BoolVar(&rolePermDeny, "deny", false, "revoke a deny rule")
 
 	return cmd
 }
@@ -110,7 +117,15 @@ func roleAddCommandFunc(cmd *cobra.Command, args []string) {
 		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("role add command requires role name as its argument"))
 	}
 
-	resp, err := mustClientFromCmd(cmd).Auth.RoleAdd(context.TODO(), args[0])
+	var (
+		resp *clientv3.AuthRoleAddResponse
+		err  error
+	)
+	if roleTenant != "" {
+		resp, err = mustClientFromCmd(cmd).Auth.TenantRoleAdd(context.TODO(), roleTenant, args[0])
+	} else {
+		resp, err = mustClientFromCmd(cmd).Auth.RoleAdd(context.TODO(), args[0])
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
@@ -173,7 +188,12 @@ func roleGrantPermissionCommandFunc(cmd *cobra.Command, args []string) {
 	}
 
 	key, rangeEnd := permRange(args[2:])
-	resp, err := mustClientFromCmd(cmd).Auth.RoleGrantPermission(context.TODO(), args[0], key, rangeEnd, perm)
+	var resp *clientv3.AuthRoleGrantPermissionResponse
+	if rolePermDeny {
+		resp, err = mustClientFromCmd(cmd).Auth.RoleDenyPermission(context.TODO(), args[0], key, rangeEnd, perm)
+	} else {
+		resp, err = mustClientFromCmd(cmd).Auth.RoleGrantPermission(context.TODO(), args[0], key, rangeEnd, perm)
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
@@ -188,7 +208,15 @@ func roleRevokePermissionCommandFunc(cmd *cobra.Command, args []string) {
 	}
 
 	key, rangeEnd := permRange(args[1:])
-	resp, err := mustClientFromCmd(cmd).Auth.RoleRevokePermission(context.TODO(), args[0], key, rangeEnd)
+	var (
+		resp *clientv3.AuthRoleRevokePermissionResponse
+		err  error
+	)
+	if rolePermDeny {
+		resp, err = mustClientFromCmd(cmd).Auth.RoleRevokeDenyPermission(context.TODO(), args[0], key, rangeEnd)
+	} else {
+		resp, err = mustClientFromCmd(cmd).Auth.RoleRevokePermission(context.TODO(), args[0], key, rangeEnd)
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
@@ -0,0 +1,104 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+)
+
+// NewTenantCommand returns the cobra command for "tenant".
+func NewTenantCommand() *cobra.Command {
+	tc := &cobra.Command{
+		Use:   "tenant <subcommand>",
+		Short: "Tenant related commands",
+	}
+
+	tc.AddCommand(newTenantAddCommand())
+	tc.AddCommand(newTenantDeleteCommand())
+	tc.AddCommand(newTenantListCommand())
+
+	return tc
+}
+
+func newTenantAddCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <tenant name> <prefix>",
+		Short: "Adds a new tenant owning the keys under prefix",
+		Run:   tenantAddCommandFunc,
+	}
+}
+
+func newTenantDeleteCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <tenant name>",
+		Short: "Deletes a tenant without users and roles",
+		Run:   tenantDeleteCommandFunc,
+	}
+}
+
+func newTenantListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "Lists all tenants",
+		Run:   tenantListCommandFunc,
+	}
+}
+
+// tenantAddCommandFunc executes the "tenant add" command.
+func tenantAddCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 2 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("tenant add command requires tenant name and prefix as its arguments"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.TenantAdd(context.TODO(), args[0], args[1])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.TenantAdd(args[0], *resp)
+}
+
+// tenantDeleteCommandFunc executes the "tenant delete" command.
+func tenantDeleteCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 1 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("tenant delete command requires tenant name as its argument"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.TenantDelete(context.TODO(), args[0])
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.TenantDelete(args[0], *resp)
+}
+
+// tenantListCommandFunc executes the "tenant list" command.
+func tenantListCommandFunc(cmd *cobra.Command, args []string) {
+	if len(args) != 0 {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, fmt.Errorf("tenant list command requires no arguments"))
+	}
+
+	resp, err := mustClientFromCmd(cmd).Auth.TenantList(context.TODO())
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitError, err)
+	}
+
+	display.TenantList(*resp)
+}
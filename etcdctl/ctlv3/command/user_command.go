@@ -52,6 +52,7 @@ var (
 	passwordInteractive bool
 	passwordFromFlag    string
 	noPassword          bool
+	userTenant          string
 )
 
 func newUserAddCommand() *cobra.Command {
@@ -64,6 +65,7 @@ func newUserAddCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&passwordInteractive, "interactive", true, "Read password from stdin instead of interactive terminal")
 	cmd.Flags().StringVar(&passwordFromFlag, "new-user-password", "", "Supply password from the command line flag")
 	cmd.Flags().BoolVar(&noPassword, "no-password", false, "Create a user without password (CN based auth only)")
+	cmd.Flags().StringVar(&userTenant, "tenant", "", "Add the user to a tenant")
 
 	return &cmd
 }
@@ -163,7 +165,15 @@ func userAddCommandFunc(cmd *cobra.Command, args []string) {
 		options.NoPassword = true
 	}
 
-	resp, err := mustClientFromCmd(cmd).Auth.UserAddWithOptions(context.TODO(), user, password, options)
+	var (
+		resp *clientv3.AuthUserAddResponse
+		err  error
+	)
+	if userTenant != "" {
+		resp, err = mustClientFromCmd(cmd).Auth.TenantUserAdd(context.TODO(), userTenant, user, password, options)
+	} else {
+		resp, err = mustClientFromCmd(cmd).Auth.UserAddWithOptions(context.TODO(), user, password, options)
+	}
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
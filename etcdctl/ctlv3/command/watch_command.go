@@ -22,6 +22,7 @@ import (
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
@@ -42,6 +43,13 @@ var (
 	watchInteractive bool
 	watchPrevKey     bool
 	progressNotify   bool
+
+	watchKeyGlob       string
+	watchKeyRegex      string
+	watchFilters       []string
+	watchOmitUnchanged bool
+	watchCoalesce      time.Duration
+	watchCompress      bool
 )
 
 // NewWatchCommand returns the cobra command for "watch".
@@ -57,6 +65,12 @@ func NewWatchCommand() *cobra.Command {
 	cmd.Flags().Int64Var(&watchRev, "rev", 0, "Revision to start watching")
 	cmd.Flags().BoolVar(&watchPrevKey, "prev-kv", false, "get the previous key-value pair before the event happens")
 	cmd.Flags().BoolVar(&progressNotify, "progress-notify", false, "get periodic watch progress notification from server")
+	cmd.Flags().StringVar(&watchKeyGlob, "key-glob", "", `Only watch the keys matching the glob pattern, e.g. "/pods/*/status"`)
+	cmd.Flags().StringVar(&watchKeyRegex, "key-regex", "", "Only watch the keys matching the regular expression")
+	cmd.Flags().StringArrayVar(&watchFilters, "filter", nil, `Only get put events whose key-value matches the filter, in the format of "get --filter" (repeatable)`)
+	cmd.Flags().BoolVar(&watchOmitUnchanged, "omit-unchanged-value", false, "omit the value of put events that do not change the value")
+	cmd.Flags().DurationVar(&watchCoalesce, "coalesce-window", 0, "delay events by up to the window to merge the events of the same key (at most 10s)")
+	cmd.Flags().BoolVar(&watchCompress, "compress", false, "compress the watch stream with gzip")
 
 	return cmd
 }
@@ -126,7 +140,7 @@ func watchInteractiveFunc(cmd *cobra.Command, osArgs []string, envKey, envRange
 			}
 			go printWatchCh(c, ch, execArgs)
 		case "progress":
-			err := c.RequestProgress(clientv3.WithRequireLeader(context.Background()))
+			err := c.RequestProgress(watchContext())
 			if err != nil {
 				cobrautl.ExitWithError(cobrautl.ExitError, err)
 			}
@@ -160,7 +174,35 @@ func getWatchChan(c *clientv3.Client, args []string) (clientv3.WatchChan, error)
 	if progressNotify {
 		opts = append(opts, clientv3.WithProgressNotify())
 	}
-	return c.Watch(clientv3.WithRequireLeader(context.Background()), key, opts...), nil
+	if watchKeyGlob != "" {
+		opts = append(opts, clientv3.WithKeyGlob(watchKeyGlob))
+	}
+	if watchKeyRegex != "" {
+		opts = append(opts, clientv3.WithKeyRegex(watchKeyRegex))
+	}
+	for _, expr := range watchFilters {
+		f, err := parseGetFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, clientv3.WithFilter(f))
+	}
+	if watchOmitUnchanged {
+		opts = append(opts, clientv3.WithOmitUnchangedValue())
+	}
+	if watchCoalesce != 0 {
+		opts = append(opts, clientv3.WithCoalesceWindow(watchCoalesce))
+	}
+	return c.Watch(watchContext(), key, opts...), nil
+}
+
+// watchContext returns the context of the watch stream of the watches.
+func watchContext() context.Context {
+	ctx := clientv3.WithRequireLeader(context.Background())
+	if watchCompress {
+		ctx = clientv3.WithWatchCompression(ctx)
+	}
+	return ctx
 }
 
 func printWatchCh(c *clientv3.Client, ch clientv3.WatchChan, execArgs []string) {
@@ -83,6 +83,7 @@ func init() {
 		command.NewCompactionCommand(),
 		command.NewAlarmCommand(),
 		command.NewDefragCommand(),
+		command.NewEncryptionCommand(),
 		command.NewEndpointCommand(),
 		command.NewMoveLeaderCommand(),
 		command.NewWatchCommand(),
@@ -91,6 +92,7 @@ func init() {
 		command.NewMemberCommand(),
 		command.NewSnapshotCommand(),
 		command.NewMakeMirrorCommand(),
+		command.NewCDCExportCommand(),
 		command.NewLockCommand(),
 		command.NewElectCommand(),
 		command.NewAuthCommand(),
@@ -99,6 +101,8 @@ func init() {
 		command.NewCheckCommand(),
 		command.NewCompletionCommand(),
 		command.NewDowngradeCommand(),
+		command.NewQuotaCommand(),
+		command.NewTenantCommand(),
 	)
 }
 
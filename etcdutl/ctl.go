@@ -39,6 +39,10 @@ func init() {
 	rootCmd.RegisterFlagCompletionFunc("write-out", func(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
 		return []string{"fields", "json", "protobuf", "simple", "table"}, cobra.ShellCompDirectiveDefault
 	})
+	rootCmd.PersistentFlags().StringVar(&etcdutl.BackendEngine, "backend-engine", etcdutl.BackendEngine, "storage engine of the backend of the data directory or snapshot")
+	rootCmd.PersistentFlags().StringVar(&etcdutl.EncryptionKeyFile, "encryption-key-file", "", "key file of the keys the data directory or snapshot is encrypted with")
+	rootCmd.PersistentFlags().StringVar(&etcdutl.EncryptionKMSSocket, "encryption-kms-socket", "", "unix socket of the KMS plugin decrypting the keys of the encryption key file")
+	rootCmd.PersistentFlags().BoolVar(&etcdutl.EncryptionAllowPlaintext, "encryption-allow-plaintext", false, "read the data that is not encrypted as is rather than rejecting it, e.g. to encrypt an unencrypted snapshot or data directory")
 
 	rootCmd.AddCommand(
 		etcdutl.NewBackupCommand(),
@@ -47,6 +51,7 @@ func init() {
 		etcdutl.NewVersionCommand(),
 		etcdutl.NewCompletionCommand(),
 		etcdutl.NewMigrateCommand(),
+		etcdutl.NewRestoreCommand(),
 	)
 }
 
@@ -38,8 +38,6 @@ import (
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.etcd.io/etcd/server/v3/verify"
 	"go.etcd.io/raft/v3/raftpb"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 var (
@@ -141,6 +139,7 @@ func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, des
 		lg.Fatal("wal.Create failed", zap.Error(err))
 	}
 	defer neww.Close()
+	neww.SetCipher(newCipher())
 	if err := neww.Save(state, ents); err != nil {
 		lg.Fatal("wal.Save failed ", zap.Error(err))
 	}
@@ -159,6 +158,7 @@ func HandleBackup(withV3 bool, srcDir string, destDir string, srcWAL string, des
 
 func saveSnap(lg *zap.Logger, destSnap, srcSnap string, desired *desiredCluster) (walsnap walpb.Snapshot) {
 	ss := snap.New(lg, srcSnap)
+	ss.SetCipher(newCipher())
 	snapshot, err := ss.Load()
 	if err != nil && err != snap.ErrNoSnapshot {
 		lg.Fatal("saveSnap(Snapshoter.Load) failed", zap.Error(err))
@@ -166,6 +166,7 @@ func saveSnap(lg *zap.Logger, destSnap, srcSnap string, desired *desiredCluster)
 	if snapshot != nil {
 		walsnap.Index, walsnap.Term, walsnap.ConfState = snapshot.Metadata.Index, snapshot.Metadata.Term, &desired.confState
 		newss := snap.New(lg, destSnap)
+		newss.SetCipher(newCipher())
 		snapshot.Metadata.ConfState = desired.confState
 		snapshot.Data = mustTranslateV2store(lg, snapshot.Data, desired)
 		if err = newss.SaveSnap(*snapshot); err != nil {
@@ -201,6 +202,7 @@ func translateWAL(lg *zap.Logger, srcWAL string, walsnap walpb.Snapshot) (etcdse
 		lg.Fatal("wal.OpenForRead failed", zap.Error(err))
 	}
 	defer w.Close()
+	w.SetCipher(newCipher())
 	wmetadata, state, ents, err := w.ReadAll()
 	switch err {
 	case nil:
@@ -268,12 +270,16 @@ func raftEntryToNoOp(entry *raftpb.Entry) {
 // saveDB copies the v3 backend and strips cluster information.
 func saveDB(lg *zap.Logger, destDB, srcDB string, idx uint64, term uint64, desired *desiredCluster) {
 	// open src db to safely copy db state
-	var src *bolt.DB
-	ch := make(chan *bolt.DB, 1)
+	var src backend.Engine
+	ch := make(chan backend.Engine, 1)
 	go func() {
-		db, err := bolt.Open(srcDB, 0444, &bolt.Options{ReadOnly: true})
+		bcfg := backend.DefaultBackendConfig(lg)
+		bcfg.Path = srcDB
+		bcfg.Engine = BackendEngine
+		bcfg.ReadOnly = true
+		db, err := backend.OpenEngine(bcfg)
 		if err != nil {
-			lg.Fatal("bolt.Open FAILED", zap.Error(err))
+			lg.Fatal("backend.OpenEngine FAILED", zap.Error(err))
 		}
 		ch <- db
 	}()
@@ -286,7 +292,7 @@ func saveDB(lg *zap.Logger, destDB, srcDB string, idx uint64, term uint64, desir
 
 	tx, err := src.Begin(false)
 	if err != nil {
-		lg.Fatal("bbolt.BeginTx failed", zap.Error(err))
+		lg.Fatal("backend.Engine.Begin failed", zap.Error(err))
 	}
 
 	// copy srcDB to destDB
@@ -295,15 +301,15 @@ func saveDB(lg *zap.Logger, destDB, srcDB string, idx uint64, term uint64, desir
 		lg.Fatal("creation of destination file failed", zap.String("dest", destDB), zap.Error(err))
 	}
 	if _, err := tx.WriteTo(dest); err != nil {
-		lg.Fatal("bbolt write to destination file failed", zap.String("dest", destDB), zap.Error(err))
+		lg.Fatal("write to destination file failed", zap.String("dest", destDB), zap.Error(err))
 	}
 	dest.Close()
 	if err := tx.Rollback(); err != nil {
-		lg.Fatal("bbolt tx.Rollback failed", zap.String("dest", destDB), zap.Error(err))
+		lg.Fatal("tx.Rollback failed", zap.String("dest", destDB), zap.Error(err))
 	}
 
 	// trim membership info
-	be := backend.NewDefaultBackend(lg, destDB)
+	be := newBackend(lg, destDB)
 	defer be.Close()
 	ms := schema.NewMembershipBackend(lg, be)
 	if err := ms.TrimClusterFromBackend(); err != nil {
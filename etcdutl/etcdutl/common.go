@@ -19,7 +19,25 @@ import (
 	"go.uber.org/zap/zapcore"
 
 	"go.etcd.io/etcd/client/pkg/v3/logutil"
+	"go.etcd.io/etcd/etcdutl/v3/snapshot"
 	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+)
+
+// BackendEngine is the storage engine of the backends opened by the commands.
+var BackendEngine = backend.BboltEngine
+
+var (
+	// EncryptionKeyFile is the key file of the keys the data directory or
+	// snapshot is encrypted with. The data is read as is if empty.
+	EncryptionKeyFile string
+	// EncryptionKMSSocket is the unix socket of the KMS plugin decrypting the
+	// keys of EncryptionKeyFile, if they are encrypted.
+	EncryptionKMSSocket string
+	// EncryptionAllowPlaintext reads the data that is not encrypted as is
+	// rather than rejecting it, e.g. to restore an unencrypted snapshot.
+	EncryptionAllowPlaintext bool
 )
 
 func GetLogger() *zap.Logger {
@@ -32,3 +50,33 @@ func GetLogger() *zap.Logger {
 	}
 	return lg
 }
+
+// newCipher returns the cipher of the keys of EncryptionKeyFile, or nil if
+// it is not set.
+func newCipher() encryption.Cipher {
+	if EncryptionKeyFile == "" {
+		return nil
+	}
+	keyring, err := encryption.NewFileKeyring(EncryptionKeyFile, EncryptionKMSSocket)
+	if err != nil {
+		cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+	}
+	keyring.SetAllowPlaintext(EncryptionAllowPlaintext)
+	return keyring
+}
+
+// newBackend opens the backend at path with the storage engine BackendEngine,
+// encrypted with the keys of EncryptionKeyFile.
+func newBackend(lg *zap.Logger, path string) backend.Backend {
+	cfg := backend.DefaultBackendConfig(lg)
+	cfg.Path = path
+	cfg.Engine = BackendEngine
+	cfg.Cipher = newCipher()
+	return backend.New(cfg)
+}
+
+// newSnapshotManager returns a snapshot manager of backends with the storage
+// engine BackendEngine, encrypted with the keys of EncryptionKeyFile.
+func newSnapshotManager(lg *zap.Logger) snapshot.Manager {
+	return snapshot.NewV3WithCipher(lg, BackendEngine, newCipher())
+}
@@ -61,6 +61,8 @@ func DefragData(dataDir string) error {
 		cfg := backend.DefaultBackendConfig(lg)
 		cfg.Logger = lg
 		cfg.Path = dbDir
+		cfg.Engine = BackendEngine
+		cfg.Cipher = newCipher()
 		be = backend.New(cfg)
 	}()
 	select {
@@ -92,7 +92,7 @@ func (o *migrateOptions) Config() (*migrateConfig, error) {
 	}
 
 	dbPath := datadir.ToBackendFileName(o.dataDir)
-	c.be = backend.NewDefaultBackend(GetLogger(), dbPath)
+	c.be = newBackend(GetLogger(), dbPath)
 
 	walPath := datadir.ToWalDir(o.dataDir)
 	w, err := wal.OpenForRead(c.lg, walPath, walpb.Snapshot{})
@@ -100,6 +100,7 @@ func (o *migrateOptions) Config() (*migrateConfig, error) {
 		return nil, fmt.Errorf(`failed to open wal: %v`, err)
 	}
 	defer w.Close()
+	w.SetCipher(newCipher())
 	c.walVersion, err = wal.ReadWALVersion(w)
 	if err != nil {
 		return nil, fmt.Errorf(`failed to read wal: %v`, err)
@@ -0,0 +1,123 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdutl
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.etcd.io/etcd/etcdutl/v3/snapshot"
+	"go.etcd.io/etcd/pkg/v3/cobrautl"
+	"go.etcd.io/etcd/server/v3/storage/datadir"
+)
+
+// NewRestoreCommand returns the cobra command for "restore".
+func NewRestoreCommand() *cobra.Command {
+	o := newRestoreOptions()
+	cmd := &cobra.Command{
+		Use:   "restore <filename> --wal-archive-dir {archive dir} [--to-revision {revision} | --to-time {time}] [options]",
+		Short: "Restores an etcd member snapshot rolled forward with archived WAL segments to a new single member data directory",
+		Run: func(cmd *cobra.Command, args []string) {
+			cfg, err := o.Config(args)
+			if err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitBadArgs, err)
+			}
+			if err = newSnapshotManager(GetLogger()).Restore(*cfg); err != nil {
+				cobrautl.ExitWithError(cobrautl.ExitError, err)
+			}
+		},
+	}
+	o.AddFlags(cmd)
+	return cmd
+}
+
+type restoreOptions struct {
+	walArchiveDir string
+	toRevision    int64
+	toTime        string
+
+	dataDir       string
+	walDir        string
+	name          string
+	peerURLs      string
+	clusterToken  string
+	skipHashCheck bool
+}
+
+func newRestoreOptions() *restoreOptions {
+	return &restoreOptions{
+		name:         defaultName,
+		peerURLs:     defaultInitialAdvertisePeerURLs,
+		clusterToken: "etcd-cluster",
+	}
+}
+
+func (o *restoreOptions) AddFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&o.walArchiveDir, "wal-archive-dir", o.walArchiveDir, "Path to the WAL segments archived by --experimental-wal-archive-dir")
+	cmd.MarkFlagRequired("wal-archive-dir")
+	cmd.MarkFlagDirname("wal-archive-dir")
+	cmd.Flags().Int64Var(&o.toRevision, "to-revision", o.toRevision, "Revision to restore the key space to. Replays all archived entries if neither --to-revision nor --to-time is given")
+	cmd.Flags().StringVar(&o.toTime, "to-time", o.toTime, "Time in RFC3339 format to restore the key space to, with a precision of a second")
+
+	cmd.Flags().StringVar(&o.dataDir, "data-dir", o.dataDir, "Path to the output data directory")
+	cmd.MarkFlagDirname("data-dir")
+	cmd.Flags().StringVar(&o.walDir, "wal-dir", o.walDir, "Path to the WAL directory (use --data-dir if none given)")
+	cmd.MarkFlagDirname("wal-dir")
+	cmd.Flags().StringVar(&o.name, "name", o.name, "Human-readable name for the member")
+	cmd.Flags().StringVar(&o.peerURLs, "initial-advertise-peer-urls", o.peerURLs, "List of the member's peer URLs")
+	cmd.Flags().StringVar(&o.clusterToken, "initial-cluster-token", o.clusterToken, "Initial cluster token for the restored cluster")
+	cmd.Flags().BoolVar(&o.skipHashCheck, "skip-hash-check", o.skipHashCheck, "Ignore snapshot integrity hash value (required if copied from data directory)")
+}
+
+func (o *restoreOptions) Config(args []string) (*snapshot.RestoreConfig, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("restore requires exactly one argument")
+	}
+	cfg := &snapshot.RestoreConfig{
+		SnapshotPath:        args[0],
+		Name:                o.name,
+		OutputDataDir:       o.dataDir,
+		OutputWALDir:        o.walDir,
+		PeerURLs:            strings.Split(o.peerURLs, ","),
+		InitialCluster:      fmt.Sprintf("%s=%s", o.name, o.peerURLs),
+		InitialClusterToken: o.clusterToken,
+		SkipHashCheck:       o.skipHashCheck,
+		WALArchiveDir:       o.walArchiveDir,
+		ToRevision:          o.toRevision,
+	}
+	if o.toRevision < 0 {
+		return nil, fmt.Errorf("invalid --to-revision %d", o.toRevision)
+	}
+	if o.toTime != "" {
+		if o.toRevision != 0 {
+			return nil, fmt.Errorf("--to-revision and --to-time cannot be used together")
+		}
+		t, err := time.Parse(time.RFC3339, o.toTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --to-time %q: %v", o.toTime, err)
+		}
+		cfg.ToTime = t
+	}
+	if cfg.OutputDataDir == "" {
+		cfg.OutputDataDir = o.name + ".etcd"
+	}
+	if cfg.OutputWALDir == "" {
+		cfg.OutputWALDir = datadir.ToWalDir(cfg.OutputDataDir)
+	}
+	return cfg, nil
+}
@@ -38,6 +38,7 @@ var (
 	restorePeerURLs     string
 	restoreName         string
 	skipHashCheck       bool
+	restoreDeltas       []string
 )
 
 // NewSnapshotCommand returns the cobra command for "snapshot".
@@ -75,6 +76,7 @@ func NewSnapshotRestoreCommand() *cobra.Command {
 	cmd.Flags().StringVar(&restorePeerURLs, "initial-advertise-peer-urls", defaultInitialAdvertisePeerURLs, "List of this member's peer URLs to advertise to the rest of the cluster")
 	cmd.Flags().StringVar(&restoreName, "name", defaultName, "Human-readable name for this member")
 	cmd.Flags().BoolVar(&skipHashCheck, "skip-hash-check", false, "Ignore snapshot integrity hash value (required if copied from data directory)")
+	cmd.Flags().StringArrayVar(&restoreDeltas, "delta", nil, "Snapshot delta saved by 'etcdctl snapshot save --incremental-from' to apply on top of the snapshot (can be repeated to apply a chain of deltas in order)")
 
 	cmd.MarkFlagDirname("data-dir")
 	cmd.MarkFlagDirname("wal-dir")
@@ -90,7 +92,7 @@ func SnapshotStatusCommandFunc(cmd *cobra.Command, args []string) {
 	printer := initPrinterFromCmd(cmd)
 
 	lg := GetLogger()
-	sp := snapshot.NewV3(lg)
+	sp := newSnapshotManager(lg)
 	ds, err := sp.Status(args[0])
 	if err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
@@ -100,7 +102,7 @@ func SnapshotStatusCommandFunc(cmd *cobra.Command, args []string) {
 
 func snapshotRestoreCommandFunc(_ *cobra.Command, args []string) {
 	SnapshotRestoreCommandFunc(restoreCluster, restoreClusterToken, restoreDataDir, restoreWalDir,
-		restorePeerURLs, restoreName, skipHashCheck, args)
+		restorePeerURLs, restoreName, skipHashCheck, restoreDeltas, args)
 }
 
 func SnapshotRestoreCommandFunc(restoreCluster string,
@@ -110,6 +112,7 @@ func SnapshotRestoreCommandFunc(restoreCluster string,
 	restorePeerURLs string,
 	restoreName string,
 	skipHashCheck bool,
+	deltaPaths []string,
 	args []string) {
 	if len(args) != 1 {
 		err := fmt.Errorf("snapshot restore requires exactly one argument")
@@ -127,7 +130,7 @@ func SnapshotRestoreCommandFunc(restoreCluster string,
 	}
 
 	lg := GetLogger()
-	sp := snapshot.NewV3(lg)
+	sp := newSnapshotManager(lg)
 
 	if err := sp.Restore(snapshot.RestoreConfig{
 		SnapshotPath:        args[0],
@@ -138,6 +141,7 @@ func SnapshotRestoreCommandFunc(restoreCluster string,
 		InitialCluster:      restoreCluster,
 		InitialClusterToken: restoreClusterToken,
 		SkipHashCheck:       skipHashCheck,
+		DeltaPaths:          deltaPaths,
 	}); err != nil {
 		cobrautl.ExitWithError(cobrautl.ExitError, err)
 	}
@@ -0,0 +1,136 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/v3/snapshot"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// applyDeltas brings the restored database up to date with the chain of
+// snapshot deltas, in order.
+func (s *v3Manager) applyDeltas() error {
+	for _, path := range s.deltaPaths {
+		if err := s.applyDelta(path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyDelta applies the snapshot delta at path. The delta is linked to the
+// restored database by checking its revision and hash against the base of
+// the delta before it is applied, and against the end of the delta after.
+func (s *v3Manager) applyDelta(path string) error {
+	dr, err := snapshot.OpenDelta(path)
+	if err != nil {
+		return err
+	}
+	defer dr.Close()
+	info := dr.Info
+
+	be := backend.New(s.backendConfig(s.outDbPath()))
+	defer be.Close()
+
+	if err = verifyDeltaLink(s.lg, be, info.BaseRevision, info.BaseRevision, info.BaseHash); err != nil {
+		return fmt.Errorf("snapshot delta %s does not apply to the restored database: %w", path, err)
+	}
+
+	buckets := map[string]backend.Bucket{string(schema.Key.Name()): schema.Key}
+	tx := be.BatchTx()
+	tx.LockOutsideApply()
+	for _, name := range info.FullBuckets {
+		b, ok := deltaFullBucket(name)
+		if !ok {
+			tx.Unlock()
+			return fmt.Errorf("snapshot delta %s has unknown bucket %q", path, name)
+		}
+		tx.UnsafeDeleteBucket(b)
+		tx.UnsafeCreateBucket(b)
+		buckets[string(name)] = b
+	}
+	tx.Unlock()
+
+	entries := 0
+	for {
+		ents, err := dr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("cannot read snapshot delta %s: %w", path, err)
+		}
+		tx.LockOutsideApply()
+		for _, e := range ents {
+			b, ok := buckets[string(e.Bucket)]
+			if !ok {
+				tx.Unlock()
+				return fmt.Errorf("snapshot delta %s has unknown bucket %q", path, e.Bucket)
+			}
+			tx.UnsafePut(b, e.Key, e.Value)
+		}
+		tx.Unlock()
+		entries += len(ents)
+	}
+	be.ForceCommit()
+
+	if err = verifyDeltaLink(s.lg, be, info.BaseRevision, info.Revision, info.Hash); err != nil {
+		return fmt.Errorf("snapshot delta %s did not restore its revision: %w", path, err)
+	}
+	s.lg.Info(
+		"applied snapshot delta",
+		zap.String("path", path),
+		zap.Int64("base-revision", info.BaseRevision),
+		zap.Int64("revision", info.Revision),
+		zap.Int("entries", entries),
+	)
+	return nil
+}
+
+// verifyDeltaLink checks that the key space of the backend is at rev and
+// hashes to hash, ignoring the revisions before baseRev.
+func verifyDeltaLink(lg *zap.Logger, be backend.Backend, baseRev, rev int64, hash uint32) error {
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer kv.Close()
+	if kv.Rev() != rev {
+		return fmt.Errorf("expected revision %d, got %d", rev, kv.Rev())
+	}
+	h, err := kv.HashStorage().HashSince(baseRev, rev)
+	if err != nil {
+		return err
+	}
+	if h.Hash != hash {
+		return fmt.Errorf("expected hash %d at revision %d, got %d", hash, rev, h.Hash)
+	}
+	return nil
+}
+
+func deltaFullBucket(name []byte) (backend.Bucket, bool) {
+	for _, b := range schema.DeltaFullBuckets {
+		if bytes.Equal(b.Name(), name) {
+			return b, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,175 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snapshot
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3quota"
+	"go.etcd.io/etcd/server/v3/etcdserver/apply"
+	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+	"go.etcd.io/etcd/server/v3/storage/wal"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// replayWarningApplyDuration matches the default of the server.
+const replayWarningApplyDuration = 100 * time.Millisecond
+
+// replayArchive rolls the restored database forward by applying the entries
+// archived in the WAL archive directory after its consistent index, up to
+// the target revision or time.
+func (s *v3Manager) replayArchive() error {
+	ci := cindex.NewConsistentIndex(nil)
+	bcfg := s.backendConfig(s.outDbPath())
+	bcfg.Hooks = storage.NewBackendHooks(s.lg, ci)
+	be := backend.New(bcfg)
+	defer be.Close()
+	ci.SetBackend(be)
+
+	start := ci.ConsistentIndex()
+	ents, marks, err := wal.ReadArchive(s.lg, s.walArchiveDir, start, s.cipher)
+	if err != nil {
+		return fmt.Errorf("cannot read WAL archive %q after index %d: %w", s.walArchiveDir, start, err)
+	}
+	if !s.toTime.IsZero() {
+		ents = entriesCommittedBy(ents, marks, s.toTime)
+	}
+
+	// the membership of the restored member is rebuilt from scratch, so
+	// the cluster is only there for the lessor and the applier
+	cl := membership.NewCluster(s.lg)
+	cl.SetBackend(schema.NewMembershipBackend(s.lg, be))
+
+	// the lessor is never promoted, so leases are only revoked by the
+	// archived entries
+	lessor := lease.NewLessor(s.lg, be, cl, lease.LessorConfig{MinLeaseTTL: 1})
+	defer lessor.Stop()
+
+	quotaStore := v3quota.NewQuotaStore(s.lg)
+	kv := mvcc.New(s.lg, be, lessor, mvcc.StoreConfig{UsageTracker: quotaStore})
+	defer kv.Close()
+	if err = quotaStore.Recover(schema.NewQuotaBackend(s.lg, be), kv); err != nil {
+		return err
+	}
+
+	tp, err := auth.NewTokenProvider(s.lg, "", nil, 0)
+	if err != nil {
+		return err
+	}
+	authStore := auth.NewAuthStore(s.lg, schema.NewAuthBackend(s.lg, be), tp, bcrypt.DefaultCost)
+	defer authStore.Close()
+
+	alarmStore, err := v3alarm.NewAlarmStore(s.lg, schema.NewAlarmBackend(s.lg, be))
+	if err != nil {
+		return err
+	}
+
+	if s.toRevision > 0 && kv.Rev() > s.toRevision {
+		return fmt.Errorf("snapshot revision %d is after the target revision %d", kv.Rev(), s.toRevision)
+	}
+
+	status := &replayStatus{}
+	ua := apply.NewUberApplier(s.lg, be, kv, alarmStore, quotaStore, authStore, lessor, cl, status, status, ci,
+		replayWarningApplyDuration, false, 0)
+
+	applied := start
+	for i := range ents {
+		e := &ents[i]
+		if s.toRevision > 0 && kv.Rev() >= s.toRevision {
+			break
+		}
+		status.index, status.term = e.Index, e.Term
+		if r, ok := replayRequest(e); ok {
+			ci.SetConsistentApplyingIndex(e.Index, e.Term)
+			ua.Apply(r, membership.ApplyBoth)
+		}
+		ci.SetConsistentIndex(e.Index, e.Term)
+		applied = e.Index
+	}
+	if s.toRevision > 0 && kv.Rev() < s.toRevision {
+		return fmt.Errorf("WAL archive ends at revision %d before the target revision %d", kv.Rev(), s.toRevision)
+	}
+
+	s.lg.Info(
+		"replayed archived WAL entries",
+		zap.String("wal-archive-dir", s.walArchiveDir),
+		zap.Uint64("from-index", start),
+		zap.Uint64("to-index", applied),
+		zap.Int64("revision", kv.Rev()),
+	)
+	return nil
+}
+
+// entriesCommittedBy returns the entries that were committed by t according
+// to the marks of the archive.
+func entriesCommittedBy(ents []raftpb.Entry, marks []wal.ArchiveMark, t time.Time) []raftpb.Entry {
+	var index uint64
+	for _, m := range marks {
+		if m.Time.After(t) {
+			break
+		}
+		index = m.Index
+	}
+	for len(ents) > 0 && ents[len(ents)-1].Index > index {
+		ents = ents[:len(ents)-1]
+	}
+	return ents
+}
+
+// replayRequest returns the request of the entry that is replayed on top of
+// the snapshot. Empty entries, configuration changes, v2 requests and
+// membership requests are not replayed, as the restored member starts a new
+// cluster.
+func replayRequest(e *raftpb.Entry) (*pb.InternalRaftRequest, bool) {
+	if e.Type != raftpb.EntryNormal || len(e.Data) == 0 {
+		return nil, false
+	}
+	var r pb.InternalRaftRequest
+	if !pbutil.MaybeUnmarshal(&r, e.Data) || r.V2 != nil {
+		return nil, false
+	}
+	if r.ClusterVersionSet != nil || r.ClusterMemberAttrSet != nil || r.DowngradeInfoSet != nil {
+		return nil, false
+	}
+	return &r, true
+}
+
+// replayStatus is the raft status seen by the applier during a replay.
+type replayStatus struct {
+	index uint64
+	term  uint64
+}
+
+func (rs *replayStatus) MemberId() types.ID     { return 0 }
+func (rs *replayStatus) Leader() types.ID       { return 0 }
+func (rs *replayStatus) CommittedIndex() uint64 { return rs.index }
+func (rs *replayStatus) AppliedIndex() uint64   { return rs.index }
+func (rs *replayStatus) Term() uint64           { return rs.term }
+func (rs *replayStatus) ForceSnapshot()         {}
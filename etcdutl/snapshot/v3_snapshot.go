@@ -24,11 +24,10 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
-	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
-	bolt "go.etcd.io/bbolt"
 	"go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/client/pkg/v3/types"
@@ -41,6 +40,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/schema"
 	"go.etcd.io/etcd/server/v3/storage/wal"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
@@ -74,8 +74,23 @@ func NewV3(lg *zap.Logger) Manager {
 	return &v3Manager{lg: lg}
 }
 
+// NewV3WithEngine returns a new snapshot Manager for v3.x snapshot of a
+// backend with the given storage engine.
+func NewV3WithEngine(lg *zap.Logger, engine string) Manager {
+	return &v3Manager{lg: lg, engine: engine}
+}
+
+// NewV3WithCipher returns a new snapshot Manager for v3.x snapshot of a
+// backend with the given storage engine, whose data is encrypted at rest
+// with c. The restored WAL and snapshot files are encrypted with c as well.
+func NewV3WithCipher(lg *zap.Logger, engine string, c encryption.Cipher) Manager {
+	return &v3Manager{lg: lg, engine: engine, cipher: c}
+}
+
 type v3Manager struct {
-	lg *zap.Logger
+	lg     *zap.Logger
+	engine string
+	cipher encryption.Cipher
 
 	name      string
 	srcDbPath string
@@ -84,6 +99,12 @@ type v3Manager struct {
 	cl        *membership.RaftCluster
 
 	skipHashCheck bool
+
+	walArchiveDir string
+	toRevision    int64
+	toTime        time.Time
+
+	deltaPaths []string
 }
 
 // hasChecksum returns "true" if the file size "n"
@@ -116,7 +137,9 @@ func (s *v3Manager) Status(dbPath string) (ds Status, err error) {
 		return ds, err
 	}
 
-	db, err := bolt.Open(dbPath, 0400, &bolt.Options{ReadOnly: true})
+	bcfg := s.backendConfig(dbPath)
+	bcfg.ReadOnly = true
+	db, err := backend.OpenEngine(bcfg)
 	if err != nil {
 		return ds, err
 	}
@@ -124,46 +147,45 @@ func (s *v3Manager) Status(dbPath string) (ds Status, err error) {
 
 	h := crc32.New(crc32.MakeTable(crc32.Castagnoli))
 
-	if err = db.View(func(tx *bolt.Tx) error {
-		// check snapshot file integrity first
-		var dbErrStrings []string
-		for dbErr := range tx.Check() {
-			dbErrStrings = append(dbErrStrings, dbErr.Error())
-		}
-		if len(dbErrStrings) > 0 {
-			return fmt.Errorf("snapshot file integrity check failed. %d errors found.\n"+strings.Join(dbErrStrings, "\n"), len(dbErrStrings))
+	tx, err := db.Begin(false)
+	if err != nil {
+		return ds, err
+	}
+	defer tx.Rollback()
+
+	// check snapshot file integrity first
+	if err = tx.Check(); err != nil {
+		return ds, fmt.Errorf("snapshot file integrity check failed. %v", err)
+	}
+	ds.TotalSize = tx.Size()
+	v := schema.ReadStorageVersionFromSnapshot(tx)
+	if v != nil {
+		ds.Version = v.String()
+	}
+	if err = tx.ForEachBucket(func(next []byte) error {
+		b := tx.Bucket(next)
+		if b == nil {
+			return fmt.Errorf("cannot get hash of bucket %s", string(next))
 		}
-		ds.TotalSize = tx.Size()
-		v := schema.ReadStorageVersionFromSnapshot(tx)
-		if v != nil {
-			ds.Version = v.String()
+		if _, err := h.Write(next); err != nil {
+			return fmt.Errorf("cannot write bucket %s : %v", string(next), err)
 		}
-		c := tx.Cursor()
-		for next, _ := c.First(); next != nil; next, _ = c.Next() {
-			b := tx.Bucket(next)
-			if b == nil {
-				return fmt.Errorf("cannot get hash of bucket %s", string(next))
+		iskeyb := (string(next) == "key")
+		if err := b.ForEach(func(k, v []byte) error {
+			if _, err := h.Write(k); err != nil {
+				return fmt.Errorf("cannot write to bucket %s", err.Error())
 			}
-			if _, err := h.Write(next); err != nil {
-				return fmt.Errorf("cannot write bucket %s : %v", string(next), err)
+			if _, err := h.Write(v); err != nil {
+				return fmt.Errorf("cannot write to bucket %s", err.Error())
 			}
-			iskeyb := (string(next) == "key")
-			if err := b.ForEach(func(k, v []byte) error {
-				if _, err := h.Write(k); err != nil {
-					return fmt.Errorf("cannot write to bucket %s", err.Error())
-				}
-				if _, err := h.Write(v); err != nil {
-					return fmt.Errorf("cannot write to bucket %s", err.Error())
-				}
-				if iskeyb {
-					rev := bytesToRev(k)
-					ds.Revision = rev.main
-				}
-				ds.TotalKey++
-				return nil
-			}); err != nil {
-				return fmt.Errorf("cannot write bucket %s : %v", string(next), err)
+			if iskeyb {
+				rev := bytesToRev(k)
+				ds.Revision = rev.main
 			}
+			ds.TotalKey++
+			return nil
+		}); err != nil {
+			return fmt.Errorf("cannot write bucket %s : %v", string(next), err)
 		}
 		return nil
 	}); err != nil {
@@ -203,6 +225,21 @@ type RestoreConfig struct {
 	// SkipHashCheck is "true" to ignore snapshot integrity hash value
 	// (required if copied from data directory).
 	SkipHashCheck bool
+
+	// WALArchiveDir is the directory of archived WAL segments to roll the
+	// snapshot forward with. If empty, the snapshot is restored as is.
+	WALArchiveDir string
+	// ToRevision stops the roll forward once the key space reaches the
+	// revision. If zero, all archived entries are replayed.
+	ToRevision int64
+	// ToTime stops the roll forward at the entries committed by the time.
+	// If zero, all archived entries are replayed.
+	ToTime time.Time
+
+	// DeltaPaths are the paths of the snapshot deltas applied in order on
+	// top of the snapshot. The first delta must start at the revision of the
+	// snapshot, and every other one at the revision the previous one ended.
+	DeltaPaths []string
 }
 
 // Restore restores a new etcd data directory from given snapshot file.
@@ -217,6 +254,16 @@ func (s *v3Manager) Restore(cfg RestoreConfig) error {
 		return err
 	}
 
+	if cfg.WALArchiveDir == "" && (cfg.ToRevision != 0 || !cfg.ToTime.IsZero()) {
+		return fmt.Errorf("restoring to a revision or time requires a WAL archive directory")
+	}
+	if cfg.ToRevision != 0 && !cfg.ToTime.IsZero() {
+		return fmt.Errorf("cannot restore to both a revision and a time")
+	}
+	if cfg.WALArchiveDir != "" && len(cfg.DeltaPaths) > 0 {
+		return fmt.Errorf("cannot roll forward with both a WAL archive and snapshot deltas")
+	}
+
 	srv := config.ServerConfig{
 		Logger:              s.lg,
 		Name:                cfg.Name,
@@ -253,6 +300,10 @@ func (s *v3Manager) Restore(cfg RestoreConfig) error {
 	s.walDir = walDir
 	s.snapDir = filepath.Join(dataDir, "member", "snap")
 	s.skipHashCheck = cfg.SkipHashCheck
+	s.walArchiveDir = cfg.WALArchiveDir
+	s.toRevision = cfg.ToRevision
+	s.toTime = cfg.ToTime
+	s.deltaPaths = cfg.DeltaPaths
 
 	s.lg.Info(
 		"restoring snapshot",
@@ -293,14 +344,34 @@ func (s *v3Manager) outDbPath() string {
 	return filepath.Join(s.snapDir, "db")
 }
 
-// saveDB copies the database snapshot to the snapshot directory
+// backendConfig returns the configuration of the backend at path, with the
+// storage engine and the cipher of the manager.
+func (s *v3Manager) backendConfig(path string) backend.BackendConfig {
+	bcfg := backend.DefaultBackendConfig(s.lg)
+	bcfg.Path = path
+	bcfg.Engine = s.engine
+	bcfg.Cipher = s.cipher
+	return bcfg
+}
+
+// saveDB copies the database snapshot to the snapshot directory, rolling it
+// forward with the WAL archive or the snapshot deltas if any
 func (s *v3Manager) saveDB() error {
 	err := s.copyAndVerifyDB()
 	if err != nil {
 		return err
 	}
 
-	be := backend.NewDefaultBackend(s.lg, s.outDbPath())
+	if s.walArchiveDir != "" {
+		if err = s.replayArchive(); err != nil {
+			return err
+		}
+	}
+	if err = s.applyDeltas(); err != nil {
+		return err
+	}
+
+	be := backend.New(s.backendConfig(s.outDbPath()))
 	defer be.Close()
 
 	err = schema.NewMembershipBackend(s.lg, be).TrimMembershipFromBackend()
@@ -398,7 +469,7 @@ func (s *v3Manager) saveWALAndSnap() (*raftpb.HardState, error) {
 	// add members again to persist them to the store we create.
 	st := v2store.New(etcdserver.StoreClusterPrefix, etcdserver.StoreKeysPrefix)
 	s.cl.SetStore(st)
-	be := backend.NewDefaultBackend(s.lg, s.outDbPath())
+	be := backend.New(s.backendConfig(s.outDbPath()))
 	defer be.Close()
 	s.cl.SetBackend(schema.NewMembershipBackend(s.lg, be))
 	for _, m := range s.cl.Members() {
@@ -416,6 +487,7 @@ func (s *v3Manager) saveWALAndSnap() (*raftpb.HardState, error) {
 		return nil, walerr
 	}
 	defer w.Close()
+	w.SetCipher(s.cipher)
 
 	peers := make([]raft.Peer, len(s.cl.MemberIDs()))
 	for i, id := range s.cl.MemberIDs() {
@@ -473,6 +545,7 @@ func (s *v3Manager) saveWALAndSnap() (*raftpb.HardState, error) {
 		},
 	}
 	sn := snap.New(s.lg, s.snapDir)
+	sn.SetCipher(s.cipher)
 	if err := sn.SaveSnap(raftSnap); err != nil {
 		return nil, err
 	}
@@ -481,7 +554,7 @@ func (s *v3Manager) saveWALAndSnap() (*raftpb.HardState, error) {
 }
 
 func (s *v3Manager) updateCIndex(commit uint64, term uint64) error {
-	be := backend.NewDefaultBackend(s.lg, s.outDbPath())
+	be := backend.New(s.backendConfig(s.outDbPath()))
 	defer be.Close()
 
 	cindex.UpdateConsistentIndexForce(be.BatchTx(), commit, term)
@@ -66,6 +66,7 @@ tools_build() {
   tools_path="tools/benchmark
     tools/etcd-dump-db
     tools/etcd-dump-logs
+    tools/etcd-replicator
     tools/local-tester/bridge"
   for tool in ${tools_path}
   do
@@ -21,14 +21,37 @@ import (
 	"go.etcd.io/etcd/pkg/v3/adt"
 )
 
+// grantedOps maps each permission type to the operations it covers. The
+// operations are identified by the permission type checked for them: READ
+// for ranges, WRITE for puts, WATCH, DELETE, LEASE and ADMIN.
+var grantedOps = map[authpb.Permission_Type][]authpb.Permission_Type{
+	authpb.READ:      {authpb.READ, authpb.WATCH},
+	authpb.WRITE:     {authpb.WRITE, authpb.DELETE, authpb.LEASE},
+	authpb.READWRITE: {authpb.READ, authpb.WATCH, authpb.WRITE, authpb.DELETE, authpb.LEASE},
+	authpb.WATCH:     {authpb.WATCH},
+	authpb.DELETE:    {authpb.DELETE},
+	authpb.LEASE:     {authpb.LEASE},
+	authpb.ADMIN:     {authpb.ADMIN},
+}
+
 func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
 	user := tx.UnsafeGetUser(userName)
 	if user == nil {
 		return nil
 	}
 
-	readPerms := adt.NewIntervalTree()
-	writePerms := adt.NewIntervalTree()
+	perms := newUnifiedRangePermissions()
+	perms.denied = newUnifiedRangePermissions()
+
+	// permissions of tenant roles are relative to the tenant prefix
+	var tenantPrefix []byte
+	if user.Tenant != "" {
+		tenant := tx.UnsafeGetTenant(user.Tenant)
+		if tenant == nil {
+			return nil
+		}
+		tenantPrefix = tenant.Prefix
+	}
 
 	for _, roleName := range user.Roles {
 		role := tx.UnsafeGetRole(roleName)
@@ -40,34 +63,32 @@ func getMergedPerms(tx AuthReadTx, userName string) *unifiedRangePermissions {
 			var ivl adt.Interval
 			var rangeEnd []byte
 
-			if len(perm.RangeEnd) != 1 || perm.RangeEnd[0] != 0 {
-				rangeEnd = perm.RangeEnd
+			key, permRangeEnd := perm.Key, perm.RangeEnd
+			if tenantPrefix != nil {
+				key, permRangeEnd = PrefixInterval(tenantPrefix, key, permRangeEnd)
 			}
 
-			if len(perm.RangeEnd) != 0 {
-				ivl = adt.NewBytesAffineInterval(perm.Key, rangeEnd)
-			} else {
-				ivl = adt.NewBytesAffinePoint(perm.Key)
+			if len(permRangeEnd) != 1 || permRangeEnd[0] != 0 {
+				rangeEnd = permRangeEnd
 			}
 
-			switch perm.PermType {
-			case authpb.READWRITE:
-				readPerms.Insert(ivl, struct{}{})
-				writePerms.Insert(ivl, struct{}{})
-
-			case authpb.READ:
-				readPerms.Insert(ivl, struct{}{})
+			if len(permRangeEnd) != 0 {
+				ivl = adt.NewBytesAffineInterval(key, rangeEnd)
+			} else {
+				ivl = adt.NewBytesAffinePoint(key)
+			}
 
-			case authpb.WRITE:
-				writePerms.Insert(ivl, struct{}{})
+			target := perms
+			if perm.Deny {
+				target = perms.denied
+			}
+			for _, op := range grantedOps[perm.PermType] {
+				target.tree(op).Insert(ivl, struct{}{})
 			}
 		}
 	}
 
-	return &unifiedRangePermissions{
-		readPerms:  readPerms,
-		writePerms: writePerms,
-	}
+	return perms
 }
 
 func checkKeyInterval(
@@ -80,28 +101,24 @@ func checkKeyInterval(
 	}
 
 	ivl := adt.NewBytesAffineInterval(key, rangeEnd)
-	switch permtyp {
-	case authpb.READ:
-		return cachedPerms.readPerms.Contains(ivl)
-	case authpb.WRITE:
-		return cachedPerms.writePerms.Contains(ivl)
-	default:
+	perms := cachedPerms.tree(permtyp)
+	if perms == nil {
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
-	return false
+	if !perms.Contains(ivl) {
+		return false
+	}
+	// a deny rule on any key of the interval denies the whole interval
+	return !cachedPerms.isDenied(ivl, permtyp)
 }
 
 func checkKeyPoint(lg *zap.Logger, cachedPerms *unifiedRangePermissions, key []byte, permtyp authpb.Permission_Type) bool {
 	pt := adt.NewBytesAffinePoint(key)
-	switch permtyp {
-	case authpb.READ:
-		return cachedPerms.readPerms.Intersects(pt)
-	case authpb.WRITE:
-		return cachedPerms.writePerms.Intersects(pt)
-	default:
+	perms := cachedPerms.tree(permtyp)
+	if perms == nil {
 		lg.Panic("unknown auth type", zap.String("auth-type", permtyp.String()))
 	}
-	return false
+	return perms.Intersects(pt) && !cachedPerms.isDenied(pt, permtyp)
 }
 
 func (as *authStore) isRangeOpPermitted(userName string, key, rangeEnd []byte, permtyp authpb.Permission_Type) bool {
@@ -135,10 +152,16 @@ func (as *authStore) refreshRangePermCache(tx AuthReadTx) {
 	as.lg.Debug("Refreshing rangePermCache")
 
 	as.rangePermCache = make(map[string]*unifiedRangePermissions)
+	as.tenantPrefixCache = make(map[string][]byte)
 
 	users := tx.UnsafeGetAllUsers()
 	for _, user := range users {
 		userName := string(user.Name)
+		if user.Tenant != "" {
+			if tenant := tx.UnsafeGetTenant(user.Tenant); tenant != nil {
+				as.tenantPrefixCache[userName] = tenant.Prefix
+			}
+		}
 		perms := getMergedPerms(tx, userName)
 		if perms == nil {
 			as.lg.Error(
@@ -152,6 +175,52 @@ func (as *authStore) refreshRangePermCache(tx AuthReadTx) {
 }
 
 type unifiedRangePermissions struct {
-	readPerms  adt.IntervalTree
-	writePerms adt.IntervalTree
+	readPerms   adt.IntervalTree
+	writePerms  adt.IntervalTree
+	watchPerms  adt.IntervalTree
+	deletePerms adt.IntervalTree
+	leasePerms  adt.IntervalTree
+	adminPerms  adt.IntervalTree
+
+	// denied holds the deny rules of the user, if any.
+	denied *unifiedRangePermissions
+}
+
+func newUnifiedRangePermissions() *unifiedRangePermissions {
+	return &unifiedRangePermissions{
+		readPerms:   adt.NewIntervalTree(),
+		writePerms:  adt.NewIntervalTree(),
+		watchPerms:  adt.NewIntervalTree(),
+		deletePerms: adt.NewIntervalTree(),
+		leasePerms:  adt.NewIntervalTree(),
+		adminPerms:  adt.NewIntervalTree(),
+	}
+}
+
+// tree returns the intervals on which the operation checked with permtyp is
+// granted, or nil if permtyp does not identify an operation.
+func (perms *unifiedRangePermissions) tree(permtyp authpb.Permission_Type) adt.IntervalTree {
+	switch permtyp {
+	case authpb.READ:
+		return perms.readPerms
+	case authpb.WRITE:
+		return perms.writePerms
+	case authpb.WATCH:
+		return perms.watchPerms
+	case authpb.DELETE:
+		return perms.deletePerms
+	case authpb.LEASE:
+		return perms.leasePerms
+	case authpb.ADMIN:
+		return perms.adminPerms
+	}
+	return nil
+}
+
+func (perms *unifiedRangePermissions) isDenied(ivl adt.Interval, permtyp authpb.Permission_Type) bool {
+	if perms.denied == nil {
+		return false
+	}
+	denied := perms.denied.tree(permtyp)
+	return denied != nil && denied.Intersects(ivl)
 }
@@ -100,3 +100,36 @@ func TestKeyPermission(t *testing.T) {
 		}
 	}
 }
+
+func TestDeniedPermission(t *testing.T) {
+	perms := newUnifiedRangePermissions()
+	perms.denied = newUnifiedRangePermissions()
+	perms.readPerms.Insert(adt.NewBytesAffineInterval([]byte("/config/"), []byte("/config0")), struct{}{})
+	perms.denied.readPerms.Insert(adt.NewBytesAffineInterval([]byte("/config/secrets/"), []byte("/config/secrets0")), struct{}{})
+
+	tests := []struct {
+		begin []byte
+		end   []byte
+		want  bool
+	}{
+		{[]byte("/config/a"), nil, true},
+		{[]byte("/config/secrets/a"), nil, false},
+		{[]byte("/config/a"), []byte("/config/b"), true},
+		// a range that overlaps a deny rule is denied as a whole
+		{[]byte("/config/"), []byte("/config0"), false},
+		{[]byte("/config/secrets0"), []byte("/config0"), true},
+	}
+
+	lg := zaptest.NewLogger(t)
+	for i, tt := range tests {
+		var result bool
+		if len(tt.end) == 0 {
+			result = checkKeyPoint(lg, perms, tt.begin, authpb.READ)
+		} else {
+			result = checkKeyInterval(lg, perms, tt.begin, tt.end, authpb.READ)
+		}
+		if result != tt.want {
+			t.Errorf("#%d: result=%t, want=%t", i, result, tt.want)
+		}
+	}
+}
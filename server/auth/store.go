@@ -28,6 +28,7 @@ import (
 	"go.etcd.io/etcd/api/v3/authpb"
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/pkg/v3/adt"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
@@ -65,6 +66,13 @@ var (
 	ErrMissingKey           = errors.New("auth: missing key data")
 	ErrKeyMismatch          = errors.New("auth: public and private keys don't match")
 	ErrVerifyOnly           = errors.New("auth: token signing attempted with verify-only key")
+	ErrTenantEmpty          = errors.New("auth: tenant name is empty")
+	ErrTenantPrefixEmpty    = errors.New("auth: tenant prefix is empty")
+	ErrTenantAlreadyExist   = errors.New("auth: tenant already exists")
+	ErrTenantNotFound       = errors.New("auth: tenant not found")
+	ErrTenantPrefixConflict = errors.New("auth: tenant prefix overlaps the prefix of another tenant")
+	ErrTenantNotEmpty       = errors.New("auth: tenant still has users or roles")
+	ErrTenantMismatch       = errors.New("auth: user and role belong to different tenants")
 )
 
 const (
@@ -142,6 +150,22 @@ type AuthStore interface {
 	// RoleList gets a list of all roles
 	RoleList(r *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
 
+	// TenantAdd adds a new tenant
+	TenantAdd(r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error)
+
+	// TenantDelete deletes a tenant without users and roles
+	TenantDelete(r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error)
+
+	// TenantList gets a list of all tenants
+	TenantList(r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error)
+
+	// TenantPrefix returns the prefix the requests of the user are confined to,
+	// or nil if the user doesn't belong to a tenant
+	TenantPrefix(authInfo *AuthInfo) []byte
+
+	// HasTenantUsers returns true if any user belongs to a tenant
+	HasTenantUsers() bool
+
 	// IsPutPermitted checks put permission of the user
 	IsPutPermitted(authInfo *AuthInfo, key []byte) error
 
@@ -151,6 +175,21 @@ type AuthStore interface {
 	// IsDeleteRangePermitted checks delete-range permission of the user
 	IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
 
+	// IsWatchPermitted checks watch permission of the user
+	IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
+	// IsLeasePermitted checks permission of the user to attach a lease to the key
+	// or to revoke a lease attached to the key
+	IsLeasePermitted(authInfo *AuthInfo, key []byte) error
+
+	// IsAdminRangePermitted checks admin permission of the user on a key range
+	IsAdminRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error
+
+	// IsCompactPermitted checks compaction permission of the user. Compaction
+	// is permitted unless the user is denied admin permission on the whole key
+	// space, as it discards the history of every key.
+	IsCompactPermitted(authInfo *AuthInfo) error
+
 	// IsAdminPermitted checks admin permission of the user
 	IsAdminPermitted(authInfo *AuthInfo) error
 
@@ -203,6 +242,8 @@ type AuthBackend interface {
 	GetAllUsers() []*authpb.User
 	GetRole(string) *authpb.Role
 	GetAllRoles() []*authpb.Role
+	GetTenant(string) *authpb.Tenant
+	GetAllTenants() []*authpb.Tenant
 }
 
 type AuthBatchTx interface {
@@ -213,6 +254,8 @@ type AuthBatchTx interface {
 	UnsafeDeleteUser(string)
 	UnsafePutRole(*authpb.Role)
 	UnsafeDeleteRole(string)
+	UnsafePutTenant(*authpb.Tenant)
+	UnsafeDeleteTenant(string)
 }
 
 type AuthReadTx interface {
@@ -222,6 +265,8 @@ type AuthReadTx interface {
 	UnsafeGetRole(string) *authpb.Role
 	UnsafeGetAllUsers() []*authpb.User
 	UnsafeGetAllRoles() []*authpb.Role
+	UnsafeGetTenant(string) *authpb.Tenant
+	UnsafeGetAllTenants() []*authpb.Tenant
 	Lock()
 	Unlock()
 }
@@ -243,6 +288,8 @@ type authStore struct {
 	// see also: https://github.com/etcd-io/etcd/pull/13920#discussion_r849114855
 	rangePermCache   map[string]*unifiedRangePermissions // username -> unifiedRangePermissions
 	rangePermCacheMu sync.RWMutex
+	// tenantPrefixCache is protected by rangePermCacheMu and refreshed together with rangePermCache
+	tenantPrefixCache map[string][]byte // username -> prefix of the tenant of the user
 
 	tokenProvider TokenProvider
 	bcryptCost    int // the algorithm cost / strength for hashing auth passwords
@@ -380,6 +427,8 @@ func (as *authStore) CheckPassword(username, password string) (uint64, error) {
 }
 
 func (as *authStore) Recover(be AuthBackend) {
+	// the backend may come from a snapshot taken before the tenant bucket existed
+	be.CreateAuthBuckets()
 	as.be = be
 	tx := be.ReadTx()
 	tx.Lock()
@@ -411,6 +460,11 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 		return nil, ErrUserEmpty
 	}
 
+	if r.Tenant != "" && r.Name == rootUser {
+		as.lg.Error("'root' user cannot belong to a tenant", zap.String("tenant-name", r.Tenant))
+		return nil, ErrInvalidAuthMgmt
+	}
+
 	tx := as.be.BatchTx()
 	tx.Lock()
 	defer tx.Unlock()
@@ -420,6 +474,10 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 		return nil, ErrUserAlreadyExist
 	}
 
+	if r.Tenant != "" && tx.UnsafeGetTenant(r.Tenant) == nil {
+		return nil, ErrTenantNotFound
+	}
+
 	options := r.Options
 	if options == nil {
 		options = &authpb.UserAddOptions{
@@ -441,13 +499,14 @@ func (as *authStore) UserAdd(r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse,
 		Name:     []byte(r.Name),
 		Password: password,
 		Options:  options,
+		Tenant:   r.Tenant,
 	}
 	tx.UnsafePutUser(newUser)
 
 	as.commitRevision(tx)
 	as.refreshRangePermCache(tx)
 
-	as.lg.Info("added a user", zap.String("user-name", r.Name))
+	as.lg.Info("added a user", zap.String("user-name", r.Name), zap.String("tenant-name", r.Tenant))
 	return &pb.AuthUserAddResponse{}, nil
 }
 
@@ -505,6 +564,7 @@ func (as *authStore) UserChangePassword(r *pb.AuthUserChangePasswordRequest) (*p
 		Roles:    user.Roles,
 		Password: password,
 		Options:  user.Options,
+		Tenant:   user.Tenant,
 	}
 	tx.UnsafePutUser(updatedUser)
 
@@ -531,11 +591,24 @@ func (as *authStore) UserGrantRole(r *pb.AuthUserGrantRoleRequest) (*pb.AuthUser
 		return nil, ErrUserNotFound
 	}
 
+	var roleTenant string
 	if r.Role != rootRole {
 		role := tx.UnsafeGetRole(r.Role)
 		if role == nil {
 			return nil, ErrRoleNotFound
 		}
+		roleTenant = role.Tenant
+	}
+
+	if user.Tenant != roleTenant {
+		as.lg.Error(
+			"cannot grant a role of another tenant to a user",
+			zap.String("user-name", r.User),
+			zap.String("user-tenant-name", user.Tenant),
+			zap.String("role-name", r.Role),
+			zap.String("role-tenant-name", roleTenant),
+		)
+		return nil, ErrTenantMismatch
 	}
 
 	idx := sort.SearchStrings(user.Roles, r.Role)
@@ -575,6 +648,7 @@ func (as *authStore) UserGet(r *pb.AuthUserGetRequest) (*pb.AuthUserGetResponse,
 
 	var resp pb.AuthUserGetResponse
 	resp.Roles = append(resp.Roles, user.Roles...)
+	resp.Tenant = user.Tenant
 	return &resp, nil
 }
 
@@ -611,6 +685,7 @@ func (as *authStore) UserRevokeRole(r *pb.AuthUserRevokeRoleRequest) (*pb.AuthUs
 		Name:     user.Name,
 		Password: user.Password,
 		Options:  user.Options,
+		Tenant:   user.Tenant,
 	}
 
 	for _, role := range user.Roles {
@@ -650,6 +725,7 @@ func (as *authStore) RoleGet(r *pb.AuthRoleGetRequest) (*pb.AuthRoleGetResponse,
 	} else {
 		resp.Perm = append(resp.Perm, role.KeyPermission...)
 	}
+	resp.Tenant = role.Tenant
 	return &resp, nil
 }
 
@@ -674,11 +750,12 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 	}
 
 	updatedRole := &authpb.Role{
-		Name: role.Name,
+		Name:   role.Name,
+		Tenant: role.Tenant,
 	}
 
 	for _, perm := range role.KeyPermission {
-		if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) {
+		if !bytes.Equal(perm.Key, r.Key) || !bytes.Equal(perm.RangeEnd, r.RangeEnd) || perm.Deny != r.Deny {
 			updatedRole.KeyPermission = append(updatedRole.KeyPermission, perm)
 		}
 	}
@@ -697,6 +774,7 @@ func (as *authStore) RoleRevokePermission(r *pb.AuthRoleRevokePermissionRequest)
 		zap.String("role-name", r.Role),
 		zap.String("key", string(r.Key)),
 		zap.String("range-end", string(r.RangeEnd)),
+		zap.Bool("deny", r.Deny),
 	)
 	return &pb.AuthRoleRevokePermissionResponse{}, nil
 }
@@ -724,6 +802,7 @@ func (as *authStore) RoleDelete(r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDelete
 			Name:     user.Name,
 			Password: user.Password,
 			Options:  user.Options,
+			Tenant:   user.Tenant,
 		}
 
 		for _, role := range user.Roles {
@@ -752,6 +831,11 @@ func (as *authStore) RoleAdd(r *pb.AuthRoleAddRequest) (*pb.AuthRoleAddResponse,
 		return nil, ErrRoleEmpty
 	}
 
+	if r.Tenant != "" && r.Name == rootRole {
+		as.lg.Error("'root' role cannot belong to a tenant", zap.String("tenant-name", r.Tenant))
+		return nil, ErrInvalidAuthMgmt
+	}
+
 	tx := as.be.BatchTx()
 	tx.Lock()
 	defer tx.Unlock()
@@ -761,15 +845,20 @@ func (as *authStore) RoleAdd(r *pb.AuthRoleAddRequest) (*pb.AuthRoleAddResponse,
 		return nil, ErrRoleAlreadyExist
 	}
 
+	if r.Tenant != "" && tx.UnsafeGetTenant(r.Tenant) == nil {
+		return nil, ErrTenantNotFound
+	}
+
 	newRole := &authpb.Role{
-		Name: []byte(r.Name),
+		Name:   []byte(r.Name),
+		Tenant: r.Tenant,
 	}
 
 	tx.UnsafePutRole(newRole)
 
 	as.commitRevision(tx)
 
-	as.lg.Info("created a role", zap.String("role-name", r.Name))
+	as.lg.Info("created a role", zap.String("role-name", r.Name), zap.String("tenant-name", r.Tenant))
 	return &pb.AuthRoleAddResponse{}, nil
 }
 
@@ -777,6 +866,17 @@ func (as *authStore) authInfoFromToken(ctx context.Context, token string) (*Auth
 	return as.tokenProvider.info(ctx, token, as.Revision())
 }
 
+// permClass returns the class of a permission type. A role has one permission
+// of each class on a key range: READ, WRITE and READWRITE replace each other,
+// and each other type is a class of its own.
+func permClass(permType authpb.Permission_Type) authpb.Permission_Type {
+	switch permType {
+	case authpb.READ, authpb.WRITE, authpb.READWRITE:
+		return authpb.READWRITE
+	}
+	return permType
+}
+
 type permSlice []*authpb.Permission
 
 func (perms permSlice) Len() int {
@@ -809,7 +909,15 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 		return bytes.Compare(role.KeyPermission[i].Key, r.Perm.Key) >= 0
 	})
 
-	if idx < len(role.KeyPermission) && bytes.Equal(role.KeyPermission[idx].Key, r.Perm.Key) && bytes.Equal(role.KeyPermission[idx].RangeEnd, r.Perm.RangeEnd) {
+	// a range has at most one allow and one deny permission of each class
+	for ; idx < len(role.KeyPermission) && bytes.Equal(role.KeyPermission[idx].Key, r.Perm.Key); idx++ {
+		perm := role.KeyPermission[idx]
+		if bytes.Equal(perm.RangeEnd, r.Perm.RangeEnd) && perm.Deny == r.Perm.Deny && permClass(perm.PermType) == permClass(r.Perm.PermType) {
+			break
+		}
+	}
+
+	if idx < len(role.KeyPermission) && bytes.Equal(role.KeyPermission[idx].Key, r.Perm.Key) {
 		// update existing permission
 		role.KeyPermission[idx].PermType = r.Perm.PermType
 	} else {
@@ -818,6 +926,7 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 			Key:      r.Perm.Key,
 			RangeEnd: r.Perm.RangeEnd,
 			PermType: r.Perm.PermType,
+			Deny:     r.Perm.Deny,
 		}
 
 		role.KeyPermission = append(role.KeyPermission, newPerm)
@@ -835,6 +944,7 @@ func (as *authStore) RoleGrantPermission(r *pb.AuthRoleGrantPermissionRequest) (
 		zap.String("permission-name", authpb.Permission_Type_name[int32(r.Perm.PermType)]),
 		zap.ByteString("key", r.Perm.Key),
 		zap.ByteString("range-end", r.Perm.RangeEnd),
+		zap.Bool("deny", r.Perm.Deny),
 	)
 	return &pb.AuthRoleGrantPermissionResponse{}, nil
 }
@@ -890,7 +1000,43 @@ func (as *authStore) IsRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte)
 }
 
 func (as *authStore) IsDeleteRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
-	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.WRITE)
+	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.DELETE)
+}
+
+func (as *authStore) IsWatchPermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.WATCH)
+}
+
+func (as *authStore) IsLeasePermitted(authInfo *AuthInfo, key []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, nil, authpb.LEASE)
+}
+
+func (as *authStore) IsAdminRangePermitted(authInfo *AuthInfo, key, rangeEnd []byte) error {
+	return as.isOpPermitted(authInfo.Username, authInfo.Revision, key, rangeEnd, authpb.ADMIN)
+}
+
+func (as *authStore) IsCompactPermitted(authInfo *AuthInfo) error {
+	// compaction has never required a permission; only deny rules restrict it
+	if !as.IsAuthEnabled() || authInfo == nil || authInfo.Username == "" {
+		return nil
+	}
+
+	tx := as.be.ReadTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	user := tx.UnsafeGetUser(authInfo.Username)
+	if user == nil || hasRootRole(user) {
+		return nil
+	}
+
+	as.rangePermCacheMu.RLock()
+	defer as.rangePermCacheMu.RUnlock()
+	perms, ok := as.rangePermCache[authInfo.Username]
+	if ok && perms.denied != nil && perms.denied.adminPerms.Contains(adt.NewBytesAffineInterval([]byte{0}, nil)) {
+		return ErrPermissionDenied
+	}
+	return nil
 }
 
 func (as *authStore) IsAdminPermitted(authInfo *AuthInfo) error {
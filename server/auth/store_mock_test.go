@@ -19,14 +19,16 @@ import "go.etcd.io/etcd/api/v3/authpb"
 type backendMock struct {
 	users    map[string]*authpb.User
 	roles    map[string]*authpb.Role
+	tenants  map[string]*authpb.Tenant
 	enabled  bool
 	revision uint64
 }
 
 func newBackendMock() *backendMock {
 	return &backendMock{
-		users: make(map[string]*authpb.User),
-		roles: make(map[string]*authpb.Role),
+		users:   make(map[string]*authpb.User),
+		roles:   make(map[string]*authpb.Role),
+		tenants: make(map[string]*authpb.Tenant),
 	}
 }
 
@@ -60,6 +62,14 @@ func (b *backendMock) GetAllRoles() []*authpb.Role {
 	return b.BatchTx().UnsafeGetAllRoles()
 }
 
+func (b *backendMock) GetTenant(s string) *authpb.Tenant {
+	return b.tenants[s]
+}
+
+func (b *backendMock) GetAllTenants() []*authpb.Tenant {
+	return b.BatchTx().UnsafeGetAllTenants()
+}
+
 var _ AuthBackend = (*backendMock)(nil)
 
 type txMock struct {
@@ -100,6 +110,18 @@ func (t txMock) UnsafeGetAllRoles() []*authpb.Role {
 	return roles
 }
 
+func (t txMock) UnsafeGetTenant(s string) *authpb.Tenant {
+	return t.be.tenants[s]
+}
+
+func (t txMock) UnsafeGetAllTenants() []*authpb.Tenant {
+	var tenants []*authpb.Tenant
+	for _, tenant := range t.be.tenants {
+		tenants = append(tenants, tenant)
+	}
+	return tenants
+}
+
 func (t txMock) Lock() {
 }
 
@@ -129,3 +151,11 @@ func (t txMock) UnsafePutRole(role *authpb.Role) {
 func (t txMock) UnsafeDeleteRole(s string) {
 	delete(t.be.roles, s)
 }
+
+func (t txMock) UnsafePutTenant(tenant *authpb.Tenant) {
+	t.be.tenants[string(tenant.Name)] = tenant
+}
+
+func (t txMock) UnsafeDeleteTenant(s string) {
+	delete(t.be.tenants, s)
+}
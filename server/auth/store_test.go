@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 
 	"golang.org/x/crypto/bcrypt"
@@ -525,6 +526,134 @@ func TestRoleGrantPermission(t *testing.T) {
 	assert.Equal(t, perm, r.Perm[0])
 }
 
+func TestRoleDenyPermission(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	allow := &authpb.Permission{PermType: authpb.READWRITE, Key: []byte("a"), RangeEnd: []byte("c")}
+	deny := &authpb.Permission{PermType: authpb.DELETE, Key: []byte("a"), RangeEnd: []byte("c"), Deny: true}
+	for _, perm := range []*authpb.Permission{allow, deny} {
+		_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test", Perm: perm})
+		require.NoError(t, err)
+	}
+
+	// an allow and a deny permission on the same range are kept apart
+	r, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*authpb.Permission{allow, deny}, r.Perm)
+
+	_, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", Key: []byte("a"), RangeEnd: []byte("c"), Deny: true})
+	require.NoError(t, err)
+	r, err = as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	require.NoError(t, err)
+	assert.Equal(t, []*authpb.Permission{allow}, r.Perm)
+
+	_, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", Key: []byte("a"), RangeEnd: []byte("c"), Deny: true})
+	assert.Equal(t, ErrPermissionNotGranted, err)
+}
+
+func TestRoleGrantPermissionClasses(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	grant := func(permType authpb.Permission_Type) *authpb.Permission {
+		perm := &authpb.Permission{PermType: permType, Key: []byte("a"), RangeEnd: []byte("c")}
+		_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test", Perm: perm})
+		require.NoError(t, err)
+		return perm
+	}
+
+	// READ, WRITE and READWRITE replace each other, the other types are kept apart
+	grant(authpb.READ)
+	write := grant(authpb.WRITE)
+	admin := grant(authpb.ADMIN)
+	lease := grant(authpb.LEASE)
+	r, err := as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []*authpb.Permission{write, admin, lease}, r.Perm)
+
+	// revoking the range revokes every permission on it
+	_, err = as.RoleRevokePermission(&pb.AuthRoleRevokePermissionRequest{Role: "role-test", Key: []byte("a"), RangeEnd: []byte("c")})
+	require.NoError(t, err)
+	r, err = as.RoleGet(&pb.AuthRoleGetRequest{Role: "role-test"})
+	require.NoError(t, err)
+	assert.Empty(t, r.Perm)
+}
+
+func TestIsOpPermittedPerOperation(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	perms := []*authpb.Permission{
+		{PermType: authpb.READWRITE, Key: []byte("/app/"), RangeEnd: []byte("/app0")},
+		{PermType: authpb.DELETE, Key: []byte("/app/keep/"), RangeEnd: []byte("/app/keep0"), Deny: true},
+		{PermType: authpb.READ, Key: []byte("/app/secret"), Deny: true},
+		{PermType: authpb.WATCH, Key: []byte("/events/"), RangeEnd: []byte("/events0")},
+		{PermType: authpb.ADMIN, Key: []byte("/app/"), RangeEnd: []byte("/app0")},
+	}
+	for _, perm := range perms {
+		_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{Name: "role-test", Perm: perm})
+		require.NoError(t, err)
+	}
+	_, err := as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"})
+	require.NoError(t, err)
+
+	tests := []struct {
+		key, rangeEnd []byte
+		permType      authpb.Permission_Type
+		want          error
+	}{
+		{[]byte("/app/a"), nil, authpb.READ, nil},
+		{[]byte("/app/a"), nil, authpb.WATCH, nil},
+		{[]byte("/app/a"), nil, authpb.DELETE, nil},
+		{[]byte("/app/a"), nil, authpb.LEASE, nil},
+		{[]byte("/app/keep/a"), nil, authpb.WRITE, nil},
+		{[]byte("/app/keep/a"), nil, authpb.DELETE, ErrPermissionDenied},
+		{[]byte("/app/"), []byte("/app0"), authpb.DELETE, ErrPermissionDenied},
+		{[]byte("/app/secret"), nil, authpb.READ, ErrPermissionDenied},
+		{[]byte("/app/secret"), nil, authpb.WATCH, ErrPermissionDenied},
+		{[]byte("/app/secret"), nil, authpb.WRITE, nil},
+		{[]byte("/events/a"), nil, authpb.WATCH, nil},
+		{[]byte("/events/a"), nil, authpb.READ, ErrPermissionDenied},
+		{[]byte("/app/a/"), []byte("/app/a0"), authpb.ADMIN, nil},
+		{[]byte("/events/"), []byte("/events0"), authpb.ADMIN, ErrPermissionDenied},
+	}
+	for i, tt := range tests {
+		err := as.isOpPermitted("foo", as.Revision(), tt.key, tt.rangeEnd, tt.permType)
+		assert.Equal(t, tt.want, err, "#%d", i)
+	}
+}
+
+func TestIsCompactPermitted(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	ai := &AuthInfo{Username: "foo", Revision: as.Revision()}
+	assert.NoError(t, as.IsCompactPermitted(ai))
+
+	_, err := as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.ADMIN, Key: []byte("/secret/"), RangeEnd: []byte("/secret0"), Deny: true},
+	})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "role-test"})
+	require.NoError(t, err)
+
+	// a deny rule on a part of the key space doesn't deny compaction
+	ai.Revision = as.Revision()
+	assert.NoError(t, as.IsCompactPermitted(ai))
+
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "role-test",
+		Perm: &authpb.Permission{PermType: authpb.ADMIN, Key: []byte{0}, RangeEnd: []byte{0}, Deny: true},
+	})
+	require.NoError(t, err)
+
+	ai.Revision = as.Revision()
+	assert.Equal(t, ErrPermissionDenied, as.IsCompactPermitted(ai))
+	assert.NoError(t, as.IsCompactPermitted(&AuthInfo{Username: "root", Revision: as.Revision()}))
+}
+
 func TestRootRoleGrantPermission(t *testing.T) {
 	as, tearDown := setupAuthStore(t)
 	defer tearDown(t)
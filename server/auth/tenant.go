@@ -0,0 +1,147 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"bytes"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// A tenant owns the key space under its prefix. Users and roles of a tenant
+// see that key space as if it was the whole key space: the API layer prefixes
+// the keys of their requests and strips the prefix from the responses, and
+// the permissions of tenant roles are granted relative to the prefix.
+
+func (as *authStore) TenantAdd(r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error) {
+	if len(r.Name) == 0 {
+		return nil, ErrTenantEmpty
+	}
+	if len(r.Prefix) == 0 {
+		return nil, ErrTenantPrefixEmpty
+	}
+
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	if tx.UnsafeGetTenant(r.Name) != nil {
+		return nil, ErrTenantAlreadyExist
+	}
+
+	for _, tenant := range tx.UnsafeGetAllTenants() {
+		if bytes.HasPrefix(tenant.Prefix, r.Prefix) || bytes.HasPrefix(r.Prefix, tenant.Prefix) {
+			as.lg.Error(
+				"tenant prefix overlaps the prefix of another tenant",
+				zap.String("tenant-name", r.Name),
+				zap.ByteString("prefix", r.Prefix),
+				zap.String("conflicting-tenant-name", string(tenant.Name)),
+				zap.ByteString("conflicting-prefix", tenant.Prefix),
+			)
+			return nil, ErrTenantPrefixConflict
+		}
+	}
+
+	tx.UnsafePutTenant(&authpb.Tenant{
+		Name:   []byte(r.Name),
+		Prefix: r.Prefix,
+	})
+
+	as.commitRevision(tx)
+
+	as.lg.Info("added a tenant", zap.String("tenant-name", r.Name), zap.ByteString("prefix", r.Prefix))
+	return &pb.AuthTenantAddResponse{}, nil
+}
+
+func (as *authStore) TenantDelete(r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error) {
+	tx := as.be.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+
+	if tx.UnsafeGetTenant(r.Name) == nil {
+		return nil, ErrTenantNotFound
+	}
+
+	for _, user := range tx.UnsafeGetAllUsers() {
+		if user.Tenant == r.Name {
+			return nil, ErrTenantNotEmpty
+		}
+	}
+	for _, role := range tx.UnsafeGetAllRoles() {
+		if role.Tenant == r.Name {
+			return nil, ErrTenantNotEmpty
+		}
+	}
+
+	tx.UnsafeDeleteTenant(r.Name)
+
+	as.commitRevision(tx)
+
+	as.lg.Info("deleted a tenant", zap.String("tenant-name", r.Name))
+	return &pb.AuthTenantDeleteResponse{}, nil
+}
+
+func (as *authStore) TenantList(r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error) {
+	return &pb.AuthTenantListResponse{Tenants: as.be.GetAllTenants()}, nil
+}
+
+func (as *authStore) TenantPrefix(authInfo *AuthInfo) []byte {
+	if authInfo == nil {
+		return nil
+	}
+	as.rangePermCacheMu.RLock()
+	defer as.rangePermCacheMu.RUnlock()
+	return as.tenantPrefixCache[authInfo.Username]
+}
+
+func (as *authStore) HasTenantUsers() bool {
+	as.rangePermCacheMu.RLock()
+	defer as.rangePermCacheMu.RUnlock()
+	return len(as.tenantPrefixCache) > 0
+}
+
+// PrefixInterval maps the interval [key, rangeEnd) of a tenant onto the key
+// space under prefix. As in range requests, an empty rangeEnd denotes the
+// single key and a rangeEnd of "\x00" denotes all keys from key onwards, which
+// becomes all keys of the tenant from key onwards.
+func PrefixInterval(prefix, key, rangeEnd []byte) ([]byte, []byte) {
+	pkey := make([]byte, 0, len(prefix)+len(key))
+	pkey = append(append(pkey, prefix...), key...)
+	if len(rangeEnd) == 0 {
+		return pkey, nil
+	}
+	if len(rangeEnd) == 1 && rangeEnd[0] == 0 {
+		return pkey, prefixEnd(prefix)
+	}
+	pend := make([]byte, 0, len(prefix)+len(rangeEnd))
+	return pkey, append(append(pend, prefix...), rangeEnd...)
+}
+
+// prefixEnd returns the range end covering all keys with the given prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff; range to the end of the key space
+	return []byte{0}
+}
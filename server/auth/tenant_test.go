@@ -0,0 +1,156 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestTenantAdd(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.TenantAdd(&pb.AuthTenantAddRequest{Name: "t1", Prefix: []byte("/t1/")})
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name    string
+		req     *pb.AuthTenantAddRequest
+		wantErr error
+	}{
+		{"empty name", &pb.AuthTenantAddRequest{Prefix: []byte("/t2/")}, ErrTenantEmpty},
+		{"empty prefix", &pb.AuthTenantAddRequest{Name: "t2"}, ErrTenantPrefixEmpty},
+		{"existing name", &pb.AuthTenantAddRequest{Name: "t1", Prefix: []byte("/t2/")}, ErrTenantAlreadyExist},
+		{"nested prefix", &pb.AuthTenantAddRequest{Name: "t2", Prefix: []byte("/t1/a/")}, ErrTenantPrefixConflict},
+		{"enclosing prefix", &pb.AuthTenantAddRequest{Name: "t2", Prefix: []byte("/t")}, ErrTenantPrefixConflict},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := as.TenantAdd(tc.req)
+			assert.Equal(t, tc.wantErr, err)
+		})
+	}
+
+	resp, err := as.TenantList(&pb.AuthTenantListRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, []*authpb.Tenant{{Name: []byte("t1"), Prefix: []byte("/t1/")}}, resp.Tenants)
+}
+
+func TestTenantDelete(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.TenantDelete(&pb.AuthTenantDeleteRequest{Name: "t1"})
+	assert.Equal(t, ErrTenantNotFound, err)
+
+	_, err = as.TenantAdd(&pb.AuthTenantAddRequest{Name: "t1", Prefix: []byte("/t1/")})
+	require.NoError(t, err)
+	_, err = as.RoleAdd(&pb.AuthRoleAddRequest{Name: "t1-role", Tenant: "t1"})
+	require.NoError(t, err)
+
+	_, err = as.TenantDelete(&pb.AuthTenantDeleteRequest{Name: "t1"})
+	assert.Equal(t, ErrTenantNotEmpty, err)
+
+	_, err = as.RoleDelete(&pb.AuthRoleDeleteRequest{Role: "t1-role"})
+	require.NoError(t, err)
+	_, err = as.TenantDelete(&pb.AuthTenantDeleteRequest{Name: "t1"})
+	require.NoError(t, err)
+
+	resp, err := as.TenantList(&pb.AuthTenantListRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, resp.Tenants)
+}
+
+func TestTenantUserAdd(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.UserAdd(&pb.AuthUserAddRequest{Name: "u1", Options: &authpb.UserAddOptions{NoPassword: true}, Tenant: "t1"})
+	assert.Equal(t, ErrTenantNotFound, err)
+	_, err = as.RoleAdd(&pb.AuthRoleAddRequest{Name: "r1", Tenant: "t1"})
+	assert.Equal(t, ErrTenantNotFound, err)
+
+	_, err = as.TenantAdd(&pb.AuthTenantAddRequest{Name: "t1", Prefix: []byte("/t1/")})
+	require.NoError(t, err)
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "root", Options: &authpb.UserAddOptions{NoPassword: true}, Tenant: "t1"})
+	assert.Equal(t, ErrInvalidAuthMgmt, err)
+
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "u1", Options: &authpb.UserAddOptions{NoPassword: true}, Tenant: "t1"})
+	require.NoError(t, err)
+	ur, err := as.UserGet(&pb.AuthUserGetRequest{Name: "u1"})
+	require.NoError(t, err)
+	assert.Equal(t, "t1", ur.Tenant)
+
+	assert.True(t, as.HasTenantUsers())
+	assert.Equal(t, []byte("/t1/"), as.TenantPrefix(&AuthInfo{Username: "u1"}))
+	assert.Nil(t, as.TenantPrefix(&AuthInfo{Username: "foo"}))
+	assert.Nil(t, as.TenantPrefix(nil))
+}
+
+func TestTenantUserGrantRole(t *testing.T) {
+	as, tearDown := setupAuthStore(t)
+	defer tearDown(t)
+
+	_, err := as.TenantAdd(&pb.AuthTenantAddRequest{Name: "t1", Prefix: []byte("/t1/")})
+	require.NoError(t, err)
+	_, err = as.UserAdd(&pb.AuthUserAddRequest{Name: "u1", Options: &authpb.UserAddOptions{NoPassword: true}, Tenant: "t1"})
+	require.NoError(t, err)
+	_, err = as.RoleAdd(&pb.AuthRoleAddRequest{Name: "r1", Tenant: "t1"})
+	require.NoError(t, err)
+
+	// roles and users of different tenants cannot be mixed
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "u1", Role: "role-test"})
+	assert.Equal(t, ErrTenantMismatch, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "foo", Role: "r1"})
+	assert.Equal(t, ErrTenantMismatch, err)
+
+	// permissions of tenant roles are relative to the tenant prefix
+	_, err = as.RoleGrantPermission(&pb.AuthRoleGrantPermissionRequest{
+		Name: "r1",
+		Perm: &authpb.Permission{PermType: authpb.READWRITE, Key: []byte("a"), RangeEnd: []byte{0}},
+	})
+	require.NoError(t, err)
+	_, err = as.UserGrantRole(&pb.AuthUserGrantRoleRequest{User: "u1", Role: "r1"})
+	require.NoError(t, err)
+
+	assert.NoError(t, as.isOpPermitted("u1", as.Revision(), []byte("/t1/a"), nil, authpb.READ))
+	assert.NoError(t, as.isOpPermitted("u1", as.Revision(), []byte("/t1/b"), []byte("/t10"), authpb.WRITE))
+	assert.Equal(t, ErrPermissionDenied, as.isOpPermitted("u1", as.Revision(), []byte("a"), nil, authpb.READ))
+	assert.Equal(t, ErrPermissionDenied, as.isOpPermitted("u1", as.Revision(), []byte("/t1/a"), []byte{0}, authpb.READ))
+}
+
+func TestPrefixInterval(t *testing.T) {
+	tcs := []struct {
+		key, rangeEnd    []byte
+		wantKey, wantEnd []byte
+		prefix           []byte
+	}{
+		{key: []byte("a"), prefix: []byte("/t/"), wantKey: []byte("/t/a")},
+		{key: []byte("a"), rangeEnd: []byte("b"), prefix: []byte("/t/"), wantKey: []byte("/t/a"), wantEnd: []byte("/t/b")},
+		{key: []byte("a"), rangeEnd: []byte{0}, prefix: []byte("/t/"), wantKey: []byte("/t/a"), wantEnd: []byte("/t0")},
+		{key: []byte{}, rangeEnd: []byte{0}, prefix: []byte{0xff}, wantKey: []byte{0xff}, wantEnd: []byte{0}},
+	}
+	for _, tc := range tcs {
+		key, end := PrefixInterval(tc.prefix, tc.key, tc.rangeEnd)
+		assert.Equal(t, tc.wantKey, key)
+		assert.Equal(t, tc.wantEnd, end)
+	}
+}
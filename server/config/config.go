@@ -30,6 +30,7 @@ import (
 	"go.etcd.io/etcd/pkg/v3/netutil"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
 	"go.etcd.io/etcd/server/v3/storage/datadir"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 
 	bolt "go.etcd.io/bbolt"
 )
@@ -203,6 +204,57 @@ type ServerConfig struct {
 
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalMaxObservers sets a limit to the number of observer members that can exist in the cluster membership.
+	ExperimentalMaxObservers int `json:"experimental-max-observers"`
+
+	// ExperimentalAuditLogPath is the file the audit log of mutating and auth
+	// requests is written to. Auditing is disabled if empty.
+	ExperimentalAuditLogPath string `json:"experimental-audit-log-path"`
+	// ExperimentalAuditLogRotationConfigJSON configures the rotation of the audit log.
+	ExperimentalAuditLogRotationConfigJSON string `json:"experimental-audit-log-rotation-config-json"`
+	// ExperimentalAuditLogLevels sets how much of each request type is recorded,
+	// as a comma separated list of <request type>=<none|metadata|request>.
+	ExperimentalAuditLogLevels string `json:"experimental-audit-log-levels"`
+
+	// ExperimentalWALArchiveDir is the directory finished WAL segments are
+	// copied to, for a point-in-time recovery with "etcdutl restore".
+	// Archiving is disabled if empty.
+	ExperimentalWALArchiveDir string `json:"experimental-wal-archive-dir"`
+	// ExperimentalWALCompression compresses the entries saved to the WAL.
+	ExperimentalWALCompression bool `json:"experimental-wal-compression"`
+
+	// ExperimentalBackendEngine is the storage engine of the backend, among
+	// the registered engines. The bbolt engine is used if empty.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
+
+	// ExperimentalEncryptionKeyFile is the key file of the keys encrypting
+	// the data at rest. The data is not encrypted if empty.
+	ExperimentalEncryptionKeyFile string `json:"experimental-encryption-key-file"`
+	// ExperimentalEncryptionKMSSocket is the unix socket of the KMS plugin
+	// decrypting the keys of ExperimentalEncryptionKeyFile. The keys are
+	// stored in plaintext if empty.
+	ExperimentalEncryptionKMSSocket string `json:"experimental-encryption-kms-socket"`
+	// ExperimentalEncryptionAllowPlaintext reads the data at rest that is not
+	// encrypted as is, while the data written before encryption was enabled
+	// is migrated.
+	ExperimentalEncryptionAllowPlaintext bool `json:"experimental-encryption-allow-plaintext"`
+	// EncryptionKeyring encrypts the data at rest with the keys of
+	// ExperimentalEncryptionKeyFile. It is nil if encryption is disabled.
+	EncryptionKeyring *encryption.Keyring
+
+	// ExperimentalScrubInterval is the interval between the scrubs of the
+	// local storage. Scrubbing is disabled if zero.
+	ExperimentalScrubInterval time.Duration `json:"experimental-scrub-interval"`
+	// ExperimentalScrubRateLimitBytes limits the bytes read per second by
+	// the scrubs. Zero means no limit.
+	ExperimentalScrubRateLimitBytes int64 `json:"experimental-scrub-rate-limit-bytes"`
+
+	// ExperimentalLeaseRead lets the leader serve linearizable reads locally
+	// while it holds a leadership lease.
+	ExperimentalLeaseRead bool `json:"experimental-lease-read"`
+	// ExperimentalLeaseReadMaxClockDrift bounds the difference between the
+	// election timeouts measured by the clocks of the members.
+	ExperimentalLeaseReadMaxClockDrift time.Duration `json:"experimental-lease-read-max-clock-drift"`
 
 	// V2Deprecation defines a phase of v2store deprecation process.
 	V2Deprecation V2DeprecationEnum `json:"v2-deprecation"`
@@ -314,6 +366,15 @@ func (c *ServerConfig) WALDir() string {
 
 func (c *ServerConfig) SnapDir() string { return filepath.Join(c.MemberDir(), "snap") }
 
+// Cipher returns the cipher encrypting the data at rest, or nil if
+// encryption is disabled.
+func (c *ServerConfig) Cipher() encryption.Cipher {
+	if c.EncryptionKeyring == nil {
+		return nil
+	}
+	return c.EncryptionKeyring
+}
+
 func (c *ServerConfig) ShouldDiscover() bool {
 	return c.DiscoveryURL != "" || len(c.DiscoveryCfg.Endpoints) > 0
 }
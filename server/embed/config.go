@@ -40,6 +40,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3discovery"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -67,6 +68,8 @@ const (
 	DefaultGRPCKeepAliveTimeout        = 20 * time.Second
 	DefaultDowngradeCheckTime          = 5 * time.Second
 	DefaultWaitClusterReadyTimeout     = 5 * time.Second
+	DefaultScrubRateLimitBytes         = 8 * 1024 * 1024
+	DefaultLeaseReadMaxClockDrift      = 100 * time.Millisecond
 
 	DefaultDiscoveryDialTimeout      = 2 * time.Second
 	DefaultDiscoveryRequestTimeOut   = 5 * time.Second
@@ -348,6 +351,67 @@ type Config struct {
 	ExperimentalWarningUnaryRequestDuration time.Duration `json:"experimental-warning-unary-request-duration"`
 	// ExperimentalMaxLearners sets a limit to the number of learner members that can exist in the cluster membership.
 	ExperimentalMaxLearners int `json:"experimental-max-learners"`
+	// ExperimentalMaxObservers sets a limit to the number of observer members that can exist in the cluster membership.
+	ExperimentalMaxObservers int `json:"experimental-max-observers"`
+
+	// ExperimentalAuditLogPath is the file the audit log of mutating and auth
+	// requests is written to. Auditing is disabled if empty.
+	ExperimentalAuditLogPath string `json:"experimental-audit-log-path"`
+	// ExperimentalAuditLogRotationConfigJSON configures the rotation of the audit log.
+	ExperimentalAuditLogRotationConfigJSON string `json:"experimental-audit-log-rotation-config-json"`
+	// ExperimentalAuditLogLevels sets how much of each request type is recorded,
+	// as a comma separated list of <request type>=<none|metadata|request>.
+	ExperimentalAuditLogLevels string `json:"experimental-audit-log-levels"`
+
+	// ExperimentalWALArchiveDir is the directory finished WAL segments are
+	// copied to, for a point-in-time recovery with "etcdutl restore".
+	// Archiving is disabled if empty.
+	ExperimentalWALArchiveDir string `json:"experimental-wal-archive-dir"`
+	// ExperimentalWALCompression compresses the entries saved to the WAL.
+	// Once a WAL holds compressed entries, it cannot be read by etcd older
+	// than v3.6, so downgrading requires a snapshot first.
+	ExperimentalWALCompression bool `json:"experimental-wal-compression"`
+
+	// ExperimentalBackendEngine is the storage engine of the backend, among
+	// the registered engines. Existing data dirs must keep the engine they
+	// were created with.
+	ExperimentalBackendEngine string `json:"experimental-backend-engine"`
+
+	// ExperimentalEncryptionKeyFile is the key file of the keys encrypting
+	// the values of the backend, the WAL entries and the snapshot files. Each
+	// line of the file is a key, as <id>:<base64 AES key>, the last key being
+	// the one data is encrypted with. The data is not encrypted if empty.
+	ExperimentalEncryptionKeyFile string `json:"experimental-encryption-key-file"`
+	// ExperimentalEncryptionKMSSocket is the unix socket of the KMS plugin
+	// decrypting the keys of the key file, if they are encrypted.
+	ExperimentalEncryptionKMSSocket string `json:"experimental-encryption-kms-socket"`
+	// ExperimentalEncryptionAllowPlaintext reads the data at rest that is not
+	// encrypted as is, to migrate the data written before encryption was
+	// enabled. Otherwise such data is rejected, as it may have been written
+	// by someone without the keys.
+	ExperimentalEncryptionAllowPlaintext bool `json:"experimental-encryption-allow-plaintext"`
+
+	// ExperimentalScrubInterval is the interval between the scrubs of the
+	// local storage, which re-read the finished WAL segments and the backend
+	// to find corrupted data before a restart fails on it. A scrub that
+	// finds corrupted or inconsistent data raises the STORAGE_CORRUPT alarm.
+	// Scrubbing is disabled if zero.
+	ExperimentalScrubInterval time.Duration `json:"experimental-scrub-interval"`
+	// ExperimentalScrubRateLimitBytes limits the bytes read per second by
+	// the scrubs, so that they do not compete with the requests for disk
+	// bandwidth. Zero means no limit.
+	ExperimentalScrubRateLimitBytes int64 `json:"experimental-scrub-rate-limit-bytes"`
+
+	// ExperimentalLeaseRead lets the leader serve linearizable reads locally,
+	// without a ReadIndex round trip, while it holds a leadership lease.
+	// The lease is renewed by the quorum confirming the leadership, and lasts
+	// the election timeout minus ExperimentalLeaseReadMaxClockDrift, during
+	// which no other member can be elected. Reads fall back to ReadIndex
+	// when the lease is expired or a leadership transfer is in progress.
+	ExperimentalLeaseRead bool `json:"experimental-lease-read"`
+	// ExperimentalLeaseReadMaxClockDrift bounds the difference between the
+	// election timeouts measured by the clocks of the members.
+	ExperimentalLeaseReadMaxClockDrift time.Duration `json:"experimental-lease-read-max-clock-drift"`
 
 	// ForceNewCluster starts a new cluster even if previously started; unsafe.
 	ForceNewCluster bool `json:"force-new-cluster"`
@@ -522,10 +586,18 @@ func NewConfig() *Config {
 		ExperimentalMemoryMlock:                  false,
 		ExperimentalTxnModeWriteWithSharedBuffer: true,
 		ExperimentalMaxLearners:                  membership.DefaultMaxLearners,
+		ExperimentalMaxObservers:                 membership.DefaultMaxObservers,
+
+		ExperimentalAuditLogRotationConfigJSON: DefaultLogRotationConfig,
+		ExperimentalBackendEngine:              backend.BboltEngine,
 
 		ExperimentalCompactHashCheckEnabled: false,
 		ExperimentalCompactHashCheckTime:    time.Minute,
 
+		ExperimentalScrubRateLimitBytes: DefaultScrubRateLimitBytes,
+
+		ExperimentalLeaseReadMaxClockDrift: DefaultLeaseReadMaxClockDrift,
+
 		V2Deprecation: config.V2_DEPR_DEFAULT,
 
 		DiscoveryCfg: v3discovery.DiscoveryConfig{
@@ -764,6 +836,30 @@ func (cfg *Config) Validate() error {
 		return fmt.Errorf("--experimental-compact-hash-check-time must be >0 (set to %v)", cfg.ExperimentalCompactHashCheckTime)
 	}
 
+	if !backend.IsEngineRegistered(cfg.ExperimentalBackendEngine) {
+		return fmt.Errorf("unknown --experimental-backend-engine %q (registered engines: %v)", cfg.ExperimentalBackendEngine, backend.Engines())
+	}
+
+	if cfg.ExperimentalEncryptionKMSSocket != "" && cfg.ExperimentalEncryptionKeyFile == "" {
+		return fmt.Errorf("--experimental-encryption-kms-socket requires --experimental-encryption-key-file")
+	}
+	if cfg.ExperimentalEncryptionAllowPlaintext && cfg.ExperimentalEncryptionKeyFile == "" {
+		return fmt.Errorf("--experimental-encryption-allow-plaintext requires --experimental-encryption-key-file")
+	}
+
+	if cfg.ExperimentalScrubInterval < 0 {
+		return fmt.Errorf("--experimental-scrub-interval must be >=0 (set to %v)", cfg.ExperimentalScrubInterval)
+	}
+	if cfg.ExperimentalScrubRateLimitBytes < 0 {
+		return fmt.Errorf("--experimental-scrub-rate-limit-bytes must be >=0 (set to %v)", cfg.ExperimentalScrubRateLimitBytes)
+	}
+	if cfg.ExperimentalLeaseReadMaxClockDrift < 0 {
+		return fmt.Errorf("--experimental-lease-read-max-clock-drift must be >=0 (set to %v)", cfg.ExperimentalLeaseReadMaxClockDrift)
+	}
+	if cfg.ExperimentalLeaseRead && cfg.ExperimentalLeaseReadMaxClockDrift >= time.Duration(cfg.ElectionMs)*time.Millisecond {
+		return fmt.Errorf("--experimental-lease-read-max-clock-drift[%v] must be less than --election-timeout[%vms]", cfg.ExperimentalLeaseReadMaxClockDrift, cfg.ElectionMs)
+	}
+
 	// If `--name` isn't configured, then multiple members may have the same "default" name.
 	// When adding a new member with the "default" name as well, etcd may regards its peerURL
 	// as one additional peerURL of the existing member which has the same "default" name,
@@ -39,6 +39,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api/etcdhttp"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/rafthttp"
 	"go.etcd.io/etcd/server/v3/storage"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/verify"
 
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
@@ -222,9 +223,37 @@ func StartEtcd(inCfg *Config) (e *Etcd, err error) {
 		ExperimentalTxnModeWriteWithSharedBuffer: cfg.ExperimentalTxnModeWriteWithSharedBuffer,
 		ExperimentalBootstrapDefragThresholdMegabytes: cfg.ExperimentalBootstrapDefragThresholdMegabytes,
 		ExperimentalMaxLearners:                       cfg.ExperimentalMaxLearners,
+		ExperimentalMaxObservers:                      cfg.ExperimentalMaxObservers,
+		ExperimentalAuditLogPath:                      cfg.ExperimentalAuditLogPath,
+		ExperimentalAuditLogRotationConfigJSON:        cfg.ExperimentalAuditLogRotationConfigJSON,
+		ExperimentalAuditLogLevels:                    cfg.ExperimentalAuditLogLevels,
+		ExperimentalWALArchiveDir:                     cfg.ExperimentalWALArchiveDir,
+		ExperimentalWALCompression:                    cfg.ExperimentalWALCompression,
+		ExperimentalBackendEngine:                     cfg.ExperimentalBackendEngine,
+		ExperimentalEncryptionKeyFile:                 cfg.ExperimentalEncryptionKeyFile,
+		ExperimentalEncryptionKMSSocket:               cfg.ExperimentalEncryptionKMSSocket,
+		ExperimentalEncryptionAllowPlaintext:          cfg.ExperimentalEncryptionAllowPlaintext,
+		ExperimentalScrubInterval:                     cfg.ExperimentalScrubInterval,
+		ExperimentalScrubRateLimitBytes:               cfg.ExperimentalScrubRateLimitBytes,
+		ExperimentalLeaseRead:                         cfg.ExperimentalLeaseRead,
+		ExperimentalLeaseReadMaxClockDrift:            cfg.ExperimentalLeaseReadMaxClockDrift,
 		V2Deprecation:                                 cfg.V2DeprecationEffective(),
 	}
 
+	if srvcfg.ExperimentalEncryptionKeyFile != "" {
+		srvcfg.EncryptionKeyring, err = encryption.NewFileKeyring(srvcfg.ExperimentalEncryptionKeyFile, srvcfg.ExperimentalEncryptionKMSSocket)
+		if err != nil {
+			return e, fmt.Errorf("cannot load the encryption keys: %w", err)
+		}
+		srvcfg.EncryptionKeyring.SetAllowPlaintext(srvcfg.ExperimentalEncryptionAllowPlaintext)
+		e.cfg.logger.Info(
+			"encryption at rest enabled",
+			zap.String("key-file", srvcfg.ExperimentalEncryptionKeyFile),
+			zap.Uint32("primary-key-id", srvcfg.EncryptionKeyring.PrimaryKeyID()),
+			zap.Bool("allow-plaintext", srvcfg.ExperimentalEncryptionAllowPlaintext),
+		)
+	}
+
 	if srvcfg.ExperimentalEnableDistributedTracing {
 		tctx := context.Background()
 		tracingExporter, err := newTracingExporter(tctx, cfg)
@@ -370,6 +399,7 @@ func print(lg *zap.Logger, ec Config, sc config.ServerConfig, memberInitialized
 
 		zap.String("downgrade-check-interval", sc.DowngradeCheckTime.String()),
 		zap.Int("max-learners", sc.ExperimentalMaxLearners),
+		zap.Int("max-observers", sc.ExperimentalMaxObservers),
 	)
 }
 
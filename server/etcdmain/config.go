@@ -278,6 +278,20 @@ func newConfig() *config {
 	fs.BoolVar(&cfg.ec.ExperimentalTxnModeWriteWithSharedBuffer, "experimental-txn-mode-write-with-shared-buffer", true, "Enable the write transaction to use a shared buffer in its readonly check operations.")
 	fs.UintVar(&cfg.ec.ExperimentalBootstrapDefragThresholdMegabytes, "experimental-bootstrap-defrag-threshold-megabytes", 0, "Enable the defrag during etcd server bootstrap on condition that it will free at least the provided threshold of disk space. Needs to be set to non-zero value to take effect.")
 	fs.IntVar(&cfg.ec.ExperimentalMaxLearners, "experimental-max-learners", membership.DefaultMaxLearners, "Sets the maximum number of learners that can be available in the cluster membership.")
+	fs.IntVar(&cfg.ec.ExperimentalMaxObservers, "experimental-max-observers", membership.DefaultMaxObservers, "Sets the maximum number of observers that can be available in the cluster membership.")
+	fs.StringVar(&cfg.ec.ExperimentalAuditLogPath, "experimental-audit-log-path", "", "Path of the audit log of mutating and auth requests. Auditing is disabled if empty.")
+	fs.StringVar(&cfg.ec.ExperimentalAuditLogRotationConfigJSON, "experimental-audit-log-rotation-config-json", embed.DefaultLogRotationConfig, "Configures rotation of the audit log with a JSON logger config, in the format of --log-rotation-config-json.")
+	fs.StringVar(&cfg.ec.ExperimentalAuditLogLevels, "experimental-audit-log-levels", "", "Comma separated list of <request type>=<none|metadata|request> setting how much of each request type is recorded in the audit log. '*' sets all request types audited by default.")
+	fs.StringVar(&cfg.ec.ExperimentalWALArchiveDir, "experimental-wal-archive-dir", "", "Path of the directory finished WAL segments are copied to, for point-in-time recovery with 'etcdutl restore'. Archiving is disabled if empty.")
+	fs.BoolVar(&cfg.ec.ExperimentalWALCompression, "experimental-wal-compression", false, "Compress the entries saved to the WAL. etcd older than v3.6 cannot read compressed WAL entries.")
+	fs.StringVar(&cfg.ec.ExperimentalBackendEngine, "experimental-backend-engine", cfg.ec.ExperimentalBackendEngine, "Storage engine of the backend. Existing data dirs must keep the engine they were created with.")
+	fs.StringVar(&cfg.ec.ExperimentalEncryptionKeyFile, "experimental-encryption-key-file", "", "Path of the key file of the keys encrypting the data at rest, one <id>:<base64 AES key> per line, the last one encrypting new data. Data is not encrypted if empty.")
+	fs.StringVar(&cfg.ec.ExperimentalEncryptionKMSSocket, "experimental-encryption-kms-socket", "", "Path of the unix socket of the KMS plugin decrypting the keys of the encryption key file. The keys are stored in plaintext if empty.")
+	fs.BoolVar(&cfg.ec.ExperimentalEncryptionAllowPlaintext, "experimental-encryption-allow-plaintext", false, "Read the data at rest that is not encrypted as is, to migrate the data written before encryption was enabled. Such data is rejected if false.")
+	fs.DurationVar(&cfg.ec.ExperimentalScrubInterval, "experimental-scrub-interval", 0, "Interval between the scrubs of the local WAL and backend, raising the STORAGE_CORRUPT alarm on corrupted or inconsistent data. Scrubbing is disabled if 0.")
+	fs.Int64Var(&cfg.ec.ExperimentalScrubRateLimitBytes, "experimental-scrub-rate-limit-bytes", cfg.ec.ExperimentalScrubRateLimitBytes, "Maximum number of bytes read per second by the scrubs. No limit if 0.")
+	fs.BoolVar(&cfg.ec.ExperimentalLeaseRead, "experimental-lease-read", false, "Enable the leader to serve linearizable reads locally while it holds a leadership lease, instead of a ReadIndex round trip.")
+	fs.DurationVar(&cfg.ec.ExperimentalLeaseReadMaxClockDrift, "experimental-lease-read-max-clock-drift", cfg.ec.ExperimentalLeaseReadMaxClockDrift, "Maximum difference between the election timeouts measured by the clocks of the members. The leadership lease lasts the election timeout minus this bound.")
 	fs.DurationVar(&cfg.ec.ExperimentalWaitClusterReadyTimeout, "experimental-wait-cluster-ready-timeout", cfg.ec.ExperimentalWaitClusterReadyTimeout, "Maximum duration to wait for the cluster to be ready.")
 	fs.Uint64Var(&cfg.ec.SnapshotCatchUpEntries, "experimental-snapshot-catchup-entries", cfg.ec.SnapshotCatchUpEntries, "Number of entries for a slow follower to catch up after compacting the the raft storage entries.")
 
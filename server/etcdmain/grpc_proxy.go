@@ -97,6 +97,9 @@ var (
 	grpcProxyEnableOrdering bool
 	grpcProxyEnableLogging  bool
 
+	grpcProxyCoherentCache         bool
+	grpcProxyCoherentCachePrefixes []string
+
 	grpcProxyDebug bool
 
 	// GRPC keep alive related options.
@@ -169,6 +172,8 @@ func newGRPCProxyStartCommand() *cobra.Command {
 	cmd.Flags().BoolVar(&grpcProxyEnableOrdering, "experimental-serializable-ordering", false, "Ensure serializable reads have monotonically increasing store revisions across endpoints.")
 	cmd.Flags().StringVar(&grpcProxyLeasing, "experimental-leasing-prefix", "", "leasing metadata prefix for disconnected linearized reads.")
 	cmd.Flags().BoolVar(&grpcProxyEnableLogging, "experimental-enable-grpc-logging", false, "logging all grpc requests and responses")
+	cmd.Flags().BoolVar(&grpcProxyCoherentCache, "experimental-coherent-cache", false, "Keep the range cache coherent with the cluster with a watch on the whole key space, including the writes not passing through the proxy.")
+	cmd.Flags().StringArrayVar(&grpcProxyCoherentCachePrefixes, "experimental-coherent-cache-prefix", nil, "Key prefix to keep in a materialized view of the coherent cache, which also serves linearizable reads after a revision check (repeatable). Requires --experimental-coherent-cache.")
 
 	cmd.Flags().BoolVar(&grpcProxyDebug, "debug", false, "Enable debug-level logging for grpc-proxy.")
 
@@ -289,6 +294,14 @@ func checkArgs() {
 		fmt.Fprintln(os.Stderr, fmt.Errorf("selfSignedCertValidity is invalid,it should be greater than 0"))
 		os.Exit(1)
 	}
+	if len(grpcProxyCoherentCachePrefixes) > 0 && !grpcProxyCoherentCache {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("experimental-coherent-cache-prefix requires experimental-coherent-cache"))
+		os.Exit(1)
+	}
+	if grpcProxyCoherentCache && grpcProxyLeasing != "" {
+		fmt.Fprintln(os.Stderr, fmt.Errorf("experimental-coherent-cache cannot be combined with experimental-leasing-prefix"))
+		os.Exit(1)
+	}
 }
 
 func mustNewClient(lg *zap.Logger) *clientv3.Client {
@@ -435,7 +448,12 @@ func newGRPCProxyServer(lg *zap.Logger, client *clientv3.Client) *grpc.Server {
 		client.KV, _, _ = leasing.NewKV(client, grpcProxyLeasing)
 	}
 
-	kvp, _ := grpcproxy.NewKvProxy(client)
+	var kvp pb.KVServer
+	if grpcProxyCoherentCache {
+		kvp, _ = grpcproxy.NewCoherentKvProxy(lg, client, grpcProxyCoherentCachePrefixes)
+	} else {
+		kvp, _ = grpcproxy.NewKvProxy(client)
+	}
 	watchp, _ := grpcproxy.NewWatchProxy(client.Ctx(), lg, client)
 	if grpcProxyResolverPrefix != "" {
 		grpcproxy.Register(lg, client, grpcProxyResolverPrefix, grpcProxyAdvertiseClientURL, grpcProxyResolverTTL)
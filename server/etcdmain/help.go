@@ -267,6 +267,34 @@ Experimental feature:
     Set time duration after which a warning is generated if a unary request takes more than this duration. It's deprecated, and will be decommissioned in v3.7. Use --warning-unary-request-duration instead.
   --experimental-max-learners '1'
     Set the max number of learner members allowed in the cluster membership.
+  --experimental-max-observers '16'
+    Set the max number of observer members allowed in the cluster membership. Observers do not count as learners.
+  --experimental-audit-log-path ''
+    Path of the audit log of mutating and auth requests. Auditing is disabled if empty.
+  --experimental-audit-log-rotation-config-json '{"maxsize": 100, "maxage": 0, "maxbackups": 0, "localtime": false, "compress": false}'
+    Configures rotation of the audit log with a JSON logger config.
+  --experimental-audit-log-levels ''
+    Comma separated list of <request type>=<none|metadata|request> setting how much of each request type is recorded in the audit log. '*' sets all request types audited by default, which are mutating and auth requests, at 'metadata' level.
+  --experimental-wal-archive-dir ''
+    Path of the directory finished WAL segments are copied to, for point-in-time recovery with 'etcdutl restore'. Archiving is disabled if empty.
+  --experimental-wal-compression 'false'
+    Compress the entries saved to the WAL. etcd older than v3.6 cannot read compressed WAL entries.
+  --experimental-backend-engine 'bbolt'
+    Storage engine of the backend. Existing data dirs must keep the engine they were created with.
+  --experimental-encryption-key-file ''
+    Path of the key file of the keys encrypting the data at rest, one <id>:<base64 AES key> per line, the last one encrypting new data. Data is not encrypted if empty.
+  --experimental-encryption-kms-socket ''
+    Path of the unix socket of the KMS plugin decrypting the keys of the encryption key file. The keys are stored in plaintext if empty.
+  --experimental-encryption-allow-plaintext 'false'
+    Read the data at rest that is not encrypted as is, to migrate the data written before encryption was enabled. Such data is rejected if false.
+  --experimental-scrub-interval '0s'
+    Interval between the scrubs of the local WAL and backend, raising the STORAGE_CORRUPT alarm on corrupted or inconsistent data. Scrubbing is disabled if 0.
+  --experimental-scrub-rate-limit-bytes '8388608'
+    Maximum number of bytes read per second by the scrubs. No limit if 0.
+  --experimental-lease-read 'false'
+    Enable the leader to serve linearizable reads locally while it holds a leadership lease, instead of a ReadIndex round trip.
+  --experimental-lease-read-max-clock-drift '100ms'
+    Maximum difference between the election timeouts measured by the clocks of the members. The leadership lease lasts the election timeout minus this bound.
   --experimental-wait-cluster-ready-timeout '5s'
     Set the maximum time duration to wait for the cluster to be ready.
   --experimental-snapshot-catch-up-entries '5000'
@@ -158,6 +158,8 @@ func checkAlarms(lg *zap.Logger, srv ServerHealth, excludedAlarms AlarmSet) Heal
 				h.Reason = "ALARM NOSPACE"
 			case etcdserverpb.AlarmType_CORRUPT:
 				h.Reason = "ALARM CORRUPT"
+			case etcdserverpb.AlarmType_STORAGE_CORRUPT:
+				h.Reason = "ALARM STORAGE_CORRUPT"
 			default:
 				h.Reason = "ALARM UNKNOWN"
 			}
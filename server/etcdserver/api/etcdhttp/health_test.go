@@ -129,6 +129,13 @@ func TestHealthHandler(t *testing.T) {
 			expectStatusCode: http.StatusOK,
 			expectHealth:     "true",
 		},
+		{
+			name:             "Unhealthy if STORAGE_CORRUPT is on",
+			alarms:           []*pb.AlarmMember{{MemberID: uint64(0), Alarm: pb.AlarmType_STORAGE_CORRUPT}},
+			healthCheckURL:   "/health",
+			expectStatusCode: http.StatusServiceUnavailable,
+			expectHealth:     "false",
+		},
 		{
 			name:             "Healthy even if authentication failed",
 			healthCheckURL:   "/health",
@@ -39,7 +39,7 @@ const (
 
 // NewPeerHandler generates an http.Handler to handle etcd peer requests.
 func NewPeerHandler(lg *zap.Logger, s etcdserver.ServerPeerV2) http.Handler {
-	return newPeerHandler(lg, s, s.RaftHandler(), s.LeaseHandler(), s.HashKVHandler(), s.DowngradeEnabledHandler())
+	return newPeerHandler(lg, s, s.RaftHandler(), s.LeaseHandler(), s.HashKVHandler(), s.DowngradeEnabledHandler(), s.SnapshotRelayHandler())
 }
 
 func newPeerHandler(
@@ -49,6 +49,7 @@ func newPeerHandler(
 	leaseHandler http.Handler,
 	hashKVHandler http.Handler,
 	downgradeEnabledHandler http.Handler,
+	snapshotRelayHandler http.Handler,
 ) http.Handler {
 	if lg == nil {
 		lg = zap.NewNop()
@@ -72,6 +73,9 @@ func newPeerHandler(
 	if hashKVHandler != nil {
 		mux.Handle(etcdserver.PeerHashKVPath, hashKVHandler)
 	}
+	if snapshotRelayHandler != nil {
+		mux.Handle(etcdserver.PeerSnapshotRelayPath, snapshotRelayHandler)
+	}
 	mux.HandleFunc(versionPath, versionHandler(s, serveVersion))
 	return mux
 }
@@ -141,7 +145,7 @@ func (h *peerMemberPromoteHandler) ServeHTTP(w http.ResponseWriter, r *http.Requ
 		switch err {
 		case membership.ErrIDNotFound:
 			http.Error(w, err.Error(), http.StatusNotFound)
-		case membership.ErrMemberNotLearner:
+		case membership.ErrMemberNotLearner, membership.ErrObserverNotPromotable:
 			http.Error(w, err.Error(), http.StatusPreconditionFailed)
 		case errors.ErrLearnerNotReady:
 			http.Error(w, err.Error(), http.StatusPreconditionFailed)
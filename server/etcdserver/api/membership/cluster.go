@@ -59,6 +59,7 @@ type RaftCluster struct {
 
 	downgradeInfo  *serverversion.DowngradeInfo
 	maxLearners    int
+	maxObservers   int
 	versionChanged *notify.Notifier
 }
 
@@ -117,6 +118,7 @@ func NewCluster(lg *zap.Logger, opts ...ClusterOption) *RaftCluster {
 		removed:       make(map[types.ID]bool),
 		downgradeInfo: &serverversion.DowngradeInfo{Enabled: false},
 		maxLearners:   clOpts.maxLearners,
+		maxObservers:  clOpts.maxObservers,
 	}
 }
 
@@ -325,6 +327,9 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 			if !membersMap[id].IsLearner {
 				return ErrMemberNotLearner
 			}
+			if membersMap[id].IsObserver {
+				return ErrObserverNotPromotable
+			}
 		} else { // adding a new member
 			if membersMap[id] != nil {
 				return ErrIDExists
@@ -344,7 +349,21 @@ func (c *RaftCluster) ValidateConfigurationChange(cc raftpb.ConfChange) error {
 				}
 			}
 
-			if confChangeContext.Member.RaftAttributes.IsLearner && cc.Type == raftpb.ConfChangeAddLearnerNode { // the new member is a learner
+			if confChangeContext.Member.RaftAttributes.IsObserver {
+				// an observer is a learner for raft
+				if cc.Type != raftpb.ConfChangeAddLearnerNode || !confChangeContext.Member.IsLearner {
+					return ErrObserverNotLearner
+				}
+				scaleUpObservers := true
+				if err := ValidateMaxObserverConfig(c.maxObservers, members, scaleUpObservers); err != nil {
+					return err
+				}
+				if src := confChangeContext.Member.SnapshotSource; src != 0 {
+					if m := membersMap[src]; m == nil || m.IsLearner {
+						return ErrInvalidSnapshotSource
+					}
+				}
+			} else if confChangeContext.Member.RaftAttributes.IsLearner && cc.Type == raftpb.ConfChangeAddLearnerNode { // the new member is a learner
 				scaleUpLearners := true
 				if err := ValidateMaxLearnerConfig(c.maxLearners, members, scaleUpLearners); err != nil {
 					return err
@@ -505,12 +524,15 @@ func (c *RaftCluster) UpdateRaftAttributes(id types.ID, raftAttr RaftAttributes,
 	c.Lock()
 	defer c.Unlock()
 
-	c.members[id].RaftAttributes = raftAttr
+	// an update only changes the peer URLs; whether the member is a learner
+	// or an observer, and its snapshot source, are kept
+	m := c.members[id]
+	m.PeerURLs = raftAttr.PeerURLs
 	if c.v2store != nil {
-		mustUpdateMemberInStore(c.lg, c.v2store, c.members[id])
+		mustUpdateMemberInStore(c.lg, c.v2store, m)
 	}
 	if c.be != nil && shouldApplyV3 {
-		c.be.MustSaveMemberToBackend(c.members[id])
+		c.be.MustSaveMemberToBackend(m)
 	}
 
 	c.lg.Info(
@@ -518,8 +540,8 @@ func (c *RaftCluster) UpdateRaftAttributes(id types.ID, raftAttr RaftAttributes,
 		zap.String("cluster-id", c.cid.String()),
 		zap.String("local-member-id", c.localID.String()),
 		zap.String("updated-remote-peer-id", id.String()),
-		zap.Strings("updated-remote-peer-urls", raftAttr.PeerURLs),
-		zap.Bool("updated-remote-peer-is-learner", raftAttr.IsLearner),
+		zap.Strings("updated-remote-peer-urls", m.PeerURLs),
+		zap.Bool("updated-remote-peer-is-learner", m.IsLearner),
 	)
 }
 
@@ -745,6 +767,21 @@ func (c *RaftCluster) IsLocalMemberLearner() bool {
 	return localMember.IsLearner
 }
 
+// IsLocalMemberObserver returns if the local member is an observer
+func (c *RaftCluster) IsLocalMemberObserver() bool {
+	c.Lock()
+	defer c.Unlock()
+	localMember, ok := c.members[c.localID]
+	if !ok {
+		c.lg.Panic(
+			"failed to find local ID in cluster members",
+			zap.String("cluster-id", c.cid.String()),
+			zap.String("local-member-id", c.localID.String()),
+		)
+	}
+	return localMember.IsObserver
+}
+
 // DowngradeInfo returns the downgrade status of the cluster
 func (c *RaftCluster) DowngradeInfo() *serverversion.DowngradeInfo {
 	c.Lock()
@@ -836,11 +873,11 @@ func (c *RaftCluster) updateMembershipMetric(peer types.ID, known bool) {
 }
 
 // ValidateMaxLearnerConfig verifies the existing learner members in the cluster membership and an optional N+1 learner
-// scale up are not more than maxLearners.
+// scale up are not more than maxLearners. Observers do not count as learners.
 func ValidateMaxLearnerConfig(maxLearners int, members []*Member, scaleUpLearners bool) error {
 	numLearners := 0
 	for _, m := range members {
-		if m.IsLearner {
+		if m.IsLearner && !m.IsObserver {
 			numLearners++
 		}
 	}
@@ -855,3 +892,23 @@ func ValidateMaxLearnerConfig(maxLearners int, members []*Member, scaleUpLearner
 
 	return nil
 }
+
+// ValidateMaxObserverConfig verifies the existing observer members in the cluster membership and an optional N+1 observer
+// scale up are not more than maxObservers.
+func ValidateMaxObserverConfig(maxObservers int, members []*Member, scaleUpObservers bool) error {
+	numObservers := 0
+	for _, m := range members {
+		if m.IsObserver {
+			numObservers++
+		}
+	}
+	if scaleUpObservers {
+		numObservers++
+	}
+
+	if numObservers > maxObservers {
+		return ErrTooManyObservers
+	}
+
+	return nil
+}
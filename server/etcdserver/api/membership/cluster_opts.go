@@ -14,10 +14,14 @@
 
 package membership
 
-const DefaultMaxLearners = 1
+const (
+	DefaultMaxLearners  = 1
+	DefaultMaxObservers = 16
+)
 
 type ClusterOptions struct {
-	maxLearners int
+	maxLearners  int
+	maxObservers int
 }
 
 // ClusterOption are options which can be applied to the raft cluster.
@@ -41,3 +45,10 @@ func WithMaxLearners(max int) ClusterOption {
 		co.maxLearners = max
 	}
 }
+
+// WithMaxObservers sets the maximum number of observers that can exist in the cluster membership.
+func WithMaxObservers(max int) ClusterOption {
+	return func(co *ClusterOptions) {
+		co.maxObservers = max
+	}
+}
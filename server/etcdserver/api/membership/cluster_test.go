@@ -461,6 +461,76 @@ func TestClusterValidateConfigurationChange(t *testing.T) {
 	}
 }
 
+func TestClusterValidateConfigurationChangeObserver(t *testing.T) {
+	cl := NewCluster(zaptest.NewLogger(t), WithMaxLearners(1), WithMaxObservers(2))
+	cl.SetStore(v2store.New())
+	for i := 1; i <= 3; i++ {
+		attr := RaftAttributes{PeerURLs: []string{fmt.Sprintf("http://127.0.0.1:%d", i)}, IsLearner: i == 3, IsObserver: i == 3}
+		cl.AddMember(&Member{ID: types.ID(i), RaftAttributes: attr}, true)
+	}
+
+	newContext := func(id uint64, attr RaftAttributes, isPromote bool) []byte {
+		attr.PeerURLs = []string{fmt.Sprintf("http://127.0.0.1:%d", id)}
+		ctx, err := json.Marshal(&ConfigChangeContext{Member: Member{ID: types.ID(id), RaftAttributes: attr}, IsPromote: isPromote})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ctx
+	}
+	tests := []struct {
+		name string
+		cc   raftpb.ConfChange
+		werr error
+	}{
+		{
+			name: "observer cannot be promoted",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 3, Context: newContext(3, RaftAttributes{}, true)},
+			werr: ErrObserverNotPromotable,
+		},
+		{
+			name: "observers do not count as learners",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 4, Context: newContext(4, RaftAttributes{IsLearner: true}, false)},
+		},
+		{
+			name: "observer with a voting snapshot source",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 5, Context: newContext(5, RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 2}, false)},
+		},
+		{
+			name: "observer with an observer snapshot source",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 5, Context: newContext(5, RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 3}, false)},
+			werr: ErrInvalidSnapshotSource,
+		},
+		{
+			name: "observer added as a voter",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddNode, NodeID: 5, Context: newContext(5, RaftAttributes{IsLearner: true, IsObserver: true}, false)},
+			werr: ErrObserverNotLearner,
+		},
+		{
+			name: "observer not marked as a learner",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 5, Context: newContext(5, RaftAttributes{IsObserver: true}, false)},
+			werr: ErrObserverNotLearner,
+		},
+		{
+			name: "observer with a missing snapshot source",
+			cc:   raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 5, Context: newContext(5, RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 9}, false)},
+			werr: ErrInvalidSnapshotSource,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := cl.ValidateConfigurationChange(tt.cc); err != tt.werr {
+				t.Errorf("validateConfigurationChange error = %v, want %v", err, tt.werr)
+			}
+		})
+	}
+
+	cl.AddMember(&Member{ID: 5, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:5"}, IsLearner: true, IsObserver: true}}, true)
+	cc := raftpb.ConfChange{Type: raftpb.ConfChangeAddLearnerNode, NodeID: 6, Context: newContext(6, RaftAttributes{IsLearner: true, IsObserver: true}, false)}
+	if err := cl.ValidateConfigurationChange(cc); err != ErrTooManyObservers {
+		t.Errorf("validateConfigurationChange error = %v, want %v", err, ErrTooManyObservers)
+	}
+}
+
 func TestClusterGenID(t *testing.T) {
 	cs := newTestCluster(t, []*Member{
 		newTestMember(1, nil, "", nil),
@@ -630,6 +700,24 @@ func TestClusterUpdateAttributes(t *testing.T) {
 	}
 }
 
+// TestClusterUpdateRaftAttributesObserver ensures updating the peer URLs of
+// an observer keeps it an observer with its snapshot source.
+func TestClusterUpdateRaftAttributesObserver(t *testing.T) {
+	c := newTestCluster(t, []*Member{
+		newTestMember(1, []string{"http://127.0.0.1:1"}, "", nil),
+		{ID: 2, RaftAttributes: RaftAttributes{PeerURLs: []string{"http://127.0.0.1:2"}, IsLearner: true, IsObserver: true, SnapshotSource: 1}},
+	})
+
+	c.UpdateRaftAttributes(2, RaftAttributes{PeerURLs: []string{"http://127.0.0.1:3"}}, true)
+	want := RaftAttributes{PeerURLs: []string{"http://127.0.0.1:3"}, IsLearner: true, IsObserver: true, SnapshotSource: 1}
+	if g := c.Member(2).RaftAttributes; !reflect.DeepEqual(g, want) {
+		t.Errorf("raft attributes = %+v, want %+v", g, want)
+	}
+	if g, w := c.VotingMemberIDs(), []types.ID{1}; !reflect.DeepEqual(g, w) {
+		t.Errorf("voting members = %v, want %v", g, w)
+	}
+}
+
 func TestNodeToMember(t *testing.T) {
 	n := &v2store.NodeExtern{Key: "/1234", Nodes: []*v2store.NodeExtern{
 		{Key: "/1234/attributes", Value: stringp(`{"name":"node1","clientURLs":null}`)},
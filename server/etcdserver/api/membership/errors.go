@@ -21,12 +21,16 @@ import (
 )
 
 var (
-	ErrIDRemoved        = errors.New("membership: ID removed")
-	ErrIDExists         = errors.New("membership: ID exists")
-	ErrIDNotFound       = errors.New("membership: ID not found")
-	ErrPeerURLexists    = errors.New("membership: peerURL exists")
-	ErrMemberNotLearner = errors.New("membership: can only promote a learner member")
-	ErrTooManyLearners  = errors.New("membership: too many learner members in cluster")
+	ErrIDRemoved             = errors.New("membership: ID removed")
+	ErrIDExists              = errors.New("membership: ID exists")
+	ErrIDNotFound            = errors.New("membership: ID not found")
+	ErrPeerURLexists         = errors.New("membership: peerURL exists")
+	ErrMemberNotLearner      = errors.New("membership: can only promote a learner member")
+	ErrTooManyLearners       = errors.New("membership: too many learner members in cluster")
+	ErrObserverNotPromotable = errors.New("membership: cannot promote an observer member")
+	ErrTooManyObservers      = errors.New("membership: too many observer members in cluster")
+	ErrObserverNotLearner    = errors.New("membership: observer member must be added as a learner")
+	ErrInvalidSnapshotSource = errors.New("membership: snapshot source must be a voting member")
 )
 
 func isKeyNotFound(err error) bool {
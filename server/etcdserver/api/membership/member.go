@@ -32,6 +32,12 @@ type RaftAttributes struct {
 	PeerURLs []string `json:"peerURLs"`
 	// IsLearner indicates if the member is raft learner.
 	IsLearner bool `json:"isLearner,omitempty"`
+	// IsObserver indicates if the member is a permanent raft learner, which
+	// serves reads and watches but can never be promoted.
+	IsObserver bool `json:"isObserver,omitempty"`
+	// SnapshotSource is the ID of the voting member sending snapshots to the
+	// observer instead of the leader. Zero means the leader.
+	SnapshotSource types.ID `json:"snapshotSource,omitempty"`
 }
 
 // Attributes represents all the non-raft related attributes of an etcd member.
@@ -60,6 +66,15 @@ func NewMemberAsLearner(name string, peerURLs types.URLs, clusterName string, no
 	return newMember(name, peerURLs, memberId, true)
 }
 
+// NewMemberAsObserver creates an observer Member without an ID and generates one based on the
+// cluster name, peer URLs, and time. This is used for adding new observer member.
+func NewMemberAsObserver(name string, peerURLs types.URLs, clusterName string, now *time.Time, snapshotSource types.ID) *Member {
+	m := NewMemberAsLearner(name, peerURLs, clusterName, now)
+	m.IsObserver = true
+	m.SnapshotSource = snapshotSource
+	return m
+}
+
 func computeMemberId(peerURLs types.URLs, clusterName string, now *time.Time) types.ID {
 	peerURLstrs := peerURLs.StringSlice()
 	sort.Strings(peerURLstrs)
@@ -94,7 +109,9 @@ func (m *Member) Clone() *Member {
 	mm := &Member{
 		ID: m.ID,
 		RaftAttributes: RaftAttributes{
-			IsLearner: m.IsLearner,
+			IsLearner:      m.IsLearner,
+			IsObserver:     m.IsObserver,
+			SnapshotSource: m.SnapshotSource,
 		},
 		Attributes: Attributes{
 			Name: m.Name,
@@ -61,6 +61,7 @@ func TestMemberClone(t *testing.T) {
 		newTestMember(1, []string{"http://a"}, "abc", nil),
 		newTestMember(1, nil, "abc", []string{"http://b"}),
 		newTestMember(1, []string{"http://a"}, "abc", []string{"http://b"}),
+		NewMemberAsObserver("abc", types.MustNewURLs([]string{"http://a:2380"}), "", nil, 2),
 	}
 	for i, tt := range tests {
 		nm := tt.Clone()
@@ -29,6 +29,7 @@ import (
 	pioutil "go.etcd.io/etcd/pkg/v3/ioutil"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/snap/snappb"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.etcd.io/raft/v3"
 	"go.etcd.io/raft/v3/raftpb"
@@ -51,8 +52,9 @@ var (
 )
 
 type Snapshotter struct {
-	lg  *zap.Logger
-	dir string
+	lg     *zap.Logger
+	dir    string
+	cipher encryption.Cipher
 }
 
 func New(lg *zap.Logger, dir string) *Snapshotter {
@@ -65,6 +67,13 @@ func New(lg *zap.Logger, dir string) *Snapshotter {
 	}
 }
 
+// SetCipher encrypts the snapshots saved with c, and decrypts the snapshots
+// loaded with it. The snapshots saved before are loaded as is, if they were
+// not encrypted.
+func (s *Snapshotter) SetCipher(c encryption.Cipher) {
+	s.cipher = c
+}
+
 func (s *Snapshotter) SaveSnap(snapshot raftpb.Snapshot) error {
 	if raft.IsEmptySnap(snapshot) {
 		return nil
@@ -76,7 +85,10 @@ func (s *Snapshotter) save(snapshot *raftpb.Snapshot) error {
 	start := time.Now()
 
 	fname := fmt.Sprintf("%016x-%016x%s", snapshot.Metadata.Term, snapshot.Metadata.Index, snapSuffix)
-	b := pbutil.MustMarshal(snapshot)
+	b, err := encryption.Encrypt(s.cipher, pbutil.MustMarshal(snapshot))
+	if err != nil {
+		return err
+	}
 	crc := crc32.Update(0, crcTable, b)
 	snap := snappb.Snapshot{Crc: crc, Data: b}
 	d, err := snap.Marshal()
@@ -133,14 +145,17 @@ func (s *Snapshotter) loadMatching(matchFn func(*raftpb.Snapshot) bool) (*raftpb
 		if snap, err = s.loadSnap(name); err == nil && matchFn(snap) {
 			return snap, nil
 		}
+		if isKeyError(err) {
+			return nil, err
+		}
 	}
 	return nil, ErrNoSnapshot
 }
 
 func (s *Snapshotter) loadSnap(name string) (*raftpb.Snapshot, error) {
 	fpath := filepath.Join(s.dir, name)
-	snap, err := Read(s.lg, fpath)
-	if err != nil {
+	snap, err := read(s.lg, fpath, s.cipher)
+	if err != nil && !isKeyError(err) {
 		brokenPath := fpath + ".broken"
 		s.lg.Warn("failed to read a snap file", zap.String("path", fpath), zap.Error(err))
 		if rerr := os.Rename(fpath, brokenPath); rerr != nil {
@@ -152,8 +167,18 @@ func (s *Snapshotter) loadSnap(name string) (*raftpb.Snapshot, error) {
 	return snap, err
 }
 
+// isKeyError returns true if err is due to the encryption keys rather than
+// to the snap file, which is then not broken.
+func isKeyError(err error) bool {
+	return errors.Is(err, encryption.ErrKeyRequired) || errors.Is(err, encryption.ErrUnknownKey)
+}
+
 // Read reads the snapshot named by snapname and returns the snapshot.
 func Read(lg *zap.Logger, snapname string) (*raftpb.Snapshot, error) {
+	return read(lg, snapname, nil)
+}
+
+func read(lg *zap.Logger, snapname string, c encryption.Cipher) (*raftpb.Snapshot, error) {
 	verify.Assert(lg != nil, "the logger should not be nil")
 	b, err := os.ReadFile(snapname)
 	if err != nil {
@@ -187,8 +212,14 @@ func Read(lg *zap.Logger, snapname string) (*raftpb.Snapshot, error) {
 		return nil, ErrCRCMismatch
 	}
 
+	data, err := encryption.Decrypt(c, serializedSnap.Data)
+	if err != nil {
+		lg.Warn("failed to decrypt snap file", zap.String("path", snapname), zap.Error(err))
+		return nil, err
+	}
+
 	var snap raftpb.Snapshot
-	if err = snap.Unmarshal(serializedSnap.Data); err != nil {
+	if err = snap.Unmarshal(data); err != nil {
 		lg.Warn("failed to unmarshal raftpb.Snapshot", zap.String("path", snapname), zap.Error(err))
 		return nil, err
 	}
@@ -15,6 +15,8 @@
 package snap
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
 	"hash/crc32"
 	"os"
@@ -25,6 +27,7 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.etcd.io/raft/v3/raftpb"
 )
@@ -62,6 +65,47 @@ func TestSaveAndLoad(t *testing.T) {
 	}
 }
 
+type testKeyProvider []encryption.Key
+
+func (p testKeyProvider) Keys() ([]encryption.Key, error) { return p, nil }
+
+func TestSaveAndLoadEncrypted(t *testing.T) {
+	dir := t.TempDir()
+	keyring, err := encryption.NewKeyring(testKeyProvider{{ID: 1, Data: bytes.Repeat([]byte{1}, 32)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss := New(zaptest.NewLogger(t), dir)
+	ss.SetCipher(keyring)
+	if err = ss.save(testSnap); err != nil {
+		t.Fatal(err)
+	}
+	names, err := ss.snapNames()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, names[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, testSnap.Data) {
+		t.Fatal("expected the snapshot to be encrypted")
+	}
+
+	// the snapshot is not broken without the key
+	_, err = New(zaptest.NewLogger(t), dir).Load()
+	if !errors.Is(err, encryption.ErrKeyRequired) {
+		t.Fatalf("err = %v, want %v", err, encryption.ErrKeyRequired)
+	}
+	g, err := ss.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(g, testSnap) {
+		t.Errorf("snap = %#v, want %#v", g, testSnap)
+	}
+}
+
 func TestBadCRC(t *testing.T) {
 	dir := filepath.Join(os.TempDir(), "snapshot")
 	err := os.Mkdir(dir, 0700)
@@ -0,0 +1,204 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3audit records an audit trail of mutating and auth requests as
+// JSON lines.
+package v3audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Level is how much of a request is recorded.
+type Level int
+
+const (
+	// LevelNone does not record the request.
+	LevelNone Level = iota
+	// LevelMetadata records who made the request, the keys it touched and
+	// its outcome.
+	LevelMetadata
+	// LevelRequest additionally records the request itself, including the
+	// values it writes. Passwords are always redacted.
+	LevelRequest
+)
+
+// AllTypes is the request type that sets the level of all the request types
+// audited by default.
+const AllTypes = "*"
+
+var levelNames = map[string]Level{
+	"none":     LevelNone,
+	"metadata": LevelMetadata,
+	"request":  LevelRequest,
+}
+
+func (l Level) String() string {
+	switch l {
+	case LevelNone:
+		return "none"
+	case LevelMetadata:
+		return "metadata"
+	case LevelRequest:
+		return "request"
+	}
+	return fmt.Sprintf("Level(%d)", int(l))
+}
+
+// ParseLevels parses a comma separated list of <request type>=<level>
+// entries, for example "Put=request,Range=metadata,*=metadata". The level is
+// one of "none", "metadata" or "request".
+func ParseLevels(s string) (map[string]Level, error) {
+	levels := make(map[string]Level)
+	if strings.TrimSpace(s) == "" {
+		return levels, nil
+	}
+	for _, entry := range strings.Split(s, ",") {
+		kv := strings.SplitN(strings.TrimSpace(entry), "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("invalid audit level %q, expected <request type>=<level>", entry)
+		}
+		lvl, ok := levelNames[kv[1]]
+		if !ok {
+			return nil, fmt.Errorf("unknown audit level %q for request type %q", kv[1], kv[0])
+		}
+		levels[kv[0]] = lvl
+	}
+	return levels, nil
+}
+
+const (
+	// StageResponse events are recorded by the member serving the client
+	// once the response is ready.
+	StageResponse = "response"
+	// StageApply events are recorded by every member when a request is
+	// applied to its state machine.
+	StageApply = "apply"
+)
+
+// KeyRange is a key or a range of keys touched by a request.
+type KeyRange struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range-end,omitempty"`
+}
+
+// Event is a single entry of the audit log.
+type Event struct {
+	Time  time.Time `json:"time"`
+	Stage string    `json:"stage"`
+	Type  string    `json:"type"`
+	// User is the authenticated user, if any.
+	User string `json:"user,omitempty"`
+	// CommonName is the common name of the client certificate, if any.
+	CommonName string `json:"common-name,omitempty"`
+	RemoteAddr string `json:"remote-addr,omitempty"`
+	// Subject is the user, role or tenant an auth request is about.
+	Subject  string     `json:"subject,omitempty"`
+	Ranges   []KeyRange `json:"ranges,omitempty"`
+	Revision int64      `json:"revision,omitempty"`
+	Outcome  string     `json:"outcome"`
+	Error    string     `json:"error,omitempty"`
+	// Request is only recorded at LevelRequest.
+	Request interface{} `json:"request,omitempty"`
+}
+
+// Logger writes audit events to a JSON lines file.
+type Logger struct {
+	lg     *zap.Logger
+	levels map[string]Level
+
+	mu     sync.Mutex
+	w      io.WriteCloser
+	closed bool
+}
+
+// NewLogger creates a Logger writing to w. Request types missing from levels
+// are recorded at the level of AllTypes if they are audited by default, and
+// not recorded otherwise.
+func NewLogger(lg *zap.Logger, w io.WriteCloser, levels map[string]Level) *Logger {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &Logger{lg: lg, w: w, levels: levels}
+}
+
+// NewFileLogger creates a Logger writing to the file at path, rotated
+// according to a JSON config in the format of --log-rotation-config-json.
+func NewFileLogger(lg *zap.Logger, path string, rotationConfigJSON string, levels map[string]Level) (*Logger, error) {
+	rotation := &lumberjack.Logger{}
+	if rotationConfigJSON != "" {
+		if err := json.Unmarshal([]byte(rotationConfigJSON), rotation); err != nil {
+			return nil, fmt.Errorf("invalid audit log rotation config: %v", err)
+		}
+	}
+	rotation.Filename = path
+	return NewLogger(lg, rotation, levels), nil
+}
+
+// Level returns the level requests of the given type are recorded at.
+func (l *Logger) Level(typ string) Level {
+	if lvl, ok := l.levels[typ]; ok {
+		return lvl
+	}
+	if _, ok := defaultTypes[typ]; !ok {
+		return LevelNone
+	}
+	if lvl, ok := l.levels[AllTypes]; ok {
+		return lvl
+	}
+	return LevelMetadata
+}
+
+// Log writes the event as a single line. Failures are logged and counted
+// but never fail the request.
+func (l *Logger) Log(ev *Event) {
+	data, err := json.Marshal(ev)
+	if err != nil {
+		writeFailures.Inc()
+		l.lg.Warn("failed to marshal audit event", zap.String("type", ev.Type), zap.Error(err))
+		return
+	}
+	data = append(data, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return
+	}
+	if _, err = l.w.Write(data); err != nil {
+		writeFailures.Inc()
+		l.lg.Warn("failed to write audit event", zap.String("type", ev.Type), zap.Error(err))
+		return
+	}
+	events.WithLabelValues(ev.Stage, ev.Type).Inc()
+}
+
+// Close closes the underlying writer. Events logged afterwards are dropped.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		return nil
+	}
+	l.closed = true
+	return l.w.Close()
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+type nopCloser struct{ bytes.Buffer }
+
+func (*nopCloser) Close() error { return nil }
+
+func TestParseLevels(t *testing.T) {
+	tcs := []struct {
+		in      string
+		want    map[string]Level
+		wantErr bool
+	}{
+		{in: "", want: map[string]Level{}},
+		{in: "Put=request, *=none", want: map[string]Level{"Put": LevelRequest, AllTypes: LevelNone}},
+		{in: "Range=metadata", want: map[string]Level{"Range": LevelMetadata}},
+		{in: "Put", wantErr: true},
+		{in: "=request", wantErr: true},
+		{in: "Put=all", wantErr: true},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.in, func(t *testing.T) {
+			got, err := ParseLevels(tc.in)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestLoggerLevel(t *testing.T) {
+	l := NewLogger(zaptest.NewLogger(t), &nopCloser{}, map[string]Level{"Range": LevelMetadata})
+	assert.Equal(t, LevelMetadata, l.Level("Put"))
+	assert.Equal(t, LevelMetadata, l.Level("Range"))
+	assert.Equal(t, LevelNone, l.Level("LeaseKeepAlive"))
+
+	l = NewLogger(zaptest.NewLogger(t), &nopCloser{}, map[string]Level{AllTypes: LevelRequest, "UserGet": LevelNone})
+	assert.Equal(t, LevelRequest, l.Level("Put"))
+	assert.Equal(t, LevelNone, l.Level("UserGet"))
+	assert.Equal(t, LevelNone, l.Level("Range"))
+}
+
+func TestLoggerLog(t *testing.T) {
+	w := &nopCloser{}
+	l := NewLogger(zaptest.NewLogger(t), w, nil)
+
+	req := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("b"), Value: []byte("v")}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Success: []*pb.RequestOp{
+					{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("c"), RangeEnd: []byte("d")}}},
+				},
+			}}},
+		},
+	}
+	ev := NewEvent(StageResponse, "Txn", LevelMetadata, req, &pb.TxnResponse{Header: &pb.ResponseHeader{Revision: 5}}, nil)
+	ev.User = "u1"
+	l.Log(ev)
+	l.Log(NewEvent(StageApply, "Put", LevelRequest, &pb.PutRequest{Key: []byte("a"), Value: []byte("v")}, nil, errors.New("no space")))
+	require.NoError(t, l.Close())
+	// events logged after close are dropped
+	l.Log(ev)
+
+	lines := bytes.Split(bytes.TrimSuffix(w.Bytes(), []byte("\n")), []byte("\n"))
+	require.Len(t, lines, 2)
+
+	var got Event
+	require.NoError(t, json.Unmarshal(lines[0], &got))
+	assert.Equal(t, StageResponse, got.Stage)
+	assert.Equal(t, "Txn", got.Type)
+	assert.Equal(t, "u1", got.User)
+	assert.Equal(t, int64(5), got.Revision)
+	assert.Equal(t, OutcomeSuccess, got.Outcome)
+	assert.Equal(t, []KeyRange{{Key: []byte("a")}, {Key: []byte("b")}, {Key: []byte("c"), RangeEnd: []byte("d")}}, got.Ranges)
+	assert.Nil(t, got.Request)
+
+	got = Event{}
+	require.NoError(t, json.Unmarshal(lines[1], &got))
+	assert.Equal(t, StageApply, got.Stage)
+	assert.Equal(t, OutcomeFailure, got.Outcome)
+	assert.Equal(t, "no space", got.Error)
+	assert.NotNil(t, got.Request)
+}
+
+func TestRedact(t *testing.T) {
+	req := &pb.AuthUserAddRequest{Name: "u1", Password: "secret"}
+	ev := NewEvent(StageResponse, "UserAdd", LevelRequest, req, nil, nil)
+	assert.Equal(t, "u1", ev.Subject)
+	assert.Equal(t, &pb.AuthUserAddRequest{Name: "u1", Password: redacted}, ev.Request)
+	// the request itself is left untouched
+	assert.Equal(t, "secret", req.Password)
+
+	ev = NewEvent(StageApply, "Authenticate", LevelRequest, &pb.InternalAuthenticateRequest{Name: "u1", Password: "secret", SimpleToken: "token"}, nil, nil)
+	assert.Equal(t, &pb.InternalAuthenticateRequest{Name: "u1", Password: redacted, SimpleToken: redacted}, ev.Request)
+}
+
+func TestRaftRequest(t *testing.T) {
+	typ, req := RaftRequest(&pb.InternalRaftRequest{Compaction: &pb.CompactionRequest{Revision: 3}})
+	assert.Equal(t, "Compact", typ)
+	assert.Equal(t, &pb.CompactionRequest{Revision: 3}, req)
+
+	typ, _ = RaftRequest(&pb.InternalRaftRequest{AuthTenantAdd: &pb.AuthTenantAddRequest{Name: "t1"}})
+	assert.Equal(t, "TenantAdd", typ)
+
+	typ, _ = RaftRequest(&pb.InternalRaftRequest{LeaseCheckpoint: &pb.LeaseCheckpointRequest{}})
+	assert.Empty(t, typ)
+}
@@ -0,0 +1,282 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3audit
+
+import (
+	"strings"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+// Outcomes of a request.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+const redacted = "[redacted]"
+
+// defaultTypes are the request types audited unless configured otherwise:
+// every request that mutates the cluster and every auth request. The names
+// are the names of the gRPC methods.
+var defaultTypes = map[string]struct{}{
+	"Put":         {},
+	"DeleteRange": {},
+	"Txn":         {},
+	"Compact":     {},
+	"LeaseGrant":  {},
+	"LeaseRevoke": {},
+
+	"MemberAdd":     {},
+	"MemberRemove":  {},
+	"MemberUpdate":  {},
+	"MemberPromote": {},
+
+	"Alarm":               {},
+	"Defragment":          {},
+	"MoveLeader":          {},
+	"Downgrade":           {},
+	"QuotaSet":            {},
+	"QuotaDelete":         {},
+	"RotateEncryptionKey": {},
+
+	"AuthEnable":           {},
+	"AuthDisable":          {},
+	"AuthStatus":           {},
+	"Authenticate":         {},
+	"UserAdd":              {},
+	"UserGet":              {},
+	"UserList":             {},
+	"UserDelete":           {},
+	"UserChangePassword":   {},
+	"UserGrantRole":        {},
+	"UserRevokeRole":       {},
+	"RoleAdd":              {},
+	"RoleGet":              {},
+	"RoleList":             {},
+	"RoleDelete":           {},
+	"RoleGrantPermission":  {},
+	"RoleRevokePermission": {},
+	"TenantAdd":            {},
+	"TenantDelete":         {},
+	"TenantList":           {},
+}
+
+// MethodType returns the request type of a full gRPC method name, for
+// example "Put" for "/etcdserverpb.KV/Put".
+func MethodType(fullMethod string) string {
+	return fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+}
+
+// NewEvent creates the event of a request of type typ answered with resp or
+// failed with err.
+func NewEvent(stage, typ string, lvl Level, req, resp interface{}, err error) *Event {
+	ev := &Event{
+		Time:    time.Now().UTC(),
+		Stage:   stage,
+		Type:    typ,
+		Outcome: OutcomeSuccess,
+	}
+	ev.Subject, ev.Ranges = describe(req)
+	if err != nil {
+		ev.Outcome = OutcomeFailure
+		ev.Error = err.Error()
+	}
+	if r, ok := resp.(interface{ GetHeader() *pb.ResponseHeader }); ok && r.GetHeader() != nil {
+		ev.Revision = r.GetHeader().Revision
+	}
+	if lvl >= LevelRequest {
+		ev.Request = redact(req)
+	}
+	return ev
+}
+
+// RaftRequest returns the request type and the request of an applied raft
+// request. The type is empty for requests not sent by clients.
+func RaftRequest(r *pb.InternalRaftRequest) (string, interface{}) {
+	switch {
+	case r.Put != nil:
+		return "Put", r.Put
+	case r.DeleteRange != nil:
+		return "DeleteRange", r.DeleteRange
+	case r.Txn != nil:
+		return "Txn", r.Txn
+	case r.Compaction != nil:
+		return "Compact", r.Compaction
+	case r.LeaseGrant != nil:
+		return "LeaseGrant", r.LeaseGrant
+	case r.LeaseRevoke != nil:
+		return "LeaseRevoke", r.LeaseRevoke
+	case r.Alarm != nil:
+		return "Alarm", r.Alarm
+	case r.QuotaSet != nil:
+		return "QuotaSet", r.QuotaSet
+	case r.QuotaDelete != nil:
+		return "QuotaDelete", r.QuotaDelete
+	case r.AuthEnable != nil:
+		return "AuthEnable", r.AuthEnable
+	case r.AuthDisable != nil:
+		return "AuthDisable", r.AuthDisable
+	case r.AuthStatus != nil:
+		return "AuthStatus", r.AuthStatus
+	case r.Authenticate != nil:
+		return "Authenticate", r.Authenticate
+	case r.AuthUserAdd != nil:
+		return "UserAdd", r.AuthUserAdd
+	case r.AuthUserDelete != nil:
+		return "UserDelete", r.AuthUserDelete
+	case r.AuthUserGet != nil:
+		return "UserGet", r.AuthUserGet
+	case r.AuthUserChangePassword != nil:
+		return "UserChangePassword", r.AuthUserChangePassword
+	case r.AuthUserGrantRole != nil:
+		return "UserGrantRole", r.AuthUserGrantRole
+	case r.AuthUserRevokeRole != nil:
+		return "UserRevokeRole", r.AuthUserRevokeRole
+	case r.AuthUserList != nil:
+		return "UserList", r.AuthUserList
+	case r.AuthRoleList != nil:
+		return "RoleList", r.AuthRoleList
+	case r.AuthRoleAdd != nil:
+		return "RoleAdd", r.AuthRoleAdd
+	case r.AuthRoleDelete != nil:
+		return "RoleDelete", r.AuthRoleDelete
+	case r.AuthRoleGet != nil:
+		return "RoleGet", r.AuthRoleGet
+	case r.AuthRoleGrantPermission != nil:
+		return "RoleGrantPermission", r.AuthRoleGrantPermission
+	case r.AuthRoleRevokePermission != nil:
+		return "RoleRevokePermission", r.AuthRoleRevokePermission
+	case r.AuthTenantAdd != nil:
+		return "TenantAdd", r.AuthTenantAdd
+	case r.AuthTenantDelete != nil:
+		return "TenantDelete", r.AuthTenantDelete
+	case r.AuthTenantList != nil:
+		return "TenantList", r.AuthTenantList
+	}
+	return "", nil
+}
+
+// describe returns the user, role or tenant the request is about and the
+// key ranges it touches.
+func describe(req interface{}) (string, []KeyRange) {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		return "", []KeyRange{{Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.PutRequest:
+		return "", []KeyRange{{Key: r.Key}}
+	case *pb.DeleteRangeRequest:
+		return "", []KeyRange{{Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.TxnRequest:
+		return "", txnRanges(nil, r)
+	case *pb.QuotaSetRequest:
+		if r.Quota != nil {
+			return "", []KeyRange{{Key: r.Quota.Prefix}}
+		}
+	case *pb.QuotaDeleteRequest:
+		return "", []KeyRange{{Key: r.Prefix}}
+	case *pb.AuthenticateRequest:
+		return r.Name, nil
+	case *pb.InternalAuthenticateRequest:
+		return r.Name, nil
+	case *pb.AuthUserAddRequest:
+		return r.Name, nil
+	case *pb.AuthUserGetRequest:
+		return r.Name, nil
+	case *pb.AuthUserDeleteRequest:
+		return r.Name, nil
+	case *pb.AuthUserChangePasswordRequest:
+		return r.Name, nil
+	case *pb.AuthUserGrantRoleRequest:
+		return r.User, nil
+	case *pb.AuthUserRevokeRoleRequest:
+		return r.Name, nil
+	case *pb.AuthRoleAddRequest:
+		return r.Name, nil
+	case *pb.AuthRoleGetRequest:
+		return r.Role, nil
+	case *pb.AuthRoleDeleteRequest:
+		return r.Role, nil
+	case *pb.AuthRoleGrantPermissionRequest:
+		if r.Perm != nil {
+			return r.Name, []KeyRange{{Key: r.Perm.Key, RangeEnd: r.Perm.RangeEnd}}
+		}
+		return r.Name, nil
+	case *pb.AuthRoleRevokePermissionRequest:
+		return r.Role, []KeyRange{{Key: r.Key, RangeEnd: r.RangeEnd}}
+	case *pb.AuthTenantAddRequest:
+		return r.Name, []KeyRange{{Key: r.Prefix}}
+	case *pb.AuthTenantDeleteRequest:
+		return r.Name, nil
+	}
+	return "", nil
+}
+
+func txnRanges(ranges []KeyRange, r *pb.TxnRequest) []KeyRange {
+	for _, c := range r.Compare {
+		ranges = append(ranges, KeyRange{Key: c.Key, RangeEnd: c.RangeEnd})
+	}
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch tv := op.Request.(type) {
+			case *pb.RequestOp_RequestRange:
+				ranges = append(ranges, KeyRange{Key: tv.RequestRange.Key, RangeEnd: tv.RequestRange.RangeEnd})
+			case *pb.RequestOp_RequestPut:
+				ranges = append(ranges, KeyRange{Key: tv.RequestPut.Key})
+			case *pb.RequestOp_RequestDeleteRange:
+				ranges = append(ranges, KeyRange{Key: tv.RequestDeleteRange.Key, RangeEnd: tv.RequestDeleteRange.RangeEnd})
+			case *pb.RequestOp_RequestTxn:
+				ranges = txnRanges(ranges, tv.RequestTxn)
+			}
+		}
+	}
+	return ranges
+}
+
+// redact returns the request with its secrets replaced. Requests carrying
+// secrets are copied rather than modified in place.
+func redact(req interface{}) interface{} {
+	switch r := req.(type) {
+	case *pb.AuthenticateRequest:
+		nr := *r
+		nr.Password = redacted
+		return &nr
+	case *pb.InternalAuthenticateRequest:
+		nr := *r
+		nr.Password = redacted
+		nr.SimpleToken = redacted
+		return &nr
+	case *pb.AuthUserAddRequest:
+		nr := *r
+		if nr.Password != "" {
+			nr.Password = redacted
+		}
+		if nr.HashedPassword != "" {
+			nr.HashedPassword = redacted
+		}
+		return &nr
+	case *pb.AuthUserChangePasswordRequest:
+		nr := *r
+		if nr.Password != "" {
+			nr.Password = redacted
+		}
+		if nr.HashedPassword != "" {
+			nr.HashedPassword = redacted
+		}
+		return &nr
+	}
+	return req
+}
@@ -0,0 +1,55 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3quota
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var (
+	keyQuotaUsedBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "key_quota_used_bytes",
+		Help:      "Bytes of keys and values stored under each key quota prefix.",
+	},
+		[]string{"prefix"})
+	keyQuotaUsedKeys = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "key_quota_used_keys",
+		Help:      "Number of keys stored under each key quota prefix.",
+	},
+		[]string{"prefix"})
+	keyQuotaMaxBytes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "key_quota_max_bytes",
+		Help:      "Byte limit of each key quota prefix. 0 means unlimited.",
+	},
+		[]string{"prefix"})
+	keyQuotaMaxKeys = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "key_quota_max_keys",
+		Help:      "Key count limit of each key quota prefix. 0 means unlimited.",
+	},
+		[]string{"prefix"})
+)
+
+func init() {
+	prometheus.MustRegister(keyQuotaUsedBytes)
+	prometheus.MustRegister(keyQuotaUsedKeys)
+	prometheus.MustRegister(keyQuotaMaxBytes)
+	prometheus.MustRegister(keyQuotaMaxKeys)
+}
@@ -0,0 +1,272 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package v3quota enforces storage quotas on key prefixes.
+package v3quota
+
+import (
+	"bytes"
+	"context"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+// scanPageSize is the number of keys read at once when the usage of a
+// prefix is recomputed from the key space.
+const scanPageSize = 1000
+
+type QuotaBackend interface {
+	CreateQuotaBucket()
+	MustPutQuota(quota *pb.KeyQuota)
+	MustDeleteQuota(prefix []byte)
+	GetAllQuotas() ([]*pb.KeyQuota, error)
+	ForceCommit()
+}
+
+// Charge is the change in usage a request would cause on a single key.
+type Charge struct {
+	Key   []byte
+	Bytes int64
+	Keys  int64
+}
+
+type keyQuota struct {
+	pb.KeyQuota
+	usedBytes int64
+	usedKeys  int64
+}
+
+// QuotaStore persists key prefix quotas to the backend and tracks how much
+// of each quota is used. It implements mvcc.UsageTracker, so the usage is
+// updated by the write txn that changes a tracked key.
+//
+// Quotas are expected to be few; every lookup scans all of them.
+type QuotaStore struct {
+	lg *zap.Logger
+	mu sync.RWMutex
+	// quotas is sorted by prefix.
+	quotas []*keyQuota
+
+	be QuotaBackend
+	rv mvcc.ReadView
+}
+
+func NewQuotaStore(lg *zap.Logger) *QuotaStore {
+	if lg == nil {
+		lg = zap.NewNop()
+	}
+	return &QuotaStore{lg: lg}
+}
+
+// Recover loads the quotas stored in the backend and recomputes their usage
+// from the given key space. It must be called before the store is used and
+// whenever the backend is replaced.
+func (s *QuotaStore) Recover(be QuotaBackend, rv mvcc.ReadView) error {
+	be.CreateQuotaBucket()
+	qs, err := be.GetAllQuotas()
+	if err != nil {
+		return err
+	}
+	be.ForceCommit()
+
+	quotas := make([]*keyQuota, 0, len(qs))
+	for _, q := range qs {
+		kq := &keyQuota{KeyQuota: *q}
+		if kq.usedBytes, kq.usedKeys, err = scanUsage(rv, q.Prefix); err != nil {
+			return err
+		}
+		quotas = append(quotas, kq)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range s.quotas {
+		deleteMetrics(q)
+	}
+	s.quotas = quotas
+	s.be = be
+	s.rv = rv
+	for _, q := range s.quotas {
+		q.updateMetrics()
+	}
+	return nil
+}
+
+// Set creates or replaces the quota on the given prefix.
+func (s *QuotaStore) Set(quota *pb.KeyQuota) error {
+	if len(quota.Prefix) == 0 || quota.MaxBytes < 0 || quota.MaxKeys < 0 {
+		return errors.ErrKeyQuotaInvalid
+	}
+	usedBytes, usedKeys, err := scanUsage(s.rv, quota.Prefix)
+	if err != nil {
+		return err
+	}
+	s.be.MustPutQuota(quota)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	kq := &keyQuota{KeyQuota: *quota, usedBytes: usedBytes, usedKeys: usedKeys}
+	i := s.search(quota.Prefix)
+	if i < len(s.quotas) && bytes.Equal(s.quotas[i].Prefix, quota.Prefix) {
+		s.quotas[i] = kq
+	} else {
+		s.quotas = append(s.quotas, nil)
+		copy(s.quotas[i+1:], s.quotas[i:])
+		s.quotas[i] = kq
+	}
+	kq.updateMetrics()
+	return nil
+}
+
+// Delete removes the quota on the given prefix.
+func (s *QuotaStore) Delete(prefix []byte) error {
+	s.mu.Lock()
+	i := s.search(prefix)
+	if i == len(s.quotas) || !bytes.Equal(s.quotas[i].Prefix, prefix) {
+		s.mu.Unlock()
+		return errors.ErrKeyQuotaNotFound
+	}
+	deleteMetrics(s.quotas[i])
+	s.quotas = append(s.quotas[:i], s.quotas[i+1:]...)
+	s.mu.Unlock()
+
+	s.be.MustDeleteQuota(prefix)
+	return nil
+}
+
+// List returns all quotas along with their current usage, sorted by prefix.
+func (s *QuotaStore) List() []*pb.KeyQuotaUsage {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	usages := make([]*pb.KeyQuotaUsage, 0, len(s.quotas))
+	for _, q := range s.quotas {
+		quota := q.KeyQuota
+		usages = append(usages, &pb.KeyQuotaUsage{Quota: &quota, UsedBytes: q.usedBytes, UsedKeys: q.usedKeys})
+	}
+	return usages
+}
+
+// Check returns ErrKeyQuotaExceeded if applying all the given charges would
+// grow the usage of any quota beyond its limits. Charges that shrink or keep
+// the usage are always allowed, even when a quota is already exceeded.
+func (s *QuotaStore) Check(charges ...Charge) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, q := range s.quotas {
+		var b, k int64
+		for _, c := range charges {
+			if bytes.HasPrefix(c.Key, q.Prefix) {
+				b += c.Bytes
+				k += c.Keys
+			}
+		}
+		if b > 0 && q.MaxBytes > 0 && q.usedBytes+b > q.MaxBytes {
+			return errors.ErrKeyQuotaExceeded
+		}
+		if k > 0 && q.MaxKeys > 0 && q.usedKeys+k > q.MaxKeys {
+			return errors.ErrKeyQuotaExceeded
+		}
+	}
+	return nil
+}
+
+// Tracked implements mvcc.UsageTracker.
+func (s *QuotaStore) Tracked(key []byte) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, q := range s.quotas {
+		if bytes.HasPrefix(key, q.Prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Update implements mvcc.UsageTracker.
+func (s *QuotaStore) Update(key []byte, bytesDelta, keysDelta int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, q := range s.quotas {
+		if bytes.HasPrefix(key, q.Prefix) {
+			q.usedBytes += bytesDelta
+			q.usedKeys += keysDelta
+			q.updateMetrics()
+		}
+	}
+}
+
+func (s *QuotaStore) search(prefix []byte) int {
+	return sort.Search(len(s.quotas), func(i int) bool {
+		return bytes.Compare(s.quotas[i].Prefix, prefix) >= 0
+	})
+}
+
+func (q *keyQuota) updateMetrics() {
+	prefix := string(q.Prefix)
+	keyQuotaUsedBytes.WithLabelValues(prefix).Set(float64(q.usedBytes))
+	keyQuotaUsedKeys.WithLabelValues(prefix).Set(float64(q.usedKeys))
+	keyQuotaMaxBytes.WithLabelValues(prefix).Set(float64(q.MaxBytes))
+	keyQuotaMaxKeys.WithLabelValues(prefix).Set(float64(q.MaxKeys))
+}
+
+func deleteMetrics(q *keyQuota) {
+	prefix := string(q.Prefix)
+	keyQuotaUsedBytes.DeleteLabelValues(prefix)
+	keyQuotaUsedKeys.DeleteLabelValues(prefix)
+	keyQuotaMaxBytes.DeleteLabelValues(prefix)
+	keyQuotaMaxKeys.DeleteLabelValues(prefix)
+}
+
+// scanUsage sums the size of all keys under prefix, reading the key space in
+// pages at a single revision.
+func scanUsage(rv mvcc.ReadView, prefix []byte) (usedBytes, usedKeys int64, err error) {
+	key, end := prefix, prefixEnd(prefix)
+	var rev int64
+	for {
+		rr, err := rv.Range(context.TODO(), key, end, mvcc.RangeOptions{Limit: scanPageSize, Rev: rev})
+		if err != nil {
+			return 0, 0, err
+		}
+		for _, kv := range rr.KVs {
+			usedBytes += int64(len(kv.Key) + len(kv.Value))
+		}
+		usedKeys += int64(len(rr.KVs))
+		if len(rr.KVs) < scanPageSize {
+			return usedBytes, usedKeys, nil
+		}
+		rev = rr.Rev
+		key = append(rr.KVs[len(rr.KVs)-1].Key, 0)
+	}
+}
+
+// prefixEnd returns the end of the range covering all keys with the given
+// prefix.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i] = end[i] + 1
+			return end[:i+1]
+		}
+	}
+	// the prefix is all 0xff; range to the end of the key space
+	return []byte{}
+}
@@ -0,0 +1,200 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3quota
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func newTestQuotaStore(t *testing.T) (*QuotaStore, mvcc.KV) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	t.Cleanup(func() { betesting.Close(t, be) })
+
+	qs := NewQuotaStore(lg)
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{UsageTracker: qs})
+	t.Cleanup(func() { kv.Close() })
+	require.NoError(t, qs.Recover(schema.NewQuotaBackend(lg, be), kv))
+	return qs, kv
+}
+
+func usage(prefix string, maxBytes, maxKeys, usedBytes, usedKeys int64) *pb.KeyQuotaUsage {
+	return &pb.KeyQuotaUsage{
+		Quota:     &pb.KeyQuota{Prefix: []byte(prefix), MaxBytes: maxBytes, MaxKeys: maxKeys},
+		UsedBytes: usedBytes,
+		UsedKeys:  usedKeys,
+	}
+}
+
+func TestQuotaStoreSetComputesUsage(t *testing.T) {
+	qs, kv := newTestQuotaStore(t)
+	kv.Put([]byte("/a/1"), []byte("foo"), lease.NoLease)
+	kv.Put([]byte("/a/2"), []byte("barbar"), lease.NoLease)
+	kv.Put([]byte("/b/1"), []byte("baz"), lease.NoLease)
+
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/b/"), MaxKeys: 1}))
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 100}))
+	assert.Equal(t, []*pb.KeyQuotaUsage{
+		usage("/a/", 100, 0, 17, 2),
+		usage("/b/", 0, 1, 7, 1),
+	}, qs.List())
+
+	// replacing a quota keeps its usage
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxKeys: 5}))
+	assert.Equal(t, usage("/a/", 0, 5, 17, 2), qs.List()[0])
+}
+
+func TestQuotaStoreSetInvalid(t *testing.T) {
+	qs, _ := newTestQuotaStore(t)
+	tcs := []*pb.KeyQuota{
+		{MaxBytes: 10},
+		{Prefix: []byte("/a/"), MaxBytes: -1},
+		{Prefix: []byte("/a/"), MaxKeys: -1},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, errors.ErrKeyQuotaInvalid, qs.Set(tc))
+	}
+	assert.Empty(t, qs.List())
+}
+
+func TestQuotaStoreTracksWrites(t *testing.T) {
+	qs, kv := newTestQuotaStore(t)
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/")}))
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/b/")}))
+
+	kv.Put([]byte("/a/1"), []byte("foo"), lease.NoLease)
+	kv.Put([]byte("/a/b/1"), []byte("bar"), lease.NoLease)
+	kv.Put([]byte("/c/1"), []byte("baz"), lease.NoLease)
+	assert.Equal(t, []*pb.KeyQuotaUsage{
+		usage("/a/", 0, 0, 16, 2),
+		usage("/a/b/", 0, 0, 9, 1),
+	}, qs.List())
+
+	kv.DeleteRange([]byte("/a/"), []byte("/a0"))
+	assert.Equal(t, []*pb.KeyQuotaUsage{
+		usage("/a/", 0, 0, 0, 0),
+		usage("/a/b/", 0, 0, 0, 0),
+	}, qs.List())
+}
+
+func TestQuotaStoreCheck(t *testing.T) {
+	qs, kv := newTestQuotaStore(t)
+	kv.Put([]byte("/a/1"), []byte("foo"), lease.NoLease)
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 20, MaxKeys: 2}))
+
+	tcs := []struct {
+		name    string
+		charges []Charge
+		wantErr error
+	}{
+		{
+			name:    "within quota",
+			charges: []Charge{{Key: []byte("/a/2"), Bytes: 7, Keys: 1}},
+		},
+		{
+			name:    "untracked key",
+			charges: []Charge{{Key: []byte("/b/2"), Bytes: 100, Keys: 10}},
+		},
+		{
+			name:    "exceeds bytes",
+			charges: []Charge{{Key: []byte("/a/1"), Bytes: 14}},
+			wantErr: errors.ErrKeyQuotaExceeded,
+		},
+		{
+			name: "exceeds keys",
+			charges: []Charge{
+				{Key: []byte("/a/2"), Bytes: 1, Keys: 1},
+				{Key: []byte("/a/3"), Bytes: 1, Keys: 1},
+			},
+			wantErr: errors.ErrKeyQuotaExceeded,
+		},
+		{
+			name: "shrinking charges offset growing ones",
+			charges: []Charge{
+				{Key: []byte("/a/1"), Bytes: -3},
+				{Key: []byte("/a/2"), Bytes: 16},
+			},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, qs.Check(tc.charges...))
+		})
+	}
+
+	// shrinking a quota below its usage still allows writes that free space
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 1}))
+	assert.NoError(t, qs.Check(Charge{Key: []byte("/a/1"), Bytes: -1}))
+	assert.Equal(t, errors.ErrKeyQuotaExceeded, qs.Check(Charge{Key: []byte("/a/1"), Bytes: 1}))
+}
+
+func TestQuotaStoreDelete(t *testing.T) {
+	qs, _ := newTestQuotaStore(t)
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 10}))
+
+	assert.Equal(t, errors.ErrKeyQuotaNotFound, qs.Delete([]byte("/b/")))
+	require.NoError(t, qs.Delete([]byte("/a/")))
+	assert.Empty(t, qs.List())
+	assert.False(t, qs.Tracked([]byte("/a/1")))
+	assert.Equal(t, errors.ErrKeyQuotaNotFound, qs.Delete([]byte("/a/")))
+}
+
+func TestQuotaStoreRecover(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+
+	qs := NewQuotaStore(lg)
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{UsageTracker: qs})
+	defer kv.Close()
+	require.NoError(t, qs.Recover(schema.NewQuotaBackend(lg, be), kv))
+	require.NoError(t, qs.Set(&pb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 100}))
+
+	txn := kv.Write(traceutil.TODO())
+	txn.Put([]byte("/a/1"), []byte("foo"), lease.NoLease)
+	txn.Put([]byte("/a/2"), []byte("bar"), lease.NoLease)
+	txn.End()
+
+	recovered := NewQuotaStore(lg)
+	require.NoError(t, recovered.Recover(schema.NewQuotaBackend(lg, be), kv))
+	assert.Equal(t, qs.List(), recovered.List())
+	assert.Equal(t, []*pb.KeyQuotaUsage{usage("/a/", 100, 0, 14, 2)}, recovered.List())
+}
+
+func TestPrefixEnd(t *testing.T) {
+	tcs := []struct {
+		prefix []byte
+		want   []byte
+	}{
+		{[]byte("/a/"), []byte("/a0")},
+		{[]byte{'a', 0xff}, []byte{'b'}},
+		{[]byte{0xff, 0xff}, []byte{}},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.want, prefixEnd(tc.prefix))
+	}
+}
@@ -110,6 +110,30 @@ func (as *AuthServer) RoleGrantPermission(ctx context.Context, r *pb.AuthRoleGra
 	return resp, nil
 }
 
+func (as *AuthServer) TenantAdd(ctx context.Context, r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error) {
+	resp, err := as.authenticator.TenantAdd(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) TenantDelete(ctx context.Context, r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error) {
+	resp, err := as.authenticator.TenantDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
+func (as *AuthServer) TenantList(ctx context.Context, r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error) {
+	resp, err := as.authenticator.TenantList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	return resp, nil
+}
+
 func (as *AuthServer) UserAdd(ctx context.Context, r *pb.AuthUserAddRequest) (*pb.AuthUserAddResponse, error) {
 	resp, err := as.authenticator.UserAdd(ctx, r)
 	if err != nil {
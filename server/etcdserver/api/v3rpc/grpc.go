@@ -26,6 +26,10 @@ import (
 	grpc_prometheus "github.com/grpc-ecosystem/go-grpc-prometheus"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"google.golang.org/grpc"
+	// registers the gzip compressor; the server compresses the responses of
+	// streams whose client compresses its requests, such as watch streams
+	// from clientv3.WithWatchCompression
+	_ "google.golang.org/grpc/encoding/gzip"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 )
@@ -45,6 +49,8 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 	chainUnaryInterceptors := []grpc.UnaryServerInterceptor{
 		newLogUnaryInterceptor(s),
 		newUnaryInterceptor(s),
+		newTenantUnaryInterceptor(s),
+		newAuditUnaryInterceptor(s),
 		grpc_prometheus.UnaryServerInterceptor,
 	}
 	if interceptor != nil {
@@ -53,6 +59,7 @@ func Server(s *etcdserver.EtcdServer, tls *tls.Config, interceptor grpc.UnarySer
 
 	chainStreamInterceptors := []grpc.StreamServerInterceptor{
 		newStreamInterceptor(s),
+		newTenantStreamInterceptor(s),
 		grpc_prometheus.StreamServerInterceptor,
 	}
 
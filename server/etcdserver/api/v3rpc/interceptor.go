@@ -24,6 +24,7 @@ import (
 	"go.etcd.io/etcd/client/pkg/v3/types"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/api"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3audit"
 	"go.etcd.io/raft/v3"
 
 	"go.uber.org/zap"
@@ -37,6 +38,7 @@ import (
 const (
 	maxNoLeaderCnt = 3
 	snapshotMethod = "/etcdserverpb.Maintenance/Snapshot"
+	watchMethod    = "/etcdserverpb.Watch/Watch"
 )
 
 type streamsMap struct {
@@ -88,6 +90,38 @@ func newLogUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerIntercepto
 	}
 }
 
+// newAuditUnaryInterceptor records the requests served by this member in the
+// audit log. It runs after the tenant interceptor, so the keys recorded are the
+// real keys of the request.
+func newAuditUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		al := s.AuditLogger()
+		if al == nil {
+			return handler(ctx, req)
+		}
+		typ := v3audit.MethodType(info.FullMethod)
+		lvl := al.Level(typ)
+		if lvl == v3audit.LevelNone {
+			return handler(ctx, req)
+		}
+
+		resp, err := handler(ctx, req)
+
+		ev := v3audit.NewEvent(v3audit.StageResponse, typ, lvl, req, resp, err)
+		if authInfo, aerr := s.AuthInfoFromCtx(ctx); aerr == nil && authInfo != nil {
+			ev.User = authInfo.Username
+		}
+		if tlsInfo := s.AuthStore().AuthInfoFromTLS(ctx); tlsInfo != nil {
+			ev.CommonName = tlsInfo.Username
+		}
+		if peerInfo, ok := peer.FromContext(ctx); ok {
+			ev.RemoteAddr = peerInfo.Addr.String()
+		}
+		al.Log(ev)
+		return resp, err
+	}
+}
+
 func logUnaryRequestStats(ctx context.Context, lg *zap.Logger, warnLatency time.Duration, info *grpc.UnaryServerInfo, startTime time.Time, req interface{}, resp interface{}) {
 	duration := time.Since(startTime)
 	var enabledDebugLevel, expensiveRequest bool
@@ -219,7 +253,7 @@ func newStreamInterceptor(s *etcdserver.EtcdServer) grpc.StreamServerInterceptor
 			return rpctypes.ErrGRPCNotCapable
 		}
 
-		if s.IsMemberExist(s.MemberId()) && s.IsLearner() && info.FullMethod != snapshotMethod { // learner does not support stream RPC except Snapshot
+		if s.IsMemberExist(s.MemberId()) && s.IsLearner() && !isStreamRPCSupportedForLearner(info.FullMethod, s.IsObserver()) {
 			return rpctypes.ErrGRPCNotSupportedForLearner
 		}
 
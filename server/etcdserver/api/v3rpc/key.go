@@ -22,6 +22,8 @@ import (
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/pkg/v3/adt"
 	"go.etcd.io/etcd/server/v3/etcdserver"
+	"go.etcd.io/etcd/server/v3/etcdserver/txn"
+	"go.etcd.io/etcd/server/v3/lease"
 )
 
 type kvServer struct {
@@ -124,6 +126,20 @@ func checkRangeRequest(r *pb.RangeRequest) error {
 		return rpctypes.ErrGRPCInvalidSortOption
 	}
 
+	for _, f := range r.Filters {
+		if f == nil || txn.CheckRangeFilter(f) != nil {
+			return rpctypes.ErrGRPCInvalidRangeFilter
+		}
+	}
+
+	if txn.CheckContinueToken(r) != nil {
+		return rpctypes.ErrGRPCInvalidContinueToken
+	}
+
+	if r.MaxStalenessMs < 0 || r.MaxStalenessRevisions < 0 {
+		return rpctypes.ErrGRPCInvalidMaxStaleness
+	}
+
 	return nil
 }
 
@@ -137,6 +153,15 @@ func checkPutRequest(r *pb.PutRequest) error {
 	if r.IgnoreLease && r.Lease != 0 {
 		return rpctypes.ErrGRPCLeaseProvided
 	}
+	if r.Ttl < 0 {
+		return rpctypes.ErrGRPCInvalidTTL
+	}
+	if r.Ttl > 0 && (r.Lease != 0 || r.IgnoreLease) {
+		return rpctypes.ErrGRPCTTLProvided
+	}
+	if r.Ttl > lease.MaxLeaseTTL {
+		return rpctypes.ErrGRPCLeaseTTLTooLarge
+	}
 	return nil
 }
 
@@ -155,7 +180,7 @@ func checkTxnRequest(r *pb.TxnRequest, maxTxnOps int) error {
 	if opc < len(r.Failure) {
 		opc = len(r.Failure)
 	}
-	if opc > maxTxnOps {
+	if opc > maxTxnOps || len(r.ReadSet) > maxTxnOps {
 		return rpctypes.ErrGRPCTooManyOps
 	}
 
@@ -164,6 +189,11 @@ func checkTxnRequest(r *pb.TxnRequest, maxTxnOps int) error {
 			return rpctypes.ErrGRPCEmptyKey
 		}
 	}
+	for _, e := range r.ReadSet {
+		if len(e.Key) == 0 {
+			return rpctypes.ErrGRPCEmptyKey
+		}
+	}
 	for _, u := range r.Success {
 		if err := checkRequestOp(u, maxTxnOps-opc); err != nil {
 			return err
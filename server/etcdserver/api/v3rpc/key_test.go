@@ -59,6 +59,105 @@ func TestCheckRangeRequest(t *testing.T) {
 	}
 }
 
+func TestCheckPutRequestTTL(t *testing.T) {
+	tcs := []struct {
+		req           *pb.PutRequest
+		expectedError error
+	}{
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: 10}},
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: 10, IgnoreValue: true}},
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: -1}, expectedError: rpctypes.ErrGRPCInvalidTTL},
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: 10, Lease: 1}, expectedError: rpctypes.ErrGRPCTTLProvided},
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: 10, IgnoreLease: true}, expectedError: rpctypes.ErrGRPCTTLProvided},
+		{req: &pb.PutRequest{Key: []byte("a"), Ttl: 9000000001}, expectedError: rpctypes.ErrGRPCLeaseTTLTooLarge},
+	}
+	for _, tc := range tcs {
+		if err := checkPutRequest(tc.req); getError(err) != getError(tc.expectedError) {
+			t.Errorf("checkPutRequest(%v) = %q, want %q", tc.req, getError(err), getError(tc.expectedError))
+		}
+	}
+}
+
+func TestCheckRangeRequestFilters(t *testing.T) {
+	tcs := []struct {
+		filter        *pb.RangeFilter
+		expectedError error
+	}{
+		{filter: &pb.RangeFilter{Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 2}}},
+		{filter: &pb.RangeFilter{Result: pb.RangeFilter_PREFIX, Target: pb.RangeFilter_VALUE, TargetUnion: &pb.RangeFilter_Value{Value: []byte("v")}}},
+		{filter: &pb.RangeFilter{Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte(`"v"`)}, JsonPath: "a.b[0]"}},
+		{
+			filter:        &pb.RangeFilter{Result: 10, Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 2}},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+		{
+			filter:        &pb.RangeFilter{Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Lease{Lease: 2}},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+		{
+			filter:        &pb.RangeFilter{Result: pb.RangeFilter_PREFIX, Target: pb.RangeFilter_MOD, TargetUnion: &pb.RangeFilter_ModRevision{ModRevision: 2}},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+		{
+			filter:        &pb.RangeFilter{Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte("v")}, JsonPath: "a"},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+		{
+			filter:        &pb.RangeFilter{Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte(`"v"`)}},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+		{
+			filter:        &pb.RangeFilter{Target: pb.RangeFilter_VALUE, TargetUnion: &pb.RangeFilter_Value{Value: []byte("v")}, JsonPath: "a"},
+			expectedError: rpctypes.ErrGRPCInvalidRangeFilter,
+		},
+	}
+	for _, tc := range tcs {
+		req := &pb.RangeRequest{Key: []byte("a"), Filters: []*pb.RangeFilter{tc.filter}}
+		if err := checkRangeRequest(req); getError(err) != getError(tc.expectedError) {
+			t.Errorf("checkRangeRequest(%v) = %q, want %q", req, getError(err), getError(tc.expectedError))
+		}
+	}
+}
+
+func TestCheckRangeRequestContinueToken(t *testing.T) {
+	req := &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), ContinueToken: "foo"}
+	if err := checkRangeRequest(req); getError(err) != getError(rpctypes.ErrGRPCInvalidContinueToken) {
+		t.Errorf("checkRangeRequest(%v) = %q, want %q", req, getError(err), getError(rpctypes.ErrGRPCInvalidContinueToken))
+	}
+}
+
+func TestCheckRangeRequestMaxStaleness(t *testing.T) {
+	tcs := []struct {
+		req           *pb.RangeRequest
+		expectedError error
+	}{
+		{req: &pb.RangeRequest{Key: []byte("a"), MaxStalenessMs: 500, MaxStalenessRevisions: 100}},
+		{req: &pb.RangeRequest{Key: []byte("a"), MaxStalenessMs: -1}, expectedError: rpctypes.ErrGRPCInvalidMaxStaleness},
+		{req: &pb.RangeRequest{Key: []byte("a"), MaxStalenessRevisions: -1}, expectedError: rpctypes.ErrGRPCInvalidMaxStaleness},
+	}
+	for _, tc := range tcs {
+		if err := checkRangeRequest(tc.req); getError(err) != getError(tc.expectedError) {
+			t.Errorf("checkRangeRequest(%v) = %q, want %q", tc.req, getError(err), getError(tc.expectedError))
+		}
+	}
+}
+
+func TestCheckTxnRequestReadSet(t *testing.T) {
+	tcs := []struct {
+		req           *pb.TxnRequest
+		expectedError error
+	}{
+		{req: &pb.TxnRequest{ReadSet: []*pb.ReadSetEntry{{Key: []byte("a"), ModRevision: 2}, {Key: []byte("b")}}}},
+		{req: &pb.TxnRequest{ReadSet: []*pb.ReadSetEntry{{ModRevision: 2}}}, expectedError: rpctypes.ErrGRPCEmptyKey},
+		{req: &pb.TxnRequest{ReadSet: []*pb.ReadSetEntry{{Key: []byte("a")}, {Key: []byte("b")}, {Key: []byte("c")}}}, expectedError: rpctypes.ErrGRPCTooManyOps},
+	}
+	for _, tc := range tcs {
+		if err := checkTxnRequest(tc.req, 2); getError(err) != getError(tc.expectedError) {
+			t.Errorf("checkTxnRequest(%v) = %q, want %q", tc.req, getError(err), getError(tc.expectedError))
+		}
+	}
+}
+
 func getError(err error) string {
 	if err == nil {
 		return ""
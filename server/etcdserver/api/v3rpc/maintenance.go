@@ -17,6 +17,7 @@ package v3rpc
 import (
 	"context"
 	"crypto/sha256"
+	"hash/crc32"
 	"io"
 	"time"
 
@@ -56,6 +57,16 @@ type Downgrader interface {
 	Downgrade(ctx context.Context, dr *pb.DowngradeRequest) (*pb.DowngradeResponse, error)
 }
 
+type KeyQuotaManager interface {
+	QuotaSet(ctx context.Context, r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error)
+	QuotaDelete(ctx context.Context, r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error)
+	QuotaList(ctx context.Context, r *pb.QuotaListRequest) (*pb.QuotaListResponse, error)
+}
+
+type EncryptionKeyRotator interface {
+	RotateEncryptionKey(ctx context.Context, rateLimitBytes int64) (uint32, error)
+}
+
 type LeaderTransferrer interface {
 	MoveLeader(ctx context.Context, lead, target uint64) error
 }
@@ -68,6 +79,7 @@ type maintenanceServer struct {
 	lg     *zap.Logger
 	rg     apply.RaftStatusGetter
 	hasher mvcc.HashStorage
+	kg     KVGetter
 	bg     BackendGetter
 	a      Alarmer
 	lt     LeaderTransferrer
@@ -75,19 +87,35 @@ type maintenanceServer struct {
 	cs     ClusterStatusGetter
 	d      Downgrader
 	vs     serverversion.Server
+	qm     KeyQuotaManager
+	er     EncryptionKeyRotator
+
+	snapshots *snapshotSessions
 }
 
 func NewMaintenanceServer(s *etcdserver.EtcdServer) pb.MaintenanceServer {
-	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, hasher: s.KV().HashStorage(), bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, vs: etcdserver.NewServerVersionAdapter(s)}
+	srv := &maintenanceServer{lg: s.Cfg.Logger, rg: s, hasher: s.KV().HashStorage(), kg: s, bg: s, a: s, lt: s, hdr: newHeader(s), cs: s, d: s, vs: etcdserver.NewServerVersionAdapter(s), qm: s, er: s}
 	if srv.lg == nil {
 		srv.lg = zap.NewNop()
 	}
+	srv.snapshots = newSnapshotSessions(srv.lg)
+	go func() {
+		// release the read transactions of the sessions before the backend
+		// is closed
+		<-s.StoppingNotify()
+		srv.snapshots.close()
+	}()
 	return &authMaintenanceServer{srv, &AuthAdmin{s}}
 }
 
 func (ms *maintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRequest) (*pb.DefragmentResponse, error) {
-	ms.lg.Info("starting defragment")
-	err := ms.bg.Backend().Defrag()
+	ms.lg.Info("starting defragment", zap.Bool("online", sr.Online), zap.Int64("rate-limit-bytes", sr.RateLimitBytes))
+	var err error
+	if sr.Online {
+		err = ms.bg.Backend().OnlineDefrag(ctx, backend.OnlineDefragConfig{RateLimitBytes: sr.RateLimitBytes})
+	} else {
+		err = ms.bg.Backend().Defrag()
+	}
 	if err != nil {
 		ms.lg.Warn("failed to defragment", zap.Error(err))
 		return nil, err
@@ -96,15 +124,26 @@ func (ms *maintenanceServer) Defragment(ctx context.Context, sr *pb.DefragmentRe
 	return &pb.DefragmentResponse{}, nil
 }
 
+func (ms *maintenanceServer) RotateEncryptionKey(ctx context.Context, r *pb.RotateEncryptionKeyRequest) (*pb.RotateEncryptionKeyResponse, error) {
+	id, err := ms.er.RotateEncryptionKey(ctx, r.RateLimitBytes)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp := &pb.RotateEncryptionKeyResponse{KeyId: id, Header: &pb.ResponseHeader{}}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 // big enough size to hold >1 OS pages in the buffer
 const snapshotSendBufferSize = 32 * 1024
 
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
 func (ms *maintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Maintenance_SnapshotServer) error {
-	ver := schema.ReadStorageVersion(ms.bg.Backend().ReadTx())
-	storageVersion := ""
-	if ver != nil {
-		storageVersion = ver.String()
+	if sr.Resumable || sr.SessionId != 0 {
+		return ms.resumableSnapshot(sr, srv)
 	}
+	storageVersion := ms.storageVersion()
 	snap := ms.bg.Backend().Snapshot()
 	pr, pw := io.Pipe()
 
@@ -187,6 +226,201 @@ func (ms *maintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Maintenance
 	return nil
 }
 
+func (ms *maintenanceServer) storageVersion() string {
+	ver := schema.ReadStorageVersion(ms.bg.Backend().ReadTx())
+	if ver == nil {
+		return ""
+	}
+	return ver.String()
+}
+
+// resumableSnapshot streams the snapshot of a resumable snapshot session from
+// the requested offset. Each message carries the offset and the CRC-32C
+// checksum of its blob, and the last one the SHA-256 digest of the snapshot.
+func (ms *maintenanceServer) resumableSnapshot(sr *pb.SnapshotRequest, srv pb.Maintenance_SnapshotServer) error {
+	var (
+		s   *snapshotSession
+		err error
+	)
+	if sr.SessionId == 0 {
+		snap := ms.bg.Backend().Snapshot()
+		if s, err = ms.snapshots.open(snap, ms.storageVersion()); err != nil {
+			snap.Close()
+			return err
+		}
+		ms.lg.Info("opened snapshot session",
+			zap.Int64("session-id", s.id),
+			zap.Int64("total-bytes", snap.Size()),
+			zap.String("storage-version", s.version),
+		)
+	} else if s, err = ms.snapshots.acquire(sr.SessionId); err != nil {
+		return err
+	}
+	defer ms.snapshots.release(s)
+
+	total := s.snap.Size()
+	if sr.Offset < 0 || sr.Offset > total {
+		return rpctypes.ErrGRPCInvalidSnapshotOffset
+	}
+
+	pr, pw := io.Pipe()
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		pw.CloseWithError(s.writeFrom(pw, sr.Offset))
+	}()
+	defer func() {
+		// the writer uses the session, so it must be done before the session
+		// is released
+		pr.Close()
+		<-donec
+	}()
+
+	start := time.Now()
+	ms.lg.Info("sending database snapshot of snapshot session to client",
+		zap.Int64("session-id", s.id),
+		zap.Int64("offset", sr.Offset),
+		zap.Int64("total-bytes", total),
+	)
+	offset := sr.Offset
+	for offset < total {
+		// NOTE: srv.Send does not wait until the message is received by the client.
+		// Therefore the buffer can not be safely reused between Send operations
+		buf := make([]byte, snapshotSendBufferSize)
+		n, err := io.ReadFull(pr, buf)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return togRPCError(err)
+		}
+		if n == 0 {
+			return togRPCError(io.ErrUnexpectedEOF)
+		}
+		resp := &pb.SnapshotResponse{
+			RemainingBytes: uint64(total - offset - int64(n)),
+			Blob:           buf[:n],
+			Version:        s.version,
+			SessionId:      s.id,
+			Offset:         offset,
+			Checksum:       crc32.Checksum(buf[:n], crc32cTable),
+			Size_:          total,
+		}
+		if err = srv.Send(resp); err != nil {
+			return togRPCError(err)
+		}
+		offset += int64(n)
+	}
+
+	// wait for the rest of the snapshot to be hashed
+	if _, err = io.Copy(io.Discard, pr); err != nil {
+		return togRPCError(err)
+	}
+	hresp := &pb.SnapshotResponse{
+		Version:   s.version,
+		SessionId: s.id,
+		Offset:    total,
+		Size_:     total,
+		Sha256:    s.sum(),
+	}
+	if err = srv.Send(hresp); err != nil {
+		return togRPCError(err)
+	}
+
+	ms.lg.Info("successfully sent database snapshot of snapshot session to client",
+		zap.Int64("session-id", s.id),
+		zap.Int64("sent-bytes", total-sr.Offset),
+		zap.Duration("took", time.Since(start)),
+	)
+	return nil
+}
+
+// snapshotDeltaSendSize is the size of the entries sent in one message of a
+// snapshot delta stream.
+const snapshotDeltaSendSize = 1024 * 1024
+
+// SnapshotDelta streams the key revisions after the base revision, followed
+// by the full content of the buckets whose changes are not versioned. The
+// first message carries the hashes of the key space at the base revision and
+// at the revision of the delta, which link the delta to its base on restore.
+func (ms *maintenanceServer) SnapshotDelta(r *pb.SnapshotDeltaRequest, srv pb.Maintenance_SnapshotDeltaServer) error {
+	// the hashes, the revisions and the full buckets all come from the same
+	// read transaction, so the delta is consistent with its hash; compactions
+	// after the transaction is opened are not seen by it
+	err := ms.hasher.ReadSince(r.BaseRevision, func(tx backend.ReadTx, baseHash, hash mvcc.KeyValueHash) error {
+		return ms.sendSnapshotDelta(tx, r.BaseRevision, baseHash, hash, srv)
+	})
+	if err != nil {
+		return togRPCError(err)
+	}
+	return nil
+}
+
+func (ms *maintenanceServer) sendSnapshotDelta(tx backend.ReadTx, baseRev int64, baseHash, hash mvcc.KeyValueHash, srv pb.Maintenance_SnapshotDeltaServer) error {
+	rev := hash.Revision
+	resp := &pb.SnapshotDeltaResponse{
+		Header:       &pb.ResponseHeader{Revision: rev},
+		BaseRevision: baseRev,
+		Revision:     rev,
+		BaseHash:     baseHash.Hash,
+		Hash:         hash.Hash,
+	}
+	ms.hdr.fill(resp.Header)
+	for _, b := range schema.DeltaFullBuckets {
+		resp.FullBuckets = append(resp.FullBuckets, b.Name())
+	}
+
+	start := time.Now()
+	ms.lg.Info("sending database snapshot delta to client",
+		zap.Int64("base-revision", baseRev),
+		zap.Int64("revision", rev),
+	)
+	var (
+		size  int
+		total int
+	)
+	send := func() error {
+		if err := srv.Send(resp); err != nil {
+			return err
+		}
+		resp = &pb.SnapshotDeltaResponse{}
+		size = 0
+		return nil
+	}
+	add := func(bucket, k, v []byte) error {
+		resp.Entries = append(resp.Entries, &pb.SnapshotDeltaEntry{Bucket: bucket, Key: k, Value: v})
+		size += len(k) + len(v)
+		total++
+		if size < snapshotDeltaSendSize {
+			return nil
+		}
+		return send()
+	}
+
+	err := mvcc.UnsafeForEachRevision(tx, baseRev, rev, func(k, v []byte) error {
+		return add(schema.Key.Name(), k, v)
+	})
+	for _, b := range schema.DeltaFullBuckets {
+		if err != nil {
+			break
+		}
+		err = tx.UnsafeForEach(b, func(k, v []byte) error {
+			return add(b.Name(), k, v)
+		})
+	}
+	if err != nil {
+		return err
+	}
+	if err := send(); err != nil {
+		return err
+	}
+
+	ms.lg.Info("successfully sent database snapshot delta to client",
+		zap.Int64("base-revision", baseRev),
+		zap.Int64("revision", rev),
+		zap.Int("entries", total),
+		zap.Duration("took", time.Since(start)),
+	)
+	return nil
+}
+
 func (ms *maintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (*pb.HashResponse, error) {
 	h, rev, err := ms.hasher.Hash()
 	if err != nil {
@@ -272,6 +506,37 @@ func (ms *maintenanceServer) Downgrade(ctx context.Context, r *pb.DowngradeReque
 	return resp, nil
 }
 
+func (ms *maintenanceServer) QuotaSet(ctx context.Context, r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error) {
+	if r.Quota == nil || len(r.Quota.Prefix) == 0 {
+		return nil, rpctypes.ErrGRPCKeyQuotaInvalid
+	}
+	resp, err := ms.qm.QuotaSet(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) QuotaDelete(ctx context.Context, r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error) {
+	resp, err := ms.qm.QuotaDelete(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
+func (ms *maintenanceServer) QuotaList(ctx context.Context, r *pb.QuotaListRequest) (*pb.QuotaListResponse, error) {
+	resp, err := ms.qm.QuotaList(ctx, r)
+	if err != nil {
+		return nil, togRPCError(err)
+	}
+	resp.Header = &pb.ResponseHeader{}
+	ms.hdr.fill(resp.Header)
+	return resp, nil
+}
+
 type authMaintenanceServer struct {
 	*maintenanceServer
 	*AuthAdmin
@@ -285,6 +550,14 @@ func (ams *authMaintenanceServer) Defragment(ctx context.Context, sr *pb.Defragm
 	return ams.maintenanceServer.Defragment(ctx, sr)
 }
 
+func (ams *authMaintenanceServer) RotateEncryptionKey(ctx context.Context, r *pb.RotateEncryptionKeyRequest) (*pb.RotateEncryptionKeyResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.RotateEncryptionKey(ctx, r)
+}
+
 func (ams *authMaintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Maintenance_SnapshotServer) error {
 	if err := ams.isPermitted(srv.Context()); err != nil {
 		return err
@@ -293,6 +566,14 @@ func (ams *authMaintenanceServer) Snapshot(sr *pb.SnapshotRequest, srv pb.Mainte
 	return ams.maintenanceServer.Snapshot(sr, srv)
 }
 
+func (ams *authMaintenanceServer) SnapshotDelta(r *pb.SnapshotDeltaRequest, srv pb.Maintenance_SnapshotDeltaServer) error {
+	if err := ams.isPermitted(srv.Context()); err != nil {
+		return err
+	}
+
+	return ams.maintenanceServer.SnapshotDelta(r, srv)
+}
+
 func (ams *authMaintenanceServer) Hash(ctx context.Context, r *pb.HashRequest) (*pb.HashResponse, error) {
 	if err := ams.isPermitted(ctx); err != nil {
 		return nil, err
@@ -331,3 +612,11 @@ func (ams *authMaintenanceServer) Downgrade(ctx context.Context, r *pb.Downgrade
 
 	return ams.maintenanceServer.Downgrade(ctx, r)
 }
+
+func (ams *authMaintenanceServer) QuotaList(ctx context.Context, r *pb.QuotaListRequest) (*pb.QuotaListResponse, error) {
+	if err := ams.isPermitted(ctx); err != nil {
+		return nil, err
+	}
+
+	return ams.maintenanceServer.QuotaList(ctx, r)
+}
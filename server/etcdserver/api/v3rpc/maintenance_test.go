@@ -0,0 +1,166 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+type fakeStorageGetter struct {
+	kv mvcc.WatchableKV
+	be backend.Backend
+}
+
+func (g *fakeStorageGetter) KV() mvcc.WatchableKV     { return g.kv }
+func (g *fakeStorageGetter) Backend() backend.Backend { return g.be }
+
+type fakeRaftStatusGetter struct{}
+
+func (fakeRaftStatusGetter) MemberId() types.ID     { return 1 }
+func (fakeRaftStatusGetter) Leader() types.ID       { return 1 }
+func (fakeRaftStatusGetter) CommittedIndex() uint64 { return 0 }
+func (fakeRaftStatusGetter) AppliedIndex() uint64   { return 0 }
+func (fakeRaftStatusGetter) Term() uint64           { return 1 }
+
+type fakeSnapshotDeltaServer struct {
+	grpc.ServerStream
+	resps []*pb.SnapshotDeltaResponse
+}
+
+func (s *fakeSnapshotDeltaServer) Context() context.Context { return context.Background() }
+
+func (s *fakeSnapshotDeltaServer) Send(resp *pb.SnapshotDeltaResponse) error {
+	s.resps = append(s.resps, resp)
+	return nil
+}
+
+func TestSnapshotDelta(t *testing.T) {
+	lg := zaptest.NewLogger(t)
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	kv := mvcc.New(lg, be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer kv.Close()
+
+	tx := be.BatchTx()
+	tx.Lock()
+	for _, b := range schema.DeltaFullBuckets {
+		tx.UnsafeCreateBucket(b)
+	}
+	tx.UnsafePut(schema.Lease, []byte("lease"), []byte("lease-value"))
+	tx.Unlock()
+
+	kv.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	kv.Put([]byte("baz"), []byte("bar"), lease.NoLease)
+	baseRev := kv.Rev()
+	kv.Put([]byte("foo"), []byte("bar2"), lease.NoLease)
+	kv.DeleteRange([]byte("baz"), nil)
+
+	g := &fakeStorageGetter{kv: kv, be: be}
+	ms := &maintenanceServer{
+		lg:     lg,
+		hasher: kv.HashStorage(),
+		kg:     g,
+		bg:     g,
+		hdr:    header{sg: fakeRaftStatusGetter{}, rev: kv.Rev},
+	}
+
+	srv := &fakeSnapshotDeltaServer{}
+	require.NoError(t, ms.SnapshotDelta(&pb.SnapshotDeltaRequest{BaseRevision: baseRev}, srv))
+	require.NotEmpty(t, srv.resps)
+
+	first := srv.resps[0]
+	assert.Equal(t, baseRev, first.BaseRevision)
+	assert.Equal(t, kv.Rev(), first.Revision)
+	baseHash, err := kv.HashStorage().HashSince(baseRev, baseRev)
+	require.NoError(t, err)
+	hash, err := kv.HashStorage().HashSince(baseRev, kv.Rev())
+	require.NoError(t, err)
+	assert.Equal(t, baseHash.Hash, first.BaseHash)
+	assert.Equal(t, hash.Hash, first.Hash)
+	assert.Len(t, first.FullBuckets, len(schema.DeltaFullBuckets))
+
+	var keys, leases int
+	for _, resp := range srv.resps {
+		for _, e := range resp.Entries {
+			switch {
+			case bytes.Equal(e.Bucket, schema.Key.Name()):
+				keys++
+			case bytes.Equal(e.Bucket, schema.Lease.Name()):
+				leases++
+				assert.Equal(t, []byte("lease-value"), e.Value)
+			}
+		}
+	}
+	assert.Equal(t, 2, keys)
+	assert.Equal(t, 1, leases)
+
+	err = ms.SnapshotDelta(&pb.SnapshotDeltaRequest{BaseRevision: kv.Rev() + 1}, &fakeSnapshotDeltaServer{})
+	assert.Equal(t, rpctypes.ErrGRPCFutureRev, err)
+
+	done, err := kv.Compact(traceutil.TODO(), baseRev+1)
+	require.NoError(t, err)
+	<-done
+	err = ms.SnapshotDelta(&pb.SnapshotDeltaRequest{BaseRevision: baseRev}, &fakeSnapshotDeltaServer{})
+	assert.Equal(t, rpctypes.ErrGRPCCompacted, err)
+}
+
+type fakeEncryptionKeyRotator struct {
+	id  uint32
+	err error
+}
+
+func (r *fakeEncryptionKeyRotator) RotateEncryptionKey(ctx context.Context, rateLimitBytes int64) (uint32, error) {
+	return r.id, r.err
+}
+
+func TestRotateEncryptionKey(t *testing.T) {
+	be, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, be)
+	kv := mvcc.New(zaptest.NewLogger(t), be, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer kv.Close()
+
+	r := &fakeEncryptionKeyRotator{id: 2}
+	ms := &maintenanceServer{
+		lg:  zaptest.NewLogger(t),
+		er:  r,
+		hdr: header{sg: fakeRaftStatusGetter{}, rev: kv.Rev},
+	}
+	resp, err := ms.RotateEncryptionKey(context.Background(), &pb.RotateEncryptionKeyRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), resp.KeyId)
+	assert.NotNil(t, resp.Header)
+
+	r.err = errors.ErrEncryptionDisabled
+	_, err = ms.RotateEncryptionKey(context.Background(), &pb.RotateEncryptionKeyRequest{})
+	assert.Equal(t, rpctypes.ErrGRPCEncryptionDisabled, err)
+}
@@ -46,7 +46,9 @@ func (cs *ClusterServer) MemberAdd(ctx context.Context, r *pb.MemberAddRequest)
 
 	now := time.Now()
 	var m *membership.Member
-	if r.IsLearner {
+	if r.IsObserver {
+		m = membership.NewMemberAsObserver("", urls, "", &now, types.ID(r.SnapshotSourceID))
+	} else if r.IsLearner {
 		m = membership.NewMemberAsLearner("", urls, "", &now)
 	} else {
 		m = membership.NewMember("", urls, "", &now)
@@ -59,9 +61,11 @@ func (cs *ClusterServer) MemberAdd(ctx context.Context, r *pb.MemberAddRequest)
 	return &pb.MemberAddResponse{
 		Header: cs.header(),
 		Member: &pb.Member{
-			ID:        uint64(m.ID),
-			PeerURLs:  m.PeerURLs,
-			IsLearner: m.IsLearner,
+			ID:               uint64(m.ID),
+			PeerURLs:         m.PeerURLs,
+			IsLearner:        m.IsLearner,
+			IsObserver:       m.IsObserver,
+			SnapshotSourceID: uint64(m.SnapshotSource),
 		},
 		Members: membersToProtoMembers(membs),
 	}, nil
@@ -113,11 +117,13 @@ func membersToProtoMembers(membs []*membership.Member) []*pb.Member {
 	protoMembs := make([]*pb.Member, len(membs))
 	for i := range membs {
 		protoMembs[i] = &pb.Member{
-			Name:       membs[i].Name,
-			ID:         uint64(membs[i].ID),
-			PeerURLs:   membs[i].PeerURLs,
-			ClientURLs: membs[i].ClientURLs,
-			IsLearner:  membs[i].IsLearner,
+			Name:             membs[i].Name,
+			ID:               uint64(membs[i].ID),
+			PeerURLs:         membs[i].PeerURLs,
+			ClientURLs:       membs[i].ClientURLs,
+			IsLearner:        membs[i].IsLearner,
+			IsObserver:       membs[i].IsObserver,
+			SnapshotSourceID: uint64(membs[i].SnapshotSource),
 		}
 	}
 	return protoMembs
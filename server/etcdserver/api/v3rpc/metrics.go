@@ -48,6 +48,13 @@ var (
 	},
 		[]string{"type", "client_api_version"},
 	)
+
+	coalescedEvents = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "watch_coalesced_events_total",
+		Help:      "The total number of watch events collapsed into a later event of the same key by coalescing watchers.",
+	})
 )
 
 func init() {
@@ -55,4 +62,5 @@ func init() {
 	prometheus.MustRegister(receivedBytes)
 	prometheus.MustRegister(streamFailures)
 	prometheus.MustRegister(clientRequests)
+	prometheus.MustRegister(coalescedEvents)
 }
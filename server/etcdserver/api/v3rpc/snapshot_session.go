@@ -0,0 +1,195 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"crypto/sha256"
+	"hash"
+	"io"
+	"math/rand"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+)
+
+// snapshotSessionTimeout is how long a resumable snapshot session is kept
+// without a stream. The session pins a read transaction of the backend, which
+// keeps the backend from reusing the pages freed since the snapshot.
+const snapshotSessionTimeout = time.Minute
+
+// snapshotSession is a resumable snapshot. Streams of the session write the
+// snapshot from the start and skip the bytes before their offset, since the
+// backend snapshot can only be written in full. The digest of the snapshot is
+// computed along the way by whichever stream gets furthest.
+type snapshotSession struct {
+	id      int64
+	snap    backend.Snapshot
+	version string
+	timer   *time.Timer
+
+	// mu is held by the stream of the session.
+	mu sync.Mutex
+	h  hash.Hash
+	// hashed is the number of bytes of the snapshot written to h.
+	hashed int64
+}
+
+// writeFrom writes the snapshot from offset to w.
+func (s *snapshotSession) writeFrom(w io.Writer, offset int64) error {
+	if offset == s.snap.Size() && s.hashed == offset {
+		return nil
+	}
+	_, err := s.snap.WriteTo(&sessionWriter{s: s, w: w, offset: offset})
+	return err
+}
+
+// sum returns the SHA-256 digest of the snapshot, once it was written in full.
+func (s *snapshotSession) sum() []byte {
+	return s.h.Sum(nil)
+}
+
+// sessionWriter passes the bytes of a snapshot from offset on to w, and
+// hashes the bytes its session did not hash yet.
+type sessionWriter struct {
+	s      *snapshotSession
+	w      io.Writer
+	pos    int64
+	offset int64
+}
+
+func (sw *sessionWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	start := sw.pos
+	sw.pos += int64(n)
+	if start <= sw.s.hashed && sw.pos > sw.s.hashed {
+		sw.s.h.Write(p[sw.s.hashed-start:])
+		sw.s.hashed = sw.pos
+	}
+	if sw.pos <= sw.offset {
+		return n, nil
+	}
+	if start < sw.offset {
+		p = p[sw.offset-start:]
+	}
+	if _, err := sw.w.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// snapshotSessions tracks the resumable snapshot sessions of a member.
+type snapshotSessions struct {
+	lg      *zap.Logger
+	timeout time.Duration
+
+	mu sync.Mutex
+	// rand generates session IDs, which must not repeat the IDs of sessions
+	// before a restart of the member.
+	rand     *rand.Rand
+	sessions map[int64]*snapshotSession
+	closed   bool
+}
+
+func newSnapshotSessions(lg *zap.Logger) *snapshotSessions {
+	return &snapshotSessions{
+		lg:       lg,
+		timeout:  snapshotSessionTimeout,
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		sessions: make(map[int64]*snapshotSession),
+	}
+}
+
+// open starts a session for snap and returns it locked.
+func (ss *snapshotSessions) open(snap backend.Snapshot, version string) (*snapshotSession, error) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	if ss.closed {
+		return nil, rpctypes.ErrGRPCStopped
+	}
+	s := &snapshotSession{snap: snap, version: version, h: sha256.New()}
+	for s.id == 0 || ss.sessions[s.id] != nil {
+		s.id = ss.rand.Int63()
+	}
+	s.mu.Lock()
+	// the timer is armed once the stream releases the session
+	s.timer = time.AfterFunc(ss.timeout, func() { ss.expire(s) })
+	s.timer.Stop()
+	ss.sessions[s.id] = s
+	return s, nil
+}
+
+// acquire returns the session with the given id locked.
+func (ss *snapshotSessions) acquire(id int64) (*snapshotSession, error) {
+	ss.mu.Lock()
+	s, ok := ss.sessions[id]
+	ss.mu.Unlock()
+	if !ok {
+		return nil, rpctypes.ErrGRPCSnapshotSessionNotFound
+	}
+	if !s.mu.TryLock() {
+		return nil, rpctypes.ErrGRPCSnapshotSessionBusy
+	}
+	s.timer.Stop()
+	return s, nil
+}
+
+// release unlocks the session, which expires if it is not acquired again
+// within the session timeout.
+func (ss *snapshotSessions) release(s *snapshotSession) {
+	s.timer.Reset(ss.timeout)
+	s.mu.Unlock()
+}
+
+// expire closes the session once no stream holds it.
+func (ss *snapshotSessions) expire(s *snapshotSession) {
+	ss.mu.Lock()
+	if ss.sessions[s.id] != s {
+		ss.mu.Unlock()
+		return
+	}
+	delete(ss.sessions, s.id)
+	ss.mu.Unlock()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.snap.Close(); err != nil {
+		ss.lg.Warn("failed to close snapshot of expired snapshot session", zap.Int64("session-id", s.id), zap.Error(err))
+		return
+	}
+	ss.lg.Info("closed expired snapshot session", zap.Int64("session-id", s.id))
+}
+
+// close closes all sessions, waiting for their streams to end. Sessions can
+// no longer be opened afterwards.
+func (ss *snapshotSessions) close() {
+	ss.mu.Lock()
+	ss.closed = true
+	sessions := ss.sessions
+	ss.sessions = make(map[int64]*snapshotSession)
+	ss.mu.Unlock()
+
+	for _, s := range sessions {
+		s.timer.Stop()
+		s.mu.Lock()
+		if err := s.snap.Close(); err != nil {
+			ss.lg.Warn("failed to close snapshot of snapshot session", zap.Int64("session-id", s.id), zap.Error(err))
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,134 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+)
+
+// fakeSnapshot writes data in chunks of 3 bytes.
+type fakeSnapshot struct {
+	data   []byte
+	closed bool
+}
+
+func (s *fakeSnapshot) Size() int64 { return int64(len(s.data)) }
+
+func (s *fakeSnapshot) WriteTo(w io.Writer) (int64, error) {
+	var n int64
+	for i := 0; i < len(s.data); i += 3 {
+		end := i + 3
+		if end > len(s.data) {
+			end = len(s.data)
+		}
+		m, err := w.Write(s.data[i:end])
+		n += int64(m)
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func (s *fakeSnapshot) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestSnapshotSessionWriteFrom(t *testing.T) {
+	data := []byte("0123456789abcdef")
+	ss := newSnapshotSessions(zaptest.NewLogger(t))
+	s, err := ss.open(&fakeSnapshot{data: data}, "3.6.0")
+	require.NoError(t, err)
+	defer ss.release(s)
+
+	// an interrupted stream hashes what it passed
+	var buf bytes.Buffer
+	err = s.writeFrom(&limitedWriter{w: &buf, n: 7}, 0)
+	assert.Equal(t, io.ErrShortBuffer, err)
+	assert.Equal(t, data[:7], buf.Bytes())
+
+	for _, offset := range []int64{4, 7, 16} {
+		buf.Reset()
+		require.NoError(t, s.writeFrom(&buf, offset))
+		assert.Equal(t, data[offset:], buf.Bytes())
+	}
+	sum := sha256.Sum256(data)
+	assert.Equal(t, sum[:], s.sum())
+}
+
+// limitedWriter fails once more than n bytes are written.
+type limitedWriter struct {
+	w io.Writer
+	n int
+}
+
+func (lw *limitedWriter) Write(p []byte) (int, error) {
+	if len(p) > lw.n {
+		lw.w.Write(p[:lw.n])
+		lw.n = 0
+		return 0, io.ErrShortBuffer
+	}
+	lw.n -= len(p)
+	return lw.w.Write(p)
+}
+
+func TestSnapshotSessions(t *testing.T) {
+	ss := newSnapshotSessions(zaptest.NewLogger(t))
+	ss.timeout = 50 * time.Millisecond
+	snap := &fakeSnapshot{data: []byte("0123")}
+	s, err := ss.open(snap, "")
+	require.NoError(t, err)
+
+	// a session is streamed by one stream at a time
+	_, err = ss.acquire(s.id)
+	assert.Equal(t, rpctypes.ErrGRPCSnapshotSessionBusy, err)
+	ss.release(s)
+
+	got, err := ss.acquire(s.id)
+	require.NoError(t, err)
+	assert.Same(t, s, got)
+	// sessions do not expire while streamed
+	time.Sleep(2 * ss.timeout)
+	assert.False(t, snap.closed)
+	ss.release(s)
+
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return snap.closed
+	}, time.Second, 10*time.Millisecond)
+	_, err = ss.acquire(s.id)
+	assert.Equal(t, rpctypes.ErrGRPCSnapshotSessionNotFound, err)
+
+	snap = &fakeSnapshot{data: []byte("0123")}
+	s, err = ss.open(snap, "")
+	require.NoError(t, err)
+	ss.release(s)
+	ss.close()
+	assert.True(t, snap.closed)
+	_, err = ss.open(&fakeSnapshot{}, "")
+	assert.Equal(t, rpctypes.ErrGRPCStopped, err)
+}
@@ -0,0 +1,273 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"bytes"
+	"context"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/etcdserver"
+)
+
+// newTenantUnaryInterceptor confines the requests of tenant users to the key
+// space of their tenant. Keys of requests are prefixed with the tenant prefix
+// before the request reaches the server, so the permission checks of the auth
+// store see the real keys, and the prefix is stripped from the responses.
+// Requests spanning the whole cluster, which cannot be confined to a tenant,
+// are rejected.
+func newTenantUnaryInterceptor(s *etcdserver.EtcdServer) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !s.AuthStore().HasTenantUsers() {
+			return handler(ctx, req)
+		}
+		authInfo, err := s.AuthInfoFromCtx(ctx)
+		if err != nil {
+			return nil, togRPCError(err)
+		}
+		prefix := s.AuthStore().TenantPrefix(authInfo)
+		if len(prefix) == 0 {
+			return handler(ctx, req)
+		}
+		if !isTenantRequest(req) {
+			return nil, rpctypes.ErrGRPCPermissionDenied
+		}
+
+		resp, err := handler(ctx, prefixRequest(prefix, req))
+		if err != nil {
+			return resp, err
+		}
+		return stripResponse(prefix, resp), nil
+	}
+}
+
+// newTenantStreamInterceptor confines the watches of tenant users to the key
+// space of their tenant.
+func newTenantStreamInterceptor(s *etcdserver.EtcdServer) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !s.AuthStore().HasTenantUsers() {
+			return handler(srv, ss)
+		}
+		authInfo, err := s.AuthInfoFromCtx(ss.Context())
+		if err != nil {
+			return togRPCError(err)
+		}
+		prefix := s.AuthStore().TenantPrefix(authInfo)
+		if len(prefix) == 0 {
+			return handler(srv, ss)
+		}
+		return handler(srv, &tenantServerStream{ServerStream: ss, prefix: prefix})
+	}
+}
+
+// globEscaper escapes the special characters of path.Match patterns.
+var globEscaper = strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`)
+
+type tenantServerStream struct {
+	grpc.ServerStream
+	prefix []byte
+}
+
+func (ts *tenantServerStream) RecvMsg(m interface{}) error {
+	if err := ts.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if wr, ok := m.(*pb.WatchRequest); ok {
+		if cr := wr.GetCreateRequest(); cr != nil {
+			cr.Key, cr.RangeEnd = auth.PrefixInterval(ts.prefix, cr.Key, cr.RangeEnd)
+			// key patterns match whole keys, so they are moved under the
+			// prefix too
+			if cr.KeyGlob != "" {
+				cr.KeyGlob = globEscaper.Replace(string(ts.prefix)) + cr.KeyGlob
+			}
+			if cr.KeyRegex != "" {
+				cr.KeyRegex = regexp.QuoteMeta(string(ts.prefix)) + `(?:` + cr.KeyRegex + `)`
+			}
+		}
+	}
+	return nil
+}
+
+func (ts *tenantServerStream) SendMsg(m interface{}) error {
+	if wr, ok := m.(*pb.WatchResponse); ok && len(wr.Events) > 0 {
+		// the events are shared with the other watchers of the same keys
+		resp := *wr
+		resp.Events = make([]*mvccpb.Event, len(wr.Events))
+		for i, ev := range wr.Events {
+			e := *ev
+			e.Kv = stripKeyValue(ts.prefix, ev.Kv)
+			e.PrevKv = stripKeyValue(ts.prefix, ev.PrevKv)
+			resp.Events[i] = &e
+		}
+		m = &resp
+	}
+	return ts.ServerStream.SendMsg(m)
+}
+
+// isTenantRequest returns false for the requests that act on the whole
+// cluster rather than on keys: listing every lease of the cluster and
+// compacting the history of every tenant.
+func isTenantRequest(req interface{}) bool {
+	switch req.(type) {
+	case *pb.LeaseLeasesRequest, *pb.CompactionRequest:
+		return false
+	}
+	return true
+}
+
+// prefixRequest returns a copy of the request whose keys are moved under
+// prefix. Requests without keys are returned as is.
+func prefixRequest(prefix []byte, req interface{}) interface{} {
+	switch r := req.(type) {
+	case *pb.RangeRequest:
+		nr := *r
+		nr.Key, nr.RangeEnd = auth.PrefixInterval(prefix, r.Key, r.RangeEnd)
+		return &nr
+	case *pb.PutRequest:
+		nr := *r
+		nr.Key, _ = auth.PrefixInterval(prefix, r.Key, nil)
+		return &nr
+	case *pb.DeleteRangeRequest:
+		nr := *r
+		nr.Key, nr.RangeEnd = auth.PrefixInterval(prefix, r.Key, r.RangeEnd)
+		return &nr
+	case *pb.TxnRequest:
+		return prefixTxnRequest(prefix, r)
+	case *pb.QuotaSetRequest:
+		if r.Quota == nil {
+			return req
+		}
+		nq := *r.Quota
+		nq.Prefix, _ = auth.PrefixInterval(prefix, r.Quota.Prefix, nil)
+		return &pb.QuotaSetRequest{Quota: &nq}
+	case *pb.QuotaDeleteRequest:
+		nr := *r
+		nr.Prefix, _ = auth.PrefixInterval(prefix, r.Prefix, nil)
+		return &nr
+	}
+	return req
+}
+
+func prefixTxnRequest(prefix []byte, r *pb.TxnRequest) *pb.TxnRequest {
+	nr := &pb.TxnRequest{
+		Compare: make([]*pb.Compare, len(r.Compare)),
+		Success: prefixRequestOps(prefix, r.Success),
+		Failure: prefixRequestOps(prefix, r.Failure),
+	}
+	for i, c := range r.Compare {
+		nc := *c
+		nc.Key, nc.RangeEnd = auth.PrefixInterval(prefix, c.Key, c.RangeEnd)
+		nr.Compare[i] = &nc
+	}
+	if r.ReadSet != nil {
+		nr.ReadSet = make([]*pb.ReadSetEntry, len(r.ReadSet))
+		for i, e := range r.ReadSet {
+			ne := *e
+			ne.Key, _ = auth.PrefixInterval(prefix, e.Key, nil)
+			nr.ReadSet[i] = &ne
+		}
+	}
+	return nr
+}
+
+func prefixRequestOps(prefix []byte, ops []*pb.RequestOp) []*pb.RequestOp {
+	if ops == nil {
+		return nil
+	}
+	nops := make([]*pb.RequestOp, len(ops))
+	for i, op := range ops {
+		switch tv := op.Request.(type) {
+		case *pb.RequestOp_RequestRange:
+			nops[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestRange{
+				RequestRange: prefixRequest(prefix, tv.RequestRange).(*pb.RangeRequest)}}
+		case *pb.RequestOp_RequestPut:
+			nops[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestPut{
+				RequestPut: prefixRequest(prefix, tv.RequestPut).(*pb.PutRequest)}}
+		case *pb.RequestOp_RequestDeleteRange:
+			nops[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestDeleteRange{
+				RequestDeleteRange: prefixRequest(prefix, tv.RequestDeleteRange).(*pb.DeleteRangeRequest)}}
+		case *pb.RequestOp_RequestTxn:
+			nops[i] = &pb.RequestOp{Request: &pb.RequestOp_RequestTxn{
+				RequestTxn: prefixTxnRequest(prefix, tv.RequestTxn)}}
+		default:
+			nops[i] = op
+		}
+	}
+	return nops
+}
+
+// stripResponse removes prefix from the keys of the response. The key-values
+// of the response are copied rather than modified in place.
+func stripResponse(prefix []byte, resp interface{}) interface{} {
+	switch r := resp.(type) {
+	case *pb.RangeResponse:
+		r.Kvs = stripKeyValues(prefix, r.Kvs)
+	case *pb.PutResponse:
+		r.PrevKv = stripKeyValue(prefix, r.PrevKv)
+	case *pb.DeleteRangeResponse:
+		r.PrevKvs = stripKeyValues(prefix, r.PrevKvs)
+	case *pb.TxnResponse:
+		r.Conflicts = stripKeyValues(prefix, r.Conflicts)
+		for _, op := range r.Responses {
+			switch tv := op.Response.(type) {
+			case *pb.ResponseOp_ResponseRange:
+				stripResponse(prefix, tv.ResponseRange)
+			case *pb.ResponseOp_ResponsePut:
+				stripResponse(prefix, tv.ResponsePut)
+			case *pb.ResponseOp_ResponseDeleteRange:
+				stripResponse(prefix, tv.ResponseDeleteRange)
+			case *pb.ResponseOp_ResponseTxn:
+				stripResponse(prefix, tv.ResponseTxn)
+			}
+		}
+	case *pb.LeaseTimeToLiveResponse:
+		// a lease may be attached to keys of other tenants
+		var keys [][]byte
+		for _, k := range r.Keys {
+			if bytes.HasPrefix(k, prefix) {
+				keys = append(keys, k[len(prefix):])
+			}
+		}
+		r.Keys = keys
+	}
+	return resp
+}
+
+func stripKeyValues(prefix []byte, kvs []*mvccpb.KeyValue) []*mvccpb.KeyValue {
+	if kvs == nil {
+		return nil
+	}
+	nkvs := make([]*mvccpb.KeyValue, len(kvs))
+	for i, kv := range kvs {
+		nkvs[i] = stripKeyValue(prefix, kv)
+	}
+	return nkvs
+}
+
+func stripKeyValue(prefix []byte, kv *mvccpb.KeyValue) *mvccpb.KeyValue {
+	if kv == nil {
+		return nil
+	}
+	nkv := *kv
+	nkv.Key = bytes.TrimPrefix(kv.Key, prefix)
+	return &nkv
+}
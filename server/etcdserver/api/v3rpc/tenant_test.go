@@ -0,0 +1,105 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func TestPrefixTxnRequest(t *testing.T) {
+	prefix := []byte("/t/")
+	req := &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("a"), RangeEnd: []byte("b")}},
+		ReadSet: []*pb.ReadSetEntry{{Key: []byte("a"), ModRevision: 2}, {Key: []byte("b")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("a"), Value: []byte("v")}}},
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Failure: []*pb.RequestOp{
+					{Request: &pb.RequestOp_RequestRange{RequestRange: &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte{0}}}},
+				},
+			}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("c")}}},
+		},
+	}
+
+	got := prefixRequest(prefix, req).(*pb.TxnRequest)
+	assert.Equal(t, &pb.TxnRequest{
+		Compare: []*pb.Compare{{Key: []byte("/t/a"), RangeEnd: []byte("/t/b")}},
+		ReadSet: []*pb.ReadSetEntry{{Key: []byte("/t/a"), ModRevision: 2}, {Key: []byte("/t/b")}},
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("/t/a"), Value: []byte("v")}}},
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Compare: []*pb.Compare{},
+				Failure: []*pb.RequestOp{
+					{Request: &pb.RequestOp_RequestRange{RequestRange: &pb.RangeRequest{Key: []byte("/t/a"), RangeEnd: []byte("/t0")}}},
+				},
+			}}},
+		},
+		Failure: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("/t/c")}}},
+		},
+	}, got)
+
+	// the original request is left untouched
+	assert.Equal(t, []byte("a"), req.Compare[0].Key)
+	assert.Equal(t, []byte("a"), req.Success[0].GetRequestPut().Key)
+}
+
+func TestPrefixQuotaRequest(t *testing.T) {
+	prefix := []byte("/t/")
+	set := &pb.QuotaSetRequest{Quota: &pb.KeyQuota{Prefix: []byte("a/"), MaxKeys: 10}}
+	assert.Equal(t, &pb.QuotaSetRequest{Quota: &pb.KeyQuota{Prefix: []byte("/t/a/"), MaxKeys: 10}}, prefixRequest(prefix, set))
+	assert.Equal(t, []byte("a/"), set.Quota.Prefix)
+
+	del := &pb.QuotaDeleteRequest{Prefix: []byte("a/")}
+	assert.Equal(t, &pb.QuotaDeleteRequest{Prefix: []byte("/t/a/")}, prefixRequest(prefix, del))
+}
+
+func TestIsTenantRequest(t *testing.T) {
+	assert.True(t, isTenantRequest(&pb.RangeRequest{}))
+	assert.True(t, isTenantRequest(&pb.LeaseGrantRequest{}))
+	assert.False(t, isTenantRequest(&pb.LeaseLeasesRequest{}))
+	assert.False(t, isTenantRequest(&pb.CompactionRequest{}))
+}
+
+func TestStripResponse(t *testing.T) {
+	prefix := []byte("/t/")
+	kv := &mvccpb.KeyValue{Key: []byte("/t/a"), Value: []byte("v")}
+	resp := &pb.TxnResponse{
+		Responses: []*pb.ResponseOp{
+			{Response: &pb.ResponseOp_ResponseRange{ResponseRange: &pb.RangeResponse{Kvs: []*mvccpb.KeyValue{kv}}}},
+			{Response: &pb.ResponseOp_ResponsePut{ResponsePut: &pb.PutResponse{PrevKv: kv}}},
+		},
+		Conflicts: []*mvccpb.KeyValue{kv},
+	}
+
+	stripResponse(prefix, resp)
+	assert.Equal(t, []byte("a"), resp.Responses[0].GetResponseRange().Kvs[0].Key)
+	assert.Equal(t, []byte("a"), resp.Responses[1].GetResponsePut().PrevKv.Key)
+	assert.Equal(t, []byte("a"), resp.Conflicts[0].Key)
+	// key-values may be shared, so they must not be modified
+	assert.Equal(t, []byte("/t/a"), kv.Key)
+
+	ttl := &pb.LeaseTimeToLiveResponse{Keys: [][]byte{[]byte("/t/a"), []byte("/u/b")}}
+	stripResponse(prefix, ttl)
+	assert.Equal(t, [][]byte{[]byte("a")}, ttl.Keys)
+}
@@ -32,14 +32,18 @@ import (
 )
 
 var toGRPCErrorMap = map[error]error{
-	membership.ErrIDRemoved:           rpctypes.ErrGRPCMemberNotFound,
-	membership.ErrIDNotFound:          rpctypes.ErrGRPCMemberNotFound,
-	membership.ErrIDExists:            rpctypes.ErrGRPCMemberExist,
-	membership.ErrPeerURLexists:       rpctypes.ErrGRPCPeerURLExist,
-	membership.ErrMemberNotLearner:    rpctypes.ErrGRPCMemberNotLearner,
-	membership.ErrTooManyLearners:     rpctypes.ErrGRPCTooManyLearners,
-	errors.ErrNotEnoughStartedMembers: rpctypes.ErrMemberNotEnoughStarted,
-	errors.ErrLearnerNotReady:         rpctypes.ErrGRPCLearnerNotReady,
+	membership.ErrIDRemoved:             rpctypes.ErrGRPCMemberNotFound,
+	membership.ErrIDNotFound:            rpctypes.ErrGRPCMemberNotFound,
+	membership.ErrIDExists:              rpctypes.ErrGRPCMemberExist,
+	membership.ErrPeerURLexists:         rpctypes.ErrGRPCPeerURLExist,
+	membership.ErrMemberNotLearner:      rpctypes.ErrGRPCMemberNotLearner,
+	membership.ErrTooManyLearners:       rpctypes.ErrGRPCTooManyLearners,
+	membership.ErrObserverNotPromotable: rpctypes.ErrGRPCObserverNotPromotable,
+	membership.ErrTooManyObservers:      rpctypes.ErrGRPCTooManyObservers,
+	membership.ErrObserverNotLearner:    rpctypes.ErrGRPCObserverNotLearner,
+	membership.ErrInvalidSnapshotSource: rpctypes.ErrGRPCInvalidSnapshotSource,
+	errors.ErrNotEnoughStartedMembers:   rpctypes.ErrMemberNotEnoughStarted,
+	errors.ErrLearnerNotReady:           rpctypes.ErrGRPCLearnerNotReady,
 
 	mvcc.ErrCompacted:         rpctypes.ErrGRPCCompacted,
 	mvcc.ErrFutureRev:         rpctypes.ErrGRPCFutureRev,
@@ -59,6 +63,13 @@ var toGRPCErrorMap = map[error]error{
 	errors.ErrKeyNotFound:                rpctypes.ErrGRPCKeyNotFound,
 	errors.ErrCorrupt:                    rpctypes.ErrGRPCCorrupt,
 	errors.ErrBadLeaderTransferee:        rpctypes.ErrGRPCBadLeaderTransferee,
+	errors.ErrKeyQuotaExceeded:           rpctypes.ErrGRPCKeyQuotaExceeded,
+	errors.ErrKeyQuotaNotFound:           rpctypes.ErrGRPCKeyQuotaNotFound,
+	errors.ErrKeyQuotaInvalid:            rpctypes.ErrGRPCKeyQuotaInvalid,
+	errors.ErrInvalidContinueToken:       rpctypes.ErrGRPCInvalidContinueToken,
+	errors.ErrEncryptionDisabled:         rpctypes.ErrGRPCEncryptionDisabled,
+	errors.ErrReadSetNotSupported:        rpctypes.ErrGRPCReadSetNotSupported,
+	errors.ErrKeyTTLNotSupported:         rpctypes.ErrGRPCKeyTTLNotSupported,
 
 	errors.ErrClusterVersionUnavailable:      rpctypes.ErrGRPCClusterVersionUnavailable,
 	errors.ErrWrongDowngradeVersionFormat:    rpctypes.ErrGRPCWrongDowngradeVersionFormat,
@@ -87,6 +98,13 @@ var toGRPCErrorMap = map[error]error{
 	auth.ErrInvalidAuthToken:     rpctypes.ErrGRPCInvalidAuthToken,
 	auth.ErrInvalidAuthMgmt:      rpctypes.ErrGRPCInvalidAuthMgmt,
 	auth.ErrAuthOldRevision:      rpctypes.ErrGRPCAuthOldRevision,
+	auth.ErrTenantEmpty:          rpctypes.ErrGRPCTenantEmpty,
+	auth.ErrTenantPrefixEmpty:    rpctypes.ErrGRPCTenantPrefixEmpty,
+	auth.ErrTenantAlreadyExist:   rpctypes.ErrGRPCTenantAlreadyExist,
+	auth.ErrTenantNotFound:       rpctypes.ErrGRPCTenantNotFound,
+	auth.ErrTenantPrefixConflict: rpctypes.ErrGRPCTenantPrefixConflict,
+	auth.ErrTenantNotEmpty:       rpctypes.ErrGRPCTenantNotEmpty,
+	auth.ErrTenantMismatch:       rpctypes.ErrGRPCTenantMismatch,
 
 	// In sync with status.FromContextError
 	context.Canceled:         rpctypes.ErrGRPCCanceled,
@@ -137,13 +155,19 @@ func isClientCtxErr(ctxErr error, err error) bool {
 	return false
 }
 
-// in v3.4, learner is allowed to serve serializable read and endpoint status
+// learner does not support stream RPC except Snapshot, observers also serve watches
+func isStreamRPCSupportedForLearner(method string, isObserver bool) bool {
+	return method == snapshotMethod || (isObserver && method == watchMethod)
+}
+
+// in v3.4, learner is allowed to serve serializable read and endpoint status,
+// and in v3.6 read with bounded staleness
 func isRPCSupportedForLearner(req interface{}) bool {
 	switch r := req.(type) {
 	case *pb.StatusRequest:
 		return true
 	case *pb.RangeRequest:
-		return r.Serializable
+		return r.Serializable || r.MaxStalenessMs > 0 || r.MaxStalenessRevisions > 0
 	default:
 		return false
 	}
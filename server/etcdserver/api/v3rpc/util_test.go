@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"google.golang.org/grpc/codes"
@@ -33,6 +34,7 @@ func TestGRPCError(t *testing.T) {
 	}{
 		{err: mvcc.ErrCompacted, exp: rpctypes.ErrGRPCCompacted},
 		{err: mvcc.ErrFutureRev, exp: rpctypes.ErrGRPCFutureRev},
+		{err: membership.ErrObserverNotPromotable, exp: rpctypes.ErrGRPCObserverNotPromotable},
 		{err: context.Canceled, exp: context.Canceled},
 		{err: context.DeadlineExceeded, exp: context.DeadlineExceeded},
 		{err: errors.New("foo"), exp: status.Error(codes.Unknown, "foo")},
@@ -48,3 +50,21 @@ func TestGRPCError(t *testing.T) {
 		}
 	}
 }
+
+func TestIsStreamRPCSupportedForLearner(t *testing.T) {
+	tt := []struct {
+		method     string
+		isObserver bool
+		exp        bool
+	}{
+		{method: snapshotMethod, exp: true},
+		{method: watchMethod, exp: false},
+		{method: watchMethod, isObserver: true, exp: true},
+		{method: "/etcdserverpb.Lease/LeaseKeepAlive", isObserver: true, exp: false},
+	}
+	for i := range tt {
+		if got := isStreamRPCSupportedForLearner(tt[i].method, tt[i].isObserver); got != tt[i].exp {
+			t.Errorf("#%d: got %v, expected %v", i, got, tt[i].exp)
+		}
+	}
+}
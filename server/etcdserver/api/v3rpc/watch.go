@@ -15,9 +15,12 @@
 package v3rpc
 
 import (
+	"bytes"
 	"context"
 	"io"
 	"math/rand"
+	"path"
+	"regexp"
 	"sync"
 	"time"
 
@@ -29,6 +32,7 @@ import (
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver"
 	"go.etcd.io/etcd/server/v3/etcdserver/apply"
+	"go.etcd.io/etcd/server/v3/etcdserver/txn"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"go.uber.org/zap"
@@ -135,7 +139,7 @@ type serverWatchStream struct {
 	watchStream mvcc.WatchStream
 	ctrlStream  chan *pb.WatchResponse
 
-	// mu protects progress, prevKV, fragment
+	// mu protects progress, prevKV, fragment, omitUnchanged, coalesce
 	mu sync.RWMutex
 	// tracks the watchID that stream might need to send progress to
 	// TODO: combine progress and prevKV into a single struct?
@@ -144,6 +148,10 @@ type serverWatchStream struct {
 	prevKV map[mvcc.WatchID]bool
 	// records fragmented watch IDs
 	fragment map[mvcc.WatchID]bool
+	// records watch IDs omitting the values of puts that do not change them
+	omitUnchanged map[mvcc.WatchID]bool
+	// records the coalescing windows of coalescing watch IDs
+	coalesce map[mvcc.WatchID]time.Duration
 
 	// closec indicates the stream is closed.
 	closec chan struct{}
@@ -174,6 +182,9 @@ func (ws *watchServer) Watch(stream pb.Watch_WatchServer) (err error) {
 		prevKV:   make(map[mvcc.WatchID]bool),
 		fragment: make(map[mvcc.WatchID]bool),
 
+		omitUnchanged: make(map[mvcc.WatchID]bool),
+		coalesce:      make(map[mvcc.WatchID]time.Duration),
+
 		closec: make(chan struct{}),
 	}
 
@@ -232,10 +243,10 @@ func (sws *serverWatchStream) isWatchPermitted(wcr *pb.WatchCreateRequest) error
 		return err
 	}
 	if authInfo == nil {
-		// if auth is enabled, IsRangePermitted() can cause an error
+		// if auth is enabled, IsWatchPermitted() can cause an error
 		authInfo = &auth.AuthInfo{}
 	}
-	return sws.ag.AuthStore().IsRangePermitted(authInfo, wcr.Key, wcr.RangeEnd)
+	return sws.ag.AuthStore().IsWatchPermitted(authInfo, wcr.Key, wcr.RangeEnd)
 }
 
 func (sws *serverWatchStream) recvLoop() error {
@@ -302,6 +313,23 @@ func (sws *serverWatchStream) recvLoop() error {
 				}
 			}
 
+			if err := CheckWatchCreateRequest(creq); err != nil {
+				wr := &pb.WatchResponse{
+					Header:       sws.newResponseHeader(sws.watchStream.Rev()),
+					WatchId:      clientv3.InvalidWatchID,
+					Canceled:     true,
+					Created:      true,
+					CancelReason: err.Error(),
+				}
+
+				select {
+				case sws.ctrlStream <- wr:
+					continue
+				case <-sws.closec:
+					return nil
+				}
+			}
+
 			filters := FiltersFromRequest(creq)
 
 			wsrev := sws.watchStream.Rev()
@@ -321,6 +349,12 @@ func (sws *serverWatchStream) recvLoop() error {
 				if creq.Fragment {
 					sws.fragment[id] = true
 				}
+				if creq.OmitUnchangedValue {
+					sws.omitUnchanged[id] = true
+				}
+				if creq.CoalesceWindowMs > 0 {
+					sws.coalesce[id] = time.Duration(creq.CoalesceWindowMs) * time.Millisecond
+				}
 				sws.mu.Unlock()
 			} else {
 				id = clientv3.InvalidWatchID
@@ -355,6 +389,8 @@ func (sws *serverWatchStream) recvLoop() error {
 					delete(sws.progress, mvcc.WatchID(id))
 					delete(sws.prevKV, mvcc.WatchID(id))
 					delete(sws.fragment, mvcc.WatchID(id))
+					delete(sws.omitUnchanged, mvcc.WatchID(id))
+					delete(sws.coalesce, mvcc.WatchID(id))
 					sws.mu.Unlock()
 				}
 			}
@@ -384,8 +420,45 @@ func (sws *serverWatchStream) sendLoop() {
 	interval := GetProgressReportInterval()
 	progressTicker := time.NewTicker(interval)
 
+	// events of coalescing watchers waiting for their window to end
+	coalescers := make(map[mvcc.WatchID]*EventCoalescer)
+	var coalesceTimer *time.Timer
+	var coalesceC <-chan time.Time
+	// armCoalesceTimer arms the timer for the earliest window to end
+	armCoalesceTimer := func() {
+		if coalesceTimer != nil {
+			coalesceTimer.Stop()
+		}
+		coalesceTimer, coalesceC = nil, nil
+		var next time.Time
+		for _, c := range coalescers {
+			if !c.Empty() && (next.IsZero() || c.deadline.Before(next)) {
+				next = c.deadline
+			}
+		}
+		if !next.IsZero() {
+			coalesceTimer = time.NewTimer(time.Until(next))
+			coalesceC = coalesceTimer.C
+		}
+	}
+	flushCoalesced := func(id mvcc.WatchID) bool {
+		c, ok := coalescers[id]
+		if !ok || c.Empty() {
+			return true
+		}
+		events, rev := c.Flush()
+		return sws.sendWatchResponse(&pb.WatchResponse{
+			Header:  sws.newResponseHeader(rev),
+			WatchId: int64(id),
+			Events:  events,
+		})
+	}
+
 	defer func() {
 		progressTicker.Stop()
+		if coalesceTimer != nil {
+			coalesceTimer.Stop()
+		}
 		// drain the chan to clean up pending events
 		for ws := range sws.watchStream.Chan() {
 			mvcc.ReportEventReceived(len(ws.Events))
@@ -411,16 +484,23 @@ func (sws *serverWatchStream) sendLoop() {
 			events := make([]*mvccpb.Event, len(evs))
 			sws.mu.RLock()
 			needPrevKV := sws.prevKV[wresp.WatchID]
+			omitUnchanged := sws.omitUnchanged[wresp.WatchID]
 			sws.mu.RUnlock()
 			for i := range evs {
 				events[i] = &evs[i]
-				if needPrevKV && !IsCreateEvent(evs[i]) {
+				if (needPrevKV || omitUnchanged) && !IsCreateEvent(evs[i]) {
 					opt := mvcc.RangeOptions{Rev: evs[i].Kv.ModRevision - 1}
 					r, err := sws.watchable.Range(context.TODO(), evs[i].Kv.Key, nil, opt)
 					if err == nil && len(r.KVs) != 0 {
 						events[i].PrevKv = &(r.KVs[0])
 					}
 				}
+				if omitUnchanged {
+					events[i] = OmitUnchangedValue(events[i])
+					if !needPrevKV {
+						events[i].PrevKv = nil
+					}
+				}
 			}
 
 			canceled := wresp.CompactRevision != 0
@@ -442,38 +522,48 @@ func (sws *serverWatchStream) sendLoop() {
 			mvcc.ReportEventReceived(len(evs))
 
 			sws.mu.RLock()
-			fragmented, ok := sws.fragment[wresp.WatchID]
+			window := sws.coalesce[wresp.WatchID]
 			sws.mu.RUnlock()
-
-			var serr error
-			if !fragmented && !ok {
-				serr = sws.gRPCStream.Send(wr)
-			} else {
-				serr = sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
-			}
-
-			if serr != nil {
-				if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
-					sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
-				} else {
-					sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
-					streamFailures.WithLabelValues("send", "watch").Inc()
+			if window > 0 {
+				c, ok := coalescers[wresp.WatchID]
+				if !ok {
+					c = NewEventCoalescer(window)
+					coalescers[wresp.WatchID] = c
+				}
+				if len(events) > 0 && !canceled {
+					wasEmpty := c.Empty()
+					c.Add(time.Now(), wresp.Revision, events)
+					if wasEmpty {
+						armCoalesceTimer()
+					}
+					continue
+				}
+				// progress notifications and cancellations must not
+				// overtake the buffered events
+				if !flushCoalesced(wresp.WatchID) {
+					return
 				}
-				return
 			}
 
-			sws.mu.Lock()
-			if len(evs) > 0 && sws.progress[wresp.WatchID] {
-				// elide next progress update if sent a key update
-				sws.progress[wresp.WatchID] = false
+			if !sws.sendWatchResponse(wr) {
+				return
 			}
-			sws.mu.Unlock()
 
 		case c, ok := <-sws.ctrlStream:
 			if !ok {
 				return
 			}
 
+			if c.WatchId == clientv3.InvalidWatchID && !c.Created {
+				// progress responses of the stream must not overtake the
+				// buffered events of coalescing watchers
+				for id := range coalescers {
+					if !flushCoalesced(id) {
+						return
+					}
+				}
+			}
+
 			if err := sws.gRPCStream.Send(c); err != nil {
 				if isClientCtxErr(sws.gRPCStream.Context().Err(), err) {
 					sws.lg.Debug("failed to send watch control response to gRPC stream", zap.Error(err))
@@ -491,6 +581,7 @@ func (sws *serverWatchStream) sendLoop() {
 
 			if c.Canceled && wid != clientv3.InvalidWatchID {
 				delete(ids, wid)
+				delete(coalescers, wid)
 				continue
 			}
 			if c.Created {
@@ -511,6 +602,17 @@ func (sws *serverWatchStream) sendLoop() {
 				delete(pending, wid)
 			}
 
+		case <-coalesceC:
+			now := time.Now()
+			for id, c := range coalescers {
+				if !c.Empty() && !c.deadline.After(now) {
+					if !flushCoalesced(id) {
+						return
+					}
+				}
+			}
+			armCoalesceTimer()
+
 		case <-progressTicker.C:
 			sws.mu.Lock()
 			for id, ok := range sws.progress {
@@ -527,6 +629,41 @@ func (sws *serverWatchStream) sendLoop() {
 	}
 }
 
+// sendWatchResponse sends a watch response of an announced watcher, in
+// fragments if the watcher asked for it. It returns false if the stream
+// failed.
+func (sws *serverWatchStream) sendWatchResponse(wr *pb.WatchResponse) bool {
+	id := mvcc.WatchID(wr.WatchId)
+	sws.mu.RLock()
+	fragmented, ok := sws.fragment[id]
+	sws.mu.RUnlock()
+
+	var serr error
+	if !fragmented && !ok {
+		serr = sws.gRPCStream.Send(wr)
+	} else {
+		serr = sendFragments(wr, sws.maxRequestBytes, sws.gRPCStream.Send)
+	}
+
+	if serr != nil {
+		if isClientCtxErr(sws.gRPCStream.Context().Err(), serr) {
+			sws.lg.Debug("failed to send watch response to gRPC stream", zap.Error(serr))
+		} else {
+			sws.lg.Warn("failed to send watch response to gRPC stream", zap.Error(serr))
+			streamFailures.WithLabelValues("send", "watch").Inc()
+		}
+		return false
+	}
+
+	sws.mu.Lock()
+	if len(wr.Events) > 0 && sws.progress[id] {
+		// elide next progress update if sent a key update
+		sws.progress[id] = false
+	}
+	sws.mu.Unlock()
+	return true
+}
+
 func IsCreateEvent(e mvccpb.Event) bool {
 	return e.Type == mvccpb.PUT && e.Kv.CreateRevision == e.Kv.ModRevision
 }
@@ -593,9 +730,63 @@ func filterNoPut(e mvccpb.Event) bool {
 	return e.Type == mvccpb.PUT
 }
 
+func filterAll(e mvccpb.Event) bool {
+	return true
+}
+
+func newKeyGlobFilter(pattern string) mvcc.FilterFunc {
+	return func(e mvccpb.Event) bool {
+		ok, err := path.Match(pattern, string(e.Kv.Key))
+		return err != nil || !ok
+	}
+}
+
+func newKeyRegexFilter(re *regexp.Regexp) mvcc.FilterFunc {
+	return func(e mvccpb.Event) bool {
+		return !re.Match(e.Kv.Key)
+	}
+}
+
+func newKVFilter(match func(kv *mvccpb.KeyValue) bool) mvcc.FilterFunc {
+	return func(e mvccpb.Event) bool {
+		return e.Type == mvccpb.PUT && !match(e.Kv)
+	}
+}
+
+// compileKeyRegex compiles the key_regex of a watch create request, which
+// must match the whole key.
+func compileKeyRegex(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile(`^(?:` + expr + `)$`)
+}
+
+// CheckWatchCreateRequest returns an error if the key patterns, the key-value
+// filters or the coalescing window of the watch create request are invalid.
+func CheckWatchCreateRequest(creq *pb.WatchCreateRequest) error {
+	if creq.KeyGlob != "" {
+		if _, err := path.Match(creq.KeyGlob, ""); err != nil {
+			return rpctypes.ErrGRPCInvalidWatchFilter
+		}
+	}
+	if creq.KeyRegex != "" {
+		if _, err := compileKeyRegex(creq.KeyRegex); err != nil {
+			return rpctypes.ErrGRPCInvalidWatchFilter
+		}
+	}
+	for _, f := range creq.KvFilters {
+		if f == nil || txn.CheckRangeFilter(f) != nil {
+			return rpctypes.ErrGRPCInvalidWatchFilter
+		}
+	}
+	if creq.CoalesceWindowMs < 0 || time.Duration(creq.CoalesceWindowMs)*time.Millisecond > maxCoalesceWindow {
+		return rpctypes.ErrGRPCInvalidCoalesceWindow
+	}
+	return nil
+}
+
 // FiltersFromRequest returns "mvcc.FilterFunc" from a given watch create request.
+// Malformed key patterns filter out all events; see CheckWatchCreateRequest.
 func FiltersFromRequest(creq *pb.WatchCreateRequest) []mvcc.FilterFunc {
-	filters := make([]mvcc.FilterFunc, 0, len(creq.Filters))
+	filters := make([]mvcc.FilterFunc, 0, len(creq.Filters)+3)
 	for _, ft := range creq.Filters {
 		switch ft {
 		case pb.WatchCreateRequest_NOPUT:
@@ -605,5 +796,34 @@ func FiltersFromRequest(creq *pb.WatchCreateRequest) []mvcc.FilterFunc {
 		default:
 		}
 	}
+	if creq.KeyGlob != "" {
+		filters = append(filters, newKeyGlobFilter(creq.KeyGlob))
+	}
+	if creq.KeyRegex != "" {
+		re, err := compileKeyRegex(creq.KeyRegex)
+		if err != nil {
+			filters = append(filters, filterAll)
+		} else {
+			filters = append(filters, newKeyRegexFilter(re))
+		}
+	}
+	if match := txn.NewRangeFilter(creq.KvFilters); match != nil {
+		filters = append(filters, newKVFilter(match))
+	}
 	return filters
 }
+
+// OmitUnchangedValue returns a copy of the event without values if it is a
+// put that did not change the value of its previous key-value. Other events,
+// and events without previous key-value, are returned as is.
+func OmitUnchangedValue(ev *mvccpb.Event) *mvccpb.Event {
+	if ev.Type != mvccpb.PUT || ev.PrevKv == nil || !bytes.Equal(ev.Kv.Value, ev.PrevKv.Value) {
+		return ev
+	}
+	kv, prevKV := *ev.Kv, *ev.PrevKv
+	kv.Value, prevKV.Value = nil, nil
+	nev := *ev
+	nev.Kv, nev.PrevKv = &kv, &prevKV
+	nev.UnchangedValue = true
+	return &nev
+}
@@ -0,0 +1,105 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// maxCoalesceWindow bounds how long events of a coalescing watcher are held
+// back.
+const maxCoalesceWindow = 10 * time.Second
+
+// EventCoalescer buffers the events of a watcher, keeping only the latest
+// event of each key, until they are flushed. It is not safe for concurrent
+// use.
+type EventCoalescer struct {
+	window time.Duration
+	// deadline is when the buffered events are due.
+	deadline time.Time
+	// rev is the revision of the last response merged into the buffer.
+	rev int64
+
+	// events holds the buffered events in revision order. Events replaced by
+	// a later event of the same key are set to nil.
+	events []*mvccpb.Event
+	// index maps keys to the position of their event in events.
+	index map[string]int
+}
+
+func NewEventCoalescer(window time.Duration) *EventCoalescer {
+	return &EventCoalescer{window: window, index: make(map[string]int)}
+}
+
+func (c *EventCoalescer) Empty() bool { return len(c.events) == 0 }
+
+// Add merges the events of a watch response at revision rev.
+func (c *EventCoalescer) Add(now time.Time, rev int64, events []*mvccpb.Event) {
+	if len(events) == 0 {
+		return
+	}
+	if c.Empty() {
+		c.deadline = now.Add(c.window)
+	}
+	c.rev = rev
+	for _, ev := range events {
+		key := string(ev.Kv.Key)
+		if i, ok := c.index[key]; ok {
+			ev = coalesceEvents(c.events[i], ev)
+			c.events[i] = nil
+			coalescedEvents.Inc()
+		}
+		c.index[key] = len(c.events)
+		c.events = append(c.events, ev)
+	}
+}
+
+// Flush returns the buffered events and the revision they are current at,
+// and empties the buffer.
+func (c *EventCoalescer) Flush() ([]*mvccpb.Event, int64) {
+	events := make([]*mvccpb.Event, 0, len(c.index))
+	for _, ev := range c.events {
+		if ev != nil {
+			events = append(events, ev)
+		}
+	}
+	c.events = nil
+	c.index = make(map[string]int)
+	return events, c.rev
+}
+
+// coalesceEvents returns the event covering the changes of a key from prev
+// through ev.
+func coalesceEvents(prev, ev *mvccpb.Event) *mvccpb.Event {
+	nev := *ev
+	nev.CoalescedStartRevision = prev.CoalescedStartRevision
+	if nev.CoalescedStartRevision == 0 {
+		nev.CoalescedStartRevision = prev.Kv.ModRevision
+	}
+	// the previous key-value is the one before the first change
+	nev.PrevKv = prev.PrevKv
+	if ev.UnchangedValue && !prev.UnchangedValue {
+		// the value was changed within the window, so it cannot be omitted
+		nev.UnchangedValue = false
+		if prev.Type == mvccpb.PUT {
+			kv := *ev.Kv
+			kv.Value = prev.Kv.Value
+			nev.Kv = &kv
+		}
+	}
+	return &nev
+}
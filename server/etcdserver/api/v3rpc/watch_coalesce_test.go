@@ -0,0 +1,84 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v3rpc
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+func putEvent(key, value string, rev int64) *mvccpb.Event {
+	return &mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte(key), Value: []byte(value), ModRevision: rev}}
+}
+
+func TestEventCoalescer(t *testing.T) {
+	now := time.Now()
+	c := NewEventCoalescer(time.Second)
+	assert.True(t, c.Empty())
+
+	c.Add(now, 2, []*mvccpb.Event{putEvent("a", "1", 2)})
+	assert.Equal(t, now.Add(time.Second), c.deadline)
+	c.Add(now.Add(time.Millisecond), 3, []*mvccpb.Event{putEvent("b", "1", 3)})
+	c.Add(now.Add(2*time.Millisecond), 5, []*mvccpb.Event{
+		putEvent("a", "2", 4),
+		{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("c"), ModRevision: 5}},
+	})
+	// the deadline is set by the first buffered event
+	assert.Equal(t, now.Add(time.Second), c.deadline)
+
+	events, rev := c.Flush()
+	assert.Equal(t, int64(5), rev)
+	assert.True(t, c.Empty())
+	// events are ordered by their last change
+	assert.Equal(t, []*mvccpb.Event{
+		putEvent("b", "1", 3),
+		{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("2"), ModRevision: 4}, CoalescedStartRevision: 2},
+		{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte("c"), ModRevision: 5}},
+	}, events)
+
+	// a flushed coalescer starts a new window
+	c.Add(now.Add(2*time.Second), 6, []*mvccpb.Event{putEvent("a", "3", 6)})
+	assert.Equal(t, now.Add(3*time.Second), c.deadline)
+	events, _ = c.Flush()
+	assert.Equal(t, []*mvccpb.Event{putEvent("a", "3", 6)}, events)
+}
+
+func TestCoalesceEvents(t *testing.T) {
+	prevKV := &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("0"), ModRevision: 1}
+	first := &mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("1"), ModRevision: 2}, PrevKv: prevKV}
+	second := &mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("2"), ModRevision: 3}}
+	third := &mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), ModRevision: 4}, UnchangedValue: true}
+
+	ev := coalesceEvents(coalesceEvents(first, second), third)
+	assert.Equal(t, int64(2), ev.CoalescedStartRevision)
+	assert.Same(t, prevKV, ev.PrevKv)
+	// the value changed within the window, so it is restored
+	assert.False(t, ev.UnchangedValue)
+	assert.Equal(t, []byte("2"), ev.Kv.Value)
+	assert.Equal(t, int64(4), ev.Kv.ModRevision)
+	// the coalesced events are left untouched
+	assert.Nil(t, third.Kv.Value)
+	assert.True(t, third.UnchangedValue)
+
+	// an unchanged value stays omitted if it never changed
+	unchanged := &mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte("a"), ModRevision: 2}, UnchangedValue: true}
+	ev = coalesceEvents(unchanged, third)
+	assert.True(t, ev.UnchangedValue)
+	assert.Nil(t, ev.Kv.Value)
+}
@@ -19,8 +19,11 @@ import (
 	"math"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 )
 
 func TestSendFragment(t *testing.T) {
@@ -93,3 +96,118 @@ func createResponse(dataSize, events int) (resp *pb.WatchResponse) {
 	}
 	return resp
 }
+
+func TestFiltersFromRequest(t *testing.T) {
+	put := func(key string, version int64, value string) mvccpb.Event {
+		return mvccpb.Event{Type: mvccpb.PUT, Kv: &mvccpb.KeyValue{Key: []byte(key), Version: version, Value: []byte(value)}}
+	}
+	del := func(key string) mvccpb.Event {
+		return mvccpb.Event{Type: mvccpb.DELETE, Kv: &mvccpb.KeyValue{Key: []byte(key)}}
+	}
+	created := &pb.RangeFilter{Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 1}}
+	ready := &pb.RangeFilter{Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte("true")}, JsonPath: "ready"}
+
+	tcs := []struct {
+		name   string
+		creq   *pb.WatchCreateRequest
+		passed []mvccpb.Event
+		failed []mvccpb.Event
+	}{
+		{
+			name:   "no put",
+			creq:   &pb.WatchCreateRequest{Filters: []pb.WatchCreateRequest_FilterType{pb.WatchCreateRequest_NOPUT}},
+			passed: []mvccpb.Event{del("a")},
+			failed: []mvccpb.Event{put("a", 1, "")},
+		},
+		{
+			name:   "key glob",
+			creq:   &pb.WatchCreateRequest{KeyGlob: "/pods/*/status"},
+			passed: []mvccpb.Event{put("/pods/p1/status", 1, ""), del("/pods/p2/status")},
+			failed: []mvccpb.Event{put("/pods/p1/spec", 1, ""), put("/pods/p1/status/x", 1, "")},
+		},
+		{
+			name:   "key regex",
+			creq:   &pb.WatchCreateRequest{KeyRegex: "/pods/p[0-9]+"},
+			passed: []mvccpb.Event{put("/pods/p1", 1, ""), del("/pods/p22")},
+			failed: []mvccpb.Event{put("/pods/p1/status", 1, ""), put("/x/pods/p1", 1, "")},
+		},
+		{
+			name:   "creations only",
+			creq:   &pb.WatchCreateRequest{KvFilters: []*pb.RangeFilter{created}, Filters: []pb.WatchCreateRequest_FilterType{pb.WatchCreateRequest_NODELETE}},
+			passed: []mvccpb.Event{put("a", 1, "")},
+			failed: []mvccpb.Event{put("a", 2, ""), del("a")},
+		},
+		{
+			name:   "value predicate",
+			creq:   &pb.WatchCreateRequest{KvFilters: []*pb.RangeFilter{ready}},
+			passed: []mvccpb.Event{put("a", 2, `{"ready":true}`), del("a")},
+			failed: []mvccpb.Event{put("a", 2, `{"ready":false}`), put("a", 2, "foo")},
+		},
+		{
+			name:   "malformed regex",
+			creq:   &pb.WatchCreateRequest{KeyRegex: "("},
+			failed: []mvccpb.Event{put("a", 1, ""), del("a")},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			filters := FiltersFromRequest(tc.creq)
+			filtered := func(ev mvccpb.Event) bool {
+				for _, f := range filters {
+					if f(ev) {
+						return true
+					}
+				}
+				return false
+			}
+			for _, ev := range tc.passed {
+				assert.False(t, filtered(ev), "%s should pass", ev.Kv.Key)
+			}
+			for _, ev := range tc.failed {
+				assert.True(t, filtered(ev), "%s should be filtered out", ev.Kv.Key)
+			}
+		})
+	}
+}
+
+func TestCheckWatchCreateRequest(t *testing.T) {
+	tcs := []struct {
+		creq    *pb.WatchCreateRequest
+		wantErr error
+	}{
+		{creq: &pb.WatchCreateRequest{KeyGlob: "/a/*", KeyRegex: "/a/.*"}},
+		{creq: &pb.WatchCreateRequest{KeyGlob: "/a/["}, wantErr: rpctypes.ErrGRPCInvalidWatchFilter},
+		{creq: &pb.WatchCreateRequest{KeyRegex: "/a/("}, wantErr: rpctypes.ErrGRPCInvalidWatchFilter},
+		{
+			creq:    &pb.WatchCreateRequest{KvFilters: []*pb.RangeFilter{{Target: pb.RangeFilter_LEASE, TargetUnion: &pb.RangeFilter_Version{Version: 1}}}},
+			wantErr: rpctypes.ErrGRPCInvalidWatchFilter,
+		},
+		{creq: &pb.WatchCreateRequest{CoalesceWindowMs: 10000}},
+		{creq: &pb.WatchCreateRequest{CoalesceWindowMs: -1}, wantErr: rpctypes.ErrGRPCInvalidCoalesceWindow},
+		{creq: &pb.WatchCreateRequest{CoalesceWindowMs: 10001}, wantErr: rpctypes.ErrGRPCInvalidCoalesceWindow},
+	}
+	for _, tc := range tcs {
+		assert.Equal(t, tc.wantErr, CheckWatchCreateRequest(tc.creq), "%+v", tc.creq)
+	}
+}
+
+func TestOmitUnchangedValue(t *testing.T) {
+	kv := &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("v"), Lease: 2}
+	prevKV := &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("v"), Lease: 1}
+	ev := &mvccpb.Event{Type: mvccpb.PUT, Kv: kv, PrevKv: prevKV}
+
+	got := OmitUnchangedValue(ev)
+	assert.True(t, got.UnchangedValue)
+	assert.Nil(t, got.Kv.Value)
+	assert.Nil(t, got.PrevKv.Value)
+	assert.Equal(t, int64(2), got.Kv.Lease)
+	// the key-values may be shared with other watchers
+	assert.Equal(t, []byte("v"), kv.Value)
+	assert.Equal(t, []byte("v"), prevKV.Value)
+	assert.False(t, ev.UnchangedValue)
+
+	changed := &mvccpb.Event{Type: mvccpb.PUT, Kv: kv, PrevKv: &mvccpb.KeyValue{Key: []byte("a"), Value: []byte("w")}}
+	assert.Same(t, changed, OmitUnchangedValue(changed))
+	created := &mvccpb.Event{Type: mvccpb.PUT, Kv: kv}
+	assert.Same(t, created, OmitUnchangedValue(created))
+}
@@ -27,6 +27,7 @@ import (
 	"go.etcd.io/etcd/server/v3/etcdserver/api"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3quota"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	"go.etcd.io/etcd/server/v3/etcdserver/errors"
 	mvcctxn "go.etcd.io/etcd/server/v3/etcdserver/txn"
@@ -84,6 +85,9 @@ type applierV3 interface {
 
 	Alarm(*pb.AlarmRequest) (*pb.AlarmResponse, error)
 
+	QuotaSet(qs *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error)
+	QuotaDelete(qd *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error)
+
 	Authenticate(r *pb.InternalAuthenticateRequest) (*pb.AuthenticateResponse, error)
 
 	AuthEnable() (*pb.AuthEnableResponse, error)
@@ -103,6 +107,9 @@ type applierV3 interface {
 	RoleDelete(ua *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 	UserList(ua *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ua *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
+	TenantAdd(ua *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error)
+	TenantDelete(ua *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error)
+	TenantList(ua *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error)
 
 	// processing internal V3 raft request
 
@@ -119,6 +126,7 @@ type applierV3backend struct {
 	lg              *zap.Logger
 	kv              mvcc.KV
 	alarmStore      *v3alarm.AlarmStore
+	quotaStore      *v3quota.QuotaStore
 	authStore       auth.AuthStore
 	lessor          lease.Lessor
 	cluster         *membership.RaftCluster
@@ -133,6 +141,7 @@ func newApplierV3Backend(
 	lg *zap.Logger,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	quotaStore *v3quota.QuotaStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
@@ -144,6 +153,7 @@ func newApplierV3Backend(
 		lg:                           lg,
 		kv:                           kv,
 		alarmStore:                   alarmStore,
+		quotaStore:                   quotaStore,
 		authStore:                    authStore,
 		lessor:                       lessor,
 		cluster:                      cluster,
@@ -246,6 +256,23 @@ func (a *applierV3backend) Alarm(ar *pb.AlarmRequest) (*pb.AlarmResponse, error)
 	return resp, nil
 }
 
+func (a *applierV3backend) QuotaSet(r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error) {
+	if r.Quota == nil {
+		return nil, errors.ErrKeyQuotaInvalid
+	}
+	if err := a.quotaStore.Set(r.Quota); err != nil {
+		return nil, err
+	}
+	return &pb.QuotaSetResponse{Header: a.newHeader()}, nil
+}
+
+func (a *applierV3backend) QuotaDelete(r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error) {
+	if err := a.quotaStore.Delete(r.Prefix); err != nil {
+		return nil, err
+	}
+	return &pb.QuotaDeleteResponse{Header: a.newHeader()}, nil
+}
+
 type applierV3Capped struct {
 	applierV3
 	q serverstorage.BackendQuota
@@ -402,6 +429,30 @@ func (a *applierV3backend) RoleList(r *pb.AuthRoleListRequest) (*pb.AuthRoleList
 	return resp, err
 }
 
+func (a *applierV3backend) TenantAdd(r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error) {
+	resp, err := a.authStore.TenantAdd(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) TenantDelete(r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error) {
+	resp, err := a.authStore.TenantDelete(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
+func (a *applierV3backend) TenantList(r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error) {
+	resp, err := a.authStore.TenantList(r)
+	if resp != nil {
+		resp.Header = a.newHeader()
+	}
+	return resp, err
+}
+
 func (a *applierV3backend) ClusterVersionSet(r *membershippb.ClusterVersionSetRequest, shouldApplyV3 membership.ShouldApplyV3) {
 	prevVersion := a.cluster.Version()
 	newVersion := semver.Must(semver.NewVersion(r.Ver))
@@ -70,6 +70,12 @@ func (aa *authApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, r *pb.PutRe
 		return nil, nil, err
 	}
 
+	if r.Lease != 0 {
+		if err := aa.as.IsLeasePermitted(&aa.authInfo, r.Key); err != nil {
+			return nil, nil, err
+		}
+	}
+
 	if err := aa.checkLeasePuts(lease.LeaseID(r.Lease)); err != nil {
 		// The specified lease is already attached with a key that cannot
 		// be written by this user. It means the user cannot revoke the
@@ -122,11 +128,42 @@ func (aa *authApplierV3) LeaseRevoke(lc *pb.LeaseRevokeRequest) (*pb.LeaseRevoke
 	return aa.applierV3.LeaseRevoke(lc)
 }
 
+func (aa *authApplierV3) Compaction(compaction *pb.CompactionRequest) (*pb.CompactionResponse, <-chan struct{}, *traceutil.Trace, error) {
+	if err := aa.as.IsCompactPermitted(&aa.authInfo); err != nil {
+		return nil, nil, nil, err
+	}
+	return aa.applierV3.Compaction(compaction)
+}
+
+func (aa *authApplierV3) QuotaSet(r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error) {
+	var prefix []byte
+	if r.Quota != nil {
+		prefix = r.Quota.Prefix
+	}
+	if err := aa.checkPrefixAdmin(prefix); err != nil {
+		return nil, err
+	}
+	return aa.applierV3.QuotaSet(r)
+}
+
+func (aa *authApplierV3) QuotaDelete(r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error) {
+	if err := aa.checkPrefixAdmin(r.Prefix); err != nil {
+		return nil, err
+	}
+	return aa.applierV3.QuotaDelete(r)
+}
+
+// checkPrefixAdmin checks admin permission on all keys under prefix.
+func (aa *authApplierV3) checkPrefixAdmin(prefix []byte) error {
+	key, rangeEnd := auth.PrefixInterval(prefix, nil, []byte{0})
+	return aa.as.IsAdminRangePermitted(&aa.authInfo, key, rangeEnd)
+}
+
 func (aa *authApplierV3) checkLeasePuts(leaseID lease.LeaseID) error {
 	l := aa.lessor.Lookup(leaseID)
 	if l != nil {
 		for _, key := range l.Keys() {
-			if err := aa.as.IsPutPermitted(&aa.authInfo, []byte(key)); err != nil {
+			if err := aa.as.IsLeasePermitted(&aa.authInfo, []byte(key)); err != nil {
 				return err
 			}
 		}
@@ -187,6 +224,12 @@ func needAdminPermission(r *pb.InternalRaftRequest) bool {
 		return true
 	case r.AuthRoleList != nil:
 		return true
+	case r.AuthTenantAdd != nil:
+		return true
+	case r.AuthTenantDelete != nil:
+		return true
+	case r.AuthTenantList != nil:
+		return true
 	default:
 		return false
 	}
@@ -0,0 +1,96 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package apply
+
+import (
+	"context"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3quota"
+	mvcctxn "go.etcd.io/etcd/server/v3/etcdserver/txn"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+type keyQuotaApplierV3 struct {
+	applierV3
+	kv mvcc.KV
+	qs *v3quota.QuotaStore
+}
+
+// newKeyQuotaApplierV3 creates an applyV3 that rejects Puts and transactions
+// with Puts that would grow a key prefix beyond its quota.
+func newKeyQuotaApplierV3(kv mvcc.KV, qs *v3quota.QuotaStore, app applierV3) applierV3 {
+	return &keyQuotaApplierV3{applierV3: app, kv: kv, qs: qs}
+}
+
+func (a *keyQuotaApplierV3) Put(ctx context.Context, txn mvcc.TxnWrite, p *pb.PutRequest) (*pb.PutResponse, *traceutil.Trace, error) {
+	if a.qs.Tracked(p.Key) {
+		if err := a.qs.Check(putCharge(a.kv, p)); err != nil {
+			return nil, nil, err
+		}
+	}
+	return a.applierV3.Put(ctx, txn, p)
+}
+
+func (a *keyQuotaApplierV3) Txn(ctx context.Context, rt *pb.TxnRequest) (*pb.TxnResponse, *traceutil.Trace, error) {
+	if !mvcctxn.IsTxnReadonly(rt) {
+		if err := a.checkTxn(rt); err != nil {
+			return nil, nil, err
+		}
+	}
+	return a.applierV3.Txn(ctx, rt)
+}
+
+// checkTxn charges every put the txn executes against the state before the
+// txn. Deletes in the same txn are not credited, so the check is conservative.
+func (a *keyQuotaApplierV3) checkTxn(rt *pb.TxnRequest) error {
+	txnRead := a.kv.Read(mvcc.ConcurrentReadTxMode, traceutil.TODO())
+	defer txnRead.End()
+
+	// only the last put to a key determines its size after the txn
+	charged := make(map[string]int)
+	var charges []v3quota.Charge
+	for _, p := range mvcctxn.TxnPuts(txnRead, rt) {
+		if !a.qs.Tracked(p.Key) {
+			continue
+		}
+		c := putCharge(txnRead, p)
+		if i, ok := charged[string(p.Key)]; ok {
+			charges[i] = c
+			continue
+		}
+		charged[string(p.Key)] = len(charges)
+		charges = append(charges, c)
+	}
+	if len(charges) == 0 {
+		return nil
+	}
+	return a.qs.Check(charges...)
+}
+
+// putCharge computes how the put would change the usage of its key. Puts
+// with IgnoreValue keep the size of the key and are charged nothing; if the
+// key is missing the backend applier rejects them.
+func putCharge(rv mvcc.ReadView, p *pb.PutRequest) v3quota.Charge {
+	rr, err := rv.Range(context.TODO(), p.Key, nil, mvcc.RangeOptions{})
+	if err != nil || p.IgnoreValue {
+		return v3quota.Charge{Key: p.Key}
+	}
+	if len(rr.KVs) == 0 {
+		return v3quota.Charge{Key: p.Key, Bytes: int64(len(p.Key) + len(p.Value)), Keys: 1}
+	}
+	return v3quota.Charge{Key: p.Key, Bytes: int64(len(p.Value) - len(rr.KVs[0].Value))}
+}
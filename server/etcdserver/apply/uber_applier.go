@@ -24,6 +24,7 @@ import (
 	"go.etcd.io/etcd/server/v3/auth"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3quota"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	"go.etcd.io/etcd/server/v3/etcdserver/txn"
 	"go.etcd.io/etcd/server/v3/lease"
@@ -53,6 +54,7 @@ func NewUberApplier(
 	be backend.Backend,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	quotaStore *v3quota.QuotaStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
@@ -62,7 +64,7 @@ func NewUberApplier(
 	warningApplyDuration time.Duration,
 	txnModeWriteWithSharedBuffer bool,
 	quotaBackendBytesCfg int64) UberApplier {
-	applyV3base_ := newApplierV3(lg, be, kv, alarmStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer, quotaBackendBytesCfg)
+	applyV3base_ := newApplierV3(lg, be, kv, alarmStore, quotaStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer, quotaBackendBytesCfg)
 
 	ua := &uberApplier{
 		lg:                   lg,
@@ -80,6 +82,7 @@ func newApplierV3(
 	be backend.Backend,
 	kv mvcc.KV,
 	alarmStore *v3alarm.AlarmStore,
+	quotaStore *v3quota.QuotaStore,
 	authStore auth.AuthStore,
 	lessor lease.Lessor,
 	cluster *membership.RaftCluster,
@@ -88,10 +91,10 @@ func newApplierV3(
 	consistentIndex cindex.ConsistentIndexer,
 	txnModeWriteWithSharedBuffer bool,
 	quotaBackendBytesCfg int64) applierV3 {
-	applierBackend := newApplierV3Backend(lg, kv, alarmStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer)
+	applierBackend := newApplierV3Backend(lg, kv, alarmStore, quotaStore, authStore, lessor, cluster, raftStatus, snapshotServer, consistentIndex, txnModeWriteWithSharedBuffer)
 	return newAuthApplierV3(
 		authStore,
-		newQuotaApplierV3(lg, quotaBackendBytesCfg, be, applierBackend),
+		newQuotaApplierV3(lg, quotaBackendBytesCfg, be, newKeyQuotaApplierV3(kv, quotaStore, applierBackend)),
 		lessor,
 	)
 }
@@ -110,7 +113,7 @@ func (a *uberApplier) restoreAlarms() {
 
 func (a *uberApplier) Apply(r *pb.InternalRaftRequest, shouldApplyV3 membership.ShouldApplyV3) *Result {
 	// We first execute chain of Apply() calls down the hierarchy:
-	// (i.e. CorruptApplier -> CappedApplier -> Auth -> Quota -> Backend),
+	// (i.e. CorruptApplier -> CappedApplier -> Auth -> Quota -> KeyQuota -> Backend),
 	// then dispatch() unpacks the request to a specific method (like Put),
 	// that gets executed down the hierarchy again:
 	// i.e. CorruptApplier.Put(CappedApplier.Put(...(BackendApplier.Put(...)))).
@@ -178,6 +181,12 @@ func (a *uberApplier) dispatch(ctx context.Context, r *pb.InternalRaftRequest, s
 	case r.Alarm != nil:
 		op = "Alarm"
 		ar.Resp, ar.Err = a.Alarm(r.Alarm)
+	case r.QuotaSet != nil:
+		op = "QuotaSet"
+		ar.Resp, ar.Err = a.applyV3.QuotaSet(r.QuotaSet)
+	case r.QuotaDelete != nil:
+		op = "QuotaDelete"
+		ar.Resp, ar.Err = a.applyV3.QuotaDelete(r.QuotaDelete)
 	case r.Authenticate != nil:
 		op = "Authenticate"
 		ar.Resp, ar.Err = a.applyV3.Authenticate(r.Authenticate)
@@ -228,6 +237,15 @@ func (a *uberApplier) dispatch(ctx context.Context, r *pb.InternalRaftRequest, s
 	case r.AuthRoleList != nil:
 		op = "AuthRoleList"
 		ar.Resp, ar.Err = a.applyV3.RoleList(r.AuthRoleList)
+	case r.AuthTenantAdd != nil:
+		op = "AuthTenantAdd"
+		ar.Resp, ar.Err = a.applyV3.TenantAdd(r.AuthTenantAdd)
+	case r.AuthTenantDelete != nil:
+		op = "AuthTenantDelete"
+		ar.Resp, ar.Err = a.applyV3.TenantDelete(r.AuthTenantDelete)
+	case r.AuthTenantList != nil:
+		op = "AuthTenantList"
+		ar.Resp, ar.Err = a.applyV3.TenantList(r.AuthTenantList)
 	default:
 		a.lg.Panic("not implemented apply", zap.Stringer("raft-request", r))
 	}
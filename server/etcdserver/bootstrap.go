@@ -199,7 +199,9 @@ func bootstrapSnapshot(cfg config.ServerConfig) *snap.Snapshotter {
 			zap.Error(err),
 		)
 	}
-	return snap.New(cfg.Logger, cfg.SnapDir())
+	ss := snap.New(cfg.Logger, cfg.SnapDir())
+	ss.SetCipher(cfg.Cipher())
+	return ss
 }
 
 func bootstrapBackend(cfg config.ServerConfig, haveWAL bool, st v2store.Store, ss *snap.Snapshotter) (backend *bootstrappedBackend, err error) {
@@ -289,7 +291,7 @@ func bootstrapExistingClusterNoWAL(cfg config.ServerConfig, prt http.RoundTrippe
 	if err := cfg.VerifyJoinExisting(); err != nil {
 		return nil, err
 	}
-	cl, err := membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, cfg.InitialPeerURLsMap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners))
+	cl, err := membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, cfg.InitialPeerURLsMap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners), membership.WithMaxObservers(cfg.ExperimentalMaxObservers))
 	if err != nil {
 		return nil, err
 	}
@@ -321,7 +323,7 @@ func bootstrapNewClusterNoWAL(cfg config.ServerConfig, prt http.RoundTripper) (*
 	if err := cfg.VerifyBootstrap(); err != nil {
 		return nil, err
 	}
-	cl, err := membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, cfg.InitialPeerURLsMap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners))
+	cl, err := membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, cfg.InitialPeerURLsMap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners), membership.WithMaxObservers(cfg.ExperimentalMaxObservers))
 	if err != nil {
 		return nil, err
 	}
@@ -349,7 +351,7 @@ func bootstrapNewClusterNoWAL(cfg config.ServerConfig, prt http.RoundTripper) (*
 		if config.CheckDuplicateURL(urlsmap) {
 			return nil, fmt.Errorf("discovery cluster %s has duplicate url", urlsmap)
 		}
-		if cl, err = membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, urlsmap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners)); err != nil {
+		if cl, err = membership.NewClusterFromURLsMap(cfg.Logger, cfg.InitialClusterToken, urlsmap, membership.WithMaxLearners(cfg.ExperimentalMaxLearners), membership.WithMaxObservers(cfg.ExperimentalMaxObservers)); err != nil {
 			return nil, err
 		}
 	}
@@ -371,7 +373,7 @@ func bootstrapClusterWithWAL(cfg config.ServerConfig, meta *snapshotMetadata) (*
 			zap.String("wal-dir", cfg.WALDir()),
 		)
 	}
-	cl := membership.NewCluster(cfg.Logger, membership.WithMaxLearners(cfg.ExperimentalMaxLearners))
+	cl := membership.NewCluster(cfg.Logger, membership.WithMaxLearners(cfg.ExperimentalMaxLearners), membership.WithMaxObservers(cfg.ExperimentalMaxObservers))
 
 	scaleUpLearners := false
 	if err := membership.ValidateMaxLearnerConfig(cfg.ExperimentalMaxLearners, cl.Members(), scaleUpLearners); err != nil {
@@ -610,6 +612,13 @@ func openWALFromSnapshot(cfg config.ServerConfig, snapshot *raftpb.Snapshot) (*w
 		if cfg.UnsafeNoFsync {
 			w.SetUnsafeNoFsync()
 		}
+		w.SetCipher(cfg.Cipher())
+		w.SetCompression(cfg.ExperimentalWALCompression)
+		if cfg.ExperimentalWALArchiveDir != "" {
+			if err = w.SetArchiveDir(cfg.ExperimentalWALArchiveDir); err != nil {
+				cfg.Logger.Fatal("failed to enable WAL archiving", zap.Error(err))
+			}
+		}
 		wmetadata, st, ents, err := w.ReadAll()
 		if err != nil {
 			w.Close()
@@ -652,6 +661,13 @@ func bootstrapNewWAL(cfg config.ServerConfig, cl *bootstrapedCluster) *bootstrap
 	if cfg.UnsafeNoFsync {
 		w.SetUnsafeNoFsync()
 	}
+	w.SetCipher(cfg.Cipher())
+	w.SetCompression(cfg.ExperimentalWALCompression)
+	if cfg.ExperimentalWALArchiveDir != "" {
+		if err = w.SetArchiveDir(cfg.ExperimentalWALArchiveDir); err != nil {
+			cfg.Logger.Panic("failed to enable WAL archiving", zap.Error(err))
+		}
+	}
 	return &bootstrappedWAL{
 		lg: cfg.Logger,
 		w:  w,
@@ -0,0 +1,110 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+)
+
+// stalenessBound is the state the local member must apply to serve a read
+// with a staleness bound.
+type stalenessBound struct {
+	// index is the lowest applied index serving the read.
+	index uint64
+	// deadline is the time until which the local member may wait for index
+	// to be applied, or the zero time to wait as long as the request.
+	deadline time.Time
+	// ok is false if the read must be confirmed by the leader.
+	ok bool
+}
+
+// newStalenessBound returns the bound of the given request for a member that
+// last responded to the leader at contact, and knows the entries up to
+// committed to be committed.
+//
+// The time bound is measured from the moment the member handled the last
+// message of the leader, and does not include the network delay of that
+// message. Every applied entry increments the revision at most once, so the
+// revision bound is met once at most that many known committed entries are
+// not applied yet. As the committed index is only known as of the last leader
+// contact, the revision bound also requires a contact within the election
+// timeout, and does not count the entries the leader committed since; the
+// time bound covers those.
+func newStalenessBound(r *pb.RangeRequest, committed uint64, contact, now time.Time, electionTimeout time.Duration) stalenessBound {
+	if contact.IsZero() || now.Sub(contact) > electionTimeout {
+		return stalenessBound{}
+	}
+	b := stalenessBound{ok: true}
+	if r.MaxStalenessRevisions > 0 && uint64(r.MaxStalenessRevisions) < committed {
+		b.index = committed - uint64(r.MaxStalenessRevisions)
+	}
+	if r.MaxStalenessMs > 0 {
+		b.deadline = contact.Add(time.Duration(r.MaxStalenessMs) * time.Millisecond)
+		if !now.Before(b.deadline) {
+			return stalenessBound{}
+		}
+		b.index = committed
+	}
+	return b
+}
+
+// boundedStalenessReadNotify waits until the local member can serve the given
+// range within its staleness bounds. A follower serves the range from its
+// local state when it is recent enough, waits for its committed entries to be
+// applied if it can, and otherwise confirms the read with a ReadIndex. The
+// leader serves the range as a linearizable read.
+func (s *EtcdServer) boundedStalenessReadNotify(ctx context.Context, r *pb.RangeRequest) error {
+	if s.isLeader() {
+		boundedStalenessReads.WithLabelValues("read_index").Inc()
+		return s.linearizableReadNotify(ctx)
+	}
+
+	committed := s.getCommittedIndex()
+	if lc := s.r.leaderCommitIndex(); lc > committed {
+		committed = lc
+	}
+	b := newStalenessBound(r, committed, s.r.lastLeaderContact(), time.Now(), s.Cfg.ElectionTimeout())
+	if !b.ok {
+		boundedStalenessReads.WithLabelValues("read_index").Inc()
+		return s.linearizableReadNotify(ctx)
+	}
+	if s.getAppliedIndex() >= b.index {
+		boundedStalenessReads.WithLabelValues("local").Inc()
+		return nil
+	}
+
+	var expired <-chan time.Time
+	if !b.deadline.IsZero() {
+		timer := time.NewTimer(time.Until(b.deadline))
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case <-s.applyWait.Wait(b.index):
+		boundedStalenessReads.WithLabelValues("wait").Inc()
+		return nil
+	case <-expired:
+		boundedStalenessReads.WithLabelValues("read_index").Inc()
+		return s.linearizableReadNotify(ctx)
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-s.done:
+		return errors.ErrStopped
+	}
+}
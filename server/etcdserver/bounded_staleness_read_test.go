@@ -0,0 +1,80 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"testing"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+)
+
+func TestNewStalenessBound(t *testing.T) {
+	now := time.Now()
+	electionTimeout := time.Second
+	tests := []struct {
+		name    string
+		req     *pb.RangeRequest
+		contact time.Time
+		want    stalenessBound
+	}{
+		{
+			name: "no leader contact",
+			req:  &pb.RangeRequest{MaxStalenessRevisions: 10},
+		},
+		{
+			name:    "leader contact before the election timeout",
+			req:     &pb.RangeRequest{MaxStalenessRevisions: 10},
+			contact: now.Add(-2 * electionTimeout),
+		},
+		{
+			name:    "revision bound",
+			req:     &pb.RangeRequest{MaxStalenessRevisions: 10},
+			contact: now.Add(-100 * time.Millisecond),
+			want:    stalenessBound{index: 90, ok: true},
+		},
+		{
+			name:    "revision bound above the committed index",
+			req:     &pb.RangeRequest{MaxStalenessRevisions: 1000},
+			contact: now.Add(-100 * time.Millisecond),
+			want:    stalenessBound{ok: true},
+		},
+		{
+			name:    "time bound",
+			req:     &pb.RangeRequest{MaxStalenessMs: 500},
+			contact: now.Add(-100 * time.Millisecond),
+			want:    stalenessBound{index: 100, deadline: now.Add(400 * time.Millisecond), ok: true},
+		},
+		{
+			name:    "time bound exceeded",
+			req:     &pb.RangeRequest{MaxStalenessMs: 50},
+			contact: now.Add(-100 * time.Millisecond),
+		},
+		{
+			name:    "both bounds",
+			req:     &pb.RangeRequest{MaxStalenessMs: 500, MaxStalenessRevisions: 10},
+			contact: now.Add(-100 * time.Millisecond),
+			want:    stalenessBound{index: 100, deadline: now.Add(400 * time.Millisecond), ok: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := newStalenessBound(tt.req, 100, tt.contact, now, electionTimeout)
+			if got.index != tt.want.index || !got.deadline.Equal(tt.want.deadline) || got.ok != tt.want.ok {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 )
 
@@ -439,6 +440,14 @@ func (f *fakeHasher) HashByRev(rev int64) (hash mvcc.KeyValueHash, revision int6
 	return hashByRev.hash, hashByRev.revision, hashByRev.err
 }
 
+func (f *fakeHasher) HashSince(baseRev, rev int64) (mvcc.KeyValueHash, error) {
+	panic("not implemented")
+}
+
+func (f *fakeHasher) ReadSince(baseRev int64, fn func(tx backend.ReadTx, baseHash, hash mvcc.KeyValueHash) error) error {
+	panic("not implemented")
+}
+
 func (f *fakeHasher) Store(hash mvcc.KeyValueHash) {
 	f.actions = append(f.actions, fmt.Sprintf("Store(%v)", hash))
 	f.hashes = append(f.hashes, hash)
@@ -42,6 +42,13 @@ var (
 	ErrClusterVersionUnavailable   = errors.New("etcdserver: cluster version not found during downgrade")
 	ErrWrongDowngradeVersionFormat = errors.New("etcdserver: wrong downgrade target version format")
 	ErrKeyNotFound                 = errors.New("etcdserver: key not found")
+	ErrKeyQuotaExceeded            = errors.New("etcdserver: key quota exceeded")
+	ErrKeyQuotaNotFound            = errors.New("etcdserver: key quota not found")
+	ErrKeyQuotaInvalid             = errors.New("etcdserver: invalid key quota")
+	ErrInvalidContinueToken        = errors.New("etcdserver: invalid continue token")
+	ErrEncryptionDisabled          = errors.New("etcdserver: encryption at rest is not enabled")
+	ErrReadSetNotSupported         = errors.New("etcdserver: read set requires cluster version 3.6")
+	ErrKeyTTLNotSupported          = errors.New("etcdserver: key ttl requires cluster version 3.6")
 )
 
 type DiscoveryError struct {
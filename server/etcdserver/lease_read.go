@@ -0,0 +1,165 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+
+	"go.uber.org/zap"
+)
+
+// leaderLease tracks the time until which no other member can become leader
+// in the term of the local leader.
+//
+// With CheckQuorum, a follower does not vote for another member within the
+// election timeout of hearing from the leader. A ReadIndex confirmed by the
+// quorum after a time t thus guarantees that no other leader is elected
+// before t plus the election timeout, as measured by the followers. The lease
+// subtracts the clock drift bound from the election timeout to account for
+// followers measuring it faster than the leader does.
+//
+// A leadership transfer bypasses the election timeout, so the lease is
+// revoked while a transfer can be in progress. The lease also assumes that a
+// restarted follower does not vote before the election timeout, which holds
+// as long as restarting takes longer than the election timeout.
+type leaderLease struct {
+	mu sync.RWMutex
+	// term is the raft term the lease was granted in.
+	term uint64
+	// expiry is the time the lease expires at.
+	expiry time.Time
+	// transfers is the number of leadership transfers in progress.
+	transfers int
+	// revokedUntil is the time until which a finished transfer may still
+	// happen, as raft aborts a pending transfer after an election timeout.
+	revokedUntil time.Time
+}
+
+// renew extends the lease with a leadership confirmed by the quorum at a
+// time after start.
+func (l *leaderLease) renew(term uint64, start time.Time, d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.transfers > 0 || !start.After(l.revokedUntil) {
+		return
+	}
+	if term != l.term || start.Add(d).After(l.expiry) {
+		l.term, l.expiry = term, start.Add(d)
+	}
+}
+
+// valid returns if the lease of the given term is held at the given time.
+func (l *leaderLease) valid(term uint64, now time.Time) bool {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.transfers == 0 && l.term == term && now.Before(l.expiry)
+}
+
+// revoke revokes the lease until the returned function is called, and for
+// d after.
+func (l *leaderLease) revoke(d time.Duration) (release func()) {
+	l.mu.Lock()
+	l.transfers++
+	l.expiry = time.Time{}
+	l.mu.Unlock()
+	return func() {
+		l.mu.Lock()
+		l.transfers--
+		l.revokedUntil = time.Now().Add(d)
+		l.mu.Unlock()
+	}
+}
+
+// leaseDuration is the duration of the leadership lease after a
+// confirmation of the leadership by the quorum.
+func (s *EtcdServer) leaseDuration() time.Duration {
+	return s.Cfg.ElectionTimeout() - s.Cfg.ExperimentalLeaseReadMaxClockDrift
+}
+
+// monitorLeaderLease renews the leadership lease while the local member is
+// leader, so that reads do not wait for a ReadIndex round trip when it
+// expires.
+func (s *EtcdServer) monitorLeaderLease() {
+	if !s.Cfg.ExperimentalLeaseRead {
+		return
+	}
+	lg := s.Logger()
+	d := s.leaseDuration()
+	lg.Info(
+		"enabled lease-based linearizable reads",
+		zap.String("local-member-id", s.MemberId().String()),
+		zap.Duration("lease-duration", d),
+		zap.Duration("max-clock-drift", s.Cfg.ExperimentalLeaseReadMaxClockDrift),
+	)
+	for {
+		select {
+		case <-time.After(d / 3):
+		case <-s.stopping:
+			return
+		}
+		if !s.isLeader() {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(s.ctx, d)
+		err := s.renewLeaderLease(ctx)
+		cancel()
+		if isStopped(err) {
+			return
+		}
+		if err != nil {
+			lg.Debug("failed to renew leadership lease", zap.Error(err))
+		}
+	}
+}
+
+// renewLeaderLease confirms the leadership with a ReadIndex, and renews the
+// lease if the local member is still leader of the same term.
+func (s *EtcdServer) renewLeaderLease(ctx context.Context) error {
+	start, term := time.Now(), s.Term()
+	if err := s.readIndexNotify(ctx); err != nil {
+		return err
+	}
+	if s.isLeader() && s.Term() == term {
+		s.leaderLease.renew(term, start, s.leaseDuration())
+	}
+	return nil
+}
+
+// leaseReadNotify waits for the local member to apply the committed entries
+// if it holds the leadership lease. It returns false if the read must be
+// confirmed by a ReadIndex instead.
+func (s *EtcdServer) leaseReadNotify(ctx context.Context) (bool, error) {
+	if !s.isLeader() || !s.leaderLease.valid(s.Term(), time.Now()) {
+		return false, nil
+	}
+	// the leader knows every committed entry, which are applied before the
+	// read as with the index confirmed by a ReadIndex
+	ci := s.getCommittedIndex()
+	if s.getAppliedIndex() >= ci {
+		return true, nil
+	}
+	select {
+	case <-s.applyWait.Wait(ci):
+		return true, nil
+	case <-ctx.Done():
+		return true, ctx.Err()
+	case <-s.done:
+		return true, errors.ErrStopped
+	}
+}
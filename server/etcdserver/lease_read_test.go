@@ -0,0 +1,64 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLeaderLease(t *testing.T) {
+	var l leaderLease
+	now := time.Now()
+	d := time.Second
+
+	if l.valid(1, now) {
+		t.Fatal("expected no lease before a renewal")
+	}
+	l.renew(1, now, d)
+	if !l.valid(1, now.Add(d/2)) {
+		t.Error("expected the lease to be held within its duration")
+	}
+	if l.valid(1, now.Add(d)) {
+		t.Error("expected the lease to expire after its duration")
+	}
+	if l.valid(2, now) {
+		t.Error("expected no lease in another term")
+	}
+
+	// an older confirmation does not shorten the lease
+	l.renew(1, now.Add(-d/2), d)
+	if !l.valid(1, now.Add(d/2)) {
+		t.Error("expected the lease to be kept by an older confirmation")
+	}
+
+	release := l.revoke(d)
+	if l.valid(1, now) {
+		t.Error("expected no lease during a leadership transfer")
+	}
+	l.renew(1, time.Now(), d)
+	if l.valid(1, time.Now()) {
+		t.Error("expected no renewal during a leadership transfer")
+	}
+	release()
+	l.renew(1, time.Now(), d)
+	if l.valid(1, time.Now()) {
+		t.Error("expected no renewal confirmed within an election timeout of the transfer")
+	}
+	l.renew(1, time.Now().Add(2*d), d)
+	if !l.valid(1, time.Now().Add(2*d)) {
+		t.Error("expected a renewal confirmed after the transfer to grant the lease")
+	}
+}
@@ -140,6 +140,38 @@ var (
 		Help:      "Server or member ID in hexadecimal format. 1 for 'server_id' label with current ID.",
 	},
 		[]string{"server_id"})
+	scrubDurationSec = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "scrub_duration_seconds",
+		Help:      "The latency distributions of the checks of the storage scrubber.",
+
+		// lowest bucket start of upper bound 0.1 sec (100 ms) with factor 2
+		// highest bucket start of 0.1 sec * 2^15 == 3276.8 sec
+		Buckets: prometheus.ExponentialBuckets(.1, 2, 16),
+	},
+		[]string{"check"})
+	scrubFailures = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "scrub_failures_total",
+		Help:      "The total number of checks of the storage scrubber that found corrupted or inconsistent data.",
+	},
+		[]string{"check"})
+	linearizableReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "linearizable_reads_total",
+		Help:      "The total number of linearizable reads, by the path confirming them: 'lease' for the leadership lease, 'read_index' for a ReadIndex round trip.",
+	},
+		[]string{"path"})
+	boundedStalenessReads = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "server",
+		Name:      "bounded_staleness_reads_total",
+		Help:      "The total number of reads with a staleness bound, by the path serving them: 'local' for reads served right away, 'wait' for reads waiting for entries to be applied, 'read_index' for reads confirmed with the leader.",
+	},
+		[]string{"path"})
 
 	fdUsed = prometheus.NewGauge(prometheus.GaugeOpts{
 		Namespace: "os",
@@ -174,6 +206,10 @@ func init() {
 	prometheus.MustRegister(isLearner)
 	prometheus.MustRegister(learnerPromoteSucceed)
 	prometheus.MustRegister(learnerPromoteFailed)
+	prometheus.MustRegister(scrubDurationSec)
+	prometheus.MustRegister(scrubFailures)
+	prometheus.MustRegister(linearizableReads)
+	prometheus.MustRegister(boundedStalenessReads)
 	prometheus.MustRegister(fdUsed)
 	prometheus.MustRegister(fdLimit)
 
@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
@@ -74,6 +75,16 @@ type toApply struct {
 }
 
 type raftNode struct {
+	// leaderContact is the unix time in nanoseconds the local member last
+	// responded to the leader at.
+	leaderContact int64 // must use atomic operations to access; keep 64-bit aligned.
+	// leaderCommit is the highest commit index received from a leader.
+	leaderCommit uint64 // must use atomic operations to access; keep 64-bit aligned.
+	// lead and term are the leader and the term of the last Ready; only
+	// accessed by the raft loop.
+	lead uint64
+	term uint64
+
 	lg *zap.Logger
 
 	tickMu *sync.Mutex
@@ -159,6 +170,10 @@ func (r *raftNode) tick() {
 func (r *raftNode) start(rh *raftReadyHandler) {
 	internalTimeout := time.Second
 
+	if hs, _, err := r.raftStorage.InitialState(); err == nil {
+		r.term = hs.Term
+	}
+
 	go func() {
 		defer r.onStop()
 		islead := false
@@ -181,6 +196,7 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 					}
 
 					rh.updateLead(rd.SoftState.Lead)
+					r.lead = rd.SoftState.Lead
 					islead = rd.RaftState == raft.StateLeader
 					if islead {
 						isLeader.Set(1)
@@ -191,6 +207,10 @@ func (r *raftNode) start(rh *raftReadyHandler) {
 					r.td.Reset()
 				}
 
+				if !raft.IsEmptyHardState(rd.HardState) {
+					r.term = rd.HardState.Term
+				}
+
 				if len(rd.ReadStates) != 0 {
 					select {
 					case r.readStateC <- rd.ReadStates[len(rd.ReadStates)-1]:
@@ -343,6 +363,12 @@ func (r *raftNode) processMessages(ms []raftpb.Message) []raftpb.Message {
 			}
 		}
 
+		if r.isLeaderContact(ms[i]) {
+			// the responses are sent once the messages of the leader are
+			// handled, so the entries it committed are known to be committed
+			atomic.StoreInt64(&r.leaderContact, time.Now().UnixNano())
+		}
+
 		if ms[i].Type == raftpb.MsgSnap {
 			// There are two separate data store: the store for v2, and the KV for v3.
 			// The msgSnap only contains the most recent snapshot of store without KV.
@@ -373,6 +399,44 @@ func (r *raftNode) processMessages(ms []raftpb.Message) []raftpb.Message {
 	return ms
 }
 
+// isLeaderContact returns true if the message responds to the current leader
+// in the current term, and is not dropped.
+func (r *raftNode) isLeaderContact(m raftpb.Message) bool {
+	if m.Type != raftpb.MsgHeartbeatResp && m.Type != raftpb.MsgAppResp {
+		return false
+	}
+	return !m.Reject && m.To != raft.None && m.To == r.lead && m.Term == r.term
+}
+
+// observeLeaderCommit records the commit index of a message received from the
+// leader. Unlike heartbeats, which carry at most the index the receiver
+// replicated, appends carry the commit index of the leader.
+func (r *raftNode) observeLeaderCommit(m raftpb.Message) {
+	if m.Type != raftpb.MsgApp {
+		return
+	}
+	for {
+		ci := atomic.LoadUint64(&r.leaderCommit)
+		if m.Commit <= ci || atomic.CompareAndSwapUint64(&r.leaderCommit, ci, m.Commit) {
+			return
+		}
+	}
+}
+
+// leaderCommitIndex returns the highest commit index received from a leader.
+func (r *raftNode) leaderCommitIndex() uint64 {
+	return atomic.LoadUint64(&r.leaderCommit)
+}
+
+// lastLeaderContact returns the time the local member last responded to the
+// leader at, or the zero time if it never did.
+func (r *raftNode) lastLeaderContact() time.Time {
+	if t := atomic.LoadInt64(&r.leaderContact); t != 0 {
+		return time.Unix(0, t)
+	}
+	return time.Time{}
+}
+
 func (r *raftNode) apply() chan toApply {
 	return r.applyc
 }
@@ -279,6 +279,39 @@ func TestProcessDuplicatedAppRespMessage(t *testing.T) {
 	}
 }
 
+func TestProcessMessagesLeaderContact(t *testing.T) {
+	tests := []struct {
+		name string
+		m    raftpb.Message
+		want bool
+	}{
+		{"heartbeat response", raftpb.Message{Type: raftpb.MsgHeartbeatResp, From: 2, To: 1, Term: 2}, true},
+		{"append response", raftpb.Message{Type: raftpb.MsgAppResp, From: 2, To: 1, Term: 2, Index: 1}, true},
+		{"rejected append", raftpb.Message{Type: raftpb.MsgAppResp, From: 2, To: 1, Term: 2, Reject: true}, false},
+		{"deposed leader", raftpb.Message{Type: raftpb.MsgAppResp, From: 2, To: 3, Term: 2, Index: 1}, false},
+		{"previous term", raftpb.Message{Type: raftpb.MsgHeartbeatResp, From: 2, To: 1, Term: 1}, false},
+		{"removed leader", raftpb.Message{Type: raftpb.MsgHeartbeatResp, From: 2, To: 4, Term: 2}, false},
+		{"other message", raftpb.Message{Type: raftpb.MsgVote, From: 2, To: 1, Term: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newRaftNode(raftNodeConfig{
+				lg:          zaptest.NewLogger(t),
+				isIDRemoved: func(id uint64) bool { return id == 4 },
+				raftStorage: raft.NewMemoryStorage(),
+			})
+			r.lead, r.term = 1, 2
+			if tt.m.To == 4 {
+				r.lead = 4
+			}
+			r.processMessages([]raftpb.Message{tt.m})
+			if got := !r.lastLeaderContact().IsZero(); got != tt.want {
+				t.Errorf("leader contact = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
 // TestExpvarWithNoRaftStatus to test that none of the expvars that get added during init panic.
 // This matters if another package imports etcdserver, doesn't use it, but does use expvars.
 func TestExpvarWithNoRaftStatus(t *testing.T) {
@@ -0,0 +1,137 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"errors"
+	"time"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+
+	"go.uber.org/zap"
+)
+
+var errScrubAborted = errors.New("etcdserver: scrub aborted as the server is stopping")
+
+// scrubCheck is a check of the storage scrubber. throttle is called with the
+// number of bytes read by the check.
+type scrubCheck struct {
+	name  string
+	check func(throttle func(n int) error) error
+}
+
+func (s *EtcdServer) monitorScrub() {
+	t := s.Cfg.ExperimentalScrubInterval
+	if t == 0 {
+		return
+	}
+
+	lg := s.Logger()
+	lg.Info(
+		"enabled storage scrubbing",
+		zap.String("local-member-id", s.MemberId().String()),
+		zap.Duration("interval", t),
+		zap.Int64("rate-limit-bytes", s.Cfg.ExperimentalScrubRateLimitBytes),
+	)
+	for {
+		select {
+		case <-s.stopping:
+			return
+		case <-time.After(t):
+		}
+		s.scrub()
+	}
+}
+
+// scrub re-reads the local storage to find corrupted data before a restart
+// fails on it. It validates the CRCs of the finished WAL segments, the pages
+// of the backend, and the consistency of the key index, the key bucket and
+// the leases. A failed check raises the STORAGE_CORRUPT alarm of the member.
+// Unlike the corruption checks comparing the hashes of the members, scrubs
+// find corrupted data no member has read yet.
+func (s *EtcdServer) scrub() {
+	lg := s.Logger()
+	checks := []scrubCheck{
+		{name: "wal", check: s.r.storage.Scrub},
+		// the page structure is checked in one pass, which is not paced
+		{name: "backend", check: func(func(int) error) error { return s.Backend().Check() }},
+		{name: "mvcc", check: s.KV().Scrub},
+	}
+	st := &scrubThrottle{rate: s.Cfg.ExperimentalScrubRateLimitBytes, stopc: s.stopping}
+	for _, c := range checks {
+		start := time.Now()
+		st.start, st.read = start, 0
+		err := c.check(st.wait)
+		if errors.Is(err, errScrubAborted) {
+			return
+		}
+		scrubDurationSec.WithLabelValues(c.name).Observe(time.Since(start).Seconds())
+		if err == nil {
+			lg.Debug("storage scrub check passed", zap.String("check", c.name), zap.Duration("took", time.Since(start)))
+			continue
+		}
+		scrubFailures.WithLabelValues(c.name).Inc()
+		lg.Error(
+			"storage scrub found corrupted data",
+			zap.String("local-member-id", s.MemberId().String()),
+			zap.String("check", c.name),
+			zap.Error(err),
+		)
+		s.triggerStorageCorruptAlarm()
+	}
+}
+
+func (s *EtcdServer) triggerStorageCorruptAlarm() {
+	a := &pb.AlarmRequest{
+		MemberID: uint64(s.MemberId()),
+		Action:   pb.AlarmRequest_ACTIVATE,
+		Alarm:    pb.AlarmType_STORAGE_CORRUPT,
+	}
+	s.GoAttach(func() {
+		s.raftRequest(s.ctx, pb.InternalRaftRequest{Alarm: a})
+	})
+}
+
+// scrubThrottle paces the reads of a scrub check to its rate limit.
+type scrubThrottle struct {
+	rate  int64
+	stopc <-chan struct{}
+
+	start time.Time
+	read  int64
+}
+
+func (st *scrubThrottle) wait(n int) error {
+	st.read += int64(n)
+	var wait time.Duration
+	if st.rate > 0 {
+		due := st.start.Add(time.Duration(float64(st.read) / float64(st.rate) * float64(time.Second)))
+		wait = time.Until(due)
+	}
+	if wait <= 0 {
+		select {
+		case <-st.stopc:
+			return errScrubAborted
+		default:
+			return nil
+		}
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-st.stopc:
+		return errScrubAborted
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestScrubThrottle(t *testing.T) {
+	stopc := make(chan struct{})
+	st := &scrubThrottle{rate: 1000, stopc: stopc, start: time.Now()}
+
+	// reads under the rate limit are not delayed
+	start := time.Now()
+	if err := st.wait(10); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(start); took > 50*time.Millisecond {
+		t.Errorf("took %v, expected no delay", took)
+	}
+
+	// 200 bytes at 1000 bytes per second are due after 200ms
+	if err := st.wait(190); err != nil {
+		t.Fatal(err)
+	}
+	if took := time.Since(st.start); took < 200*time.Millisecond {
+		t.Errorf("took %v, expected at least 200ms", took)
+	}
+
+	close(stopc)
+	if err := st.wait(1000); !errors.Is(err, errScrubAborted) {
+		t.Errorf("err = %v, want %v", err, errScrubAborted)
+	}
+	st.rate = 0
+	if err := st.wait(1); !errors.Is(err, errScrubAborted) {
+		t.Errorf("err = %v, want %v", err, errScrubAborted)
+	}
+}
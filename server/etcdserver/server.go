@@ -60,7 +60,9 @@ import (
 	stats "go.etcd.io/etcd/server/v3/etcdserver/api/v2stats"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3alarm"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3audit"
 	"go.etcd.io/etcd/server/v3/etcdserver/api/v3compactor"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3quota"
 	"go.etcd.io/etcd/server/v3/etcdserver/cindex"
 	serverversion "go.etcd.io/etcd/server/v3/etcdserver/version"
 	"go.etcd.io/etcd/server/v3/lease"
@@ -264,6 +266,10 @@ type EtcdServer struct {
 	beHooks    *serverstorage.BackendHooks
 	authStore  auth.AuthStore
 	alarmStore *v3alarm.AlarmStore
+	quotaStore *v3quota.QuotaStore
+
+	// auditLogger records mutating and auth requests; nil if auditing is disabled.
+	auditLogger *v3audit.Logger
 
 	stats  *stats.ServerStats
 	lstats *stats.LeaderStats
@@ -298,6 +304,18 @@ type EtcdServer struct {
 	// Should only be set within apply code path. Used to force snapshot after cluster version downgrade.
 	forceSnapshot     bool
 	corruptionChecker CorruptionChecker
+
+	// snapRelayc queues the snapshots the leader asked the local member to
+	// send to the observers it is the snapshot source of.
+	snapRelayc chan snapRelay
+	// snapRelayMu guards snapRelayFailed, the time of the last failed relay
+	// of a snapshot to each observer.
+	snapRelayMu     sync.Mutex
+	snapRelayFailed map[types.ID]time.Time
+
+	// leaderLease is the leadership lease serving linearizable reads
+	// without a ReadIndex on the leader.
+	leaderLease leaderLease
 }
 
 // NewServer creates a new EtcdServer from the supplied configuration. The
@@ -339,6 +357,8 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		consistIndex:          b.storage.backend.ci,
 		firstCommitInTerm:     notify.NewNotifier(),
 		clusterVersionChanged: notify.NewNotifier(),
+		snapRelayc:            make(chan snapRelay, maxInFlightMsgSnap),
+		snapRelayFailed:       make(map[types.ID]time.Time),
 	}
 	serverID.With(prometheus.Labels{"server_id": b.cluster.nodeID.String()}).Set(1)
 	srv.cluster.SetVersionChangedNotifier(srv.clusterVersionChanged)
@@ -368,9 +388,11 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 		return nil, err
 	}
 
+	srv.quotaStore = v3quota.NewQuotaStore(srv.Logger())
 	mvccStoreConfig := mvcc.StoreConfig{
 		CompactionBatchLimit:    cfg.CompactionBatchLimit,
 		CompactionSleepInterval: cfg.CompactionSleepInterval,
+		UsageTracker:            srv.quotaStore,
 	}
 	srv.kv = mvcc.New(srv.Logger(), srv.be, srv.lessor, mvccStoreConfig)
 	srv.corruptionChecker = newCorruptionChecker(cfg.Logger, srv, srv.kv.HashStorage())
@@ -396,6 +418,18 @@ func NewServer(cfg config.ServerConfig) (srv *EtcdServer, err error) {
 	if err = srv.restoreAlarms(); err != nil {
 		return nil, err
 	}
+	if err = srv.quotaStore.Recover(schema.NewQuotaBackend(srv.Logger(), srv.be), srv.kv); err != nil {
+		return nil, err
+	}
+	if cfg.ExperimentalAuditLogPath != "" {
+		var levels map[string]v3audit.Level
+		if levels, err = v3audit.ParseLevels(cfg.ExperimentalAuditLogLevels); err != nil {
+			return nil, err
+		}
+		if srv.auditLogger, err = v3audit.NewFileLogger(srv.Logger(), cfg.ExperimentalAuditLogPath, cfg.ExperimentalAuditLogRotationConfigJSON, levels); err != nil {
+			return nil, err
+		}
+	}
 	srv.uberApply = srv.NewUberApplier()
 
 	if srv.Cfg.EnableLeaseCheckpoint {
@@ -534,6 +568,8 @@ func (s *EtcdServer) Start() {
 	s.GoAttach(s.linearizableReadLoop)
 	s.GoAttach(s.monitorKVHash)
 	s.GoAttach(s.monitorCompactHash)
+	s.GoAttach(s.monitorScrub)
+	s.GoAttach(s.monitorLeaderLease)
 	s.GoAttach(s.monitorDowngrade)
 }
 
@@ -648,6 +684,7 @@ type ServerPeerV2 interface {
 	ServerPeer
 	HashKVHandler() http.Handler
 	DowngradeEnabledHandler() http.Handler
+	SnapshotRelayHandler() http.Handler
 }
 
 func (s *EtcdServer) DowngradeInfo() *serverversion.DowngradeInfo { return s.cluster.DowngradeInfo() }
@@ -709,6 +746,7 @@ func (s *EtcdServer) Process(ctx context.Context, m raftpb.Message) error {
 	if m.Type == raftpb.MsgApp {
 		s.stats.RecvAppendReq(types.ID(m.From).String(), m.Size())
 	}
+	s.r.observeLeaderCommit(m)
 	return s.r.Step(ctx, m)
 }
 
@@ -916,6 +954,9 @@ func (s *EtcdServer) Cleanup() {
 	if s.compactor != nil {
 		s.compactor.Stop()
 	}
+	if s.auditLogger != nil {
+		s.auditLogger.Close()
+	}
 }
 
 func (s *EtcdServer) applyAll(ep *etcdProgress, apply *toApply) {
@@ -934,8 +975,16 @@ func (s *EtcdServer) applyAll(ep *etcdProgress, apply *toApply) {
 	select {
 	// snapshot requested via send()
 	case m := <-s.r.msgSnapC:
+		if src := s.snapshotSourceOf(types.ID(m.To)); src != nil {
+			s.relaySnap(m, src)
+			break
+		}
 		merged := s.createMergedSnapshotMessage(m, ep.appliedt, ep.appliedi, ep.confState)
-		s.sendMergedSnap(merged)
+		s.sendMergedSnap(merged, nil)
+	// snapshot relayed by the leader to an observer
+	case r := <-s.snapRelayc:
+		merged := s.createMergedSnapshotMessage(r.m, ep.appliedt, ep.appliedi, ep.confState)
+		s.sendMergedSnap(merged, r.donec)
 	default:
 	}
 }
@@ -1037,6 +1086,16 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, toApply *toApply) {
 
 	lg.Info("restored alarm store")
 
+	if s.quotaStore != nil {
+		lg.Info("restoring key quota store")
+
+		if err := s.quotaStore.Recover(schema.NewQuotaBackend(lg, newbe), s.kv); err != nil {
+			lg.Panic("failed to restore key quota store", zap.Error(err))
+		}
+
+		lg.Info("restored key quota store")
+	}
+
 	if s.authStore != nil {
 		lg.Info("restoring auth store")
 
@@ -1091,7 +1150,7 @@ func (s *EtcdServer) applySnapshot(ep *etcdProgress, toApply *toApply) {
 }
 
 func (s *EtcdServer) NewUberApplier() apply.UberApplier {
-	return apply.NewUberApplier(s.lg, s.be, s.KV(), s.alarmStore, s.authStore, s.lessor, s.cluster, s, s, s.consistIndex,
+	return apply.NewUberApplier(s.lg, s.be, s.KV(), s.alarmStore, s.quotaStore, s.authStore, s.lessor, s.cluster, s, s, s.consistIndex,
 		s.Cfg.WarningApplyDuration, s.Cfg.ExperimentalTxnModeWriteWithSharedBuffer, s.Cfg.QuotaBackendBytes)
 }
 
@@ -1173,6 +1232,11 @@ func (s *EtcdServer) MoveLeader(ctx context.Context, lead, transferee uint64) er
 	now := time.Now()
 	interval := time.Duration(s.Cfg.TickMs) * time.Millisecond
 
+	// the transferee is elected without waiting for the election timeout,
+	// and raft may still transfer the leadership an election timeout after
+	// giving up on it.
+	defer s.leaderLease.revoke(s.Cfg.ElectionTimeout())()
+
 	lg := s.Logger()
 	lg.Info(
 		"leadership transfer starting",
@@ -1201,6 +1265,30 @@ func (s *EtcdServer) MoveLeader(ctx context.Context, lead, transferee uint64) er
 	return nil
 }
 
+// RotateEncryptionKey reloads the encryption keys and rewrites the backend
+// encrypted with the new primary key, by defragmenting it online. The WAL
+// entries and the snapshot files are encrypted with the new key as they are
+// written. It returns the ID of the new primary key.
+func (s *EtcdServer) RotateEncryptionKey(ctx context.Context, rateLimitBytes int64) (uint32, error) {
+	keyring := s.Cfg.EncryptionKeyring
+	if keyring == nil {
+		return 0, errors.ErrEncryptionDisabled
+	}
+	lg := s.Logger()
+	id, err := keyring.Reload()
+	if err != nil {
+		lg.Warn("failed to reload the encryption keys", zap.Error(err))
+		return 0, err
+	}
+	lg.Info("reloaded the encryption keys; re-encrypting the backend", zap.Uint32("primary-key-id", id))
+	if err = s.Backend().OnlineDefrag(ctx, backend.OnlineDefragConfig{RateLimitBytes: rateLimitBytes}); err != nil {
+		lg.Warn("failed to re-encrypt the backend", zap.Uint32("primary-key-id", id), zap.Error(err))
+		return id, err
+	}
+	lg.Info("re-encrypted the backend", zap.Uint32("primary-key-id", id))
+	return id, nil
+}
+
 // TransferLeadership transfers the leader to the chosen transferee.
 func (s *EtcdServer) TransferLeadership() error {
 	lg := s.Logger()
@@ -1407,7 +1495,7 @@ func (s *EtcdServer) PromoteMember(ctx context.Context, id uint64) ([]*membershi
 				return resp, nil
 			}
 			// If member promotion failed, return early. Otherwise keep retry.
-			if err == errors.ErrLearnerNotReady || err == membership.ErrIDNotFound || err == membership.ErrMemberNotLearner {
+			if err == errors.ErrLearnerNotReady || err == membership.ErrIDNotFound || err == membership.ErrMemberNotLearner || err == membership.ErrObserverNotPromotable {
 				return nil, err
 			}
 		}
@@ -1430,6 +1518,11 @@ func (s *EtcdServer) promoteMember(ctx context.Context, id uint64) ([]*membershi
 		return nil, err
 	}
 
+	// observers are never promoted, which any member can tell.
+	if m := s.cluster.Member(types.ID(id)); m != nil && m.IsObserver {
+		return nil, membership.ErrObserverNotPromotable
+	}
+
 	// check if we can promote this learner.
 	if err := s.mayPromoteMember(types.ID(id)); err != nil {
 		return nil, err
@@ -1747,7 +1840,9 @@ func (s *EtcdServer) publishV3(timeout time.Duration) {
 	}
 }
 
-func (s *EtcdServer) sendMergedSnap(merged snap.Message) {
+// sendMergedSnap sends the merged snapshot, and reports whether it was sent
+// to donec if not nil.
+func (s *EtcdServer) sendMergedSnap(merged snap.Message, donec chan<- bool) {
 	atomic.AddInt64(&s.inflightSnapshots, 1)
 
 	lg := s.Logger()
@@ -1765,6 +1860,9 @@ func (s *EtcdServer) sendMergedSnap(merged snap.Message) {
 	s.GoAttach(func() {
 		select {
 		case ok := <-merged.CloseNotify():
+			if donec != nil {
+				donec <- ok
+			}
 			// delay releasing inflight snapshot for another 30 seconds to
 			// block log compaction.
 			// If the follower still fails to catch up, it is probably just too slow
@@ -1916,6 +2014,8 @@ func (s *EtcdServer) applyEntryNormal(e *raftpb.Entry) {
 		return
 	}
 
+	s.auditApply(&raftReq, ar)
+
 	if ar.Err != errors.ErrNoSpace || len(s.alarmStore.Get(pb.AlarmType_NOSPACE)) > 0 {
 		s.w.Trigger(id, ar)
 		return
@@ -2377,6 +2477,30 @@ func (s *EtcdServer) Backend() backend.Backend {
 
 func (s *EtcdServer) AuthStore() auth.AuthStore { return s.authStore }
 
+// AuditLogger returns the audit logger of the server, or nil if auditing is
+// disabled.
+func (s *EtcdServer) AuditLogger() *v3audit.Logger { return s.auditLogger }
+
+// auditApply records an applied request in the audit log.
+func (s *EtcdServer) auditApply(r *pb.InternalRaftRequest, ar *apply.Result) {
+	if s.auditLogger == nil {
+		return
+	}
+	typ, req := v3audit.RaftRequest(r)
+	if typ == "" {
+		return
+	}
+	lvl := s.auditLogger.Level(typ)
+	if lvl == v3audit.LevelNone {
+		return
+	}
+	ev := v3audit.NewEvent(v3audit.StageApply, typ, lvl, req, ar.Resp, ar.Err)
+	if r.Header != nil {
+		ev.User = r.Header.Username
+	}
+	s.auditLogger.Log(ev)
+}
+
 func (s *EtcdServer) restoreAlarms() error {
 	as, err := v3alarm.NewAlarmStore(s.lg, schema.NewAlarmBackend(s.lg, s.be))
 	if err != nil {
@@ -2417,6 +2541,11 @@ func (s *EtcdServer) IsLearner() bool {
 	return s.cluster.IsLocalMemberLearner()
 }
 
+// IsObserver returns if the local member is an observer
+func (s *EtcdServer) IsObserver() bool {
+	return s.cluster.IsLocalMemberObserver()
+}
+
 // IsMemberExist returns if the member with the given id exists in cluster.
 func (s *EtcdServer) IsMemberExist(id types.ID) bool {
 	return s.cluster.IsMemberExist(id)
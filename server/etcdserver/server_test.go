@@ -1644,6 +1644,29 @@ func TestUpdateVersion(t *testing.T) {
 	}
 }
 
+// TestKeyTTLClusterVersion ensures puts with a ttl are rejected until the
+// cluster version is at least 3.6.
+func TestKeyTTLClusterVersion(t *testing.T) {
+	srv := &EtcdServer{
+		lgMu:    new(sync.RWMutex),
+		lg:      zaptest.NewLogger(t),
+		cluster: &membership.RaftCluster{},
+	}
+	put := &pb.PutRequest{Key: []byte("foo"), Ttl: 10}
+	if _, err := srv.Put(context.TODO(), put); err != errors.ErrKeyTTLNotSupported {
+		t.Errorf("put error = %v, want %v", err, errors.ErrKeyTTLNotSupported)
+	}
+	txn := &pb.TxnRequest{Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+		Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: put}}},
+	}}}}}
+	if _, err := srv.Txn(context.TODO(), txn); err != errors.ErrKeyTTLNotSupported {
+		t.Errorf("txn error = %v, want %v", err, errors.ErrKeyTTLNotSupported)
+	}
+	if put.Lease != 0 {
+		t.Errorf("lease = %d, want no lease chosen for a rejected put", put.Lease)
+	}
+}
+
 func TestStopNotify(t *testing.T) {
 	s := &EtcdServer{
 		lgMu: new(sync.RWMutex),
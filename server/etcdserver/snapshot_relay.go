@@ -0,0 +1,214 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/raft/v3"
+	"go.etcd.io/raft/v3/raftpb"
+
+	"go.uber.org/zap"
+)
+
+const (
+	PeerSnapshotRelayPath = "/members/snapshot-relay"
+
+	// snapshotRelayRetryDelay is the time after a failed relay during which
+	// the leader sends the snapshots of the observer itself.
+	snapshotRelayRetryDelay = time.Minute
+)
+
+// snapRelay is a snapshot the leader asked the local member to send to an
+// observer. donec receives whether the snapshot was sent.
+type snapRelay struct {
+	m     raftpb.Message
+	donec chan bool
+}
+
+// snapshotSourceOf returns the member sending snapshots to the given observer
+// instead of the leader, or nil if the leader sends them. Snapshots are sent
+// by the leader when the source is not a started voting member, or when a
+// relay to the observer failed recently.
+func (s *EtcdServer) snapshotSourceOf(to types.ID) *membership.Member {
+	m := s.cluster.Member(to)
+	if m == nil || !m.IsObserver || m.SnapshotSource == 0 || m.SnapshotSource == s.MemberId() {
+		return nil
+	}
+	src := s.cluster.Member(m.SnapshotSource)
+	if src == nil || src.IsLearner || s.r.transport.ActiveSince(src.ID).IsZero() {
+		return nil
+	}
+
+	s.snapRelayMu.Lock()
+	defer s.snapRelayMu.Unlock()
+	if t, ok := s.snapRelayFailed[to]; ok && time.Since(t) < snapshotRelayRetryDelay {
+		return nil
+	}
+	return src
+}
+
+// relaySnap asks the snapshot source of an observer to send its own snapshot
+// in place of the one of the leader. The source sends the state it applied,
+// which is at least as recent as the snapshot requested by raft, and the
+// observer skips the entries it already contains on replay as it does with
+// the snapshots of the leader.
+func (s *EtcdServer) relaySnap(m raftpb.Message, src *membership.Member) {
+	atomic.AddInt64(&s.inflightSnapshots, 1)
+
+	lg := s.Logger()
+	fields := []zap.Field{
+		zap.String("from", s.MemberId().String()),
+		zap.String("to", types.ID(m.To).String()),
+		zap.String("snapshot-source", src.ID.String()),
+		zap.Uint64("snapshot-index", m.Snapshot.Metadata.Index),
+	}
+	lg.Info("relaying snapshot to observer", fields...)
+
+	now := time.Now()
+	s.GoAttach(func() {
+		cc := &http.Client{Transport: s.peerRt}
+		var err error
+		for _, url := range src.PeerURLs {
+			if err = relaySnapshotHTTP(s.ctx, cc, url, m); err == nil {
+				break
+			}
+		}
+		if err != nil {
+			lg.Warn("failed to relay snapshot to observer; leader sends the next snapshots", append(fields, zap.Error(err))...)
+			s.snapRelayMu.Lock()
+			s.snapRelayFailed[types.ID(m.To)] = time.Now()
+			s.snapRelayMu.Unlock()
+			s.r.ReportSnapshot(m.To, raft.SnapshotFailure)
+			atomic.AddInt64(&s.inflightSnapshots, -1)
+			return
+		}
+		s.r.ReportSnapshot(m.To, raft.SnapshotFinish)
+		lg.Info("relayed snapshot to observer", append(fields, zap.Duration("took", time.Since(now)))...)
+
+		// as in sendMergedSnap, block log compaction while the observer
+		// catches up from the snapshot
+		select {
+		case <-time.After(releaseDelayAfterSnapshot):
+		case <-s.stopping:
+		}
+		atomic.AddInt64(&s.inflightSnapshots, -1)
+	})
+}
+
+type snapshotRelayHandler struct {
+	lg     *zap.Logger
+	server *EtcdServer
+}
+
+func (s *EtcdServer) SnapshotRelayHandler() http.Handler {
+	return &snapshotRelayHandler{lg: s.Logger(), server: s}
+}
+
+func (h *snapshotRelayHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "Method Not Allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if r.URL.Path != PeerSnapshotRelayPath {
+		http.Error(w, "bad path", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("X-Etcd-Cluster-ID", h.server.Cluster().ID().String())
+
+	defer r.Body.Close()
+	b, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "error reading body", http.StatusBadRequest)
+		return
+	}
+	var m raftpb.Message
+	if err = m.Unmarshal(b); err != nil || m.Type != raftpb.MsgSnap || m.Snapshot == nil {
+		http.Error(w, "error unmarshalling snapshot message", http.StatusBadRequest)
+		return
+	}
+	to := h.server.cluster.Member(types.ID(m.To))
+	if to == nil || !to.IsObserver || to.SnapshotSource != h.server.MemberId() {
+		http.Error(w, fmt.Sprintf("member %s is not the snapshot source of %s", h.server.MemberId(), types.ID(m.To)), http.StatusPreconditionFailed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), h.server.Cfg.ReqTimeout())
+	defer cancel()
+	// the merged snapshot must be at least as recent as the requested one
+	select {
+	case <-h.server.applyWait.Wait(m.Snapshot.Metadata.Index):
+	case <-ctx.Done():
+		http.Error(w, "timed out waiting for the snapshot index to be applied", http.StatusServiceUnavailable)
+		return
+	case <-h.server.stopping:
+		http.Error(w, "server stopping", http.StatusServiceUnavailable)
+		return
+	}
+
+	relay := snapRelay{m: m, donec: make(chan bool, 1)}
+	select {
+	case h.server.snapRelayc <- relay:
+	default:
+		http.Error(w, "too many snapshots in flight", http.StatusServiceUnavailable)
+		return
+	}
+	// the snapshot is sent once queued, even if the leader stops waiting
+	select {
+	case ok := <-relay.donec:
+		if !ok {
+			http.Error(w, "failed to send snapshot", http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	case <-r.Context().Done():
+	case <-h.server.stopping:
+		http.Error(w, "server stopping", http.StatusServiceUnavailable)
+	}
+}
+
+// relaySnapshotHTTP asks the member at the given peer url to send its
+// snapshot to the target of the given snapshot message, and waits until it is
+// sent.
+func relaySnapshotHTTP(ctx context.Context, cc *http.Client, url string, m raftpb.Message) error {
+	b, err := m.Marshal()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url+PeerSnapshotRelayPath, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/protobuf")
+	resp, err := cc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("snapshot relay: unexpected status %s (%s)", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
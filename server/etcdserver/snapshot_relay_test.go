@@ -0,0 +1,131 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package etcdserver
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/client/pkg/v3/types"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/membership"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v2store"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+func newObserverTestServer(t *testing.T) *EtcdServer {
+	lg := zaptest.NewLogger(t)
+	cl := membership.NewCluster(lg)
+	cl.SetStore(v2store.New())
+	members := []*membership.Member{
+		{ID: 1},
+		{ID: 2},
+		{ID: 3, RaftAttributes: membership.RaftAttributes{IsLearner: true}},
+		{ID: 4, RaftAttributes: membership.RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 2}},
+		{ID: 5, RaftAttributes: membership.RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 3}},
+		{ID: 6, RaftAttributes: membership.RaftAttributes{IsLearner: true, IsObserver: true, SnapshotSource: 1}},
+		{ID: 7, RaftAttributes: membership.RaftAttributes{IsLearner: true, IsObserver: true}},
+	}
+	for _, m := range members {
+		cl.AddMember(m, true)
+	}
+	r := newRaftNode(raftNodeConfig{
+		lg:        lg,
+		Node:      newNodeNop(),
+		transport: newNopTransporterWithActiveTime([]types.ID{1, 2, 3}),
+	})
+	return &EtcdServer{
+		lgMu:            new(sync.RWMutex),
+		lg:              lg,
+		memberId:        1,
+		r:               *r,
+		cluster:         cl,
+		snapRelayFailed: make(map[types.ID]time.Time),
+	}
+}
+
+func TestSnapshotSourceOf(t *testing.T) {
+	s := newObserverTestServer(t)
+	tests := []struct {
+		to   types.ID
+		want types.ID
+	}{
+		{to: 2},          // voting member
+		{to: 3},          // learner
+		{to: 4, want: 2}, // observer with a voting snapshot source
+		{to: 5},          // snapshot source is a learner
+		{to: 6},          // snapshot source is the local member
+		{to: 7},          // no snapshot source
+	}
+	for _, tt := range tests {
+		var got types.ID
+		if src := s.snapshotSourceOf(tt.to); src != nil {
+			got = src.ID
+		}
+		if got != tt.want {
+			t.Errorf("snapshot source of %s = %s, want %s", tt.to, got, tt.want)
+		}
+	}
+
+	// the leader sends the snapshots itself after a failed relay
+	s.snapRelayFailed[4] = time.Now()
+	if src := s.snapshotSourceOf(4); src != nil {
+		t.Errorf("snapshot source of 4 = %s after a failed relay, want none", src.ID)
+	}
+	s.snapRelayFailed[4] = time.Now().Add(-snapshotRelayRetryDelay)
+	if src := s.snapshotSourceOf(4); src == nil || src.ID != 2 {
+		t.Errorf("snapshot source of 4 = %v once the retry delay passed, want 2", src)
+	}
+}
+
+func TestSnapshotRelayHandler(t *testing.T) {
+	s := newObserverTestServer(t)
+	h := s.SnapshotRelayHandler()
+
+	msg := func(to uint64) []byte {
+		m := raftpb.Message{Type: raftpb.MsgSnap, From: 2, To: to, Snapshot: &raftpb.Snapshot{}}
+		b, err := m.Marshal()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return b
+	}
+	tests := []struct {
+		name   string
+		method string
+		body   []byte
+		want   int
+	}{
+		{name: "wrong method", method: http.MethodGet, want: http.StatusMethodNotAllowed},
+		{name: "not a snapshot", method: http.MethodPost, body: []byte("foo"), want: http.StatusBadRequest},
+		{name: "not an observer", method: http.MethodPost, body: msg(2), want: http.StatusPreconditionFailed},
+		{name: "not the snapshot source", method: http.MethodPost, body: msg(4), want: http.StatusPreconditionFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, PeerSnapshotRelayPath, bytes.NewReader(tt.body))
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (%s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txn
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+)
+
+// continueToken is the decoded continue_token of range requests. Clients
+// treat tokens as opaque, so the encoding may change between versions as long
+// as tokens issued by older members are still accepted.
+type continueToken struct {
+	// Rev is the revision the paginated range is pinned to.
+	Rev int64 `json:"rev"`
+	// Start is the first key of the next page.
+	Start []byte `json:"start"`
+}
+
+func encodeContinueToken(rev int64, lastKey []byte) string {
+	start := make([]byte, len(lastKey)+1)
+	copy(start, lastKey)
+	data, _ := json.Marshal(continueToken{Rev: rev, Start: start})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// CheckContinueToken returns an error if the continue token of the range
+// request is malformed or was not issued for the range of the request.
+func CheckContinueToken(r *pb.RangeRequest) error {
+	if r.ContinueToken == "" {
+		return nil
+	}
+	_, err := decodeContinueToken(r)
+	return err
+}
+
+func decodeContinueToken(r *pb.RangeRequest) (*continueToken, error) {
+	data, err := base64.RawURLEncoding.DecodeString(r.ContinueToken)
+	if err != nil {
+		return nil, errors.ErrInvalidContinueToken
+	}
+	var tok continueToken
+	if err = json.Unmarshal(data, &tok); err != nil || tok.Rev <= 0 {
+		return nil, errors.ErrInvalidContinueToken
+	}
+	// tokens only resume ranges over several keys in key order, from a key
+	// within the requested range and at the revision of the first page
+	if len(r.RangeEnd) == 0 || !isKeyOrdered(r) ||
+		bytes.Compare(tok.Start, r.Key) < 0 ||
+		(r.Revision != 0 && r.Revision != tok.Rev) {
+		return nil, errors.ErrInvalidContinueToken
+	}
+	return &tok, nil
+}
+
+// isKeyOrdered returns true if the range request returns keys in ascending
+// key order.
+func isKeyOrdered(r *pb.RangeRequest) bool {
+	return r.SortTarget == pb.RangeRequest_KEY && r.SortOrder != pb.RangeRequest_DESCEND
+}
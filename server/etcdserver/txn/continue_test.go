@@ -0,0 +1,110 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+func TestRangeContinueToken(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer s.Close()
+	lg := zaptest.NewLogger(t)
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		s.Put([]byte(k), []byte("v1"), lease.NoLease)
+	}
+	rev := s.Rev()
+
+	req := &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), Limit: 2}
+	var keys []string
+	for page := 0; ; page++ {
+		resp, err := Range(context.TODO(), lg, s, nil, req)
+		require.NoError(t, err)
+		for _, kv := range resp.Kvs {
+			keys = append(keys, string(kv.Key))
+		}
+		if page == 0 {
+			// later writes are not visible to the following pages
+			s.Put([]byte("c"), []byte("v2"), lease.NoLease)
+			s.Put([]byte("bb"), []byte("v2"), lease.NoLease)
+		} else {
+			for _, kv := range resp.Kvs {
+				assert.Equal(t, []byte("v1"), kv.Value)
+			}
+		}
+		assert.Equal(t, resp.More, resp.ContinueToken != "")
+		if !resp.More {
+			break
+		}
+		req.ContinueToken = resp.ContinueToken
+	}
+	assert.Equal(t, []string{"a", "b", "c", "d", "e"}, keys)
+
+	// tokens are only issued for ranges in key order
+	resp, err := Range(context.TODO(), lg, s, nil, &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), Limit: 2, SortTarget: pb.RangeRequest_MOD})
+	require.NoError(t, err)
+	assert.True(t, resp.More)
+	assert.Empty(t, resp.ContinueToken)
+
+	// tokens are rejected once their revision is compacted
+	token := encodeContinueToken(rev, []byte("b"))
+	_, err = Range(context.TODO(), lg, s, nil, &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), ContinueToken: token})
+	require.NoError(t, err)
+	done, err := s.Compact(traceutil.TODO(), s.Rev())
+	require.NoError(t, err)
+	<-done
+	_, err = Range(context.TODO(), lg, s, nil, &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), ContinueToken: token})
+	assert.Equal(t, mvcc.ErrCompacted, err)
+}
+
+func TestCheckContinueToken(t *testing.T) {
+	token := encodeContinueToken(5, []byte("b"))
+	tcs := []struct {
+		name    string
+		req     *pb.RangeRequest
+		wantErr error
+	}{
+		{"no token", &pb.RangeRequest{Key: []byte("a")}, nil},
+		{"valid", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), ContinueToken: token}, nil},
+		{"same revision", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), Revision: 5, ContinueToken: token}, nil},
+		{"key ascending", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), SortOrder: pb.RangeRequest_ASCEND, ContinueToken: token}, nil},
+		{"malformed", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), ContinueToken: "foo"}, errors.ErrInvalidContinueToken},
+		{"single key", &pb.RangeRequest{Key: []byte("a"), ContinueToken: token}, errors.ErrInvalidContinueToken},
+		{"other range", &pb.RangeRequest{Key: []byte("c"), RangeEnd: []byte("z"), ContinueToken: token}, errors.ErrInvalidContinueToken},
+		{"other revision", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), Revision: 4, ContinueToken: token}, errors.ErrInvalidContinueToken},
+		{"sorted", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), SortTarget: pb.RangeRequest_VALUE, ContinueToken: token}, errors.ErrInvalidContinueToken},
+		{"descending", &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), SortOrder: pb.RangeRequest_DESCEND, ContinueToken: token}, errors.ErrInvalidContinueToken},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.wantErr, CheckContinueToken(tc.req))
+		})
+	}
+}
@@ -0,0 +1,260 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txn
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+)
+
+// CheckRangeFilter returns an error if the range filter is malformed.
+func CheckRangeFilter(f *pb.RangeFilter) error {
+	if _, ok := pb.RangeFilter_FilterResult_name[int32(f.Result)]; !ok {
+		return fmt.Errorf("unknown filter result %d", f.Result)
+	}
+	if _, ok := pb.RangeFilter_FilterTarget_name[int32(f.Target)]; !ok {
+		return fmt.Errorf("unknown filter target %d", f.Target)
+	}
+
+	var ok bool
+	switch f.Target {
+	case pb.RangeFilter_VERSION:
+		_, ok = f.TargetUnion.(*pb.RangeFilter_Version)
+	case pb.RangeFilter_CREATE:
+		_, ok = f.TargetUnion.(*pb.RangeFilter_CreateRevision)
+	case pb.RangeFilter_MOD:
+		_, ok = f.TargetUnion.(*pb.RangeFilter_ModRevision)
+	case pb.RangeFilter_LEASE:
+		_, ok = f.TargetUnion.(*pb.RangeFilter_Lease)
+	case pb.RangeFilter_VALUE, pb.RangeFilter_JSON:
+		_, ok = f.TargetUnion.(*pb.RangeFilter_Value)
+	}
+	if !ok {
+		return fmt.Errorf("filter target %v does not match the filter value", f.Target)
+	}
+	if f.Result == pb.RangeFilter_PREFIX && f.Target != pb.RangeFilter_VALUE && f.Target != pb.RangeFilter_JSON {
+		return fmt.Errorf("filter target %v does not support %v", f.Target, f.Result)
+	}
+
+	if f.Target != pb.RangeFilter_JSON {
+		if f.JsonPath != "" {
+			return fmt.Errorf("json path given for filter target %v", f.Target)
+		}
+		return nil
+	}
+	if _, err := parseJSONPath(f.JsonPath); err != nil {
+		return err
+	}
+	if !json.Valid(f.GetValue()) {
+		return fmt.Errorf("filter value %q is not valid JSON", f.GetValue())
+	}
+	return nil
+}
+
+// NewRangeFilter returns the mvcc filter matching the key-values that match
+// all the given range filters, or nil if there are no filters.
+func NewRangeFilter(filters []*pb.RangeFilter) func(kv *mvccpb.KeyValue) bool {
+	if len(filters) == 0 {
+		return nil
+	}
+	matchers := make([]func(kv *mvccpb.KeyValue) bool, len(filters))
+	for i, f := range filters {
+		matchers[i] = newFilterMatcher(f)
+	}
+	return func(kv *mvccpb.KeyValue) bool {
+		for _, match := range matchers {
+			if !match(kv) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+func newFilterMatcher(f *pb.RangeFilter) func(kv *mvccpb.KeyValue) bool {
+	if f.Target != pb.RangeFilter_JSON {
+		return func(kv *mvccpb.KeyValue) bool { return matchFilter(f, kv) }
+	}
+
+	path, err := parseJSONPath(f.JsonPath)
+	var want interface{}
+	if err == nil {
+		err = json.Unmarshal(f.GetValue(), &want)
+	}
+	if err != nil {
+		// malformed filters are rejected by the API layer
+		return func(kv *mvccpb.KeyValue) bool { return false }
+	}
+	return func(kv *mvccpb.KeyValue) bool {
+		var doc interface{}
+		if err := json.Unmarshal(kv.Value, &doc); err != nil {
+			return false
+		}
+		v, ok := lookupJSONPath(doc, path)
+		if !ok {
+			return false
+		}
+		return matchJSON(f.Result, v, want)
+	}
+}
+
+func matchFilter(f *pb.RangeFilter, kv *mvccpb.KeyValue) bool {
+	var result int
+	switch f.Target {
+	case pb.RangeFilter_VALUE:
+		if f.Result == pb.RangeFilter_PREFIX {
+			return bytes.HasPrefix(kv.Value, f.GetValue())
+		}
+		result = bytes.Compare(kv.Value, f.GetValue())
+	case pb.RangeFilter_VERSION:
+		result = compareInt64(kv.Version, f.GetVersion())
+	case pb.RangeFilter_CREATE:
+		result = compareInt64(kv.CreateRevision, f.GetCreateRevision())
+	case pb.RangeFilter_MOD:
+		result = compareInt64(kv.ModRevision, f.GetModRevision())
+	case pb.RangeFilter_LEASE:
+		result = compareInt64(kv.Lease, f.GetLease())
+	default:
+		return false
+	}
+	return matchResult(f.Result, result)
+}
+
+func matchResult(r pb.RangeFilter_FilterResult, result int) bool {
+	switch r {
+	case pb.RangeFilter_EQUAL:
+		return result == 0
+	case pb.RangeFilter_NOT_EQUAL:
+		return result != 0
+	case pb.RangeFilter_GREATER:
+		return result > 0
+	case pb.RangeFilter_LESS:
+		return result < 0
+	}
+	return false
+}
+
+// matchJSON compares the decoded JSON values v and want. Only numbers and
+// strings are ordered, and only strings have prefixes.
+func matchJSON(r pb.RangeFilter_FilterResult, v, want interface{}) bool {
+	switch r {
+	case pb.RangeFilter_EQUAL:
+		return reflect.DeepEqual(v, want)
+	case pb.RangeFilter_NOT_EQUAL:
+		return !reflect.DeepEqual(v, want)
+	case pb.RangeFilter_PREFIX:
+		s, ok := v.(string)
+		prefix, pok := want.(string)
+		return ok && pok && strings.HasPrefix(s, prefix)
+	}
+
+	var result int
+	switch tv := v.(type) {
+	case float64:
+		w, ok := want.(float64)
+		if !ok {
+			return false
+		}
+		switch {
+		case tv < w:
+			result = -1
+		case tv > w:
+			result = 1
+		}
+	case string:
+		w, ok := want.(string)
+		if !ok {
+			return false
+		}
+		result = strings.Compare(tv, w)
+	default:
+		return false
+	}
+	return matchResult(r, result)
+}
+
+// jsonPathElem is an element of a JSON path: an object key, or an array index
+// if the key is empty.
+type jsonPathElem struct {
+	key   string
+	index int
+}
+
+// parseJSONPath parses a path such as "spec.containers[0].image". An optional
+// leading "$." is ignored, and "$" alone is the whole document.
+func parseJSONPath(path string) ([]jsonPathElem, error) {
+	if path == "$" {
+		return nil, nil
+	}
+	p := strings.TrimPrefix(path, "$.")
+	if p == "" {
+		return nil, fmt.Errorf("empty json path")
+	}
+
+	var elems []jsonPathElem
+	for _, part := range strings.Split(p, ".") {
+		name, rest := part, ""
+		if i := strings.IndexByte(part, '['); i >= 0 {
+			name, rest = part[:i], part[i:]
+		}
+		if name == "" && rest == "" {
+			return nil, fmt.Errorf("invalid json path %q: empty element", path)
+		}
+		if name != "" {
+			elems = append(elems, jsonPathElem{key: name})
+		}
+		for rest != "" {
+			end := strings.IndexByte(rest, ']')
+			if rest[0] != '[' || end < 0 {
+				return nil, fmt.Errorf("invalid json path %q: malformed index", path)
+			}
+			index, err := strconv.Atoi(rest[1:end])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid json path %q: invalid index %q", path, rest[1:end])
+			}
+			elems = append(elems, jsonPathElem{index: index})
+			rest = rest[end+1:]
+		}
+	}
+	return elems, nil
+}
+
+func lookupJSONPath(doc interface{}, path []jsonPathElem) (interface{}, bool) {
+	for _, elem := range path {
+		if elem.key != "" {
+			obj, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = obj[elem.key]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		arr, ok := doc.([]interface{})
+		if !ok || elem.index >= len(arr) {
+			return nil, false
+		}
+		doc = arr[elem.index]
+	}
+	return doc, true
+}
@@ -0,0 +1,152 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package txn
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+func valueFilter(result pb.RangeFilter_FilterResult, v string) *pb.RangeFilter {
+	return &pb.RangeFilter{Result: result, Target: pb.RangeFilter_VALUE, TargetUnion: &pb.RangeFilter_Value{Value: []byte(v)}}
+}
+
+func jsonFilter(path string, result pb.RangeFilter_FilterResult, v string) *pb.RangeFilter {
+	return &pb.RangeFilter{Result: result, Target: pb.RangeFilter_JSON, TargetUnion: &pb.RangeFilter_Value{Value: []byte(v)}, JsonPath: path}
+}
+
+func TestRangeFilterMatch(t *testing.T) {
+	kv := &mvccpb.KeyValue{
+		Key:            []byte("k"),
+		Value:          []byte(`{"name":"foo","replicas":3,"spec":{"ports":[80,443]}}`),
+		CreateRevision: 2,
+		ModRevision:    5,
+		Version:        3,
+		Lease:          7,
+	}
+
+	tcs := []struct {
+		name    string
+		filters []*pb.RangeFilter
+		want    bool
+	}{
+		{"no filters", nil, true},
+		{"value equal", []*pb.RangeFilter{valueFilter(pb.RangeFilter_EQUAL, string(kv.Value))}, true},
+		{"value not equal", []*pb.RangeFilter{valueFilter(pb.RangeFilter_NOT_EQUAL, string(kv.Value))}, false},
+		{"value prefix", []*pb.RangeFilter{valueFilter(pb.RangeFilter_PREFIX, `{"name"`)}, true},
+		{"value greater", []*pb.RangeFilter{valueFilter(pb.RangeFilter_GREATER, "{")}, true},
+		{"version range", []*pb.RangeFilter{
+			{Result: pb.RangeFilter_GREATER, Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 1}},
+			{Result: pb.RangeFilter_LESS, Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 3}},
+		}, false},
+		{"create revision", []*pb.RangeFilter{{Target: pb.RangeFilter_CREATE, TargetUnion: &pb.RangeFilter_CreateRevision{CreateRevision: 2}}}, true},
+		{"mod revision", []*pb.RangeFilter{{Result: pb.RangeFilter_LESS, Target: pb.RangeFilter_MOD, TargetUnion: &pb.RangeFilter_ModRevision{ModRevision: 5}}}, false},
+		{"lease", []*pb.RangeFilter{{Target: pb.RangeFilter_LEASE, TargetUnion: &pb.RangeFilter_Lease{Lease: 7}}}, true},
+		{"json string", []*pb.RangeFilter{jsonFilter("name", pb.RangeFilter_EQUAL, `"foo"`)}, true},
+		{"json string prefix", []*pb.RangeFilter{jsonFilter("$.name", pb.RangeFilter_PREFIX, `"fo"`)}, true},
+		{"json number", []*pb.RangeFilter{jsonFilter("replicas", pb.RangeFilter_GREATER, "2")}, true},
+		{"json number mismatch", []*pb.RangeFilter{jsonFilter("replicas", pb.RangeFilter_GREATER, `"2"`)}, false},
+		{"json array element", []*pb.RangeFilter{jsonFilter("spec.ports[1]", pb.RangeFilter_EQUAL, "443")}, true},
+		{"json object", []*pb.RangeFilter{jsonFilter("spec", pb.RangeFilter_EQUAL, `{"ports":[80,443]}`)}, true},
+		{"json missing path", []*pb.RangeFilter{jsonFilter("spec.ports[2]", pb.RangeFilter_NOT_EQUAL, "1")}, false},
+		{"json malformed path", []*pb.RangeFilter{jsonFilter("spec..ports", pb.RangeFilter_NOT_EQUAL, "1")}, false},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			filter := NewRangeFilter(tc.filters)
+			if tc.filters == nil {
+				assert.Nil(t, filter)
+				return
+			}
+			assert.Equal(t, tc.want, filter(kv))
+		})
+	}
+
+	// values that are not JSON never match JSON filters
+	filter := NewRangeFilter([]*pb.RangeFilter{jsonFilter("$", pb.RangeFilter_NOT_EQUAL, "1")})
+	assert.False(t, filter(&mvccpb.KeyValue{Value: []byte("foo")}))
+}
+
+func TestParseJSONPath(t *testing.T) {
+	tcs := []struct {
+		path    string
+		want    []jsonPathElem
+		wantErr bool
+	}{
+		{path: "$", want: nil},
+		{path: "a", want: []jsonPathElem{{key: "a"}}},
+		{path: "$.a.b", want: []jsonPathElem{{key: "a"}, {key: "b"}}},
+		{path: "a[0][2].b", want: []jsonPathElem{{key: "a"}, {index: 0}, {index: 2}, {key: "b"}}},
+		{path: "[1]", want: []jsonPathElem{{index: 1}}},
+		{path: "", wantErr: true},
+		{path: "$.", wantErr: true},
+		{path: "a.", wantErr: true},
+		{path: "a[", wantErr: true},
+		{path: "a[x]", wantErr: true},
+		{path: "a[-1]", wantErr: true},
+		{path: "a[0]b", wantErr: true},
+	}
+	for _, tc := range tcs {
+		got, err := parseJSONPath(tc.path)
+		if tc.wantErr {
+			assert.Error(t, err, tc.path)
+			continue
+		}
+		require.NoError(t, err, tc.path)
+		assert.Equal(t, tc.want, got, tc.path)
+	}
+}
+
+func TestRangeWithFilters(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer s.Close()
+	lg := zaptest.NewLogger(t)
+
+	for _, k := range []string{"a", "b", "c", "d"} {
+		s.Put([]byte(k), []byte(`{"owner":"`+k+`"}`), lease.NoLease)
+	}
+	s.Put([]byte("b"), []byte(`{"owner":"b"}`), lease.NoLease)
+	s.Put([]byte("c"), []byte(`{"owner":"c"}`), lease.NoLease)
+
+	versionFilter := &pb.RangeFilter{Result: pb.RangeFilter_GREATER, Target: pb.RangeFilter_VERSION, TargetUnion: &pb.RangeFilter_Version{Version: 1}}
+	req := &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), Limit: 1, Filters: []*pb.RangeFilter{versionFilter}}
+	resp, err := Range(context.TODO(), lg, s, nil, req)
+	require.NoError(t, err)
+	// the limit applies after filtering and the count includes all matches
+	require.Len(t, resp.Kvs, 1)
+	assert.Equal(t, []byte("b"), resp.Kvs[0].Key)
+	assert.Equal(t, int64(2), resp.Count)
+	assert.True(t, resp.More)
+
+	req = &pb.RangeRequest{Key: []byte("a"), RangeEnd: []byte("z"), CountOnly: true, Filters: []*pb.RangeFilter{
+		jsonFilter("owner", pb.RangeFilter_NOT_EQUAL, `"a"`),
+	}}
+	resp, err = Range(context.TODO(), lg, s, nil, req)
+	require.NoError(t, err)
+	assert.Empty(t, resp.Kvs)
+	assert.Equal(t, int64(3), resp.Count)
+}
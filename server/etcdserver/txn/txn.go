@@ -45,7 +45,17 @@ func Put(ctx context.Context, lg *zap.Logger, lessor lease.Lessor, kv mvcc.KV, t
 	}
 	val, leaseID := p.Value, lease.LeaseID(p.Lease)
 	if txnWrite == nil {
-		if leaseID != lease.NoLease {
+		if p.Ttl > 0 {
+			rv := kv.Read(mvcc.ConcurrentReadTxMode, trace)
+			err = checkRequestPut(rv, lessor, &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: p}})
+			rv.End()
+			if err == nil {
+				err = grantKeyLeases(lessor, []*pb.PutRequest{p})
+			}
+			if err != nil {
+				return nil, nil, err
+			}
+		} else if leaseID != lease.NoLease {
 			if l := lessor.Lookup(leaseID); l == nil {
 				return nil, nil, lease.ErrLeaseNotFound
 			}
@@ -125,6 +135,15 @@ func Range(ctx context.Context, lg *zap.Logger, kv mvcc.KV, txnRead mvcc.TxnRead
 		defer txnRead.End()
 	}
 
+	key, rev := r.Key, r.Revision
+	if r.ContinueToken != "" {
+		tok, err := decodeContinueToken(r)
+		if err != nil {
+			return nil, err
+		}
+		key, rev = tok.Start, tok.Rev
+	}
+
 	limit := r.Limit
 	if r.SortOrder != pb.RangeRequest_NONE ||
 		r.MinModRevision != 0 || r.MaxModRevision != 0 ||
@@ -138,12 +157,13 @@ func Range(ctx context.Context, lg *zap.Logger, kv mvcc.KV, txnRead mvcc.TxnRead
 	}
 
 	ro := mvcc.RangeOptions{
-		Limit: limit,
-		Rev:   r.Revision,
-		Count: r.CountOnly,
+		Limit:  limit,
+		Rev:    rev,
+		Count:  r.CountOnly,
+		Filter: NewRangeFilter(r.Filters),
 	}
 
-	rr, err := txnRead.Range(ctx, r.Key, mkGteRange(r.RangeEnd), ro)
+	rr, err := txnRead.Range(ctx, key, mkGteRange(r.RangeEnd), ro)
 	if err != nil {
 		return nil, err
 	}
@@ -204,6 +224,12 @@ func Range(ctx context.Context, lg *zap.Logger, kv mvcc.KV, txnRead mvcc.TxnRead
 	if r.Limit > 0 && len(rr.KVs) > int(r.Limit) {
 		rr.KVs = rr.KVs[:r.Limit]
 		resp.More = true
+		if isKeyOrdered(r) {
+			if rev == 0 {
+				rev = rr.Rev
+			}
+			resp.ContinueToken = encodeContinueToken(rev, rr.KVs[len(rr.KVs)-1].Key)
+		}
 	}
 	trace.Step("filter and sort the key-value pairs")
 	resp.Header.Revision = rr.Rev
@@ -244,10 +270,16 @@ func Txn(ctx context.Context, lg *zap.Logger, rt *pb.TxnRequest, txnModeWriteWit
 		"compare",
 	)
 
+	var ttlPuts []*pb.PutRequest
 	if isWrite {
 		trace.AddField(traceutil.Field{Key: "read_only", Value: false})
 		if _, err := checkRequests(txnWrite, rt, txnPath,
-			func(rv mvcc.ReadView, ro *pb.RequestOp) error { return checkRequestPut(rv, lessor, ro) }); err != nil {
+			func(rv mvcc.ReadView, ro *pb.RequestOp) error {
+				if p := ro.GetRequestPut(); p != nil && p.Ttl > 0 {
+					ttlPuts = append(ttlPuts, p)
+				}
+				return checkRequestPut(rv, lessor, ro)
+			}); err != nil {
 			txnWrite.End()
 			return nil, nil, err
 		}
@@ -257,7 +289,7 @@ func Txn(ctx context.Context, lg *zap.Logger, rt *pb.TxnRequest, txnModeWriteWit
 		return nil, nil, err
 	}
 	trace.Step("check requests")
-	txnResp, _ := newTxnResp(rt, txnPath)
+	txnResp, _ := newTxnResp(txnWrite, rt, txnPath)
 
 	// When executing mutable txnWrite ops, etcd must hold the txnWrite lock so
 	// readers do not see any intermediate results. Since writes are
@@ -265,6 +297,9 @@ func Txn(ctx context.Context, lg *zap.Logger, rt *pb.TxnRequest, txnModeWriteWit
 	// be the revision of the write txnWrite.
 	if isWrite {
 		txnWrite.End()
+		if err := grantKeyLeases(lessor, ttlPuts); err != nil {
+			return nil, nil, err
+		}
 		txnWrite = kv.Write(trace)
 	}
 	_, err := applyTxn(ctx, lg, kv, lessor, txnWrite, rt, txnPath, txnResp)
@@ -293,8 +328,9 @@ func Txn(ctx context.Context, lg *zap.Logger, rt *pb.TxnRequest, txnModeWriteWit
 	return txnResp, trace, err
 }
 
-// newTxnResp allocates a txn response for a txn request given a path.
-func newTxnResp(rt *pb.TxnRequest, txnPath []bool) (txnResp *pb.TxnResponse, txnCount int) {
+// newTxnResp allocates a txn response for a txn request given a path, with
+// the conflicts of its read set in the given read view.
+func newTxnResp(rv mvcc.ReadView, rt *pb.TxnRequest, txnPath []bool) (txnResp *pb.TxnResponse, txnCount int) {
 	reqs := rt.Success
 	if !txnPath[0] {
 		reqs = rt.Failure
@@ -305,6 +341,9 @@ func newTxnResp(rt *pb.TxnRequest, txnPath []bool) (txnResp *pb.TxnResponse, txn
 		Succeeded: txnPath[0],
 		Header:    &pb.ResponseHeader{},
 	}
+	if !txnPath[0] {
+		txnResp.Conflicts = readSetConflicts(rv, rt.ReadSet)
+	}
 	for i, req := range reqs {
 		switch tv := req.Request.(type) {
 		case *pb.RequestOp_RequestRange:
@@ -314,7 +353,7 @@ func newTxnResp(rt *pb.TxnRequest, txnPath []bool) (txnResp *pb.TxnResponse, txn
 		case *pb.RequestOp_RequestDeleteRange:
 			resps[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseDeleteRange{}}
 		case *pb.RequestOp_RequestTxn:
-			resp, txns := newTxnResp(tv.RequestTxn, txnPath[1:])
+			resp, txns := newTxnResp(rv, tv.RequestTxn, txnPath[1:])
 			resps[i] = &pb.ResponseOp{Response: &pb.ResponseOp_ResponseTxn{ResponseTxn: resp}}
 			txnPath = txnPath[1+txns:]
 			txnCount += txns + 1
@@ -398,6 +437,16 @@ func checkRequestPut(rv mvcc.ReadView, lessor lease.Lessor, reqOp *pb.RequestOp)
 			return errors.ErrKeyNotFound
 		}
 	}
+	if req.Ttl > 0 {
+		// the lease backing the ttl is granted right before the put
+		if req.Ttl > lease.MaxLeaseTTL {
+			return lease.ErrLeaseTTLTooLarge
+		}
+		if l := lessor.Lookup(lease.LeaseID(req.Lease)); l != nil {
+			return lease.ErrLeaseExists
+		}
+		return nil
+	}
 	if lease.LeaseID(req.Lease) != lease.NoLease {
 		if l := lessor.Lookup(lease.LeaseID(req.Lease)); l == nil {
 			return lease.ErrLeaseNotFound
@@ -406,18 +455,38 @@ func checkRequestPut(rv mvcc.ReadView, lessor lease.Lessor, reqOp *pb.RequestOp)
 	return nil
 }
 
+// grantKeyLeases grants the leases backing the ttl of the given puts. The
+// lessor persists leases in a transaction of its own, so they must be granted
+// before the write transaction of the puts begins.
+func grantKeyLeases(lessor lease.Lessor, puts []*pb.PutRequest) error {
+	for _, p := range puts {
+		if _, err := lessor.GrantKeyLease(lease.LeaseID(p.Lease), p.Ttl); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func checkRequestRange(rv mvcc.ReadView, reqOp *pb.RequestOp) error {
 	tv, ok := reqOp.Request.(*pb.RequestOp_RequestRange)
 	if !ok || tv.RequestRange == nil {
 		return nil
 	}
 	req := tv.RequestRange
+	rev := req.Revision
+	if req.ContinueToken != "" {
+		tok, err := decodeContinueToken(req)
+		if err != nil {
+			return err
+		}
+		rev = tok.Rev
+	}
 	switch {
-	case req.Revision == 0:
+	case rev == 0:
 		return nil
-	case req.Revision > rv.Rev():
+	case rev > rv.Rev():
 		return mvcc.ErrFutureRev
-	case req.Revision < rv.FirstRev():
+	case rev < rv.FirstRev():
 		return mvcc.ErrCompacted
 	}
 	return nil
@@ -521,7 +590,7 @@ func compareInt64(a, b int64) int {
 func compareToPath(rv mvcc.ReadView, rt *pb.TxnRequest) []bool {
 	txnPath := make([]bool, 1)
 	ops := rt.Success
-	if txnPath[0] = applyCompares(rv, rt.Compare); !txnPath[0] {
+	if txnPath[0] = applyCompares(rv, rt.Compare) && len(readSetConflicts(rv, rt.ReadSet)) == 0; !txnPath[0] {
 		ops = rt.Failure
 	}
 	for _, op := range ops {
@@ -571,6 +640,29 @@ func applyCompare(rv mvcc.ReadView, c *pb.Compare) bool {
 	return true
 }
 
+// readSetConflicts returns the current key-values of the keys of the read set
+// which changed since they were read. A deleted key only has its key set.
+func readSetConflicts(rv mvcc.ReadView, rs []*pb.ReadSetEntry) []*mvccpb.KeyValue {
+	var conflicts []*mvccpb.KeyValue
+	for _, e := range rs {
+		rr, err := rv.Range(context.TODO(), e.Key, nil, mvcc.RangeOptions{})
+		if err != nil {
+			// fails the read set, as a failed compare fails the txn
+			conflicts = append(conflicts, &mvccpb.KeyValue{Key: e.Key})
+			continue
+		}
+		switch {
+		case len(rr.KVs) == 0:
+			if e.ModRevision != 0 {
+				conflicts = append(conflicts, &mvccpb.KeyValue{Key: e.Key})
+			}
+		case rr.KVs[0].ModRevision != e.ModRevision:
+			conflicts = append(conflicts, &rr.KVs[0])
+		}
+	}
+	return conflicts
+}
+
 func compareKV(c *pb.Compare, ckv mvccpb.KeyValue) bool {
 	var result int
 	rev := int64(0)
@@ -643,12 +735,65 @@ func IsTxnReadonly(r *pb.TxnRequest) bool {
 	return true
 }
 
+// HasReadSet returns true if the txn or one of its nested txns has a read set.
+func HasReadSet(r *pb.TxnRequest) bool {
+	if len(r.ReadSet) != 0 {
+		return true
+	}
+	for _, reqs := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, u := range reqs {
+			if t := u.GetRequestTxn(); t != nil && HasReadSet(t) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// HasKeyTTL returns true if one of the puts of the txn or of its nested txns
+// has a ttl.
+func HasKeyTTL(r *pb.TxnRequest) bool {
+	for _, reqs := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, u := range reqs {
+			switch tv := u.Request.(type) {
+			case *pb.RequestOp_RequestPut:
+				if tv.RequestPut.Ttl > 0 {
+					return true
+				}
+			case *pb.RequestOp_RequestTxn:
+				if HasKeyTTL(tv.RequestTxn) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// TxnPuts returns the put requests the txn executes when its compares are
+// evaluated against the given read view, in execution order.
+func TxnPuts(rv mvcc.ReadView, rt *pb.TxnRequest) []*pb.PutRequest {
+	var puts []*pb.PutRequest
+	_, _ = checkRequests(rv, rt, compareToPath(rv, rt), func(_ mvcc.ReadView, ro *pb.RequestOp) error {
+		if p := ro.GetRequestPut(); p != nil {
+			puts = append(puts, p)
+		}
+		return nil
+	})
+	return puts
+}
+
 func CheckTxnAuth(as auth.AuthStore, ai *auth.AuthInfo, rt *pb.TxnRequest) error {
 	for _, c := range rt.Compare {
 		if err := as.IsRangePermitted(ai, c.Key, c.RangeEnd); err != nil {
 			return err
 		}
 	}
+	for _, e := range rt.ReadSet {
+		if err := as.IsRangePermitted(ai, e.Key, nil); err != nil {
+			return err
+		}
+	}
 	if err := checkTxnReqsPermission(as, ai, rt.Success); err != nil {
 		return err
 	}
@@ -676,6 +821,12 @@ func checkTxnReqsPermission(as auth.AuthStore, ai *auth.AuthInfo, reqs []*pb.Req
 				return err
 			}
 
+			if tv.RequestPut.Lease != 0 {
+				if err := as.IsLeasePermitted(ai, tv.RequestPut.Key); err != nil {
+					return err
+				}
+			}
+
 		case *pb.RequestOp_RequestDeleteRange:
 			if tv.RequestDeleteRange == nil {
 				continue
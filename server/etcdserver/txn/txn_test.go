@@ -22,11 +22,14 @@ import (
 	"go.uber.org/zap/zaptest"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/errors"
 	"go.etcd.io/etcd/server/v3/lease"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestReadonlyTxnError(t *testing.T) {
@@ -94,3 +97,186 @@ func TestWriteTxnPanic(t *testing.T) {
 
 	assert.Panics(t, func() { Txn(ctx, zaptest.NewLogger(t), txn, false, s, &lease.FakeLessor{}) }, "Expected panic in Txn with writes")
 }
+
+func TestTxnPuts(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer s.Close()
+
+	s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+
+	put := func(key string) *pb.RequestOp {
+		return &pb.RequestOp{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte(key)}}}
+	}
+	valueIs := func(value string) []*pb.Compare {
+		return []*pb.Compare{{
+			Key:         []byte("foo"),
+			Target:      pb.Compare_VALUE,
+			Result:      pb.Compare_EQUAL,
+			TargetUnion: &pb.Compare_Value{Value: []byte(value)},
+		}}
+	}
+
+	txn := &pb.TxnRequest{
+		Compare: valueIs("bar"),
+		Success: []*pb.RequestOp{
+			put("a"),
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{
+				Compare: valueIs("baz"),
+				Success: []*pb.RequestOp{put("b")},
+				Failure: []*pb.RequestOp{put("c")},
+			}}},
+			{Request: &pb.RequestOp_RequestDeleteRange{RequestDeleteRange: &pb.DeleteRangeRequest{Key: []byte("foo")}}},
+			put("d"),
+		},
+		Failure: []*pb.RequestOp{put("e")},
+	}
+
+	var keys []string
+	for _, p := range TxnPuts(s, txn) {
+		keys = append(keys, string(p.Key))
+	}
+	assert.Equal(t, []string{"a", "c", "d"}, keys)
+}
+
+func TestPutWithTTL(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	le := lease.NewLessor(zaptest.NewLogger(t), b, nil, lease.LessorConfig{MinLeaseTTL: 5})
+	defer le.Stop()
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, le, mvcc.StoreConfig{})
+	defer s.Close()
+	lg := zaptest.NewLogger(t)
+
+	getKV := func(key string) mvccpb.KeyValue {
+		rr, err := s.Range(context.TODO(), []byte(key), nil, mvcc.RangeOptions{})
+		require.NoError(t, err)
+		require.Len(t, rr.KVs, 1)
+		return rr.KVs[0]
+	}
+
+	_, _, err := Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("a"), Value: []byte("v"), Lease: 1, Ttl: 10})
+	require.NoError(t, err)
+	kv := getKV("a")
+	assert.Equal(t, int64(1), kv.Lease)
+	assert.Equal(t, int64(10), kv.Ttl)
+	l := le.Lookup(1)
+	require.NotNil(t, l)
+	assert.True(t, l.IsKeyLease())
+	assert.Equal(t, []string{"a"}, l.Keys())
+
+	// the ttl is raised to the minimum lease ttl
+	_, _, err = Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("b"), Lease: 2, Ttl: 1})
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), getKV("b").Ttl)
+
+	_, _, err = Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("c"), Lease: 1, Ttl: 10})
+	assert.Equal(t, lease.ErrLeaseExists, err)
+
+	// no lease is granted if the put fails
+	_, _, err = Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("c"), IgnoreValue: true, Lease: 3, Ttl: 10})
+	assert.Equal(t, errors.ErrKeyNotFound, err)
+	assert.Nil(t, le.Lookup(3))
+
+	txn := &pb.TxnRequest{
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("d"), Lease: 4, Ttl: 20}}},
+		},
+	}
+	assert.True(t, HasKeyTTL(txn))
+	assert.True(t, HasKeyTTL(&pb.TxnRequest{Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: txn}}}}))
+	assert.False(t, HasKeyTTL(&pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("d"), Lease: 4}}}}}))
+	_, _, err = Txn(context.TODO(), lg, txn, false, s, le)
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), getKV("d").Ttl)
+	assert.True(t, le.Lookup(4).IsKeyLease())
+
+	// overwriting the key without a ttl revokes its lease
+	_, _, err = Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("d")})
+	require.NoError(t, err)
+	kv = getKV("d")
+	assert.Equal(t, int64(0), kv.Lease)
+	assert.Equal(t, int64(0), kv.Ttl)
+	assert.Nil(t, le.Lookup(4))
+
+	// as does overwriting it with another ttl
+	_, _, err = Put(context.TODO(), lg, le, s, nil, &pb.PutRequest{Key: []byte("a"), Lease: 5, Ttl: 10})
+	require.NoError(t, err)
+	assert.Nil(t, le.Lookup(1))
+	assert.Equal(t, []string{"a"}, le.Lookup(5).Keys())
+
+	// and deleting it
+	_, err = DeleteRange(s, nil, &pb.DeleteRangeRequest{Key: []byte("a")})
+	require.NoError(t, err)
+	assert.Nil(t, le.Lookup(5))
+	assert.Len(t, le.Leases(), 1)
+
+	// the revoked key leases are deleted from the backend
+	nle := lease.NewLessor(zaptest.NewLogger(t), b, nil, lease.LessorConfig{MinLeaseTTL: 5})
+	defer nle.Stop()
+	for _, l := range nle.Leases() {
+		assert.Equal(t, lease.LeaseID(2), l.ID)
+	}
+	assert.Len(t, nle.Leases(), 1)
+}
+
+func TestTxnReadSet(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+	s := mvcc.NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, mvcc.StoreConfig{})
+	defer s.Close()
+	lg := zaptest.NewLogger(t)
+
+	fooRev := s.Put([]byte("foo"), []byte("bar"), lease.NoLease)
+	barRev := s.Put([]byte("bar"), []byte("bar"), lease.NoLease)
+
+	readSet := []*pb.ReadSetEntry{
+		{Key: []byte("foo"), ModRevision: fooRev},
+		{Key: []byte("bar"), ModRevision: barRev},
+		{Key: []byte("baz")},
+	}
+	txn := &pb.TxnRequest{
+		ReadSet: readSet,
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestPut{RequestPut: &pb.PutRequest{Key: []byte("foo"), Value: []byte("baz")}}},
+		},
+	}
+	resp, _, err := Txn(context.TODO(), lg, txn, false, s, &lease.FakeLessor{})
+	require.NoError(t, err)
+	assert.True(t, resp.Succeeded)
+	assert.Empty(t, resp.Conflicts)
+
+	s.DeleteRange([]byte("bar"), nil)
+	bazRev := s.Put([]byte("baz"), []byte("baz"), lease.NoLease)
+
+	resp, _, err = Txn(context.TODO(), lg, txn, false, s, &lease.FakeLessor{})
+	require.NoError(t, err)
+	assert.False(t, resp.Succeeded)
+	require.Len(t, resp.Conflicts, 3)
+	assert.Equal(t, []byte("foo"), resp.Conflicts[0].Key)
+	assert.Equal(t, []byte("baz"), resp.Conflicts[0].Value)
+	assert.Equal(t, mvccpb.KeyValue{Key: []byte("bar")}, *resp.Conflicts[1])
+	assert.Equal(t, bazRev, resp.Conflicts[2].ModRevision)
+
+	// the read set is checked in nested txns
+	readSet[0].ModRevision, readSet[1].ModRevision, readSet[2].ModRevision = resp.Conflicts[0].ModRevision, 0, bazRev
+	readSet = append(readSet, &pb.ReadSetEntry{Key: []byte("foo"), ModRevision: fooRev})
+	txn = &pb.TxnRequest{
+		ReadSet: readSet[:3],
+		Success: []*pb.RequestOp{
+			{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{ReadSet: readSet[3:]}}},
+		},
+	}
+	assert.True(t, HasReadSet(txn))
+	assert.True(t, HasReadSet(&pb.TxnRequest{Failure: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{ReadSet: readSet[3:]}}}}}))
+	assert.False(t, HasReadSet(&pb.TxnRequest{Success: []*pb.RequestOp{{Request: &pb.RequestOp_RequestTxn{RequestTxn: &pb.TxnRequest{}}}}}))
+	resp, _, err = Txn(context.TODO(), lg, txn, false, s, &lease.FakeLessor{})
+	require.NoError(t, err)
+	assert.True(t, resp.Succeeded)
+	assert.Empty(t, resp.Conflicts)
+	nested := resp.Responses[0].GetResponseTxn()
+	assert.False(t, nested.Succeeded)
+	require.Len(t, nested.Conflicts, 1)
+	assert.Equal(t, []byte("foo"), nested.Conflicts[0].Key)
+}
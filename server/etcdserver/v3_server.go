@@ -97,6 +97,9 @@ type Authenticator interface {
 	RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest) (*pb.AuthRoleDeleteResponse, error)
 	UserList(ctx context.Context, r *pb.AuthUserListRequest) (*pb.AuthUserListResponse, error)
 	RoleList(ctx context.Context, r *pb.AuthRoleListRequest) (*pb.AuthRoleListResponse, error)
+	TenantAdd(ctx context.Context, r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error)
+	TenantDelete(ctx context.Context, r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error)
+	TenantList(ctx context.Context, r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error)
 }
 
 func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
@@ -120,7 +123,13 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 		trace.LogIfLong(traceThreshold)
 	}(time.Now())
 
-	if !r.Serializable {
+	if !r.Serializable && (r.MaxStalenessMs > 0 || r.MaxStalenessRevisions > 0) {
+		err = s.boundedStalenessReadNotify(ctx, r)
+		trace.Step("local state within staleness bound before reading")
+		if err != nil {
+			return nil, err
+		}
+	} else if !r.Serializable {
 		err = s.linearizableReadNotify(ctx)
 		trace.Step("agreement among raft nodes before linearized reading")
 		if err != nil {
@@ -140,7 +149,12 @@ func (s *EtcdServer) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeRe
 }
 
 func (s *EtcdServer) Put(ctx context.Context, r *pb.PutRequest) (*pb.PutResponse, error) {
+	// members before v3.6 apply the put without granting its key lease
+	if r.Ttl > 0 && !s.keyTTLSupported() {
+		return nil, errors.ErrKeyTTLNotSupported
+	}
 	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
+	s.chooseKeyLease(r)
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Put: r})
 	if err != nil {
 		return nil, err
@@ -157,6 +171,15 @@ func (s *EtcdServer) DeleteRange(ctx context.Context, r *pb.DeleteRangeRequest)
 }
 
 func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, error) {
+	// members before v3.6 apply the txn without validating the read set
+	if txn.HasReadSet(r) {
+		if cv := s.ClusterVersion(); cv == nil || cv.LessThan(version.V3_6) {
+			return nil, errors.ErrReadSetNotSupported
+		}
+	}
+	if txn.HasKeyTTL(r) && !s.keyTTLSupported() {
+		return nil, errors.ErrKeyTTLNotSupported
+	}
 	if txn.IsTxnReadonly(r) {
 		trace := traceutil.New("transaction",
 			s.Logger(),
@@ -191,6 +214,7 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 	}
 
 	ctx = context.WithValue(ctx, traceutil.StartTimeKey, time.Now())
+	s.chooseTxnKeyLeases(r)
 	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{Txn: r})
 	if err != nil {
 		return nil, err
@@ -198,6 +222,37 @@ func (s *EtcdServer) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse
 	return resp.(*pb.TxnResponse), nil
 }
 
+func (s *EtcdServer) keyTTLSupported() bool {
+	cv := s.ClusterVersion()
+	return cv != nil && !cv.LessThan(version.V3_6)
+}
+
+// chooseKeyLease chooses the ID of the lease backing the ttl of a put. The
+// lease is granted by every member when the put is applied, so the ID must be
+// part of the proposal.
+func (s *EtcdServer) chooseKeyLease(r *pb.PutRequest) {
+	if r.Ttl <= 0 {
+		return
+	}
+	for r.Lease == int64(lease.NoLease) {
+		// only use positive int64 id's
+		r.Lease = int64(s.reqIDGen.Next() & ((1 << 63) - 1))
+	}
+}
+
+func (s *EtcdServer) chooseTxnKeyLeases(r *pb.TxnRequest) {
+	for _, ops := range [][]*pb.RequestOp{r.Success, r.Failure} {
+		for _, op := range ops {
+			switch tv := op.Request.(type) {
+			case *pb.RequestOp_RequestPut:
+				s.chooseKeyLease(tv.RequestPut)
+			case *pb.RequestOp_RequestTxn:
+				s.chooseTxnKeyLeases(tv.RequestTxn)
+			}
+		}
+	}
+}
+
 func (s *EtcdServer) Compact(ctx context.Context, r *pb.CompactionRequest) (*pb.CompactionResponse, error) {
 	startTime := time.Now()
 	result, err := s.processInternalRaftRequestOnce(ctx, pb.InternalRaftRequest{Compaction: r})
@@ -414,6 +469,29 @@ func (s *EtcdServer) Alarm(ctx context.Context, r *pb.AlarmRequest) (*pb.AlarmRe
 	return resp.(*pb.AlarmResponse), nil
 }
 
+func (s *EtcdServer) QuotaSet(ctx context.Context, r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{QuotaSet: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.QuotaSetResponse), nil
+}
+
+func (s *EtcdServer) QuotaDelete(ctx context.Context, r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error) {
+	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{QuotaDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.QuotaDeleteResponse), nil
+}
+
+func (s *EtcdServer) QuotaList(ctx context.Context, r *pb.QuotaListRequest) (*pb.QuotaListResponse, error) {
+	if err := s.linearizableReadNotify(ctx); err != nil {
+		return nil, err
+	}
+	return &pb.QuotaListResponse{Quotas: s.quotaStore.List()}, nil
+}
+
 func (s *EtcdServer) AuthEnable(ctx context.Context, r *pb.AuthEnableRequest) (*pb.AuthEnableResponse, error) {
 	resp, err := s.raftRequestOnce(ctx, pb.InternalRaftRequest{AuthEnable: r})
 	if err != nil {
@@ -607,6 +685,30 @@ func (s *EtcdServer) RoleDelete(ctx context.Context, r *pb.AuthRoleDeleteRequest
 	return resp.(*pb.AuthRoleDeleteResponse), nil
 }
 
+func (s *EtcdServer) TenantAdd(ctx context.Context, r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error) {
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthTenantAdd: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthTenantAddResponse), nil
+}
+
+func (s *EtcdServer) TenantDelete(ctx context.Context, r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error) {
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthTenantDelete: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthTenantDeleteResponse), nil
+}
+
+func (s *EtcdServer) TenantList(ctx context.Context, r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error) {
+	resp, err := s.raftRequest(ctx, pb.InternalRaftRequest{AuthTenantList: r})
+	if err != nil {
+		return nil, err
+	}
+	return resp.(*pb.AuthTenantListResponse), nil
+}
+
 func (s *EtcdServer) raftRequestOnce(ctx context.Context, r pb.InternalRaftRequest) (proto.Message, error) {
 	result, err := s.processInternalRaftRequestOnce(ctx, r)
 	if err != nil {
@@ -881,6 +983,25 @@ func (s *EtcdServer) LinearizableReadNotify(ctx context.Context) error {
 }
 
 func (s *EtcdServer) linearizableReadNotify(ctx context.Context) error {
+	if s.Cfg.ExperimentalLeaseRead {
+		if ok, err := s.leaseReadNotify(ctx); ok {
+			linearizableReads.WithLabelValues("lease").Inc()
+			return err
+		}
+		linearizableReads.WithLabelValues("read_index").Inc()
+		if s.isLeader() {
+			// the ReadIndex confirming the read renews the expired lease
+			return s.renewLeaderLease(ctx)
+		}
+		return s.readIndexNotify(ctx)
+	}
+	linearizableReads.WithLabelValues("read_index").Inc()
+	return s.readIndexNotify(ctx)
+}
+
+// readIndexNotify waits for the linearizable read loop to confirm the
+// committed index with a ReadIndex, and for the local member to apply it.
+func (s *EtcdServer) readIndexNotify(ctx context.Context) error {
 	s.readMu.RLock()
 	nc := s.readNotifier
 	s.readMu.RUnlock()
@@ -28,6 +28,7 @@ type Lease struct {
 	ID           LeaseID
 	ttl          int64 // time to live of the lease in seconds
 	remainingTTL int64 // remaining time to live in seconds, if zero valued it is considered unset and the full ttl should be used
+	keyLease     bool  // backs the ttl of a key put with a ttl
 	// expiryMu protects concurrent accesses to expiry
 	expiryMu sync.RWMutex
 	// expiry is time when lease should expire. no expiration when expiry.IsZero() is true
@@ -44,7 +45,7 @@ func (l *Lease) expired() bool {
 }
 
 func (l *Lease) persistTo(b backend.Backend) {
-	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL}
+	lpb := leasepb.Lease{ID: int64(l.ID), TTL: l.ttl, RemainingTTL: l.remainingTTL, KeyLease: l.keyLease}
 	tx := b.BatchTx()
 	tx.LockInsideApply()
 	defer tx.Unlock()
@@ -56,6 +57,12 @@ func (l *Lease) TTL() int64 {
 	return l.ttl
 }
 
+// IsKeyLease returns true if the lease was granted to back the TTL of a key
+// put with a TTL rather than by a LeaseGrant request.
+func (l *Lease) IsKeyLease() bool {
+	return l.keyLease
+}
+
 // RemainingTTL returns the last checkpointed remaining TTL of the lease.
 func (l *Lease) getRemainingTTL() int64 {
 	if l.remainingTTL > 0 {
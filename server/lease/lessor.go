@@ -90,6 +90,10 @@ type Lessor interface {
 
 	// Grant grants a lease that expires at least after TTL seconds.
 	Grant(id LeaseID, ttl int64) (*Lease, error)
+	// GrantKeyLease grants a lease backing the TTL of a key put with a TTL.
+	// It behaves as Grant, except that keys attached to the lease report
+	// its TTL.
+	GrantKeyLease(id LeaseID, ttl int64) (*Lease, error)
 	// Revoke revokes a lease with given ID. The item attached to the
 	// given lease will be removed. If the ID does not exist, an error
 	// will be returned.
@@ -108,7 +112,9 @@ type Lessor interface {
 	GetLease(item LeaseItem) LeaseID
 
 	// Detach detaches given leaseItem from the lease with given LeaseID.
-	// If the lease does not exist, an error will be returned.
+	// If the lease does not exist, an error will be returned. A key lease
+	// is revoked along with the detach of its key, so it must be called
+	// inside the write transaction removing the key from the lease.
 	Detach(id LeaseID, items []LeaseItem) error
 
 	// Promote promotes the lessor to be the primary lessor. Primary lessor manages
@@ -269,6 +275,14 @@ func (le *lessor) SetCheckpointer(cp Checkpointer) {
 }
 
 func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
+	return le.grant(id, ttl, false)
+}
+
+func (le *lessor) GrantKeyLease(id LeaseID, ttl int64) (*Lease, error) {
+	return le.grant(id, ttl, true)
+}
+
+func (le *lessor) grant(id LeaseID, ttl int64, keyLease bool) (*Lease, error) {
 	if id == NoLease {
 		return nil, ErrLeaseNotFound
 	}
@@ -280,10 +294,11 @@ func (le *lessor) Grant(id LeaseID, ttl int64) (*Lease, error) {
 	// TODO: when lessor is under high load, it should give out lease
 	// with longer TTL to reduce renew load.
 	l := &Lease{
-		ID:      id,
-		ttl:     ttl,
-		itemSet: make(map[LeaseItem]struct{}),
-		revokec: make(chan struct{}),
+		ID:       id,
+		ttl:      ttl,
+		keyLease: keyLease,
+		itemSet:  make(map[LeaseItem]struct{}),
+		revokec:  make(chan struct{}),
 	}
 
 	if l.ttl < le.minLeaseTTL {
@@ -580,7 +595,19 @@ func (le *lessor) Detach(id LeaseID, items []LeaseItem) error {
 		delete(l.itemSet, it)
 		delete(le.itemMap, it)
 	}
+	unused := l.keyLease && len(l.itemSet) == 0
 	l.mu.Unlock()
+
+	if unused {
+		// the key of a key lease was overwritten or deleted; the lease is
+		// deleted in the write transaction of the key
+		delete(le.leaseMap, id)
+		close(l.revokec)
+		if le.b != nil {
+			schema.UnsafeDeleteLease(le.b.BatchTx(), &leasepb.Lease{ID: int64(l.ID)})
+		}
+		leaseRevoked.Inc()
+	}
 	return nil
 }
 
@@ -808,6 +835,7 @@ func (le *lessor) initAndRecover() {
 			expiry:       forever,
 			revokec:      make(chan struct{}),
 			remainingTTL: lpb.RemainingTTL,
+			keyLease:     lpb.KeyLease,
 		}
 	}
 	le.leaseExpiredNotifier.Init()
@@ -826,6 +854,8 @@ func (fl *FakeLessor) SetCheckpointer(cp Checkpointer) {}
 
 func (fl *FakeLessor) Grant(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
 
+func (fl *FakeLessor) GrantKeyLease(id LeaseID, ttl int64) (*Lease, error) { return nil, nil }
+
 func (fl *FakeLessor) Revoke(id LeaseID) error { return nil }
 
 func (fl *FakeLessor) Checkpoint(id LeaseID, remainingTTL int64) error { return nil }
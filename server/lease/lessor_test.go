@@ -426,6 +426,92 @@ func TestLessorRecover(t *testing.T) {
 	}
 }
 
+func TestLessorGrantKeyLease(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	l1, err := le.GrantKeyLease(1, 10)
+	if err != nil {
+		t.Fatalf("could not grant key lease (%v)", err)
+	}
+	if !l1.IsKeyLease() {
+		t.Errorf("IsKeyLease() = false, want true")
+	}
+	if _, err = le.GrantKeyLease(1, 10); err != ErrLeaseExists {
+		t.Errorf("err = %v, want %v", err, ErrLeaseExists)
+	}
+	l2, err := le.Grant(2, 10)
+	if err != nil {
+		t.Fatalf("could not grant lease (%v)", err)
+	}
+	if l2.IsKeyLease() {
+		t.Errorf("IsKeyLease() = true, want false")
+	}
+
+	// key leases stay key leases after recovery
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if nl1 := nle.Lookup(l1.ID); nl1 == nil || !nl1.IsKeyLease() {
+		t.Errorf("recovered lease %v is not a key lease", l1.ID)
+	}
+	if nl2 := nle.Lookup(l2.ID); nl2 == nil || nl2.IsKeyLease() {
+		t.Errorf("recovered lease %v is a key lease", l2.ID)
+	}
+}
+
+func TestLessorDetachKeyLease(t *testing.T) {
+	lg := zap.NewNop()
+	dir, be := NewTestBackend(t)
+	defer os.RemoveAll(dir)
+	defer be.Close()
+
+	le := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer le.Stop()
+	kl, err := le.GrantKeyLease(1, 10)
+	if err != nil {
+		t.Fatalf("could not grant key lease (%v)", err)
+	}
+	if _, err = le.Grant(2, 10); err != nil {
+		t.Fatalf("could not grant lease (%v)", err)
+	}
+	items := []LeaseItem{{Key: "foo"}}
+	for _, id := range []LeaseID{1, 2} {
+		if err = le.Attach(id, items); err != nil {
+			t.Fatalf("failed to attach items to lease %v (%v)", id, err)
+		}
+		tx := be.BatchTx()
+		tx.LockInsideApply()
+		err = le.Detach(id, items)
+		tx.Unlock()
+		if err != nil {
+			t.Fatalf("failed to detach items from lease %v (%v)", id, err)
+		}
+	}
+
+	// the key lease is revoked once its key is detached
+	if l := le.Lookup(1); l != nil {
+		t.Errorf("key lease %v still exists", l.ID)
+	}
+	select {
+	case <-kl.revokec:
+	default:
+		t.Errorf("key lease %v is not done", kl.ID)
+	}
+	if l := le.Lookup(2); l == nil {
+		t.Errorf("lease 2 was revoked")
+	}
+
+	nle := newLessor(lg, be, clusterLatest(), LessorConfig{MinLeaseTTL: minLeaseTTL})
+	defer nle.Stop()
+	if l := nle.Lookup(1); l != nil {
+		t.Errorf("recovered revoked key lease %v", l.ID)
+	}
+}
+
 func TestLessorExpire(t *testing.T) {
 	lg := zap.NewNop()
 	dir, be := NewTestBackend(t)
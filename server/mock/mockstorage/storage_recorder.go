@@ -61,3 +61,4 @@ func (p *storageRecorder) Sync() error {
 
 func (p *storageRecorder) Close() error                        { return nil }
 func (p *storageRecorder) MinimalEtcdVersion() *semver.Version { return nil }
+func (p *storageRecorder) Scrub(func(n int) error) error       { return nil }
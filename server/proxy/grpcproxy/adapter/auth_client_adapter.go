@@ -95,3 +95,15 @@ func (s *as2ac) UserRevokeRole(ctx context.Context, in *pb.AuthUserRevokeRoleReq
 func (s *as2ac) UserChangePassword(ctx context.Context, in *pb.AuthUserChangePasswordRequest, opts ...grpc.CallOption) (*pb.AuthUserChangePasswordResponse, error) {
 	return s.as.UserChangePassword(ctx, in)
 }
+
+func (s *as2ac) TenantAdd(ctx context.Context, in *pb.AuthTenantAddRequest, opts ...grpc.CallOption) (*pb.AuthTenantAddResponse, error) {
+	return s.as.TenantAdd(ctx, in)
+}
+
+func (s *as2ac) TenantDelete(ctx context.Context, in *pb.AuthTenantDeleteRequest, opts ...grpc.CallOption) (*pb.AuthTenantDeleteResponse, error) {
+	return s.as.TenantDelete(ctx, in)
+}
+
+func (s *as2ac) TenantList(ctx context.Context, in *pb.AuthTenantListRequest, opts ...grpc.CallOption) (*pb.AuthTenantListResponse, error) {
+	return s.as.TenantList(ctx, in)
+}
@@ -56,6 +56,22 @@ func (s *mts2mtc) Downgrade(ctx context.Context, r *pb.DowngradeRequest, opts ..
 	return s.mts.Downgrade(ctx, r)
 }
 
+func (s *mts2mtc) QuotaSet(ctx context.Context, r *pb.QuotaSetRequest, opts ...grpc.CallOption) (*pb.QuotaSetResponse, error) {
+	return s.mts.QuotaSet(ctx, r)
+}
+
+func (s *mts2mtc) QuotaDelete(ctx context.Context, r *pb.QuotaDeleteRequest, opts ...grpc.CallOption) (*pb.QuotaDeleteResponse, error) {
+	return s.mts.QuotaDelete(ctx, r)
+}
+
+func (s *mts2mtc) QuotaList(ctx context.Context, r *pb.QuotaListRequest, opts ...grpc.CallOption) (*pb.QuotaListResponse, error) {
+	return s.mts.QuotaList(ctx, r)
+}
+
+func (s *mts2mtc) RotateEncryptionKey(ctx context.Context, r *pb.RotateEncryptionKeyRequest, opts ...grpc.CallOption) (*pb.RotateEncryptionKeyResponse, error) {
+	return s.mts.RotateEncryptionKey(ctx, r)
+}
+
 func (s *mts2mtc) Snapshot(ctx context.Context, in *pb.SnapshotRequest, opts ...grpc.CallOption) (pb.Maintenance_SnapshotClient, error) {
 	cs := newPipeStream(ctx, func(ss chanServerStream) error {
 		return s.mts.Snapshot(in, &ss2scServerStream{ss})
@@ -90,3 +106,28 @@ func (s *ss2scServerStream) Recv() (*pb.SnapshotRequest, error) {
 	}
 	return v.(*pb.SnapshotRequest), nil
 }
+
+func (s *mts2mtc) SnapshotDelta(ctx context.Context, in *pb.SnapshotDeltaRequest, opts ...grpc.CallOption) (pb.Maintenance_SnapshotDeltaClient, error) {
+	cs := newPipeStream(ctx, func(ss chanServerStream) error {
+		return s.mts.SnapshotDelta(in, &sds2sdcServerStream{ss})
+	})
+	return &sds2sdcClientStream{cs}, nil
+}
+
+// sds2sdcClientStream implements Maintenance_SnapshotDeltaClient
+type sds2sdcClientStream struct{ chanClientStream }
+
+// sds2sdcServerStream implements Maintenance_SnapshotDeltaServer
+type sds2sdcServerStream struct{ chanServerStream }
+
+func (s *sds2sdcClientStream) Recv() (*pb.SnapshotDeltaResponse, error) {
+	var v interface{}
+	if err := s.RecvMsg(&v); err != nil {
+		return nil, err
+	}
+	return v.(*pb.SnapshotDeltaResponse), nil
+}
+
+func (s *sds2sdcServerStream) Send(rr *pb.SnapshotDeltaResponse) error {
+	return s.SendMsg(rr)
+}
@@ -96,3 +96,15 @@ func (ap *AuthProxy) UserRevokeRole(ctx context.Context, r *pb.AuthUserRevokeRol
 func (ap *AuthProxy) UserChangePassword(ctx context.Context, r *pb.AuthUserChangePasswordRequest) (*pb.AuthUserChangePasswordResponse, error) {
 	return ap.authClient.UserChangePassword(ctx, r)
 }
+
+func (ap *AuthProxy) TenantAdd(ctx context.Context, r *pb.AuthTenantAddRequest) (*pb.AuthTenantAddResponse, error) {
+	return ap.authClient.TenantAdd(ctx, r)
+}
+
+func (ap *AuthProxy) TenantDelete(ctx context.Context, r *pb.AuthTenantDeleteRequest) (*pb.AuthTenantDeleteResponse, error) {
+	return ap.authClient.TenantDelete(ctx, r)
+}
+
+func (ap *AuthProxy) TenantList(ctx context.Context, r *pb.AuthTenantListRequest) (*pb.AuthTenantListResponse, error) {
+	return ap.authClient.TenantList(ctx, r)
+}
@@ -0,0 +1,270 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/google/btree"
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/server/v3/etcdserver/txn"
+	"go.etcd.io/etcd/server/v3/storage/mvcc"
+)
+
+var (
+	// ErrNotCovered is returned by View.Range for requests it cannot serve.
+	ErrNotCovered = errors.New("range not covered by the view")
+	// ErrNotReady is returned by View.Range while the view is not loaded.
+	ErrNotReady = errors.New("view not ready")
+)
+
+// View is a materialized view of the key-values under a set of prefixes, at
+// a single revision. It is loaded with Reset and kept coherent with the
+// cluster by applying the events of a watch covering the prefixes.
+type View struct {
+	lg *zap.Logger
+
+	mu sync.RWMutex
+	// prefixes are the [begin, end) key ranges of the view, sorted by begin;
+	// an empty end is the end of the key space.
+	prefixes [][2][]byte
+	kvs      *btree.BTreeG[*mvccpb.KeyValue]
+	rev      int64
+	ready    bool
+	// revc is closed when the revision of the view increases.
+	revc chan struct{}
+}
+
+// NewView returns a view of the key-values under the given prefixes. The
+// view is not ready until loaded with Reset.
+func NewView(lg *zap.Logger, prefixes []string) *View {
+	v := &View{
+		lg:   lg,
+		kvs:  btree.NewG(32, func(a, b *mvccpb.KeyValue) bool { return bytes.Compare(a.Key, b.Key) < 0 }),
+		revc: make(chan struct{}),
+	}
+	for _, p := range prefixes {
+		v.prefixes = append(v.prefixes, [2][]byte{[]byte(p), prefixEnd([]byte(p))})
+	}
+	sort.Slice(v.prefixes, func(i, j int) bool { return bytes.Compare(v.prefixes[i][0], v.prefixes[j][0]) < 0 })
+	return v
+}
+
+// Prefixes returns the prefixes of the view, sorted.
+func (v *View) Prefixes() []string {
+	ps := make([]string, len(v.prefixes))
+	for i, p := range v.prefixes {
+		ps[i] = string(p[0])
+	}
+	return ps
+}
+
+// Reset loads the view with the key-values under its prefixes at the given
+// revision, and marks it ready.
+func (v *View) Reset(kvs []*mvccpb.KeyValue, rev int64) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.kvs.Clear(false)
+	for _, kv := range kvs {
+		if v.contains(kv.Key) {
+			v.kvs.ReplaceOrInsert(kv)
+		}
+	}
+	v.ready = true
+	v.advance(rev)
+}
+
+// Apply applies the events of a watch up to the given revision. The events
+// of the keys outside of the prefixes are ignored, so that a single watch
+// can keep both the view and other caches coherent.
+func (v *View) Apply(rev int64, evs []*mvccpb.Event) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, ev := range evs {
+		if !v.contains(ev.Kv.Key) {
+			continue
+		}
+		switch ev.Type {
+		case mvccpb.PUT:
+			v.kvs.ReplaceOrInsert(ev.Kv)
+		case mvccpb.DELETE:
+			v.kvs.Delete(ev.Kv)
+		}
+	}
+	v.advance(rev)
+}
+
+// Invalidate marks the view as not ready, until the next Reset.
+func (v *View) Invalidate() {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.ready = false
+}
+
+// advance sets the revision of the view, and wakes up the waiters of a lower
+// revision.
+func (v *View) advance(rev int64) {
+	if rev <= v.rev {
+		return
+	}
+	v.rev = rev
+	close(v.revc)
+	v.revc = make(chan struct{})
+}
+
+// Rev returns the revision of the view, and whether it is ready.
+func (v *View) Rev() (int64, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.rev, v.ready
+}
+
+// Len returns the number of key-values in the view.
+func (v *View) Len() int {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.kvs.Len()
+}
+
+// WaitRev waits until the revision of the view is at least rev.
+func (v *View) WaitRev(ctx context.Context, rev int64) error {
+	for {
+		v.mu.RLock()
+		cur, revc := v.rev, v.revc
+		v.mu.RUnlock()
+		if cur >= rev {
+			return nil
+		}
+		select {
+		case <-revc:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Covers returns whether the view holds every key in the range of the given
+// request.
+func (v *View) Covers(r *pb.RangeRequest) bool {
+	var end []byte
+	switch {
+	case len(r.RangeEnd) == 1 && r.RangeEnd[0] == 0:
+		end = []byte{}
+	case len(r.RangeEnd) != 0:
+		end = r.RangeEnd
+	}
+	for _, p := range v.prefixes {
+		if bytes.Compare(r.Key, p[0]) < 0 {
+			continue
+		}
+		switch {
+		case len(p[1]) == 0:
+			// the prefix extends to the end of the key space
+			return true
+		case end == nil:
+			if bytes.Compare(r.Key, p[1]) < 0 {
+				return true
+			}
+		case len(end) != 0:
+			if bytes.Compare(end, p[1]) <= 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Range serves the given range request from the view, at the revision of
+// the view. Only the revision of the response header is set.
+func (v *View) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	if !v.Covers(r) {
+		return nil, ErrNotCovered
+	}
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	if !v.ready {
+		return nil, ErrNotReady
+	}
+	return txn.Range(ctx, v.lg, nil, (*viewTxnRead)(v), r)
+}
+
+func (v *View) contains(key []byte) bool {
+	for _, p := range v.prefixes {
+		if bytes.Compare(key, p[0]) >= 0 && (len(p[1]) == 0 || bytes.Compare(key, p[1]) < 0) {
+			return true
+		}
+	}
+	return false
+}
+
+// viewTxnRead reads a view as a mvcc.TxnRead at the revision of the view, so
+// that ranges are served with the options and the semantics of the server.
+// The read lock of the view is held by the caller.
+type viewTxnRead View
+
+func (tr *viewTxnRead) FirstRev() int64 { return tr.rev }
+func (tr *viewTxnRead) Rev() int64      { return tr.rev }
+func (tr *viewTxnRead) End()            {}
+
+func (tr *viewTxnRead) Range(ctx context.Context, key, end []byte, ro mvcc.RangeOptions) (*mvcc.RangeResult, error) {
+	if ro.Rev > 0 && ro.Rev != tr.rev {
+		// only the latest revision is held
+		return nil, ErrNotCovered
+	}
+	rr := &mvcc.RangeResult{Rev: tr.rev}
+	visit := func(kv *mvccpb.KeyValue) bool {
+		if ro.Filter != nil && !ro.Filter(kv) {
+			return true
+		}
+		rr.Count++
+		if !ro.Count && (ro.Limit <= 0 || int64(len(rr.KVs)) < ro.Limit) {
+			rr.KVs = append(rr.KVs, *kv)
+		}
+		return true
+	}
+	pivot := &mvccpb.KeyValue{Key: key}
+	switch {
+	case end == nil:
+		if kv, ok := tr.kvs.Get(pivot); ok {
+			visit(kv)
+		}
+	case len(end) == 0:
+		tr.kvs.AscendGreaterOrEqual(pivot, visit)
+	default:
+		tr.kvs.AscendRange(pivot, &mvccpb.KeyValue{Key: end}, visit)
+	}
+	return rr, ctx.Err()
+}
+
+// prefixEnd returns the end of the range of the keys with the given prefix,
+// or an empty end if the range extends to the end of the key space.
+func prefixEnd(prefix []byte) []byte {
+	end := make([]byte, len(prefix))
+	copy(end, prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{}
+}
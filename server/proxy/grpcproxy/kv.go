@@ -16,6 +16,9 @@ package grpcproxy
 
 import (
 	"context"
+	"time"
+
+	"go.uber.org/zap"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
@@ -25,6 +28,9 @@ import (
 type kvProxy struct {
 	kv    clientv3.KV
 	cache cache.Cache
+	// coherent is the cache kept coherent by a watch, if any, which is also
+	// the cache of the responses.
+	coherent *coherentCache
 }
 
 func NewKvProxy(c *clientv3.Client) (pb.KVServer, <-chan struct{}) {
@@ -37,7 +43,32 @@ func NewKvProxy(c *clientv3.Client) (pb.KVServer, <-chan struct{}) {
 	return kv, donec
 }
 
+// NewCoherentKvProxy returns a kv proxy whose cache is kept coherent with the
+// cluster by a watch, including the writes that do not pass through the
+// proxy. The key-values under the given prefixes are kept in a materialized
+// view, which also serves the linearizable reads of the prefixes after a
+// revision check. The returned channel is closed once the client is closed.
+func NewCoherentKvProxy(lg *zap.Logger, c *clientv3.Client, prefixes []string) (pb.KVServer, <-chan struct{}) {
+	cc := newCoherentCache(lg, c, prefixes)
+	kv := &kvProxy{
+		kv:       c.KV,
+		cache:    cc,
+		coherent: cc,
+	}
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		cc.run(c.Ctx())
+	}()
+	return kv, donec
+}
+
 func (p *kvProxy) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, error) {
+	if p.coherent != nil {
+		if resp, ok := p.coherent.Range(ctx, r); ok {
+			return resp, nil
+		}
+	}
 	if r.Serializable {
 		resp, err := p.cache.Get(r)
 		switch err {
@@ -111,6 +142,9 @@ func (p *kvProxy) Txn(ctx context.Context, r *pb.TxnRequest) (*pb.TxnResponse, e
 	for _, cmp := range r.Compare {
 		p.cache.Invalidate(cmp.Key, cmp.RangeEnd)
 	}
+	for _, e := range r.ReadSet {
+		p.cache.Invalidate(e.Key, nil)
+	}
 	// update any fetched keys
 	if resp.Succeeded {
 		p.txnToCache(r.Success, resp.Responses)
@@ -176,6 +210,18 @@ func RangeRequestToOp(r *pb.RangeRequest) clientv3.Op {
 	opts = append(opts, clientv3.WithMinCreateRev(r.MinCreateRevision))
 	opts = append(opts, clientv3.WithMaxModRev(r.MaxModRevision))
 	opts = append(opts, clientv3.WithMinModRev(r.MinModRevision))
+	for _, f := range r.Filters {
+		opts = append(opts, clientv3.WithFilter(clientv3.RangeFilter(*f)))
+	}
+	if r.ContinueToken != "" {
+		opts = append(opts, clientv3.WithContinue(r.ContinueToken))
+	}
+	if r.MaxStalenessMs != 0 {
+		opts = append(opts, clientv3.WithMaxStaleness(time.Duration(r.MaxStalenessMs)*time.Millisecond))
+	}
+	if r.MaxStalenessRevisions != 0 {
+		opts = append(opts, clientv3.WithMaxStalenessRevisions(r.MaxStalenessRevisions))
+	}
 	if r.CountOnly {
 		opts = append(opts, clientv3.WithCountOnly())
 	}
@@ -198,6 +244,9 @@ func PutRequestToOp(r *pb.PutRequest) clientv3.Op {
 	if r.IgnoreLease {
 		opts = append(opts, clientv3.WithIgnoreLease())
 	}
+	if r.Ttl != 0 {
+		opts = append(opts, clientv3.WithTTL(r.Ttl))
+	}
 	if r.PrevKv {
 		opts = append(opts, clientv3.WithPrevKV())
 	}
@@ -228,5 +277,13 @@ func TxnRequestToOp(r *pb.TxnRequest) clientv3.Op {
 	for i := range r.Failure {
 		elseops[i] = requestOpToOp(r.Failure[i])
 	}
-	return clientv3.OpTxn(cmps, thenops, elseops)
+	op := clientv3.OpTxn(cmps, thenops, elseops)
+	if len(r.ReadSet) != 0 {
+		rs := make([]clientv3.ReadSetEntry, len(r.ReadSet))
+		for i := range r.ReadSet {
+			rs[i] = (clientv3.ReadSetEntry)(*r.ReadSet[i])
+		}
+		op.WithReadSet(rs)
+	}
+	return op
 }
@@ -0,0 +1,314 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy/cache"
+)
+
+const (
+	// coherentCacheMaxWait is the time a linearizable read waits for the
+	// view to catch up with the cluster revision before being forwarded.
+	coherentCacheMaxWait = 100 * time.Millisecond
+	// coherentCacheRetryInterval is the time between attempts to load the
+	// view.
+	coherentCacheRetryInterval = time.Second
+)
+
+// coherentCache is a range cache kept coherent with the cluster by a watch
+// on the whole key space, rather than by the writes passing through the
+// proxy only. The events of the watch invalidate the cached responses, and
+// maintain a materialized view of the key-values under a set of prefixes.
+// The view serves serializable reads, and linearizable reads once it caught
+// up with the revision of the cluster.
+type coherentCache struct {
+	lg   *zap.Logger
+	c    *clientv3.Client
+	view *cache.View
+
+	mu  sync.Mutex
+	lru cache.Cache
+	// rev is the revision the cached responses are coherent with.
+	rev int64
+	// synced is false while the events after rev may be missed, in which
+	// case no response is added to the cache.
+	synced bool
+	// hdr is the header of the last watch response, for the responses
+	// served by the view.
+	hdr pb.ResponseHeader
+	// wctx is the context of the watch, for progress requests.
+	wctx context.Context
+}
+
+func newCoherentCache(lg *zap.Logger, c *clientv3.Client, prefixes []string) *coherentCache {
+	return &coherentCache{
+		lg:   lg,
+		c:    c,
+		view: cache.NewView(lg, prefixes),
+		lru:  cache.NewCache(cache.DefaultMaxEntries),
+	}
+}
+
+// run keeps the cache coherent until the context is done.
+func (cc *coherentCache) run(ctx context.Context) {
+	var rev int64
+	for {
+		if rev == 0 {
+			var err error
+			if rev, err = cc.load(ctx); err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+				cc.lg.Warn("failed to load coherent cache", zap.Error(err))
+				select {
+				case <-time.After(coherentCacheRetryInterval):
+					continue
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+		rev = cc.watch(ctx, rev)
+		select {
+		case <-time.After(coherentCacheRetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// load loads the view at the current revision of the cluster, and returns
+// the revision.
+func (cc *coherentCache) load(ctx context.Context) (int64, error) {
+	var (
+		kvs []*mvccpb.KeyValue
+		rev int64
+		hdr pb.ResponseHeader
+	)
+	for _, p := range cc.view.Prefixes() {
+		opts := []clientv3.OpOption{clientv3.WithPrefix()}
+		if rev != 0 {
+			opts = append(opts, clientv3.WithRev(rev))
+		}
+		resp, err := cc.c.KV.Get(ctx, p, opts...)
+		if err != nil {
+			return 0, err
+		}
+		if rev == 0 {
+			rev, hdr = resp.Header.Revision, *resp.Header
+		}
+		kvs = append(kvs, resp.Kvs...)
+	}
+	if rev == 0 {
+		// no prefixes; only the cached responses are kept coherent
+		resp, err := cc.c.KV.Get(ctx, "\x00", clientv3.WithCountOnly())
+		if err != nil {
+			return 0, err
+		}
+		rev, hdr = resp.Header.Revision, *resp.Header
+	}
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.view.Reset(kvs, rev)
+	cc.rev, cc.synced, cc.hdr = rev, true, hdr
+	coherentCacheKeys.Set(float64(cc.view.Len()))
+	cc.lg.Info("loaded coherent cache", zap.Strings("prefixes", cc.view.Prefixes()), zap.Int64("revision", rev), zap.Int("keys", len(kvs)))
+	return rev, nil
+}
+
+// watch applies the events after the given revision until the watch fails,
+// and returns the revision to resume from, or zero if the events after it
+// are compacted.
+func (cc *coherentCache) watch(ctx context.Context, rev int64) int64 {
+	wctx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	cc.mu.Lock()
+	cc.wctx = wctx
+	cc.mu.Unlock()
+
+	for wresp := range cc.c.Watcher.Watch(wctx, "", clientv3.WithPrefix(), clientv3.WithRev(rev+1)) {
+		if wresp.CompactRevision != 0 {
+			cc.lg.Warn("coherent cache watch compacted; reloading", zap.Int64("revision", rev), zap.Int64("compact-revision", wresp.CompactRevision))
+			cc.desync()
+			return 0
+		}
+		if err := wresp.Err(); err != nil {
+			cc.lg.Warn("coherent cache watch failed; resuming", zap.Int64("revision", rev), zap.Error(err))
+			return rev
+		}
+		switch {
+		case len(wresp.Events) != 0:
+			rev = wresp.Events[len(wresp.Events)-1].Kv.ModRevision
+		case wresp.IsProgressNotify():
+			rev = wresp.Header.Revision
+		}
+		cc.apply(rev, wresp.Events, wresp.Header)
+	}
+	return rev
+}
+
+// apply invalidates the cached responses of the keys of the given events,
+// and applies them to the view.
+func (cc *coherentCache) apply(rev int64, evs []*clientv3.Event, hdr pb.ResponseHeader) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	mevs := make([]*mvccpb.Event, len(evs))
+	for i, ev := range evs {
+		cc.lru.Invalidate(ev.Kv.Key, nil)
+		mevs[i] = (*mvccpb.Event)(ev)
+	}
+	cc.view.Apply(rev, mevs)
+	if rev > cc.rev {
+		cc.rev = rev
+	}
+	cc.hdr = hdr
+	cacheKeys.Set(float64(cc.lru.Size()))
+	coherentCacheKeys.Set(float64(cc.view.Len()))
+}
+
+// desync drops the cached responses and the view once events are missed.
+func (cc *coherentCache) desync() {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.lru = cache.NewCache(cache.DefaultMaxEntries)
+	cc.synced = false
+	cc.view.Invalidate()
+	cacheKeys.Set(0)
+}
+
+// Range serves the given range request from the view, or returns false if
+// the request must be forwarded to the cluster. As the view holds the
+// key-values of every user, the requested range is first authorized with the
+// credentials of the request, by a count-only range of the cluster, which is
+// cheaper than the range. Linearizable requests are served once the view
+// caught up with the revision of that range.
+func (cc *coherentCache) Range(ctx context.Context, r *pb.RangeRequest) (*pb.RangeResponse, bool) {
+	if !cc.view.Covers(r) {
+		coherentCacheMisses.WithLabelValues("uncovered").Inc()
+		return nil, false
+	}
+	vrev, ready := cc.view.Rev()
+	if !ready {
+		coherentCacheMisses.WithLabelValues("not_ready").Inc()
+		return nil, false
+	}
+
+	cc.mu.Lock()
+	hdr := cc.hdr
+	cc.mu.Unlock()
+	// the key-values at a given revision never change, so only the reads
+	// of the latest revision are checked
+	latest := !r.Serializable && r.Revision == 0 && r.ContinueToken == ""
+	opts := []clientv3.OpOption{clientv3.WithCountOnly()}
+	if len(r.RangeEnd) != 0 {
+		opts = append(opts, clientv3.WithRange(string(r.RangeEnd)))
+	}
+	if !latest {
+		opts = append(opts, clientv3.WithSerializable())
+	}
+	resp, err := cc.c.KV.Get(ctx, string(r.Key), opts...)
+	if err != nil {
+		// the request is forwarded, failing the same if it is not permitted
+		coherentCacheMisses.WithLabelValues("error").Inc()
+		return nil, false
+	}
+	if latest {
+		hdr = *resp.Header
+		if lag := hdr.Revision - vrev; lag > 0 {
+			coherentCacheStaleness.Observe(float64(lag))
+			cc.requestProgress()
+			wctx, cancel := context.WithTimeout(ctx, coherentCacheMaxWait)
+			err = cc.view.WaitRev(wctx, hdr.Revision)
+			cancel()
+			if err != nil {
+				coherentCacheMisses.WithLabelValues("stale").Inc()
+				return nil, false
+			}
+		} else {
+			coherentCacheStaleness.Observe(0)
+		}
+	}
+
+	vresp, err := cc.view.Range(ctx, r)
+	if err != nil {
+		coherentCacheMisses.WithLabelValues("unsupported").Inc()
+		return nil, false
+	}
+	vresp.Header.ClusterId, vresp.Header.MemberId, vresp.Header.RaftTerm = hdr.ClusterId, hdr.MemberId, hdr.RaftTerm
+	coherentCacheHits.Inc()
+	return vresp, true
+}
+
+// requestProgress asks the cluster for the revision of the watch, which
+// advances the view when the latest revisions have no events for it, as
+// with a namespaced client.
+func (cc *coherentCache) requestProgress() {
+	cc.mu.Lock()
+	wctx := cc.wctx
+	cc.mu.Unlock()
+	if wctx == nil {
+		return
+	}
+	if err := cc.c.Watcher.RequestProgress(wctx); err != nil {
+		cc.lg.Debug("failed to request coherent cache watch progress", zap.Error(err))
+	}
+}
+
+// Add adds the response of a request to the cache unless an event after its
+// revision was already applied, in which case the response may be stale.
+func (cc *coherentCache) Add(req *pb.RangeRequest, resp *pb.RangeResponse) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	if !cc.synced || resp.Header == nil || resp.Header.Revision < cc.rev {
+		return
+	}
+	cc.lru.Add(req, resp)
+}
+
+func (cc *coherentCache) Get(req *pb.RangeRequest) (*pb.RangeResponse, error) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.lru.Get(req)
+}
+
+func (cc *coherentCache) Compact(revision int64) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.lru.Compact(revision)
+}
+
+func (cc *coherentCache) Invalidate(key []byte, endkey []byte) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	cc.lru.Invalidate(key, endkey)
+}
+
+func (cc *coherentCache) Size() int {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	return cc.lru.Size()
+}
+
+func (cc *coherentCache) Close() {}
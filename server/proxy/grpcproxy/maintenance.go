@@ -62,6 +62,32 @@ func (mp *maintenanceProxy) Snapshot(sr *pb.SnapshotRequest, stream pb.Maintenan
 	}
 }
 
+func (mp *maintenanceProxy) SnapshotDelta(r *pb.SnapshotDeltaRequest, stream pb.Maintenance_SnapshotDeltaServer) error {
+	ctx, cancel := context.WithCancel(stream.Context())
+	defer cancel()
+
+	ctx = withClientAuthToken(ctx, stream.Context())
+
+	sc, err := mp.maintenanceClient.SnapshotDelta(ctx, r)
+	if err != nil {
+		return err
+	}
+
+	for {
+		rr, err := sc.Recv()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+		err = stream.Send(rr)
+		if err != nil {
+			return err
+		}
+	}
+}
+
 func (mp *maintenanceProxy) Hash(ctx context.Context, r *pb.HashRequest) (*pb.HashResponse, error) {
 	return mp.maintenanceClient.Hash(ctx, r)
 }
@@ -85,3 +111,19 @@ func (mp *maintenanceProxy) MoveLeader(ctx context.Context, r *pb.MoveLeaderRequ
 func (mp *maintenanceProxy) Downgrade(ctx context.Context, r *pb.DowngradeRequest) (*pb.DowngradeResponse, error) {
 	return mp.maintenanceClient.Downgrade(ctx, r)
 }
+
+func (mp *maintenanceProxy) QuotaSet(ctx context.Context, r *pb.QuotaSetRequest) (*pb.QuotaSetResponse, error) {
+	return mp.maintenanceClient.QuotaSet(ctx, r)
+}
+
+func (mp *maintenanceProxy) QuotaDelete(ctx context.Context, r *pb.QuotaDeleteRequest) (*pb.QuotaDeleteResponse, error) {
+	return mp.maintenanceClient.QuotaDelete(ctx, r)
+}
+
+func (mp *maintenanceProxy) QuotaList(ctx context.Context, r *pb.QuotaListRequest) (*pb.QuotaListResponse, error) {
+	return mp.maintenanceClient.QuotaList(ctx, r)
+}
+
+func (mp *maintenanceProxy) RotateEncryptionKey(ctx context.Context, r *pb.RotateEncryptionKeyRequest) (*pb.RotateEncryptionKeyResponse, error) {
+	return mp.maintenanceClient.RotateEncryptionKey(ctx, r)
+}
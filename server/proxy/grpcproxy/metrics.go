@@ -59,6 +59,35 @@ var (
 		Name:      "cache_misses_total",
 		Help:      "Total number of cache misses",
 	})
+	coherentCacheKeys = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "coherent_cache_keys",
+		Help:      "Number of keys in the materialized view of the coherent cache",
+	})
+	coherentCacheHits = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "coherent_cache_hits_total",
+		Help:      "Total number of range requests served from the materialized view of the coherent cache",
+	})
+	coherentCacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "coherent_cache_misses_total",
+		Help:      "Total number of range requests forwarded by the coherent cache, by reason: 'uncovered' for ranges outside of the cached prefixes, 'not_ready' while the view is loaded, 'stale' when the view does not catch up with the cluster revision in time, 'unsupported' for revisions not held by the view, 'error' when the revision check fails.",
+	},
+		[]string{"reason"})
+	coherentCacheStaleness = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "etcd",
+		Subsystem: "grpc_proxy",
+		Name:      "coherent_cache_staleness_revisions",
+		Help:      "Number of revisions the materialized view of the coherent cache is behind the cluster at the revision check of linearizable reads",
+
+		// lowest bucket start of upper bound 1 with factor 4
+		// highest bucket start of 1 * 4^7 == 16384
+		Buckets: prometheus.ExponentialBuckets(1, 4, 8),
+	})
 )
 
 func init() {
@@ -67,6 +96,10 @@ func init() {
 	prometheus.MustRegister(cacheKeys)
 	prometheus.MustRegister(cacheHits)
 	prometheus.MustRegister(cachedMisses)
+	prometheus.MustRegister(coherentCacheKeys)
+	prometheus.MustRegister(coherentCacheHits)
+	prometheus.MustRegister(coherentCacheMisses)
+	prometheus.MustRegister(coherentCacheStaleness)
 }
 
 // HandleMetrics performs a GET request against etcd endpoint and returns '/metrics'.
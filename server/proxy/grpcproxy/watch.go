@@ -17,6 +17,7 @@ package grpcproxy
 import (
 	"context"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
@@ -196,6 +197,7 @@ func (wps *watchProxyStream) close() {
 	for _, wpsw := range wps.watchers {
 		go func(w *watcher) {
 			wps.ranges.delete(w)
+			w.stop()
 			wg.Done()
 		}(wpsw)
 	}
@@ -235,7 +237,11 @@ func (wps *watchProxyStream) recvLoop() error {
 		case *pb.WatchRequest_CreateRequest:
 			cr := uv.CreateRequest
 
-			if err := wps.checkPermissionForWatch(cr.Key, cr.RangeEnd); err != nil {
+			err := wps.checkPermissionForWatch(cr.Key, cr.RangeEnd)
+			if err == nil {
+				err = v3rpc.CheckWatchCreateRequest(cr)
+			}
+			if err != nil {
 				wps.watchCh <- &pb.WatchResponse{
 					Header:       &pb.ResponseHeader{},
 					WatchId:      clientv3.InvalidWatchID,
@@ -256,6 +262,9 @@ func (wps *watchProxyStream) recvLoop() error {
 				progress: cr.ProgressNotify,
 				prevKV:   cr.PrevKv,
 				filters:  v3rpc.FiltersFromRequest(cr),
+
+				omitUnchanged: cr.OmitUnchangedValue,
+				window:        time.Duration(cr.CoalesceWindowMs) * time.Millisecond,
 			}
 			if !w.wr.valid() {
 				w.post(&pb.WatchResponse{WatchId: clientv3.InvalidWatchID, Created: true, Canceled: true})
@@ -303,6 +312,7 @@ func (wps *watchProxyStream) delete(id int64) {
 		return
 	}
 	wps.ranges.delete(w)
+	w.stop()
 	delete(wps.watchers, id)
 	resp := &pb.WatchResponse{
 		Header:   &w.lastHeader,
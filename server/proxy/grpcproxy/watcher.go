@@ -15,11 +15,13 @@
 package grpcproxy
 
 import (
+	"sync"
 	"time"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
 	"go.etcd.io/etcd/api/v3/mvccpb"
 	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/etcdserver/api/v3rpc"
 	"go.etcd.io/etcd/server/v3/storage/mvcc"
 )
 
@@ -38,6 +40,10 @@ type watcher struct {
 	filters  []mvcc.FilterFunc
 	progress bool
 	prevKV   bool
+	// omitUnchanged omits the values of puts that do not change them
+	omitUnchanged bool
+	// window is how long the events of a coalescing watcher are held back.
+	window time.Duration
 
 	// id is the id returned to the client on its watch stream.
 	id int64
@@ -48,6 +54,16 @@ type watcher struct {
 
 	// wps is the parent.
 	wps *watchProxyStream
+
+	// coalesceMu protects the fields below, and orders the responses posted
+	// when the window of a coalescing watcher ends with the other responses.
+	coalesceMu sync.Mutex
+	coalescer  *v3rpc.EventCoalescer
+	// coalesceHeader is the header of the last response merged into the
+	// coalescer.
+	coalesceHeader pb.ResponseHeader
+	coalesceTimer  *time.Timer
+	stopped        bool
 }
 
 // send filters out repeated events by discarding revisions older
@@ -89,6 +105,10 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 			continue
 		}
 
+		if w.omitUnchanged {
+			// the broadcast watches with prev_kv, so this needs no lookup
+			ev = v3rpc.OmitUnchangedValue(ev)
+		}
 		if !w.prevKV {
 			evCopy := *ev
 			evCopy.PrevKv = nil
@@ -106,6 +126,20 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 		return
 	}
 
+	if w.window > 0 {
+		w.coalesceMu.Lock()
+		defer w.coalesceMu.Unlock()
+		if len(events) > 0 && wr.CompactRevision == 0 && !wr.Canceled && !wr.Created {
+			w.coalesce(wr.Header, events)
+			return
+		}
+		// progress notifications and cancellations must not overtake the
+		// buffered events
+		if !w.flushLocked() {
+			return
+		}
+	}
+
 	w.lastHeader = wr.Header
 	w.post(&pb.WatchResponse{
 		Header:          &wr.Header,
@@ -117,6 +151,53 @@ func (w *watcher) send(wr clientv3.WatchResponse) {
 	})
 }
 
+// coalesce buffers the events of a coalescing watcher until its window ends.
+func (w *watcher) coalesce(hdr pb.ResponseHeader, events []*mvccpb.Event) {
+	if w.stopped {
+		return
+	}
+	if w.coalescer == nil {
+		w.coalescer = v3rpc.NewEventCoalescer(w.window)
+	}
+	if w.coalescer.Empty() {
+		w.coalesceTimer = time.AfterFunc(w.window, w.flush)
+	}
+	w.coalescer.Add(time.Now(), hdr.Revision, events)
+	w.coalesceHeader = hdr
+}
+
+// flush posts the events buffered when the window of the watcher ends.
+func (w *watcher) flush() {
+	w.coalesceMu.Lock()
+	defer w.coalesceMu.Unlock()
+	w.flushLocked()
+}
+
+func (w *watcher) flushLocked() bool {
+	if w.stopped || w.coalescer == nil || w.coalescer.Empty() {
+		return !w.stopped
+	}
+	w.coalesceTimer.Stop()
+	events, _ := w.coalescer.Flush()
+	hdr := w.coalesceHeader
+	return w.post(&pb.WatchResponse{
+		Header:  &hdr,
+		WatchId: w.id,
+		Events:  events,
+	})
+}
+
+// stop drops the buffered events of the watcher once it no longer receives
+// responses, so none are posted after it is canceled.
+func (w *watcher) stop() {
+	w.coalesceMu.Lock()
+	defer w.coalesceMu.Unlock()
+	w.stopped = true
+	if w.coalesceTimer != nil {
+		w.coalesceTimer.Stop()
+	}
+}
+
 // post puts a watch response on the watcher's proxy stream channel
 func (w *watcher) post(wr *pb.WatchResponse) bool {
 	select {
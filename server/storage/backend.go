@@ -44,6 +44,8 @@ func newBackend(cfg config.ServerConfig, hooks backend.Hooks) backend.Backend {
 			cfg.Logger.Info("setting backend batch interval", zap.Duration("batch interval", cfg.BackendBatchInterval))
 		}
 	}
+	bcfg.Engine = cfg.ExperimentalBackendEngine
+	bcfg.Cipher = cfg.Cipher()
 	bcfg.BackendFreelistType = cfg.BackendFreelistType
 	bcfg.Logger = cfg.Logger
 	if cfg.QuotaBackendBytes > 0 && cfg.QuotaBackendBytes != DefaultQuotaBytes {
@@ -105,5 +107,7 @@ func RecoverSnapshotBackend(cfg config.ServerConfig, oldbe backend.Backend, snap
 		return oldbe, nil
 	}
 	oldbe.Close()
-	return OpenSnapshotBackend(cfg, snap.New(cfg.Logger, cfg.SnapDir()), snapshot, hooks)
+	ss := snap.New(cfg.Logger, cfg.SnapDir())
+	ss.SetCipher(cfg.Cipher())
+	return OpenSnapshotBackend(cfg, ss, snapshot, hooks)
 }
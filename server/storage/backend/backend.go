@@ -15,6 +15,7 @@
 package backend
 
 import (
+	"context"
 	"fmt"
 	"hash/crc32"
 	"io"
@@ -28,6 +29,8 @@ import (
 	"go.uber.org/zap"
 
 	bolt "go.etcd.io/bbolt"
+
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 )
 
 var (
@@ -66,6 +69,14 @@ type Backend interface {
 	// OpenReadTxN returns the number of currently open read transactions in the backend.
 	OpenReadTxN() int64
 	Defrag() error
+	// OnlineDefrag defragments the backend like Defrag, but copies the
+	// database while reads and writes continue. They are only blocked to
+	// replay the last writes made during the copy and to swap the files.
+	OnlineDefrag(ctx context.Context, cfg OnlineDefragConfig) error
+	// Check verifies the consistency of the committed database, like the
+	// page structure of a bbolt database. Reads and writes continue
+	// meanwhile.
+	Check() error
 	ForceCommit()
 	Close() error
 
@@ -103,9 +114,12 @@ type backend struct {
 	// mlock prevents backend database file to be swapped
 	mlock bool
 
-	mu    sync.RWMutex
-	bopts *bolt.Options
-	db    *bolt.DB
+	mu     sync.RWMutex
+	bcfg   BackendConfig
+	engine Engine
+
+	// defragMu serializes defragmentations.
+	defragMu sync.Mutex
 
 	batchInterval time.Duration
 	batchLimit    int
@@ -132,13 +146,18 @@ type backend struct {
 type BackendConfig struct {
 	// Path is the file path to the backend file.
 	Path string
+	// Engine is the name of the storage engine of the backend, among the
+	// registered engines. The default bbolt engine is used if empty.
+	Engine string
 	// BatchInterval is the maximum time before flushing the BatchTx.
 	BatchInterval time.Duration
 	// BatchLimit is the maximum puts before flushing the BatchTx.
 	BatchLimit int
 	// BackendFreelistType is the backend boltdb's freelist type.
+	// Only used by the bbolt engine.
 	BackendFreelistType bolt.FreelistType
 	// MmapSize is the number of bytes to mmap for the backend.
+	// Only used by the bbolt engine.
 	MmapSize uint64
 	// Logger logs backend-side operations.
 	Logger *zap.Logger
@@ -146,6 +165,12 @@ type BackendConfig struct {
 	UnsafeNoFsync bool `json:"unsafe-no-fsync"`
 	// Mlock prevents backend database file to be swapped
 	Mlock bool
+	// ReadOnly opens the database read-only. It is only supported by
+	// OpenEngine, as a backend writes to its database.
+	ReadOnly bool
+	// Cipher encrypts the values stored in the database if set. Values
+	// written before are decrypted as is, until they are rewritten.
+	Cipher encryption.Cipher
 
 	// Hooks are getting executed during lifecycle of Backend's transactions.
 	Hooks Hooks
@@ -171,26 +196,16 @@ func NewDefaultBackend(lg *zap.Logger, path string) Backend {
 }
 
 func newBackend(bcfg BackendConfig) *backend {
-	bopts := &bolt.Options{}
-	if boltOpenOptions != nil {
-		*bopts = *boltOpenOptions
-	}
-	bopts.InitialMmapSize = bcfg.mmapSize()
-	bopts.FreelistType = bcfg.BackendFreelistType
-	bopts.NoSync = bcfg.UnsafeNoFsync
-	bopts.NoGrowSync = bcfg.UnsafeNoFsync
-	bopts.Mlock = bcfg.Mlock
-
-	db, err := bolt.Open(bcfg.Path, 0600, bopts)
+	engine, err := OpenEngine(bcfg)
 	if err != nil {
-		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.Error(err))
+		bcfg.Logger.Panic("failed to open database", zap.String("path", bcfg.Path), zap.String("engine", bcfg.engineName()), zap.Error(err))
 	}
 
 	// In future, may want to make buffering optional for low-concurrency systems
 	// or dynamically swap between buffered/non-buffered depending on workload.
 	b := &backend{
-		bopts: bopts,
-		db:    db,
+		bcfg:   bcfg,
+		engine: engine,
 
 		batchInterval: bcfg.BatchInterval,
 		batchLimit:    bcfg.BatchLimit,
@@ -202,7 +217,7 @@ func newBackend(bcfg BackendConfig) *backend {
 					txBuffer:   txBuffer{make(map[BucketID]*bucketBuffer)},
 					bufVersion: 0,
 				},
-				buckets: make(map[BucketID]*bolt.Bucket),
+				buckets: make(map[BucketID]EngineBucket),
 				txWg:    new(sync.WaitGroup),
 				txMu:    new(sync.RWMutex),
 			},
@@ -327,12 +342,23 @@ func (b *backend) ForceCommit() {
 	b.batchTx.Commit()
 }
 
+func (b *backend) Check() error {
+	b.mu.RLock()
+	tx, err := b.engine.Begin(false)
+	b.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	return tx.Check()
+}
+
 func (b *backend) Snapshot() Snapshot {
 	b.batchTx.Commit()
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	tx, err := b.db.Begin(false)
+	tx, err := b.engine.Begin(false)
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
 	}
@@ -376,23 +402,24 @@ func (b *backend) Hash(ignores func(bucketName, keyName []byte) bool) (uint32, e
 
 	b.mu.RLock()
 	defer b.mu.RUnlock()
-	err := b.db.View(func(tx *bolt.Tx) error {
-		c := tx.Cursor()
-		for next, _ := c.First(); next != nil; next, _ = c.Next() {
-			b := tx.Bucket(next)
-			if b == nil {
-				return fmt.Errorf("cannot get hash of bucket %s", string(next))
-			}
-			h.Write(next)
-			b.ForEach(func(k, v []byte) error {
-				if ignores != nil && !ignores(next, k) {
-					h.Write(k)
-					h.Write(v)
-				}
-				return nil
-			})
+	tx, err := b.engine.Begin(false)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+	err = tx.ForEachBucket(func(next []byte) error {
+		b := tx.Bucket(next)
+		if b == nil {
+			return fmt.Errorf("cannot get hash of bucket %s", string(next))
 		}
-		return nil
+		h.Write(next)
+		return b.ForEach(func(k, v []byte) error {
+			if ignores != nil && !ignores(next, k) {
+				h.Write(k)
+				h.Write(v)
+			}
+			return nil
+		})
 	})
 
 	if err != nil {
@@ -433,7 +460,7 @@ func (b *backend) Close() error {
 	<-b.donec
 	b.mu.Lock()
 	defer b.mu.Unlock()
-	return b.db.Close()
+	return b.engine.Close()
 }
 
 // Commits returns total number of commits since start
@@ -442,10 +469,44 @@ func (b *backend) Commits() int64 {
 }
 
 func (b *backend) Defrag() error {
+	if c, ok := b.engine.(EngineCompactor); ok {
+		return b.compact(c)
+	}
 	return b.defrag()
 }
 
+// compact reclaims the free space of an engine compacting in place, with the
+// pending writes committed.
+func (b *backend) compact(c EngineCompactor) error {
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
+
+	now := time.Now()
+	isDefragActive.Set(1)
+	defer isDefragActive.Set(0)
+
+	b.ForceCommit()
+	b.mu.RLock()
+	err := c.Compact()
+	b.mu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	took := time.Since(now)
+	defragSec.Observe(took.Seconds())
+	b.lg.Info(
+		"compacted storage engine",
+		zap.String("path", b.engine.Path()),
+		zap.Duration("took", took),
+	)
+	return nil
+}
+
 func (b *backend) defrag() error {
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
+
 	now := time.Now()
 	isDefragActive.Set(1)
 	defer isDefragActive.Set(0)
@@ -468,29 +529,12 @@ func (b *backend) defrag() error {
 
 	b.batchTx.tx = nil
 
-	// Create a temporary file to ensure we start with a clean slate.
-	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
-	dir := filepath.Dir(b.db.Path())
-	temp, err := os.CreateTemp(dir, "db.tmp.*")
-	if err != nil {
-		return err
-	}
-	options := bolt.Options{}
-	if boltOpenOptions != nil {
-		options = *boltOpenOptions
-	}
-	options.OpenFile = func(_ string, _ int, _ os.FileMode) (file *os.File, err error) {
-		return temp, nil
-	}
-	// Don't load tmp db into memory regardless of opening options
-	options.Mlock = false
-	tdbp := temp.Name()
-	tmpdb, err := bolt.Open(tdbp, 0600, &options)
+	tmpdb, err := b.openDefragDB()
 	if err != nil {
 		return err
 	}
 
-	dbp := b.db.Path()
+	dbp := b.engine.Path()
 	size1, sizeInUse1 := b.Size(), b.SizeInUse()
 	if b.lg != nil {
 		b.lg.Info(
@@ -503,16 +547,69 @@ func (b *backend) defrag() error {
 		)
 	}
 	// gofail: var defragBeforeCopy struct{}
-	err = defragdb(b.db, tmpdb, defragLimit)
+	err = defragdb(b.engine, tmpdb, defragLimit)
 	if err != nil {
-		tmpdb.Close()
-		if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
-			b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
-		}
+		b.removeDefragDB(tmpdb)
 		return err
 	}
 
-	err = b.db.Close()
+	b.unsafeSwapDB(tmpdb)
+
+	took := time.Since(now)
+	defragSec.Observe(took.Seconds())
+
+	size2, sizeInUse2 := b.Size(), b.SizeInUse()
+	if b.lg != nil {
+		b.lg.Info(
+			"finished defragmenting directory",
+			zap.String("path", dbp),
+			zap.Int64("current-db-size-bytes-diff", size2-size1),
+			zap.Int64("current-db-size-bytes", size2),
+			zap.String("current-db-size", humanize.Bytes(uint64(size2))),
+			zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
+			zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
+			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
+			zap.Duration("took", took),
+		)
+	}
+	return nil
+}
+
+// openDefragDB creates the temporary database the backend is copied to.
+func (b *backend) openDefragDB() (Engine, error) {
+	// Create a temporary file to ensure we start with a clean slate.
+	// Snapshotter.cleanupSnapdir cleans up any of these that are found during startup.
+	dir := filepath.Dir(b.engine.Path())
+	temp, err := os.CreateTemp(dir, "db.tmp.*")
+	if err != nil {
+		return nil, err
+	}
+	// the engine creates its database at the path of the file, which may be
+	// a directory rather than a file
+	temp.Close()
+	if err = os.Remove(temp.Name()); err != nil {
+		return nil, err
+	}
+	cfg := b.bcfg
+	cfg.Path = temp.Name()
+	// Don't load tmp db into memory regardless of opening options
+	cfg.Mlock = false
+	return OpenEngine(cfg)
+}
+
+func (b *backend) removeDefragDB(tmpdb Engine) {
+	tmpdb.Close()
+	if rmErr := os.RemoveAll(tmpdb.Path()); rmErr != nil {
+		b.lg.Error("failed to remove db.tmp after defragmentation completed", zap.Error(rmErr))
+	}
+}
+
+// unsafeSwapDB replaces the database with the defragmented copy tmpdb. It
+// must be called holding the locks on the batch tx, the backend and the
+// read tx, after committing the batch tx.
+func (b *backend) unsafeSwapDB(tmpdb Engine) {
+	dbp, tdbp := b.engine.Path(), tmpdb.Path()
+	err := b.engine.Close()
 	if err != nil {
 		b.lg.Fatal("failed to close database", zap.Error(err))
 	}
@@ -526,7 +623,7 @@ func (b *backend) defrag() error {
 		b.lg.Fatal("failed to rename tmp database", zap.Error(err))
 	}
 
-	b.db, err = bolt.Open(dbp, 0600, b.bopts)
+	b.engine, err = OpenEngine(b.bcfg)
 	if err != nil {
 		b.lg.Fatal("failed to open database", zap.String("path", dbp), zap.Error(err))
 	}
@@ -536,31 +633,24 @@ func (b *backend) defrag() error {
 	b.readTx.tx = b.unsafeBegin(false)
 
 	size := b.readTx.tx.Size()
-	db := b.readTx.tx.DB()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(db.Stats().FreePageN)*int64(db.Info().PageSize)))
-
-	took := time.Since(now)
-	defragSec.Observe(took.Seconds())
+	atomic.StoreInt64(&b.sizeInUse, size-b.engine.Stats().FreeBytes)
+}
 
-	size2, sizeInUse2 := b.Size(), b.SizeInUse()
-	if b.lg != nil {
-		b.lg.Info(
-			"finished defragmenting directory",
-			zap.String("path", dbp),
-			zap.Int64("current-db-size-bytes-diff", size2-size1),
-			zap.Int64("current-db-size-bytes", size2),
-			zap.String("current-db-size", humanize.Bytes(uint64(size2))),
-			zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
-			zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
-			zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
-			zap.Duration("took", took),
-		)
+func defragdb(odb, tmpdb Engine, limit int) error {
+	// open a tx on old db for read
+	tx, err := odb.Begin(false)
+	if err != nil {
+		return err
 	}
-	return nil
+	defer tx.Rollback()
+
+	return defragTx(tx, tmpdb, limit, nil)
 }
 
-func defragdb(odb, tmpdb *bolt.DB, limit int) error {
+// defragTx copies the buckets of tx to tmpdb, committing every limit keys.
+// If visit is not nil, it is called before each key is copied.
+func defragTx(tx EngineTx, tmpdb Engine, limit int, visit func(k, v []byte) error) error {
 	// open a tx on tmpdb for writes
 	tmptx, err := tmpdb.Begin(true)
 	if err != nil {
@@ -572,29 +662,25 @@ func defragdb(odb, tmpdb *bolt.DB, limit int) error {
 		}
 	}()
 
-	// open a tx on old db for read
-	tx, err := odb.Begin(false)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-
-	c := tx.Cursor()
-
 	count := 0
-	for next, _ := c.First(); next != nil; next, _ = c.Next() {
+	err = tx.ForEachBucket(func(next []byte) error {
 		b := tx.Bucket(next)
 		if b == nil {
 			return fmt.Errorf("backend: cannot defrag bucket %s", string(next))
 		}
 
-		tmpb, berr := tmptx.CreateBucketIfNotExists(next)
+		tmpb, berr := tmptx.CreateBucket(next)
 		if berr != nil {
 			return berr
 		}
-		tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+		tmpb.SetSequential() // for bucket2seq write in for each
 
-		if err = b.ForEach(func(k, v []byte) error {
+		return b.ForEach(func(k, v []byte) error {
+			if visit != nil {
+				if err = visit(k, v); err != nil {
+					return err
+				}
+			}
 			count++
 			if count > limit {
 				err = tmptx.Commit()
@@ -606,37 +692,37 @@ func defragdb(odb, tmpdb *bolt.DB, limit int) error {
 					return err
 				}
 				tmpb = tmptx.Bucket(next)
-				tmpb.FillPercent = 0.9 // for bucket2seq write in for each
+				tmpb.SetSequential() // for bucket2seq write in for each
 
 				count = 0
 			}
 			return tmpb.Put(k, v)
-		}); err != nil {
-			return err
-		}
+		})
+	})
+	if err != nil {
+		return err
 	}
 
 	return tmptx.Commit()
 }
 
-func (b *backend) begin(write bool) *bolt.Tx {
+func (b *backend) begin(write bool) EngineTx {
 	b.mu.RLock()
 	tx := b.unsafeBegin(write)
+	stats := b.engine.Stats()
 	b.mu.RUnlock()
 
 	size := tx.Size()
-	db := tx.DB()
-	stats := db.Stats()
 	atomic.StoreInt64(&b.size, size)
-	atomic.StoreInt64(&b.sizeInUse, size-(int64(stats.FreePageN)*int64(db.Info().PageSize)))
+	atomic.StoreInt64(&b.sizeInUse, size-stats.FreeBytes)
 	atomic.StoreInt64(&b.openReadTxN, int64(stats.OpenTxN))
 
 	return tx
 }
 
-func (b *backend) unsafeBegin(write bool) *bolt.Tx {
+func (b *backend) unsafeBegin(write bool) EngineTx {
 	// gofail: var beforeStartDBTxn struct{}
-	tx, err := b.db.Begin(write)
+	tx, err := b.engine.Begin(write)
 	// gofail: var afterStartDBTxn struct{}
 	if err != nil {
 		b.lg.Fatal("failed to begin tx", zap.Error(err))
@@ -649,7 +735,7 @@ func (b *backend) OpenReadTxN() int64 {
 }
 
 type snapshot struct {
-	*bolt.Tx
+	EngineTx
 	stopc chan struct{}
 	donec chan struct{}
 }
@@ -657,5 +743,5 @@ type snapshot struct {
 func (s *snapshot) Close() error {
 	close(s.stopc)
 	<-s.donec
-	return s.Tx.Rollback()
+	return s.EngineTx.Rollback()
 }
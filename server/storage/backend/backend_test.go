@@ -15,6 +15,7 @@
 package backend_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"reflect"
@@ -22,6 +23,7 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	bolt "go.etcd.io/bbolt"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
@@ -193,6 +195,133 @@ func TestBackendDefrag(t *testing.T) {
 	b.ForceCommit()
 }
 
+// TestBackendOnlineDefrag ensures the writes made during an online defrag
+// are kept in the defragmented database.
+func TestBackendOnlineDefrag(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	want := make(map[string]string)
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < backend.DefragLimitForTest()+100; i++ {
+		k := fmt.Sprintf("foo_%d", i)
+		tx.UnsafePut(schema.Test, []byte(k), []byte("bar"))
+		want[k] = "bar"
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	// the copy of about 110 KB takes about a second at 128 KB/s
+	donec := make(chan error, 1)
+	go func() {
+		donec <- b.OnlineDefrag(context.Background(), backend.OnlineDefragConfig{RateLimitBytes: 128 * 1024})
+	}()
+
+	writes := 0
+	for done := false; !done; writes++ {
+		select {
+		case err := <-donec:
+			if err != nil {
+				t.Fatal(err)
+			}
+			done = true
+		case <-time.After(time.Millisecond):
+		}
+		k, dk := fmt.Sprintf("new_%d", writes), fmt.Sprintf("foo_%d", writes)
+		tx = b.BatchTx()
+		tx.Lock()
+		tx.UnsafePut(schema.Test, []byte(k), []byte("baz"))
+		tx.UnsafeDelete(schema.Test, []byte(dk))
+		tx.Unlock()
+		want[k] = "baz"
+		delete(want, dk)
+	}
+	if writes < 100 {
+		t.Fatalf("writes during defrag = %d, want >= 100", writes)
+	}
+	b.ForceCommit()
+
+	got := make(map[string]string)
+	rtx := b.ReadTx()
+	rtx.RLock()
+	err := rtx.UnsafeForEach(schema.Test, func(k, v []byte) error {
+		got[string(k)] = string(v)
+		return nil
+	})
+	rtx.RUnlock()
+	assert.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// try put more keys after the swap.
+	tx = b.BatchTx()
+	tx.Lock()
+	tx.UnsafePut(schema.Test, []byte("more"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+}
+
+// TestBackendOnlineDefragAbort ensures an online defrag is aborted once its
+// journal is full or its context is done, and the writes are kept.
+func TestBackendOnlineDefragAbort(t *testing.T) {
+	defer backend.SetOnlineDefragMaxJournalBytesForTest(1024)()
+
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	defer betesting.Close(t, b)
+
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Test)
+	for i := 0; i < backend.DefragLimitForTest()+100; i++ {
+		tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("foo_%d", i)), []byte("bar"))
+	}
+	tx.Unlock()
+	b.ForceCommit()
+
+	// the copy of about 110 KB takes about a second at 128 KB/s
+	donec := make(chan error, 1)
+	go func() {
+		donec <- b.OnlineDefrag(context.Background(), backend.OnlineDefragConfig{RateLimitBytes: 128 * 1024})
+	}()
+	var err error
+	for writes := 0; ; writes++ {
+		select {
+		case err = <-donec:
+		case <-time.After(time.Millisecond):
+			tx = b.BatchTx()
+			tx.Lock()
+			tx.UnsafePut(schema.Test, []byte(fmt.Sprintf("new_%d", writes)), []byte("baz"))
+			tx.Unlock()
+			continue
+		}
+		break
+	}
+	assert.Equal(t, backend.ErrDefragJournalFull, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		donec <- b.OnlineDefrag(ctx, backend.OnlineDefragConfig{RateLimitBytes: 128 * 1024})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	assert.Equal(t, context.Canceled, <-donec)
+
+	// the writes are kept and writes are no longer journaled
+	tx = b.BatchTx()
+	tx.Lock()
+	tx.UnsafePut(schema.Test, []byte("more"), []byte("bar"))
+	tx.Unlock()
+	b.ForceCommit()
+	rtx := b.ReadTx()
+	rtx.RLock()
+	_, vals := rtx.UnsafeRange(schema.Test, []byte("new_0"), nil, 0)
+	rtx.RUnlock()
+	assert.Equal(t, [][]byte{[]byte("baz")}, vals)
+
+	require.NoError(t, b.OnlineDefrag(context.Background(), backend.OnlineDefragConfig{}))
+}
+
 // TestBackendWriteback ensures writes are stored to the read txn on write txn unlock.
 func TestBackendWriteback(t *testing.T) {
 	b, _ := betesting.NewDefaultTmpBackend(t)
@@ -22,8 +22,6 @@ import (
 	"time"
 
 	"go.uber.org/zap"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 type BucketID int
@@ -60,10 +58,14 @@ type BatchTx interface {
 
 type batchTx struct {
 	sync.Mutex
-	tx      *bolt.Tx
+	tx      EngineTx
 	backend *backend
 
 	pending int
+
+	// journal records the writes while an online defragmentation is
+	// copying the database.
+	journal *defragJournal
 }
 
 // Lock is supposed to be called only by the unit test.
@@ -115,25 +117,31 @@ func (t *batchTx) RUnlock() {
 
 func (t *batchTx) UnsafeCreateBucket(bucket Bucket) {
 	_, err := t.tx.CreateBucket(bucket.Name())
-	if err != nil && err != bolt.ErrBucketExists {
+	if err != nil {
 		t.backend.lg.Fatal(
 			"failed to create a bucket",
 			zap.Stringer("bucket-name", bucket),
 			zap.Error(err),
 		)
 	}
+	if t.journal != nil {
+		t.journal.add(defragOpCreateBucket, bucket.Name(), nil, nil)
+	}
 	t.pending++
 }
 
 func (t *batchTx) UnsafeDeleteBucket(bucket Bucket) {
 	err := t.tx.DeleteBucket(bucket.Name())
-	if err != nil && err != bolt.ErrBucketNotFound {
+	if err != nil {
 		t.backend.lg.Fatal(
 			"failed to delete a bucket",
 			zap.Stringer("bucket-name", bucket),
 			zap.Error(err),
 		)
 	}
+	if t.journal != nil {
+		t.journal.add(defragOpDeleteBucket, bucket.Name(), nil, nil)
+	}
 	t.pending++
 }
 
@@ -157,9 +165,7 @@ func (t *batchTx) unsafePut(bucketType Bucket, key []byte, value []byte, seq boo
 		)
 	}
 	if seq {
-		// it is useful to increase fill percent when the workloads are mostly append-only.
-		// this can delay the page split and reduce space usage.
-		bucket.FillPercent = 0.9
+		bucket.SetSequential()
 	}
 	if err := bucket.Put(key, value); err != nil {
 		t.backend.lg.Fatal(
@@ -168,6 +174,9 @@ func (t *batchTx) unsafePut(bucketType Bucket, key []byte, value []byte, seq boo
 			zap.Error(err),
 		)
 	}
+	if t.journal != nil {
+		t.journal.add(defragOpPut, bucketType.Name(), key, value)
+	}
 	t.pending++
 }
 
@@ -184,7 +193,7 @@ func (t *batchTx) UnsafeRange(bucketType Bucket, key, endKey []byte, limit int64
 	return unsafeRange(bucket.Cursor(), key, endKey, limit)
 }
 
-func unsafeRange(c *bolt.Cursor, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
+func unsafeRange(c EngineCursor, key, endKey []byte, limit int64) (keys [][]byte, vs [][]byte) {
 	if limit <= 0 {
 		limit = math.MaxInt64
 	}
@@ -224,6 +233,9 @@ func (t *batchTx) UnsafeDelete(bucketType Bucket, key []byte) {
 			zap.Error(err),
 		)
 	}
+	if t.journal != nil {
+		t.journal.add(defragOpDelete, bucketType.Name(), key, nil)
+	}
 	t.pending++
 }
 
@@ -232,7 +244,7 @@ func (t *batchTx) UnsafeForEach(bucket Bucket, visitor func(k, v []byte) error)
 	return unsafeForEach(t.tx, bucket, visitor)
 }
 
-func unsafeForEach(tx *bolt.Tx, bucket Bucket, visitor func(k, v []byte) error) error {
+func unsafeForEach(tx EngineTx, bucket Bucket, visitor func(k, v []byte) error) error {
 	if b := tx.Bucket(bucket.Name()); b != nil {
 		return b.ForEach(visitor)
 	}
@@ -259,6 +271,12 @@ func (t *batchTx) safePending() int {
 	return t.pending
 }
 
+// commitStatser is implemented by the transactions of engines that report the
+// time spent in the stages of their commits.
+type commitStatser interface {
+	commitStats() (rebalance, spill, write time.Duration)
+}
+
 func (t *batchTx) commit(stop bool) {
 	// commit the last tx
 	if t.tx != nil {
@@ -272,9 +290,12 @@ func (t *batchTx) commit(stop bool) {
 		err := t.tx.Commit()
 		// gofail: var afterCommit struct{}
 
-		rebalanceSec.Observe(t.tx.Stats().RebalanceTime.Seconds())
-		spillSec.Observe(t.tx.Stats().SpillTime.Seconds())
-		writeSec.Observe(t.tx.Stats().WriteTime.Seconds())
+		if cs, ok := t.tx.(commitStatser); ok {
+			rebalance, spill, write := cs.commitStats()
+			rebalanceSec.Observe(rebalance.Seconds())
+			spillSec.Observe(spill.Seconds())
+			writeSec.Observe(write.Seconds())
+		}
 		commitSec.Observe(time.Since(start).Seconds())
 		atomic.AddInt64(&t.backend.commits, 1)
 
@@ -348,7 +369,7 @@ func (t *batchTxBuffered) unsafeCommit(stop bool) {
 	if t.backend.readTx.tx != nil {
 		// wait all store read transactions using the current boltdb tx to finish,
 		// then close the boltdb tx
-		go func(tx *bolt.Tx, wg *sync.WaitGroup) {
+		go func(tx EngineTx, wg *sync.WaitGroup) {
 			wg.Wait()
 			if err := tx.Rollback(); err != nil {
 				t.backend.lg.Fatal("failed to rollback tx", zap.Error(err))
@@ -0,0 +1,371 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"go.uber.org/zap"
+)
+
+var (
+	// onlineDefragSwapOps is the number of journaled writes below which an
+	// online defragmentation replays the journal while blocking writes, and
+	// swaps the databases.
+	onlineDefragSwapOps = 1000
+	// onlineDefragMaxReplays is the number of times the journal is replayed
+	// while writes continue, before writes are blocked to replay at most
+	// onlineDefragMaxBlockedOps journaled writes.
+	onlineDefragMaxReplays = 10
+	// onlineDefragMaxBlockedOps is the number of journaled writes above which
+	// an online defragmentation which cannot catch up with the writes is
+	// aborted, rather than blocking writes for the replay.
+	onlineDefragMaxBlockedOps = 10 * onlineDefragSwapOps
+	// onlineDefragMaxJournalBytes is the size of the keys and values of the
+	// journaled writes above which an online defragmentation is aborted.
+	onlineDefragMaxJournalBytes int64 = 256 * 1024 * 1024
+	// onlineDefragLogInterval is the interval of the progress logs.
+	onlineDefragLogInterval = 10 * time.Second
+	// onlineDefragCheckKeys is the number of keys copied between checks of
+	// the rate limit and updates of the progress.
+	onlineDefragCheckKeys = 100
+
+	errDefragAborted = errors.New("backend: online defragmentation aborted as the backend is closing")
+	// ErrDefragJournalFull is returned when the writes made during an online
+	// defragmentation outpace it; an offline defragmentation still succeeds.
+	ErrDefragJournalFull = errors.New("backend: online defragmentation aborted as the writes made meanwhile outpace it")
+)
+
+// OnlineDefragConfig configures an online defragmentation.
+type OnlineDefragConfig struct {
+	// RateLimitBytes limits the bytes of keys and values copied per second.
+	// Zero means no limit.
+	RateLimitBytes int64
+}
+
+// OnlineDefrag copies the database to a temporary database from a read
+// transaction, while the writes made meanwhile are recorded in a journal.
+// The journal is then replayed on the copy until few enough writes are left
+// to replay them while blocking writes, before the databases are swapped.
+// The defragmentation is aborted with ErrDefragJournalFull if the journal
+// outgrows its limits, and once ctx is done until writes are blocked.
+func (b *backend) OnlineDefrag(ctx context.Context, cfg OnlineDefragConfig) error {
+	if c, ok := b.engine.(EngineCompactor); ok {
+		return b.compact(c)
+	}
+
+	b.defragMu.Lock()
+	defer b.defragMu.Unlock()
+
+	now := time.Now()
+	isDefragActive.Set(1)
+	defer isDefragActive.Set(0)
+	defer defragProgress.Set(0)
+
+	tmpdb, err := b.openDefragDB()
+	if err != nil {
+		return err
+	}
+
+	// commit the pending writes, so the writes missing from the read tx of
+	// the copy are exactly the journaled ones
+	b.batchTx.LockOutsideApply()
+	b.batchTx.commit(false)
+	b.mu.RLock()
+	tx := b.unsafeBegin(false)
+	b.mu.RUnlock()
+	journal := &defragJournal{}
+	b.batchTx.journal = journal
+	b.batchTx.Unlock()
+
+	dbp := b.engine.Path()
+	size1, sizeInUse1 := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"defragmenting online",
+		zap.String("path", dbp),
+		zap.Int64("current-db-size-bytes", size1),
+		zap.String("current-db-size", humanize.Bytes(uint64(size1))),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse1),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse1))),
+		zap.Int64("rate-limit-bytes", cfg.RateLimitBytes),
+	)
+
+	dt := &defragThrottle{lg: b.lg, ctx: ctx, stopc: b.stopc, journal: journal, rate: cfg.RateLimitBytes, total: sizeInUse1, start: now, logged: now}
+	err = defragTx(tx, tmpdb, defragLimit, dt.visit)
+	tx.Rollback()
+	if err != nil {
+		return b.abortOnlineDefrag(tmpdb, err)
+	}
+	defragProgress.Set(1)
+
+	replays := 0
+	for {
+		b.batchTx.LockOutsideApply()
+		// the batch tx is stopped once the backend is closing
+		if err = dt.check(); err != nil {
+			b.batchTx.Unlock()
+			return b.abortOnlineDefrag(tmpdb, err)
+		}
+		if n := len(journal.ops); n <= onlineDefragSwapOps {
+			break
+		} else if replays == onlineDefragMaxReplays {
+			if n <= onlineDefragMaxBlockedOps {
+				break
+			}
+			b.batchTx.Unlock()
+			return b.abortOnlineDefrag(tmpdb, ErrDefragJournalFull)
+		}
+		ops := journal.take()
+		b.batchTx.Unlock()
+
+		replays++
+		if err = replayDefragOps(tmpdb, ops, defragLimit); err != nil {
+			return b.abortOnlineDefrag(tmpdb, err)
+		}
+	}
+	// the batch tx is locked from here on
+	defer b.batchTx.Unlock()
+	blocked := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// block concurrent read requests while swapping the databases
+	b.readTx.Lock()
+	defer b.readTx.Unlock()
+
+	b.batchTx.unsafeCommit(true)
+	b.batchTx.tx = nil
+
+	// the journal also holds the writes of the pre-commit hooks
+	ops := journal.take()
+	b.batchTx.journal = nil
+	if err = replayDefragOps(tmpdb, ops, defragLimit); err != nil {
+		b.batchTx.tx = b.unsafeBegin(true)
+		b.readTx.reset()
+		b.readTx.tx = b.unsafeBegin(false)
+		b.removeDefragDB(tmpdb)
+		return err
+	}
+	b.unsafeSwapDB(tmpdb)
+
+	took := time.Since(now)
+	defragSec.Observe(took.Seconds())
+
+	size2, sizeInUse2 := b.Size(), b.SizeInUse()
+	b.lg.Info(
+		"finished defragmenting directory online",
+		zap.String("path", dbp),
+		zap.Int64("current-db-size-bytes-diff", size2-size1),
+		zap.Int64("current-db-size-bytes", size2),
+		zap.String("current-db-size", humanize.Bytes(uint64(size2))),
+		zap.Int64("current-db-size-in-use-bytes-diff", sizeInUse2-sizeInUse1),
+		zap.Int64("current-db-size-in-use-bytes", sizeInUse2),
+		zap.String("current-db-size-in-use", humanize.Bytes(uint64(sizeInUse2))),
+		zap.Int("journal-replays", replays),
+		zap.Int("blocked-writes-replayed", len(ops)),
+		zap.Duration("blocked", time.Since(blocked)),
+		zap.Duration("took", took),
+	)
+	return nil
+}
+
+// abortOnlineDefrag stops journaling writes and removes the temporary
+// database of a failed online defragmentation.
+func (b *backend) abortOnlineDefrag(tmpdb Engine, err error) error {
+	b.batchTx.LockOutsideApply()
+	b.batchTx.journal = nil
+	b.batchTx.Unlock()
+	b.removeDefragDB(tmpdb)
+	return err
+}
+
+// defragThrottle paces the copy of an online defragmentation to its rate
+// limit, reports its progress and aborts it.
+type defragThrottle struct {
+	lg      *zap.Logger
+	ctx     context.Context
+	stopc   <-chan struct{}
+	journal *defragJournal
+	rate    int64
+	// total is the estimated number of bytes to copy.
+	total int64
+
+	start  time.Time
+	logged time.Time
+	keys   int
+	copied int64
+}
+
+func (dt *defragThrottle) visit(k, v []byte) error {
+	dt.copied += int64(len(k) + len(v))
+	if dt.keys++; dt.keys%onlineDefragCheckKeys != 0 {
+		return nil
+	}
+	if dt.total > 0 {
+		// the size in use includes the overhead of the pages, so the
+		// estimate stays below completion until the copy is done
+		defragProgress.Set(math.Min(float64(dt.copied)/float64(dt.total), 0.99))
+	}
+	now := time.Now()
+	if now.Sub(dt.logged) >= onlineDefragLogInterval {
+		dt.logged = now
+		dt.lg.Info(
+			"online defragmentation in progress",
+			zap.Int("copied-keys", dt.keys),
+			zap.String("copied", humanize.Bytes(uint64(dt.copied))),
+			zap.String("estimated-total", humanize.Bytes(uint64(dt.total))),
+			zap.Duration("took", now.Sub(dt.start)),
+		)
+	}
+
+	var wait time.Duration
+	if dt.rate > 0 {
+		due := dt.start.Add(time.Duration(float64(dt.copied) / float64(dt.rate) * float64(time.Second)))
+		wait = due.Sub(now)
+	}
+	if wait <= 0 {
+		return dt.check()
+	}
+	t := time.NewTimer(wait)
+	defer t.Stop()
+	select {
+	case <-dt.stopc:
+		return errDefragAborted
+	case <-dt.ctx.Done():
+		return dt.ctx.Err()
+	case <-t.C:
+		return dt.check()
+	}
+}
+
+// check returns the error aborting the online defragmentation, if any.
+func (dt *defragThrottle) check() error {
+	select {
+	case <-dt.stopc:
+		return errDefragAborted
+	case <-dt.ctx.Done():
+		return dt.ctx.Err()
+	default:
+	}
+	if dt.journal.full() {
+		return ErrDefragJournalFull
+	}
+	return nil
+}
+
+type defragOpType int
+
+const (
+	defragOpPut defragOpType = iota
+	defragOpDelete
+	defragOpCreateBucket
+	defragOpDeleteBucket
+)
+
+// defragOp is a write to the backend recorded in a defragJournal.
+type defragOp struct {
+	typ    defragOpType
+	bucket []byte
+	key    []byte
+	value  []byte
+}
+
+// defragJournal records the writes to the backend in order. It is protected
+// by the lock of the batch tx. As with the write buffer, the keys and values
+// are not copied, since they are not modified after they are written.
+type defragJournal struct {
+	ops []defragOp
+	// size is the size of the keys and values of ops, read atomically while
+	// copying the database. The journal stops recording once it is full.
+	size int64
+}
+
+func (j *defragJournal) add(typ defragOpType, bucket, key, value []byte) {
+	if j.full() {
+		return
+	}
+	if atomic.AddInt64(&j.size, int64(len(key)+len(value))) > onlineDefragMaxJournalBytes {
+		// the defragmentation is aborted; release the recorded writes
+		j.ops = nil
+		return
+	}
+	j.ops = append(j.ops, defragOp{typ: typ, bucket: bucket, key: key, value: value})
+}
+
+func (j *defragJournal) full() bool {
+	return atomic.LoadInt64(&j.size) > onlineDefragMaxJournalBytes
+}
+
+// take returns the recorded writes and empties the journal.
+func (j *defragJournal) take() []defragOp {
+	ops := j.ops
+	j.ops = nil
+	atomic.StoreInt64(&j.size, 0)
+	return ops
+}
+
+// replayDefragOps applies the writes to db, committing every limit writes.
+func replayDefragOps(db Engine, ops []defragOp, limit int) error {
+	for len(ops) > 0 {
+		n := len(ops)
+		if n > limit {
+			n = limit
+		}
+		if err := replayDefragOpsTx(db, ops[:n]); err != nil {
+			return err
+		}
+		ops = ops[n:]
+	}
+	return nil
+}
+
+func replayDefragOpsTx(db Engine, ops []defragOp) error {
+	tx, err := db.Begin(true)
+	if err != nil {
+		return err
+	}
+	for _, op := range ops {
+		if err = op.apply(tx); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (op defragOp) apply(tx EngineTx) error {
+	switch op.typ {
+	case defragOpCreateBucket:
+		_, err := tx.CreateBucket(op.bucket)
+		return err
+	case defragOpDeleteBucket:
+		return tx.DeleteBucket(op.bucket)
+	}
+	b := tx.Bucket(op.bucket)
+	if b == nil {
+		return fmt.Errorf("backend: cannot replay write to missing bucket %s", string(op.bucket))
+	}
+	if op.typ == defragOpPut {
+		return b.Put(op.key, op.value)
+	}
+	return b.Delete(op.key)
+}
@@ -0,0 +1,184 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+)
+
+// BboltEngine is the name of the bbolt storage engine, the default engine of
+// the backend.
+const BboltEngine = "bbolt"
+
+// Engine is an embedded key-value store the backend keeps its buckets in.
+// Engines are registered with RegisterEngine and selected by name with
+// BackendConfig.Engine.
+type Engine interface {
+	// Path returns the path of the database.
+	Path() string
+	// Begin starts a transaction. At most one writable transaction is open at
+	// a time. A read transaction sees the database as it was when the
+	// transaction began, regardless of the writable transactions committed
+	// since.
+	Begin(writable bool) (EngineTx, error)
+	// Stats returns the space and transaction statistics of the database.
+	Stats() EngineStats
+	// Close closes the database. All transactions must be closed before.
+	Close() error
+}
+
+// EngineStats are the statistics of the database of an engine.
+type EngineStats struct {
+	// FreeBytes is the number of bytes allocated by the database but not in
+	// use, which a defragmentation would reclaim.
+	FreeBytes int64
+	// OpenTxN is the number of open read transactions.
+	OpenTxN int
+}
+
+// EngineTx is a transaction of an engine. A transaction must not be used
+// concurrently.
+type EngineTx interface {
+	// Bucket returns the bucket of the given name, or nil if it does not exist.
+	Bucket(name []byte) EngineBucket
+	// CreateBucket creates the bucket of the given name if it does not exist,
+	// and returns it.
+	CreateBucket(name []byte) (EngineBucket, error)
+	// DeleteBucket deletes the bucket of the given name if it exists.
+	DeleteBucket(name []byte) error
+	// ForEachBucket calls f with the name of each bucket, in order.
+	ForEachBucket(f func(name []byte) error) error
+	// Size returns the size in bytes of the database seen by the transaction.
+	Size() int64
+	// WriteTo writes the database seen by the transaction to w, in a format
+	// the engine opens as a database once written to a file. Snapshots of the
+	// backend are written in this format.
+	WriteTo(w io.Writer) (n int64, err error)
+	// Check verifies the consistency of the database seen by the transaction.
+	Check() error
+	Commit() error
+	Rollback() error
+}
+
+// EngineBucket is a bucket of keys and values in a transaction. The keys and
+// values it returns are only valid for the life of the transaction.
+type EngineBucket interface {
+	// Get returns the value of key, or nil if key does not exist.
+	Get(key []byte) []byte
+	Put(key, value []byte) error
+	// Delete deletes key. Deleting a key that does not exist is not an error.
+	Delete(key []byte) error
+	// SetSequential hints that the following puts mostly append keys in order.
+	SetSequential()
+	Cursor() EngineCursor
+	// ForEach calls f with each key and value of the bucket, in key order.
+	ForEach(f func(k, v []byte) error) error
+}
+
+// EngineCursor iterates the keys of a bucket in order. Both methods return a
+// nil key past the last key.
+type EngineCursor interface {
+	// Seek moves the cursor to the first key greater than or equal to key.
+	Seek(key []byte) (k, v []byte)
+	// Next moves the cursor to the next key.
+	Next() (k, v []byte)
+}
+
+// EngineCompactor is implemented by engines that reclaim the space of deleted
+// data in place. The backend compacts such engines on defragmentation,
+// instead of copying the database to a new one.
+type EngineCompactor interface {
+	Compact() error
+}
+
+// EngineOpener opens the database at path, creating it if it does not exist.
+// The engine applies the options of cfg it supports and ignores the others.
+type EngineOpener func(path string, cfg BackendConfig) (Engine, error)
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]EngineOpener{BboltEngine: openBboltEngine}
+)
+
+// RegisterEngine makes an engine available under name. It panics if an
+// engine is already registered under the same name.
+func RegisterEngine(name string, open EngineOpener) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if open == nil {
+		panic("backend: RegisterEngine opener is nil")
+	}
+	if _, ok := engines[name]; ok {
+		panic("backend: RegisterEngine called twice for engine " + name)
+	}
+	engines[name] = open
+}
+
+// Engines returns the sorted names of the registered engines.
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// IsEngineRegistered returns whether an engine is registered under name. The
+// empty name stands for the default bbolt engine.
+func IsEngineRegistered(name string) bool {
+	if name == "" {
+		return true
+	}
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	_, ok := engines[name]
+	return ok
+}
+
+// OpenEngine opens the database at cfg.Path with the engine cfg.Engine,
+// without a backend on top. It is meant for tools reading a database or a
+// snapshot, typically with cfg.ReadOnly set.
+func OpenEngine(cfg BackendConfig) (Engine, error) {
+	name := cfg.engineName()
+	enginesMu.RLock()
+	open, ok := engines[name]
+	enginesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("backend: unknown storage engine %q (registered engines: %v)", name, Engines())
+	}
+	if name != BboltEngine && isBboltFile(cfg.Path) {
+		// existing data dirs keep their bbolt database, which another engine
+		// would not read
+		return nil, fmt.Errorf("backend: %s holds a bbolt database, which cannot be opened with storage engine %q", cfg.Path, name)
+	}
+	e, err := open(cfg.Path, cfg)
+	if err != nil || cfg.Cipher == nil {
+		return e, err
+	}
+	return newEncryptedEngine(e, cfg.Cipher), nil
+}
+
+func (bcfg *BackendConfig) engineName() string {
+	if bcfg.Engine == "" {
+		return BboltEngine
+	}
+	return bcfg.Engine
+}
@@ -0,0 +1,160 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// bboltMagic is the magic number of the meta pages of a bbolt database,
+// which follows the 16 bytes of the page header.
+const bboltMagic = 0xED0CDAED
+
+// bboltEngine is the Engine of a bbolt database.
+type bboltEngine struct {
+	db *bolt.DB
+}
+
+func openBboltEngine(path string, cfg BackendConfig) (Engine, error) {
+	bopts := &bolt.Options{}
+	if boltOpenOptions != nil {
+		*bopts = *boltOpenOptions
+	}
+	bopts.InitialMmapSize = cfg.mmapSize()
+	bopts.FreelistType = cfg.BackendFreelistType
+	bopts.NoSync = cfg.UnsafeNoFsync
+	bopts.NoGrowSync = cfg.UnsafeNoFsync
+	bopts.Mlock = cfg.Mlock
+
+	mode := os.FileMode(0600)
+	if cfg.ReadOnly {
+		bopts.ReadOnly = true
+		mode = 0400
+	}
+	db, err := bolt.Open(path, mode, bopts)
+	if err != nil {
+		return nil, err
+	}
+	return &bboltEngine{db: db}, nil
+}
+
+// isBboltFile returns whether path is a file starting with a bbolt meta page.
+func isBboltFile(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	var hdr [20]byte
+	if _, err = io.ReadFull(f, hdr[:]); err != nil {
+		return false
+	}
+	magic := hdr[16:]
+	return binary.LittleEndian.Uint32(magic) == bboltMagic || binary.BigEndian.Uint32(magic) == bboltMagic
+}
+
+func (e *bboltEngine) Path() string { return e.db.Path() }
+
+func (e *bboltEngine) Begin(writable bool) (EngineTx, error) {
+	tx, err := e.db.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &bboltTx{tx: tx}, nil
+}
+
+func (e *bboltEngine) Stats() EngineStats {
+	stats := e.db.Stats()
+	return EngineStats{
+		FreeBytes: int64(stats.FreePageN) * int64(e.db.Info().PageSize),
+		OpenTxN:   stats.OpenTxN,
+	}
+}
+
+func (e *bboltEngine) Close() error { return e.db.Close() }
+
+type bboltTx struct {
+	tx *bolt.Tx
+}
+
+func (t *bboltTx) Bucket(name []byte) EngineBucket {
+	b := t.tx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return bboltBucket{b}
+}
+
+func (t *bboltTx) CreateBucket(name []byte) (EngineBucket, error) {
+	b, err := t.tx.CreateBucketIfNotExists(name)
+	if err != nil {
+		return nil, err
+	}
+	return bboltBucket{b}, nil
+}
+
+func (t *bboltTx) DeleteBucket(name []byte) error {
+	if err := t.tx.DeleteBucket(name); err != nil && err != bolt.ErrBucketNotFound {
+		return err
+	}
+	return nil
+}
+
+func (t *bboltTx) ForEachBucket(f func(name []byte) error) error {
+	return t.tx.ForEach(func(name []byte, _ *bolt.Bucket) error { return f(name) })
+}
+
+func (t *bboltTx) Size() int64 { return t.tx.Size() }
+
+func (t *bboltTx) WriteTo(w io.Writer) (int64, error) { return t.tx.WriteTo(w) }
+
+func (t *bboltTx) Check() error {
+	var errs []string
+	for err := range t.tx.Check() {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d errors found.\n%s", len(errs), strings.Join(errs, "\n"))
+	}
+	return nil
+}
+
+func (t *bboltTx) Commit() error { return t.tx.Commit() }
+
+func (t *bboltTx) Rollback() error { return t.tx.Rollback() }
+
+func (t *bboltTx) commitStats() (rebalance, spill, write time.Duration) {
+	stats := t.tx.Stats()
+	return stats.RebalanceTime, stats.SpillTime, stats.WriteTime
+}
+
+type bboltBucket struct {
+	*bolt.Bucket
+}
+
+func (b bboltBucket) SetSequential() {
+	// it is useful to increase fill percent when the workloads are mostly append-only.
+	// this can delay the page split and reduce space usage.
+	b.FillPercent = 0.9
+}
+
+func (b bboltBucket) Cursor() EngineCursor { return b.Bucket.Cursor() }
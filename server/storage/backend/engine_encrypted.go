@@ -0,0 +1,159 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend
+
+import (
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+)
+
+// encryptedEngine encrypts the values stored in the database of an engine.
+// Keys are stored in plaintext, as the buckets are ordered by key. The bucket
+// and the key of a value are its associated data, so a value copied to
+// another key cannot be decrypted. Since the values are decrypted as they are
+// read, defragmenting the database rewrites them encrypted with the primary
+// key of the cipher.
+type encryptedEngine struct {
+	Engine
+	cipher encryption.Cipher
+}
+
+// encryptedCompactorEngine is an encryptedEngine of an engine that compacts
+// its database in place.
+type encryptedCompactorEngine struct {
+	*encryptedEngine
+}
+
+func (e encryptedCompactorEngine) Compact() error {
+	return e.Engine.(EngineCompactor).Compact()
+}
+
+func newEncryptedEngine(e Engine, c encryption.Cipher) Engine {
+	ee := &encryptedEngine{Engine: e, cipher: c}
+	if _, ok := e.(EngineCompactor); ok {
+		return encryptedCompactorEngine{ee}
+	}
+	return ee
+}
+
+func (e *encryptedEngine) Begin(writable bool) (EngineTx, error) {
+	tx, err := e.Engine.Begin(writable)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedTx{EngineTx: tx, cipher: e.cipher}, nil
+}
+
+// encryptedTx is a transaction of an encryptedEngine. WriteTo, Size and
+// Check see the encrypted database.
+type encryptedTx struct {
+	EngineTx
+	cipher encryption.Cipher
+}
+
+// commitStats returns the commit statistics of the underlying transaction.
+func (tx *encryptedTx) commitStats() (rebalance, spill, write time.Duration) {
+	if cs, ok := tx.EngineTx.(commitStatser); ok {
+		return cs.commitStats()
+	}
+	return 0, 0, 0
+}
+
+func (tx *encryptedTx) Bucket(name []byte) EngineBucket {
+	b := tx.EngineTx.Bucket(name)
+	if b == nil {
+		return nil
+	}
+	return &encryptedBucket{EngineBucket: b, name: name, cipher: tx.cipher}
+}
+
+func (tx *encryptedTx) CreateBucket(name []byte) (EngineBucket, error) {
+	b, err := tx.EngineTx.CreateBucket(name)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedBucket{EngineBucket: b, name: name, cipher: tx.cipher}, nil
+}
+
+type encryptedBucket struct {
+	EngineBucket
+	name   []byte
+	cipher encryption.Cipher
+}
+
+// ad returns the associated data of the value of key: the length of the
+// bucket name, the bucket name and the key.
+func (b *encryptedBucket) ad(key []byte) []byte {
+	ad := make([]byte, 4, 4+len(b.name)+len(key))
+	binary.BigEndian.PutUint32(ad, uint32(len(b.name)))
+	return append(append(ad, b.name...), key...)
+}
+
+func (b *encryptedBucket) Get(key []byte) []byte {
+	return b.decrypt(key, b.EngineBucket.Get(key))
+}
+
+func (b *encryptedBucket) Put(key, value []byte) error {
+	data, err := b.cipher.Encrypt(value, b.ad(key))
+	if err != nil {
+		return err
+	}
+	return b.EngineBucket.Put(key, data)
+}
+
+func (b *encryptedBucket) Cursor() EngineCursor {
+	return &encryptedCursor{c: b.EngineBucket.Cursor(), b: b}
+}
+
+func (b *encryptedBucket) ForEach(f func(k, v []byte) error) error {
+	return b.EngineBucket.ForEach(func(k, v []byte) error {
+		return f(k, b.decrypt(k, v))
+	})
+}
+
+// decrypt decrypts the value of key. The reads of the backend cannot fail,
+// so it panics if the value cannot be decrypted, like a corrupted database.
+func (b *encryptedBucket) decrypt(key, data []byte) []byte {
+	if data == nil {
+		return nil
+	}
+	v, err := b.cipher.Decrypt(data, b.ad(key))
+	if err != nil {
+		panic(fmt.Sprintf("backend: cannot decrypt the value of key %q: %v", key, err))
+	}
+	if v == nil {
+		// the value exists, though empty
+		v = []byte{}
+	}
+	return v
+}
+
+type encryptedCursor struct {
+	c EngineCursor
+	b *encryptedBucket
+}
+
+func (c *encryptedCursor) Seek(key []byte) (k, v []byte) {
+	k, v = c.c.Seek(key)
+	return k, c.b.decrypt(k, v)
+}
+
+func (c *encryptedCursor) Next() (k, v []byte) {
+	k, v = c.c.Next()
+	return k, c.b.decrypt(k, v)
+}
@@ -0,0 +1,95 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+type testKeyProvider []encryption.Key
+
+func (p testKeyProvider) Keys() ([]encryption.Key, error) { return p, nil }
+
+func fileContains(t *testing.T, path string, s string) bool {
+	b, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return bytes.Contains(b, []byte(s))
+}
+
+func TestBackendEncryption(t *testing.T) {
+	pb, path := newEngineTestBackend(t, backend.BboltEngine)
+	putTestKeys(t, pb)
+	want, err := pb.Hash(nil)
+	require.NoError(t, err)
+	require.NoError(t, pb.Close())
+
+	keyring, err := encryption.NewKeyring(testKeyProvider{{ID: 1, Data: bytes.Repeat([]byte{1}, 32)}})
+	require.NoError(t, err)
+	keyring.SetAllowPlaintext(true)
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Path, bcfg.Cipher = path, keyring
+	eb := backend.New(bcfg)
+	defer betesting.Close(t, eb)
+
+	// the values written before encryption was enabled are read as is while
+	// they are migrated
+	got, err := eb.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	tx := eb.BatchTx()
+	tx.Lock()
+	tx.UnsafePut(schema.Key, []byte("secret"), []byte("secret-value"))
+	tx.Unlock()
+	eb.ForceCommit()
+	assert.False(t, fileContains(t, path, "secret-value"))
+	assert.True(t, fileContains(t, path, "bar2"))
+
+	rtx := eb.ConcurrentReadTx()
+	rtx.RLock()
+	_, vals := rtx.UnsafeRange(schema.Key, []byte("secret"), nil, 0)
+	rtx.RUnlock()
+	assert.Equal(t, [][]byte{[]byte("secret-value")}, vals)
+
+	// defragmentation rewrites the values encrypted
+	require.NoError(t, eb.Defrag())
+	assert.False(t, fileContains(t, path, "bar2"))
+	// after which plaintext is rejected again
+	keyring.SetAllowPlaintext(false)
+	tx.Lock()
+	tx.UnsafeDelete(schema.Key, []byte("secret"))
+	tx.Unlock()
+	got, err = eb.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	snap := eb.Snapshot()
+	defer snap.Close()
+	var buf bytes.Buffer
+	_, err = snap.WriteTo(&buf)
+	require.NoError(t, err)
+	assert.NotContains(t, buf.String(), "bar2")
+}
@@ -0,0 +1,412 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backend_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+const fileEngine = "test-file"
+
+func init() {
+	backend.RegisterEngine(fileEngine, openFileEngine)
+}
+
+// fileEngineDB is a minimal Engine holding the database in memory, which is
+// rewritten to its file on each commit.
+type fileEngineDB struct {
+	path string
+	// wmu serializes the writable transactions.
+	wmu     sync.Mutex
+	mu      sync.Mutex
+	buckets map[string]map[string][]byte
+	openTxN int32
+	// compactions counts the calls to Compact.
+	compactions int
+}
+
+func openFileEngine(path string, cfg backend.BackendConfig) (backend.Engine, error) {
+	db := &fileEngineDB{path: path, buckets: make(map[string]map[string][]byte)}
+	f, err := os.Open(path)
+	switch {
+	case os.IsNotExist(err):
+		return db, db.save(db.buckets)
+	case err != nil:
+		return nil, err
+	}
+	defer f.Close()
+	if err = gob.NewDecoder(f).Decode(&db.buckets); err != nil && err != io.EOF {
+		return nil, err
+	}
+	return db, nil
+}
+
+func (db *fileEngineDB) save(buckets map[string]map[string][]byte) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(buckets); err != nil {
+		return err
+	}
+	return os.WriteFile(db.path, buf.Bytes(), 0600)
+}
+
+func (db *fileEngineDB) Path() string { return db.path }
+
+func (db *fileEngineDB) Begin(writable bool) (backend.EngineTx, error) {
+	if writable {
+		db.wmu.Lock()
+	} else {
+		atomic.AddInt32(&db.openTxN, 1)
+	}
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	// committed buckets are never modified, so a read transaction shares
+	// them and a writable one copies them
+	buckets := db.buckets
+	if writable {
+		buckets = make(map[string]map[string][]byte, len(db.buckets))
+		for name, b := range db.buckets {
+			nb := make(map[string][]byte, len(b))
+			for k, v := range b {
+				nb[k] = v
+			}
+			buckets[name] = nb
+		}
+	}
+	return &fileEngineTx{db: db, writable: writable, buckets: buckets}, nil
+}
+
+func (db *fileEngineDB) Stats() backend.EngineStats {
+	return backend.EngineStats{OpenTxN: int(atomic.LoadInt32(&db.openTxN))}
+}
+
+func (db *fileEngineDB) Close() error { return nil }
+
+type fileEngineTx struct {
+	db       *fileEngineDB
+	writable bool
+	buckets  map[string]map[string][]byte
+	closed   bool
+}
+
+func (tx *fileEngineTx) Bucket(name []byte) backend.EngineBucket {
+	b, ok := tx.buckets[string(name)]
+	if !ok {
+		return nil
+	}
+	return &fileEngineBucket{tx: tx, kvs: b}
+}
+
+func (tx *fileEngineTx) CreateBucket(name []byte) (backend.EngineBucket, error) {
+	if !tx.writable {
+		return nil, errors.New("read-only transaction")
+	}
+	if _, ok := tx.buckets[string(name)]; !ok {
+		tx.buckets[string(name)] = make(map[string][]byte)
+	}
+	return tx.Bucket(name), nil
+}
+
+func (tx *fileEngineTx) DeleteBucket(name []byte) error {
+	if !tx.writable {
+		return errors.New("read-only transaction")
+	}
+	delete(tx.buckets, string(name))
+	return nil
+}
+
+func (tx *fileEngineTx) ForEachBucket(f func(name []byte) error) error {
+	for _, name := range sortedKeys(tx.buckets) {
+		if err := f([]byte(name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tx *fileEngineTx) Size() int64 {
+	var size int64
+	for name, b := range tx.buckets {
+		size += int64(len(name))
+		for k, v := range b {
+			size += int64(len(k) + len(v))
+		}
+	}
+	return size
+}
+
+func (tx *fileEngineTx) WriteTo(w io.Writer) (int64, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(tx.buckets); err != nil {
+		return 0, err
+	}
+	return buf.WriteTo(w)
+}
+
+func (tx *fileEngineTx) Check() error { return nil }
+
+func (tx *fileEngineTx) Commit() error {
+	if !tx.writable {
+		return errors.New("read-only transaction")
+	}
+	defer tx.close()
+	if err := tx.db.save(tx.buckets); err != nil {
+		return err
+	}
+	tx.db.mu.Lock()
+	tx.db.buckets = tx.buckets
+	tx.db.mu.Unlock()
+	return nil
+}
+
+func (tx *fileEngineTx) Rollback() error {
+	tx.close()
+	return nil
+}
+
+func (tx *fileEngineTx) close() {
+	if tx.closed {
+		return
+	}
+	tx.closed = true
+	if tx.writable {
+		tx.db.wmu.Unlock()
+	} else {
+		atomic.AddInt32(&tx.db.openTxN, -1)
+	}
+}
+
+type fileEngineBucket struct {
+	tx  *fileEngineTx
+	kvs map[string][]byte
+}
+
+func (b *fileEngineBucket) Get(key []byte) []byte { return b.kvs[string(key)] }
+
+func (b *fileEngineBucket) Put(key, value []byte) error {
+	if !b.tx.writable {
+		return errors.New("read-only transaction")
+	}
+	b.kvs[string(key)] = append([]byte(nil), value...)
+	return nil
+}
+
+func (b *fileEngineBucket) Delete(key []byte) error {
+	if !b.tx.writable {
+		return errors.New("read-only transaction")
+	}
+	delete(b.kvs, string(key))
+	return nil
+}
+
+func (b *fileEngineBucket) SetSequential() {}
+
+func (b *fileEngineBucket) Cursor() backend.EngineCursor {
+	return &fileEngineCursor{kvs: b.kvs, keys: sortedKeys(b.kvs)}
+}
+
+func (b *fileEngineBucket) ForEach(f func(k, v []byte) error) error {
+	for _, k := range sortedKeys(b.kvs) {
+		if err := f([]byte(k), b.kvs[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type fileEngineCursor struct {
+	kvs  map[string][]byte
+	keys []string
+	i    int
+}
+
+func (c *fileEngineCursor) Seek(key []byte) ([]byte, []byte) {
+	c.i = sort.SearchStrings(c.keys, string(key))
+	return c.item()
+}
+
+func (c *fileEngineCursor) Next() ([]byte, []byte) {
+	c.i++
+	return c.item()
+}
+
+func (c *fileEngineCursor) item() ([]byte, []byte) {
+	if c.i >= len(c.keys) {
+		return nil, nil
+	}
+	k := c.keys[c.i]
+	return []byte(k), c.kvs[k]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// compactingFileEngineDB is a fileEngineDB compacting in place.
+type compactingFileEngineDB struct {
+	*fileEngineDB
+}
+
+func (db compactingFileEngineDB) Compact() error {
+	db.compactions++
+	return nil
+}
+
+func newEngineTestBackend(t *testing.T, engine string) (backend.Backend, string) {
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Engine = engine
+	bcfg.BatchInterval, bcfg.BatchLimit = time.Hour, 10000
+	return betesting.NewTmpBackendFromCfg(t, bcfg)
+}
+
+func putTestKeys(t *testing.T, b backend.Backend) {
+	tx := b.BatchTx()
+	tx.Lock()
+	tx.UnsafeCreateBucket(schema.Key)
+	tx.UnsafeCreateBucket(schema.Meta)
+	tx.UnsafePut(schema.Key, []byte("foo"), []byte("bar"))
+	tx.UnsafePut(schema.Key, []byte("foo1"), []byte("bar1"))
+	tx.UnsafePut(schema.Key, []byte("foo2"), []byte("bar2"))
+	tx.UnsafeDelete(schema.Key, []byte("foo1"))
+	tx.UnsafePut(schema.Meta, []byte("meta"), []byte("value"))
+	tx.Unlock()
+	b.ForceCommit()
+}
+
+func TestEngineRegistry(t *testing.T) {
+	assert.Contains(t, backend.Engines(), backend.BboltEngine)
+	assert.Contains(t, backend.Engines(), fileEngine)
+	assert.True(t, backend.IsEngineRegistered(""))
+	assert.False(t, backend.IsEngineRegistered("unknown"))
+	assert.Panics(t, func() { backend.RegisterEngine(fileEngine, openFileEngine) })
+
+	_, err := backend.OpenEngine(backend.BackendConfig{Engine: "unknown", Path: filepath.Join(t.TempDir(), "db")})
+	assert.Error(t, err)
+}
+
+func TestBackendEngine(t *testing.T) {
+	bb, _ := newEngineTestBackend(t, backend.BboltEngine)
+	defer betesting.Close(t, bb)
+	fb, path := newEngineTestBackend(t, fileEngine)
+	putTestKeys(t, bb)
+	putTestKeys(t, fb)
+
+	// the hash does not depend on the engine
+	want, err := bb.Hash(nil)
+	require.NoError(t, err)
+	got, err := fb.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	rtx := fb.ConcurrentReadTx()
+	rtx.RLock()
+	keys, vals := rtx.UnsafeRange(schema.Key, []byte("foo"), []byte("foo3"), 0)
+	rtx.RUnlock()
+	assert.Equal(t, [][]byte{[]byte("foo"), []byte("foo2")}, keys)
+	assert.Equal(t, [][]byte{[]byte("bar"), []byte("bar2")}, vals)
+
+	// a snapshot is opened by the engine it was taken from
+	snap := fb.Snapshot()
+	snapPath := filepath.Join(t.TempDir(), "snap.db")
+	f, err := os.Create(snapPath)
+	require.NoError(t, err)
+	_, err = snap.WriteTo(f)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	require.NoError(t, snap.Close())
+	sdb, err := backend.OpenEngine(backend.BackendConfig{Engine: fileEngine, Path: snapPath, ReadOnly: true})
+	require.NoError(t, err)
+	stx, err := sdb.Begin(false)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("bar2"), stx.Bucket(schema.Key.Name()).Get([]byte("foo2")))
+	require.NoError(t, stx.Rollback())
+	require.NoError(t, sdb.Close())
+
+	// the database is copied through the engine on defragmentation
+	require.NoError(t, fb.Defrag())
+	got, err = fb.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	require.NoError(t, fb.Close())
+	bcfg := backend.DefaultBackendConfig(zaptest.NewLogger(t))
+	bcfg.Engine, bcfg.Path = fileEngine, path
+	fb = backend.New(bcfg)
+	defer betesting.Close(t, fb)
+	got, err = fb.Hash(nil)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+}
+
+func TestBackendEngineCompact(t *testing.T) {
+	var db compactingFileEngineDB
+	backend.RegisterEngine("test-compacting-file", func(path string, cfg backend.BackendConfig) (backend.Engine, error) {
+		e, err := openFileEngine(path, cfg)
+		if err != nil {
+			return nil, err
+		}
+		db = compactingFileEngineDB{e.(*fileEngineDB)}
+		return db, nil
+	})
+	b, _ := newEngineTestBackend(t, "test-compacting-file")
+	defer betesting.Close(t, b)
+	putTestKeys(t, b)
+
+	require.NoError(t, b.Defrag())
+	assert.Equal(t, 1, db.compactions)
+	require.NoError(t, b.OnlineDefrag(context.Background(), backend.OnlineDefragConfig{}))
+	assert.Equal(t, 2, db.compactions)
+}
+
+func TestOpenEngineBboltFile(t *testing.T) {
+	b, path := newEngineTestBackend(t, backend.BboltEngine)
+	putTestKeys(t, b)
+	betesting.Close(t, b)
+
+	// an existing bbolt data dir is not opened by another engine
+	_, err := backend.OpenEngine(backend.BackendConfig{Engine: fileEngine, Path: path})
+	assert.ErrorContains(t, err, "bbolt database")
+	db, err := backend.OpenEngine(backend.BackendConfig{Path: path, ReadOnly: true})
+	require.NoError(t, err)
+	tx, err := db.Begin(false)
+	require.NoError(t, err)
+	assert.NoError(t, tx.Check())
+	assert.Equal(t, []byte("value"), tx.Bucket(schema.Meta.Name()).Get([]byte("meta")))
+	require.NoError(t, tx.Rollback())
+	require.NoError(t, db.Close())
+}
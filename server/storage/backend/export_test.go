@@ -17,13 +17,21 @@ package backend
 import bolt "go.etcd.io/bbolt"
 
 func DbFromBackendForTest(b Backend) *bolt.DB {
-	return b.(*backend).db
+	return b.(*backend).engine.(*bboltEngine).db
 }
 
 func DefragLimitForTest() int {
 	return defragLimit
 }
 
+// SetOnlineDefragMaxJournalBytesForTest sets the journal size limit of online
+// defragmentations and returns a function restoring it.
+func SetOnlineDefragMaxJournalBytesForTest(n int64) func() {
+	old := onlineDefragMaxJournalBytes
+	onlineDefragMaxJournalBytes = n
+	return func() { onlineDefragMaxJournalBytes = old }
+}
+
 func CommitsForTest(b Backend) int64 {
 	return b.(*backend).Commits()
 }
@@ -90,6 +90,13 @@ var (
 		Name:      "defrag_inflight",
 		Help:      "Whether or not defrag is active on the member. 1 means active, 0 means not.",
 	})
+
+	defragProgress = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "defrag_progress",
+		Help:      "The estimated fraction of the database copied by the ongoing online defragmentation.",
+	})
 )
 
 func init() {
@@ -100,4 +107,5 @@ func init() {
 	prometheus.MustRegister(defragSec)
 	prometheus.MustRegister(snapshotTransferSec)
 	prometheus.MustRegister(isDefragActive)
+	prometheus.MustRegister(defragProgress)
 }
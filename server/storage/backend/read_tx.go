@@ -17,8 +17,6 @@ package backend
 import (
 	"math"
 	"sync"
-
-	bolt "go.etcd.io/bbolt"
 )
 
 // IsSafeRangeBucket is a hack to avoid inadvertently reading duplicate keys;
@@ -44,8 +42,8 @@ type baseReadTx struct {
 	// TODO: group and encapsulate {txMu, tx, buckets, txWg}, as they share the same lifecycle.
 	// txMu protects accesses to buckets and tx on Range requests.
 	txMu    *sync.RWMutex
-	tx      *bolt.Tx
-	buckets map[BucketID]*bolt.Bucket
+	tx      EngineTx
+	buckets map[BucketID]EngineBucket
 	// txWg protects tx from being rolled back at the end of a batch interval until all reads using this tx are done.
 	txWg *sync.WaitGroup
 }
@@ -131,7 +129,7 @@ func (rt *readTx) RUnlock() { rt.mu.RUnlock() }
 
 func (rt *readTx) reset() {
 	rt.buf.reset()
-	rt.buckets = make(map[BucketID]*bolt.Bucket)
+	rt.buckets = make(map[BucketID]EngineBucket)
 	rt.tx = nil
 	rt.txWg = new(sync.WaitGroup)
 }
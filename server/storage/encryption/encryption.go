@@ -0,0 +1,70 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package encryption encrypts the data etcd stores at rest: the values of
+// the backend, the entries of the WAL and the snapshot files.
+package encryption
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	ErrKeyRequired = errors.New("encryption: data is encrypted but no encryption key is configured")
+	ErrUnknownKey  = errors.New("encryption: data is encrypted with an unknown key")
+	ErrCorrupt     = errors.New("encryption: encrypted data is corrupt")
+	ErrPlaintext   = errors.New("encryption: data is not encrypted")
+)
+
+// encryptedPrefix starts the data encrypted by a Keyring. Data without the
+// prefix is plaintext, written before encryption was enabled or tampered with.
+var encryptedPrefix = []byte("etcd:enc:aesgcm:v1:")
+
+// Cipher encrypts and decrypts data stored at rest. The associated data ad
+// is authenticated along with the plaintext but not stored, so the data can
+// only be decrypted with the same ad, e.g. under the key it was stored at.
+type Cipher interface {
+	// Encrypt returns the encryption of plaintext.
+	Encrypt(plaintext, ad []byte) ([]byte, error)
+	// Decrypt returns the plaintext of data. Data that is not encrypted is
+	// rejected with ErrPlaintext, unless the cipher accepts plaintext while
+	// the data written before encryption was enabled is migrated.
+	Decrypt(data, ad []byte) ([]byte, error)
+}
+
+// IsEncrypted returns true if data was encrypted by a Keyring.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, encryptedPrefix)
+}
+
+// Encrypt encrypts plaintext with c, or returns it as is if c is nil.
+func Encrypt(c Cipher, plaintext []byte) ([]byte, error) {
+	if c == nil {
+		return plaintext, nil
+	}
+	return c.Encrypt(plaintext, nil)
+}
+
+// Decrypt decrypts data with c. If c is nil, data is returned as is unless
+// it is encrypted.
+func Decrypt(c Cipher, data []byte) ([]byte, error) {
+	if c != nil {
+		return c.Decrypt(data, nil)
+	}
+	if IsEncrypted(data) {
+		return nil, ErrKeyRequired
+	}
+	return data, nil
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultKMSTimeout is the timeout of the requests to a KMS plugin.
+const DefaultKMSTimeout = 5 * time.Second
+
+// KeyProvider provides the keys of a Keyring.
+type KeyProvider interface {
+	// Keys returns the keys of the keyring. The last key is the primary key,
+	// the one data is encrypted with.
+	Keys() ([]Key, error)
+}
+
+// KMSPlugin decrypts the keys of a key file that are encrypted by a key
+// management service, so that the keys are never stored in plaintext.
+type KMSPlugin interface {
+	// Decrypt returns the plaintext of a key encrypted by the service.
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// FileKeyProvider provides the keys of a key file. Each line of the file is
+// a key, as its ID and its base64 encoding separated by a colon. Empty lines
+// and lines starting with '#' are ignored. The last key is the primary key,
+// so keys are rotated by appending a new key to the file.
+type FileKeyProvider struct {
+	// Path is the path of the key file.
+	Path string
+	// KMS decrypts the keys of the file if set. Otherwise, the keys are
+	// stored in plaintext.
+	KMS KMSPlugin
+}
+
+func (p *FileKeyProvider) Keys() ([]Key, error) {
+	b, err := os.ReadFile(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	var keys []Key
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for line := 1; sc.Scan(); line++ {
+		s := strings.TrimSpace(sc.Text())
+		if s == "" || strings.HasPrefix(s, "#") {
+			continue
+		}
+		key, err := p.parseKey(s)
+		if err != nil {
+			return nil, fmt.Errorf("encryption: invalid key at line %d of %s: %w", line, p.Path, err)
+		}
+		keys = append(keys, key)
+	}
+	if err = sc.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (p *FileKeyProvider) parseKey(s string) (Key, error) {
+	ids, data, ok := strings.Cut(s, ":")
+	if !ok {
+		return Key{}, fmt.Errorf("expected <id>:<base64 key>")
+	}
+	id, err := strconv.ParseUint(ids, 10, 32)
+	if err != nil {
+		return Key{}, err
+	}
+	key := Key{ID: uint32(id)}
+	if key.Data, err = base64.StdEncoding.DecodeString(data); err != nil {
+		return Key{}, err
+	}
+	if p.KMS != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultKMSTimeout)
+		defer cancel()
+		if key.Data, err = p.KMS.Decrypt(ctx, key.Data); err != nil {
+			return Key{}, fmt.Errorf("cannot decrypt key with the KMS plugin: %w", err)
+		}
+	}
+	return key, nil
+}
+
+// NewFileKeyring returns a keyring of the keys of the key file at path. The
+// keys are decrypted by the KMS plugin listening on the unix socket at
+// kmsSocket, unless it is empty.
+func NewFileKeyring(path, kmsSocket string) (*Keyring, error) {
+	p := &FileKeyProvider{Path: path}
+	if kmsSocket != "" {
+		p.KMS = NewUnixKMSPlugin(kmsSocket)
+	}
+	return NewKeyring(p)
+}
@@ -0,0 +1,106 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeKeyFile(t *testing.T, keys []Key, wrap func([]byte) []byte) string {
+	path := filepath.Join(t.TempDir(), "keys")
+	s := "# encryption keys\n\n"
+	for _, k := range keys {
+		s += fmt.Sprintf("%d:%s\n", k.ID, base64.StdEncoding.EncodeToString(wrap(k.Data)))
+	}
+	require.NoError(t, os.WriteFile(path, []byte(s), 0600))
+	return path
+}
+
+func TestFileKeyProvider(t *testing.T) {
+	keys := []Key{testKey(1), testKey(7)}
+	path := writeKeyFile(t, keys, func(b []byte) []byte { return b })
+
+	got, err := (&FileKeyProvider{Path: path}).Keys()
+	require.NoError(t, err)
+	assert.Equal(t, keys, got)
+
+	require.NoError(t, os.WriteFile(path, []byte("1-invalid\n"), 0600))
+	_, err = (&FileKeyProvider{Path: path}).Keys()
+	assert.ErrorContains(t, err, "line 1")
+}
+
+// xorKMS is a KMS plugin serving on a unix socket, which "encrypts" keys by
+// xoring them with 0xff.
+func xorKMS(t *testing.T) string {
+	socket := filepath.Join(t.TempDir(), "kms.sock")
+	l, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	mux := http.NewServeMux()
+	mux.HandleFunc(KMSDecryptPath, func(w http.ResponseWriter, r *http.Request) {
+		var req KMSDecryptRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		json.NewEncoder(w).Encode(&KMSDecryptResponse{Plaintext: xor(req.Ciphertext)})
+	})
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	t.Cleanup(func() { srv.Close() })
+	return socket
+}
+
+func xor(b []byte) []byte {
+	x := make([]byte, len(b))
+	for i := range b {
+		x[i] = b[i] ^ 0xff
+	}
+	return x
+}
+
+func TestFileKeyringKMS(t *testing.T) {
+	socket := xorKMS(t)
+	path := writeKeyFile(t, []Key{testKey(1)}, xor)
+
+	k, err := NewFileKeyring(path, socket)
+	require.NoError(t, err)
+	data, err := k.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+
+	// the keys are read as is without the plugin
+	plain, err := NewFileKeyring(writeKeyFile(t, []Key{testKey(1)}, xor), "")
+	require.NoError(t, err)
+	_, err = plain.Decrypt(data, nil)
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	unwrapped, err := NewFileKeyring(writeKeyFile(t, []Key{testKey(1)}, func(b []byte) []byte { return b }), "")
+	require.NoError(t, err)
+	plaintext, err := unwrapped.Decrypt(data, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+
+	_, err = NewFileKeyring(path, filepath.Join(t.TempDir(), "missing.sock"))
+	assert.ErrorContains(t, err, "KMS plugin")
+}
@@ -0,0 +1,164 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// keyIDBytes is the size of the key ID following the prefix of encrypted data.
+const keyIDBytes = 4
+
+// Key is an AES key of a keyring.
+type Key struct {
+	// ID identifies the key in the data it encrypts.
+	ID uint32
+	// Data is the AES-128, AES-192 or AES-256 key.
+	Data []byte
+}
+
+// Keyring is a Cipher encrypting data with AES-GCM. Data is encrypted with
+// the primary key of the keyring, and decrypted with the key it was
+// encrypted with, so that the keys can be rotated while the data encrypted
+// with the former keys is rewritten.
+type Keyring struct {
+	provider KeyProvider
+
+	mu      sync.RWMutex
+	aeads   map[uint32]cipher.AEAD
+	primary uint32
+	// allowPlaintext accepts data that is not encrypted.
+	allowPlaintext bool
+}
+
+// NewKeyring returns a keyring of the keys of the provider.
+func NewKeyring(provider KeyProvider) (*Keyring, error) {
+	k := &Keyring{provider: provider}
+	if _, err := k.Reload(); err != nil {
+		return nil, err
+	}
+	return k, nil
+}
+
+// Reload reloads the keys of the provider and returns the ID of the new
+// primary key. Data encrypted with keys the provider no longer has cannot
+// be decrypted anymore.
+func (k *Keyring) Reload() (uint32, error) {
+	keys, err := k.provider.Keys()
+	if err != nil {
+		return 0, err
+	}
+	if len(keys) == 0 {
+		return 0, errors.New("encryption: no encryption key provided")
+	}
+	aeads := make(map[uint32]cipher.AEAD, len(keys))
+	for _, key := range keys {
+		if _, ok := aeads[key.ID]; ok {
+			return 0, fmt.Errorf("encryption: duplicate encryption key ID %d", key.ID)
+		}
+		block, err := aes.NewCipher(key.Data)
+		if err != nil {
+			return 0, fmt.Errorf("encryption: invalid encryption key %d: %w", key.ID, err)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return 0, err
+		}
+		aeads[key.ID] = aead
+	}
+
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.aeads = aeads
+	k.primary = keys[len(keys)-1].ID
+	return k.primary, nil
+}
+
+// SetAllowPlaintext sets whether data that is not encrypted is decrypted as
+// is, which migrates the data written before encryption was enabled. Once it
+// is rewritten encrypted, plaintext should be rejected again, as it may have
+// been written by someone without the keys.
+func (k *Keyring) SetAllowPlaintext(allow bool) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.allowPlaintext = allow
+}
+
+// PrimaryKeyID returns the ID of the key data is encrypted with.
+func (k *Keyring) PrimaryKeyID() uint32 {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	return k.primary
+}
+
+// Encrypt encrypts plaintext with the primary key. The encrypted data is the
+// prefix, the key ID, the nonce and the sealed plaintext.
+func (k *Keyring) Encrypt(plaintext, ad []byte) ([]byte, error) {
+	k.mu.RLock()
+	id, aead := k.primary, k.aeads[k.primary]
+	k.mu.RUnlock()
+
+	n := len(encryptedPrefix) + keyIDBytes
+	data := make([]byte, n+aead.NonceSize(), n+aead.NonceSize()+len(plaintext)+aead.Overhead())
+	copy(data, encryptedPrefix)
+	binary.BigEndian.PutUint32(data[len(encryptedPrefix):], id)
+	nonce := data[n:]
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(data, nonce, plaintext, ad), nil
+}
+
+// Decrypt decrypts data with the key it was encrypted with. Data that is not
+// encrypted is returned as is if plaintext is allowed, and rejected
+// otherwise.
+func (k *Keyring) Decrypt(data, ad []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		k.mu.RLock()
+		allow := k.allowPlaintext
+		k.mu.RUnlock()
+		if !allow {
+			return nil, ErrPlaintext
+		}
+		return data, nil
+	}
+	data = data[len(encryptedPrefix):]
+	if len(data) < keyIDBytes {
+		return nil, ErrCorrupt
+	}
+	id := binary.BigEndian.Uint32(data)
+	data = data[keyIDBytes:]
+
+	k.mu.RLock()
+	aead, ok := k.aeads[id]
+	k.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w (key ID %d)", ErrUnknownKey, id)
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	plaintext, err := aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], ad)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plaintext, nil
+}
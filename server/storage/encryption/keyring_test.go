@@ -0,0 +1,122 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type staticKeyProvider struct {
+	keys []Key
+}
+
+func (p *staticKeyProvider) Keys() ([]Key, error) { return p.keys, nil }
+
+func testKey(id uint32) Key {
+	return Key{ID: id, Data: bytes.Repeat([]byte{byte(id)}, 32)}
+}
+
+func TestKeyringEncrypt(t *testing.T) {
+	k, err := NewKeyring(&staticKeyProvider{keys: []Key{testKey(1)}})
+	require.NoError(t, err)
+
+	data, err := k.Encrypt([]byte("secret"), nil)
+	require.NoError(t, err)
+	assert.True(t, IsEncrypted(data))
+	assert.NotContains(t, string(data), "secret")
+
+	plaintext, err := k.Decrypt(data, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+
+	// data is bound to its associated data
+	data, err = k.Encrypt([]byte("secret"), []byte("key"))
+	require.NoError(t, err)
+	plaintext, err = k.Decrypt(data, []byte("key"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("secret"), plaintext)
+	_, err = k.Decrypt(data, []byte("other-key"))
+	assert.ErrorIs(t, err, ErrCorrupt)
+
+	// data that is not encrypted is only read while it is migrated
+	_, err = k.Decrypt([]byte("plain"), nil)
+	assert.ErrorIs(t, err, ErrPlaintext)
+	k.SetAllowPlaintext(true)
+	plaintext, err = k.Decrypt([]byte("plain"), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plain"), plaintext)
+
+	data[len(data)-1] ^= 0xff
+	_, err = k.Decrypt(data, []byte("key"))
+	assert.ErrorIs(t, err, ErrCorrupt)
+}
+
+func TestKeyringReload(t *testing.T) {
+	p := &staticKeyProvider{keys: []Key{testKey(1)}}
+	k, err := NewKeyring(p)
+	require.NoError(t, err)
+	old, err := k.Encrypt([]byte("old"), nil)
+	require.NoError(t, err)
+
+	p.keys = append(p.keys, testKey(2))
+	primary, err := k.Reload()
+	require.NoError(t, err)
+	assert.Equal(t, uint32(2), primary)
+	assert.Equal(t, uint32(2), k.PrimaryKeyID())
+
+	data, err := k.Encrypt([]byte("new"), nil)
+	require.NoError(t, err)
+	plaintext, err := k.Decrypt(old, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("old"), plaintext)
+
+	// the retired key is removed once the data is rewritten
+	p.keys = p.keys[1:]
+	_, err = k.Reload()
+	require.NoError(t, err)
+	_, err = k.Decrypt(old, nil)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+	plaintext, err = k.Decrypt(data, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("new"), plaintext)
+
+	p.keys = nil
+	_, err = k.Reload()
+	assert.Error(t, err)
+	p.keys = []Key{testKey(1), testKey(1)}
+	_, err = k.Reload()
+	assert.Error(t, err)
+	p.keys = []Key{{ID: 3, Data: []byte("short")}}
+	_, err = k.Reload()
+	assert.Error(t, err)
+	assert.Equal(t, uint32(2), k.PrimaryKeyID())
+}
+
+func TestDecryptWithoutCipher(t *testing.T) {
+	k, err := NewKeyring(&staticKeyProvider{keys: []Key{testKey(1)}})
+	require.NoError(t, err)
+	data, err := Encrypt(k, []byte("secret"))
+	require.NoError(t, err)
+
+	_, err = Decrypt(nil, data)
+	assert.ErrorIs(t, err, ErrKeyRequired)
+	plaintext, err := Decrypt(nil, []byte("plain"))
+	require.NoError(t, err)
+	assert.Equal(t, []byte("plain"), plaintext)
+}
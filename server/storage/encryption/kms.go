@@ -0,0 +1,91 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// KMSDecryptPath is the HTTP path a unix socket KMS plugin serves decryption
+// requests on.
+const KMSDecryptPath = "/v1/decrypt"
+
+// KMSDecryptRequest is the JSON body of a decryption request to a unix
+// socket KMS plugin.
+type KMSDecryptRequest struct {
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// KMSDecryptResponse is the JSON body of the response of a unix socket KMS
+// plugin to a decryption request.
+type KMSDecryptResponse struct {
+	Plaintext []byte `json:"plaintext"`
+}
+
+// unixKMSPlugin is a KMSPlugin that posts decryption requests to a plugin
+// serving HTTP over a unix socket.
+type unixKMSPlugin struct {
+	socket string
+	client *http.Client
+}
+
+// NewUnixKMSPlugin returns a KMSPlugin talking to the plugin listening on
+// the unix socket at path. The plugin serves KMSDecryptPath, decrypting the
+// ciphertext of a KMSDecryptRequest into a KMSDecryptResponse.
+func NewUnixKMSPlugin(path string) KMSPlugin {
+	return &unixKMSPlugin{
+		socket: path,
+		client: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var d net.Dialer
+					return d.DialContext(ctx, "unix", path)
+				},
+			},
+		},
+	}
+}
+
+func (p *unixKMSPlugin) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	body, err := json.Marshal(&KMSDecryptRequest{Ciphertext: ciphertext})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://kms"+KMSDecryptPath, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("KMS plugin at %s: %w", p.socket, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("KMS plugin at %s: %s: %s", p.socket, resp.Status, bytes.TrimSpace(msg))
+	}
+	var dr KMSDecryptResponse
+	if err = json.NewDecoder(resp.Body).Decode(&dr); err != nil {
+		return nil, fmt.Errorf("KMS plugin at %s: %w", p.socket, err)
+	}
+	return dr.Plaintext, nil
+}
@@ -0,0 +1,47 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+// revisionBatchSize is the number of main revisions read from the key
+// bucket at a time by UnsafeForEachRevision.
+const revisionBatchSize = 1000
+
+// UnsafeForEachRevision calls f with the key bucket entries of the main
+// revisions after afterRev up to rev, in revision order. The entries are
+// read in batches of main revisions, so large ranges of revisions are never
+// held in memory at once. The caller must hold the lock of tx.
+func UnsafeForEachRevision(tx backend.ReadTx, afterRev, rev int64, f func(k, v []byte) error) error {
+	start, end := newRevBytes(), newRevBytes()
+	for main := afterRev + 1; main <= rev; main += revisionBatchSize {
+		next := main + revisionBatchSize
+		if next > rev+1 {
+			next = rev + 1
+		}
+		revToBytes(revision{main: main}, start)
+		revToBytes(revision{main: next}, end)
+		keys, vals := tx.UnsafeRange(schema.Key, start, end, 0)
+		for i := range keys {
+			if err := f(keys[i], vals[i]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
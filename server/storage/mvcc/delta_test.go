@@ -0,0 +1,71 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestUnsafeForEachRevision(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	// span several batches, with a main revision of two changes and a
+	// tombstone at the end
+	for i := 0; i < 2*revisionBatchSize+10; i++ {
+		s.Put([]byte("foo"), []byte(fmt.Sprint(i)), lease.NoLease)
+	}
+	txn := s.Write(traceutil.TODO())
+	txn.Put([]byte("a"), []byte("bar"), lease.NoLease)
+	txn.Put([]byte("b"), []byte("bar"), lease.NoLease)
+	txn.End()
+	s.DeleteRange([]byte("foo"), nil)
+	rev := s.Rev()
+
+	tx := b.ConcurrentReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+
+	var revs []revision
+	err := UnsafeForEachRevision(tx, 5, rev, func(k, v []byte) error {
+		revs = append(revs, bytesToRev(k))
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, revs, int(rev-5)+1)
+	assert.Equal(t, revision{main: 6}, revs[0])
+	for i := 1; i < len(revs); i++ {
+		assert.True(t, revs[i].GreaterThan(revs[i-1]), "revision %v after %v", revs[i], revs[i-1])
+	}
+	assert.Equal(t, revision{main: rev}, revs[len(revs)-1])
+
+	revs = nil
+	err = UnsafeForEachRevision(tx, rev, rev, func(k, v []byte) error {
+		revs = append(revs, bytesToRev(k))
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Empty(t, revs)
+}
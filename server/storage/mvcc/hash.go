@@ -39,6 +39,23 @@ func unsafeHashByRev(tx backend.ReadTx, compactRevision, revision int64, keep ma
 	return h.Hash(), err
 }
 
+// unsafeHashSince hashes the revisions after baseRev up to rev and the
+// revisions at or before baseRev in keep. Unlike unsafeHashByRev, revisions at
+// or before baseRev are skipped even if keep is empty.
+func unsafeHashSince(tx backend.ReadTx, baseRev, rev int64, keep map[revision]struct{}) (KeyValueHash, error) {
+	h := newKVHasher(baseRev, rev, keep)
+	err := tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
+		if kr := bytesToRev(k); kr.main <= baseRev {
+			if _, ok := keep[kr]; !ok {
+				return nil
+			}
+		}
+		h.WriteKeyValue(k, v)
+		return nil
+	})
+	return h.Hash(), err
+}
+
 type kvHasher struct {
 	hash            hash.Hash32
 	compactRevision int64
@@ -92,6 +109,17 @@ type HashStorage interface {
 	// HashByRev computes the hash of all MVCC revisions up to a given revision.
 	HashByRev(rev int64) (hash KeyValueHash, currentRev int64, err error)
 
+	// HashSince computes the hash of the key space at baseRev and of all MVCC
+	// revisions after it up to rev. Unlike HashByRev, the hash does not depend
+	// on how far the store is compacted, as long as it is not compacted past
+	// baseRev.
+	HashSince(baseRev, rev int64) (KeyValueHash, error)
+
+	// ReadSince opens a read transaction on the current revision and calls f
+	// with it and the hashes of HashSince at baseRev and at that revision,
+	// both computed from the same transaction.
+	ReadSince(baseRev int64, f func(tx backend.ReadTx, baseHash, hash KeyValueHash) error) error
+
 	// Store adds hash value in local cache, allowing it can be returned by HashByRev.
 	Store(valueHash KeyValueHash)
 
@@ -134,6 +162,14 @@ func (s *hashStorage) HashByRev(rev int64) (KeyValueHash, int64, error) {
 	return s.store.hashByRev(rev)
 }
 
+func (s *hashStorage) HashSince(baseRev, rev int64) (KeyValueHash, error) {
+	return s.store.hashSince(baseRev, rev)
+}
+
+func (s *hashStorage) ReadSince(baseRev int64, f func(tx backend.ReadTx, baseHash, hash KeyValueHash) error) error {
+	return s.store.readSince(baseRev, f)
+}
+
 func (s *hashStorage) Store(hash KeyValueHash) {
 	s.lg.Info("storing new hash",
 		zap.Uint32("hash", hash.Hash),
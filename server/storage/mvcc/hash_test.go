@@ -20,12 +20,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap/zaptest"
 
 	"go.etcd.io/etcd/pkg/v3/traceutil"
 	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 	"go.etcd.io/etcd/server/v3/storage/mvcc/testutil"
+	"go.etcd.io/etcd/server/v3/storage/schema"
 )
 
 // TestHashByRevValue test HashByRevValue values to ensure we don't change the
@@ -128,6 +131,87 @@ func testHashByRev(t *testing.T, s *store, rev int64) KeyValueHash {
 	return hash
 }
 
+// TestHashSince ensures that the hash of a store since a base revision does
+// not depend on how far the store is compacted.
+func TestHashSince(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	s.Put([]byte("foo"), []byte("bar1"), lease.NoLease)
+	s.Put([]byte("gone"), []byte("bar"), lease.NoLease)
+	s.DeleteRange([]byte("gone"), nil)
+	s.Put([]byte("foo"), []byte("bar2"), lease.NoLease)
+	s.Put([]byte("baz"), []byte("bar"), lease.NoLease)
+	baseRev := s.Rev()
+	s.Put([]byte("foo"), []byte("bar3"), lease.NoLease)
+	s.DeleteRange([]byte("baz"), nil)
+	rev := s.Rev()
+
+	baseHash, err := s.HashStorage().HashSince(baseRev, baseRev)
+	require.NoError(t, err)
+	hash, err := s.HashStorage().HashSince(baseRev, rev)
+	require.NoError(t, err)
+	assert.NotEqual(t, baseHash.Hash, hash.Hash)
+
+	for _, compactRev := range []int64{3, baseRev} {
+		done, err := s.Compact(traceutil.TODO(), compactRev)
+		require.NoError(t, err)
+		<-done
+
+		got, err := s.HashStorage().HashSince(baseRev, baseRev)
+		require.NoError(t, err)
+		assert.Equal(t, baseHash, got, "compacted at %d", compactRev)
+		got, err = s.HashStorage().HashSince(baseRev, rev)
+		require.NoError(t, err)
+		assert.Equal(t, hash, got, "compacted at %d", compactRev)
+	}
+
+	_, err = s.HashStorage().HashSince(baseRev, rev+1)
+	assert.Equal(t, ErrFutureRev, err)
+	_, err = s.Compact(traceutil.TODO(), baseRev+1)
+	require.NoError(t, err)
+	_, err = s.HashStorage().HashSince(baseRev, rev)
+	assert.Equal(t, ErrCompacted, err)
+}
+
+// TestReadSince ensures that the hashes of ReadSince match HashSince and
+// are computed from the transaction passed to the callback.
+func TestReadSince(t *testing.T) {
+	b, _ := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+
+	s.Put([]byte("foo"), []byte("bar1"), lease.NoLease)
+	baseRev := s.Rev()
+	s.Put([]byte("foo"), []byte("bar2"), lease.NoLease)
+	rev := s.Rev()
+
+	baseHash, err := s.HashStorage().HashSince(baseRev, baseRev)
+	require.NoError(t, err)
+	hash, err := s.HashStorage().HashSince(baseRev, rev)
+	require.NoError(t, err)
+
+	err = s.HashStorage().ReadSince(baseRev, func(tx backend.ReadTx, gotBase, got KeyValueHash) error {
+		assert.Equal(t, baseHash, gotBase)
+		assert.Equal(t, hash, got)
+
+		// writes after the transaction is opened are not seen by it
+		s.Put([]byte("foo"), []byte("bar3"), lease.NoLease)
+		return tx.UnsafeForEach(schema.Key, func(k, v []byte) error {
+			assert.LessOrEqual(t, bytesToRev(k).main, got.Revision)
+			return nil
+		})
+	})
+	require.NoError(t, err)
+
+	err = s.HashStorage().ReadSince(s.Rev()+1, func(backend.ReadTx, KeyValueHash, KeyValueHash) error { return nil })
+	assert.Equal(t, ErrFutureRev, err)
+	done, err := s.Compact(traceutil.TODO(), baseRev+1)
+	require.NoError(t, err)
+	<-done
+	err = s.HashStorage().ReadSince(baseRev, func(backend.ReadTx, KeyValueHash, KeyValueHash) error { return nil })
+	assert.Equal(t, ErrCompacted, err)
+}
+
 // TestCompactionHash tests compaction hash
 // TODO: Change this to fuzz test
 func TestCompactionHash(t *testing.T) {
@@ -27,6 +27,9 @@ type RangeOptions struct {
 	Limit int64
 	Rev   int64
 	Count bool
+	// Filter, if set, restricts the range to the key-values it returns true
+	// for. Limit and Count apply to the matching key-values only.
+	Filter func(kv *mvccpb.KeyValue) bool
 }
 
 type RangeResult struct {
@@ -89,6 +92,17 @@ type TxnWrite interface {
 	Changes() []mvccpb.KeyValue
 }
 
+// UsageTracker accounts the space used by a subset of the keyspace. Its
+// methods are called from within write txns, so they must not access the store.
+type UsageTracker interface {
+	// Tracked reports whether changes to the given key should be reported.
+	Tracked(key []byte) bool
+	// Update reports that the size of the key changed by bytes and that the
+	// number of keys changed by keys. The size of a key is the length of its
+	// name plus the length of its value.
+	Update(key []byte, bytes, keys int64)
+}
+
 // txnReadWrite coerces a read txn to a write, panicking on any write operation.
 type txnReadWrite struct{ TxnRead }
 
@@ -128,6 +142,10 @@ type KV interface {
 	// Commit commits outstanding txns into the underlying backend.
 	Commit()
 
+	// Scrub validates the consistency of the key index, the key bucket and
+	// the leases of the keys, while reads and writes continue.
+	Scrub(throttle func(n int) error) error
+
 	// Restore restores the KV store from a backend.
 	Restore(b backend.Backend) error
 	Close() error
@@ -256,6 +256,46 @@ func testKVRangeLimit(t *testing.T, f rangeFunc) {
 	}
 }
 
+func TestKVRangeFilter(t *testing.T)    { testKVRangeFilter(t, normalRangeFunc) }
+func TestKVTxnRangeFilter(t *testing.T) { testKVRangeFilter(t, txnRangeFunc) }
+
+func testKVRangeFilter(t *testing.T, f rangeFunc) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	kvs := put3TestKVs(s)
+	notLease2 := func(kv *mvccpb.KeyValue) bool { return kv.Lease != 2 }
+	none := func(kv *mvccpb.KeyValue) bool { return false }
+
+	tests := []struct {
+		filter  func(kv *mvccpb.KeyValue) bool
+		limit   int64
+		count   bool
+		wcounts int
+		wkvs    []mvccpb.KeyValue
+	}{
+		{filter: notLease2, wcounts: 2, wkvs: []mvccpb.KeyValue{kvs[0], kvs[2]}},
+		// the limit applies to the matching keys, the count is not limited
+		{filter: notLease2, limit: 1, wcounts: 2, wkvs: kvs[:1]},
+		{filter: notLease2, limit: 2, wcounts: 2, wkvs: []mvccpb.KeyValue{kvs[0], kvs[2]}},
+		{filter: notLease2, count: true, wcounts: 2},
+		{filter: none, wcounts: 0},
+	}
+	for i, tt := range tests {
+		r, err := f(s, []byte("foo"), []byte("foo3"), RangeOptions{Limit: tt.limit, Count: tt.count, Filter: tt.filter})
+		if err != nil {
+			t.Fatalf("#%d: range error (%v)", i, err)
+		}
+		if !reflect.DeepEqual(r.KVs, tt.wkvs) {
+			t.Errorf("#%d: kvs = %+v, want %+v", i, r.KVs, tt.wkvs)
+		}
+		if r.Count != tt.wcounts {
+			t.Errorf("#%d: count = %d, want %d", i, r.Count, tt.wcounts)
+		}
+	}
+}
+
 func TestKVPutMultipleTimes(t *testing.T)    { testKVPutMultipleTimes(t, normalPutFunc) }
 func TestKVTxnPutMultipleTimes(t *testing.T) { testKVPutMultipleTimes(t, txnPutFunc) }
 
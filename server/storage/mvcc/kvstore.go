@@ -53,6 +53,9 @@ var minimumBatchInterval = 10 * time.Millisecond
 type StoreConfig struct {
 	CompactionBatchLimit    int
 	CompactionSleepInterval time.Duration
+	// UsageTracker, if set, is notified about every change in the size of
+	// the keys it tracks.
+	UsageTracker UsageTracker
 }
 
 type store struct {
@@ -198,6 +201,68 @@ func (s *store) hashByRev(rev int64) (hash KeyValueHash, currentRev int64, err e
 	return hash, currentRev, err
 }
 
+func (s *store) hashSince(baseRev, rev int64) (KeyValueHash, error) {
+	start := time.Now()
+
+	s.mu.RLock()
+	s.revMu.RLock()
+	compactRev, currentRev := s.compactMainRev, s.currentRev
+	s.revMu.RUnlock()
+
+	if baseRev < compactRev {
+		s.mu.RUnlock()
+		return KeyValueHash{}, ErrCompacted
+	} else if rev > currentRev || baseRev > rev {
+		s.mu.RUnlock()
+		return KeyValueHash{}, ErrFutureRev
+	}
+	keep := s.kvindex.Keep(rev)
+
+	tx := s.b.ReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	s.mu.RUnlock()
+	hash, err := unsafeHashSince(tx, baseRev, rev, keep)
+	hashRevSec.Observe(time.Since(start).Seconds())
+	return hash, err
+}
+
+func (s *store) readSince(baseRev int64, f func(tx backend.ReadTx, baseHash, hash KeyValueHash) error) error {
+	start := time.Now()
+
+	s.mu.RLock()
+	s.revMu.RLock()
+	compactRev, rev := s.compactMainRev, s.currentRev
+	if baseRev < compactRev {
+		s.revMu.RUnlock()
+		s.mu.RUnlock()
+		return ErrCompacted
+	} else if baseRev > rev {
+		s.revMu.RUnlock()
+		s.mu.RUnlock()
+		return ErrFutureRev
+	}
+	// the current revision cannot move while the read transaction is
+	// opened, so it holds exactly the revisions up to rev
+	tx := s.b.ConcurrentReadTx()
+	tx.RLock()
+	defer tx.RUnlock()
+	s.revMu.RUnlock()
+	baseKeep, keep := s.kvindex.Keep(baseRev), s.kvindex.Keep(rev)
+	s.mu.RUnlock()
+
+	baseHash, err := unsafeHashSince(tx, baseRev, baseRev, baseKeep)
+	if err != nil {
+		return err
+	}
+	hash, err := unsafeHashSince(tx, baseRev, rev, keep)
+	if err != nil {
+		return err
+	}
+	hashRevSec.Observe(time.Since(start).Seconds())
+	return f(tx, baseHash, hash)
+}
+
 func (s *store) updateCompactRev(rev int64) (<-chan struct{}, int64, error) {
 	s.revMu.Lock()
 	if rev <= s.compactMainRev {
@@ -0,0 +1,253 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+const (
+	// scrubBatchLimit is the number of revisions Scrub reads per
+	// transaction, so that compactions are not blocked for long.
+	scrubBatchLimit = 1000
+	// scrubConfirmDelay is the time after which the keys found attached to
+	// a missing lease are read again, since revoking a lease removes the
+	// lease before its keys.
+	scrubConfirmDelay = time.Second
+	// scrubMaxFindings is the number of inconsistencies described by the
+	// error of Scrub.
+	scrubMaxFindings = 10
+)
+
+// Scrub validates the key index against the key bucket, and the leases of
+// the keys against the lessor:
+//   - every revision of the key bucket after the compacted revision is in
+//     the index, and every deleted key is deleted in the index
+//   - the revision of every key of the index is in the key bucket
+//   - every key attached to a lease has its lease granted
+//
+// Reads and writes continue meanwhile. throttle is called with the number of
+// bytes read, so that the caller can pace the scrub or abort it by returning
+// an error. Scrub returns an error describing the inconsistencies found.
+func (s *store) Scrub(throttle func(n int) error) error {
+	s.mu.RLock()
+	b := s.b
+	s.mu.RUnlock()
+
+	f := &scrubFindings{}
+	if err := s.scrubKeyBucket(b, throttle, f); err != nil {
+		return err
+	}
+	suspects, err := s.scrubIndex(b, throttle, f)
+	if err != nil {
+		return err
+	}
+	if err = s.confirmLeaseSuspects(suspects, f); err != nil {
+		return err
+	}
+	return f.err()
+}
+
+// scrubKeyBucket checks the revisions of the key bucket against the index.
+func (s *store) scrubKeyBucket(b backend.Backend, throttle func(n int) error, f *scrubFindings) error {
+	start := newRevBytes()
+	for {
+		s.mu.RLock()
+		if s.b != b {
+			// the store was restored from a snapshot, and its index rebuilt
+			s.mu.RUnlock()
+			return nil
+		}
+		s.revMu.RLock()
+		tx := s.b.ConcurrentReadTx()
+		tx.RLock()
+		compactRev, rev := s.compactMainRev, s.currentRev
+		s.revMu.RUnlock()
+
+		end := newRevBytes()
+		revToBytes(revision{main: rev + 1}, end)
+		keys, vals := tx.UnsafeRange(schema.Key, start, end, scrubBatchLimit)
+		n := 0
+		for i := range keys {
+			n += len(keys[i]) + len(vals[i])
+			r := bytesToRev(keys[i])
+			if r.main <= compactRev {
+				// compacted revisions are deleted from the index first
+				continue
+			}
+			var kv mvccpb.KeyValue
+			if err := kv.Unmarshal(vals[i]); err != nil {
+				f.add("revision %d_%d of the key bucket cannot be unmarshaled: %v", r.main, r.sub, err)
+				continue
+			}
+			modified, _, _, err := s.kvindex.Get(kv.Key, r.main)
+			if isTombstone(keys[i]) {
+				if err == nil {
+					f.add("key %q is deleted at revision %d_%d of the key bucket, but not in the index", kv.Key, r.main, r.sub)
+				}
+				continue
+			}
+			if kv.ModRevision != r.main {
+				f.add("key %q at revision %d_%d of the key bucket has mod revision %d", kv.Key, r.main, r.sub, kv.ModRevision)
+			}
+			if err != nil || modified != r {
+				f.add("key %q at revision %d_%d of the key bucket is missing from the index", kv.Key, r.main, r.sub)
+			}
+		}
+		done := len(keys) < scrubBatchLimit
+		if !done {
+			// the keys are only valid in the transaction
+			start = append(append(start[:0], keys[len(keys)-1]...), 0)
+		}
+		tx.RUnlock()
+		s.mu.RUnlock()
+
+		if err := throttle(n); err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+	}
+}
+
+type leaseSuspect struct {
+	key     []byte
+	leaseID lease.LeaseID
+}
+
+// scrubIndex checks the revisions of the keys of the index against the key
+// bucket, and returns the keys attached to a lease the lessor misses.
+func (s *store) scrubIndex(b backend.Backend, throttle func(n int) error, f *scrubFindings) ([]leaseSuspect, error) {
+	s.mu.RLock()
+	s.revMu.RLock()
+	atRev := s.currentRev
+	s.revMu.RUnlock()
+	keys, revs := s.kvindex.Range([]byte{}, []byte{}, atRev)
+	s.mu.RUnlock()
+
+	var suspects []leaseSuspect
+	for lo := 0; lo < len(keys); lo += scrubBatchLimit {
+		hi := lo + scrubBatchLimit
+		if hi > len(keys) {
+			hi = len(keys)
+		}
+
+		s.mu.RLock()
+		if s.b != b {
+			s.mu.RUnlock()
+			return nil, nil
+		}
+		s.revMu.RLock()
+		tx := s.b.ConcurrentReadTx()
+		tx.RLock()
+		compactRev := s.compactMainRev
+		s.revMu.RUnlock()
+
+		n := 0
+		rb := newRevBytes()
+		for i := lo; i < hi; i++ {
+			r := revs[i]
+			if compactRev > atRev && r.main <= compactRev {
+				// the key may have been modified after atRev and compacted
+				continue
+			}
+			revToBytes(r, rb)
+			_, vals := tx.UnsafeRange(schema.Key, rb, nil, 0)
+			if len(vals) == 0 {
+				f.add("key %q at revision %d_%d of the index is missing from the key bucket", keys[i], r.main, r.sub)
+				continue
+			}
+			n += len(rb) + len(vals[0])
+			var kv mvccpb.KeyValue
+			if err := kv.Unmarshal(vals[0]); err != nil {
+				f.add("revision %d_%d of the key bucket cannot be unmarshaled: %v", r.main, r.sub, err)
+				continue
+			}
+			if !bytes.Equal(kv.Key, keys[i]) {
+				f.add("key %q at revision %d_%d of the index is key %q in the key bucket", keys[i], r.main, r.sub, kv.Key)
+				continue
+			}
+			if kv.Lease != 0 && s.le != nil && s.le.Lookup(lease.LeaseID(kv.Lease)) == nil {
+				suspects = append(suspects, leaseSuspect{key: keys[i], leaseID: lease.LeaseID(kv.Lease)})
+			}
+		}
+		tx.RUnlock()
+		s.mu.RUnlock()
+
+		if err := throttle(n); err != nil {
+			return nil, err
+		}
+	}
+	return suspects, nil
+}
+
+// confirmLeaseSuspects reports the suspects still attached to a missing lease
+// once a revocation in progress would have deleted them.
+func (s *store) confirmLeaseSuspects(suspects []leaseSuspect, f *scrubFindings) error {
+	if len(suspects) == 0 {
+		return nil
+	}
+	s.mu.RLock()
+	stopc := s.stopc
+	s.mu.RUnlock()
+	select {
+	case <-time.After(scrubConfirmDelay):
+	case <-stopc:
+		return nil
+	}
+	for _, sp := range suspects {
+		tr := s.Read(ConcurrentReadTxMode, traceutil.TODO())
+		r, err := tr.Range(context.TODO(), sp.key, nil, RangeOptions{})
+		tr.End()
+		if err != nil {
+			return err
+		}
+		if len(r.KVs) == 1 && lease.LeaseID(r.KVs[0].Lease) == sp.leaseID && s.le.Lookup(sp.leaseID) == nil {
+			f.add("key %q is attached to lease %x, which does not exist", sp.key, int64(sp.leaseID))
+		}
+	}
+	return nil
+}
+
+// scrubFindings collects the inconsistencies found by a scrub.
+type scrubFindings struct {
+	n    int
+	msgs []string
+}
+
+func (f *scrubFindings) add(format string, args ...any) {
+	f.n++
+	if len(f.msgs) < scrubMaxFindings {
+		f.msgs = append(f.msgs, fmt.Sprintf(format, args...))
+	}
+}
+
+func (f *scrubFindings) err() error {
+	if f.n == 0 {
+		return nil
+	}
+	return fmt.Errorf("mvcc: %d inconsistencies found:\n%s", f.n, strings.Join(f.msgs, "\n"))
+}
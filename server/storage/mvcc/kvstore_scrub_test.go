@@ -0,0 +1,112 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mvcc
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/pkg/v3/traceutil"
+	"go.etcd.io/etcd/server/v3/lease"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+	"go.etcd.io/etcd/server/v3/storage/schema"
+)
+
+func noThrottle(int) error { return nil }
+
+func TestScrub(t *testing.T) {
+	tcs := []struct {
+		name    string
+		corrupt func(s *store)
+		expect  string
+	}{
+		{
+			name:    "consistent store",
+			corrupt: func(s *store) {},
+		},
+		{
+			name: "revision missing from the index",
+			corrupt: func(s *store) {
+				s.kvindex = newTreeIndex(s.lg)
+			},
+			expect: "of the key bucket is missing from the index",
+		},
+		{
+			name: "revision missing from the key bucket",
+			corrupt: func(s *store) {
+				rev, _, _, err := s.kvindex.Get([]byte("foo1"), s.currentRev)
+				if err != nil {
+					t.Fatal(err)
+				}
+				rb := newRevBytes()
+				revToBytes(rev, rb)
+				tx := s.b.BatchTx()
+				tx.LockOutsideApply()
+				tx.UnsafeDelete(schema.Key, rb)
+				tx.Unlock()
+				s.b.ForceCommit()
+			},
+			expect: `key "foo1" at revision 8_0 of the index is missing from the key bucket`,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			b, tmpPath := betesting.NewDefaultTmpBackend(t)
+			s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+			defer cleanup(s, b, tmpPath)
+
+			for i := 0; i < 5; i++ {
+				s.Put([]byte(fmt.Sprintf("foo%d", i)), []byte("bar"), lease.NoLease)
+			}
+			s.DeleteRange([]byte("foo0"), nil)
+			s.Put([]byte("foo1"), []byte("baz"), lease.NoLease)
+			ch, err := s.Compact(traceutil.TODO(), 3)
+			if err != nil {
+				t.Fatal(err)
+			}
+			<-ch
+			s.Put([]byte("foo2"), []byte("baz"), lease.NoLease)
+
+			tc.corrupt(s)
+			err = s.Scrub(noThrottle)
+			if tc.expect == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.expect) {
+				t.Fatalf("error = %v, want %q", err, tc.expect)
+			}
+		})
+	}
+}
+
+func TestScrubMissingLease(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	// the fake lessor has no lease
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{})
+	defer cleanup(s, b, tmpPath)
+
+	s.Put([]byte("foo"), []byte("bar"), lease.LeaseID(0x42))
+	err := s.Scrub(noThrottle)
+	expect := `key "foo" is attached to lease 42, which does not exist`
+	if err == nil || !strings.Contains(err.Error(), expect) {
+		t.Fatalf("error = %v, want %q", err, expect)
+	}
+}
@@ -701,6 +701,51 @@ func TestTxnPut(t *testing.T) {
 	}
 }
 
+type fakeUsageTracker struct {
+	prefix      []byte
+	bytes, keys int64
+}
+
+func (t *fakeUsageTracker) Tracked(key []byte) bool { return bytes.HasPrefix(key, t.prefix) }
+
+func (t *fakeUsageTracker) Update(key []byte, bytesDelta, keysDelta int64) {
+	t.bytes += bytesDelta
+	t.keys += keysDelta
+}
+
+func TestStoreUsageTracker(t *testing.T) {
+	b, tmpPath := betesting.NewDefaultTmpBackend(t)
+	tracker := &fakeUsageTracker{prefix: []byte("/a/")}
+	s := NewStore(zaptest.NewLogger(t), b, &lease.FakeLessor{}, StoreConfig{UsageTracker: tracker})
+	defer cleanup(s, b, tmpPath)
+
+	tests := []struct {
+		op func()
+
+		wbytes int64
+		wkeys  int64
+	}{
+		{func() { s.Put([]byte("/a/foo"), []byte("bar"), lease.NoLease) }, 9, 1},
+		{func() { s.Put([]byte("/b/foo"), []byte("bar"), lease.NoLease) }, 9, 1},
+		{func() { s.Put([]byte("/a/foo"), []byte("barbar"), lease.NoLease) }, 12, 1},
+		{func() { s.Put([]byte("/a/foo2"), []byte(""), lease.NoLease) }, 19, 2},
+		{func() { s.DeleteRange([]byte("/a/foo"), nil) }, 7, 1},
+		{func() { s.DeleteRange([]byte("/a/foo"), nil) }, 7, 1},
+		{func() {
+			txn := s.Write(traceutil.TODO())
+			txn.Put([]byte("/a/foo"), []byte("bar"), lease.NoLease)
+			txn.DeleteRange([]byte("/a/"), []byte("/a0"))
+			txn.End()
+		}, 0, 0},
+	}
+	for i, tt := range tests {
+		tt.op()
+		if tracker.bytes != tt.wbytes || tracker.keys != tt.wkeys {
+			t.Errorf("#%d: usage = (%d bytes, %d keys), want (%d bytes, %d keys)", i, tracker.bytes, tracker.keys, tt.wbytes, tt.wkeys)
+		}
+	}
+}
+
 // TestConcurrentReadNotBlockingWrite ensures Read does not blocking Write after its creation
 func TestConcurrentReadNotBlockingWrite(t *testing.T) {
 	b, tmpPath := betesting.NewDefaultTmpBackend(t)
@@ -961,18 +1006,20 @@ type fakeBackend struct {
 	tx *fakeBatchTx
 }
 
-func (b *fakeBackend) BatchTx() backend.BatchTx                                   { return b.tx }
-func (b *fakeBackend) ReadTx() backend.ReadTx                                     { return b.tx }
-func (b *fakeBackend) ConcurrentReadTx() backend.ReadTx                           { return b.tx }
-func (b *fakeBackend) Hash(func(bucketName, keyName []byte) bool) (uint32, error) { return 0, nil }
-func (b *fakeBackend) Size() int64                                                { return 0 }
-func (b *fakeBackend) SizeInUse() int64                                           { return 0 }
-func (b *fakeBackend) OpenReadTxN() int64                                         { return 0 }
-func (b *fakeBackend) Snapshot() backend.Snapshot                                 { return nil }
-func (b *fakeBackend) ForceCommit()                                               {}
-func (b *fakeBackend) Defrag() error                                              { return nil }
-func (b *fakeBackend) Close() error                                               { return nil }
-func (b *fakeBackend) SetTxPostLockInsideApplyHook(func())                        {}
+func (b *fakeBackend) BatchTx() backend.BatchTx                                       { return b.tx }
+func (b *fakeBackend) ReadTx() backend.ReadTx                                         { return b.tx }
+func (b *fakeBackend) ConcurrentReadTx() backend.ReadTx                               { return b.tx }
+func (b *fakeBackend) Hash(func(bucketName, keyName []byte) bool) (uint32, error)     { return 0, nil }
+func (b *fakeBackend) Size() int64                                                    { return 0 }
+func (b *fakeBackend) SizeInUse() int64                                               { return 0 }
+func (b *fakeBackend) OpenReadTxN() int64                                             { return 0 }
+func (b *fakeBackend) Snapshot() backend.Snapshot                                     { return nil }
+func (b *fakeBackend) ForceCommit()                                                   {}
+func (b *fakeBackend) Defrag() error                                                  { return nil }
+func (b *fakeBackend) OnlineDefrag(context.Context, backend.OnlineDefragConfig) error { return nil }
+func (b *fakeBackend) Check() error                                                   { return nil }
+func (b *fakeBackend) Close() error                                                   { return nil }
+func (b *fakeBackend) SetTxPostLockInsideApplyHook(func())                            {}
 
 type indexGetResp struct {
 	rev     revision
@@ -75,6 +75,9 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 	if rev < tr.s.compactMainRev {
 		return &RangeResult{KVs: nil, Count: -1, Rev: 0}, ErrCompacted
 	}
+	if ro.Filter != nil {
+		return tr.rangeFilteredKeys(ctx, key, end, rev, curRev, ro)
+	}
 	if ro.Count {
 		total := tr.s.kvindex.CountRevisions(key, end, rev)
 		tr.trace.Step("count revisions from in-memory index tree")
@@ -99,33 +102,69 @@ func (tr *storeTxnRead) rangeKeys(ctx context.Context, key, end []byte, curRev i
 			return nil, fmt.Errorf("rangeKeys: context cancelled: %w", ctx.Err())
 		default:
 		}
-		revToBytes(revpair, revBytes)
-		_, vs := tr.tx.UnsafeRange(schema.Key, revBytes, nil, 0)
-		if len(vs) != 1 {
-			tr.s.lg.Fatal(
-				"range failed to find revision pair",
-				zap.Int64("revision-main", revpair.main),
-				zap.Int64("revision-sub", revpair.sub),
-				zap.Int64("revision-current", curRev),
-				zap.Int64("range-option-rev", ro.Rev),
-				zap.Int64("range-option-limit", ro.Limit),
-				zap.Binary("key", key),
-				zap.Binary("end", end),
-				zap.Int("len-revpairs", len(revpairs)),
-				zap.Int("len-values", len(vs)),
-			)
+		tr.readKeyValue(&kvs[i], revBytes, revpair, key, end, curRev, ro, len(revpairs))
+	}
+	tr.trace.Step("range keys from bolt db")
+	return &RangeResult{KVs: kvs, Count: total, Rev: curRev}, nil
+}
+
+// rangeFilteredKeys ranges over the key-values matching ro.Filter. Every
+// key-value of the range has to be read to evaluate the filter, so the count
+// is the number of matching key-values rather than the size of the range.
+func (tr *storeTxnRead) rangeFilteredKeys(ctx context.Context, key, end []byte, rev, curRev int64, ro RangeOptions) (*RangeResult, error) {
+	revpairs, _ := tr.s.kvindex.Revisions(key, end, rev, 0)
+	tr.trace.Step("range keys from in-memory index tree")
+
+	var kvs []mvccpb.KeyValue
+	total := 0
+	revBytes := newRevBytes()
+	for _, revpair := range revpairs {
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("rangeKeys: context cancelled: %w", ctx.Err())
+		default:
 		}
-		if err := kvs[i].Unmarshal(vs[0]); err != nil {
-			tr.s.lg.Fatal(
-				"failed to unmarshal mvccpb.KeyValue",
-				zap.Error(err),
-			)
+		var kv mvccpb.KeyValue
+		tr.readKeyValue(&kv, revBytes, revpair, key, end, curRev, ro, len(revpairs))
+		if !ro.Filter(&kv) {
+			continue
+		}
+		total++
+		if !ro.Count && (ro.Limit <= 0 || int64(len(kvs)) < ro.Limit) {
+			kvs = append(kvs, kv)
 		}
 	}
-	tr.trace.Step("range keys from bolt db")
+	tr.trace.Step("filter keys from bolt db")
 	return &RangeResult{KVs: kvs, Count: total, Rev: curRev}, nil
 }
 
+// readKeyValue reads the key-value at revpair into kv. The remaining
+// arguments only describe the range for logging.
+func (tr *storeTxnRead) readKeyValue(kv *mvccpb.KeyValue, revBytes []byte, revpair revision, key, end []byte, curRev int64, ro RangeOptions, nrevpairs int) {
+	revToBytes(revpair, revBytes)
+	_, vs := tr.tx.UnsafeRange(schema.Key, revBytes, nil, 0)
+	if len(vs) != 1 {
+		tr.s.lg.Fatal(
+			"range failed to find revision pair",
+			zap.Int64("revision-main", revpair.main),
+			zap.Int64("revision-sub", revpair.sub),
+			zap.Int64("revision-current", curRev),
+			zap.Int64("range-option-rev", ro.Rev),
+			zap.Int64("range-option-limit", ro.Limit),
+			zap.Binary("key", key),
+			zap.Binary("end", end),
+			zap.Int("len-revpairs", nrevpairs),
+			zap.Int("len-values", len(vs)),
+		)
+	}
+	if err := kv.Unmarshal(vs[0]); err != nil {
+		tr.s.lg.Fatal(
+			"failed to unmarshal mvccpb.KeyValue",
+			zap.Error(err),
+		)
+	}
+}
+
 func (tr *storeTxnRead) End() {
 	tr.tx.RUnlock() // RUnlock signals the end of concurrentReadTx.
 	tr.s.mu.RUnlock()
@@ -195,12 +234,19 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 
 	// if the key exists before, use its previous created and
 	// get its previous leaseID
-	_, created, ver, err := tw.s.kvindex.Get(key, rev)
+	modified, created, ver, err := tw.s.kvindex.Get(key, rev)
 	if err == nil {
 		c = created.main
 		oldLease = tw.s.le.GetLease(lease.LeaseItem{Key: string(key)})
 		tw.trace.Step("get key's previous created_revision and leaseID")
 	}
+	if t := tw.s.cfg.UsageTracker; t != nil && t.Tracked(key) {
+		if err == nil {
+			t.Update(key, int64(len(value))-tw.valueSize(modified), 0)
+		} else {
+			t.Update(key, int64(len(key)+len(value)), 1)
+		}
+	}
 	ibytes := newRevBytes()
 	idxRev := revision{main: rev, sub: int64(len(tw.changes))}
 	revToBytes(idxRev, ibytes)
@@ -214,6 +260,11 @@ func (tw *storeTxnWrite) put(key, value []byte, leaseID lease.LeaseID) {
 		Version:        ver,
 		Lease:          int64(leaseID),
 	}
+	if leaseID != lease.NoLease && tw.s.le != nil {
+		if l := tw.s.le.Lookup(leaseID); l != nil && l.IsKeyLease() {
+			kv.Ttl = l.TTL()
+		}
+	}
 
 	d, err := kv.Marshal()
 	if err != nil {
@@ -274,6 +325,12 @@ func (tw *storeTxnWrite) deleteRange(key, end []byte) int64 {
 }
 
 func (tw *storeTxnWrite) delete(key []byte) {
+	if t := tw.s.cfg.UsageTracker; t != nil && t.Tracked(key) {
+		if modified, _, _, err := tw.s.kvindex.Get(key, tw.beginRev+1); err == nil {
+			t.Update(key, -int64(len(key))-tw.valueSize(modified), -1)
+		}
+	}
+
 	ibytes := newRevBytes()
 	idxRev := revision{main: tw.beginRev + 1, sub: int64(len(tw.changes))}
 	revToBytes(idxRev, ibytes)
@@ -315,4 +372,27 @@ func (tw *storeTxnWrite) delete(key []byte) {
 	}
 }
 
+// valueSize returns the length of the value written at the given revision.
+func (tw *storeTxnWrite) valueSize(rev revision) int64 {
+	ibytes := newRevBytes()
+	revToBytes(rev, ibytes)
+	_, vs := tw.tx.UnsafeRange(schema.Key, ibytes, nil, 0)
+	if len(vs) != 1 {
+		tw.storeTxnRead.s.lg.Fatal(
+			"failed to find the previous revision of a key",
+			zap.Int64("revision-main", rev.main),
+			zap.Int64("revision-sub", rev.sub),
+			zap.Int("len-values", len(vs)),
+		)
+	}
+	var kv mvccpb.KeyValue
+	if err := kv.Unmarshal(vs[0]); err != nil {
+		tw.storeTxnRead.s.lg.Fatal(
+			"failed to unmarshal mvccpb.KeyValue",
+			zap.Error(err),
+		)
+	}
+	return int64(len(kv.Value))
+}
+
 func (tw *storeTxnWrite) Changes() []mvccpb.KeyValue { return tw.changes }
@@ -54,6 +54,7 @@ func (abe *authBackend) CreateAuthBuckets() {
 	tx.UnsafeCreateBucket(Auth)
 	tx.UnsafeCreateBucket(AuthUsers)
 	tx.UnsafeCreateBucket(AuthRoles)
+	tx.UnsafeCreateBucket(AuthTenants)
 }
 
 func (abe *authBackend) ForceCommit() {
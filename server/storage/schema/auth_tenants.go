@@ -0,0 +1,94 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+)
+
+func (abe *authBackend) GetTenant(tenantName string) *authpb.Tenant {
+	tx := abe.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	return tx.UnsafeGetTenant(tenantName)
+}
+
+func (atx *authBatchTx) UnsafeGetTenant(tenantName string) *authpb.Tenant {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeGetTenant(tenantName)
+}
+
+func (abe *authBackend) GetAllTenants() []*authpb.Tenant {
+	tx := abe.BatchTx()
+	tx.Lock()
+	defer tx.Unlock()
+	return tx.UnsafeGetAllTenants()
+}
+
+func (atx *authBatchTx) UnsafeGetAllTenants() []*authpb.Tenant {
+	arx := &authReadTx{tx: atx.tx, lg: atx.lg}
+	return arx.UnsafeGetAllTenants()
+}
+
+func (atx *authBatchTx) UnsafePutTenant(tenant *authpb.Tenant) {
+	b, err := tenant.Marshal()
+	if err != nil {
+		atx.lg.Panic(
+			"failed to marshal 'authpb.Tenant'",
+			zap.String("tenant-name", string(tenant.Name)),
+			zap.Error(err),
+		)
+	}
+
+	atx.tx.UnsafePut(AuthTenants, tenant.Name, b)
+}
+
+func (atx *authBatchTx) UnsafeDeleteTenant(tenantName string) {
+	atx.tx.UnsafeDelete(AuthTenants, []byte(tenantName))
+}
+
+func (atx *authReadTx) UnsafeGetTenant(tenantName string) *authpb.Tenant {
+	_, vs := atx.tx.UnsafeRange(AuthTenants, []byte(tenantName), nil, 0)
+	if len(vs) == 0 {
+		return nil
+	}
+
+	tenant := &authpb.Tenant{}
+	err := tenant.Unmarshal(vs[0])
+	if err != nil {
+		atx.lg.Panic("failed to unmarshal 'authpb.Tenant'", zap.Error(err))
+	}
+	return tenant
+}
+
+func (atx *authReadTx) UnsafeGetAllTenants() []*authpb.Tenant {
+	_, vs := atx.tx.UnsafeRange(AuthTenants, []byte{0}, []byte{0xff}, -1)
+	if len(vs) == 0 {
+		return nil
+	}
+
+	tenants := make([]*authpb.Tenant, len(vs))
+	for i := range vs {
+		tenant := &authpb.Tenant{}
+		err := tenant.Unmarshal(vs[i])
+		if err != nil {
+			atx.lg.Panic("failed to unmarshal 'authpb.Tenant'", zap.Error(err))
+		}
+		tenants[i] = tenant
+	}
+	return tenants
+}
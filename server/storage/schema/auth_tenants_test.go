@@ -0,0 +1,150 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/authpb"
+	"go.etcd.io/etcd/server/v3/auth"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestGetAllTenants(t *testing.T) {
+	tcs := []struct {
+		name  string
+		setup func(tx auth.AuthBatchTx)
+		want  []*authpb.Tenant
+	}{
+		{
+			name:  "Empty by default",
+			setup: func(tx auth.AuthBatchTx) {},
+			want:  nil,
+		},
+		{
+			name: "Returns data put before",
+			setup: func(tx auth.AuthBatchTx) {
+				tx.UnsafePutTenant(&authpb.Tenant{
+					Name:   []byte("tenant1"),
+					Prefix: []byte("/tenant1/"),
+				})
+			},
+			want: []*authpb.Tenant{
+				{Name: []byte("tenant1"), Prefix: []byte("/tenant1/")},
+			},
+		},
+		{
+			name: "Skips deleted",
+			setup: func(tx auth.AuthBatchTx) {
+				tx.UnsafePutTenant(&authpb.Tenant{
+					Name:   []byte("tenant1"),
+					Prefix: []byte("/tenant1/"),
+				})
+				tx.UnsafePutTenant(&authpb.Tenant{
+					Name:   []byte("tenant2"),
+					Prefix: []byte("/tenant2/"),
+				})
+				tx.UnsafeDeleteTenant("tenant1")
+			},
+			want: []*authpb.Tenant{{Name: []byte("tenant2"), Prefix: []byte("/tenant2/")}},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zaptest.NewLogger(t)
+			be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+			abe := NewAuthBackend(lg, be)
+			abe.CreateAuthBuckets()
+
+			tx := abe.BatchTx()
+			tx.Lock()
+			tc.setup(tx)
+			tx.Unlock()
+
+			abe.ForceCommit()
+			be.Close()
+
+			be2 := backend.NewDefaultBackend(lg, tmpPath)
+			defer be2.Close()
+			abe2 := NewAuthBackend(lg, be2)
+			tenants := abe2.GetAllTenants()
+
+			assert.Equal(t, tc.want, tenants)
+		})
+	}
+}
+
+func TestGetTenant(t *testing.T) {
+	tcs := []struct {
+		name  string
+		setup func(tx auth.AuthBatchTx)
+		want  *authpb.Tenant
+	}{
+		{
+			name:  "Returns nil for missing",
+			setup: func(tx auth.AuthBatchTx) {},
+			want:  nil,
+		},
+		{
+			name: "Returns data put before",
+			setup: func(tx auth.AuthBatchTx) {
+				tx.UnsafePutTenant(&authpb.Tenant{
+					Name:   []byte("tenant1"),
+					Prefix: []byte("/tenant1/"),
+				})
+			},
+			want: &authpb.Tenant{Name: []byte("tenant1"), Prefix: []byte("/tenant1/")},
+		},
+		{
+			name: "Return nil for deleted",
+			setup: func(tx auth.AuthBatchTx) {
+				tx.UnsafePutTenant(&authpb.Tenant{
+					Name:   []byte("tenant1"),
+					Prefix: []byte("/tenant1/"),
+				})
+				tx.UnsafeDeleteTenant("tenant1")
+			},
+			want: nil,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zaptest.NewLogger(t)
+			be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+			abe := NewAuthBackend(lg, be)
+			abe.CreateAuthBuckets()
+
+			tx := abe.BatchTx()
+			tx.Lock()
+			tc.setup(tx)
+			tx.Unlock()
+
+			abe.ForceCommit()
+			be.Close()
+
+			be2 := backend.NewDefaultBackend(lg, tmpPath)
+			defer be2.Close()
+			abe2 := NewAuthBackend(lg, be2)
+			tenant := abe2.GetTenant("tenant1")
+
+			assert.Equal(t, tc.want, tenant)
+		})
+	}
+}
@@ -26,15 +26,17 @@ var (
 	metaBucketName  = []byte("meta")
 	leaseBucketName = []byte("lease")
 	alarmBucketName = []byte("alarm")
+	quotaBucketName = []byte("quota")
 
 	clusterBucketName = []byte("cluster")
 
 	membersBucketName        = []byte("members")
 	membersRemovedBucketName = []byte("members_removed")
 
-	authBucketName      = []byte("auth")
-	authUsersBucketName = []byte("authUsers")
-	authRolesBucketName = []byte("authRoles")
+	authBucketName        = []byte("auth")
+	authUsersBucketName   = []byte("authUsers")
+	authRolesBucketName   = []byte("authRoles")
+	authTenantsBucketName = []byte("authTenants")
 
 	testBucketName = []byte("test")
 )
@@ -45,17 +47,23 @@ var (
 	Lease   = backend.Bucket(bucket{id: 3, name: leaseBucketName, safeRangeBucket: false})
 	Alarm   = backend.Bucket(bucket{id: 4, name: alarmBucketName, safeRangeBucket: false})
 	Cluster = backend.Bucket(bucket{id: 5, name: clusterBucketName, safeRangeBucket: false})
+	Quota   = backend.Bucket(bucket{id: 6, name: quotaBucketName, safeRangeBucket: false})
 
 	Members        = backend.Bucket(bucket{id: 10, name: membersBucketName, safeRangeBucket: false})
 	MembersRemoved = backend.Bucket(bucket{id: 11, name: membersRemovedBucketName, safeRangeBucket: false})
 
-	Auth      = backend.Bucket(bucket{id: 20, name: authBucketName, safeRangeBucket: false})
-	AuthUsers = backend.Bucket(bucket{id: 21, name: authUsersBucketName, safeRangeBucket: false})
-	AuthRoles = backend.Bucket(bucket{id: 22, name: authRolesBucketName, safeRangeBucket: false})
+	Auth        = backend.Bucket(bucket{id: 20, name: authBucketName, safeRangeBucket: false})
+	AuthUsers   = backend.Bucket(bucket{id: 21, name: authUsersBucketName, safeRangeBucket: false})
+	AuthRoles   = backend.Bucket(bucket{id: 22, name: authRolesBucketName, safeRangeBucket: false})
+	AuthTenants = backend.Bucket(bucket{id: 23, name: authTenantsBucketName, safeRangeBucket: false})
 
 	Test = backend.Bucket(bucket{id: 100, name: testBucketName, safeRangeBucket: false})
 )
 
+// DeltaFullBuckets are the buckets stored as a whole in snapshot deltas,
+// since their changes are not versioned by revision like the changes of keys.
+var DeltaFullBuckets = []backend.Bucket{Lease, Quota, Auth, AuthUsers, AuthRoles, AuthTenants}
+
 type bucket struct {
 	id              backend.BucketID
 	name            []byte
@@ -0,0 +1,93 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/server/v3/storage/backend"
+	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
+)
+
+func TestQuotaBackend(t *testing.T) {
+	tcs := []struct {
+		name  string
+		setup func(qb *quotaBackend)
+		want  []*etcdserverpb.KeyQuota
+	}{
+		{
+			name:  "Empty by default",
+			setup: func(qb *quotaBackend) {},
+			want:  nil,
+		},
+		{
+			name: "Returns data put before",
+			setup: func(qb *quotaBackend) {
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 10})
+			},
+			want: []*etcdserverpb.KeyQuota{
+				{Prefix: []byte("/a/"), MaxBytes: 10},
+			},
+		},
+		{
+			name: "Overwrites quota with the same prefix",
+			setup: func(qb *quotaBackend) {
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 10})
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/a/"), MaxKeys: 5})
+			},
+			want: []*etcdserverpb.KeyQuota{
+				{Prefix: []byte("/a/"), MaxKeys: 5},
+			},
+		},
+		{
+			name: "Skips deleted",
+			setup: func(qb *quotaBackend) {
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/b/"), MaxBytes: 20})
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/a/"), MaxBytes: 10})
+				qb.MustPutQuota(&etcdserverpb.KeyQuota{Prefix: []byte("/c/"), MaxKeys: 1})
+				qb.MustDeleteQuota([]byte("/b/"))
+			},
+			want: []*etcdserverpb.KeyQuota{
+				{Prefix: []byte("/a/"), MaxBytes: 10},
+				{Prefix: []byte("/c/"), MaxKeys: 1},
+			},
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			lg := zaptest.NewLogger(t)
+			be, tmpPath := betesting.NewTmpBackend(t, time.Microsecond, 10)
+			qb := NewQuotaBackend(lg, be)
+			qb.CreateQuotaBucket()
+			tc.setup(qb)
+			qb.ForceCommit()
+			be.Close()
+
+			be2 := backend.NewDefaultBackend(lg, tmpPath)
+			defer be2.Close()
+			quotas, err := NewQuotaBackend(lg, be2).GetAllQuotas()
+			require.NoError(t, err)
+
+			assert.Equal(t, tc.want, quotas)
+		})
+	}
+}
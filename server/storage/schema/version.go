@@ -17,7 +17,6 @@ package schema
 import (
 	"github.com/coreos/go-semver/semver"
 
-	"go.etcd.io/bbolt"
 	"go.etcd.io/etcd/server/v3/storage/backend"
 )
 
@@ -43,10 +42,14 @@ func UnsafeReadStorageVersion(tx backend.ReadTx) *semver.Version {
 	return v
 }
 
-// ReadStorageVersionFromSnapshot loads storage version from given bbolt transaction.
+// ReadStorageVersionFromSnapshot loads storage version from given storage engine transaction.
 // Populated since v3.6
-func ReadStorageVersionFromSnapshot(tx *bbolt.Tx) *semver.Version {
-	v := tx.Bucket(Meta.Name()).Get(MetaStorageVersionName)
+func ReadStorageVersionFromSnapshot(tx backend.EngineTx) *semver.Version {
+	b := tx.Bucket(Meta.Name())
+	if b == nil {
+		return nil
+	}
+	v := b.Get(MetaStorageVersionName)
 	version, err := semver.NewVersion(string(v))
 	if err != nil {
 		return nil
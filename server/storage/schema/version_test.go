@@ -22,8 +22,6 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap/zaptest"
 
-	"go.etcd.io/bbolt"
-
 	"go.etcd.io/etcd/server/v3/storage/backend"
 	betesting "go.etcd.io/etcd/server/v3/storage/backend/testing"
 )
@@ -115,19 +113,18 @@ func TestVersionSnapshot(t *testing.T) {
 			tx.Unlock()
 			be.ForceCommit()
 			be.Close()
-			db, err := bbolt.Open(tmpPath, 0400, &bbolt.Options{ReadOnly: true})
+			db, err := backend.OpenEngine(backend.BackendConfig{Path: tmpPath, ReadOnly: true})
 			if err != nil {
 				t.Fatal(err)
 			}
 			defer db.Close()
-
-			var ver *semver.Version
-			if err = db.View(func(tx *bbolt.Tx) error {
-				ver = ReadStorageVersionFromSnapshot(tx)
-				return nil
-			}); err != nil {
+			etx, err := db.Begin(false)
+			if err != nil {
 				t.Fatal(err)
 			}
+			defer etx.Rollback()
+
+			ver := ReadStorageVersionFromSnapshot(etx)
 
 			assert.Equal(t, tc.expectVersion, ver.String())
 
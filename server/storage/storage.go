@@ -40,6 +40,8 @@ type Storage interface {
 	Sync() error
 	// MinimalEtcdVersion returns minimal etcd storage able to interpret WAL log.
 	MinimalEtcdVersion() *semver.Version
+	// Scrub validates the finished WAL segments, see wal.WAL.Scrub.
+	Scrub(throttle func(n int) error) error
 }
 
 type storage struct {
@@ -106,6 +108,13 @@ func (st *storage) Sync() error {
 	return st.w.Sync()
 }
 
+func (st *storage) Scrub(throttle func(n int) error) error {
+	st.mux.RLock()
+	w := st.w
+	st.mux.RUnlock()
+	return w.Scrub(throttle)
+}
+
 func (st *storage) MinimalEtcdVersion() *semver.Version {
 	st.mux.Lock()
 	defer st.mux.Unlock()
@@ -124,11 +133,10 @@ func (st *storage) MinimalEtcdVersion() *semver.Version {
 	if err != nil {
 		panic(err)
 	}
-	_, _, ents, err := w.ReadAll()
+	wv, err := wal.ReadWALVersion(w)
 	if err != nil {
 		panic(err)
 	}
-	v := wal.MinimalEtcdVersion(ents)
 	st.w = w
-	return v
+	return wv.MinimalEtcdVersion()
 }
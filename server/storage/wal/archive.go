@@ -0,0 +1,368 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+const (
+	// archiveMarkInterval is the resolution of the times recorded for the
+	// entries of archived segments.
+	archiveMarkInterval = time.Second
+	// archivePendingWarn is the number of finished segments waiting to be
+	// archived above which the archiver warns that it is falling behind.
+	archivePendingWarn = 16
+	// archiveRetryInterval is the time to wait before retrying to archive a
+	// segment which failed to be archived.
+	archiveRetryInterval = time.Second
+
+	archiveMarksExt = ".marks"
+)
+
+var ErrArchiveGap = errors.New("wal: archive is missing segments")
+
+// ArchiveMark records that the entries up to Index were committed by Time.
+type ArchiveMark struct {
+	Index uint64
+	Time  time.Time
+}
+
+// archiver copies finished WAL segments to an archive directory, for a
+// point-in-time recovery from a snapshot and the archived segments. Each
+// segment is archived along with a marks file recording when its entries
+// were committed.
+type archiver struct {
+	lg  *zap.Logger
+	dir string
+
+	mu sync.Mutex
+	// pending holds the segments waiting to be archived, in order. Segments
+	// are never dropped, so the archive has no gaps while the segments can
+	// be read.
+	pending []archiveSegment
+	closed  bool
+	notifyc chan struct{}
+	donec   chan struct{}
+
+	// marks of the tail segment, protected by the mutex of the WAL
+	marks []ArchiveMark
+}
+
+type archiveSegment struct {
+	// f is opened before the segment is handed to the archiver, so the
+	// segment can still be archived if it is purged in the meantime.
+	f     *os.File
+	name  string
+	marks []ArchiveMark
+}
+
+func newArchiver(lg *zap.Logger, dir string) (*archiver, error) {
+	if err := fileutil.TouchDirAll(lg, dir); err != nil {
+		return nil, err
+	}
+	a := &archiver{
+		lg:      lg,
+		dir:     dir,
+		notifyc: make(chan struct{}, 1),
+		donec:   make(chan struct{}),
+	}
+	go a.run()
+	return a, nil
+}
+
+// mark records that the entries up to index were committed at now.
+func (a *archiver) mark(index uint64, now time.Time) {
+	if n := len(a.marks); n > 0 && (index <= a.marks[n-1].Index || now.Sub(a.marks[n-1].Time) < archiveMarkInterval) {
+		return
+	}
+	a.marks = append(a.marks, ArchiveMark{Index: index, Time: now})
+}
+
+// add queues the finished segment at path for archiving.
+func (a *archiver) add(path string) {
+	marks := a.marks
+	a.marks = nil
+	f, err := os.Open(path)
+	if err != nil {
+		walArchiveFailures.Inc()
+		a.lg.Warn("failed to open WAL segment for archiving", zap.String("path", path), zap.Error(err))
+		return
+	}
+	a.mu.Lock()
+	a.pending = append(a.pending, archiveSegment{f: f, name: filepath.Base(path), marks: marks})
+	n := len(a.pending)
+	a.mu.Unlock()
+	walArchivePendingSegments.Set(float64(n))
+	if n > archivePendingWarn {
+		a.lg.Warn("WAL archiving is falling behind", zap.Int("pending-segments", n))
+	}
+	a.notify()
+}
+
+func (a *archiver) notify() {
+	select {
+	case a.notifyc <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the first pending segment, waiting for one to be added. It
+// returns false once the archiver is closed and has no pending segment.
+func (a *archiver) next() (archiveSegment, bool) {
+	for {
+		a.mu.Lock()
+		if len(a.pending) != 0 {
+			seg := a.pending[0]
+			a.mu.Unlock()
+			return seg, true
+		}
+		closed := a.closed
+		a.mu.Unlock()
+		if closed {
+			return archiveSegment{}, false
+		}
+		<-a.notifyc
+	}
+}
+
+// done removes the first pending segment.
+func (a *archiver) done() {
+	a.mu.Lock()
+	a.pending[0].f.Close()
+	a.pending[0] = archiveSegment{}
+	a.pending = a.pending[1:]
+	n := len(a.pending)
+	a.mu.Unlock()
+	walArchivePendingSegments.Set(float64(n))
+}
+
+func (a *archiver) isClosed() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.closed
+}
+
+func (a *archiver) run() {
+	defer close(a.donec)
+	for {
+		seg, ok := a.next()
+		if !ok {
+			return
+		}
+		start := time.Now()
+		if err := a.archive(seg); err != nil {
+			walArchiveFailures.Inc()
+			if !a.isClosed() {
+				// later segments wait for the failed one, so the archive
+				// has no gaps
+				a.lg.Warn("failed to archive WAL segment; retrying", zap.String("segment", seg.name), zap.Error(err))
+				time.Sleep(archiveRetryInterval)
+				continue
+			}
+			a.lg.Warn("failed to archive WAL segment", zap.String("segment", seg.name), zap.Error(err))
+			a.done()
+			continue
+		}
+		a.done()
+		walArchivedSegments.Inc()
+		a.lg.Info("archived WAL segment",
+			zap.String("segment", seg.name),
+			zap.String("archive-dir", a.dir),
+			zap.Duration("took", time.Since(start)),
+		)
+	}
+}
+
+// archive writes the marks and then the segment, so archived segments
+// always have their marks.
+func (a *archiver) archive(seg archiveSegment) error {
+	if _, err := seg.f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var sb strings.Builder
+	for _, m := range seg.marks {
+		fmt.Fprintf(&sb, "%d %s\n", m.Index, m.Time.UTC().Format(time.RFC3339Nano))
+	}
+	if err := a.writeFile(seg.name+archiveMarksExt, strings.NewReader(sb.String())); err != nil {
+		return err
+	}
+	return a.writeFile(seg.name, seg.f)
+}
+
+// writeFile atomically writes the content of r to the file name of the
+// archive directory.
+func (a *archiver) writeFile(name string, r io.Reader) error {
+	path := filepath.Join(a.dir, name)
+	tmp := path + ".tmp"
+	f, err := os.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, fileutil.PrivateFileMode)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(f, r); err == nil {
+		err = fileutil.Fsync(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err = os.Rename(tmp, path); err != nil {
+		return err
+	}
+	dir, err := fileutil.OpenDir(a.dir)
+	if err != nil {
+		return err
+	}
+	defer dir.Close()
+	return fileutil.Fsync(dir)
+}
+
+// close waits for the pending segments to be archived. Segments failing to
+// be archived are no longer retried.
+func (a *archiver) close() {
+	a.mu.Lock()
+	a.closed = true
+	a.mu.Unlock()
+	a.notify()
+	<-a.donec
+}
+
+// ReadArchive reads the WAL segments archived in dir and returns the
+// committed entries after the given index, along with the marks of the
+// archived segments ordered by index. It returns ErrArchiveGap if the
+// archive does not have all the segments from the one holding the entry
+// after index on. The entries are decrypted with c, if they are encrypted.
+func ReadArchive(lg *zap.Logger, dir string, index uint64, c encryption.Cipher) ([]raftpb.Entry, []ArchiveMark, error) {
+	names, err := fileutil.ReadDir(dir, fileutil.WithExt(".wal"))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(names) == 0 {
+		return nil, nil, ErrFileNotFound
+	}
+	nameIndex, ok := searchIndex(lg, names, index+1)
+	if !ok || !isValidSeq(lg, names[nameIndex:]) {
+		return nil, nil, ErrArchiveGap
+	}
+	names = names[nameIndex:]
+
+	marks, err := readArchiveMarks(dir, names)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rs, _, closer, err := openWALFiles(lg, dir, names, 0, false)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer closer()
+
+	var (
+		ents  []raftpb.Entry
+		state raftpb.HardState
+		rec   walpb.Record
+	)
+	decoder := NewDecoder(rs...)
+	for err = decoder.Decode(&rec); err == nil; err = decoder.Decode(&rec) {
+		switch rec.Type {
+		case EntryType, CompressedEntryType:
+			e, derr := DecodeEntryRecord(c, &rec)
+			if derr != nil {
+				return nil, nil, derr
+			}
+			if e.Index <= index {
+				continue
+			}
+			up := e.Index - index - 1
+			if up > uint64(len(ents)) {
+				return nil, nil, ErrArchiveGap
+			}
+			// later entries override uncommitted entries of the same index
+			ents = append(ents[:up], e)
+		case StateType:
+			state = MustUnmarshalState(rec.Data)
+		case CrcType:
+			crc := decoder.LastCRC()
+			if crc != 0 && rec.Validate(crc) != nil {
+				return nil, nil, ErrCRCMismatch
+			}
+			decoder.UpdateCRC(rec.Crc)
+		case MetadataType, SnapshotType:
+		default:
+			return nil, nil, fmt.Errorf("unexpected block type %d", rec.Type)
+		}
+	}
+	if err != io.EOF && err != io.ErrUnexpectedEOF {
+		return nil, nil, err
+	}
+
+	// only committed entries are final
+	for len(ents) > 0 && ents[len(ents)-1].Index > state.Commit {
+		ents = ents[:len(ents)-1]
+	}
+	return ents, marks, nil
+}
+
+func readArchiveMarks(dir string, names []string) ([]ArchiveMark, error) {
+	var marks []ArchiveMark
+	for _, name := range names {
+		f, err := os.Open(filepath.Join(dir, name+archiveMarksExt))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			var (
+				m  ArchiveMark
+				ts string
+			)
+			if _, err = fmt.Sscanf(s.Text(), "%d %s", &m.Index, &ts); err == nil {
+				m.Time, err = time.Parse(time.RFC3339Nano, ts)
+			}
+			if err != nil {
+				f.Close()
+				return nil, fmt.Errorf("invalid mark %q in %s: %v", s.Text(), name+archiveMarksExt, err)
+			}
+			marks = append(marks, m)
+		}
+		err = s.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return marks, nil
+}
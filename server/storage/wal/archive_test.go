@@ -0,0 +1,168 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zaptest"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+// createArchivedWAL writes three finished segments and an unfinished one to
+// a WAL archived to the returned directory. The finished segments hold the
+// entries 1-3, 4-6 and 7-8, the last of which is not committed.
+func createArchivedWAL(t *testing.T) string {
+	lg := zaptest.NewLogger(t)
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	w, err := Create(lg, t.TempDir(), nil)
+	require.NoError(t, err)
+	require.NoError(t, w.SetArchiveDir(archiveDir))
+
+	segments := []struct {
+		first, last, commit uint64
+	}{
+		{1, 3, 3},
+		{4, 6, 6},
+		{7, 8, 7},
+	}
+	for _, seg := range segments {
+		var ents []raftpb.Entry
+		for i := seg.first; i <= seg.last; i++ {
+			ents = append(ents, raftpb.Entry{Index: i, Term: 1, Data: []byte("data")})
+		}
+		require.NoError(t, w.Save(raftpb.HardState{Term: 1, Commit: seg.commit}, ents))
+		require.NoError(t, w.cut())
+	}
+	require.NoError(t, w.Save(raftpb.HardState{Term: 1, Commit: 9}, []raftpb.Entry{{Index: 9, Term: 1}}))
+	require.NoError(t, w.Close())
+	return archiveDir
+}
+
+func TestArchive(t *testing.T) {
+	archiveDir := createArchivedWAL(t)
+
+	names, err := readWALNames(zaptest.NewLogger(t), archiveDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{walName(0, 0), walName(1, 4), walName(2, 7)}, names)
+	for _, name := range names {
+		_, err = os.Stat(filepath.Join(archiveDir, name+archiveMarksExt))
+		assert.NoError(t, err)
+	}
+
+	ents, marks, err := ReadArchive(zaptest.NewLogger(t), archiveDir, 0, nil)
+	require.NoError(t, err)
+	require.Len(t, ents, 7)
+	for i, e := range ents {
+		assert.Equal(t, uint64(i+1), e.Index)
+	}
+	var markIndexes []uint64
+	for _, m := range marks {
+		markIndexes = append(markIndexes, m.Index)
+	}
+	assert.Equal(t, []uint64{3, 6, 7}, markIndexes)
+
+	ents, _, err = ReadArchive(zaptest.NewLogger(t), archiveDir, 4, nil)
+	require.NoError(t, err)
+	require.Len(t, ents, 3)
+	assert.Equal(t, uint64(5), ents[0].Index)
+}
+
+func TestReadArchiveGap(t *testing.T) {
+	archiveDir := createArchivedWAL(t)
+
+	require.NoError(t, os.Remove(filepath.Join(archiveDir, walName(0, 0))))
+	_, _, err := ReadArchive(zaptest.NewLogger(t), archiveDir, 0, nil)
+	assert.Equal(t, ErrArchiveGap, err)
+
+	// the entries after the missing segment can still be read
+	ents, _, err := ReadArchive(zaptest.NewLogger(t), archiveDir, 3, nil)
+	require.NoError(t, err)
+	assert.Len(t, ents, 4)
+
+	require.NoError(t, os.Remove(filepath.Join(archiveDir, walName(1, 4))))
+	_, _, err = ReadArchive(zaptest.NewLogger(t), archiveDir, 3, nil)
+	assert.Equal(t, ErrArchiveGap, err)
+}
+
+func TestArchiverPending(t *testing.T) {
+	segDir := t.TempDir()
+	a, err := newArchiver(zaptest.NewLogger(t), filepath.Join(t.TempDir(), "archive"))
+	require.NoError(t, err)
+
+	// more segments than the archiver warns about are all archived
+	var want []string
+	for i := 0; i < 2*archivePendingWarn; i++ {
+		name := walName(uint64(i), uint64(i))
+		require.NoError(t, os.WriteFile(filepath.Join(segDir, name), []byte("segment"), 0600))
+		a.add(filepath.Join(segDir, name))
+		want = append(want, name)
+	}
+	a.close()
+
+	names, err := fileutil.ReadDir(a.dir, fileutil.WithExt(".wal"))
+	require.NoError(t, err)
+	assert.Equal(t, want, names)
+}
+
+func TestArchiverRetry(t *testing.T) {
+	segDir := t.TempDir()
+	archiveDir := filepath.Join(t.TempDir(), "archive")
+	a, err := newArchiver(zaptest.NewLogger(t), archiveDir)
+	require.NoError(t, err)
+
+	// archiving fails while the archive directory is a file
+	require.NoError(t, os.Remove(archiveDir))
+	require.NoError(t, os.WriteFile(archiveDir, nil, 0600))
+	failures := testutil.ToFloat64(walArchiveFailures)
+	name := walName(0, 0)
+	require.NoError(t, os.WriteFile(filepath.Join(segDir, name), []byte("segment"), 0600))
+	a.add(filepath.Join(segDir, name))
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(walArchiveFailures) > failures
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// the failed segment is archived once the directory is back
+	require.NoError(t, os.Remove(archiveDir))
+	require.NoError(t, os.Mkdir(archiveDir, 0700))
+	a.close()
+
+	data, err := os.ReadFile(filepath.Join(archiveDir, name))
+	require.NoError(t, err)
+	assert.Equal(t, "segment", string(data))
+}
+
+func TestArchiverMark(t *testing.T) {
+	a := &archiver{}
+	now := time.Now()
+	a.mark(1, now)
+	// marks are recorded at most once per interval
+	a.mark(2, now.Add(archiveMarkInterval/2))
+	a.mark(3, now.Add(archiveMarkInterval))
+	// and only when the commit index moves
+	a.mark(3, now.Add(2*archiveMarkInterval))
+	assert.Equal(t, []ArchiveMark{
+		{Index: 1, Time: now},
+		{Index: 3, Time: now.Add(archiveMarkInterval)},
+	}, a.marks)
+}
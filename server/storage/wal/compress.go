@@ -0,0 +1,159 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"bytes"
+	"compress/flate"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"go.etcd.io/etcd/server/v3/storage/encryption"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+	"go.etcd.io/raft/v3/raftpb"
+)
+
+const (
+	// minCompressBytes is the size under which entries are not worth
+	// compressing.
+	minCompressBytes = 128
+
+	// entryDecodeBatch is the number of entry records ReadAll decodes at
+	// once.
+	entryDecodeBatch = 4096
+	// minParallelDecode is the number of entry records under which a batch
+	// is decoded by a single goroutine.
+	minParallelDecode = 64
+)
+
+var flateReaderPool = sync.Pool{
+	New: func() any { return flate.NewReader(bytes.NewReader(nil)) },
+}
+
+// compressor compresses the entries saved to a WAL. It reuses its buffer and
+// writer, so it is not safe for concurrent use.
+type compressor struct {
+	buf bytes.Buffer
+	fw  *flate.Writer
+}
+
+func newCompressor() *compressor {
+	c := &compressor{}
+	// flate.NewWriter only fails on an invalid level
+	c.fw, _ = flate.NewWriter(&c.buf, flate.BestSpeed)
+	return c
+}
+
+// compress returns the compression of data, or nil if it is not smaller than
+// data.
+func (c *compressor) compress(data []byte) ([]byte, error) {
+	if len(data) < minCompressBytes {
+		return nil, nil
+	}
+	c.buf.Reset()
+	c.fw.Reset(&c.buf)
+	if _, err := c.fw.Write(data); err != nil {
+		return nil, err
+	}
+	if err := c.fw.Close(); err != nil {
+		return nil, err
+	}
+	if c.buf.Len() >= len(data) {
+		return nil, nil
+	}
+	return append([]byte(nil), c.buf.Bytes()...), nil
+}
+
+func decompress(data []byte) ([]byte, error) {
+	fr := flateReaderPool.Get().(io.ReadCloser)
+	defer flateReaderPool.Put(fr)
+	if err := fr.(flate.Resetter).Reset(bytes.NewReader(data), nil); err != nil {
+		return nil, err
+	}
+	b, err := io.ReadAll(fr)
+	if err != nil {
+		return nil, fmt.Errorf("wal: cannot decompress entry: %w", err)
+	}
+	return b, nil
+}
+
+// DecodeEntryRecord returns the entry of an EntryType or CompressedEntryType
+// record, decrypting its data with c.
+func DecodeEntryRecord(c encryption.Cipher, rec *walpb.Record) (raftpb.Entry, error) {
+	data, err := encryption.Decrypt(c, rec.Data)
+	if err != nil {
+		return raftpb.Entry{}, err
+	}
+	if rec.Type == CompressedEntryType {
+		if data, err = decompress(data); err != nil {
+			return raftpb.Entry{}, err
+		}
+	}
+	return MustUnmarshalEntry(data), nil
+}
+
+// decodeEntryRecords returns the entries of recs, in order. Decrypting,
+// decompressing and unmarshaling the entries dominates the replay of a WAL,
+// so large batches are split across GOMAXPROCS goroutines.
+func decodeEntryRecords(c encryption.Cipher, recs []walpb.Record) ([]raftpb.Entry, error) {
+	ents := make([]raftpb.Entry, len(recs))
+	decode := func(lo, hi int) error {
+		for i := lo; i < hi; i++ {
+			e, err := DecodeEntryRecord(c, &recs[i])
+			if err != nil {
+				return err
+			}
+			ents[i] = e
+		}
+		return nil
+	}
+
+	workers := runtime.GOMAXPROCS(0)
+	if len(recs) < minParallelDecode || workers == 1 {
+		if err := decode(0, len(recs)); err != nil {
+			return nil, err
+		}
+		return ents, nil
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, workers)
+		size = (len(recs) + workers - 1) / workers
+	)
+	for i := 0; i < workers; i++ {
+		lo, hi := i*size, (i+1)*size
+		if lo >= len(recs) {
+			break
+		}
+		if hi > len(recs) {
+			hi = len(recs)
+		}
+		wg.Add(1)
+		go func(i, lo, hi int) {
+			defer wg.Done()
+			errs[i] = decode(lo, hi)
+		}(i, lo, hi)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ents, nil
+}
@@ -34,9 +34,33 @@ var (
 		Name:      "wal_write_bytes_total",
 		Help:      "Total number of bytes written in WAL.",
 	})
+
+	walArchivedSegments = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "wal_archived_segments_total",
+		Help:      "Total number of WAL segments copied to the WAL archive.",
+	})
+
+	walArchiveFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "wal_archive_failures_total",
+		Help:      "Total number of WAL segments that failed to be archived.",
+	})
+
+	walArchivePendingSegments = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "etcd",
+		Subsystem: "disk",
+		Name:      "wal_archive_pending_segments",
+		Help:      "The number of finished WAL segments waiting to be archived.",
+	})
 )
 
 func init() {
 	prometheus.MustRegister(walFsyncSec)
 	prometheus.MustRegister(walWriteBytes)
+	prometheus.MustRegister(walArchivedSegments)
+	prometheus.MustRegister(walArchiveFailures)
+	prometheus.MustRegister(walArchivePendingSegments)
 }
@@ -0,0 +1,97 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package wal
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.etcd.io/etcd/client/pkg/v3/fileutil"
+	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
+)
+
+// Scrub re-reads the finished segments of the WAL, the ones before the
+// segment being written, and validates the CRCs of their records. Each
+// segment starts with the CRC of the previous ones, so segments are
+// validated independently. Segments released and purged meanwhile are
+// skipped. throttle is called with the number of bytes read, so that the
+// caller can pace the scrub or abort it by returning an error.
+func (w *WAL) Scrub(throttle func(n int) error) error {
+	w.mu.Lock()
+	var paths []string
+	for i, l := range w.locks {
+		if l != nil && i < len(w.locks)-1 {
+			// the first segment is named after the temporary directory it
+			// was created in
+			paths = append(paths, filepath.Join(w.dir, filepath.Base(l.Name())))
+		}
+	}
+	w.mu.Unlock()
+
+	for _, p := range paths {
+		if err := scrubSegment(p, throttle); err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			}
+			return fmt.Errorf("wal: segment %s: %w", filepath.Base(p), err)
+		}
+	}
+	return nil
+}
+
+func scrubSegment(p string, throttle func(n int) error) error {
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	rec := &walpb.Record{}
+	decoder := NewDecoder(&throttledFileReader{FileReader: fileutil.NewFileReader(f), throttle: throttle})
+	for err = decoder.Decode(rec); err == nil; err = decoder.Decode(rec) {
+		if rec.Type != CrcType {
+			continue
+		}
+		crc := decoder.LastCRC()
+		if crc != 0 && rec.Validate(crc) != nil {
+			return ErrCRCMismatch
+		}
+		decoder.UpdateCRC(rec.Crc)
+	}
+	// finished segments are synced and truncated, so they end with a
+	// complete record
+	if err != io.EOF {
+		return err
+	}
+	return nil
+}
+
+type throttledFileReader struct {
+	fileutil.FileReader
+	throttle func(n int) error
+}
+
+func (r *throttledFileReader) Read(p []byte) (int, error) {
+	n, err := r.FileReader.Read(p)
+	if n > 0 && r.throttle != nil {
+		if terr := r.throttle(n); terr != nil {
+			return n, terr
+		}
+	}
+	return n, err
+}
@@ -37,16 +37,22 @@ func ReadWALVersion(w *WAL) (*walVersion, error) {
 	if err != nil {
 		return nil, err
 	}
-	return &walVersion{entries: ents}, nil
+	return &walVersion{entries: ents, compressed: w.compressed}, nil
 }
 
 type walVersion struct {
-	entries []raftpb.Entry
+	entries    []raftpb.Entry
+	compressed bool
 }
 
 // MinimalEtcdVersion returns minimal etcd able to interpret entries from  WAL log,
+// including the format of their records.
 func (w *walVersion) MinimalEtcdVersion() *semver.Version {
-	return MinimalEtcdVersion(w.entries)
+	ver := MinimalEtcdVersion(w.entries)
+	if w.compressed {
+		ver = maxVersion(ver, &version.V3_6)
+	}
+	return ver
 }
 
 // MinimalEtcdVersion returns minimal etcd able to interpret entries from  WAL log,
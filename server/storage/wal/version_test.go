@@ -102,6 +102,38 @@ func TestEtcdVersionFromEntry(t *testing.T) {
 	}
 }
 
+func TestWALVersionCompressed(t *testing.T) {
+	clusterVersionV3_7Data := pbutil.MustMarshal(&etcdserverpb.InternalRaftRequest{ClusterVersionSet: &membershippb.ClusterVersionSetRequest{Ver: "3.7.0"}})
+	tcs := []struct {
+		name       string
+		entries    []raftpb.Entry
+		compressed bool
+		expect     *semver.Version
+	}{
+		{
+			name:   "Empty WAL",
+			expect: nil,
+		},
+		{
+			name:       "Compressed records require v3.6",
+			compressed: true,
+			expect:     &version.V3_6,
+		},
+		{
+			name:       "Newer entries take precedence",
+			entries:    []raftpb.Entry{{Term: 1, Index: 1, Type: raftpb.EntryNormal, Data: clusterVersionV3_7Data}},
+			compressed: true,
+			expect:     &semver.Version{Major: 3, Minor: 7},
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			w := &walVersion{entries: tc.entries, compressed: tc.compressed}
+			assert.Equal(t, tc.expect, w.MinimalEtcdVersion())
+		})
+	}
+}
+
 func TestEtcdVersionFromMessage(t *testing.T) {
 	tcs := []struct {
 		name   string
@@ -138,6 +170,41 @@ func TestEtcdVersionFromMessage(t *testing.T) {
 			input:  &etcdserverpb.InternalRaftRequest{DowngradeInfoSet: &membershippb.DowngradeInfoSetRequest{}},
 			expect: &version.V3_5,
 		},
+		{
+			name:   "Setting a QuotaSetRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{QuotaSet: &etcdserverpb.QuotaSetRequest{}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting an AuthTenantAddRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{AuthTenantAdd: &etcdserverpb.AuthTenantAddRequest{}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting a tenant on an AuthUserAddRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{AuthUserAdd: &etcdserverpb.AuthUserAddRequest{Tenant: "t1"}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Revoking a deny rule implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{AuthRoleRevokePermission: &etcdserverpb.AuthRoleRevokePermissionRequest{Deny: true}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting a ttl on a PutRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{Put: &etcdserverpb.PutRequest{Ttl: 10}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting filters on a RangeRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{Range: &etcdserverpb.RangeRequest{Filters: []*etcdserverpb.RangeFilter{{}}}},
+			expect: &version.V3_6,
+		},
+		{
+			name:   "Setting a continue token on a RangeRequest implies v3.6",
+			input:  &etcdserverpb.InternalRaftRequest{Range: &etcdserverpb.RangeRequest{ContinueToken: "token"}},
+			expect: &version.V3_6,
+		},
 		{
 			name:   "Enum CompareResult set to EQUAL implies v3.0",
 			input:  &etcdserverpb.Compare{Result: etcdserverpb.Compare_EQUAL},
@@ -28,6 +28,7 @@ import (
 
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.etcd.io/raft/v3"
 	"go.etcd.io/raft/v3/raftpb"
@@ -41,6 +42,10 @@ const (
 	StateType
 	CrcType
 	SnapshotType
+	// CompressedEntryType records hold entries compressed with DEFLATE.
+	// etcd older than v3.6 cannot read them, so the WAL version reports
+	// v3.6 as long as the WAL holds any.
+	CompressedEntryType
 
 	// warnSyncDuration is the amount of time allotted to an fsync before
 	// logging a warning
@@ -92,6 +97,13 @@ type WAL struct {
 
 	locks []*fileutil.LockedFile // the locked files the WAL holds (the name is increasing)
 	fp    *filePipeline
+
+	archiver *archiver // copies finished segments to the archive directory, if set
+
+	cipher encryption.Cipher // encrypts the entries, if set
+
+	compressor *compressor // compresses the entries, if set
+	compressed bool        // whether ReadAll read compressed entries
 }
 
 // Create creates a WAL ready for appending records. The given metadata is
@@ -234,17 +246,74 @@ func Create(lg *zap.Logger, dirpath string, metadata []byte) (*WAL, error) {
 }
 
 func (w *WAL) Reopen(lg *zap.Logger, snap walpb.Snapshot) (*WAL, error) {
+	var archiveDir string
+	if w.archiver != nil {
+		archiveDir = w.archiver.dir
+	}
 	err := w.Close()
 	if err != nil {
 		lg.Panic("failed to close WAL during reopen", zap.Error(err))
 	}
-	return Open(lg, w.dir, snap)
+	nw, err := Open(lg, w.dir, snap)
+	if err != nil {
+		return nil, err
+	}
+	nw.cipher = w.cipher
+	nw.compressor = w.compressor
+	if archiveDir == "" {
+		return nw, nil
+	}
+	if err = nw.SetArchiveDir(archiveDir); err != nil {
+		nw.Close()
+		return nil, err
+	}
+	return nw, nil
 }
 
 func (w *WAL) SetUnsafeNoFsync() {
 	w.unsafeNoSync = true
 }
 
+// SetArchiveDir enables archiving of the WAL. Segments are copied to dir
+// once they are finished, along with the times their entries were
+// committed, so that a snapshot can be rolled forward to a point in time by
+// replaying the archived entries. The segment being written is not archived
+// until it is finished.
+func (w *WAL) SetArchiveDir(dir string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.archiver != nil {
+		return errors.New("wal: archive directory is already set")
+	}
+	a, err := newArchiver(w.lg, dir)
+	if err != nil {
+		return err
+	}
+	w.archiver = a
+	return nil
+}
+
+// SetCipher encrypts the data of the entries saved to the WAL with c, and
+// decrypts the entries read with it. The entries saved before are read as
+// is, if they were not encrypted. It must be set before ReadAll.
+func (w *WAL) SetCipher(c encryption.Cipher) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cipher = c
+}
+
+// SetCompression compresses the entries saved to the WAL if enabled, unless
+// they are too small to benefit from it. Compressed entries are always read,
+// whether or not compression is enabled.
+func (w *WAL) SetCompression(enabled bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.compressor = nil
+	if enabled {
+		w.compressor = newCompressor()
+	}
+}
+
 func (w *WAL) cleanupWAL(lg *zap.Logger) {
 	var err error
 	if err = w.Close(); err != nil {
@@ -452,22 +521,44 @@ func (w *WAL) ReadAll() (metadata []byte, state raftpb.HardState, ents []raftpb.
 	decoder := w.decoder
 
 	var match bool
-	for err = decoder.Decode(rec); err == nil; err = decoder.Decode(rec) {
-		switch rec.Type {
-		case EntryType:
-			e := MustUnmarshalEntry(rec.Data)
+	// entry records are decoded in batches, see decodeEntryRecords
+	var entRecs []walpb.Record
+	appendEntries := func() error {
+		decoded, err := decodeEntryRecords(w.cipher, entRecs)
+		entRecs = entRecs[:0]
+		if err != nil {
+			return err
+		}
+		for _, e := range decoded {
 			// 0 <= e.Index-w.start.Index - 1 < len(ents)
 			if e.Index > w.start.Index {
 				// prevent "panic: runtime error: slice bounds out of range [:13038096702221461992] with capacity 0"
 				up := e.Index - w.start.Index - 1
 				if up > uint64(len(ents)) {
 					// return error before append call causes runtime panic
-					return nil, state, nil, ErrSliceOutOfRange
+					return ErrSliceOutOfRange
 				}
 				// The line below is potentially overriding some 'uncommitted' entries.
 				ents = append(ents[:up], e)
 			}
 			w.enti = e.Index
+		}
+		return nil
+	}
+	for err = decoder.Decode(rec); err == nil; err = decoder.Decode(rec) {
+		switch rec.Type {
+		case EntryType, CompressedEntryType:
+			if rec.Type == CompressedEntryType {
+				w.compressed = true
+			}
+			// the decoder allocates the data of each record
+			entRecs = append(entRecs, *rec)
+			if len(entRecs) == entryDecodeBatch {
+				if err = appendEntries(); err != nil {
+					state.Reset()
+					return nil, state, nil, err
+				}
+			}
 
 		case StateType:
 			state = MustUnmarshalState(rec.Data)
@@ -505,6 +596,10 @@ func (w *WAL) ReadAll() (metadata []byte, state raftpb.HardState, ents []raftpb.
 			return nil, state, nil, fmt.Errorf("unexpected block type %d", rec.Type)
 		}
 	}
+	if derr := appendEntries(); derr != nil {
+		state.Reset()
+		return nil, state, nil, derr
+	}
 
 	switch w.tail() {
 	case nil:
@@ -689,7 +784,7 @@ func Verify(lg *zap.Logger, walDir string, snap walpb.Snapshot) (*raftpb.HardSta
 			}
 		// We ignore all entry and state type records as these
 		// are not necessary for validating the WAL contents
-		case EntryType:
+		case EntryType, CompressedEntryType:
 		case StateType:
 			pbutil.MustUnmarshal(&state, rec.Data)
 		default:
@@ -728,6 +823,12 @@ func (w *WAL) cut() error {
 		return err
 	}
 
+	if w.archiver != nil {
+		// the first segment keeps the name it was created with in the
+		// temporary directory of Create
+		w.archiver.add(filepath.Join(w.dir, filepath.Base(w.tail().Name())))
+	}
+
 	fpath := filepath.Join(w.dir, walName(w.seq()+1, w.enti+1))
 
 	// create a temp wal file with name sequence + 1, or truncate the existing one
@@ -885,6 +986,11 @@ func (w *WAL) Close() error {
 		w.fp = nil
 	}
 
+	if w.archiver != nil {
+		w.archiver.close()
+		w.archiver = nil
+	}
+
 	if w.tail() != nil {
 		if err := w.sync(); err != nil {
 			return err
@@ -905,7 +1011,21 @@ func (w *WAL) Close() error {
 func (w *WAL) saveEntry(e *raftpb.Entry) error {
 	// TODO: add MustMarshalTo to reduce one allocation.
 	b := pbutil.MustMarshal(e)
-	rec := &walpb.Record{Type: EntryType, Data: b}
+	rec := &walpb.Record{Type: EntryType}
+	if w.compressor != nil {
+		cb, err := w.compressor.compress(b)
+		if err != nil {
+			return err
+		}
+		if cb != nil {
+			b, rec.Type = cb, CompressedEntryType
+		}
+	}
+	// compress before encrypting, as encrypted data does not compress
+	var err error
+	if rec.Data, err = encryption.Encrypt(w.cipher, b); err != nil {
+		return err
+	}
 	if err := w.encoder.encode(rec); err != nil {
 		return err
 	}
@@ -943,6 +1063,9 @@ func (w *WAL) Save(st raftpb.HardState, ents []raftpb.Entry) error {
 	if err := w.saveState(&st); err != nil {
 		return err
 	}
+	if w.archiver != nil && !raft.IsEmptyHardState(st) {
+		w.archiver.mark(st.Commit, time.Now())
+	}
 
 	curOff, err := w.tail().Seek(0, io.SeekCurrent)
 	if err != nil {
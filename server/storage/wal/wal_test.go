@@ -34,6 +34,7 @@ import (
 
 	"go.etcd.io/etcd/client/pkg/v3/fileutil"
 	"go.etcd.io/etcd/pkg/v3/pbutil"
+	"go.etcd.io/etcd/server/v3/storage/encryption"
 	"go.etcd.io/etcd/server/v3/storage/wal/walpb"
 	"go.etcd.io/raft/v3/raftpb"
 )
@@ -635,6 +636,195 @@ func TestOpenForRead(t *testing.T) {
 	}
 }
 
+type testKeyProvider []encryption.Key
+
+func (p testKeyProvider) Keys() ([]encryption.Key, error) { return p, nil }
+
+func TestEncryptedEntries(t *testing.T) {
+	p := t.TempDir()
+	keyring, err := encryption.NewKeyring(testKeyProvider{{ID: 1, Data: bytes.Repeat([]byte{1}, 32)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetCipher(keyring)
+	ents := []raftpb.Entry{{Index: 1, Term: 1, Data: []byte("secret")}}
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: 1}, ents); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	b, err := os.ReadFile(filepath.Join(p, walName(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(b, []byte("secret")) {
+		t.Fatal("expected the entry data to be encrypted")
+	}
+
+	w, err = OpenForRead(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, _, err = w.ReadAll()
+	w.Close()
+	if !errors.Is(err, encryption.ErrKeyRequired) {
+		t.Fatalf("err = %v, want %v", err, encryption.ErrKeyRequired)
+	}
+
+	w, err = OpenForRead(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetCipher(keyring)
+	_, _, gents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ents, gents)
+}
+
+func TestCompressedEntries(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ents []raftpb.Entry
+	for i := 1; i <= 10; i++ {
+		ents = append(ents, raftpb.Entry{Index: uint64(i), Term: 1, Data: bytes.Repeat([]byte{byte(i)}, 1024)})
+	}
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: 10}, ents); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	// the entries saved before compression is enabled stay readable
+	w, err = Open(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetCompression(true)
+	if _, _, _, err = w.ReadAll(); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, w.compressed)
+	var newEnts []raftpb.Entry
+	for i := 11; i <= 2*entryDecodeBatch; i++ {
+		newEnts = append(newEnts, raftpb.Entry{Index: uint64(i), Term: 1, Data: bytes.Repeat([]byte{byte(i)}, 1024)})
+	}
+	// too small to be compressed
+	newEnts = append(newEnts, raftpb.Entry{Index: uint64(2*entryDecodeBatch + 1), Term: 1, Data: []byte("small")})
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: uint64(2*entryDecodeBatch + 1)}, newEnts); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+	ents = append(ents, newEnts...)
+
+	w, err = OpenForRead(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	wv, err := ReadWALVersion(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ents, wv.entries)
+	assert.True(t, wv.compressed)
+}
+
+func TestCompressedEncryptedEntries(t *testing.T) {
+	p := t.TempDir()
+	keyring, err := encryption.NewKeyring(testKeyProvider{{ID: 1, Data: bytes.Repeat([]byte{1}, 32)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	w, err := Create(zaptest.NewLogger(t), p, []byte("metadata"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.SetCipher(keyring)
+	w.SetCompression(true)
+	ents := []raftpb.Entry{{Index: 1, Term: 1, Data: bytes.Repeat([]byte("secret"), 100)}}
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: 1}, ents); err != nil {
+		t.Fatal(err)
+	}
+	w.Close()
+
+	w, err = OpenForRead(zaptest.NewLogger(t), p, walpb.Snapshot{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	w.SetCipher(keyring)
+	_, _, gents, err := w.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, w.compressed)
+	assert.Equal(t, ents, gents)
+}
+
+func TestScrub(t *testing.T) {
+	p := t.TempDir()
+	w, err := Create(zaptest.NewLogger(t), p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	es := []raftpb.Entry{{Index: 1, Term: 1, Data: []byte("somedata")}}
+	if err = w.Save(raftpb.HardState{Term: 1, Commit: 1}, es); err != nil {
+		t.Fatal(err)
+	}
+	if err = w.cut(); err != nil {
+		t.Fatal(err)
+	}
+	// the segment being written is not scrubbed
+	if err = w.Save(raftpb.HardState{}, []raftpb.Entry{{Index: 2, Term: 1, Data: []byte("somedata")}}); err != nil {
+		t.Fatal(err)
+	}
+
+	var read int
+	throttle := func(n int) error {
+		read += n
+		return nil
+	}
+	if err = w.Scrub(throttle); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	fi, err := os.Stat(filepath.Join(p, walName(0, 0)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if int64(read) != fi.Size() {
+		t.Errorf("read = %d, want %d", read, fi.Size())
+	}
+
+	// flip a byte of the entry data
+	f, err := os.OpenFile(filepath.Join(p, walName(0, 0)), os.O_RDWR, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := io.ReadAll(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	i := bytes.Index(b, []byte("somedata"))
+	if _, err = f.WriteAt([]byte{'S'}, int64(i)); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	if err = w.Scrub(throttle); !errors.Is(err, ErrCRCMismatch) {
+		t.Fatalf("err = %v, want %v", err, ErrCRCMismatch)
+	}
+}
+
 func TestOpenWithMaxIndex(t *testing.T) {
 	p := t.TempDir()
 	// create WAL
@@ -173,6 +173,7 @@ type ClusterConfig struct {
 	ExperimentalMaxLearners     int
 	DisableStrictReconfigCheck  bool
 	CorruptCheckTime            time.Duration
+	ExperimentalLeaseRead       bool
 }
 
 type Cluster struct {
@@ -288,6 +289,7 @@ func (c *Cluster) mustNewMember(t testutil.TB) *Member {
 			ExperimentalMaxLearners:     c.Cfg.ExperimentalMaxLearners,
 			DisableStrictReconfigCheck:  c.Cfg.DisableStrictReconfigCheck,
 			CorruptCheckTime:            c.Cfg.CorruptCheckTime,
+			ExperimentalLeaseRead:       c.Cfg.ExperimentalLeaseRead,
 		})
 	m.DiscoveryURL = c.Cfg.DiscoveryURL
 	return m
@@ -612,6 +614,7 @@ type MemberConfig struct {
 	ExperimentalMaxLearners     int
 	DisableStrictReconfigCheck  bool
 	CorruptCheckTime            time.Duration
+	ExperimentalLeaseRead       bool
 }
 
 // MustNewMember return an inited member with the given name. If peerTLS is
@@ -726,6 +729,10 @@ func MustNewMember(t testutil.TB, mcfg MemberConfig) *Member {
 	if mcfg.ExperimentalMaxLearners != 0 {
 		m.ExperimentalMaxLearners = mcfg.ExperimentalMaxLearners
 	}
+	m.ExperimentalMaxObservers = membership.DefaultMaxObservers
+	m.ExperimentalLeaseRead = mcfg.ExperimentalLeaseRead
+	// the election timeout of the tests is short
+	m.ExperimentalLeaseReadMaxClockDrift = 10 * time.Millisecond
 	m.V2Deprecation = config.V2_DEPR_DEFAULT
 	m.GrpcServerRecorder = &grpc_testing.GrpcRecorder{}
 	m.Logger = memberLogger(t, mcfg.Name)
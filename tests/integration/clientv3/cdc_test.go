@@ -0,0 +1,170 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/client/v3/cdc"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// readExportedEvents returns the exported events of the files of the given
+// directory, formatted as "<type> <key>@<revision>".
+func readExportedEvents(t *testing.T, dir string) []string {
+	t.Helper()
+	paths, err := filepath.Glob(filepath.Join(dir, "*"+cdc.FormatJSON.Ext()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var evs []string
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := bufio.NewScanner(f)
+		for s.Scan() {
+			var ev struct {
+				Type string `json:"type"`
+				Kv   struct {
+					Key         []byte `json:"key"`
+					ModRevision int64  `json:"mod_revision"`
+				} `json:"kv"`
+			}
+			if err = json.Unmarshal(s.Bytes(), &ev); err != nil {
+				t.Fatal(err)
+			}
+			evs = append(evs, fmt.Sprintf("%s %s@%d", ev.Type, ev.Kv.Key, ev.Kv.ModRevision))
+		}
+		f.Close()
+	}
+	return evs
+}
+
+// runExporter exports the changes under /p/ from the given revision to the
+// given directory until the returned function is called.
+func runExporter(t *testing.T, clus *integration2.Cluster, dir string, startRev int64) (stop func()) {
+	sink, err := cdc.NewFileSink(cdc.FileSinkConfig{Dir: dir, MaxFileSize: 100})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := cdc.NewExporter(clus.RandClient(), sink, cdc.Config{Prefix: "/p/", Format: cdc.FormatJSON, StartRevision: startRev, FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	donec := make(chan error, 1)
+	go func() { donec <- e.Run(ctx) }()
+	return func() {
+		cancel()
+		if err := <-donec; err != context.Canceled {
+			t.Errorf("unexpected error %v", err)
+		}
+		sink.Close()
+	}
+}
+
+func TestCDCExport(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	c := clus.RandClient()
+	dir := t.TempDir()
+
+	// the changes before the start revision are not exported
+	startRev := mustPut(t, c, "/p/before", "v") + 1
+	stop := runExporter(t, clus, dir, startRev)
+	var want []string
+	waitEvents := func() {
+		t.Helper()
+		var got []string
+		for i := 0; i < 100; i++ {
+			if got = readExportedEvents(t, dir); len(got) >= len(want) {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("exported events = %q, want %q", got, want)
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		rev := mustPut(t, c, fmt.Sprintf("/p/k%d", i), "v")
+		want = append(want, fmt.Sprintf("PUT /p/k%d@%d", i, rev))
+		mustPut(t, c, "/q/other", "v")
+	}
+	resp, err := c.Delete(context.TODO(), "/p/k0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = append(want, fmt.Sprintf("DELETE /p/k0@%d", resp.Header.Revision))
+	waitEvents()
+	stop()
+
+	// resumes from the cursor rather than the start revision, exactly once
+	rev := mustPut(t, c, "/p/k5", "v")
+	want = append(want, fmt.Sprintf("PUT /p/k5@%d", rev))
+	stop = runExporter(t, clus, dir, startRev)
+	defer stop()
+	waitEvents()
+	if files, _ := filepath.Glob(filepath.Join(dir, "*"+cdc.FormatJSON.Ext())); len(files) < 2 {
+		t.Fatalf("expected the files to be rotated, got %q", files)
+	}
+}
+
+// failingSink fails every write.
+type failingSink struct{}
+
+var errSinkFull = errors.New("sink full")
+
+func (failingSink) Cursor() int64                   { return 0 }
+func (failingSink) Write(p []byte, rev int64) error { return errSinkFull }
+func (failingSink) Close() error                    { return nil }
+
+func TestCDCExportSinkError(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+	c := clus.RandClient()
+
+	e, err := cdc.NewExporter(c, failingSink{}, cdc.Config{Prefix: "/p/", Format: cdc.FormatJSON, FlushInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	donec := make(chan error, 1)
+	go func() { donec <- e.Run(ctx) }()
+	time.Sleep(100 * time.Millisecond)
+	mustPut(t, c, "/p/k", "v")
+
+	// the sink error is returned rather than retried
+	if err = <-donec; !errors.Is(err, errSinkFull) {
+		t.Fatalf("Run() = %v, want %v", err, errSinkFull)
+	}
+}
@@ -0,0 +1,181 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/informer"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+type informerRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (r *informerRecorder) record(format string, args ...interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, fmt.Sprintf(format, args...))
+}
+
+func (r *informerRecorder) OnAdd(kv *mvccpb.KeyValue) { r.record("add %s=%s", kv.Key, kv.Value) }
+func (r *informerRecorder) OnUpdate(old, kv *mvccpb.KeyValue) {
+	r.record("update %s=%s->%s", kv.Key, old.Value, kv.Value)
+}
+func (r *informerRecorder) OnDelete(kv *mvccpb.KeyValue) { r.record("delete %s=%s", kv.Key, kv.Value) }
+
+// wait waits for the handlers to be notified of the given events.
+func (r *informerRecorder) wait(t *testing.T, want ...string) {
+	t.Helper()
+	var got []string
+	for i := 0; i < 200; i++ {
+		r.mu.Lock()
+		got = r.events
+		r.mu.Unlock()
+		if len(got) >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	r.mu.Lock()
+	r.events = nil
+	r.mu.Unlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("events = %q, want %q", got, want)
+	}
+}
+
+// pausableWatcher cancels the watches while paused.
+type pausableWatcher struct {
+	clientv3.Watcher
+
+	mu     sync.Mutex
+	paused bool
+	cancel context.CancelFunc
+}
+
+func (w *pausableWatcher) Watch(ctx context.Context, key string, opts ...clientv3.OpOption) clientv3.WatchChan {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ctx, w.cancel = context.WithCancel(ctx)
+	if w.paused {
+		w.cancel()
+	}
+	return w.Watcher.Watch(ctx, key, opts...)
+}
+
+func (w *pausableWatcher) pause(paused bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.paused = paused
+	if paused && w.cancel != nil {
+		w.cancel()
+	}
+}
+
+func TestInformer(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	c := clus.Client(0)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	for _, k := range []string{"a", "b", "c"} {
+		if _, err := c.Put(ctx, "/pods/"+k, "node1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := c.Put(ctx, "/other", "node1"); err != nil {
+		t.Fatal(err)
+	}
+
+	rec := &informerRecorder{}
+	w := &pausableWatcher{Watcher: c.Watcher}
+	inf := informer.New(c, w, "/pods/",
+		informer.WithHandler(rec),
+		informer.WithIndexer("node", func(kv *mvccpb.KeyValue) []string { return []string{string(kv.Value)} }),
+		informer.WithPageSize(2),
+	)
+	donec := make(chan error, 1)
+	go func() { donec <- inf.Run(ctx) }()
+
+	select {
+	case <-inf.Synced():
+	case <-time.After(5 * time.Second):
+		t.Fatal("informer did not sync")
+	}
+	if !inf.HasSynced() {
+		t.Fatal("informer has not synced")
+	}
+	rec.wait(t, "add /pods/a=node1", "add /pods/b=node1", "add /pods/c=node1")
+
+	if _, err := c.Put(ctx, "/pods/b", "node2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Delete(ctx, "/pods/c"); err != nil {
+		t.Fatal(err)
+	}
+	rec.wait(t, "update /pods/b=node1->node2", "delete /pods/c=node1")
+
+	kvs, err := inf.ByIndex("node", "node2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(kvs) != 1 || string(kvs[0].Key) != "/pods/b" {
+		t.Fatalf("keys on node2 = %v, want /pods/b", kvs)
+	}
+	if vs, _ := inf.IndexValues("node"); !reflect.DeepEqual(vs, []string{"node1", "node2"}) {
+		t.Fatalf("index values = %v, want [node1 node2]", vs)
+	}
+
+	// changes missed by a compacted watch are notified after a relist
+	w.pause(true)
+	if _, err = c.Put(ctx, "/pods/d", "node3"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Delete(ctx, "/pods/a"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := c.Put(ctx, "/other", "node2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = c.Compact(ctx, resp.Header.Revision); err != nil {
+		t.Fatal(err)
+	}
+	w.pause(false)
+	rec.wait(t, "add /pods/d=node3", "delete /pods/a=node1")
+
+	if _, rev := inf.List(); rev < resp.Header.Revision-1 {
+		t.Errorf("informer revision = %d, want at least %d", rev, resp.Header.Revision-1)
+	}
+
+	cancel()
+	if err = <-donec; err != context.Canceled {
+		t.Errorf("Run = %v, want %v", err, context.Canceled)
+	}
+}
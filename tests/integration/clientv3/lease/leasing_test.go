@@ -615,6 +615,53 @@ func TestLeasingTxnOwnerGetRange(t *testing.T) {
 	}
 }
 
+// TestLeasingTxnReadSet checks a txn with a read set is validated by the server,
+// even when its keys are leased.
+func TestLeasingTxnReadSet(t *testing.T) {
+	integration2.BeforeTest(t)
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	lkv, closeLKV, err := leasing.NewKV(clus.Client(0), "pfx/")
+	testutil.AssertNil(t, err)
+	defer closeLKV()
+
+	if _, err = clus.Client(0).Put(context.TODO(), "k", "abc"); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := lkv.Get(context.TODO(), "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSet := []clientv3.ReadSetEntry{{Key: []byte("k"), ModRevision: resp.Kvs[0].ModRevision}}
+
+	if _, err = clus.Client(0).Put(context.TODO(), "k", "def"); err != nil {
+		t.Fatal(err)
+	}
+	tresp, terr := lkv.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("k", "ghi")).Commit()
+	if terr != nil {
+		t.Fatal(terr)
+	}
+	if tresp.Succeeded || len(tresp.Conflicts) != 1 || string(tresp.Conflicts[0].Value) != "def" {
+		t.Fatalf("expected txn to fail with conflict on k=def, got %+v", tresp)
+	}
+
+	readSet[0].ModRevision = tresp.Conflicts[0].ModRevision
+	tresp, terr = lkv.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("k", "ghi")).Commit()
+	if terr != nil {
+		t.Fatal(terr)
+	}
+	if !tresp.Succeeded {
+		t.Fatalf("expected txn to succeed, got %+v", tresp)
+	}
+	if resp, err = lkv.Get(context.TODO(), "k"); err != nil {
+		t.Fatal(err)
+	}
+	if string(resp.Kvs[0].Value) != "ghi" {
+		t.Fatalf("expected k=ghi, got %+v", resp.Kvs)
+	}
+}
+
 func TestLeasingTxnOwnerGet(t *testing.T) {
 	integration2.BeforeTest(t)
 	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseBridge: true})
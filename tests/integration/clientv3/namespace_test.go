@@ -54,6 +54,35 @@ func TestNamespacePutGet(t *testing.T) {
 	}
 }
 
+func TestNamespaceTxnReadSet(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	c := clus.Client(0)
+	nsKV := namespace.NewKV(c.KV, "foo/")
+
+	presp, err := nsKV.Put(context.TODO(), "abc", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSet := []clientv3.ReadSetEntry{{Key: []byte("abc"), ModRevision: presp.Header.Revision}}
+	if _, err = c.Put(context.TODO(), "foo/abc", "baz"); err != nil {
+		t.Fatal(err)
+	}
+	tresp, err := nsKV.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("abc", "qux")).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.Succeeded || len(tresp.Conflicts) != 1 {
+		t.Fatalf("expected txn to fail with 1 conflict, got %+v", tresp)
+	}
+	if c := tresp.Conflicts[0]; string(c.Key) != "abc" || string(c.Value) != "baz" {
+		t.Errorf("expected conflict on abc=baz, got %+v", c)
+	}
+}
+
 func TestNamespaceWatch(t *testing.T) {
 	integration2.BeforeTest(t)
 
@@ -0,0 +1,239 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/mirror/replicator"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// runReplication runs the given replication until the returned function is
+// called.
+func runReplication(r *replicator.Replicator) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	donec := make(chan struct{})
+	go func() {
+		defer close(donec)
+		r.Run(ctx)
+	}()
+	return func() {
+		cancel()
+		<-donec
+	}
+}
+
+// waitReplicatedKeys waits for the keys under the given prefix to hold the
+// given values, ignoring the replication states.
+func waitReplicatedKeys(t *testing.T, c *clientv3.Client, prefix string, want map[string]string) {
+	t.Helper()
+	var got map[string]string
+	for i := 0; i < 100; i++ {
+		resp, err := c.Get(context.TODO(), prefix, clientv3.WithPrefix())
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = make(map[string]string)
+		for _, kv := range resp.Kvs {
+			if !strings.HasPrefix(string(kv.Key), replicator.DefaultMetaPrefix) {
+				got[string(kv.Key)] = string(kv.Value)
+			}
+		}
+		if reflect.DeepEqual(got, want) {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	t.Fatalf("keys under %q = %v, want %v", prefix, got, want)
+}
+
+func mustPut(t *testing.T, c *clientv3.Client, key, val string) int64 {
+	t.Helper()
+	resp, err := c.Put(context.TODO(), key, val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.Header.Revision
+}
+
+func TestReplication(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clusA := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusA.Terminate(t)
+	clusB := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusB.Terminate(t)
+	ca, cb := clusA.RandClient(), clusB.RandClient()
+
+	mustPut(t, ca, "/a/k1", "v1")
+	mustPut(t, ca, "/a/k2", "v2")
+	mustPut(t, ca, "/a/local/x", "a")
+	mustPut(t, cb, "/b/local/y", "b")
+
+	r := replicator.New(replicator.Config{
+		Name:            "test",
+		Primary:         ca,
+		Secondary:       cb,
+		PrimaryPrefix:   "/a/",
+		SecondaryPrefix: "/b/",
+		Filter:          func(key []byte) bool { return !bytes.HasPrefix(key, []byte("local/")) },
+		ConflictPolicy:  replicator.ConflictKeep,
+	})
+	stop := runReplication(r)
+
+	// full sync, leaving the filtered keys alone
+	waitReplicatedKeys(t, cb, "/b/", map[string]string{"/b/k1": "v1", "/b/k2": "v2", "/b/local/y": "b"})
+
+	mustPut(t, ca, "/a/k3", "v3")
+	if _, err := ca.Delete(context.TODO(), "/a/k1"); err != nil {
+		t.Fatal(err)
+	}
+	waitReplicatedKeys(t, cb, "/b/", map[string]string{"/b/k2": "v2", "/b/k3": "v3", "/b/local/y": "b"})
+
+	// a conflicting change of the destination is kept
+	mustPut(t, cb, "/b/k2", "changed")
+	mustPut(t, ca, "/a/k2", "v2'")
+	mustPut(t, ca, "/a/k4", "v4")
+	waitReplicatedKeys(t, cb, "/b/", map[string]string{"/b/k2": "changed", "/b/k3": "v3", "/b/k4": "v4", "/b/local/y": "b"})
+	stop()
+
+	// resume from the checkpoint, without rewriting the replicated keys
+	resp, err := cb.Get(context.TODO(), "/b/k3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	modRev := resp.Kvs[0].ModRevision
+	mustPut(t, ca, "/a/k5", "v5")
+	stop = runReplication(r)
+	defer func() { stop() }()
+	waitReplicatedKeys(t, cb, "/b/", map[string]string{"/b/k2": "changed", "/b/k3": "v3", "/b/k4": "v4", "/b/k5": "v5", "/b/local/y": "b"})
+	if resp, err = cb.Get(context.TODO(), "/b/k3"); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Kvs[0].ModRevision != modRev {
+		t.Fatalf("mod revision of a replicated key = %d, want %d", resp.Kvs[0].ModRevision, modRev)
+	}
+
+	// fail over to the secondary, which reverses the replication
+	epoch, err := replicator.Promote(context.TODO(), cb, "", "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if epoch != 2 {
+		t.Fatalf("epoch = %d, want 2", epoch)
+	}
+	mustPut(t, cb, "/b/k6", "v6")
+	waitReplicatedKeys(t, ca, "/a/", map[string]string{"/a/k2": "changed", "/a/k3": "v3", "/a/k4": "v4", "/a/k5": "v5", "/a/k6": "v6", "/a/local/x": "a"})
+
+	// the former active cluster is passive
+	mustPut(t, ca, "/a/k7", "lost")
+	mustPut(t, cb, "/b/k8", "v8")
+	waitReplicatedKeys(t, ca, "/a/", map[string]string{"/a/k2": "changed", "/a/k3": "v3", "/a/k4": "v4", "/a/k5": "v5", "/a/k6": "v6", "/a/k7": "lost", "/a/k8": "v8", "/a/local/x": "a"})
+	waitReplicatedKeys(t, cb, "/b/", map[string]string{"/b/k2": "changed", "/b/k3": "v3", "/b/k4": "v4", "/b/k5": "v5", "/b/k6": "v6", "/b/k8": "v8", "/b/local/y": "b"})
+}
+
+func TestReplicationCompacted(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clusA := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusA.Terminate(t)
+	clusB := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusB.Terminate(t)
+	ca, cb := clusA.RandClient(), clusB.RandClient()
+
+	mustPut(t, ca, "k1", "v1")
+	r := replicator.New(replicator.Config{Name: "test", Primary: ca, Secondary: cb})
+	stop := runReplication(r)
+	waitReplicatedKeys(t, cb, "", map[string]string{"k1": "v1"})
+	stop()
+
+	// the source compacts the changes after the checkpoint
+	mustPut(t, ca, "k2", "v2")
+	if _, err := ca.Delete(context.TODO(), "k1"); err != nil {
+		t.Fatal(err)
+	}
+	rev := mustPut(t, ca, "k3", "v3")
+	if _, err := ca.Compact(context.TODO(), rev); err != nil {
+		t.Fatal(err)
+	}
+
+	stop = runReplication(r)
+	defer stop()
+	waitReplicatedKeys(t, cb, "", map[string]string{"k2": "v2", "k3": "v3"})
+}
+
+// TestReplicationFilteredCheckpoint ensures the checkpoint follows the source
+// revisions without replicated changes.
+func TestReplicationFilteredCheckpoint(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clusA := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusA.Terminate(t)
+	clusB := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1, UseTCP: true})
+	defer clusB.Terminate(t)
+	ca, cb := clusA.RandClient(), clusB.RandClient()
+
+	mustPut(t, ca, "k1", "v1")
+	r := replicator.New(replicator.Config{
+		Name:      "test",
+		Primary:   ca,
+		Secondary: cb,
+		Filter:    func(key []byte) bool { return !bytes.HasPrefix(key, []byte("local/")) },
+	})
+	stop := runReplication(r)
+	defer func() { stop() }()
+	waitReplicatedKeys(t, cb, "k", map[string]string{"k1": "v1"})
+
+	var rev int64
+	for i := 0; i < 3; i++ {
+		rev = mustPut(t, ca, "local/x", "a")
+	}
+	var checkpoint int64
+	for i := 0; i < 100 && checkpoint < rev; i++ {
+		time.Sleep(100 * time.Millisecond)
+		resp, err := cb.Get(context.TODO(), replicator.DefaultMetaPrefix+"test")
+		if err != nil {
+			t.Fatal(err)
+		}
+		var st struct {
+			Revision int64 `json:"revision"`
+		}
+		if err = json.Unmarshal(resp.Kvs[0].Value, &st); err != nil {
+			t.Fatal(err)
+		}
+		checkpoint = st.Revision
+	}
+	if checkpoint < rev {
+		t.Fatalf("checkpoint = %d, want at least %d", checkpoint, rev)
+	}
+	stop()
+
+	// the replication resumes from the checkpoint once the source compacts
+	// the filtered changes
+	if _, err := ca.Compact(context.TODO(), rev); err != nil {
+		t.Fatal(err)
+	}
+	mustPut(t, ca, "k2", "v2")
+	stop = runReplication(r)
+	waitReplicatedKeys(t, cb, "k", map[string]string{"k1": "v1", "k2": "v2"})
+}
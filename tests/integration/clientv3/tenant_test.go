@@ -0,0 +1,134 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clientv3test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/concurrency"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// setupTenant enables auth with a root user and a tenant user "user" with
+// password "123", who may read and write every key of its tenant under "/t/".
+func setupTenant(t *testing.T, c *clientv3.Client) {
+	ctx := context.TODO()
+	steps := []func() error{
+		func() error { _, err := c.UserAdd(ctx, "root", "123"); return err },
+		func() error { _, err := c.UserGrantRole(ctx, "root", "root"); return err },
+		func() error { _, err := c.TenantAdd(ctx, "t", "/t/"); return err },
+		func() error { _, err := c.TenantRoleAdd(ctx, "t", "role"); return err },
+		func() error {
+			_, err := c.RoleGrantPermission(ctx, "role", "", "\x00", clientv3.PermissionType(clientv3.PermReadWrite))
+			return err
+		},
+		func() error { _, err := c.TenantUserAdd(ctx, "t", "user", "123", nil); return err },
+		func() error { _, err := c.UserGrantRole(ctx, "user", "role"); return err },
+		func() error { _, err := c.AuthEnable(ctx); return err },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestTenantSTMServerValidation(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	setupTenant(t, clus.Client(0))
+	root, err := integration2.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "root", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	tc, err := integration2.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err = tc.Put(ctx, "a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	var reads []string
+	applyf := func(stm concurrency.STM) error {
+		a := stm.Get("a")
+		reads = append(reads, a)
+		if len(reads) == 1 {
+			// conflict with the first attempt
+			if _, err := root.Put(ctx, "/t/a", "2"); err != nil {
+				return err
+			}
+		}
+		stm.Put("a", a+"3")
+		return nil
+	}
+	if _, err = concurrency.NewSTM(tc, applyf, concurrency.WithServerValidation()); err != nil {
+		t.Fatal(err)
+	}
+	if len(reads) != 2 || reads[1] != "2" {
+		t.Fatalf("bad reads. got %v, expected [1 2]", reads)
+	}
+	resp, err := root.Get(ctx, "/t/a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := string(resp.Kvs[0].Value); v != "23" {
+		t.Fatalf("/t/a = %q, want %q", v, "23")
+	}
+}
+
+// TestTenantClusterRequests ensures that tenant users may not list the leases
+// of the cluster or compact its history.
+func TestTenantClusterRequests(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	setupTenant(t, clus.Client(0))
+	tc, err := integration2.NewClient(t, clientv3.Config{Endpoints: clus.Client(0).Endpoints(), Username: "user", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	resp, err := tc.Put(ctx, "a", "1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tc.Grant(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = tc.Leases(ctx); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("Leases() = %v, want %v", err, rpctypes.ErrPermissionDenied)
+	}
+	if _, err = tc.Compact(ctx, resp.Header.Revision); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+		t.Fatalf("Compact() = %v, want %v", err, rpctypes.ErrPermissionDenied)
+	}
+}
@@ -226,3 +226,62 @@ func TestTxnNested(t *testing.T) {
 		t.Errorf("unexpected Get response %+v", resp)
 	}
 }
+
+func TestTxnReadSet(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	kv := clus.Client(0)
+
+	presp, err := kv.Put(context.TODO(), "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSet := []clientv3.ReadSetEntry{
+		{Key: []byte("foo"), ModRevision: presp.Header.Revision},
+		{Key: []byte("abc")},
+	}
+	tresp, err := kv.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("foo", "baz")).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tresp.Succeeded || len(tresp.Conflicts) != 0 {
+		t.Fatalf("expected txn to succeed without conflicts, got %+v", tresp)
+	}
+
+	// the txn is applied by another member than the one it is sent to
+	if _, err = clus.Client(1).Put(context.TODO(), "abc", "123"); err != nil {
+		t.Fatal(err)
+	}
+	tresp, err = kv.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("foo", "baz")).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.Succeeded || len(tresp.Conflicts) != 2 {
+		t.Fatalf("expected txn to fail with 2 conflicts, got %+v", tresp)
+	}
+	if c := tresp.Conflicts[0]; string(c.Key) != "foo" || string(c.Value) != "baz" {
+		t.Errorf("expected conflict on foo=baz, got %+v", c)
+	}
+	if c := tresp.Conflicts[1]; string(c.Key) != "abc" || string(c.Value) != "123" {
+		t.Errorf("expected conflict on abc=123, got %+v", c)
+	}
+
+	// retry with the conflicting revisions
+	readSet[0].ModRevision, readSet[1].ModRevision = tresp.Conflicts[0].ModRevision, tresp.Conflicts[1].ModRevision
+	if _, err = kv.Delete(context.TODO(), "abc"); err != nil {
+		t.Fatal(err)
+	}
+	tresp, err = kv.Txn(context.TODO()).ReadSet(readSet...).Then(clientv3.OpPut("foo", "qux")).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.Succeeded || len(tresp.Conflicts) != 1 {
+		t.Fatalf("expected txn to fail with 1 conflict, got %+v", tresp)
+	}
+	if c := tresp.Conflicts[0]; string(c.Key) != "abc" || c.ModRevision != 0 {
+		t.Errorf("expected deleted abc conflict, got %+v", c)
+	}
+}
@@ -16,13 +16,20 @@ package grpcproxy
 
 import (
 	"context"
+	"errors"
 	"net"
+	"strconv"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.uber.org/zap/zaptest"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 
 	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
 	clientv3 "go.etcd.io/etcd/client/v3"
 	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
 	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
@@ -53,6 +60,215 @@ func TestKVProxyRange(t *testing.T) {
 	client.Close()
 }
 
+// TestKVProxyTxnReadSet ensures the proxy forwards the read sets of txns.
+func TestKVProxyTxnReadSet(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvts := newKVProxyServer([]string{clus.Members[0].GRPCURL()}, t)
+	defer kvts.close()
+
+	client, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{kvts.l.Addr().String()}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	presp, err := client.Put(ctx, "foo", "bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	readSet := []clientv3.ReadSetEntry{{Key: []byte("foo"), ModRevision: presp.Header.Revision}}
+	if _, err = clus.Client(0).Put(ctx, "foo", "baz"); err != nil {
+		t.Fatal(err)
+	}
+	nested := clientv3.OpTxn(nil, []clientv3.Op{clientv3.OpPut("foo", "nested")}, nil)
+	nested.WithReadSet(readSet)
+	tresp, err := client.Txn(ctx).ReadSet(readSet...).Then(clientv3.OpPut("foo", "qux")).Else(nested).Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tresp.Succeeded || len(tresp.Conflicts) != 1 || string(tresp.Conflicts[0].Value) != "baz" {
+		t.Fatalf("expected txn to fail with conflict on foo=baz, got %+v", tresp)
+	}
+	if nresp := tresp.Responses[0].GetResponseTxn(); nresp.Succeeded || len(nresp.Conflicts) != 1 {
+		t.Fatalf("expected nested txn to fail with a conflict, got %+v", nresp)
+	}
+	gresp, err := client.Get(ctx, "foo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v := string(gresp.Kvs[0].Value); v != "baz" {
+		t.Fatalf("foo = %q, want %q", v, "baz")
+	}
+}
+
+// TestKVProxyCoherentCache ensures the coherent cache of the proxy observes
+// the writes made directly to the cluster.
+func TestKVProxyCoherentCache(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvts := newKVProxyServerWith([]string{clus.Members[0].GRPCURL()}, t, func(c *clientv3.Client) pb.KVServer {
+		kvp, _ := grpcproxy.NewCoherentKvProxy(zaptest.NewLogger(t), c, []string{"/view/"})
+		return kvp
+	})
+	defer kvts.close()
+
+	client, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{kvts.l.Addr().String()}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	direct := clus.Client(0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	get := func(key string, opts ...clientv3.OpOption) string {
+		resp, err := client.Get(ctx, key, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resp.Kvs) != 1 {
+			return ""
+		}
+		return string(resp.Kvs[0].Value)
+	}
+	waitValue := func(key, want string, opts ...clientv3.OpOption) {
+		for i := 0; i < 100 && get(key, opts...) != want; i++ {
+			time.Sleep(10 * time.Millisecond)
+		}
+		if got := get(key, opts...); got != want {
+			t.Fatalf("%s = %q, want %q", key, got, want)
+		}
+	}
+
+	// linearizable reads of the materialized prefix see the direct writes
+	for i := 0; i < 10; i++ {
+		val := strconv.Itoa(i)
+		if _, err = direct.Put(ctx, "/view/a", val); err != nil {
+			t.Fatal(err)
+		}
+		if got := get("/view/a"); got != val {
+			t.Fatalf("/view/a = %q, want %q", got, val)
+		}
+		if resp, err := client.Get(ctx, "/view/", clientv3.WithPrefix(), clientv3.WithCountOnly()); err != nil || resp.Count != 1 {
+			t.Fatalf("count of /view/ = %v (%v), want 1", resp, err)
+		}
+	}
+
+	if hits := testutil.ToFloat64(coherentCacheHits()); hits < 10 {
+		t.Errorf("coherent cache hits = %v, want at least 10", hits)
+	}
+
+	// serializable reads cached by the proxy are invalidated by direct writes
+	if _, err = direct.Put(ctx, "/other", "1"); err != nil {
+		t.Fatal(err)
+	}
+	waitValue("/other", "1", clientv3.WithSerializable())
+	if _, err = direct.Put(ctx, "/other", "2"); err != nil {
+		t.Fatal(err)
+	}
+	waitValue("/other", "2", clientv3.WithSerializable())
+	if _, err = direct.Delete(ctx, "/view/a"); err != nil {
+		t.Fatal(err)
+	}
+	waitValue("/view/a", "", clientv3.WithSerializable())
+}
+
+// TestKVProxyCoherentCacheAuth ensures the coherent cache of the proxy only
+// serves the ranges the user of the request may read.
+func TestKVProxyCoherentCacheAuth(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	kvts := newKVProxyServerWith([]string{clus.Members[0].GRPCURL()}, t, func(c *clientv3.Client) pb.KVServer {
+		kvp, _ := grpcproxy.NewCoherentKvProxy(zaptest.NewLogger(t), c, []string{"/view/"})
+		return kvp
+	})
+	defer kvts.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	direct := clus.Client(0)
+	for _, key := range []string{"/view/a", "/view/b"} {
+		if _, err := direct.Put(ctx, key, "1"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	steps := []func() error{
+		func() error { _, err := direct.UserAdd(ctx, "root", "123"); return err },
+		func() error { _, err := direct.UserGrantRole(ctx, "root", "root"); return err },
+		func() error { _, err := direct.UserAdd(ctx, "user", "123"); return err },
+		func() error { _, err := direct.RoleAdd(ctx, "role"); return err },
+		func() error {
+			_, err := direct.RoleGrantPermission(ctx, "role", "/view/a", "", clientv3.PermissionType(clientv3.PermRead))
+			return err
+		},
+		func() error { _, err := direct.UserGrantRole(ctx, "user", "role"); return err },
+		func() error { _, err := direct.AuthEnable(ctx); return err },
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	authc, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{clus.Members[0].GRPCURL()}, Username: "user", Password: "123"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer authc.Close()
+	aresp, err := authc.Authenticate(ctx, "user", "123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{kvts.l.Addr().String()}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	uctx := metadata.AppendToOutgoingContext(ctx, rpctypes.TokenFieldNameGRPC, aresp.Token)
+
+	for _, opts := range [][]clientv3.OpOption{nil, {clientv3.WithSerializable()}} {
+		resp, err := client.Get(uctx, "/view/a", opts...)
+		if err != nil || len(resp.Kvs) != 1 {
+			t.Fatalf("get of /view/a = %v (%v), want a key", resp, err)
+		}
+		if _, err = client.Get(uctx, "/view/", append(opts, clientv3.WithPrefix())...); !errors.Is(err, rpctypes.ErrPermissionDenied) {
+			t.Fatalf("get of /view/ err = %v, want %v", err, rpctypes.ErrPermissionDenied)
+		}
+		if _, err = client.Get(ctx, "/view/a", opts...); err == nil {
+			t.Fatalf("get of /view/a without token succeeded")
+		}
+	}
+}
+
+// coherentCacheHits returns the hit counter of the coherent cache from the
+// default registry.
+func coherentCacheHits() prometheus.Collector {
+	return prometheus.NewCounterFunc(prometheus.CounterOpts{Name: "hits"}, func() float64 {
+		mfs, err := prometheus.DefaultGatherer.Gather()
+		if err != nil {
+			return 0
+		}
+		for _, mf := range mfs {
+			if mf.GetName() == "etcd_grpc_proxy_coherent_cache_hits_total" {
+				return mf.GetMetric()[0].GetCounter().GetValue()
+			}
+		}
+		return 0
+	})
+}
+
 type kvproxyTestServer struct {
 	kp     pb.KVServer
 	c      *clientv3.Client
@@ -67,16 +283,25 @@ func (kts *kvproxyTestServer) close() {
 }
 
 func newKVProxyServer(endpoints []string, t *testing.T) *kvproxyTestServer {
+	return newKVProxyServerWith(endpoints, t, func(c *clientv3.Client) pb.KVServer {
+		kvp, _ := grpcproxy.NewKvProxy(c)
+		return kvp
+	})
+}
+
+func newKVProxyServerWith(endpoints []string, t *testing.T, newKvProxy func(c *clientv3.Client) pb.KVServer) *kvproxyTestServer {
 	cfg := clientv3.Config{
 		Endpoints:   endpoints,
 		DialTimeout: 5 * time.Second,
+		// forwards the auth token of the requests, as the proxy does
+		DialOptions: []grpc.DialOption{grpc.WithUnaryInterceptor(grpcproxy.AuthUnaryClientInterceptor)},
 	}
 	client, err := integration2.NewClient(t, cfg)
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	kvp, _ := grpcproxy.NewKvProxy(client)
+	kvp := newKvProxy(client)
 
 	kvts := &kvproxyTestServer{
 		kp: kvp,
@@ -0,0 +1,89 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package grpcproxy
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"go.uber.org/zap/zaptest"
+	"google.golang.org/grpc"
+
+	pb "go.etcd.io/etcd/api/v3/etcdserverpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/proxy/grpcproxy"
+	integration2 "go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestWatchProxyCoalesce ensures the proxy coalesces the events of watchers
+// with a coalesce window.
+func TestWatchProxyCoalesce(t *testing.T) {
+	integration2.BeforeTest(t)
+
+	clus := integration2.NewCluster(t, &integration2.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	pc, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{clus.Members[0].GRPCURL()}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	wp, _ := grpcproxy.NewWatchProxy(ctx, zaptest.NewLogger(t), pc)
+	server := grpc.NewServer()
+	pb.RegisterWatchServer(server, wp)
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go server.Serve(l)
+	defer server.Stop()
+
+	client, err := integration2.NewClient(t, clientv3.Config{Endpoints: []string{l.Addr().String()}, DialTimeout: 5 * time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	wch := client.Watch(ctx, "k", clientv3.WithCoalesceWindow(500*time.Millisecond), clientv3.WithCreatedNotify())
+	if wresp := <-wch; !wresp.Created {
+		t.Fatalf("expected the created response, got %+v", wresp)
+	}
+	var first int64
+	for _, v := range []string{"1", "2", "3"} {
+		resp, err := clus.Client(0).Put(ctx, "k", v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if first == 0 {
+			first = resp.Header.Revision
+		}
+	}
+
+	wresp := <-wch
+	if err = wresp.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if len(wresp.Events) != 1 {
+		t.Fatalf("len(events) = %d, want 1", len(wresp.Events))
+	}
+	ev := wresp.Events[0]
+	if string(ev.Kv.Value) != "3" || ev.CoalescedStartRevision != first {
+		t.Fatalf("got value %q coalesced from %d, want %q coalesced from %d", ev.Kv.Value, ev.CoalescedStartRevision, "3", first)
+	}
+}
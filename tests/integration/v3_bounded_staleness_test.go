@@ -0,0 +1,70 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/api/v3/v3rpc/rpctypes"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3BoundedStalenessRead ensures followers serve the reads with a
+// staleness bound from their local state, at a revision not older than the
+// bound allows.
+func TestV3BoundedStalenessRead(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	lead := clus.WaitLeader(t)
+	follower := (lead + 1) % 3
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for i := 0; i < 10; i++ {
+		presp, err := clus.Client(lead).Put(ctx, "foo", strconv.Itoa(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		// the heartbeats of the leader let the follower know the put is
+		// committed; the read is then at most one revision behind it
+		time.Sleep(100 * time.Millisecond)
+		resp, err := clus.Client(follower).Get(ctx, "foo", clientv3.WithMaxStaleness(5*time.Second), clientv3.WithMaxStalenessRevisions(1))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if resp.Header.Revision < presp.Header.Revision-1 {
+			t.Fatalf("read revision %d, want at least %d", resp.Header.Revision, presp.Header.Revision-1)
+		}
+	}
+
+	v, err := clus.Members[follower].Metric("etcd_server_bounded_staleness_reads_total", `path="local"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := strconv.Atoi(v); n == 0 {
+		t.Errorf("expected reads served from the local state of the follower, got %q", v)
+	}
+
+	if _, err = clus.Client(follower).Get(ctx, "foo", clientv3.WithMaxStalenessRevisions(-1)); err != rpctypes.ErrInvalidMaxStaleness {
+		t.Errorf("err = %v, want %v", err, rpctypes.ErrInvalidMaxStaleness)
+	}
+}
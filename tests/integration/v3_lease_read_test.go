@@ -0,0 +1,76 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"go.etcd.io/etcd/tests/v3/framework/integration"
+)
+
+// TestV3LeaseReadLinearizable ensures the reads served by the leader under
+// its leadership lease see the writes acknowledged by the other members,
+// across a leadership transfer.
+func TestV3LeaseReadLinearizable(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3, ExperimentalLeaseRead: true})
+	defer clus.Terminate(t)
+
+	check := func(round int) {
+		lead := clus.WaitLeader(t)
+		follower := clus.Client((lead + 1) % 3)
+		for i := 0; i < 10; i++ {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			val := fmt.Sprintf("%d-%d", round, i)
+			if _, err := follower.Put(ctx, "foo", val); err != nil {
+				t.Fatal(err)
+			}
+			resp, err := clus.Client(lead).Get(ctx, "foo")
+			cancel()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(resp.Kvs) != 1 || string(resp.Kvs[0].Value) != val {
+				t.Fatalf("round %d: got %v, want foo=%s", round, resp.Kvs, val)
+			}
+			// give the lease time to be renewed
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	check(0)
+
+	lead := clus.WaitLeader(t)
+	target := clus.Members[(lead+1)%3].Server.MemberId()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	err := clus.Members[lead].Server.MoveLeader(ctx, uint64(clus.Members[lead].Server.MemberId()), uint64(target))
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	check(1)
+
+	v, err := clus.Members[0].Metric("etcd_server_linearizable_reads_total", `path="lease"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := strconv.Atoi(v); n == 0 {
+		t.Errorf("expected reads served under the leadership lease, got %q", v)
+	}
+}
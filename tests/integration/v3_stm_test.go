@@ -300,3 +300,106 @@ func TestSTMSerializableSnapshotPut(t *testing.T) {
 		t.Fatalf("bad version. got %+v, expected version 2", resp)
 	}
 }
+
+// TestSTMServerValidationConflict tests that conflicts are retried when the
+// server validates the read set, at every isolation level.
+func TestSTMServerValidationConflict(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 3})
+	defer clus.Terminate(t)
+
+	isos := []concurrency.Isolation{
+		concurrency.SerializableSnapshot,
+		concurrency.Serializable,
+		concurrency.RepeatableReads,
+	}
+	for _, iso := range isos {
+		etcdc := clus.RandClient()
+		keys := make([]string, 5)
+		for i := 0; i < len(keys); i++ {
+			keys[i] = fmt.Sprintf("foo-%d-%d", iso, i)
+			if _, err := etcdc.Put(context.TODO(), keys[i], "100"); err != nil {
+				t.Fatalf("could not make key (%v)", err)
+			}
+		}
+
+		errc := make(chan error)
+		for i := range keys {
+			curEtcdc := clus.RandClient()
+			srcKey, dstKey := keys[i], keys[(i+1)%len(keys)]
+			applyf := func(stm concurrency.STM) error {
+				srcV, _ := strconv.ParseInt(stm.Get(srcKey), 10, 64)
+				dstV, _ := strconv.ParseInt(stm.Get(dstKey), 10, 64)
+				stm.Put(srcKey, fmt.Sprintf("%d", srcV-10))
+				stm.Put(dstKey, fmt.Sprintf("%d", dstV+10))
+				return nil
+			}
+			go func() {
+				_, err := concurrency.NewSTM(curEtcdc, applyf, concurrency.WithIsolation(iso), concurrency.WithServerValidation())
+				errc <- err
+			}()
+		}
+		for range keys {
+			if err := <-errc; err != nil {
+				t.Fatalf("apply failed (%v)", err)
+			}
+		}
+
+		// every key is moved to and from once
+		for _, key := range keys {
+			rk, err := etcdc.Get(context.TODO(), key)
+			if err != nil {
+				t.Fatalf("couldn't fetch key %s (%v)", key, err)
+			}
+			if v := string(rk.Kvs[0].Value); v != "100" {
+				t.Fatalf("bad value for %s at isolation %d. got %s, expected 100", key, iso, v)
+			}
+		}
+	}
+}
+
+// TestSTMServerValidationRetry tests that a retried transaction reads the
+// conflicting keys returned by the server.
+func TestSTMServerValidationRetry(t *testing.T) {
+	integration.BeforeTest(t)
+
+	clus := integration.NewCluster(t, &integration.ClusterConfig{Size: 1})
+	defer clus.Terminate(t)
+
+	cli := clus.Client(0)
+	_, err := cli.Put(context.TODO(), "a", "1")
+	testutil.AssertNil(t, err)
+	_, err = cli.Put(context.TODO(), "b", "1")
+	testutil.AssertNil(t, err)
+
+	var reads []string
+	applyf := func(stm concurrency.STM) error {
+		a, b := stm.Get("a"), stm.Get("b")
+		reads = append(reads, a+b)
+		if len(reads) == 1 {
+			// conflict with the first attempt
+			if _, err := cli.Put(context.TODO(), "b", "2"); err != nil {
+				return err
+			}
+		}
+		stm.Put("c", a+b)
+		return nil
+	}
+
+	iso := concurrency.WithIsolation(concurrency.SerializableSnapshot)
+	resp, err := concurrency.NewSTM(cli, applyf, iso, concurrency.WithServerValidation())
+	testutil.AssertNil(t, err)
+	if !resp.Succeeded {
+		t.Fatalf("expected txn to succeed, got %+v", resp)
+	}
+	if len(reads) != 2 || reads[0] != "11" || reads[1] != "12" {
+		t.Fatalf("bad reads. got %v, expected [11 12]", reads)
+	}
+
+	gresp, err := cli.Get(context.TODO(), "c")
+	testutil.AssertNil(t, err)
+	if string(gresp.Kvs[0].Value) != "12" {
+		t.Fatalf("bad value. got %+v, expected '12' value", gresp)
+	}
+}
@@ -84,8 +84,12 @@ func printRec(rec *walpb.Record, fromIndex *uint64, out io.Writer) {
 		fmt.Fprintf(out, "Metadata: %s\n", metadata.String())
 	case wal.CrcType:
 		fmt.Fprintf(out, "CRC: %d\n", rec.Crc)
-	case wal.EntryType:
-		e := wal.MustUnmarshalEntry(rec.Data)
+	case wal.EntryType, wal.CompressedEntryType:
+		e, err := wal.DecodeEntryRecord(nil, rec)
+		if err != nil {
+			log.Printf("Failed to decode WAL entry: %v", err)
+			return
+		}
 		if fromIndex == nil || e.Index >= *fromIndex {
 			fmt.Fprintf(out, "Entry: %s\n", e.String())
 		}
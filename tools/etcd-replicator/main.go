@@ -0,0 +1,205 @@
+// Copyright 2023 The etcd Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"go.uber.org/zap"
+
+	"go.etcd.io/etcd/client/pkg/v3/transport"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/client/v3/mirror/replicator"
+)
+
+var (
+	rootCommand = &cobra.Command{
+		Use:   "etcd-replicator",
+		Short: "etcd-replicator continuously replicates the keys of an etcd cluster to another.",
+	}
+	runCommand = &cobra.Command{
+		Use:   "run",
+		Short: "run replicates the active cluster to the passive one, following failovers.",
+		Run:   runCommandFunc,
+	}
+	promoteCommand = &cobra.Command{
+		Use:   "promote",
+		Short: "promote makes a passive cluster active, which reverses the replication.",
+		Run:   promoteCommandFunc,
+	}
+)
+
+// clusterFlags are the flags of the connection to a cluster.
+type clusterFlags struct {
+	endpoints []string
+	tls       transport.TLSInfo
+}
+
+func (cf *clusterFlags) register(fs *pflag.FlagSet, prefix, desc string) {
+	fs.StringSliceVar(&cf.endpoints, prefix+"endpoints", []string{"127.0.0.1:2379"}, "gRPC endpoints of the "+desc)
+	fs.StringVar(&cf.tls.CertFile, prefix+"cert", "", "identify secure client of the "+desc+" using this TLS certificate file")
+	fs.StringVar(&cf.tls.KeyFile, prefix+"key", "", "identify secure client of the "+desc+" using this TLS key file")
+	fs.StringVar(&cf.tls.TrustedCAFile, prefix+"cacert", "", "verify certificates of the TLS-enabled "+desc+" using this CA bundle")
+}
+
+func (cf *clusterFlags) mustClient(lg *zap.Logger) *clientv3.Client {
+	cfg := clientv3.Config{
+		Endpoints:   cf.endpoints,
+		DialTimeout: dialTimeout,
+		Logger:      lg,
+	}
+	if !cf.tls.Empty() || cf.tls.TrustedCAFile != "" {
+		cfgtls, err := cf.tls.ClientConfig()
+		if err != nil {
+			log.Fatalf("bad tls config: %v", err)
+		}
+		cfg.TLS = cfgtls
+	}
+	c, err := clientv3.New(cfg)
+	if err != nil {
+		log.Fatalf("failed to create client: %v", err)
+	}
+	return c
+}
+
+var (
+	dialTimeout time.Duration
+	name        string
+	metaPrefix  string
+
+	primary, secondary             clusterFlags
+	primaryPrefix, secondaryPrefix string
+	includePrefixes                []string
+	excludePrefixes                []string
+	conflictPolicy                 string
+	maxTxnOps                      int
+	listenMetrics                  string
+
+	promoted clusterFlags
+)
+
+func init() {
+	rootCommand.PersistentFlags().DurationVar(&dialTimeout, "dial-timeout", 2*time.Second, "dial timeout for client connections")
+	rootCommand.PersistentFlags().StringVar(&name, "name", "default", "name of the replication, identifying its state in both clusters")
+	rootCommand.PersistentFlags().StringVar(&metaPrefix, "meta-prefix", replicator.DefaultMetaPrefix, "prefix of the replication state keys, which are never replicated")
+
+	primary.register(runCommand.Flags(), "primary-", "primary cluster, active until a failover")
+	secondary.register(runCommand.Flags(), "secondary-", "secondary cluster")
+	runCommand.Flags().StringVar(&primaryPrefix, "primary-prefix", "", "replicated prefix of the primary cluster")
+	runCommand.Flags().StringVar(&secondaryPrefix, "secondary-prefix", "", "replicated prefix of the secondary cluster, to which the primary prefix is remapped")
+	runCommand.Flags().StringArrayVar(&includePrefixes, "include-prefix", nil, "replicate only the keys with one of these prefixes, relative to the replicated prefix")
+	runCommand.Flags().StringArrayVar(&excludePrefixes, "exclude-prefix", nil, "do not replicate the keys with one of these prefixes, relative to the replicated prefix")
+	runCommand.Flags().StringVar(&conflictPolicy, "conflict-policy", replicator.ConflictOverwrite.String(), "resolution of the destination keys changed outside of the replication: 'overwrite' or 'keep'")
+	runCommand.Flags().IntVar(&maxTxnOps, "max-txn-ops", 128, "maximum number of operations per destination transaction")
+	runCommand.Flags().StringVar(&listenMetrics, "listen-metrics", "", "address to serve the replication metrics on, at /metrics")
+
+	promoted.register(promoteCommand.Flags(), "", "cluster to promote")
+
+	rootCommand.AddCommand(runCommand)
+	rootCommand.AddCommand(promoteCommand)
+}
+
+func main() {
+	if err := rootCommand.Execute(); err != nil {
+		fmt.Fprintln(os.Stdout, err)
+		os.Exit(1)
+	}
+}
+
+func runCommandFunc(cmd *cobra.Command, args []string) {
+	var policy replicator.ConflictPolicy
+	switch conflictPolicy {
+	case replicator.ConflictOverwrite.String():
+		policy = replicator.ConflictOverwrite
+	case replicator.ConflictKeep.String():
+		policy = replicator.ConflictKeep
+	default:
+		log.Fatalf("unknown conflict policy %q", conflictPolicy)
+	}
+
+	lg, err := zap.NewProduction()
+	if err != nil {
+		log.Fatal(err)
+	}
+	if listenMetrics != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", promhttp.Handler())
+		go func() {
+			lg.Fatal("failed to serve metrics", zap.Error(http.ListenAndServe(listenMetrics, mux)))
+		}()
+	}
+
+	pc, sc := primary.mustClient(lg), secondary.mustClient(lg)
+	defer pc.Close()
+	defer sc.Close()
+	r := replicator.New(replicator.Config{
+		Name:            name,
+		Primary:         pc,
+		Secondary:       sc,
+		PrimaryPrefix:   primaryPrefix,
+		SecondaryPrefix: secondaryPrefix,
+		Filter:          filter(includePrefixes, excludePrefixes),
+		ConflictPolicy:  policy,
+		MetaPrefix:      metaPrefix,
+		MaxTxnOps:       maxTxnOps,
+		Logger:          lg,
+	})
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	r.Run(ctx)
+}
+
+// filter returns the filter of the replicated keys with one of the included
+// prefixes, if any, and none of the excluded ones.
+func filter(include, exclude []string) func(key []byte) bool {
+	if len(include) == 0 && len(exclude) == 0 {
+		return nil
+	}
+	hasPrefix := func(key []byte, prefixes []string) bool {
+		for _, p := range prefixes {
+			if bytes.HasPrefix(key, []byte(p)) {
+				return true
+			}
+		}
+		return false
+	}
+	return func(key []byte) bool {
+		return (len(include) == 0 || hasPrefix(key, include)) && !hasPrefix(key, exclude)
+	}
+}
+
+func promoteCommandFunc(cmd *cobra.Command, args []string) {
+	c := promoted.mustClient(zap.NewNop())
+	defer c.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	epoch, err := replicator.Promote(ctx, c, metaPrefix, name)
+	if err != nil {
+		log.Fatalf("failed to promote: %v", err)
+	}
+	fmt.Printf("promoted to epoch %d\n", epoch)
+}